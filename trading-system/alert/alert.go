@@ -0,0 +1,206 @@
+package alert
+
+import (
+	"fmt"
+	"lia/logging"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config email alerting settings, read from the environment by LoadConfig
+type Config struct {
+	SMTPHost     string   // ALERT_SMTP_HOST (empty = email alerts disabled)
+	SMTPPort     int      // ALERT_SMTP_PORT (default 587; 465 = implicit TLS, otherwise STARTTLS when offered)
+	Username     string   // ALERT_SMTP_USERNAME
+	Password     string   // ALERT_SMTP_PASSWORD
+	From         string   // ALERT_EMAIL_FROM (default: the username)
+	To           []string // ALERT_EMAIL_TO (comma separated)
+	Cooldown     time.Duration
+	MaxPerHour   int
+	DBDownAfter  time.Duration
+	LiqDistance  float64 // Alert when the mark price is within this % of the liquidation price
+	MarginUsage  float64 // Alert when margin usage reaches this % of equity
+	SubjectLabel string  // ALERT_SUBJECT_PREFIX (default "LIA"), to tell instances apart
+}
+
+// Enabled whether emails can be sent
+func (c Config) Enabled() bool {
+	return c.SMTPHost != "" && len(c.To) > 0
+}
+
+// LoadConfig reads the alerting settings from the environment:
+//
+//	ALERT_SMTP_HOST, ALERT_SMTP_PORT, ALERT_SMTP_USERNAME, ALERT_SMTP_PASSWORD, ALERT_EMAIL_FROM, ALERT_EMAIL_TO
+//	ALERT_COOLDOWN_MINUTES (30)          the same alert is sent at most once per cooldown
+//	ALERT_MAX_PER_HOUR (10)              cap on all alert emails
+//	ALERT_DB_DOWN_MINUTES (5)            database unreachable this long before alerting
+//	ALERT_LIQUIDATION_DISTANCE_PCT (10)  mark price this close to the liquidation price
+//	ALERT_MARGIN_USAGE_PCT (90)          margin usage of equity
+//	ALERT_SUBJECT_PREFIX ("LIA")
+func LoadConfig() Config {
+	c := Config{
+		SMTPHost:     strings.TrimSpace(os.Getenv("ALERT_SMTP_HOST")),
+		SMTPPort:     envInt("ALERT_SMTP_PORT", 587),
+		Username:     strings.TrimSpace(os.Getenv("ALERT_SMTP_USERNAME")),
+		Password:     os.Getenv("ALERT_SMTP_PASSWORD"),
+		From:         strings.TrimSpace(os.Getenv("ALERT_EMAIL_FROM")),
+		Cooldown:     time.Duration(envInt("ALERT_COOLDOWN_MINUTES", 30)) * time.Minute,
+		MaxPerHour:   envInt("ALERT_MAX_PER_HOUR", 10),
+		DBDownAfter:  time.Duration(envInt("ALERT_DB_DOWN_MINUTES", 5)) * time.Minute,
+		LiqDistance:  envFloat("ALERT_LIQUIDATION_DISTANCE_PCT", 10),
+		MarginUsage:  envFloat("ALERT_MARGIN_USAGE_PCT", 90),
+		SubjectLabel: strings.TrimSpace(os.Getenv("ALERT_SUBJECT_PREFIX")),
+	}
+	for _, to := range strings.Split(os.Getenv("ALERT_EMAIL_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			c.To = append(c.To, to)
+		}
+	}
+	if c.From == "" {
+		c.From = c.Username
+	}
+	if c.SubjectLabel == "" {
+		c.SubjectLabel = "LIA"
+	}
+	return c
+}
+
+// envInt a positive integer environment variable (def when unset or invalid)
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name))); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// envFloat a positive number environment variable (def when unset or invalid)
+func envFloat(name string, def float64) float64 {
+	if v, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(name)), 64); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// alertLog the alerting subsystem's logger
+var alertLog = logging.Component("alert")
+
+// outage a condition that only alerts once it has lasted long enough
+type outage struct {
+	since   time.Time
+	alerted bool
+}
+
+var alerts = struct {
+	mu       sync.Mutex
+	config   Config
+	send     func(Config, string, string) error
+	lastSent map[string]time.Time // Last email per alert key
+	sentLog  []time.Time          // Emails sent in the last hour
+	outages  map[string]*outage
+}{
+	config:   Config{Cooldown: 30 * time.Minute, MaxPerHour: 10, DBDownAfter: 5 * time.Minute, LiqDistance: 10, MarginUsage: 90},
+	send:     sendMail,
+	lastSent: make(map[string]time.Time),
+	outages:  make(map[string]*outage),
+}
+
+// Init configures alerting (call once at startup, before the traders start)
+func Init(c Config) {
+	alerts.mu.Lock()
+	alerts.config = c
+	alerts.mu.Unlock()
+	if c.Enabled() {
+		alertLog.Infof("📧 Email alerts enabled: %s via %s:%d (same alert at most every %v, max %d/hour)",
+			strings.Join(c.To, ", "), c.SMTPHost, c.SMTPPort, c.Cooldown, c.MaxPerHour)
+	}
+}
+
+// Settings the current alerting settings (thresholds apply even when email is disabled: alerts are still logged)
+func Settings() Config {
+	alerts.mu.Lock()
+	defer alerts.mu.Unlock()
+	return alerts.config
+}
+
+// Critical logs a critical condition and emails it, at most once per cooldown for the same key and within the
+// hourly cap. Sending happens in the background
+func Critical(key, subject, body string) {
+	now := time.Now()
+	alertLog.With("alert", key).Errorf("🚨 ALERT [%s]: %s", key, subject)
+
+	alerts.mu.Lock()
+	c := alerts.config
+	if !c.Enabled() {
+		alerts.mu.Unlock()
+		return
+	}
+	if last, ok := alerts.lastSent[key]; ok && now.Sub(last) < c.Cooldown {
+		alerts.mu.Unlock()
+		return
+	}
+	recent := alerts.sentLog[:0]
+	for _, t := range alerts.sentLog {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	alerts.sentLog = recent
+	if len(alerts.sentLog) >= c.MaxPerHour {
+		alerts.mu.Unlock()
+		alertLog.With("alert", key).Warnf("⚠️  Alert email for %s suppressed: %d emails sent in the last hour", key, c.MaxPerHour)
+		return
+	}
+	alerts.lastSent[key] = now
+	alerts.sentLog = append(alerts.sentLog, now)
+	send := alerts.send
+	alerts.mu.Unlock()
+
+	go func() {
+		fullSubject := fmt.Sprintf("[%s] %s", c.SubjectLabel, subject)
+		fullBody := fmt.Sprintf("%s\n\nAlert: %s\nTime: %s\n", body, key, now.Format(time.RFC3339))
+		if err := send(c, fullSubject, fullBody); err != nil {
+			alertLog.With("alert", key).Warnf("⚠️  Failed to send alert email (%s): %v", key, err)
+		}
+	}()
+}
+
+// Failing records that a condition (key) is failing: once it has failed continuously for at least after, it is
+// alerted through Critical (cooldown applies while it lasts)
+func Failing(key string, after time.Duration, subject string, err error) {
+	now := time.Now()
+	alerts.mu.Lock()
+	o, ok := alerts.outages[key]
+	if !ok {
+		o = &outage{since: now}
+		alerts.outages[key] = o
+	}
+	down := now.Sub(o.since)
+	if down < after {
+		alerts.mu.Unlock()
+		return
+	}
+	o.alerted = true
+	alerts.mu.Unlock()
+
+	Critical(key, fmt.Sprintf("%s for %.0f minutes", subject, down.Minutes()),
+		fmt.Sprintf("%s since %s.\n\nLast error: %v", subject, o.since.Format(time.RFC3339), err))
+}
+
+// Recovered clears a failing condition; a recovery email follows an alert that was sent for it
+func Recovered(key, subject string) {
+	alerts.mu.Lock()
+	o, ok := alerts.outages[key]
+	delete(alerts.outages, key)
+	delete(alerts.lastSent, key)
+	alerts.mu.Unlock()
+	if !ok || !o.alerted {
+		return
+	}
+	down := time.Since(o.since)
+	alertLog.With("alert", key).Infof("✅ Alert condition %s recovered after %.0f minutes", key, down.Minutes())
+	Critical(key+":recovered", fmt.Sprintf("Recovered: %s", subject),
+		fmt.Sprintf("%s recovered after %.0f minutes.", subject, down.Minutes()))
+}
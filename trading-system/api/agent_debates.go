@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"lia/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleDecisionAgents what each multi-agent committee member proposed in a cycle, the votes per symbol+action
+// and how the consensus was resolved
+func (s *Server) handleDecisionAgents(c *gin.Context) {
+	cycle, err := strconv.Atoi(c.Param("cycle"))
+	if err != nil || cycle < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cycle number"})
+		return
+	}
+
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	debate, err := trader.GetDecisionLogger().GetAgentDebate(cycle)
+	if errors.Is(err, logger.ErrCycleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("cycle #%d not found", cycle)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get agent debate: %v", err)})
+		return
+	}
+	if debate == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("cycle #%d was not decided by multi-agent consensus", cycle)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id":      traderID,
+		"cycle":          cycle,
+		"consensus_mode": debate.ConsensusMode,
+		"resolution":     debate.Resolution,
+		"proposals":      debate.Proposals,
+		"votes":          debate.Votes,
+	})
+}
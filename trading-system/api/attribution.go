@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"lia/logger"
+	"lia/market"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handlePerformanceBySymbol closed-trade P&L by symbol, side and symbol+side
+// Query: trader_id, symbol, side (long/short), days (default all), tz (IANA zone, default UTC)
+func (s *Server) handlePerformanceBySymbol(c *gin.Context) {
+	traderID, attribution, ok := s.performanceAttribution(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id":      traderID,
+		"total_trades":   attribution.TotalTrades,
+		"total_pnl":      attribution.TotalPnL,
+		"by_symbol":      attribution.BySymbol,
+		"by_side":        attribution.BySide,
+		"by_symbol_side": attribution.BySymbolSide,
+	})
+}
+
+// handlePerformanceByHour closed-trade P&L by the hour of day the trade opened and by holding duration
+// Query: as /api/performance/by-symbol
+func (s *Server) handlePerformanceByHour(c *gin.Context) {
+	traderID, attribution, ok := s.performanceAttribution(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id":    traderID,
+		"timezone":     c.DefaultQuery("tz", "UTC"),
+		"total_trades": attribution.TotalTrades,
+		"total_pnl":    attribution.TotalPnL,
+		"by_hour":      attribution.ByHour,
+		"by_duration":  attribution.ByDuration,
+	})
+}
+
+// performanceAttribution parses the attribution query and attributes the trader's closed trades (false = the
+// error response was sent)
+func (s *Server) performanceAttribution(c *gin.Context) (string, *logger.PerformanceAttribution, bool) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", nil, false
+	}
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return "", nil, false
+	}
+
+	q := logger.AttributionQuery{Side: c.Query("side")}
+	if q.Side != "" && q.Side != "long" && q.Side != "short" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "side must be 'long' or 'short'"})
+		return "", nil, false
+	}
+	if symbol := c.Query("symbol"); symbol != "" {
+		q.Symbol = market.Normalize(symbol)
+	}
+	if daysStr := c.Query("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return "", nil, false
+		}
+		q.Since = time.Now().AddDate(0, 0, -days)
+	}
+	if tz := c.Query("tz"); tz != "" {
+		if q.Location, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown time zone '%s'", tz)})
+			return "", nil, false
+		}
+	}
+
+	attribution, err := trader.GetDecisionLogger().GetPerformanceAttribution(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", nil, false
+	}
+	return traderID, attribution, true
+}
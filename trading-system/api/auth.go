@@ -0,0 +1,240 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lia/config"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalContextKey gin context key of the authenticated caller
+const principalContextKey = "api_principal"
+
+// jwtClockSkew tolerance for exp/nbf checks
+const jwtClockSkew = 30 * time.Second
+
+// apiPrincipal an authenticated API caller
+type apiPrincipal struct {
+	Name string // Key name or JWT subject
+	Role string // config.APIRoleRead / config.APIRoleAdmin
+	Via  string // "api_key" or "jwt"
+}
+
+// apiAuth API authentication settings (see config.APIAuthConfig), shared by the engine and read-only servers
+type apiAuth struct {
+	mu  sync.RWMutex
+	cfg config.APIAuthConfig
+}
+
+// set replaces the settings
+func (a *apiAuth) set(cfg config.APIAuthConfig) {
+	a.mu.Lock()
+	a.cfg = cfg
+	a.mu.Unlock()
+}
+
+// config the current settings
+func (a *apiAuth) config() config.APIAuthConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg
+}
+
+// SetAPIAuth enables authentication on the API (read role for reads, admin for trader controls and closes)
+func (s *Server) SetAPIAuth(cfg config.APIAuthConfig) {
+	s.auth.set(cfg)
+	logAPIAuth(cfg, true)
+}
+
+// SetAPIAuth enables authentication on the read-only API
+func (s *ReadServer) SetAPIAuth(cfg config.APIAuthConfig) {
+	s.auth.set(cfg)
+	logAPIAuth(cfg, false)
+}
+
+// logAPIAuth reports the authentication mode at startup (a warning when mutations are open to anyone)
+func logAPIAuth(cfg config.APIAuthConfig, mutations bool) {
+	if !cfg.Enabled {
+		if mutations {
+			log.Printf("⚠️  API authentication disabled: anyone who can reach the API port can pause traders and close positions (set api_auth)")
+		}
+		return
+	}
+	admins := 0
+	for _, k := range cfg.Keys {
+		if k.Role == config.APIRoleAdmin {
+			admins++
+		}
+	}
+	log.Printf("🔐 API authentication enabled: %d key(s) (%d admin), JWT %v, public reads %v",
+		len(cfg.Keys), admins, cfg.JWTSecret != "", cfg.PublicRead)
+}
+
+// authenticate middleware: identifies the caller from its API key or JWT. Without credentials the request is
+// rejected unless public_read is set; invalid credentials are always rejected
+func (a *apiAuth) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := a.config()
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		principal, err := authenticateRequest(c.Request, cfg, time.Now())
+		if err != nil {
+			log.Printf("🔒 Rejected %s %s from %s: %v", c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if principal == nil && !cfg.PublicRead {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "authentication required: send Authorization: Bearer <API key or JWT> or X-API-Key",
+			})
+			return
+		}
+		if principal != nil {
+			c.Set(principalContextKey, principal)
+		}
+		c.Next()
+	}
+}
+
+// requireAdmin middleware for endpoints that change trader state or positions
+func (a *apiAuth) requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.config().Enabled {
+			c.Next()
+			return
+		}
+		value, ok := c.Get(principalContextKey)
+		principal, _ := value.(*apiPrincipal)
+		if !ok || principal == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required: admin role needed"})
+			return
+		}
+		if principal.Role != config.APIRoleAdmin {
+			log.Printf("🔒 Forbidden %s %s for %s (role %s)", c.Request.Method, c.Request.URL.Path, principal.Name, principal.Role)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("admin role required (%s has role %s)", principal.Name, principal.Role)})
+			return
+		}
+		log.Printf("🔐 %s %s by %s (%s)", c.Request.Method, c.Request.URL.Path, principal.Name, principal.Via)
+		c.Next()
+	}
+}
+
+// callerName the authenticated caller's name ("" when authentication is off)
+func callerName(c *gin.Context) string {
+	value, _ := c.Get(principalContextKey)
+	if principal, ok := value.(*apiPrincipal); ok && principal != nil {
+		return principal.Name
+	}
+	return ""
+}
+
+// authenticateRequest the caller named by the request's credentials (nil without credentials)
+func authenticateRequest(r *http.Request, cfg config.APIAuthConfig, now time.Time) (*apiPrincipal, error) {
+	token := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if token == "" {
+		if auth := strings.TrimSpace(r.Header.Get("Authorization")); auth != "" {
+			scheme, value, found := strings.Cut(auth, " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") {
+				return nil, errors.New("unsupported Authorization scheme (use Bearer)")
+			}
+			token = strings.TrimSpace(value)
+		}
+	}
+	if token == "" {
+		return nil, nil
+	}
+
+	if principal := matchAPIKey(token, cfg.Keys); principal != nil {
+		return principal, nil
+	}
+	if cfg.JWTSecret != "" && strings.Count(token, ".") == 2 {
+		return verifyJWT(token, cfg.JWTSecret, now)
+	}
+	return nil, errors.New("invalid API key")
+}
+
+// matchAPIKey the key's principal (compared in constant time over hashes so neither content nor length leaks)
+func matchAPIKey(token string, keys []config.APIKeyConfig) *apiPrincipal {
+	tokenHash := sha256.Sum256([]byte(token))
+	var match *apiPrincipal
+	for _, k := range keys {
+		keyHash := sha256.Sum256([]byte(k.Key))
+		if subtle.ConstantTimeCompare(tokenHash[:], keyHash[:]) == 1 && match == nil {
+			match = &apiPrincipal{Name: k.Name, Role: k.Role, Via: "api_key"}
+		}
+	}
+	return match
+}
+
+// jwtClaims the JWT claims used for authorization
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// verifyJWT checks an HS256 JWT's signature and time claims and returns its subject and role
+func verifyJWT(token, secret string, now time.Time) (*apiPrincipal, error) {
+	parts := strings.Split(token, ".")
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("invalid JWT header encoding")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errors.New("invalid JWT header")
+	}
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported JWT algorithm '%s' (HS256 only)", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("invalid JWT signature encoding")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid JWT signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("invalid JWT payload encoding")
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("invalid JWT claims")
+	}
+	if claims.ExpiresAt != nil && now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(jwtClockSkew)) {
+		return nil, errors.New("JWT expired")
+	}
+	if claims.NotBefore != nil && now.Add(jwtClockSkew).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return nil, errors.New("JWT not valid yet")
+	}
+	role := strings.ToLower(claims.Role)
+	if role != config.APIRoleRead && role != config.APIRoleAdmin {
+		return nil, fmt.Errorf("JWT role must be read or admin, got '%s'", claims.Role)
+	}
+	name := claims.Subject
+	if name == "" {
+		name = "jwt"
+	}
+	return &apiPrincipal{Name: name, Role: role, Via: "jwt"}, nil
+}
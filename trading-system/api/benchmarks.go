@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"lia/benchmark"
+	"lia/market"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// benchmarkPrices caches the closed 1m candles /api/performance compares against (the dashboard polls it, and a
+// trader's window start never moves)
+type benchmarkPrices struct {
+	mu     sync.Mutex
+	prices map[string]float64 // key: symbol@minute
+}
+
+// priceAt market.PriceAt, cached once the minute has closed
+func (p *benchmarkPrices) priceAt(symbol string, t time.Time) (float64, error) {
+	minute := t.Truncate(time.Minute)
+	key := fmt.Sprintf("%s@%d", symbol, minute.Unix())
+	p.mu.Lock()
+	price, ok := p.prices[key]
+	p.mu.Unlock()
+	if ok {
+		return price, nil
+	}
+
+	price, err := market.PriceAt(symbol, t)
+	if err != nil {
+		return 0, err
+	}
+	if time.Since(minute) > time.Minute {
+		p.mu.Lock()
+		if p.prices == nil || len(p.prices) >= 10000 {
+			p.prices = make(map[string]float64)
+		}
+		p.prices[key] = price
+		p.mu.Unlock()
+	}
+	return price, nil
+}
+
+// benchmarkPoint one point of a benchmark's equity curve (the /api/equity-history point fields a benchmark has)
+type benchmarkPoint struct {
+	Timestamp        string  `json:"timestamp"`
+	TotalEquity      float64 `json:"total_equity"`
+	AvailableBalance float64 `json:"available_balance"`
+	TotalPnL         float64 `json:"total_pnl"` // Relative to the initial balance
+	TotalPnLPct      float64 `json:"total_pnl_pct"`
+	PositionCount    int     `json:"position_count"`
+	MarginUsedPct    float64 `json:"margin_used_pct"`
+	RealizedPnL      float64 `json:"realized_pnl"`
+	UnrealizedPnL    float64 `json:"unrealized_pnl"`
+	CycleNumber      int     `json:"cycle_number"` // Position in the replayed history
+}
+
+// handleBenchmarks lists the imported benchmarks (without their trades and curves)
+func (s *Server) handleBenchmarks(c *gin.Context) {
+	var result []gin.H
+	for _, b := range s.traderManager.GetBenchmarks() {
+		latest := b.Latest()
+		pnl := latest.Equity - b.InitialBalance
+		result = append(result, gin.H{
+			"id":              b.ID,
+			"name":            b.Name,
+			"source":          b.Source,
+			"imported_at":     b.ImportedAt,
+			"initial_balance": b.InitialBalance,
+			"trade_count":     b.TradeCount,
+			"total_equity":    latest.Equity,
+			"total_pnl":       pnl,
+			"total_pnl_pct":   pnl / b.InitialBalance * 100,
+			"as_of":           latest.Time,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"benchmarks": result, "count": len(result)})
+}
+
+// handleBenchmarkEquity /api/equity-history for a benchmark ID: its replayed curve, downsampled by ?interval=
+func (s *Server) handleBenchmarkEquity(c *gin.Context, b *benchmark.Benchmark) {
+	interval, err := parseHistoryInterval(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history := make([]benchmarkPoint, 0, len(b.Curve))
+	for i, p := range b.Curve {
+		if interval > 0 && i+1 < len(b.Curve) && b.Curve[i+1].Time.Truncate(interval).Equal(p.Time.Truncate(interval)) {
+			continue // A later point closes this bucket
+		}
+		pnl := p.Equity - b.InitialBalance
+		history = append(history, benchmarkPoint{
+			Timestamp:        p.Time.UTC().Format("2006-01-02 15:04:05"),
+			TotalEquity:      p.Equity,
+			AvailableBalance: p.Equity,
+			TotalPnL:         pnl,
+			TotalPnLPct:      pnl / b.InitialBalance * 100,
+			PositionCount:    p.PositionCount,
+			RealizedPnL:      p.RealizedPnL,
+			UnrealizedPnL:    p.UnrealizedPnL,
+			CycleNumber:      i + 1,
+		})
+	}
+	c.JSON(http.StatusOK, history)
+}
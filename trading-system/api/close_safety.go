@@ -0,0 +1,175 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"lia/config"
+	"lia/trader"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxCloseReasonLength = 500 // Characters of the close reason kept in the decision log
+
+// closeIntent a manual close request as checked by the close safeguards
+type closeIntent struct {
+	Endpoint string // "close" or "force-close"
+	TraderID string
+	Symbol   string
+	Side     string
+	Quantity float64
+	Reason   string
+}
+
+// pendingClose a close waiting for confirmation
+type pendingClose struct {
+	intent  closeIntent
+	expires time.Time
+}
+
+// closeSafety optional safeguards for the manual close endpoints (see config.CloseSafetyConfig)
+type closeSafety struct {
+	cfg     config.CloseSafetyConfig
+	mu      sync.Mutex
+	pending map[string]*pendingClose // key: confirm token
+}
+
+// SetCloseSafety enables the configured safeguards on the manual close endpoints
+func (s *Server) SetCloseSafety(cfg config.CloseSafetyConfig) {
+	s.closeSafety.mu.Lock()
+	s.closeSafety.cfg = cfg
+	s.closeSafety.mu.Unlock()
+	if cfg.RequireConfirmation || cfg.RequireReason || cfg.ForceCloseMinLossPct > 0 {
+		log.Printf("✓ Close position safeguards: confirmation=%v (token TTL %ds), reason required=%v, force-close min loss=%.1f%%",
+			cfg.RequireConfirmation, cfg.ConfirmTTLSeconds, cfg.RequireReason, cfg.ForceCloseMinLossPct)
+	}
+}
+
+// forceCloseMinLossPct minimum loss (% of margin) a position needs before it can be force-closed (0 = not configured)
+func (s *Server) forceCloseMinLossPct() float64 {
+	s.closeSafety.mu.Lock()
+	defer s.closeSafety.mu.Unlock()
+	return s.closeSafety.cfg.ForceCloseMinLossPct
+}
+
+// authorizeClose applies the reason and confirmation safeguards. Returns false if the request must not
+// execute yet (the response has been written: an error, or a confirm token for the two-step flow)
+func (s *Server) authorizeClose(c *gin.Context, intent *closeIntent, confirmToken string) bool {
+	cs := &s.closeSafety
+	cs.mu.Lock()
+	cfg := cs.cfg
+	cs.mu.Unlock()
+
+	// Second step: the token must match the request it was issued for
+	if cfg.RequireConfirmation && confirmToken != "" {
+		pending, err := cs.consume(confirmToken, *intent)
+		if err != nil {
+			log.Printf("❌ Close confirmation rejected: %s %s [%s]: %v", intent.Symbol, intent.Side, intent.TraderID, err)
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return false
+		}
+		if strings.TrimSpace(intent.Reason) == "" {
+			intent.Reason = pending.intent.Reason
+		}
+	}
+
+	intent.Reason = strings.TrimSpace(intent.Reason)
+	if runes := []rune(intent.Reason); len(runes) > maxCloseReasonLength {
+		intent.Reason = string(runes[:maxCloseReasonLength])
+	}
+	if cfg.RequireReason && intent.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "reason is required: describe why this position is being closed manually (stored in the decision log)",
+		})
+		return false
+	}
+
+	// First step: issue a short-lived token instead of closing
+	if cfg.RequireConfirmation && confirmToken == "" {
+		token, expires, err := cs.issue(*intent, time.Duration(cfg.ConfirmTTLSeconds)*time.Second)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to issue confirm token: %v", err)})
+			return false
+		}
+		log.Printf("🔐 Close %s %s [%s] awaiting confirmation (token expires %s)",
+			intent.Symbol, intent.Side, intent.TraderID, expires.Format(time.RFC3339))
+		c.JSON(http.StatusAccepted, gin.H{
+			"confirmation_required": true,
+			"confirm_token":         token,
+			"expires_at":            expires.Format(time.RFC3339),
+			"message": fmt.Sprintf("repeat the request with confirm_token within %ds to %s %s %s",
+				cfg.ConfirmTTLSeconds, intent.Endpoint, intent.Symbol, intent.Side),
+		})
+		return false
+	}
+	return true
+}
+
+// issue creates a confirm token for intent
+func (cs *closeSafety) issue(intent closeIntent, ttl time.Duration) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(buf)
+	expires := time.Now().Add(ttl)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.purgeExpired()
+	if cs.pending == nil {
+		cs.pending = make(map[string]*pendingClose)
+	}
+	cs.pending[token] = &pendingClose{intent: intent, expires: expires}
+	return token, expires, nil
+}
+
+// consume validates and invalidates a confirm token (tokens are single-use, even when the request doesn't match)
+func (cs *closeSafety) consume(token string, intent closeIntent) (*pendingClose, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.purgeExpired()
+
+	pending, ok := cs.pending[token]
+	if !ok {
+		return nil, fmt.Errorf("confirm token is invalid or expired - submit the close again to get a new token")
+	}
+	delete(cs.pending, token)
+
+	p := pending.intent
+	if p.Endpoint != intent.Endpoint || p.TraderID != intent.TraderID || p.Symbol != intent.Symbol ||
+		p.Side != intent.Side || p.Quantity != intent.Quantity {
+		return nil, fmt.Errorf("confirm token was issued for %s %s %s (quantity %.8f) on trader %s - submit the close again",
+			p.Endpoint, p.Symbol, p.Side, p.Quantity, p.TraderID)
+	}
+	return pending, nil
+}
+
+// purgeExpired drops expired tokens (caller holds mu)
+func (cs *closeSafety) purgeExpired() {
+	now := time.Now()
+	for token, pending := range cs.pending {
+		if now.After(pending.expires) {
+			delete(cs.pending, token)
+		}
+	}
+}
+
+// positionLossPct unrealized loss as % of the position's margin (negative when the position is profitable)
+func positionLossPct(pos trader.Position) float64 {
+	leverage := float64(pos.Leverage)
+	if leverage <= 0 {
+		leverage = 1
+	}
+	margin := pos.Quantity * pos.EntryPrice / leverage
+	if margin <= 0 {
+		return 0
+	}
+	return -pos.UnrealizedProfit / margin * 100
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"lia/logger"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCostDays longest /api/costs window
+const maxCostDays = 90
+
+// costTotals aggregated AI usage of a set of cycles
+type costTotals struct {
+	Cycles           int     `json:"cycles"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// add adds a cycle's usage
+func (t *costTotals) add(u logger.AIUsage) {
+	t.Cycles++
+	t.Calls += u.Calls
+	t.PromptTokens += u.PromptTokens
+	t.CompletionTokens += u.CompletionTokens
+	t.CostUSD += u.CostUSD
+}
+
+// merge adds another set of totals
+func (t *costTotals) merge(other costTotals) {
+	t.Cycles += other.Cycles
+	t.Calls += other.Calls
+	t.PromptTokens += other.PromptTokens
+	t.CompletionTokens += other.CompletionTokens
+	t.CostUSD += other.CostUSD
+}
+
+// dailyCost AI usage of one UTC day
+type dailyCost struct {
+	Date string `json:"date"` // YYYY-MM-DD (UTC)
+	costTotals
+}
+
+// traderCosts AI usage of one trader over the requested window
+type traderCosts struct {
+	TraderID        string      `json:"trader_id"`
+	Name            string      `json:"name"`
+	Model           string      `json:"model,omitempty"` // Model of the latest cycle
+	Total           costTotals  `json:"total"`
+	Last24h         costTotals  `json:"last_24h"`
+	CostPerCycleUSD float64     `json:"cost_per_cycle_usd"`
+	Daily           []dailyCost `json:"daily"`
+}
+
+// handleCosts AI token usage and estimated cost per trader and day (?days=7&trader_id=; all traders by default).
+// projected_monthly_usd extrapolates the last 24 hours over 30 days
+func (s *Server) handleCosts(c *gin.Context) {
+	days := 7
+	if daysStr := c.Query("days"); daysStr != "" {
+		if n, err := strconv.Atoi(daysStr); err == nil && n > 0 {
+			days = n
+		}
+	}
+	if days > maxCostDays {
+		days = maxCostDays
+	}
+
+	traders := s.traderManager.GetAllTraders()
+	ids := make([]string, 0, len(traders))
+	if traderID := c.Query("trader_id"); traderID != "" {
+		if _, ok := traders[traderID]; !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "trader not found: " + traderID})
+			return
+		}
+		ids = append(ids, traderID)
+	} else {
+		for id := range traders {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	now := time.Now()
+	since := now.Add(-time.Duration(days) * 24 * time.Hour)
+	dayAgo := now.Add(-24 * time.Hour)
+
+	var total, last24h costTotals
+	result := make([]traderCosts, 0, len(ids))
+	for _, id := range ids {
+		t := traders[id]
+		usage, err := t.GetDecisionLogger().GetAIUsageInRange(since, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get AI usage for " + id + ": " + err.Error()})
+			return
+		}
+
+		costs := traderCosts{TraderID: id, Name: t.GetName(), Daily: []dailyCost{}}
+		byDay := make(map[string]*dailyCost)
+		for _, u := range usage {
+			costs.Total.add(u.AIUsage)
+			if !u.Timestamp.Before(dayAgo) {
+				costs.Last24h.add(u.AIUsage)
+			}
+			date := u.Timestamp.UTC().Format("2006-01-02")
+			day, ok := byDay[date]
+			if !ok {
+				day = &dailyCost{Date: date}
+				byDay[date] = day
+			}
+			day.add(u.AIUsage)
+			costs.Model = u.Model
+		}
+		for _, day := range byDay {
+			costs.Daily = append(costs.Daily, *day)
+		}
+		sort.Slice(costs.Daily, func(i, j int) bool { return costs.Daily[i].Date < costs.Daily[j].Date })
+		if costs.Total.Cycles > 0 {
+			costs.CostPerCycleUSD = costs.Total.CostUSD / float64(costs.Total.Cycles)
+		}
+
+		total.merge(costs.Total)
+		last24h.merge(costs.Last24h)
+		result = append(result, costs)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":                  days,
+		"since":                 since,
+		"total":                 total,
+		"last_24h":              last24h,
+		"projected_monthly_usd": last24h.CostUSD * 30,
+		"traders":               result,
+	})
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// emergencyFlattenRequest optional body of POST /api/emergency/flatten
+type emergencyFlattenRequest struct {
+	Reason string `json:"reason"`
+}
+
+// handleEmergencyFlatten the kill switch (POST /api/emergency/flatten): pauses every trader, cancels their open
+// orders and closes all their positions at market. Traders stay paused until resumed one by one
+func (s *Server) handleEmergencyFlatten(c *gin.Context) {
+	var req emergencyFlattenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = "Emergency flatten"
+	}
+	operator := callerName(c)
+	if operator == "" {
+		operator = "operator"
+	}
+
+	log.Printf("🚨 API: emergency flatten requested by %s (%s)", operator, reason)
+	reports := s.traderManager.EmergencyFlatten(reason, operator)
+
+	closed, failed := 0, 0
+	for _, report := range reports {
+		closed += len(report.Closed)
+		failed += len(report.Failed)
+	}
+	log.Printf("🚨 API: emergency flatten done: %d traders, %d positions closed, %d failures", len(reports), closed, failed)
+
+	status := http.StatusOK
+	if failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"reason":  reason,
+		"traders": len(reports),
+		"closed":  closed,
+		"failed":  failed,
+		"reports": reports,
+		"message": "all traders paused, resume each with POST /api/traders/:id/resume",
+	})
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"lia/logger"
+	"lia/trader"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Equity snapshot query limits: hours of history (default / max) and points after downsampling (default / max)
+const (
+	defaultSnapshotHours  = 24
+	maxSnapshotHours      = 24 * 90
+	defaultSnapshotPoints = 500
+	maxSnapshotPoints     = 5000
+)
+
+// snapshotPoint one point of the snapshot equity curve
+type snapshotPoint struct {
+	Timestamp        string                    `json:"timestamp"`
+	TotalEquity      float64                   `json:"total_equity"`
+	AvailableBalance float64                   `json:"available_balance"`
+	UnrealizedPnL    float64                   `json:"unrealized_pnl"`
+	TotalPnL         float64                   `json:"total_pnl"` // Relative to the initial balance
+	TotalPnLPct      float64                   `json:"total_pnl_pct"`
+	PositionCount    int                       `json:"position_count"`
+	MarginUsedPct    float64                   `json:"margin_used_pct"`
+	Positions        []logger.PositionSnapshot `json:"positions,omitempty"` // ?positions=true
+}
+
+// handleEquitySnapshots /api/equity-history?source=snapshots: the fixed-schedule equity snapshots of the last
+// ?hours= (default 24), downsampled to ?points= (default 500). Empty when snapshots are off or there is no database
+func (s *Server) handleEquitySnapshots(c *gin.Context, at *trader.AutoTrader) {
+	hours := defaultSnapshotHours
+	if hoursStr := c.Query("hours"); hoursStr != "" {
+		n, err := strconv.Atoi(hoursStr)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be a positive integer"})
+			return
+		}
+		hours = min(n, maxSnapshotHours)
+	}
+	points := defaultSnapshotPoints
+	if pointsStr := c.Query("points"); pointsStr != "" {
+		n, err := strconv.Atoi(pointsStr)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "points must be a positive integer"})
+			return
+		}
+		points = min(n, maxSnapshotPoints)
+	}
+
+	end := time.Now()
+	start := end.Add(-time.Duration(hours) * time.Hour)
+	snapshots, err := at.GetDecisionLogger().GetEquitySnapshots(start, end, points, c.Query("positions") == "true")
+	if err != nil {
+		log.Printf("❌ Failed to get equity snapshots: %v", err)
+		c.JSON(http.StatusOK, []interface{}{})
+		return
+	}
+
+	initialBalance := at.GetInitialBalance()
+	history := make([]snapshotPoint, 0, len(snapshots))
+	for _, snap := range snapshots {
+		point := snapshotPoint{
+			Timestamp:        snap.Timestamp.Format("2006-01-02 15:04:05"),
+			TotalEquity:      snap.TotalEquity,
+			AvailableBalance: snap.AvailableBalance,
+			UnrealizedPnL:    snap.UnrealizedPnL,
+			PositionCount:    snap.PositionCount,
+			MarginUsedPct:    snap.MarginUsedPct,
+			Positions:        snap.Positions,
+		}
+		if initialBalance > 0 {
+			point.TotalPnL = snap.TotalEquity - initialBalance
+			point.TotalPnLPct = point.TotalPnL / initialBalance * 100
+		}
+		history = append(history, point)
+	}
+	c.JSON(http.StatusOK, history)
+}
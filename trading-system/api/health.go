@@ -0,0 +1,325 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lia/pool"
+	"lia/trader"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	healthCheckTimeout = 5 * time.Second  // Per-dependency check timeout
+	healthCacheTTL     = 10 * time.Second // Probe results are reused so frequent polling doesn't hammer the APIs
+
+	// A running trader is stale when its last successful cycle is older than this many scan intervals (and
+	// at least traderStaleMin)
+	traderStaleIntervals = 3
+	traderStaleMin       = 10 * time.Minute
+)
+
+// Dependency check status
+const (
+	checkOK      = "ok"
+	checkFail    = "fail"
+	checkStale   = "stale"   // Coin pool serving snapshot/default data, trader without a recent successful cycle
+	checkSkipped = "skipped" // No check available (paper trading, JSON file storage, source disabled)
+)
+
+// dependencyCheck result of one dependency check
+type dependencyCheck struct {
+	Component string   `json:"component"` // exchange / exchange_account / ai_provider / database / coin_pool / trader
+	Name      string   `json:"name"`
+	Status    string   `json:"status"`   // ok / fail / stale / skipped
+	Required  bool     `json:"required"` // A failed required check makes the instance not ready
+	LatencyMs *int64   `json:"latency_ms,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Detail    string   `json:"detail,omitempty"`
+	Traders   []string `json:"traders,omitempty"` // Traders depending on this endpoint
+
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"` // Trader checks: end of the last successful cycle
+}
+
+// healthReport readiness probe response
+type healthReport struct {
+	Status        string            `json:"status"` // ok / degraded / unavailable
+	Ready         bool              `json:"ready"`
+	Time          time.Time         `json:"time"`
+	CheckedAt     time.Time         `json:"checked_at"`
+	UptimeSeconds int               `json:"uptime_seconds"`
+	Checks        []dependencyCheck `json:"checks"`
+}
+
+// healthProbe caches the last readiness report
+type healthProbe struct {
+	mu     sync.Mutex
+	report *healthReport
+}
+
+// handleHealth readiness probe: reports each dependency's status and latency with an overall ready flag
+// Returns 503 when a required dependency (exchange, AI provider, database) is failing, so container
+// orchestrators and Render restart the instance. With ?strict=true a degraded report (stale coin pool or
+// trader, crashed trader, optional check failing) is a 503 too, for load balancers that should drain it
+func (s *Server) handleHealth(c *gin.Context) {
+	report := s.readiness()
+	report.Time = time.Now()
+	report.UptimeSeconds = int(time.Since(s.startTime).Seconds())
+
+	code := http.StatusOK
+	if !report.Ready || (c.Query("strict") == "true" && report.Status != "ok") {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
+
+// readiness returns the cached report, re-running the checks once it is older than healthCacheTTL
+// (concurrent probes wait for the running check instead of starting their own)
+func (s *Server) readiness() healthReport {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	if s.health.report != nil && time.Since(s.health.report.CheckedAt) < healthCacheTTL {
+		return *s.health.report
+	}
+	report := s.checkDependencies()
+	if previous := s.health.report; previous == nil || previous.Ready != report.Ready {
+		if report.Ready {
+			log.Printf("✅ Readiness: %s", report)
+		} else {
+			log.Printf("🚨 Readiness: not ready - %s", report)
+		}
+	}
+	s.health.report = &report
+	return report
+}
+
+// checkDependencies runs all dependency checks concurrently
+func (s *Server) checkDependencies() healthReport {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		checks []dependencyCheck
+	)
+	run := func(check dependencyCheck, ping func(ctx context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			err := ping(ctx)
+			latency := time.Since(start).Milliseconds()
+			switch {
+			case errors.Is(err, errSkipped):
+				check.Status = checkSkipped
+			case err != nil:
+				check.Status = checkFail
+				check.Error = err.Error()
+				check.LatencyMs = &latency
+			default:
+				check.Status = checkOK
+				check.LatencyMs = &latency
+			}
+			mu.Lock()
+			checks = append(checks, check)
+			mu.Unlock()
+		}()
+	}
+
+	// Group traders by exchange, account and AI endpoint so each is checked once
+	exchanges := make(map[string][]*trader.AutoTrader)
+	accounts := make(map[string][]*trader.AutoTrader)
+	aiEndpoints := make(map[string][]*trader.AutoTrader)
+	now := time.Now()
+	for _, at := range s.traderManager.GetAllTraders() {
+		exchanges[at.GetExchange()] = append(exchanges[at.GetExchange()], at)
+		if key := at.AccountKey(); key != "" {
+			accounts[key] = append(accounts[key], at)
+		}
+		mu.Lock()
+		checks = append(checks, traderCheck(at, now))
+		mu.Unlock()
+		if at.UsesAI() {
+			aiEndpoints[at.GetAIEndpoint()] = append(aiEndpoints[at.GetAIEndpoint()], at)
+		}
+
+		// Database: each trader has its own decision logger connection
+		decisionLogger := at.GetDecisionLogger()
+		check := dependencyCheck{Component: "database", Name: at.GetID(), Required: true, Traders: []string{at.GetID()}}
+		// With the write queue, records wait in the local spill while the database is down: not required
+		if queued, pending, _ := decisionLogger.WriteQueueStatus(); queued {
+			check.Required = false
+			check.Detail = fmt.Sprintf("%d decision records waiting to sync", pending)
+		}
+		run(check, func(ctx context.Context) error {
+			backend, err := decisionLogger.PingDB(ctx)
+			if backend == "json" {
+				return errSkipped
+			}
+			return err
+		})
+	}
+
+	for exchange, traders := range exchanges {
+		at := traders[0]
+		check := dependencyCheck{Component: "exchange", Name: exchange, Required: true, Traders: traderIDs(traders)}
+		run(check, func(ctx context.Context) error {
+			supported, err := at.PingExchange(ctx)
+			if !supported {
+				return errSkipped
+			}
+			return err
+		})
+	}
+
+	// Signed request: catches revoked keys and missing read permission, which the public ping does not
+	for _, traders := range accounts {
+		at := traders[0]
+		ids := traderIDs(traders)
+		check := dependencyCheck{Component: "exchange_account", Name: at.GetExchange() + ":" + ids[0], Required: true, Traders: ids}
+		run(check, func(ctx context.Context) error {
+			supported, err := at.CheckExchangeAccount(ctx)
+			if !supported {
+				return errSkipped
+			}
+			return err
+		})
+	}
+
+	for endpoint, traders := range aiEndpoints {
+		at := traders[0]
+		check := dependencyCheck{Component: "ai_provider", Name: endpoint, Required: true, Traders: traderIDs(traders)}
+		run(check, at.PingAI)
+	}
+
+	wg.Wait()
+
+	// Coin pool freshness (not required: traders keep running on snapshot data)
+	poolHealth := pool.GetPoolHealth()
+	checks = append(checks, coinPoolCheck("ai500", poolHealth.AI500), coinPoolCheck("oi_top", poolHealth.OITop))
+	for name, status := range poolHealth.Custom {
+		checks = append(checks, coinPoolCheck(name, status))
+	}
+
+	report := healthReport{Status: "ok", Ready: true, CheckedAt: time.Now()}
+	for i := range checks {
+		check := &checks[i]
+		switch {
+		case check.Status == checkFail && check.Required:
+			report.Ready = false
+			report.Status = "unavailable"
+		case (check.Status == checkFail || check.Status == checkStale) && report.Status == "ok":
+			report.Status = "degraded"
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].Component != checks[j].Component {
+			return checks[i].Component < checks[j].Component
+		}
+		return checks[i].Name < checks[j].Name
+	})
+	report.Checks = checks
+	return report
+}
+
+// errSkipped marks a dependency without an applicable check
+var errSkipped = errors.New("check not applicable")
+
+// coinPoolCheck reports a coin pool source's freshness (fails once stale past the alert threshold)
+func coinPoolCheck(source string, status pool.SourceStatus) dependencyCheck {
+	check := dependencyCheck{Component: "coin_pool", Name: source, Status: checkOK, Detail: status.Mode}
+	switch {
+	case status.Mode == pool.SourceModeDisabled:
+		check.Status = checkSkipped
+	case status.Alerting:
+		check.Status = checkFail
+		check.Error = status.LastError
+		check.Detail = fmt.Sprintf("%s data, upstream unavailable for %.0f minutes", status.Mode, status.StaleMinutes)
+	case status.Stale():
+		check.Status = checkStale
+		check.Error = status.LastError
+		check.Detail = fmt.Sprintf("%s data, upstream unavailable for %.0f minutes", status.Mode, status.StaleMinutes)
+	case !status.SnapshotAt.IsZero():
+		check.Detail = fmt.Sprintf("live data, %.0f minutes old", status.SnapshotAgeMinutes)
+	}
+	return check
+}
+
+// traderCheck a trader's loop health: crashed traders fail, running traders are stale once their last
+// successful cycle is older than traderStaleIntervals scan intervals (not required: other traders keep trading)
+func traderCheck(at *trader.AutoTrader, now time.Time) dependencyCheck {
+	check := dependencyCheck{Component: "trader", Name: at.GetID(), Status: checkOK}
+	last := at.LastSuccessfulCycle()
+	if !last.IsZero() {
+		check.LastSuccessAt = &last
+	}
+
+	state := at.HealthState()
+	switch state {
+	case trader.HealthCrashed:
+		check.Status = checkFail
+		check.Error = at.LastCrash()
+		check.Detail = "crashed, restart pending"
+		return check
+	case trader.HealthRunning:
+	default:
+		check.Status = checkSkipped
+		check.Detail = state
+		return check
+	}
+
+	staleAfter := at.RuntimeSettings().ScanInterval * traderStaleIntervals
+	if staleAfter < traderStaleMin {
+		staleAfter = traderStaleMin
+	}
+	since := last
+	if since.IsZero() {
+		since = at.RunningSince()
+	}
+	switch {
+	case since.IsZero():
+		check.Detail = "starting"
+	case now.Sub(since) > staleAfter && last.IsZero():
+		check.Status = checkStale
+		check.Detail = fmt.Sprintf("no successful cycle in the %.0f minutes since start", now.Sub(since).Minutes())
+	case now.Sub(since) > staleAfter:
+		check.Status = checkStale
+		check.Detail = fmt.Sprintf("last successful cycle %.0f minutes ago", now.Sub(since).Minutes())
+	case last.IsZero():
+		check.Detail = "no cycle completed yet"
+	default:
+		check.Detail = fmt.Sprintf("last successful cycle %.0f minutes ago", now.Sub(since).Minutes())
+	}
+	return check
+}
+
+// traderIDs sorted IDs of traders
+func traderIDs(traders []*trader.AutoTrader) []string {
+	ids := make([]string, 0, len(traders))
+	for _, at := range traders {
+		ids = append(ids, at.GetID())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// String summary of failing checks (for logs)
+func (r healthReport) String() string {
+	var failing []string
+	for _, check := range r.Checks {
+		if check.Status == checkFail {
+			failing = append(failing, fmt.Sprintf("%s:%s", check.Component, check.Name))
+		}
+	}
+	if len(failing) == 0 {
+		return r.Status
+	}
+	return fmt.Sprintf("%s (failing: %s)", r.Status, strings.Join(failing, ", "))
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lia/config"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// httpListener the http.Server behind an API server: HTTPS when a certificate is configured, connection
+// timeouts, and a graceful Stop that drains in-flight requests
+type httpListener struct {
+	mu      sync.Mutex
+	options config.APIServerConfig
+	server  *http.Server
+	stopped bool
+}
+
+// setOptions sets the TLS and timeout settings used by the next serve
+func (l *httpListener) setOptions(options config.APIServerConfig) {
+	l.mu.Lock()
+	l.options = options
+	l.mu.Unlock()
+}
+
+// scheme "https" or "http"
+func (l *httpListener) scheme() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.options.TLSEnabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// serve listens on port until Stop (returns nil after a graceful stop)
+func (l *httpListener) serve(handler http.Handler, port int) error {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return nil
+	}
+	options := l.options
+	seconds := func(n int) time.Duration { return time.Duration(n) * time.Second }
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadHeaderTimeout: seconds(options.ReadHeaderTimeoutSeconds),
+		ReadTimeout:       seconds(options.ReadTimeoutSeconds),
+		WriteTimeout:      seconds(options.WriteTimeoutSeconds),
+		IdleTimeout:       seconds(options.IdleTimeoutSeconds),
+	}
+	l.server = server
+	l.mu.Unlock()
+
+	var err error
+	if options.TLSEnabled() {
+		err = server.ListenAndServeTLS(options.TLSCertFile, options.TLSKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// stop stops accepting connections and waits for in-flight requests until ctx expires (then closes them)
+func (l *httpListener) stop(ctx context.Context) error {
+	l.mu.Lock()
+	l.stopped = true
+	server := l.server
+	l.mu.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	log.Printf("🛑 API server shutting down: draining in-flight requests...")
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+		return fmt.Errorf("API server shutdown: %w", err)
+	}
+	log.Printf("✓ API server stopped")
+	return nil
+}
+
+// shutdownTimeout how long Stop callers should let requests drain
+func (l *httpListener) shutdownTimeout() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.options.ShutdownTimeoutSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(l.options.ShutdownTimeoutSeconds) * time.Second
+}
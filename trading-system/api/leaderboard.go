@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"lia/manager"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleLeaderboard traders ranked by P&L % over a window: ?window=24h|7d|30d|all (default 24h; any "Nd" or Go
+// duration works) or ?since_cycle=N
+func (s *Server) handleLeaderboard(c *gin.Context) {
+	window, err := parseLeaderboardWindow(c.Query("window"), c.Query("since_cycle"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s.traderManager.GetLeaderboard(window))
+}
+
+// parseLeaderboardWindow the window of the leaderboard query (since_cycle takes precedence)
+func parseLeaderboardWindow(windowStr, sinceCycleStr string, now time.Time) (manager.LeaderboardWindow, error) {
+	if sinceCycleStr != "" {
+		cycle, err := strconv.Atoi(sinceCycleStr)
+		if err != nil || cycle <= 0 {
+			return manager.LeaderboardWindow{}, fmt.Errorf("since_cycle must be a positive cycle number")
+		}
+		return manager.LeaderboardWindow{Label: fmt.Sprintf("since_cycle=%d", cycle), SinceCycle: cycle}, nil
+	}
+
+	switch windowStr {
+	case "":
+		windowStr = "24h"
+	case "all":
+		return manager.LeaderboardWindow{Label: "all"}, nil
+	}
+	var length time.Duration
+	if days, ok := strings.CutSuffix(windowStr, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return manager.LeaderboardWindow{}, fmt.Errorf("invalid window '%s'", windowStr)
+		}
+		length = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if length, err = time.ParseDuration(windowStr); err != nil {
+			return manager.LeaderboardWindow{}, fmt.Errorf("invalid window '%s' (e.g. 24h, 7d, 30d, all)", windowStr)
+		}
+	}
+	if length <= 0 {
+		return manager.LeaderboardWindow{}, fmt.Errorf("window must be positive")
+	}
+	return manager.LeaderboardWindow{Label: windowStr, Since: now.Add(-length)}, nil
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"lia/decision"
+	"lia/trader"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// manualTradeWait how long POST /api/trade waits for the trade's cycle before answering 202
+const manualTradeWait = 60 * time.Second
+
+// manualTradeRequest an operator's trade: a "decisions" array or one decision inline
+type manualTradeRequest struct {
+	TraderID          string              `json:"trader_id"`
+	Comment           string              `json:"comment"`
+	Decisions         []decision.Decision `json:"decisions"`
+	decision.Decision                     // Single inline decision
+}
+
+// handleManualTrade executes an operator's open/close decisions on a trader (POST /api/trade). They run in a
+// cycle of their own, are validated like AI decisions and recorded with source "manual"; the response carries
+// the cycle's outcome, or 202 if it did not finish within manualTradeWait
+func (s *Server) handleManualTrade(c *gin.Context) {
+	var req manualTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.TraderID == "" {
+		req.TraderID = c.Query("trader_id")
+	}
+	if _, err := s.traderManager.GetTrader(req.TraderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	decisions := req.Decisions
+	if len(decisions) == 0 && req.Symbol != "" {
+		decisions = []decision.Decision{req.Decision}
+	}
+
+	operator := callerName(c)
+	signal, err := s.traderManager.SubmitManualTrade(req.TraderID, decisions, req.Comment, operator)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, trader.ErrInvalidSignal):
+			status = http.StatusBadRequest
+		case errors.Is(err, trader.ErrSignalQueueFull):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, trader.ErrTraderNotRunning), errors.Is(err, trader.ErrSignalsInSimulation):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("🧑 API: manual trade %s queued (%s, %d decisions, by %s)", signal.ID, req.TraderID, len(signal.Decisions), signal.Operator)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), manualTradeWait)
+	defer cancel()
+	record, err := signal.Wait(ctx)
+	if err != nil {
+		c.JSON(http.StatusAccepted, gin.H{"trader_id": req.TraderID, "trade_id": signal.ID, "queued": true,
+			"message": "trade queued, its cycle has not finished yet (see /api/decisions)"})
+		return
+	}
+	if record == nil {
+		c.JSON(http.StatusConflict, gin.H{"trader_id": req.TraderID, "trade_id": signal.ID, "error": "trade dropped before its cycle ran"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id":     req.TraderID,
+		"trade_id":      signal.ID,
+		"cycle_number":  record.CycleNumber,
+		"success":       record.Success,
+		"error_message": record.ErrorMessage,
+		"decisions":     record.Decisions,
+		"execution_log": record.ExecutionLog,
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"lia/news"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newsIngestRequest headlines pushed by a webhook or script
+type newsIngestRequest struct {
+	Headlines []struct {
+		Title       string    `json:"title" binding:"required"`
+		Source      string    `json:"source"`
+		URL         string    `json:"url"`
+		PublishedAt time.Time `json:"published_at"` // Zero = now
+		Symbols     []string  `json:"symbols"`      // Base assets, e.g. ["BTC", "ETH"]
+		Sentiment   string    `json:"sentiment"`    // positive / negative
+		Important   bool      `json:"important"`
+	} `json:"headlines" binding:"required"`
+}
+
+// handleNews the stored headlines, newest first (?symbol= filters by relevance, ?limit= default 50)
+func (s *Server) handleNews(c *gin.Context) {
+	if !news.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "news feed is not enabled"})
+		return
+	}
+	limit := 50
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+	headlines := news.Recent(strings.ToUpper(c.Query("symbol")), limit)
+	if headlines == nil {
+		headlines = []news.Headline{}
+	}
+	c.JSON(http.StatusOK, gin.H{"headlines": headlines, "last_fetch": news.LastFetch()})
+}
+
+// handleNewsIngest adds pushed headlines to the feed
+func (s *Server) handleNewsIngest(c *gin.Context) {
+	if !news.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "news feed is not enabled"})
+		return
+	}
+	var req newsIngestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	headlines := make([]news.Headline, 0, len(req.Headlines))
+	for _, h := range req.Headlines {
+		sentiment := strings.ToLower(h.Sentiment)
+		if sentiment != "positive" && sentiment != "negative" {
+			sentiment = ""
+		}
+		source := h.Source
+		if source == "" {
+			source = "webhook"
+		}
+		headlines = append(headlines, news.Headline{
+			Title:       h.Title,
+			Source:      source,
+			URL:         h.URL,
+			PublishedAt: h.PublishedAt,
+			Symbols:     h.Symbols,
+			Sentiment:   sentiment,
+			Important:   h.Important,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"received": len(headlines), "added": news.Ingest(headlines)})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"lia/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleOrders submitted orders with their status, filled quantity, average fill price and the price the
+// decision was executed at
+// Query: trader_id, unsettled (true = only orders still open or without a known fill), limit (default 100)
+func (s *Server) handleOrders(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	orders, err := trader.GetDecisionLogger().GetOrders(c.Query("unsettled") == "true", limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if orders == nil {
+		orders = []logger.OrderRecord{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"orders":    orders,
+	})
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleOwnership the positions, margin and realized P&L a trader owns on its exchange account (attributed by
+// client order tag when several traders share the account), and the exchange quantity no trader owns
+func (s *Server) handleOwnership(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownership, err := trader.GetOwnership()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ownership == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "position ownership is not tracked for this trader"})
+		return
+	}
+	c.JSON(http.StatusOK, ownership)
+}
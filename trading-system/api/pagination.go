@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"lia/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// /api/decisions page size (default / max) and the smallest /api/equity-history interval
+const (
+	defaultDecisionPageLimit = 100
+	maxDecisionPageLimit     = 1000
+	minHistoryInterval       = time.Minute
+)
+
+// parseDecisionPage the page requested by ?limit= (default 100, max 1000), ?offset=, ?cursor= (X-Next-Cursor of
+// the previous page) and ?fields= (input_prompt, cot_trace, raw_response or all; none by default)
+func parseDecisionPage(c *gin.Context) (logger.DecisionPageQuery, error) {
+	q := logger.DecisionPageQuery{Limit: defaultDecisionPageLimit}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("limit must be a positive integer")
+		}
+		q.Limit = min(n, maxDecisionPageLimit)
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		n, err := strconv.Atoi(offsetStr)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("offset must be a non-negative integer")
+		}
+		q.Offset = n
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		if q.Offset > 0 {
+			return q, fmt.Errorf("offset and cursor cannot be combined")
+		}
+		before, err := logger.ParseDecisionCursor(cursor)
+		if err != nil {
+			return q, fmt.Errorf("invalid cursor %q (use the X-Next-Cursor header of the previous page)", cursor)
+		}
+		q.Before = before
+	}
+	if fields := c.Query("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			switch strings.TrimSpace(field) {
+			case "all":
+				q.InputPrompt, q.CoTTrace, q.RawResponse = true, true, true
+			case "input_prompt":
+				q.InputPrompt = true
+			case "cot_trace":
+				q.CoTTrace = true
+			case "raw_response":
+				q.RawResponse = true
+			default:
+				return q, fmt.Errorf("unknown field %q (input_prompt, cot_trace, raw_response or all)", field)
+			}
+		}
+	}
+	return q, nil
+}
+
+// writePageHeaders sets X-Total-Count and, when older records remain, X-Next-Cursor
+func writePageHeaders(c *gin.Context, page *logger.DecisionPage) {
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if !page.NextCursor.IsZero() {
+		c.Header("X-Next-Cursor", page.NextCursor.String())
+	}
+}
+
+// parseHistoryInterval ?interval= of a chart endpoint (e.g. 15m; 0 = every point, at least 1m otherwise)
+func parseHistoryInterval(c *gin.Context) (time.Duration, error) {
+	intervalStr := c.Query("interval")
+	if intervalStr == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil || interval < minHistoryInterval {
+		return 0, fmt.Errorf("interval must be a duration of at least %v (e.g. 15m)", minHistoryInterval)
+	}
+	return interval, nil
+}
+
+// downsampleRecords keeps the last record of each interval-long time bucket (chronological records in and out)
+func downsampleRecords(records []*logger.DecisionRecord, interval time.Duration) []*logger.DecisionRecord {
+	if interval <= 0 || len(records) < 2 {
+		return records
+	}
+	sampled := make([]*logger.DecisionRecord, 0, len(records))
+	for i, record := range records {
+		if i+1 < len(records) && records[i+1].Timestamp.Truncate(interval).Equal(record.Timestamp.Truncate(interval)) {
+			continue // A later record closes this bucket
+		}
+		sampled = append(sampled, record)
+	}
+	return sampled
+}
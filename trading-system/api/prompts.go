@@ -0,0 +1,88 @@
+package api
+
+import (
+	"lia/decision"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// promptVersionInfo a stored prompt template version and the traders currently running it
+type promptVersionInfo struct {
+	Version   string    `json:"version"`
+	Name      string    `json:"name"`
+	FirstSeen time.Time `json:"first_seen"`
+	Traders   []string  `json:"traders"`
+	System    string    `json:"system,omitempty"` // Only with ?sources=true
+	User      string    `json:"user,omitempty"`
+}
+
+// SetPromptVersionsDir where the traders store their prompt template versions (config prompt_versions_dir)
+func (s *Server) SetPromptVersionsDir(dir string) {
+	s.promptVersionsDir = dir
+}
+
+// handlePromptVersions every stored prompt template version, oldest first, with the traders using it
+// (?sources=true includes the template text)
+func (s *Server) handlePromptVersions(c *gin.Context) {
+	records, err := decision.ListPromptVersions(s.promptVersionsDir)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list prompt versions: " + err.Error()})
+		return
+	}
+
+	inUse := make(map[string][]string)
+	for id, t := range s.traderManager.GetAllTraders() {
+		if pt := t.GetPromptTemplate(); pt != nil && t.UsesAI() {
+			inUse[pt.Version] = append(inUse[pt.Version], id)
+		}
+	}
+
+	withSources := c.Query("sources") == "true"
+	versions := make([]promptVersionInfo, 0, len(records))
+	for _, r := range records {
+		traders := inUse[r.Version]
+		if traders == nil {
+			traders = []string{}
+		}
+		sort.Strings(traders)
+		info := promptVersionInfo{Version: r.Version, Name: r.Name, FirstSeen: r.FirstSeen, Traders: traders}
+		if withSources {
+			info.System = r.System
+			info.User = r.User
+		}
+		versions = append(versions, info)
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// handlePromptDiff unified diff of the system and user templates between two stored versions (?from=&to=)
+func (s *Server) handlePromptDiff(c *gin.Context) {
+	fromVersion, toVersion := c.Query("from"), c.Query("to")
+	if fromVersion == "" || toVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to prompt versions are required"})
+		return
+	}
+	from, err := decision.LoadPromptVersion(s.promptVersionsDir, fromVersion)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := decision.LoadPromptVersion(s.promptVersionsDir, toVersion)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	systemDiff := decision.DiffPromptText(from.System, to.System)
+	userDiff := decision.DiffPromptText(from.User, to.User)
+	c.JSON(http.StatusOK, gin.H{
+		"from":        gin.H{"version": from.Version, "name": from.Name, "first_seen": from.FirstSeen},
+		"to":          gin.H{"version": to.Version, "name": to.Name, "first_seen": to.FirstSeen},
+		"identical":   systemDiff == "" && userDiff == "",
+		"system_diff": systemDiff,
+		"user_diff":   userDiff,
+	})
+}
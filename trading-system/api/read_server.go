@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lia/bus"
+	"lia/config"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadServer read-only HTTP API served from a StateStore fed by the message bus (split deployment).
+// Write endpoints (manual closes) and log-backed endpoints stay on the engine
+type ReadServer struct {
+	router     *gin.Engine
+	store      *StateStore
+	port       int
+	staleAfter time.Duration // No bus message for this long = not ready
+	startTime  time.Time
+	auth       apiAuth
+	listener   httpListener
+}
+
+// NewReadServer creates the read-only API server. staleAfter is how long without engine updates
+// before /health reports the instance as not ready
+func NewReadServer(store *StateStore, port int, staleAfter time.Duration) *ReadServer {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(corsMiddleware())
+
+	s := &ReadServer{
+		router:     router,
+		store:      store,
+		port:       port,
+		staleAfter: staleAfter,
+		startTime:  time.Now(),
+	}
+	s.setupRoutes()
+	return s
+}
+
+// setupRoutes registers the read endpoints (same paths and response shapes as the engine API)
+func (s *ReadServer) setupRoutes() {
+	s.router.Any("/health", s.handleHealth)
+
+	api := s.router.Group("/api", s.auth.authenticate())
+	{
+		api.GET("/competition", s.handleSystem(bus.TopicCompetition))
+		api.GET("/traders", s.handleSystem(bus.TopicTraders))
+		api.GET("/status", s.handleTrader(bus.TopicStatus))
+		api.GET("/account", s.handleTrader(bus.TopicAccount))
+		api.GET("/positions", s.handleTrader(bus.TopicPositions))
+		api.GET("/decisions/latest", s.handleTrader(bus.TopicDecisions))
+		api.GET("/statistics", s.handleTrader(bus.TopicStatistics))
+		api.GET("/context", s.handleContext)
+		api.GET("/equity-history", s.handleEquityHistory)
+	}
+
+	s.router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("route not available on the read-only API: %s %s (served by the trading engine)", c.Request.Method, c.Request.URL.Path),
+		})
+	})
+}
+
+// handleHealth ready while the engine has published within staleAfter
+func (s *ReadServer) handleHealth(c *gin.Context) {
+	last := s.store.LastReceived()
+	ready := !last.IsZero() && time.Since(last) <= s.staleAfter
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	report := gin.H{
+		"status":         status,
+		"ready":          ready,
+		"mode":           "read_only",
+		"time":           time.Now(),
+		"uptime_seconds": int(time.Since(s.startTime).Seconds()),
+		"trader_count":   len(s.store.TraderIDs()),
+	}
+	if !last.IsZero() {
+		report["last_update"] = last
+	}
+	c.JSON(code, report)
+}
+
+// traderID the requested trader (default: the first known one)
+func (s *ReadServer) traderID(c *gin.Context) (string, error) {
+	traderID := c.Query("trader_id")
+	if traderID == "" {
+		ids := s.store.TraderIDs()
+		if len(ids) == 0 {
+			return "", fmt.Errorf("no available trader")
+		}
+		return ids[0], nil
+	}
+	if !s.store.HasTrader(traderID) {
+		return "", fmt.Errorf("trader ID '%s' does not exist", traderID)
+	}
+	return traderID, nil
+}
+
+// handleSystem serves the latest system-wide snapshot of topic
+func (s *ReadServer) handleSystem(topic string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snapshot, ok := s.store.System(topic)
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "no data received from the trading engine yet"})
+			return
+		}
+		writeSnapshot(c, snapshot)
+	}
+}
+
+// handleTrader serves a trader's latest snapshot of topic
+func (s *ReadServer) handleTrader(topic string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traderID, err := s.traderID(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		snapshot, ok := s.store.Trader(traderID, topic)
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("no %s data received for %s yet", topic, traderID)})
+			return
+		}
+		writeSnapshot(c, snapshot)
+	}
+}
+
+// handleContext latest decision context, wrapped like the engine's /api/context
+func (s *ReadServer) handleContext(c *gin.Context) {
+	traderID, err := s.traderID(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	snapshot, ok := s.store.Trader(traderID, bus.TopicContext)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no decision cycle has run yet"})
+		return
+	}
+	c.Header("X-Snapshot-Time", snapshot.Time.Format(time.RFC3339))
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"context":   json.RawMessage(snapshot.Data),
+	})
+}
+
+// handleEquityHistory equity points recorded from published account snapshots
+func (s *ReadServer) handleEquityHistory(c *gin.Context) {
+	traderID, err := s.traderID(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s.store.EquityHistory(traderID))
+}
+
+// writeSnapshot writes stored JSON as-is, with its publish time in X-Snapshot-Time
+func writeSnapshot(c *gin.Context, snapshot storedSnapshot) {
+	c.Header("X-Snapshot-Time", snapshot.Time.Format(time.RFC3339))
+	c.Data(http.StatusOK, "application/json; charset=utf-8", snapshot.Data)
+}
+
+// Start starts the read-only API server (blocks)
+func (s *ReadServer) Start() error {
+	addr := fmt.Sprintf(":%d", s.port)
+	log.Printf("🌐 Read-only API server started at %s://localhost%s", s.listener.scheme(), addr)
+	log.Printf("  • GET  /api/competition, /api/traders")
+	log.Printf("  • GET  /api/status, /api/account, /api/positions, /api/decisions/latest, /api/statistics, /api/context, /api/equity-history (?trader_id=xxx)")
+	log.Printf("  • GET  /health - Ready while the engine keeps publishing")
+	return s.listener.serve(s.router, s.port)
+}
+
+// SetServerOptions sets HTTPS and the connection timeouts (call before Start)
+func (s *ReadServer) SetServerOptions(options config.APIServerConfig) {
+	s.listener.setOptions(options)
+}
+
+// Stop stops accepting requests and lets in-flight ones finish until ctx expires
+func (s *ReadServer) Stop(ctx context.Context) error {
+	return s.listener.stop(ctx)
+}
+
+// ShutdownTimeout how long Stop should be given to drain requests (api_server.shutdown_timeout_seconds)
+func (s *ReadServer) ShutdownTimeout() time.Duration {
+	return s.listener.shutdownTimeout()
+}
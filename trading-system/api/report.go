@@ -0,0 +1,41 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"lia/report"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleReport a trader's live performance as a self-contained HTML page: equity curve, drawdown, per-symbol
+// breakdown and trade table (GET /api/report/:trader_id?limit=2000, limit = latest cycles charted, 0 = all)
+func (s *Server) handleReport(c *gin.Context) {
+	traderID := c.Param("trader_id")
+	at, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 2000
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n >= 0 {
+			limit = n
+		}
+	}
+
+	r, err := report.FromDecisionLog(fmt.Sprintf("Live performance: %s", at.GetName()), at.GetDecisionLogger(), at.GetInitialBalance(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var page bytes.Buffer
+	if err := r.Render(&page); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to render report: %v", err)})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleRisk daily P&L and the daily loss / drawdown stop state of a trader (GET /api/risk?trader_id=xxx)
+func (s *Server) handleRisk(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"risk":      trader.GetRiskStatus(),
+	})
+}
+
+// handleTraderClearRiskStop ends a trader's risk stop so it trades from the next cycle; ?reset_peak=true also
+// rebases the drawdown peak on the current equity (POST /api/traders/:id/risk-stop/clear)
+func (s *Server) handleTraderClearRiskStop(c *gin.Context) {
+	traderID := c.Param("id")
+	if _, err := s.traderManager.GetTrader(traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	resetPeak := c.Query("reset_peak") == "true"
+
+	cleared, err := s.traderManager.ClearTraderRiskStop(traderID, resetPeak)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	message := "risk stop cleared"
+	if !cleared {
+		message = "no risk stop was active"
+	}
+	if resetPeak {
+		message += ", drawdown peak reset"
+	}
+	log.Printf("▶️  API: %s (%s)", message, traderID)
+	c.JSON(http.StatusOK, gin.H{"trader_id": traderID, "cleared": cleared, "peak_reset": resetPeak, "message": message})
+}
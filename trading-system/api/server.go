@@ -1,14 +1,23 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"lia/config"
+	"lia/decision"
 	"lia/logger"
 	"lia/manager"
 	"lia/market"
+	"lia/pool"
 	"lia/trader"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +30,30 @@ type Server struct {
 	router        *gin.Engine
 	traderManager *manager.TraderManager
 	port          int
+
+	// Low-memory mode: bound history loads and stream large responses
+	lowMemory         bool
+	maxHistoryRecords int
+	startTime         time.Time
+
+	// Manual close safeguards (confirm tokens, required reason, force-close loss threshold)
+	closeSafety closeSafety
+
+	// Cached /health readiness report
+	health healthProbe
+
+	// API keys / JWTs and roles (disabled = open API)
+	auth apiAuth
+
+	// HTTP(S) listener with timeouts and graceful shutdown
+	listener httpListener
+
+	// Where traders store their prompt template versions
+	promptVersionsDir string
+
+	// Default buy-and-hold benchmark of /api/performance and its price cache
+	performanceBenchmark string
+	benchmarkPrices      benchmarkPrices
 }
 
 // NewServer creates API server
@@ -44,6 +77,7 @@ func NewServer(traderManager *manager.TraderManager, port int) *Server {
 		router:        router,
 		traderManager: traderManager,
 		port:          port,
+		startTime:     time.Now(),
 	}
 
 	// Setup routes
@@ -52,12 +86,24 @@ func NewServer(traderManager *manager.TraderManager, port int) *Server {
 	return s
 }
 
+// SetPerformanceBenchmark the symbol /api/performance compares traders against by default (config performance_benchmark)
+func (s *Server) SetPerformanceBenchmark(symbol string) {
+	s.performanceBenchmark = symbol
+}
+
+// SetLowMemoryMode bounds history endpoints to maxHistoryRecords and streams large responses
+func (s *Server) SetLowMemoryMode(maxHistoryRecords int) {
+	s.lowMemory = true
+	s.maxHistoryRecords = maxHistoryRecords
+	log.Printf("✓ API server low-memory mode enabled (max history records: %d)", maxHistoryRecords)
+}
+
 // corsMiddleware CORS middleware
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Cache-Control")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Cache-Control")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusOK)
@@ -73,11 +119,23 @@ func (s *Server) setupRoutes() {
 	// Health check
 	s.router.Any("/health", s.handleHealth)
 
-	// API route group
-	api := s.router.Group("/api")
+	// External signal webhook (authenticated by the trader's signal_webhook secret, not api_auth)
+	s.router.POST("/api/signals/:trader_id", s.handleSignal)
+
+	// API route group (authenticated when api_auth is enabled; mutations need the admin role)
+	api := s.router.Group("/api", s.auth.authenticate())
+	admin := s.auth.requireAdmin()
 	{
+		// Detailed health (memory usage, uptime)
+		api.GET("/health/detailed", s.handleHealthDetailed)
+
 		// Competition overview
 		api.GET("/competition", s.handleCompetition)
+		api.GET("/leaderboard", s.handleLeaderboard)
+
+		// Competition seasons
+		api.GET("/seasons", s.handleSeasons)
+		api.GET("/seasons/leaderboard", s.handleSeasonLeaderboard)
 
 		// Portfolio overview (ETF-like aggregated view)
 		api.GET("/portfolio", s.handlePortfolio)
@@ -85,22 +143,61 @@ func (s *Server) setupRoutes() {
 		// Trader list
 		api.GET("/traders", s.handleTraderList)
 
+		// Trader controls (pause/resume scheduled cycles, run a cycle now, clear a risk stop)
+		api.POST("/traders/:id/pause", admin, s.handleTraderPause)
+		api.POST("/traders/:id/resume", admin, s.handleTraderResume)
+		api.POST("/traders/:id/run-cycle", admin, s.handleTraderRunCycle)
+		api.POST("/traders/:id/risk-stop/clear", admin, s.handleTraderClearRiskStop)
+
 		// Trader-specific data (use query parameter ?trader_id=xxx)
 		api.GET("/status", s.handleStatus)
 		api.GET("/account", s.handleAccount)
 
 		// Close position endpoints (must come before GET /positions to avoid route conflicts)
 		// Register POST routes first to ensure they're matched before GET routes
-		api.POST("/positions/close", s.handleClosePosition)
-		api.POST("/positions/force-close", s.handleForceClosePosition)
+		api.POST("/positions/close", admin, s.handleClosePosition)
+		api.POST("/positions/force-close", admin, s.handleForceClosePosition)
+		api.POST("/trade", admin, s.handleManualTrade)
+		api.POST("/emergency/flatten", admin, s.handleEmergencyFlatten)
 
 		// Position endpoints (GET must come after POST to avoid conflicts)
 		api.GET("/positions", s.handlePositions)
 		api.GET("/decisions", s.handleDecisions)
 		api.GET("/decisions/latest", s.handleLatestDecisions)
+		api.GET("/decisions/:cycle/agents", s.handleDecisionAgents)
 		api.GET("/statistics", s.handleStatistics)
+		api.GET("/costs", s.handleCosts)
 		api.GET("/equity-history", s.handleEquityHistory)
+		api.GET("/benchmarks", s.handleBenchmarks)
 		api.GET("/performance", s.handlePerformance)
+		api.GET("/performance/by-symbol", s.handlePerformanceBySymbol)
+		api.GET("/performance/by-hour", s.handlePerformanceByHour)
+		api.GET("/pnl-ledger", s.handlePnLLedger)
+		api.GET("/trades", s.handleTrades)
+		api.GET("/orders", s.handleOrders)
+		api.GET("/ownership", s.handleOwnership)
+		api.GET("/risk", s.handleRisk)
+		api.GET("/shadow", s.handlePaperShadow)
+		api.GET("/report/:trader_id", s.handleReport)
+		api.GET("/rejected-trades", s.handleRejectedTrades)
+
+		// Decision process scores (independent of P&L)
+		api.GET("/decision-quality", s.handleDecisionQuality)
+		api.GET("/context", s.handleContext)
+
+		// Cross-trader symbol entry throttle state
+		api.GET("/symbol-throttle", s.handleSymbolThrottle)
+
+		// Execution audit (decision log vs exchange order history)
+		api.GET("/audit/executions", s.handleAuditExecutions)
+
+		// Prompt template versions (what each decision's prompt_version refers to) and diffs between them
+		api.GET("/prompts/versions", s.handlePromptVersions)
+		api.GET("/prompts/diff", s.handlePromptDiff)
+
+		// News headlines shown to the AI, and ingest of pushed headlines
+		api.GET("/news", s.handleNews)
+		api.POST("/news", admin, s.handleNewsIngest)
 
 		// Trading Signal API - Get latest AI trading signal
 		api.GET("/trading-signal", s.handleTradingSignal)
@@ -116,14 +213,97 @@ func (s *Server) setupRoutes() {
 	})
 }
 
-// handleHealth health check
-func (s *Server) handleHealth(c *gin.Context) {
+// handleHealthDetailed detailed health check including current memory usage
+func (s *Server) handleHealthDetailed(c *gin.Context) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	const mb = 1024 * 1024
+	memory := gin.H{
+		"heap_alloc_mb":  float64(m.HeapAlloc) / mb,
+		"heap_inuse_mb":  float64(m.HeapInuse) / mb,
+		"heap_objects":   m.HeapObjects,
+		"stack_inuse_mb": float64(m.StackInuse) / mb,
+		"sys_mb":         float64(m.Sys) / mb,
+		"num_gc":         m.NumGC,
+		"memory_limit_mb": func() float64 {
+			limit := debug.SetMemoryLimit(-1) // -1 reads the current limit without changing it
+			if limit == math.MaxInt64 {
+				return 0
+			}
+			return float64(limit) / mb
+		}(),
+	}
+	if rss, err := readRSSBytes(); err == nil {
+		memory["rss_mb"] = float64(rss) / mb
+	}
+
+	// Coin pool upstream health (degraded while a source has been stale past the alert threshold)
+	coinPool := pool.GetPoolHealth()
+	status := "ok"
+	if coinPool.AI500.Alerting || coinPool.OITop.Alerting {
+		status = "degraded"
+	}
+	for _, source := range coinPool.Custom {
+		if source.Alerting {
+			status = "degraded"
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-		"time":   c.Request.Context().Value("time"),
+		"status":              status,
+		"uptime_seconds":      int(time.Since(s.startTime).Seconds()),
+		"goroutines":          runtime.NumGoroutine(),
+		"trader_count":        len(s.traderManager.GetTraderIDs()),
+		"low_memory_mode":     s.lowMemory,
+		"max_history_records": s.maxHistoryRecords,
+		"memory":              memory,
+		"coin_pool":           coinPool,
 	})
 }
 
+// readRSSBytes reads resident set size from /proc (Linux only)
+func readRSSBytes() (uint64, error) {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "VmRSS:"))
+		if len(fields) == 0 {
+			break
+		}
+		kb, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	return 0, fmt.Errorf("VmRSS not found")
+}
+
+// streamJSONArray writes a JSON array element by element instead of marshaling it in one buffer
+func streamJSONArray(c *gin.Context, n int, item func(i int) interface{}) {
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	w := c.Writer
+	enc := json.NewEncoder(w)
+	w.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		if err := enc.Encode(item(i)); err != nil {
+			log.Printf("⚠️  Failed to stream JSON element %d: %v", i, err)
+			break
+		}
+	}
+	w.WriteString("]")
+}
+
 // getTraderFromQuery gets trader from query parameter
 func (s *Server) getTraderFromQuery(c *gin.Context) (*manager.TraderManager, string, error) {
 	traderID := c.Query("trader_id")
@@ -150,6 +330,21 @@ func (s *Server) handleCompetition(c *gin.Context) {
 	c.JSON(http.StatusOK, comparison)
 }
 
+// handleSeasons lists competition seasons and their status
+func (s *Server) handleSeasons(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"seasons": s.traderManager.GetSeasons()})
+}
+
+// handleSeasonLeaderboard seasonal leaderboard (?season_id=, default: running season, else the latest)
+func (s *Server) handleSeasonLeaderboard(c *gin.Context) {
+	board, err := s.traderManager.GetSeasonLeaderboard(c.Query("season_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, board)
+}
+
 // handlePortfolio portfolio overview (ETF-like aggregated view of all traders)
 func (s *Server) handlePortfolio(c *gin.Context) {
 	traders := s.traderManager.GetAllTraders()
@@ -256,9 +451,21 @@ func (s *Server) handlePortfolio(c *gin.Context) {
 
 		var equity, pnl, pnlPct float64
 		var positionCount int
+		attribution := "own_account"
 
-		// If multiple traders share same account, split proportionally
-		if hasSharedAccount && totalInitialBalance > 0 {
+		// Traders sharing an exchange account: value the positions each one owns (ownership ledger)
+		ownership, _ := t.GetOwnership()
+		if ownership != nil && len(ownership.SharedWith) > 0 {
+			attribution = "ownership"
+			equity = initialBalance + ownership.RealizedPnL - ownership.Fees + ownership.UnrealizedPnL
+			pnl = equity - initialBalance
+			if initialBalance > 0 {
+				pnlPct = (pnl / initialBalance) * 100
+			}
+			positionCount = len(ownership.Positions)
+		} else if hasSharedAccount && totalInitialBalance > 0 {
+			// If multiple traders share same account, split proportionally
+			attribution = "proportional"
 			// Calculate this trader's proportional share
 			proportion := initialBalance / totalInitialBalance
 			equity = sharedAccountEquity * proportion
@@ -302,6 +509,7 @@ func (s *Server) handlePortfolio(c *gin.Context) {
 			"pnl_pct":         pnlPct,
 			"position_count":  positionCount,
 			"is_running":      status["is_running"],
+			"attribution":     attribution,
 		})
 
 		if isRunning, ok := status["is_running"].(bool); ok && !isRunning {
@@ -500,15 +708,33 @@ func (s *Server) handleDecisions(c *gin.Context) {
 		return
 	}
 
-	// Get all historical decision records (unlimited, using GetAllRecords)
-	records, err := trader.GetDecisionLogger().GetAllRecords()
+	// One page of records, newest first (returned oldest first), without prompts unless ?fields= asks for them
+	query, err := parseDecisionPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if s.lowMemory && s.maxHistoryRecords > 0 {
+		query.Limit = min(query.Limit, s.maxHistoryRecords)
+	}
+	page, err := trader.GetDecisionLogger().GetDecisionPage(query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": fmt.Sprintf("failed to get decision logs: %v", err),
 		})
 		return
 	}
+	writePageHeaders(c, page)
 
+	records := page.Records
+	if records == nil {
+		records = []*logger.DecisionRecord{}
+	}
+	// Low-memory mode: stream the records out instead of buffering the whole response
+	if s.lowMemory {
+		streamJSONArray(c, len(records), func(i int) interface{} { return records[i] })
+		return
+	}
 	c.JSON(http.StatusOK, records)
 }
 
@@ -578,25 +804,44 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 
 	trader, err := s.traderManager.GetTrader(traderID)
 	if err != nil {
+		// Imported benchmarks share the trader ID namespace
+		if b, ok := s.traderManager.GetBenchmark(traderID); ok {
+			s.handleBenchmarkEquity(c, b)
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Fixed-schedule snapshots instead of the per-cycle records
+	if c.Query("source") == "snapshots" {
+		s.handleEquitySnapshots(c, trader)
+		return
+	}
+
 	// Get historical data - limit to recent 2000 records for performance
 	// This is enough for chart display and much faster than getting all records
 	// If you need more, use startCycle parameter to fetch specific ranges
-	records, err := trader.GetDecisionLogger().GetLatestRecords(2000)
+	historyLimit := 2000
+	if s.lowMemory && s.maxHistoryRecords > 0 {
+		historyLimit = s.maxHistoryRecords
+	}
+	// Optional downsampling: one point per ?interval= (e.g. 15m)
+	interval, err := parseHistoryInterval(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Chronological (oldest to newest), without the prompts the chart does not need
+	page, err := trader.GetDecisionLogger().GetDecisionPage(logger.DecisionPageQuery{Limit: historyLimit})
 	if err != nil {
 		log.Printf("❌ Failed to get records for equity history: %v", err)
 		// Return empty array instead of error to prevent 500 errors
 		c.JSON(http.StatusOK, []interface{}{})
 		return
 	}
-
-	// Reverse to get chronological order (oldest to newest)
-	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
-		records[i], records[j] = records[j], records[i]
-	}
+	records := page.Records
 
 	// Check for startCycle query parameter to filter data from a specific cycle
 	startCycleStr := c.Query("startCycle")
@@ -629,6 +874,25 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		log.Printf("📊 Filtered equity history: starting from cycle #%d, %d records found", startCycle, len(records))
 	}
 
+	// Optional what-if curve: the real equity shifted by an alternative auto-close threshold's P&L difference
+	variant := c.Query("variant")
+	whatIf := trader.GetAutoCloseWhatIf()
+	if variant != "" {
+		if whatIf == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "auto-close what-if curves are disabled (set auto_close_what_if.enabled)"})
+			return
+		}
+		key, ok := whatIf.ResolveVariant(variant)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    fmt.Sprintf("unknown variant '%s'", variant),
+				"variants": whatIf.Variants(),
+			})
+			return
+		}
+		variant = key
+	}
+
 	// Build equity history data points
 	type EquityPoint struct {
 		Timestamp        string  `json:"timestamp"`
@@ -638,7 +902,10 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		TotalPnLPct      float64 `json:"total_pnl_pct"`     // Total P&L percentage
 		PositionCount    int     `json:"position_count"`    // Position count
 		MarginUsedPct    float64 `json:"margin_used_pct"`   // Margin usage rate
+		RealizedPnL      float64 `json:"realized_pnl"`      // Banked P&L since baseline (wallet change)
+		UnrealizedPnL    float64 `json:"unrealized_pnl"`    // Open position P&L at this point
 		CycleNumber      int     `json:"cycle_number"`
+		VariantDelta     float64 `json:"variant_delta,omitempty"` // ?variant=: P&L difference of the alternative threshold (included above)
 	}
 
 	// Determine initial balance for calculating P&L percentage
@@ -647,6 +914,7 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 	// 2. If cycle #1 exists, use it as initial balance
 	// 3. Otherwise use earliest record as baseline (so chart starts from 0%)
 	initialBalance := 0.0
+	baselineUnrealized := 0.0 // Unrealized P&L inside the baseline equity (not yet banked at baseline)
 	useEarliestAsBaseline := false
 
 	if len(records) == 0 {
@@ -660,6 +928,7 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		// First record should be the specified startCycle (since we filtered)
 		if len(records) > 0 && records[0].CycleNumber >= startCycle {
 			initialBalance = records[0].AccountState.TotalBalance
+			baselineUnrealized = records[0].AccountState.TotalUnrealizedProfit
 			useEarliestAsBaseline = true
 			log.Printf("📊 Using startCycle #%d (equity: %.2f USDT) as baseline - chart will start at 0%% from this point",
 				records[0].CycleNumber, initialBalance)
@@ -671,6 +940,7 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		if err == nil && firstRecord != nil && firstRecord.CycleNumber == 1 {
 			// We have cycle #1, use it as initial balance
 			initialBalance = firstRecord.AccountState.TotalBalance
+			baselineUnrealized = firstRecord.AccountState.TotalUnrealizedProfit
 			if initialBalance > 0 {
 				log.Printf("📊 Using cycle #1 as baseline: %.2f USDT", initialBalance)
 			}
@@ -681,6 +951,7 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 			// Use earliest record's equity as baseline
 			earliestRecord := records[0] // GetAllRecords returns sorted oldest to newest
 			initialBalance = earliestRecord.AccountState.TotalBalance
+			baselineUnrealized = earliestRecord.AccountState.TotalUnrealizedProfit
 			useEarliestAsBaseline = true
 			if initialBalance > 0 {
 				log.Printf("📊 No cycle #1 found, using earliest record (cycle #%d) as baseline: %.2f USDT",
@@ -707,6 +978,8 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		return
 	}
 
+	records = downsampleRecords(records, interval)
+
 	var history []EquityPoint
 	for _, record := range records {
 		// TotalBalance field actually stores TotalEquity
@@ -727,6 +1000,19 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 			log.Printf("📊 Setting first data point to 0%% PnL (earliest record as baseline)")
 		}
 
+		// What-if variant: shift by the alternative threshold's difference at this cycle
+		variantDelta := 0.0
+		if variant != "" {
+			variantDelta = whatIf.DeltaAt(variant, record.Timestamp)
+			totalEquity += variantDelta
+			totalPnL += variantDelta
+			totalPnLPct = (totalPnL / initialBalance) * 100
+		}
+
+		// Realized = P&L minus the change in open position P&L since the baseline
+		unrealizedPnL := record.AccountState.TotalUnrealizedProfit
+		realizedPnL := totalPnL - (unrealizedPnL - baselineUnrealized)
+
 		history = append(history, EquityPoint{
 			Timestamp:        record.Timestamp.Format("2006-01-02 15:04:05"),
 			TotalEquity:      totalEquity,
@@ -735,7 +1021,10 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 			TotalPnLPct:      totalPnLPct,
 			PositionCount:    record.AccountState.PositionCount,
 			MarginUsedPct:    record.AccountState.MarginUsedPct,
+			RealizedPnL:      realizedPnL,
+			UnrealizedPnL:    unrealizedPnL,
 			CycleNumber:      record.CycleNumber,
+			VariantDelta:     variantDelta,
 		})
 	}
 
@@ -749,6 +1038,7 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 		availableBalance, _ := currentAccount["available_balance"].(float64)
 		positionCount, _ := currentAccount["position_count"].(int)
 		marginUsedPct, _ := currentAccount["margin_used_pct"].(float64)
+		unrealizedPnL, _ := currentAccount["unrealized_pnl"].(float64)
 
 		// Calculate PnL relative to the baseline we're using for historical data
 		// This ensures consistency throughout the chart
@@ -771,6 +1061,16 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 			// Otherwise, we've already calculated using the correct baseline above
 		}
 
+		variantDelta := 0.0
+		if variant != "" {
+			variantDelta = whatIf.Current()[variant]
+			totalEquity += variantDelta
+			totalPnL += variantDelta
+			totalPnLPct = (totalPnL / initialBalance) * 100
+		}
+
+		realizedPnL := totalPnL - (unrealizedPnL - baselineUnrealized)
+
 		// Always remove any existing real-time points first to ensure only one real-time point
 		// Filter out real-time points (cycle 0)
 		filteredHistory := []EquityPoint{}
@@ -792,10 +1092,17 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 			TotalPnLPct:      totalPnLPct,
 			PositionCount:    positionCount,
 			MarginUsedPct:    marginUsedPct,
+			RealizedPnL:      realizedPnL,
+			UnrealizedPnL:    unrealizedPnL,
 			CycleNumber:      0, // 0 indicates real-time data point
+			VariantDelta:     variantDelta,
 		})
 	}
 
+	if s.lowMemory {
+		streamJSONArray(c, len(history), func(i int) interface{} { return history[i] })
+		return
+	}
 	c.JSON(http.StatusOK, history)
 }
 
@@ -823,9 +1130,207 @@ func (s *Server) handlePerformance(c *gin.Context) {
 		return
 	}
 
+	// Alpha vs buying and holding the benchmark over the same window (?benchmark=ETHUSDT, none = skip)
+	benchmarkSymbol := c.DefaultQuery("benchmark", s.performanceBenchmark)
+	if benchmarkSymbol != "" && benchmarkSymbol != "none" {
+		if err := performance.CompareToBenchmark(market.Normalize(benchmarkSymbol), s.benchmarkPrices.priceAt); err != nil {
+			log.Printf("⚠️  [%s] Benchmark comparison skipped: %v", traderID, err)
+		}
+	}
+
 	c.JSON(http.StatusOK, performance)
 }
 
+// handlePnLLedger realized P&L ledger (totals by source + recent close/fee/funding events)
+func (s *Server) handlePnLLedger(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	ledger := trader.GetPnLLedger()
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"summary":   ledger.Summary(),
+		"events":    ledger.RecentEvents(limit),
+	})
+}
+
+// handleRejectedTrades decisions rejected by validation and their simulated outcome
+// Query: trader_id, days (summary window, default 7), limit (default 50)
+func (s *Server) handleRejectedTrades(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	days := 7
+	if daysStr := c.Query("days"); daysStr != "" {
+		if n, err := strconv.Atoi(daysStr); err == nil && n > 0 {
+			days = n
+		}
+	}
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	simulator := trader.GetRejectedTrades()
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"summary":   simulator.Summary(time.Duration(days) * 24 * time.Hour),
+		"trades":    simulator.Trades(limit),
+	})
+}
+
+// handleDecisionQuality process scores of closed trades and their aggregate (?trader_id=&days=&limit=)
+func (s *Server) handleDecisionQuality(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	days := 0 // All scored trades
+	if daysStr := c.Query("days"); daysStr != "" {
+		if n, err := strconv.Atoi(daysStr); err == nil && n > 0 {
+			days = n
+		}
+	}
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	quality := trader.GetDecisionQuality()
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"summary":   quality.Summary(time.Duration(days) * 24 * time.Hour),
+		"trades":    quality.Scores(limit),
+	})
+}
+
+// handleContext the trading context of the trader's latest AI decision: account, positions, candidates and market breadth
+func (s *Server) handleContext(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := trader.GetLastContext()
+	if ctx == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no decision cycle has run yet"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"context":   ctx,
+	})
+}
+
+// handleAuditExecutions reconciles logged decision actions with the exchange's order history
+// Query: trader_id, start/end (RFC3339 or unix milliseconds, default last 24h), symbols (comma-separated extras)
+func (s *Server) handleAuditExecutions(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	at, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	end := time.Now()
+	if endStr := c.Query("end"); endStr != "" {
+		if end, err = parseQueryTime(endStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid end: %v", err)})
+			return
+		}
+	}
+	start := end.Add(-24 * time.Hour)
+	if startStr := c.Query("start"); startStr != "" {
+		if start, err = parseQueryTime(startStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid start: %v", err)})
+			return
+		}
+	}
+
+	var symbols []string
+	if symbolsStr := c.Query("symbols"); symbolsStr != "" {
+		symbols = strings.Split(symbolsStr, ",")
+	}
+
+	audit, err := at.AuditExecutions(start, end, symbols)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, trader.ErrOrderHistoryUnsupported) {
+			status = http.StatusNotImplemented
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, audit)
+}
+
+// parseQueryTime parses an RFC3339 timestamp or unix milliseconds
+func parseQueryTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// handleSymbolThrottle current per-symbol entry throttle state shared by all traders
+func (s *Server) handleSymbolThrottle(c *gin.Context) {
+	state, enabled := s.traderManager.GetSymbolThrottleState()
+	if state == nil {
+		state = []decision.SymbolThrottleInfo{}
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": enabled, "symbols": state})
+}
+
 // handleTradingSignal get latest trading signal (AI chain of thought and trading decisions)
 func (s *Server) handleTradingSignal(c *gin.Context) {
 	// Supports query by model or trader_id
@@ -948,7 +1453,7 @@ func (s *Server) handleTradingSignal(c *gin.Context) {
 }
 
 // logManualClose logs a manually closed position to the decision logger
-func (s *Server) logManualClose(traderInstance *trader.AutoTrader, symbol, side string, closePrice float64, positionInfo map[string]interface{}) {
+func (s *Server) logManualClose(traderInstance *trader.AutoTrader, symbol, side string, closePrice float64, positionInfo *trader.Position, reason string) {
 	decisionLogger := traderInstance.GetDecisionLogger()
 	if decisionLogger == nil {
 		log.Printf("⚠️  Cannot log manual close: decision logger not available")
@@ -967,20 +1472,13 @@ func (s *Server) logManualClose(traderInstance *trader.AutoTrader, symbol, side
 	quantity := 0.0
 	leverage := 0
 	if positionInfo != nil {
-		if qty, ok := positionInfo["positionAmt"].(float64); ok {
-			if qty < 0 {
-				qty = -qty // Short position quantity is negative
-			}
-			quantity = qty
-		}
-		if lev, ok := positionInfo["leverage"].(float64); ok {
-			leverage = int(lev)
-		}
+		quantity = positionInfo.Quantity
+		leverage = positionInfo.Leverage
 	}
 
 	// Create close action
 	// Note: Quantity and leverage may be 0 if not available from position info,
-	// but AnalyzePerformance will match this close with the open action from history
+	// but the trade journal matches this close with the open trade and closes its remaining quantity
 	action := logger.DecisionAction{
 		Action:    fmt.Sprintf("close_%s", side),
 		Symbol:    symbol,
@@ -1015,9 +1513,16 @@ func (s *Server) logManualClose(traderInstance *trader.AutoTrader, symbol, side
 		marginUsedPct = mup
 	}
 
+	cotTrace := "Manual position close by user"
+	executionLog := []string{fmt.Sprintf("Manually closed %s %s position at %.4f", symbol, side, closePrice)}
+	if reason != "" {
+		cotTrace = fmt.Sprintf("Manual position close by user - reason: %s", reason)
+		executionLog = append(executionLog, fmt.Sprintf("Reason: %s", reason))
+	}
+
 	record := &logger.DecisionRecord{
 		InputPrompt:  fmt.Sprintf("Manual close: %s %s at %.4f", symbol, side, closePrice),
-		CoTTrace:     "Manual position close by user",
+		CoTTrace:     cotTrace,
 		DecisionJSON: "{}",
 		RawResponse:  "",
 		AccountState: logger.AccountSnapshot{
@@ -1030,7 +1535,7 @@ func (s *Server) logManualClose(traderInstance *trader.AutoTrader, symbol, side
 		Positions:      []logger.PositionSnapshot{},
 		CandidateCoins: []string{},
 		Decisions:      []logger.DecisionAction{action},
-		ExecutionLog:   []string{fmt.Sprintf("Manually closed %s %s position at %.4f", symbol, side, closePrice)},
+		ExecutionLog:   executionLog,
 		Success:        true,
 		ErrorMessage:   "",
 	}
@@ -1075,8 +1580,10 @@ func (s *Server) handleClosePosition(c *gin.Context) {
 
 	// Parse request body
 	var req struct {
-		Symbol string `json:"symbol" binding:"required"`
-		Side   string `json:"side" binding:"required"`
+		Symbol       string `json:"symbol" binding:"required"`
+		Side         string `json:"side" binding:"required"`
+		Reason       string `json:"reason"`        // Why the position is closed manually (stored in the decision log)
+		ConfirmToken string `json:"confirm_token"` // Second step of a confirmed close
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1105,17 +1612,15 @@ func (s *Server) handleClosePosition(c *gin.Context) {
 	traderInterface := traderInstance.GetTrader()
 
 	// Get position info BEFORE closing (for logging and P&L check)
-	var positionInfo map[string]interface{}
+	var positionInfo *trader.Position
 	positions, err := traderInterface.GetPositions()
 	if err == nil {
 		for _, pos := range positions {
-			posSymbol, _ := pos["symbol"].(string)
-			posSide, _ := pos["side"].(string)
-			if posSymbol == req.Symbol && strings.ToLower(posSide) == req.Side {
-				positionInfo = pos
+			if pos.Symbol == req.Symbol && pos.Side == req.Side {
+				positionInfo = &pos
 				
 				// Check if position is losing money - prevent closing losing positions
-				unrealizedPnl, _ := pos["unRealizedProfit"].(float64)
+				unrealizedPnl := pos.UnrealizedProfit
 				if unrealizedPnl < 0 {
 					log.Printf("⚠️ Position %s %s has negative P&L (%.2f USDT) - cannot close losing positions", req.Symbol, req.Side, unrealizedPnl)
 					c.JSON(http.StatusBadRequest, gin.H{
@@ -1129,6 +1634,11 @@ func (s *Server) handleClosePosition(c *gin.Context) {
 		}
 	}
 
+	intent := &closeIntent{Endpoint: "close", TraderID: traderID, Symbol: req.Symbol, Side: req.Side, Reason: req.Reason}
+	if !s.authorizeClose(c, intent, req.ConfirmToken) {
+		return
+	}
+
 	// Get current market price for logging
 	closePrice := 0.0
 	if marketData, err := market.Get(req.Symbol); err == nil {
@@ -1136,7 +1646,7 @@ func (s *Server) handleClosePosition(c *gin.Context) {
 	}
 
 	// Close position (quantity=0 means close all)
-	var result map[string]interface{}
+	var result *trader.Order
 	if req.Side == "long" {
 		result, err = traderInterface.CloseLong(req.Symbol, 0)
 	} else {
@@ -1153,16 +1663,12 @@ func (s *Server) handleClosePosition(c *gin.Context) {
 
 	// If close price wasn't retrieved from market, try to get it from result
 	if closePrice == 0 {
-		if price, ok := result["price"].(float64); ok {
-			closePrice = price
-		} else if avgPrice, ok := result["avgPrice"].(float64); ok {
-			closePrice = avgPrice
-		}
+		closePrice = result.Price
 	}
 
 	// Log the manual close
 	if closePrice > 0 {
-		s.logManualClose(traderInstance, req.Symbol, req.Side, closePrice, positionInfo)
+		s.logManualClose(traderInstance, req.Symbol, req.Side, closePrice, positionInfo, intent.Reason)
 	}
 
 	log.Printf("✓ Successfully closed position: %s %s [%s]", req.Symbol, req.Side, traderInstance.GetName())
@@ -1190,9 +1696,11 @@ func (s *Server) handleForceClosePosition(c *gin.Context) {
 
 	// Parse request body
 	var req struct {
-		Symbol   string  `json:"symbol" binding:"required"`
-		Side     string  `json:"side" binding:"required"`
-		Quantity float64 `json:"quantity"` // Optional: if 0, close all
+		Symbol       string  `json:"symbol" binding:"required"`
+		Side         string  `json:"side" binding:"required"`
+		Quantity     float64 `json:"quantity"`      // Optional: if 0, close all
+		Reason       string  `json:"reason"`        // Why the position is force-closed (stored in the decision log)
+		ConfirmToken string  `json:"confirm_token"` // Second step of a confirmed close
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1216,19 +1724,31 @@ func (s *Server) handleForceClosePosition(c *gin.Context) {
 	traderInterface := traderInstance.GetTrader()
 
 	// Get position info BEFORE closing (for logging and P&L check)
-	var positionInfo map[string]interface{}
+	var positionInfo *trader.Position
 	positions, err := traderInterface.GetPositions()
 	if err == nil {
 		log.Printf("📊 Current positions before force-close (%d total):", len(positions))
 		for i, pos := range positions {
-			log.Printf("  Position %d: %s %s (amt: %.8f)", i+1, pos["symbol"], pos["side"], pos["positionAmt"])
-			posSymbol, _ := pos["symbol"].(string)
-			posSide, _ := pos["side"].(string)
-			if posSymbol == req.Symbol && strings.ToLower(posSide) == req.Side {
-				positionInfo = pos
-				
+			log.Printf("  Position %d: %s %s (amt: %.8f)", i+1, pos.Symbol, pos.Side, pos.Quantity)
+			if pos.Symbol == req.Symbol && pos.Side == req.Side {
+				positionInfo = &pos
+
+				// Loss threshold configured: force-close is reserved for positions losing at least that much
+				if minLossPct := s.forceCloseMinLossPct(); minLossPct > 0 {
+					lossPct := positionLossPct(pos)
+					if lossPct < minLossPct {
+						log.Printf("⚠️ Position %s %s loss %.2f%% is below the force-close threshold %.2f%% - rejecting", req.Symbol, req.Side, lossPct, minLossPct)
+						c.JSON(http.StatusBadRequest, gin.H{
+							"error": fmt.Sprintf("force-close is restricted to positions losing at least %.2f%% of margin (current loss: %.2f%%). Use /api/positions/close for other positions.", minLossPct, lossPct),
+						})
+						return
+					}
+					log.Printf("✓ Position %s %s loss %.2f%% meets the force-close threshold %.2f%% - force-closing", req.Symbol, req.Side, lossPct, minLossPct)
+					continue
+				}
+
 				// Check if position is losing money - prevent closing losing positions
-				unrealizedPnl, _ := pos["unRealizedProfit"].(float64)
+				unrealizedPnl := pos.UnrealizedProfit
 				if unrealizedPnl < 0 {
 					log.Printf("⚠️ Position %s %s has negative P&L (%.2f USDT) - cannot force-close losing positions", req.Symbol, req.Side, unrealizedPnl)
 					c.JSON(http.StatusBadRequest, gin.H{
@@ -1241,6 +1761,19 @@ func (s *Server) handleForceClosePosition(c *gin.Context) {
 		}
 	}
 
+	// The loss threshold can only be enforced against a position we could see
+	if positionInfo == nil && s.forceCloseMinLossPct() > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("no %s position found for %s - cannot verify the force-close loss threshold", req.Side, req.Symbol),
+		})
+		return
+	}
+
+	intent := &closeIntent{Endpoint: "force-close", TraderID: traderID, Symbol: req.Symbol, Side: req.Side, Quantity: req.Quantity, Reason: req.Reason}
+	if !s.authorizeClose(c, intent, req.ConfirmToken) {
+		return
+	}
+
 	// Get current market price for logging
 	closePrice := 0.0
 	if marketData, err := market.Get(req.Symbol); err == nil {
@@ -1248,7 +1781,7 @@ func (s *Server) handleForceClosePosition(c *gin.Context) {
 	}
 
 	// Close position
-	var result map[string]interface{}
+	var result *trader.Order
 	quantity := req.Quantity
 	if quantity == 0 {
 		quantity = 0 // Close all
@@ -1270,16 +1803,12 @@ func (s *Server) handleForceClosePosition(c *gin.Context) {
 
 	// If close price wasn't retrieved from market, try to get it from result
 	if closePrice == 0 {
-		if price, ok := result["price"].(float64); ok {
-			closePrice = price
-		} else if avgPrice, ok := result["avgPrice"].(float64); ok {
-			closePrice = avgPrice
-		}
+		closePrice = result.Price
 	}
 
 	// Log the manual close
 	if closePrice > 0 {
-		s.logManualClose(traderInstance, req.Symbol, req.Side, closePrice, positionInfo)
+		s.logManualClose(traderInstance, req.Symbol, req.Side, closePrice, positionInfo, intent.Reason)
 	}
 
 	log.Printf("✓ Successfully force-closed position: %s %s [%s]", req.Symbol, req.Side, traderInstance.GetName())
@@ -1294,24 +1823,65 @@ func (s *Server) handleForceClosePosition(c *gin.Context) {
 // Start starts the server
 func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.port)
-	log.Printf("🌐 API server started at http://localhost%s", addr)
+	log.Printf("🌐 API server started at %s://localhost%s", s.listener.scheme(), addr)
 	log.Printf("📊 API Documentation:")
 	log.Printf("  • GET  /api/competition      - Competition overview (compare all traders)")
+	log.Printf("  • GET  /api/leaderboard?window=7d - Traders ranked by P&L %%, Sharpe and trades over 24h/7d/30d/all or since_cycle=N")
 	log.Printf("  • GET  /api/traders          - Trader list")
 	log.Printf("  • GET  /api/status?trader_id=xxx     - Get specific trader's system status")
 	log.Printf("  • GET  /api/account?trader_id=xxx    - Get specific trader's account info")
 	log.Printf("  • GET  /api/positions?trader_id=xxx  - Get specific trader's position list")
-	log.Printf("  • GET  /api/decisions?trader_id=xxx  - Get specific trader's decision logs")
+	log.Printf("  • GET  /api/decisions?trader_id=xxx&limit=100&cursor=... - Page of decision logs (newest first, no prompts unless &fields=all)")
 	log.Printf("  • GET  /api/decisions/latest?trader_id=xxx - Get specific trader's latest decision")
+	log.Printf("  • GET  /api/decisions/:cycle/agents?trader_id=xxx - Multi-agent proposals, votes and consensus of a cycle")
 	log.Printf("  • GET  /api/statistics?trader_id=xxx - Get specific trader's statistics")
 	log.Printf("  • GET  /api/equity-history?trader_id=xxx - Get specific trader's equity history")
+	log.Printf("  • GET  /api/equity-history?trader_id=xxx&interval=15m - Equity history downsampled to one point per interval")
+	log.Printf("  • GET  /api/equity-history?trader_id=xxx&variant=3 - What-if equity curve for an alternative auto-close threshold")
+	log.Printf("  • GET  /api/equity-history?trader_id=xxx&source=snapshots - Equity curve from the fixed-schedule snapshots")
+	log.Printf("  • GET  /api/benchmarks - Imported benchmark trade histories (their IDs work as trader_id in /api/equity-history)")
 	log.Printf("  • GET  /api/performance?trader_id=xxx - Get specific trader's AI learning performance")
+	log.Printf("  • GET  /api/performance/by-symbol?trader_id=xxx - Closed-trade P&L by symbol and side")
+	log.Printf("  • GET  /api/performance/by-hour?trader_id=xxx&tz=Asia/Singapore - Closed-trade P&L by hour of day and holding duration")
+	log.Printf("  • GET  /api/pnl-ledger?trader_id=xxx - Get specific trader's realized P&L ledger")
+	log.Printf("  • GET  /api/rejected-trades?trader_id=xxx - Rejected decisions and their simulated outcome")
+	log.Printf("  • GET  /api/decision-quality?trader_id=xxx - Decision process scores (independent of P&L)")
+	log.Printf("  • GET  /api/context?trader_id=xxx - Latest decision context (account, positions, candidates, market breadth)")
+	log.Printf("  • GET  /api/symbol-throttle      - Cross-trader per-symbol entry throttle state")
+	log.Printf("  • GET  /api/seasons              - Competition seasons")
+	log.Printf("  • GET  /api/seasons/leaderboard?season_id=xxx - Seasonal leaderboard (equity change since season start)")
+	log.Printf("  • GET  /api/audit/executions?trader_id=xxx&start=&end= - Reconcile logged decisions with exchange orders")
+	log.Printf("  • GET  /api/orders?trader_id=xxx&unsettled=true - Submitted orders with status and fills")
+	log.Printf("  • GET  /api/ownership?trader_id=xxx - Positions, margin and P&L this trader owns on a shared account")
+	log.Printf("  • GET  /api/shadow?trader_id=xxx - Live vs paper shadow fills, fees and equity (paper_shadow)")
+	log.Printf("  • GET  /api/report/:trader_id    - HTML performance report (equity curve, drawdown, per-symbol breakdown, trades)")
+	log.Printf("  • GET  /api/news?symbol=xxx      - Headlines in the news feed (relevant to symbol)")
+	log.Printf("  • POST /api/news                 - Push headlines into the news feed (body: {headlines: [{title, source?, url?, published_at?, symbols?, sentiment?, important?}]})")
+	log.Printf("  • POST /api/signals/:trader_id   - External signal webhook (signed with the trader's signal_webhook secret)")
+	log.Printf("  • POST /api/trade                - Manual open/close decisions for a trader (validated, recorded with source=manual)")
+	log.Printf("  • POST /api/emergency/flatten    - Kill switch: pause all traders, cancel orders, close every position")
 	log.Printf("  • GET  /api/trading-signal?model=xxx - Get latest trading signal by AI model")
 	log.Printf("  • GET  /api/trading-signal?trader_id=xxx - Get latest trading signal by trader ID")
-	log.Printf("  • POST /api/positions/close?trader_id=xxx - Close a position (body: {symbol, side})")
-	log.Printf("  • POST /api/positions/force-close?trader_id=xxx - Force close a position (body: {symbol, side, quantity?})")
-	log.Printf("  • GET  /health               - Health check")
+	log.Printf("  • POST /api/positions/close?trader_id=xxx - Close a position (body: {symbol, side, reason?, confirm_token?})")
+	log.Printf("  • POST /api/positions/force-close?trader_id=xxx - Force close a position (body: {symbol, side, quantity?, reason?, confirm_token?})")
+	log.Printf("  • GET  /health               - Readiness probe (dependency status, 503 when not ready)")
+	log.Printf("  • GET  /api/health/detailed  - Detailed health check (memory usage, uptime)")
 	log.Println()
 
-	return s.router.Run(addr)
+	return s.listener.serve(s.router, s.port)
+}
+
+// SetServerOptions sets HTTPS and the connection timeouts (call before Start)
+func (s *Server) SetServerOptions(options config.APIServerConfig) {
+	s.listener.setOptions(options)
+}
+
+// Stop stops accepting requests and lets in-flight ones finish until ctx expires
+func (s *Server) Stop(ctx context.Context) error {
+	return s.listener.stop(ctx)
+}
+
+// ShutdownTimeout how long Stop should be given to drain requests (api_server.shutdown_timeout_seconds)
+func (s *Server) ShutdownTimeout() time.Duration {
+	return s.listener.shutdownTimeout()
 }
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"lia/trader"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handlePaperShadow live vs paper execution of a trader with paper_shadow: summary, equity curves, fills and
+// paper positions (GET /api/shadow?trader_id=xxx&limit=100)
+func (s *Server) handlePaperShadow(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	at, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	shadow := at.GetPaperShadow()
+	if shadow == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "paper_shadow is not enabled for this trader"})
+		return
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	positions, err := shadow.PaperPositions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if positions == nil {
+		positions = []trader.Position{}
+	}
+	curve, fills := shadow.History(limit)
+	if curve == nil {
+		curve = []trader.ShadowPoint{}
+	}
+	if fills == nil {
+		fills = []trader.ShadowFill{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id":       traderID,
+		"summary":         shadow.Summary(), // null until the first cycle starts the paper account
+		"curve":           curve,
+		"fills":           fills,
+		"paper_positions": positions,
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"lia/trader"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSignalBodyBytes limit of a signal webhook body
+const maxSignalBodyBytes = 64 << 10
+
+// handleSignal queues an external signal for a trader (POST /api/signals/:trader_id). The route is outside the
+// API authentication (TradingView cannot send credentials); the trader's signal_webhook secret authenticates it
+func (s *Server) handleSignal(c *gin.Context) {
+	traderID := c.Param("trader_id")
+	if _, err := s.traderManager.GetTrader(traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignalBodyBytes))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "signal body too large or unreadable"})
+		return
+	}
+
+	signal, err := s.traderManager.SubmitSignal(traderID, body, c.GetHeader("X-Signature"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, trader.ErrSignalsDisabled):
+			status = http.StatusNotFound
+		case errors.Is(err, trader.ErrSignalUnauthorized):
+			status = http.StatusUnauthorized
+		case errors.Is(err, trader.ErrInvalidSignal):
+			status = http.StatusBadRequest
+		case errors.Is(err, trader.ErrSignalQueueFull):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, trader.ErrTraderNotRunning), errors.Is(err, trader.ErrTraderPaused), errors.Is(err, trader.ErrSignalsInSimulation):
+			status = http.StatusConflict
+		}
+		if status == http.StatusUnauthorized {
+			log.Printf("⚠️  API: rejected unauthenticated signal for %s from %s", traderID, c.ClientIP())
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("📡 API: signal %s queued (%s, %d decisions)", signal.ID, traderID, len(signal.Decisions))
+	c.JSON(http.StatusAccepted, gin.H{"trader_id": traderID, "signal_id": signal.ID, "queued": true, "decisions": len(signal.Decisions)})
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/bus"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const stateStoreSaveInterval = 30 * time.Second // Snapshot file is rewritten at most this often
+
+// storedSnapshot the latest data received for a topic
+type storedSnapshot struct {
+	Data json.RawMessage `json:"data"`
+	Time time.Time       `json:"time"` // When the engine published it
+}
+
+// storedEquityPoint an equity history point built from published account snapshots
+type storedEquityPoint struct {
+	Timestamp        string  `json:"timestamp"`
+	TotalEquity      float64 `json:"total_equity"`
+	AvailableBalance float64 `json:"available_balance"`
+	TotalPnL         float64 `json:"total_pnl"`
+	TotalPnLPct      float64 `json:"total_pnl_pct"`
+	PositionCount    int     `json:"position_count"`
+	MarginUsedPct    float64 `json:"margin_used_pct"`
+	UnrealizedPnL    float64 `json:"unrealized_pnl"`
+	CycleNumber      int     `json:"cycle_number"`
+}
+
+// storeState persisted store contents
+type storeState struct {
+	Traders map[string]map[string]storedSnapshot `json:"traders"` // trader ID → topic → snapshot
+	System  map[string]storedSnapshot            `json:"system"`  // topic → snapshot
+	Equity  map[string][]storedEquityPoint       `json:"equity"`  // trader ID → points (oldest first)
+}
+
+// StateStore the API process's own copy of engine state, fed by the message bus
+type StateStore struct {
+	path         string // "" = memory only
+	historyLimit int
+
+	mu       sync.RWMutex
+	state    storeState
+	received time.Time // Last message received
+	lastSave time.Time
+}
+
+// NewStateStore creates a store persisted at path ("-" = memory only), keeping historyLimit equity points per trader
+func NewStateStore(path string, historyLimit int) *StateStore {
+	s := &StateStore{historyLimit: historyLimit}
+	if path != "-" {
+		s.path = path
+	}
+	if err := s.load(); err != nil {
+		log.Printf("⚠️  Failed to load API state store (%s): %v - starting empty", path, err)
+		s.state = storeState{}
+	}
+	if s.state.Traders == nil {
+		s.state.Traders = make(map[string]map[string]storedSnapshot)
+	}
+	if s.state.System == nil {
+		s.state.System = make(map[string]storedSnapshot)
+	}
+	if s.state.Equity == nil {
+		s.state.Equity = make(map[string][]storedEquityPoint)
+	}
+	return s
+}
+
+// Apply stores a bus message (malformed messages are logged and dropped)
+func (s *StateStore) Apply(payload []byte) {
+	var msg bus.Message
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Topic == "" {
+		log.Printf("⚠️  Dropping malformed bus message: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = time.Now()
+	snapshot := storedSnapshot{Data: msg.Data, Time: msg.Time}
+	if msg.TraderID == "" {
+		s.state.System[msg.Topic] = snapshot
+	} else {
+		topics := s.state.Traders[msg.TraderID]
+		if topics == nil {
+			topics = make(map[string]storedSnapshot)
+			s.state.Traders[msg.TraderID] = topics
+		}
+		topics[msg.Topic] = snapshot
+		if msg.Topic == bus.TopicAccount {
+			s.appendEquityLocked(msg)
+		}
+	}
+
+	if s.path != "" && time.Since(s.lastSave) >= stateStoreSaveInterval {
+		s.saveLocked()
+	}
+}
+
+// appendEquityLocked adds an equity point from an account snapshot (caller holds mu)
+func (s *StateStore) appendEquityLocked(msg bus.Message) {
+	var account struct {
+		TotalEquity      float64 `json:"total_equity"`
+		AvailableBalance float64 `json:"available_balance"`
+		TotalPnL         float64 `json:"total_pnl"`
+		TotalPnLPct      float64 `json:"total_pnl_pct"`
+		PositionCount    int     `json:"position_count"`
+		MarginUsedPct    float64 `json:"margin_used_pct"`
+		UnrealizedPnL    float64 `json:"unrealized_pnl"`
+	}
+	if err := json.Unmarshal(msg.Data, &account); err != nil {
+		return
+	}
+	cycle := 0
+	if status, ok := s.state.Traders[msg.TraderID][bus.TopicStatus]; ok {
+		var st struct {
+			CallCount int `json:"call_count"`
+		}
+		if json.Unmarshal(status.Data, &st) == nil {
+			cycle = st.CallCount
+		}
+	}
+
+	points := append(s.state.Equity[msg.TraderID], storedEquityPoint{
+		Timestamp:        msg.Time.Format("2006-01-02 15:04:05"),
+		TotalEquity:      account.TotalEquity,
+		AvailableBalance: account.AvailableBalance,
+		TotalPnL:         account.TotalPnL,
+		TotalPnLPct:      account.TotalPnLPct,
+		PositionCount:    account.PositionCount,
+		MarginUsedPct:    account.MarginUsedPct,
+		UnrealizedPnL:    account.UnrealizedPnL,
+		CycleNumber:      cycle,
+	})
+	if len(points) > s.historyLimit {
+		points = points[len(points)-s.historyLimit:]
+	}
+	s.state.Equity[msg.TraderID] = points
+}
+
+// Trader returns a trader's latest snapshot of topic
+func (s *StateStore) Trader(traderID, topic string) (storedSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.state.Traders[traderID][topic]
+	return snapshot, ok
+}
+
+// System returns the latest system-wide snapshot of topic
+func (s *StateStore) System(topic string) (storedSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.state.System[topic]
+	return snapshot, ok
+}
+
+// HasTrader whether any data was received for the trader
+func (s *StateStore) HasTrader(traderID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.state.Traders[traderID]
+	return ok
+}
+
+// TraderIDs known trader IDs, sorted
+func (s *StateStore) TraderIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.state.Traders))
+	for id := range s.state.Traders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// EquityHistory a copy of the trader's equity points (oldest first)
+func (s *StateStore) EquityHistory(traderID string) []storedEquityPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]storedEquityPoint(nil), s.state.Equity[traderID]...)
+}
+
+// LastReceived when the last bus message arrived (zero if none since start)
+func (s *StateStore) LastReceived() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.received
+}
+
+// Save persists the store now (no-op when memory only)
+func (s *StateStore) Save() {
+	if s.path == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saveLocked()
+}
+
+// load reads the snapshot file (a missing file is not an error)
+func (s *StateStore) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return fmt.Errorf("failed to parse API state store: %w", err)
+	}
+	return nil
+}
+
+// saveLocked writes the snapshot file (caller holds mu); failures are logged
+func (s *StateStore) saveLocked() {
+	s.lastSave = time.Now()
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		log.Printf("⚠️  Failed to serialize API state store: %v", err)
+		return
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Printf("⚠️  Failed to create API state store directory: %v", err)
+			return
+		}
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		log.Printf("⚠️  Failed to write API state store: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		log.Printf("⚠️  Failed to replace API state store file: %v", err)
+	}
+}
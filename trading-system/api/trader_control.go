@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"lia/trader"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleTraderPause pauses a trader's scheduled decision cycles (POST /api/traders/:id/pause)
+func (s *Server) handleTraderPause(c *gin.Context) {
+	traderID := c.Param("id")
+	if _, err := s.traderManager.GetTrader(traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	changed, err := s.traderManager.PauseTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	message := "trader paused"
+	if !changed {
+		message = "trader was already paused"
+	}
+	log.Printf("⏸ API: %s (%s)", message, traderID)
+	c.JSON(http.StatusOK, gin.H{"trader_id": traderID, "paused": true, "message": message})
+}
+
+// handleTraderResume resumes a paused trader (POST /api/traders/:id/resume)
+func (s *Server) handleTraderResume(c *gin.Context) {
+	traderID := c.Param("id")
+	if _, err := s.traderManager.GetTrader(traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	changed, err := s.traderManager.ResumeTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	message := "trader resumed"
+	if !changed {
+		message = "trader was not paused"
+	}
+	log.Printf("▶️  API: %s (%s)", message, traderID)
+	c.JSON(http.StatusOK, gin.H{"trader_id": traderID, "paused": false, "message": message})
+}
+
+// handleTraderRunCycle queues an immediate decision cycle (POST /api/traders/:id/run-cycle). The cycle runs
+// in the trader's loop, so the response only confirms it was queued
+func (s *Server) handleTraderRunCycle(c *gin.Context) {
+	traderID := c.Param("id")
+	if _, err := s.traderManager.GetTrader(traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.traderManager.RunTraderCycle(traderID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, trader.ErrTraderNotRunning) || errors.Is(err, trader.ErrCycleQueued) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("⏩ API: manual cycle queued (%s)", traderID)
+	c.JSON(http.StatusAccepted, gin.H{"trader_id": traderID, "queued": true, "message": "cycle queued"})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"lia/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleTrades trade journal: matched open/close pairs with realized P&L, fees and duration
+// Query: trader_id, status (open/closed, default all), limit (default 50)
+func (s *Server) handleTrades(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != logger.TradeOpen && status != logger.TradeClosed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'open' or 'closed'"})
+		return
+	}
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	trades, err := trader.GetDecisionLogger().GetTrades(status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if trades == nil {
+		trades = []logger.Trade{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"trades":    trades,
+	})
+}
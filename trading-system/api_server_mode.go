@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"lia/api"
+	"lia/bus"
+	"lia/config"
+	"lia/logging"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// runAPIServer implements `api-server [config.json]`: a read-only API process that serves dashboard
+// traffic from its own store, fed by the engine over the message bus. Returns the process exit code
+func runAPIServer(args []string) int {
+	configFile := "config.json"
+	if len(args) > 0 {
+		configFile = args[0]
+	}
+
+	log.Printf("📋 Loading configuration file: %s", configFile)
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		log.Printf("❌ Failed to load configuration: %v", err)
+		return 1
+	}
+	if err := logging.Init(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		log.Printf("❌ Failed to configure logging: %v", err)
+		return 1
+	}
+	if !cfg.MessageBus.Enabled {
+		log.Printf("❌ api-server needs message_bus.enabled (the engine publishes its state there)")
+		return 1
+	}
+	if renderPort := os.Getenv("PORT"); renderPort != "" {
+		if portNum, err := strconv.Atoi(renderPort); err == nil {
+			cfg.APIServerPort = portNum
+		}
+	}
+
+	stateBus, err := bus.New(cfg.MessageBus)
+	if err != nil {
+		log.Printf("❌ Failed to connect to the message bus: %v", err)
+		return 1
+	}
+	defer stateBus.Close()
+
+	store := api.NewStateStore(cfg.MessageBus.StorePath, cfg.MessageBus.EquityHistoryLimit)
+	defer store.Save()
+	if err := stateBus.SubscribeAll(cfg.MessageBus.SubjectPrefix, store.Apply); err != nil {
+		log.Printf("❌ Failed to subscribe to engine state: %v", err)
+		return 1
+	}
+	log.Printf("📡 Subscribed to engine state on %s (%s.*)", cfg.MessageBus.Type, cfg.MessageBus.SubjectPrefix)
+
+	// Not ready after missing three publishes
+	staleAfter := 3 * time.Duration(cfg.MessageBus.PublishIntervalSeconds) * time.Second
+	server := api.NewReadServer(store, cfg.APIServerPort, staleAfter)
+	server.SetAPIAuth(cfg.APIAuth)
+	server.SetServerOptions(cfg.APIServer)
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.Start() }()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-errChan:
+		log.Printf("❌ API server error: %v", err)
+		return 1
+	case <-sigChan:
+		log.Println("📛 Received shutdown signal, stopping API server...")
+		ctx, cancel := context.WithTimeout(context.Background(), server.ShutdownTimeout())
+		defer cancel()
+		if err := server.Stop(ctx); err != nil {
+			log.Printf("⚠️  %v", err)
+			return 1
+		}
+		return 0
+	}
+}
@@ -28,6 +28,9 @@ type StrategyResult struct {
 	AvgHoldTime     float64 `json:"avg_hold_time"`     // Average hold time (minutes)
 	EarlyCloses     int     `json:"early_closes"`      // Number of times auto-close triggered
 	MissedProfit    float64 `json:"missed_profit"`      // Profit that would have been made if held longer
+
+	TradePnLs  []float64         `json:"trade_pnls,omitempty"`  // Simulated P&L of each trade, in order (resampled by the Monte Carlo)
+	MonteCarlo *MonteCarloResult `json:"monte_carlo,omitempty"` // Bootstrap distributions (nil = not simulated)
 }
 
 // BacktestResult contains results for all strategies
@@ -115,6 +118,7 @@ type Trade struct {
 	StopLoss    float64 // AI's stop loss price (0 if not set)
 	ActualPnL   float64 // Actual P&L from historical data
 	ActualPnLPct float64 // Actual P&L % (with leverage)
+	Confidence  int     // AI confidence of the open (0 if not recorded)
 }
 
 // extractTrades extracts all completed trades from decision records
@@ -145,6 +149,7 @@ func extractTrades(records []*logger.DecisionRecord) []Trade {
 				// Extract take profit and stop loss from decision JSON if available
 				takeProfit := 0.0
 				stopLoss := 0.0
+				confidence := 0
 				
 				// Try to parse decision JSON to get take_profit and stop_loss
 				if record.DecisionJSON != "" {
@@ -158,6 +163,9 @@ func extractTrades(records []*logger.DecisionRecord) []Trade {
 								if sl, ok := d["stop_loss"].(float64); ok {
 									stopLoss = sl
 								}
+								if c, ok := d["confidence"].(float64); ok {
+									confidence = int(c)
+								}
 								break
 							}
 						}
@@ -173,6 +181,7 @@ func extractTrades(records []*logger.DecisionRecord) []Trade {
 					Leverage:   action.Leverage,
 					TakeProfit: takeProfit,
 					StopLoss:   stopLoss,
+					Confidence: confidence,
 				}
 
 			case "close_long", "close_short":
@@ -207,6 +216,12 @@ func extractTrades(records []*logger.DecisionRecord) []Trade {
 
 // testStrategy tests a single auto-close strategy
 func testStrategy(trades []Trade, autoClosePct float64) StrategyResult {
+	return simulateTrades(trades, autoClosePct, 10000.0, nil)
+}
+
+// simulateTrades replays trades one after another from initialEquity with an auto-close strategy; adjust (nil =
+// trades as recorded) may resize the i-th trade against the equity at that point and pick its auto-close %
+func simulateTrades(trades []Trade, autoClosePct float64, initialEquity float64, adjust func(i int, trade Trade, equity float64) (Trade, float64)) StrategyResult {
 	result := StrategyResult{
 		AutoClosePct: autoClosePct,
 	}
@@ -218,14 +233,18 @@ func testStrategy(trades []Trade, autoClosePct float64) StrategyResult {
 	earlyCloses := 0
 	missedProfit := 0.0
 	equityHistory := make([]float64, 0)
-	initialEquity := 10000.0
 	currentEquity := initialEquity
 	maxEquity := initialEquity
 	maxDrawdown := 0.0
 
-	for _, trade := range trades {
+	for i, trade := range trades {
+		tradeAutoClose := autoClosePct
+		if adjust != nil {
+			trade, tradeAutoClose = adjust(i, trade, currentEquity)
+		}
+
 		// Simulate what would happen with this auto-close strategy
-		simulatedPnL, _, closedEarly, missed := simulateTrade(trade, autoClosePct)
+		simulatedPnL, _, closedEarly, missed := simulateTrade(trade, tradeAutoClose)
 		
 		if closedEarly {
 			earlyCloses++
@@ -235,6 +254,7 @@ func testStrategy(trades []Trade, autoClosePct float64) StrategyResult {
 		totalPnL += simulatedPnL
 		currentEquity += simulatedPnL
 		equityHistory = append(equityHistory, currentEquity)
+		result.TradePnLs = append(result.TradePnLs, simulatedPnL)
 
 		if currentEquity > maxEquity {
 			maxEquity = currentEquity
@@ -450,15 +470,16 @@ func findBestByWinRate(results []StrategyResult) StrategyResult {
 	return best
 }
 
-// RunBacktest runs backtest and saves results to file
-func RunBacktest(traderID string, decisionLogDir string) error {
-	// Test strategies: 0% (no auto-close), 0.5%, 1%, 1.5%, 2%, 2.5%, 3%, 5%
-	strategies := []float64{0.0, 0.5, 1.0, 1.5, 2.0, 2.5, 3.0, 5.0}
+// defaultAutoCloseStrategies auto-close % tested by default: 0% (no auto-close), 0.5%, 1%, 1.5%, 2%, 2.5%, 3%, 5%
+var defaultAutoCloseStrategies = []float64{0.0, 0.5, 1.0, 1.5, 2.0, 2.5, 3.0, 5.0}
 
-	result, err := BacktestAutoCloseStrategies(traderID, decisionLogDir, strategies)
+// RunBacktest runs backtest, bootstraps each strategy's trades with mc and saves results to file
+func RunBacktest(traderID string, decisionLogDir string, mc MonteCarloConfig) error {
+	result, err := BacktestAutoCloseStrategies(traderID, decisionLogDir, defaultAutoCloseStrategies)
 	if err != nil {
 		return fmt.Errorf("backtest failed: %w", err)
 	}
+	result.AddMonteCarlo(mc)
 
 	// Save results to JSON file
 	outputFile := filepath.Join(decisionLogDir, fmt.Sprintf("backtest_%s.json", time.Now().Format("20060102_150405")))
@@ -501,6 +522,8 @@ func printBacktestSummary(result *BacktestResult) {
 
 	fmt.Println(strings.Repeat("-", 80))
 
+	PrintMonteCarloBands(result.Strategies)
+
 	fmt.Printf("\n🏆 Best Strategies:\n\n")
 	fmt.Printf("Best Sharpe Ratio: %.2f%% auto-close\n", result.BestStrategy.AutoClosePct)
 	fmt.Printf("  Sharpe: %.2f | P&L: $%.2f | Win Rate: %.1f%%\n",
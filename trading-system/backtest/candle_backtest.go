@@ -0,0 +1,820 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lia/decision"
+	"lia/market"
+	"lia/mcp"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Candle backtest defaults (fees and auto-close mirror the live engine on Binance futures)
+const (
+	defaultInitialBalance = 1000.0
+	defaultLeverage       = 5
+	defaultTakerFeeRate   = 0.0004 // 0.04% taker fee per side
+	defaultSlippageBps    = 5.0    // 0.05% adverse slippage per market fill
+	defaultAutoClosePct   = 4.5    // Leveraged P&L % at which the background monitor closes a position
+	minExecutableMargin   = 5.0    // Opens using less margin are skipped (same as live)
+	marginSafetyBuffer    = 1.0    // Margin left free for fees (same as live)
+)
+
+// Exit reasons of simulated trades
+const (
+	ExitClose       = "close"       // Closed by a strategy decision
+	ExitReduce      = "reduce"      // Partially closed by reduce_size or close_pct
+	ExitTakeProfit  = "take_profit" // Take profit order filled
+	ExitStopLoss    = "stop_loss"   // Stop order filled (honor_stops only)
+	ExitAutoClose   = "auto_close"  // Background monitor profit-taking
+	ExitLiquidation = "liquidation" // Margin exhausted
+	ExitEndOfTest   = "end_of_test" // Still open when the backtest ended (closed at the last price)
+)
+
+// CandleBacktestConfig settings of a candle-driven backtest
+type CandleBacktestConfig struct {
+	Symbols        []string
+	Start          time.Time
+	End            time.Time
+	CycleInterval  time.Duration // Time between decision cycles (multiple of 3m, default 3m)
+	InitialBalance float64       // Starting wallet balance in USDT (default 1000)
+
+	Strategy       string          // Registered strategy name ("" = the AI engine)
+	StrategyParams json.RawMessage // Strategy-specific settings
+	AIClient       *mcp.Client     // Required by strategies that consult the LLM
+
+	BTCETHLeverage  int  // Maximum BTC/ETH leverage (default 5)
+	AltcoinLeverage int  // Maximum altcoin leverage (default 5)
+	HonorStops      bool // Stop orders are placed and filled (stop_loss_mode "honor_stops")
+
+	TakerFeeRate float64 // Fee per fill as a fraction of notional (default 0.0004, negative = no fees)
+	SlippageBps  float64 // Adverse slippage per market fill in basis points (default 5, negative = none)
+	AutoClosePct float64 // Leveraged profit % closed by the background monitor (default 4.5, negative = off)
+
+	CSVDir string // Load <SYMBOL>_3m.csv (and optional <SYMBOL>_4h.csv) from here instead of Binance
+}
+
+// applyDefaults fills unset settings
+func (c *CandleBacktestConfig) applyDefaults() {
+	if c.CycleInterval <= 0 {
+		c.CycleInterval = 3 * time.Minute
+	}
+	if c.InitialBalance <= 0 {
+		c.InitialBalance = defaultInitialBalance
+	}
+	if c.BTCETHLeverage <= 0 {
+		c.BTCETHLeverage = defaultLeverage
+	}
+	if c.AltcoinLeverage <= 0 {
+		c.AltcoinLeverage = defaultLeverage
+	}
+	if c.TakerFeeRate == 0 {
+		c.TakerFeeRate = defaultTakerFeeRate
+	} else if c.TakerFeeRate < 0 {
+		c.TakerFeeRate = 0
+	}
+	if c.SlippageBps == 0 {
+		c.SlippageBps = defaultSlippageBps
+	} else if c.SlippageBps < 0 {
+		c.SlippageBps = 0
+	}
+	if c.AutoClosePct == 0 {
+		c.AutoClosePct = defaultAutoClosePct
+	}
+}
+
+// validate checks the settings (after applyDefaults)
+func (c *CandleBacktestConfig) validate() error {
+	if len(c.Symbols) == 0 {
+		return fmt.Errorf("at least one symbol is required")
+	}
+	if !c.End.After(c.Start) {
+		return fmt.Errorf("end (%s) must be after start (%s)", c.End.Format(time.RFC3339), c.Start.Format(time.RFC3339))
+	}
+	if c.CycleInterval%(3*time.Minute) != 0 {
+		return fmt.Errorf("cycle interval must be a multiple of 3m, got %s", c.CycleInterval)
+	}
+	return nil
+}
+
+// SimTrade a simulated closed trade (or the closed part of a position)
+type SimTrade struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Leverage   int       `json:"leverage"`
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	Margin     float64   `json:"margin"`
+	Fees       float64   `json:"fees"`    // Entry and exit fees of this quantity
+	PnL        float64   `json:"pnl"`     // Net of fees (USDT)
+	PnLPct     float64   `json:"pnl_pct"` // Net P&L on margin (%)
+	ExitReason string    `json:"exit_reason"`
+}
+
+// EquityPoint account equity at a decision cycle
+type EquityPoint struct {
+	Time      time.Time `json:"time"`
+	Equity    float64   `json:"equity"`
+	Positions int       `json:"positions"`
+}
+
+// CandleBacktestResult outcome of a candle-driven backtest
+type CandleBacktestResult struct {
+	Strategy       string    `json:"strategy"`
+	Symbols        []string  `json:"symbols"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	CycleMinutes   float64   `json:"cycle_minutes"`
+	TotalCycles    int       `json:"total_cycles"`
+	HonorStops     bool      `json:"honor_stops"`
+	TakerFeeRate   float64   `json:"taker_fee_rate"`
+	SlippageBps    float64   `json:"slippage_bps"`
+	InitialBalance float64   `json:"initial_balance"`
+	FinalEquity    float64   `json:"final_equity"`
+	TotalPnL       float64   `json:"total_pnl"`
+	TotalPnLPct    float64   `json:"total_pnl_pct"`
+	TotalFees      float64   `json:"total_fees"`
+	TotalTrades    int       `json:"total_trades"`
+	WinningTrades  int       `json:"winning_trades"`
+	LosingTrades   int       `json:"losing_trades"`
+	WinRate        float64   `json:"win_rate"`      // %
+	AvgWin         float64   `json:"avg_win"`       // USDT
+	AvgLoss        float64   `json:"avg_loss"`      // USDT
+	ProfitFactor   float64   `json:"profit_factor"` // Gross wins / gross losses
+	MaxDrawdown    float64   `json:"max_drawdown"`  // Peak-to-trough equity drop (%)
+	SharpeRatio    float64   `json:"sharpe_ratio"`  // Annualized from per-cycle equity returns
+	AvgHoldMinutes float64   `json:"avg_hold_minutes"`
+
+	ExitReasons       map[string]int `json:"exit_reasons"`
+	RejectedDecisions int            `json:"rejected_decisions"` // Removed by validation
+	FailedActions     int            `json:"failed_actions"`     // Valid decisions the simulated exchange refused
+	StrategyErrors    int            `json:"strategy_errors"`    // Cycles where the strategy returned an error
+
+	Trades      []SimTrade    `json:"trades"`
+	EquityCurve []EquityPoint `json:"equity_curve"`
+}
+
+// simPosition an open simulated position (one per symbol and side; adding to it averages the entry)
+type simPosition struct {
+	symbol     string
+	side       string
+	leverage   int
+	quantity   float64
+	entryPrice float64
+	margin     float64
+	entryFees  float64 // Fees paid opening the remaining quantity
+	stopLoss   float64
+	takeProfit float64
+	openedAt   time.Time
+}
+
+// unrealized P&L at price
+func (p *simPosition) unrealized(price float64) float64 {
+	if p.side == "long" {
+		return p.quantity * (price - p.entryPrice)
+	}
+	return p.quantity * (p.entryPrice - price)
+}
+
+// liquidationPrice price at which the loss uses up the position margin
+func (p *simPosition) liquidationPrice() float64 {
+	if p.quantity <= 0 {
+		return 0
+	}
+	if p.side == "long" {
+		return math.Max(0, p.entryPrice-p.margin/p.quantity)
+	}
+	return p.entryPrice + p.margin/p.quantity
+}
+
+// candleBacktest state of a running candle backtest
+type candleBacktest struct {
+	cfg       CandleBacktestConfig
+	strategy  decision.Strategy
+	series    map[string]*candleSeries
+	now       time.Time
+	wallet    float64
+	positions map[string]*simPosition // symbol_side
+	result    *CandleBacktestResult
+}
+
+// RunCandleBacktest replays historical candles through the same context builder, strategy and validation as
+// the live engine, simulating fills (fees, slippage, take profit / stop orders, liquidation and the
+// auto-close monitor) on an isolated-margin account. Uses process-wide decision hooks: run one at a time
+func RunCandleBacktest(cfg CandleBacktestConfig) (*CandleBacktestResult, error) {
+	cfg.applyDefaults()
+	for i, symbol := range cfg.Symbols {
+		cfg.Symbols[i] = market.Normalize(symbol)
+	}
+	cfg.Start = cfg.Start.UTC().Truncate(3 * time.Minute)
+	cfg.End = cfg.End.UTC()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	strategy, err := decision.NewStrategy(cfg.Strategy, cfg.StrategyParams, cfg.AIClient)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := loadCandleSeries(cfg.Symbols, cfg.Start, cfg.End, cfg.CSVDir)
+	if err != nil {
+		return nil, err
+	}
+
+	bt := &candleBacktest{
+		cfg:       cfg,
+		strategy:  strategy,
+		series:    series,
+		now:       cfg.Start,
+		wallet:    cfg.InitialBalance,
+		positions: make(map[string]*simPosition),
+		result: &CandleBacktestResult{
+			Strategy:       strategy.Name(),
+			Symbols:        cfg.Symbols,
+			StartTime:      cfg.Start,
+			EndTime:        cfg.End,
+			CycleMinutes:   cfg.CycleInterval.Minutes(),
+			HonorStops:     cfg.HonorStops,
+			TakerFeeRate:   cfg.TakerFeeRate,
+			SlippageBps:    cfg.SlippageBps,
+			InitialBalance: cfg.InitialBalance,
+			ExitReasons:    make(map[string]int),
+		},
+	}
+
+	// Route the decision package's market data, validation prices and clock through the simulation
+	decision.SetMarketDataSource(bt.marketData)
+	decision.SetPriceSource(bt.price)
+	decision.SetClock(func() time.Time { return bt.now })
+	defer decision.SetMarketDataSource(nil)
+	defer decision.SetPriceSource(nil)
+	defer decision.SetClock(nil)
+
+	log.Printf("🧪 Candle backtest: %s on %s, %s → %s, cycle every %s",
+		strategy.Name(), strings.Join(cfg.Symbols, ","), cfg.Start.Format(time.RFC3339), cfg.End.Format(time.RFC3339), cfg.CycleInterval)
+
+	prev := cfg.Start
+	for t := cfg.Start; !t.After(cfg.End); t = t.Add(cfg.CycleInterval) {
+		bt.advance(prev, t)
+		prev = t
+		bt.now = t
+		bt.runCycle()
+	}
+	bt.advance(prev, cfg.End)
+	bt.now = cfg.End
+	bt.closeAll()
+
+	bt.finish()
+	return bt.result, nil
+}
+
+// marketData market data source for the decision package (closed candles before the simulated time)
+func (bt *candleBacktest) marketData(symbol string) (*market.Data, error) {
+	s, ok := bt.series[market.Normalize(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("no candles loaded for %s", symbol)
+	}
+	return s.dataAt(bt.now)
+}
+
+// price validation price source (last closed candle before the simulated time)
+func (bt *candleBacktest) price(symbol string) (float64, error) {
+	s, ok := bt.series[market.Normalize(symbol)]
+	if !ok {
+		return 0, fmt.Errorf("no candles loaded for %s", symbol)
+	}
+	price := s.priceAt(bt.now)
+	if price <= 0 {
+		return 0, fmt.Errorf("no %s price at %s", symbol, bt.now.Format(time.RFC3339))
+	}
+	return price, nil
+}
+
+// advance walks the 3m candles that closed in (from, to], filling stop, liquidation and take profit orders
+// intrabar and running the auto-close monitor on each close. When a candle reaches both the stop and the
+// take profit, the stop is assumed to fill first
+func (bt *candleBacktest) advance(from, to time.Time) {
+	for _, key := range bt.positionKeys() {
+		pos := bt.positions[key]
+		s := bt.series[pos.symbol]
+		for i := s.closedBefore(from); i < s.closedBefore(to); i++ {
+			k := s.klines3m[i]
+			bt.now = time.UnixMilli(k.CloseTime + 1).UTC()
+			if bt.fillProtection(pos, k) {
+				break
+			}
+		}
+	}
+}
+
+// fillProtection fills the first order a candle triggers for pos; reports whether the position is gone
+func (bt *candleBacktest) fillProtection(pos *simPosition, k market.Kline) bool {
+	long := pos.side == "long"
+	adverse, favorable := k.Low, k.High
+	if !long {
+		adverse, favorable = k.High, k.Low
+	}
+	crossed := func(level, price float64, towardLoss bool) bool {
+		if level <= 0 {
+			return false
+		}
+		if long == towardLoss {
+			return price <= level
+		}
+		return price >= level
+	}
+	gapFill := func(level float64, towardLoss bool) float64 {
+		if crossed(level, k.Open, towardLoss) {
+			return k.Open // Opened through the level
+		}
+		return level
+	}
+
+	liq := pos.liquidationPrice()
+	if bt.cfg.HonorStops && crossed(pos.stopLoss, adverse, true) && !crossed(liq, pos.stopLoss, true) {
+		bt.close(pos, pos.quantity, bt.slip(gapFill(pos.stopLoss, true), !long), ExitStopLoss)
+		return true
+	}
+	if crossed(liq, adverse, true) {
+		bt.close(pos, pos.quantity, liq, ExitLiquidation)
+		return true
+	}
+	if crossed(pos.takeProfit, favorable, false) {
+		bt.close(pos, pos.quantity, bt.slip(gapFill(pos.takeProfit, false), !long), ExitTakeProfit)
+		return true
+	}
+
+	// Background monitor: close profitable positions past the auto-close threshold
+	if bt.cfg.AutoClosePct > 0 && pos.margin > 0 {
+		pnl := pos.unrealized(k.Close)
+		if pnl > 0 && pnl/pos.margin*100 >= bt.cfg.AutoClosePct {
+			bt.close(pos, pos.quantity, bt.slip(k.Close, !long), ExitAutoClose)
+			return true
+		}
+	}
+	return false
+}
+
+// runCycle builds the trading context at the simulated time, asks the strategy and executes its decisions
+func (bt *candleBacktest) runCycle() {
+	bt.result.TotalCycles++
+	ctx := bt.buildContext()
+
+	full, err := bt.strategy.Decide(context.Background(), ctx)
+	if err != nil {
+		bt.result.StrategyErrors++
+		log.Printf("⚠️  [%s] Strategy error: %v", bt.now.Format(time.RFC3339), err)
+	} else {
+		bt.result.RejectedDecisions += len(full.Rejected)
+		for _, d := range sortDecisions(full.Decisions) {
+			if err := bt.execute(&d); err != nil {
+				bt.result.FailedActions++
+				log.Printf("  ⚠️  [%s] %s %s not executed: %v", bt.now.Format(time.RFC3339), d.Symbol, d.Action, err)
+			}
+		}
+	}
+
+	bt.result.EquityCurve = append(bt.result.EquityCurve, EquityPoint{
+		Time:      bt.now,
+		Equity:    bt.equity(),
+		Positions: len(bt.positions),
+	})
+}
+
+// buildContext the decision context the live trader would build from this account at the simulated time
+func (bt *candleBacktest) buildContext() *decision.Context {
+	var positions []decision.PositionInfo
+	var marginUsed, unrealized float64
+	for _, key := range bt.positionKeys() {
+		pos := bt.positions[key]
+		mark := bt.series[pos.symbol].priceAt(bt.now)
+		pnl := pos.unrealized(mark)
+		pnlPct := 0.0
+		if pos.margin > 0 {
+			pnlPct = pnl / pos.margin * 100
+		}
+		var stopLoss float64
+		if bt.cfg.HonorStops {
+			stopLoss = pos.stopLoss
+		}
+		positions = append(positions, decision.PositionInfo{
+			Symbol:           pos.symbol,
+			Side:             pos.side,
+			EntryPrice:       pos.entryPrice,
+			MarkPrice:        mark,
+			Quantity:         pos.quantity,
+			Leverage:         pos.leverage,
+			UnrealizedPnL:    pnl,
+			UnrealizedPnLPct: pnlPct,
+			LiquidationPrice: pos.liquidationPrice(),
+			MarginUsed:       pos.margin,
+			UpdateTime:       pos.openedAt.UnixMilli(),
+			StopLoss:         stopLoss,
+			TakeProfit:       pos.takeProfit,
+		})
+		marginUsed += pos.margin
+		unrealized += pnl
+	}
+
+	candidates := make([]decision.CandidateCoin, 0, len(bt.cfg.Symbols))
+	for _, symbol := range bt.cfg.Symbols {
+		candidates = append(candidates, decision.CandidateCoin{Symbol: symbol, Sources: []string{"backtest"}})
+	}
+
+	equity := bt.wallet + unrealized
+	marginUsedPct := 0.0
+	if equity > 0 {
+		marginUsedPct = marginUsed / equity * 100
+	}
+	totalPnL := equity - bt.cfg.InitialBalance
+
+	return &decision.Context{
+		CurrentTime:     bt.now.Format("2006-01-02 15:04:05"),
+		RuntimeMinutes:  int(bt.now.Sub(bt.cfg.Start).Minutes()),
+		CallCount:       bt.result.TotalCycles,
+		BTCETHLeverage:  bt.cfg.BTCETHLeverage,
+		AltcoinLeverage: bt.cfg.AltcoinLeverage,
+		Account: decision.AccountInfo{
+			TotalEquity:      equity,
+			WalletBalance:    bt.wallet,
+			AvailableBalance: equity - marginUsed,
+			TotalPnL:         totalPnL,
+			TotalPnLPct:      totalPnL / bt.cfg.InitialBalance * 100,
+			MarginUsed:       marginUsed,
+			MarginUsedPct:    marginUsedPct,
+			PositionCount:    len(positions),
+			RealizedPnL:      bt.wallet - bt.cfg.InitialBalance,
+			UnrealizedPnL:    unrealized,
+		},
+		Positions:      positions,
+		NetExposure:    decision.NetExposures(positions),
+		CandidateCoins: candidates,
+		HonorStops:     bt.cfg.HonorStops,
+	}
+}
+
+// execute applies a validated decision with the live trader's rules (losing positions are never closed or
+// reduced by decisions; stops below entry need honor_stops)
+func (bt *candleBacktest) execute(d *decision.Decision) error {
+	switch d.Action {
+	case "open_long", "open_short":
+		return bt.open(d, strings.TrimPrefix(d.Action, "open_"))
+	case "add_long", "add_short":
+		side := decision.EntrySide(d.Action)
+		if _, err := bt.position(d.Symbol, side); err != nil {
+			return err
+		}
+		return bt.open(d, side) // Averages the entry; stop / take profit move to the decision's levels
+	case "close_long", "close_short":
+		pos, err := bt.position(d.Symbol, strings.TrimPrefix(d.Action, "close_"))
+		if err != nil {
+			return err
+		}
+		if err := bt.requireProfit(pos); err != nil {
+			return err
+		}
+		if d.IsPartialClose() {
+			bt.close(pos, pos.quantity*d.ClosePct/100, bt.marketFill(pos, false), ExitReduce)
+			return nil
+		}
+		bt.close(pos, pos.quantity, bt.marketFill(pos, false), ExitClose)
+		return nil
+	case "reduce_size":
+		pos, err := bt.position(d.Symbol, d.Side)
+		if err != nil {
+			return err
+		}
+		if err := bt.requireProfit(pos); err != nil {
+			return err
+		}
+		bt.close(pos, pos.quantity*d.ReducePct/100, bt.marketFill(pos, false), ExitReduce)
+		return nil
+	case "adjust_stop":
+		pos, err := bt.position(d.Symbol, d.Side)
+		if err != nil {
+			return err
+		}
+		return bt.adjustStop(pos, d.StopLoss)
+	case "adjust_target":
+		pos, err := bt.position(d.Symbol, d.Side)
+		if err != nil {
+			return err
+		}
+		mark := bt.series[pos.symbol].priceAt(bt.now)
+		if (pos.side == "long" && d.TakeProfit <= mark) || (pos.side == "short" && d.TakeProfit >= mark) {
+			return fmt.Errorf("take profit %.4f is on the wrong side of the current price %.4f", d.TakeProfit, mark)
+		}
+		pos.takeProfit = d.TakeProfit
+		return nil
+	case "add_margin":
+		pos, err := bt.position(d.Symbol, d.Side)
+		if err != nil {
+			return err
+		}
+		if d.PositionSizeUSD > bt.available()-marginSafetyBuffer {
+			return fmt.Errorf("cannot add %.2f USDT margin (available %.2f USDT)", d.PositionSizeUSD, bt.available())
+		}
+		pos.margin += d.PositionSizeUSD
+		return nil
+	}
+	return nil // hold / wait
+}
+
+// open opens or adds to a position at the current price plus slippage
+func (bt *candleBacktest) open(d *decision.Decision, side string) error {
+	margin := math.Min(d.PositionSizeUSD, bt.available()-marginSafetyBuffer)
+	if margin < minExecutableMargin {
+		return fmt.Errorf("usable margin %.2f USDT is below minimum %.2f USDT", margin, minExecutableMargin)
+	}
+	price, err := bt.price(d.Symbol)
+	if err != nil {
+		return err
+	}
+	fill := bt.slip(price, side == "long")
+	quantity := margin * float64(d.Leverage) / fill
+	fee := quantity * fill * bt.cfg.TakerFeeRate
+	bt.wallet -= fee
+	bt.result.TotalFees += fee
+
+	key := d.Symbol + "_" + side
+	pos, ok := bt.positions[key]
+	if !ok {
+		pos = &simPosition{symbol: d.Symbol, side: side, leverage: d.Leverage, openedAt: bt.now}
+		bt.positions[key] = pos
+	}
+	pos.entryPrice = (pos.entryPrice*pos.quantity + fill*quantity) / (pos.quantity + quantity)
+	pos.quantity += quantity
+	pos.margin += margin
+	pos.entryFees += fee
+	pos.takeProfit = d.TakeProfit
+	if bt.cfg.HonorStops {
+		pos.stopLoss = d.StopLoss
+	}
+	log.Printf("  📈 [%s] Open %s %s: %.4f @ %.4f (%dx, margin %.2f)",
+		bt.now.Format(time.RFC3339), strings.ToUpper(side), d.Symbol, quantity, fill, d.Leverage, margin)
+	return nil
+}
+
+// adjustStop moves a position's stop (below entry only when stops are honored, like the live trader)
+func (bt *candleBacktest) adjustStop(pos *simPosition, stop float64) error {
+	mark := bt.series[pos.symbol].priceAt(bt.now)
+	if pos.side == "long" {
+		if stop >= mark {
+			return fmt.Errorf("long stop %.4f must be below current price %.4f", stop, mark)
+		}
+		if stop < pos.entryPrice && !bt.cfg.HonorStops {
+			return fmt.Errorf("long stop %.4f is below entry %.4f - stops may only lock in breakeven or profit", stop, pos.entryPrice)
+		}
+	} else {
+		if stop <= mark {
+			return fmt.Errorf("short stop %.4f must be above current price %.4f", stop, mark)
+		}
+		if stop > pos.entryPrice && !bt.cfg.HonorStops {
+			return fmt.Errorf("short stop %.4f is above entry %.4f - stops may only lock in breakeven or profit", stop, pos.entryPrice)
+		}
+	}
+	pos.stopLoss = stop
+	return nil
+}
+
+// close closes quantity of pos at price, banking the P&L net of the entry and exit fees of that quantity
+func (bt *candleBacktest) close(pos *simPosition, quantity, price float64, reason string) {
+	if quantity <= 0 {
+		return
+	}
+	if quantity > pos.quantity {
+		quantity = pos.quantity
+	}
+	share := quantity / pos.quantity
+	margin := pos.margin * share
+	entryFees := pos.entryFees * share
+
+	var gross, exitFee float64
+	if reason == ExitLiquidation {
+		gross = -margin // The margin is lost
+	} else {
+		closed := *pos
+		closed.quantity = quantity
+		gross = closed.unrealized(price)
+		exitFee = quantity * price * bt.cfg.TakerFeeRate
+	}
+	bt.wallet += gross - exitFee
+	bt.result.TotalFees += exitFee
+
+	net := gross - exitFee - entryFees
+	pnlPct := 0.0
+	if margin > 0 {
+		pnlPct = net / margin * 100
+	}
+	bt.result.Trades = append(bt.result.Trades, SimTrade{
+		Symbol:     pos.symbol,
+		Side:       pos.side,
+		Leverage:   pos.leverage,
+		Quantity:   quantity,
+		EntryPrice: pos.entryPrice,
+		ExitPrice:  price,
+		EntryTime:  pos.openedAt,
+		ExitTime:   bt.now,
+		Margin:     margin,
+		Fees:       entryFees + exitFee,
+		PnL:        net,
+		PnLPct:     pnlPct,
+		ExitReason: reason,
+	})
+	bt.result.ExitReasons[reason]++
+
+	pos.quantity -= quantity
+	pos.margin -= margin
+	pos.entryFees -= entryFees
+	if share >= 1 || pos.quantity <= 0 {
+		delete(bt.positions, pos.symbol+"_"+pos.side)
+	}
+	log.Printf("  🔄 [%s] %s %s %s: %.4f @ %.4f, P&L %+.2f USDT",
+		bt.now.Format(time.RFC3339), reason, strings.ToUpper(pos.side), pos.symbol, quantity, price, net)
+}
+
+// closeAll closes every open position at the last price when the backtest ends
+func (bt *candleBacktest) closeAll() {
+	for _, key := range bt.positionKeys() {
+		pos := bt.positions[key]
+		bt.close(pos, pos.quantity, bt.marketFill(pos, false), ExitEndOfTest)
+	}
+	bt.result.EquityCurve = append(bt.result.EquityCurve, EquityPoint{Time: bt.now, Equity: bt.wallet})
+}
+
+// position finds the open position for a decision (side may be empty when only one side is open)
+func (bt *candleBacktest) position(symbol, side string) (*simPosition, error) {
+	if side != "" {
+		if pos, ok := bt.positions[symbol+"_"+strings.ToLower(side)]; ok {
+			return pos, nil
+		}
+		return nil, fmt.Errorf("no %s position for %s", strings.ToLower(side), symbol)
+	}
+	long, hasLong := bt.positions[symbol+"_long"]
+	short, hasShort := bt.positions[symbol+"_short"]
+	switch {
+	case hasLong && hasShort:
+		return nil, fmt.Errorf("%s has both long and short positions - side is required", symbol)
+	case hasLong:
+		return long, nil
+	case hasShort:
+		return short, nil
+	}
+	return nil, fmt.Errorf("no open position for %s", symbol)
+}
+
+// requireProfit rejects closing a losing position (same rule as the live trader)
+func (bt *candleBacktest) requireProfit(pos *simPosition) error {
+	pnl := pos.unrealized(bt.series[pos.symbol].priceAt(bt.now))
+	if pnl < 0 {
+		return fmt.Errorf("position is losing money (P&L: %.2f USDT) - holding until profitable", pnl)
+	}
+	return nil
+}
+
+// marketFill price of a market order on pos at the simulated time (opening = same direction as the position)
+func (bt *candleBacktest) marketFill(pos *simPosition, opening bool) float64 {
+	price := bt.series[pos.symbol].priceAt(bt.now)
+	return bt.slip(price, (pos.side == "long") == opening)
+}
+
+// slip applies adverse slippage to a market fill
+func (bt *candleBacktest) slip(price float64, buy bool) float64 {
+	if buy {
+		return price * (1 + bt.cfg.SlippageBps/10000)
+	}
+	return price * (1 - bt.cfg.SlippageBps/10000)
+}
+
+// equity wallet balance plus unrealized P&L
+func (bt *candleBacktest) equity() float64 {
+	equity := bt.wallet
+	for _, pos := range bt.positions {
+		equity += pos.unrealized(bt.series[pos.symbol].priceAt(bt.now))
+	}
+	return equity
+}
+
+// available equity not tied up as position margin
+func (bt *candleBacktest) available() float64 {
+	available := bt.equity()
+	for _, pos := range bt.positions {
+		available -= pos.margin
+	}
+	return available
+}
+
+// positionKeys open position keys in a stable order
+func (bt *candleBacktest) positionKeys() []string {
+	keys := make([]string, 0, len(bt.positions))
+	for key := range bt.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// finish computes the summary statistics
+func (bt *candleBacktest) finish() {
+	r := bt.result
+	r.FinalEquity = bt.wallet
+	r.TotalPnL = r.FinalEquity - r.InitialBalance
+	r.TotalPnLPct = r.TotalPnL / r.InitialBalance * 100
+	r.TotalTrades = len(r.Trades)
+
+	var grossWin, grossLoss, holdMinutes float64
+	for _, t := range r.Trades {
+		holdMinutes += t.ExitTime.Sub(t.EntryTime).Minutes()
+		if t.PnL > 0 {
+			r.WinningTrades++
+			grossWin += t.PnL
+		} else {
+			r.LosingTrades++
+			grossLoss -= t.PnL
+		}
+	}
+	if r.TotalTrades > 0 {
+		r.WinRate = float64(r.WinningTrades) / float64(r.TotalTrades) * 100
+		r.AvgHoldMinutes = holdMinutes / float64(r.TotalTrades)
+	}
+	if r.WinningTrades > 0 {
+		r.AvgWin = grossWin / float64(r.WinningTrades)
+	}
+	if r.LosingTrades > 0 {
+		r.AvgLoss = -grossLoss / float64(r.LosingTrades)
+	}
+	if grossLoss > 0 {
+		r.ProfitFactor = grossWin / grossLoss
+	} else if grossWin > 0 {
+		r.ProfitFactor = 999.0
+	}
+
+	peak := r.InitialBalance
+	var returns []float64
+	prev := r.InitialBalance
+	for _, p := range r.EquityCurve {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			r.MaxDrawdown = math.Max(r.MaxDrawdown, (peak-p.Equity)/peak*100)
+		}
+		if prev > 0 {
+			returns = append(returns, (p.Equity-prev)/prev)
+		}
+		prev = p.Equity
+	}
+	r.SharpeRatio = annualizedSharpe(returns, bt.cfg.CycleInterval)
+}
+
+// annualizedSharpe Sharpe ratio of per-cycle returns (risk-free rate 0), annualized by the cycle frequency
+func annualizedSharpe(returns []float64, cycle time.Duration) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	periodsPerYear := float64(365*24*time.Hour) / float64(cycle)
+	return mean / std * math.Sqrt(periodsPerYear)
+}
+
+// sortDecisions orders decisions like the live trader: closes, then amends, then opens, then hold/wait
+func sortDecisions(decisions []decision.Decision) []decision.Decision {
+	priority := func(action string) int {
+		switch action {
+		case "close_long", "close_short", "reduce_size":
+			return 1
+		case "adjust_stop", "adjust_target", "add_margin":
+			return 2
+		case "open_long", "open_short", "add_long", "add_short":
+			return 3
+		case "hold", "wait":
+			return 4
+		}
+		return 999
+	}
+	sorted := make([]decision.Decision, len(decisions))
+	copy(sorted, decisions)
+	sort.SliceStable(sorted, func(i, j int) bool { return priority(sorted[i].Action) < priority(sorted[j].Action) })
+	return sorted
+}
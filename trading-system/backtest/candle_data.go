@@ -0,0 +1,137 @@
+package backtest
+
+import (
+	"fmt"
+	"lia/market"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	baseInterval    = "3m"  // Candle interval driving the simulation (the live engine's intraday series)
+	trendInterval   = "4h"  // Longer-term candle interval of the market data
+	intradayWindow  = 40    // 3m candles per market data snapshot (same as live)
+	trendWindow     = 60    // 4h candles per market data snapshot (same as live)
+	minIntradayBars = 26    // Closed 3m candles required before a symbol is tradable (MACD needs 26)
+	csvTrendSuffix  = "_4h" // <SYMBOL>_4h.csv (optional, aggregated from 3m when missing)
+	csvBaseSuffix   = "_3m" // <SYMBOL>_3m.csv
+)
+
+// candleSeries historical candles of one symbol, oldest first
+type candleSeries struct {
+	symbol   string
+	klines3m []market.Kline
+	klines4h []market.Kline
+}
+
+// loadCandleSeries loads each symbol's candles for [start, end] plus the indicator warmup, from CSV files in
+// csvDir when set, otherwise from Binance
+func loadCandleSeries(symbols []string, start, end time.Time, csvDir string) (map[string]*candleSeries, error) {
+	baseStep, _ := market.IntervalDuration(baseInterval)
+	trendStep, _ := market.IntervalDuration(trendInterval)
+	baseFrom := start.Add(-time.Duration(intradayWindow) * baseStep)
+	trendFrom := start.Add(-time.Duration(trendWindow+1) * trendStep)
+
+	series := make(map[string]*candleSeries, len(symbols))
+	for _, symbol := range symbols {
+		var s *candleSeries
+		var err error
+		if csvDir != "" {
+			s, err = loadCSVSeries(symbol, csvDir)
+		} else {
+			s, err = loadBinanceSeries(symbol, baseFrom, trendFrom, end)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(s.klines3m) == 0 {
+			return nil, fmt.Errorf("no %s candles for %s", baseInterval, symbol)
+		}
+		first := time.UnixMilli(s.klines3m[0].OpenTime).UTC()
+		last := time.UnixMilli(s.klines3m[len(s.klines3m)-1].CloseTime).UTC()
+		if first.After(start) || last.Before(end.Add(-baseStep)) {
+			log.Printf("⚠️  %s candles only cover %s → %s (requested %s → %s)", symbol,
+				first.Format(time.RFC3339), last.Format(time.RFC3339), start.Format(time.RFC3339), end.Format(time.RFC3339))
+		}
+		log.Printf("📈 %s: %d %s candles, %d %s candles", symbol, len(s.klines3m), baseInterval, len(s.klines4h), trendInterval)
+		series[symbol] = s
+	}
+	return series, nil
+}
+
+// loadBinanceSeries downloads the candles from Binance futures
+func loadBinanceSeries(symbol string, baseFrom, trendFrom, end time.Time) (*candleSeries, error) {
+	klines3m, err := market.GetKlinesRange(symbol, baseInterval, baseFrom, end)
+	if err != nil {
+		return nil, err
+	}
+	klines4h, err := market.GetKlinesRange(symbol, trendInterval, trendFrom, end)
+	if err != nil {
+		return nil, err
+	}
+	return &candleSeries{symbol: symbol, klines3m: klines3m, klines4h: klines4h}, nil
+}
+
+// loadCSVSeries reads <SYMBOL>_3m.csv and <SYMBOL>_4h.csv from dir (4h candles are aggregated from the 3m
+// file when there is no 4h file, which needs ~10 days of 3m warmup for the 4h indicators)
+func loadCSVSeries(symbol, dir string) (*candleSeries, error) {
+	klines3m, err := market.LoadKlinesCSV(filepath.Join(dir, symbol+csvBaseSuffix+".csv"), baseInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s candles: %w", symbol, err)
+	}
+
+	trendPath := filepath.Join(dir, symbol+csvTrendSuffix+".csv")
+	var klines4h []market.Kline
+	if _, statErr := os.Stat(trendPath); statErr == nil {
+		klines4h, err = market.LoadKlinesCSV(trendPath, trendInterval)
+	} else {
+		klines4h, err = market.AggregateKlines(klines3m, trendInterval)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s candles: %w", symbol, err)
+	}
+	return &candleSeries{symbol: symbol, klines3m: klines3m, klines4h: klines4h}, nil
+}
+
+// closedBefore number of 3m candles closed before t (the candles visible at t)
+func (s *candleSeries) closedBefore(t time.Time) int {
+	ms := t.UnixMilli()
+	return sort.Search(len(s.klines3m), func(i int) bool { return s.klines3m[i].CloseTime >= ms })
+}
+
+// dataAt builds the market data the live engine would have seen at t from the closed candles only: the last
+// 40 3m candles and the last 4h candles, with the current 4h candle rebuilt from the 3m candles so far
+func (s *candleSeries) dataAt(t time.Time) (*market.Data, error) {
+	n := s.closedBefore(t)
+	if n < minIntradayBars {
+		return nil, fmt.Errorf("%s: not enough candle history at %s", s.symbol, t.Format(time.RFC3339))
+	}
+	intraday := s.klines3m[max(0, n-intradayWindow):n]
+
+	trendStep, _ := market.IntervalDuration(trendInterval)
+	currentOpen := t.Truncate(trendStep).UnixMilli()
+	closed := sort.Search(len(s.klines4h), func(i int) bool { return s.klines4h[i].OpenTime >= currentOpen })
+	trend := make([]market.Kline, 0, trendWindow)
+	trend = append(trend, s.klines4h[max(0, closed-trendWindow+1):closed]...)
+
+	// Current (partial) 4h candle from the 3m candles since it opened
+	from := sort.Search(n, func(i int) bool { return s.klines3m[i].OpenTime >= currentOpen })
+	if from < n {
+		partial, _ := market.AggregateKlines(s.klines3m[from:n], trendInterval)
+		trend = append(trend, partial...)
+	}
+
+	return market.BuildData(s.symbol, intraday, trend), nil
+}
+
+// priceAt close of the last candle closed before t (0 if none)
+func (s *candleSeries) priceAt(t time.Time) float64 {
+	n := s.closedBefore(t)
+	if n == 0 {
+		return 0
+	}
+	return s.klines3m[n-1].Close
+}
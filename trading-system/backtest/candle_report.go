@@ -0,0 +1,66 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SaveCandleBacktestResult writes the result (trades and equity curve included) as JSON
+func SaveCandleBacktestResult(result *CandleBacktestResult, path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// PrintCandleBacktestSummary prints a formatted summary of a candle backtest
+func PrintCandleBacktestSummary(result *CandleBacktestResult) {
+	stopMode := "never_close_losers"
+	if result.HonorStops {
+		stopMode = "honor_stops"
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Println("🧪 CANDLE BACKTEST RESULTS")
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("Strategy: %s (stop loss mode: %s)\n", result.Strategy, stopMode)
+	fmt.Printf("Symbols: %s\n", strings.Join(result.Symbols, ", "))
+	fmt.Printf("Period: %s to %s (%d cycles, every %.0f min)\n",
+		result.StartTime.Format("2006-01-02 15:04"), result.EndTime.Format("2006-01-02 15:04"), result.TotalCycles, result.CycleMinutes)
+	fmt.Printf("Costs: %.3f%% taker fee, %.1f bps slippage per fill\n", result.TakerFeeRate*100, result.SlippageBps)
+	fmt.Println(strings.Repeat("-", 80))
+
+	fmt.Printf("Equity: %.2f → %.2f USDT (%+.2f USDT, %+.2f%%)\n",
+		result.InitialBalance, result.FinalEquity, result.TotalPnL, result.TotalPnLPct)
+	fmt.Printf("Fees paid: %.2f USDT\n", result.TotalFees)
+	fmt.Printf("Trades: %d (%d wins / %d losses, win rate %.1f%%)\n",
+		result.TotalTrades, result.WinningTrades, result.LosingTrades, result.WinRate)
+	fmt.Printf("Avg win: %.2f | Avg loss: %.2f | Profit factor: %.2f\n", result.AvgWin, result.AvgLoss, result.ProfitFactor)
+	fmt.Printf("Max drawdown: %.2f%% | Sharpe: %.2f | Avg hold: %.0f min\n",
+		result.MaxDrawdown, result.SharpeRatio, result.AvgHoldMinutes)
+
+	if len(result.ExitReasons) > 0 {
+		reasons := make([]string, 0, len(result.ExitReasons))
+		for reason, n := range result.ExitReasons {
+			reasons = append(reasons, fmt.Sprintf("%s %d", reason, n))
+		}
+		sort.Strings(reasons)
+		fmt.Printf("Exits: %s\n", strings.Join(reasons, ", "))
+	}
+	fmt.Printf("Rejected by validation: %d | Not executed: %d | Strategy errors: %d\n",
+		result.RejectedDecisions, result.FailedActions, result.StrategyErrors)
+	fmt.Println(strings.Repeat("=", 80))
+}
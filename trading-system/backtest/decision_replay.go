@@ -0,0 +1,400 @@
+package backtest
+
+import (
+	"fmt"
+	"lia/decision"
+	"lia/logger"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Replay response sources
+const (
+	ReplaySourceRaw           = "raw_response"  // The AI response as recorded
+	ReplaySourceReconstructed = "reconstructed" // Chain of thought + decision JSON (the raw response was not stored)
+)
+
+// Simulated outcomes of replayed decisions
+const (
+	ReplayTakeProfit   = "take_profit"
+	ReplayStopLoss     = "stop_loss"
+	ReplayExpired      = "expired"  // Neither stop nor target hit within the horizon, closed at market
+	ReplayUnfilled     = "unfilled" // Limit entry never reached within the horizon
+	ReplayClosed       = "closed"   // Close of a position held when the decision was made
+	ReplayNotSimulated = "not_simulated"
+)
+
+// DecisionReplayConfig validation settings and simulation horizon of a decision replay
+type DecisionReplayConfig struct {
+	Equity          float64       // Account equity validation sizes against (0 = the record's)
+	BTCETHLeverage  int           // Maximum BTC/ETH leverage (default 5)
+	AltcoinLeverage int           // Maximum altcoin leverage (default 5)
+	MinConfidence   int           // Minimum confidence for opens (0 = not enforced, as without adaptive confidence)
+	Horizon         time.Duration // Simulated opens still running after this close at market (default 24h)
+	CSVDir          string        // <SYMBOL>_3m.csv candles instead of Binance
+
+	RiskPolicy *decision.RiskPolicy // Limits validation applies (nil = decision.DefaultRiskPolicy)
+}
+
+// applyDefaults fills unset settings
+func (c *DecisionReplayConfig) applyDefaults(record *logger.DecisionRecord) {
+	if c.Equity <= 0 {
+		c.Equity = record.AccountState.TotalBalance
+	}
+	if c.BTCETHLeverage <= 0 {
+		c.BTCETHLeverage = 5
+	}
+	if c.AltcoinLeverage <= 0 {
+		c.AltcoinLeverage = 5
+	}
+	if c.Horizon <= 0 {
+		c.Horizon = 24 * time.Hour
+	}
+}
+
+// ReplayExecution simulated execution of one replayed decision (accepted or rejected by validation)
+type ReplayExecution struct {
+	Symbol       string    `json:"symbol"`
+	Action       string    `json:"action"`
+	Accepted     bool      `json:"accepted"`
+	RejectReason string    `json:"reject_reason,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	EntryPrice   float64   `json:"entry_price,omitempty"`
+	EntryTime    time.Time `json:"entry_time,omitempty"`
+	StopLoss     float64   `json:"stop_loss,omitempty"`
+	TakeProfit   float64   `json:"take_profit,omitempty"`
+	Leverage     int       `json:"leverage,omitempty"`
+	MarginUSD    float64   `json:"margin_usd,omitempty"`
+	Outcome      string    `json:"outcome"`
+	ExitPrice    float64   `json:"exit_price,omitempty"`
+	ExitTime     time.Time `json:"exit_time,omitempty"`
+	PnL          float64   `json:"pnl"`               // USDT, before fees
+	PnLPct       float64   `json:"pnl_pct,omitempty"` // % of margin (opens)
+	Note         string    `json:"note,omitempty"`
+}
+
+// DecisionReplayResult a stored decision re-run through parsing, validation and simulated execution
+type DecisionReplayResult struct {
+	CycleNumber     int                         `json:"cycle_number"`
+	Timestamp       time.Time                   `json:"timestamp"`
+	ResponseSource  string                      `json:"response_source"`
+	Equity          float64                     `json:"equity"`
+	BTCETHLeverage  int                         `json:"btc_eth_leverage"`
+	AltcoinLeverage int                         `json:"altcoin_leverage"`
+	MinConfidence   int                         `json:"min_confidence,omitempty"`
+	Horizon         string                      `json:"horizon"`
+	Decisions       []decision.Decision         `json:"decisions"`
+	Rejected        []decision.RejectedDecision `json:"rejected,omitempty"`
+	ParseError      string                      `json:"parse_error,omitempty"`
+	Executions      []ReplayExecution           `json:"executions"`
+	TotalPnL        float64                     `json:"total_pnl"` // Simulated P&L of the accepted decisions
+
+	// What happened live: the record's outcome and execution log
+	RecordedSuccess bool     `json:"recorded_success"`
+	RecordedError   string   `json:"recorded_error,omitempty"`
+	RecordedLog     []string `json:"recorded_log,omitempty"`
+}
+
+// ReplayDecision re-runs a stored decision record: its AI response goes through the same parse → validate →
+// size-adjust path as live (with cfg's equity, leverage limits and confidence threshold, against the prices at
+// the record's time), then every open - accepted or rejected - is simulated on the candles that followed
+func ReplayDecision(record *logger.DecisionRecord, cfg DecisionReplayConfig) (*DecisionReplayResult, error) {
+	response, source := replayResponse(record)
+	if response == "" {
+		return nil, fmt.Errorf("cycle #%d has neither a raw response nor chain of thought and decision JSON to replay", record.CycleNumber)
+	}
+	cfg.applyDefaults(record)
+	if cfg.Equity <= 0 {
+		return nil, fmt.Errorf("cycle #%d has no account equity: pass one explicitly", record.CycleNumber)
+	}
+
+	prices := &replayPrices{
+		at:      record.Timestamp,
+		horizon: cfg.Horizon,
+		csvDir:  cfg.CSVDir,
+		series:  make(map[string]*candleSeries),
+		errs:    make(map[string]error),
+	}
+	decision.SetPriceSource(prices.price)
+	decision.SetClock(func() time.Time { return record.Timestamp })
+	defer decision.SetPriceSource(nil)
+	defer decision.SetClock(nil)
+
+	ctx := &decision.Context{
+		Account:         decision.AccountInfo{TotalEquity: cfg.Equity, AvailableBalance: record.AccountState.AvailableBalance},
+		BTCETHLeverage:  cfg.BTCETHLeverage,
+		AltcoinLeverage: cfg.AltcoinLeverage,
+		RiskPolicy:      cfg.RiskPolicy,
+		Positions:       replayPositions(record),
+	}
+	if cfg.MinConfidence > 0 {
+		ctx.ConfidenceThreshold = &decision.ConfidenceThreshold{Threshold: cfg.MinConfidence, Reason: "replay setting"}
+	}
+	full, err := decision.ReplayResponse(response, ctx)
+
+	result := &DecisionReplayResult{
+		CycleNumber:     record.CycleNumber,
+		Timestamp:       record.Timestamp,
+		ResponseSource:  source,
+		Equity:          cfg.Equity,
+		BTCETHLeverage:  cfg.BTCETHLeverage,
+		AltcoinLeverage: cfg.AltcoinLeverage,
+		MinConfidence:   cfg.MinConfidence,
+		Horizon:         cfg.Horizon.String(),
+		RecordedSuccess: record.Success,
+		RecordedError:   record.ErrorMessage,
+		RecordedLog:     record.ExecutionLog,
+	}
+	if err != nil {
+		result.ParseError = err.Error()
+	}
+	if full == nil {
+		return result, nil
+	}
+	result.Decisions = full.Decisions
+	result.Rejected = full.Rejected
+
+	for i := range full.Decisions {
+		exec := prices.simulate(&full.Decisions[i], record.Positions)
+		exec.Accepted = true
+		result.TotalPnL += exec.PnL
+		result.Executions = append(result.Executions, exec)
+	}
+	for _, r := range full.Rejected {
+		d := r.Decision
+		exec := prices.simulate(&d, record.Positions)
+		exec.RejectReason = r.Reason
+		exec.Category = r.Category
+		result.Executions = append(result.Executions, exec)
+	}
+	return result, nil
+}
+
+// replayResponse the AI response to replay. The database only keeps the raw response of failed cycles; for
+// the others it is rebuilt from the chain of thought and the decision JSON, which lacks the decisions that
+// validation rejected
+func replayResponse(record *logger.DecisionRecord) (string, string) {
+	if strings.TrimSpace(record.RawResponse) != "" {
+		return record.RawResponse, ReplaySourceRaw
+	}
+	if strings.TrimSpace(record.DecisionJSON) == "" {
+		return "", ""
+	}
+	return record.CoTTrace + "\n\n" + record.DecisionJSON, ReplaySourceReconstructed
+}
+
+// replayPositions the positions held when the record's decisions were made, as far as the record tells: its
+// snapshot (taken after execution) without the positions the cycle itself opened. Validation checks adds and
+// duplicate opens against them
+func replayPositions(record *logger.DecisionRecord) []decision.PositionInfo {
+	openedThisCycle := make(map[string]bool)
+	for _, action := range record.Decisions {
+		if action.Success && (action.Action == "open_long" || action.Action == "open_short") {
+			openedThisCycle[action.Symbol+"_"+strings.TrimPrefix(action.Action, "open_")] = true
+		}
+	}
+	var positions []decision.PositionInfo
+	for _, pos := range record.Positions {
+		if openedThisCycle[pos.Symbol+"_"+pos.Side] {
+			continue
+		}
+		quantity := math.Abs(pos.PositionAmt)
+		leverage := int(pos.Leverage)
+		marginUsed := 0.0
+		if leverage > 0 {
+			marginUsed = quantity * pos.MarkPrice / float64(leverage)
+		}
+		positions = append(positions, decision.PositionInfo{
+			Symbol:           pos.Symbol,
+			Side:             pos.Side,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        pos.MarkPrice,
+			Quantity:         quantity,
+			Leverage:         leverage,
+			UnrealizedPnL:    pos.UnrealizedProfit,
+			LiquidationPrice: pos.LiquidationPrice,
+			MarginUsed:       marginUsed,
+		})
+	}
+	return positions
+}
+
+// replayPrices historical candles around the replayed decision, loaded per symbol on first use
+type replayPrices struct {
+	at      time.Time
+	horizon time.Duration
+	csvDir  string
+	series  map[string]*candleSeries
+	errs    map[string]error
+}
+
+// load the symbol's candles from the decision time to the end of the horizon
+func (p *replayPrices) load(symbol string) (*candleSeries, error) {
+	if s, ok := p.series[symbol]; ok {
+		return s, nil
+	}
+	if err, ok := p.errs[symbol]; ok {
+		return nil, err
+	}
+	series, err := loadCandleSeries([]string{symbol}, p.at, p.at.Add(p.horizon), p.csvDir)
+	if err != nil {
+		p.errs[symbol] = err
+		return nil, err
+	}
+	p.series[symbol] = series[symbol]
+	return series[symbol], nil
+}
+
+// price the symbol's price at the decision time (validation's price source)
+func (p *replayPrices) price(symbol string) (float64, error) {
+	s, err := p.load(symbol)
+	if err != nil {
+		return 0, err
+	}
+	price := s.priceAt(p.at)
+	if price <= 0 {
+		return 0, fmt.Errorf("no %s candle before %s", symbol, p.at.Format(time.RFC3339))
+	}
+	return price, nil
+}
+
+// simulate executes a decision against the candles after the decision time: opens (at market, or at their
+// limit price once reached) run until their stop or target is hit or the horizon ends; closes realize the
+// held position at the decision time's price
+func (p *replayPrices) simulate(d *decision.Decision, positions []logger.PositionSnapshot) ReplayExecution {
+	exec := ReplayExecution{Symbol: d.Symbol, Action: d.Action, Outcome: ReplayNotSimulated}
+	switch d.Action {
+	case "open_long", "open_short", "add_long", "add_short":
+		p.simulateOpen(d, &exec) // An add's quantity runs to the position's stop / target like an open
+	case "close_long", "close_short":
+		p.simulateClose(d, positions, &exec)
+	case "hold", "wait":
+		exec.Note = "nothing to execute"
+	default:
+		exec.Note = "amendments of held positions are not simulated"
+	}
+	return exec
+}
+
+// simulateOpen walks the candles after the decision (a candle touching both stop and target stops out first,
+// as in the rejected trade simulation)
+func (p *replayPrices) simulateOpen(d *decision.Decision, exec *ReplayExecution) {
+	side := decision.EntrySide(d.Action)
+	exec.StopLoss, exec.TakeProfit, exec.Leverage, exec.MarginUSD = d.StopLoss, d.TakeProfit, d.Leverage, d.PositionSizeUSD
+	if d.StopLoss <= 0 || d.TakeProfit <= 0 || d.Leverage <= 0 || d.PositionSizeUSD <= 0 {
+		exec.Note = "missing stop loss, take profit, leverage or size"
+		return
+	}
+	s, err := p.load(d.Symbol)
+	if err != nil {
+		exec.Note = err.Error()
+		return
+	}
+
+	// Candles opened after the decision (the one in progress already had prices before it)
+	from := p.at.UnixMilli()
+	start := sort.Search(len(s.klines3m), func(i int) bool { return s.klines3m[i].OpenTime >= from })
+	deadline := p.at.Add(p.horizon).UnixMilli()
+	limit := d.LimitPrice > 0 && (d.OrderType == "limit" || d.OrderType == "post_only")
+	if !limit {
+		exec.EntryPrice = s.priceAt(p.at)
+		exec.EntryTime = p.at
+		if exec.EntryPrice <= 0 {
+			exec.Note = "no price at the decision time"
+			return
+		}
+	}
+
+	var last *candleSeriesBar
+	for i := start; i < len(s.klines3m) && s.klines3m[i].OpenTime < deadline; i++ {
+		k := s.klines3m[i]
+		last = &candleSeriesBar{close: k.Close, closeTime: time.UnixMilli(k.CloseTime).UTC()}
+		if exec.EntryPrice == 0 {
+			if side == "long" && k.Low > d.LimitPrice || side == "short" && k.High < d.LimitPrice {
+				continue
+			}
+			exec.EntryPrice = d.LimitPrice
+			exec.EntryTime = time.UnixMilli(k.OpenTime).UTC()
+		}
+		stopHit := side == "long" && k.Low <= d.StopLoss || side == "short" && k.High >= d.StopLoss
+		targetHit := side == "long" && k.High >= d.TakeProfit || side == "short" && k.Low <= d.TakeProfit
+		switch {
+		case stopHit:
+			exec.exit(side, ReplayStopLoss, d.StopLoss, last.closeTime)
+			return
+		case targetHit:
+			exec.exit(side, ReplayTakeProfit, d.TakeProfit, last.closeTime)
+			return
+		}
+	}
+	switch {
+	case exec.EntryPrice == 0:
+		exec.Outcome = ReplayUnfilled
+		exec.Note = fmt.Sprintf("limit %.4f not reached", d.LimitPrice)
+	case last == nil:
+		exec.Note = "no candles after the decision"
+	default:
+		exec.exit(side, ReplayExpired, last.close, last.closeTime)
+		if last.closeTime.Before(p.at.Add(p.horizon)) {
+			exec.Note = "candles end before the horizon"
+		}
+	}
+}
+
+// simulateClose realizes the held position (or its close_pct share) at the decision time's price
+func (p *replayPrices) simulateClose(d *decision.Decision, positions []logger.PositionSnapshot, exec *ReplayExecution) {
+	side := strings.TrimPrefix(d.Action, "close_")
+	var held *logger.PositionSnapshot
+	for i := range positions {
+		if positions[i].Symbol == d.Symbol && positions[i].Side == side {
+			held = &positions[i]
+			break
+		}
+	}
+	if held == nil {
+		exec.Note = "no such position in the record"
+		return
+	}
+	price, err := p.price(d.Symbol)
+	if err != nil {
+		exec.Note = err.Error()
+		return
+	}
+	quantity := held.PositionAmt
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	if d.IsPartialClose() {
+		quantity *= d.ClosePct / 100
+	}
+	move := price - held.EntryPrice
+	if side == "short" {
+		move = -move
+	}
+	exec.EntryPrice = held.EntryPrice
+	exec.Outcome = ReplayClosed
+	exec.ExitPrice = price
+	exec.ExitTime = p.at
+	exec.PnL = move * quantity
+}
+
+// candleSeriesBar the last candle walked by a simulation
+type candleSeriesBar struct {
+	close     float64
+	closeTime time.Time
+}
+
+// exit sets the simulated exit of an open
+func (e *ReplayExecution) exit(side, outcome string, price float64, at time.Time) {
+	move := (price - e.EntryPrice) / e.EntryPrice
+	if side == "short" {
+		move = -move
+	}
+	e.Outcome = outcome
+	e.ExitPrice = price
+	e.ExitTime = at
+	e.PnL = e.MarginUSD * float64(e.Leverage) * move
+	e.PnLPct = move * float64(e.Leverage) * 100
+}
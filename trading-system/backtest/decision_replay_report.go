@@ -0,0 +1,71 @@
+package backtest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrintDecisionReplay prints what the replayed decision did under the replay settings next to what happened live
+func PrintDecisionReplay(result *DecisionReplayResult) {
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Printf("🔁 DECISION REPLAY - cycle #%d at %s\n", result.CycleNumber, result.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Println(strings.Repeat("=", 80))
+	source := "recorded raw response"
+	if result.ResponseSource == ReplaySourceReconstructed {
+		source = "rebuilt from chain of thought + decision JSON (decisions rejected live are missing)"
+	}
+	fmt.Printf("Response: %s\n", source)
+	minConfidence := "not enforced"
+	if result.MinConfidence > 0 {
+		minConfidence = fmt.Sprintf("%d", result.MinConfidence)
+	}
+	fmt.Printf("Validation: equity %.2f USDT, max leverage %dx BTC/ETH / %dx altcoins, min confidence %s\n",
+		result.Equity, result.BTCETHLeverage, result.AltcoinLeverage, minConfidence)
+	if result.ParseError != "" {
+		fmt.Printf("⚠️  Pipeline error: %s\n", result.ParseError)
+	}
+
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("Replay (opens simulated for up to %s):\n", result.Horizon)
+	if len(result.Executions) == 0 {
+		fmt.Println("  (no decisions)")
+	}
+	for _, e := range result.Executions {
+		status := "✓ accepted"
+		if !e.Accepted {
+			status = fmt.Sprintf("🚫 rejected (%s): %s", e.Category, e.RejectReason)
+		}
+		fmt.Printf("  %s %s - %s\n", e.Symbol, e.Action, status)
+		switch e.Outcome {
+		case ReplayNotSimulated, ReplayUnfilled:
+			fmt.Printf("      %s: %s\n", e.Outcome, e.Note)
+			continue
+		case ReplayClosed:
+			fmt.Printf("      closed: entry %.4f → %.4f, P&L %+.2f USDT\n", e.EntryPrice, e.ExitPrice, e.PnL)
+		default:
+			fmt.Printf("      %dx, margin %.2f: entry %.4f at %s, stop %.4f / target %.4f\n",
+				e.Leverage, e.MarginUSD, e.EntryPrice, e.EntryTime.Format("01-02 15:04"), e.StopLoss, e.TakeProfit)
+			fmt.Printf("      %s at %.4f (%s): P&L %+.2f USDT (%+.2f%%)\n",
+				e.Outcome, e.ExitPrice, e.ExitTime.Format("01-02 15:04"), e.PnL, e.PnLPct)
+		}
+		if e.Note != "" {
+			fmt.Printf("      note: %s\n", e.Note)
+		}
+	}
+	fmt.Printf("Simulated P&L of accepted decisions: %+.2f USDT (before fees)\n", result.TotalPnL)
+
+	fmt.Println(strings.Repeat("-", 80))
+	outcome := "succeeded"
+	if !result.RecordedSuccess {
+		outcome = "failed"
+	}
+	fmt.Printf("Live: cycle %s", outcome)
+	if result.RecordedError != "" {
+		fmt.Printf(" (%s)", result.RecordedError)
+	}
+	fmt.Println()
+	for _, line := range result.RecordedLog {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println(strings.Repeat("=", 80))
+}
@@ -0,0 +1,391 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"lia/logger"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics the grid report picks a best configuration for
+var gridMetrics = []string{"total_pnl", "sharpe_ratio", "win_rate", "profit_factor", "max_drawdown"}
+
+// GridSpec the parameter values a grid backtest sweeps; every combination is one run
+type GridSpec struct {
+	AutoClosePcts    []float64 `json:"auto_close_pcts"`    // Leveraged P&L % closed early (0 = no auto-close)
+	MaxLeverages     []int     `json:"max_leverages"`      // Leverage cap; capped trades keep their margin (0 = as traded)
+	PositionSizePcts []float64 `json:"position_size_pcts"` // Margin per trade as % of the simulated equity (0 = as traded)
+	MinConfidences   []int     `json:"min_confidences"`    // Opens below this confidence are skipped, as live (0 = all)
+
+	InitialEquity float64 `json:"initial_equity"` // Simulated starting equity (default 10000)
+	Workers       int     `json:"-"`              // Runs in parallel (default the number of CPUs)
+}
+
+// applyDefaults fills unset dimensions with a single neutral value
+func (g *GridSpec) applyDefaults() {
+	if len(g.AutoClosePcts) == 0 {
+		g.AutoClosePcts = []float64{0}
+	}
+	if len(g.MaxLeverages) == 0 {
+		g.MaxLeverages = []int{0}
+	}
+	if len(g.PositionSizePcts) == 0 {
+		g.PositionSizePcts = []float64{0}
+	}
+	if len(g.MinConfidences) == 0 {
+		g.MinConfidences = []int{0}
+	}
+	if g.InitialEquity <= 0 {
+		g.InitialEquity = 10000
+	}
+	if g.Workers <= 0 {
+		g.Workers = runtime.NumCPU()
+	}
+}
+
+// validate rejects values no run can use
+func (g *GridSpec) validate() error {
+	for _, v := range g.AutoClosePcts {
+		if v < 0 {
+			return fmt.Errorf("auto-close %% cannot be negative, got %v", v)
+		}
+	}
+	for _, v := range g.MaxLeverages {
+		if v < 0 {
+			return fmt.Errorf("leverage cap cannot be negative, got %d", v)
+		}
+	}
+	for _, v := range g.PositionSizePcts {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("position size %% must be between 0 and 100, got %v", v)
+		}
+	}
+	for _, v := range g.MinConfidences {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("confidence threshold must be between 0 and 100, got %d", v)
+		}
+	}
+	return nil
+}
+
+// params every combination of the grid, in a fixed order
+func (g *GridSpec) params() []GridParams {
+	var combos []GridParams
+	for _, autoClose := range g.AutoClosePcts {
+		for _, leverage := range g.MaxLeverages {
+			for _, size := range g.PositionSizePcts {
+				for _, confidence := range g.MinConfidences {
+					combos = append(combos, GridParams{
+						AutoClosePct:    autoClose,
+						MaxLeverage:     leverage,
+						PositionSizePct: size,
+						MinConfidence:   confidence,
+					})
+				}
+			}
+		}
+	}
+	return combos
+}
+
+// GridParams one combination of the grid
+type GridParams struct {
+	AutoClosePct    float64 `json:"auto_close_pct"`
+	MaxLeverage     int     `json:"max_leverage"`
+	PositionSizePct float64 `json:"position_size_pct"`
+	MinConfidence   int     `json:"min_confidence"`
+}
+
+// String the combination for reports
+func (p GridParams) String() string {
+	return fmt.Sprintf("auto-close %s, leverage %s, size %s, confidence ≥%d",
+		gridValue(p.AutoClosePct, "%.2f%%", "off"), gridValue(float64(p.MaxLeverage), "%.0fx", "as traded"),
+		gridValue(p.PositionSizePct, "%.1f%%", "as traded"), p.MinConfidence)
+}
+
+// gridValue formats a grid value, zero as zeroLabel
+func gridValue(v float64, format, zeroLabel string) string {
+	if v == 0 {
+		return zeroLabel
+	}
+	return fmt.Sprintf(format, v)
+}
+
+// GridRun the result of one combination
+type GridRun struct {
+	Params        GridParams     `json:"params"`
+	Result        StrategyResult `json:"result"`
+	FinalEquity   float64        `json:"final_equity"`
+	ReturnPct     float64        `json:"return_pct"`
+	SkippedTrades int            `json:"skipped_trades"` // Below the confidence threshold
+}
+
+// GridBacktestResult all runs of a grid backtest and the best configuration per metric
+type GridBacktestResult struct {
+	TraderID    string             `json:"trader_id"`
+	StartTime   time.Time          `json:"start_time"`
+	EndTime     time.Time          `json:"end_time"`
+	TotalCycles int                `json:"total_cycles"`
+	TotalTrades int                `json:"total_trades"` // Completed trades in the decision logs
+	Grid        GridSpec           `json:"grid"`
+	Runs        []GridRun          `json:"runs"`
+	Best        map[string]GridRun `json:"best"` // Metric → run (max_drawdown = lowest, the others highest)
+}
+
+// BacktestGrid replays the trader's completed trades once per grid combination, spreading the runs over
+// spec.Workers goroutines
+func BacktestGrid(traderID string, decisionLogDir string, spec GridSpec) (*GridBacktestResult, error) {
+	spec.applyDefaults()
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	records, err := logger.NewDecisionLogger(decisionLogDir).GetAllRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical records: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no historical records found")
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	trades := extractTrades(records)
+
+	combos := spec.params()
+	log.Printf("🧪 Grid backtest for %s: %d trades, %d combinations on %d workers", traderID, len(trades), len(combos), spec.Workers)
+	runs := runGrid(trades, combos, spec.InitialEquity, spec.Workers)
+
+	return &GridBacktestResult{
+		TraderID:    traderID,
+		StartTime:   records[0].Timestamp,
+		EndTime:     records[len(records)-1].Timestamp,
+		TotalCycles: len(records),
+		TotalTrades: len(trades),
+		Grid:        spec,
+		Runs:        runs,
+		Best:        bestGridRuns(runs),
+	}, nil
+}
+
+// runGrid runs every combination on workers goroutines (runs are in combos order)
+func runGrid(trades []Trade, combos []GridParams, initialEquity float64, workers int) []GridRun {
+	runs := make([]GridRun, len(combos))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				runs[i] = runGridCombination(trades, combos[i], initialEquity)
+			}
+		}()
+	}
+	for i := range combos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return runs
+}
+
+// runGridCombination simulates the trades with one combination's filter, sizing and auto-close
+func runGridCombination(trades []Trade, params GridParams, initialEquity float64) GridRun {
+	kept, skipped := params.filter(trades)
+	result := simulateTrades(kept, params.AutoClosePct, initialEquity, func(_ int, trade Trade, equity float64) (Trade, float64) {
+		return params.resize(trade, equity), params.AutoClosePct
+	})
+	result.TradePnLs = nil // Kept out of the grid report (one row per combination)
+
+	run := GridRun{Params: params, Result: result, FinalEquity: initialEquity + result.TotalPnL, SkippedTrades: skipped}
+	run.ReturnPct = result.TotalPnL / initialEquity * 100
+	return run
+}
+
+// filter the trades the combination's confidence threshold keeps, and how many it skips
+func (p GridParams) filter(trades []Trade) ([]Trade, int) {
+	kept := make([]Trade, 0, len(trades))
+	skipped := 0
+	for _, trade := range trades {
+		if p.MinConfidence > 0 && trade.Confidence < p.MinConfidence {
+			skipped++
+			continue
+		}
+		kept = append(kept, trade)
+	}
+	return kept, skipped
+}
+
+// resize applies the leverage cap (the margin stays, the quantity shrinks) and a margin of PositionSizePct % of
+// equity to a recorded trade, recomputing its actual P&L
+func (p GridParams) resize(trade Trade, equity float64) Trade {
+	if (p.MaxLeverage <= 0 && p.PositionSizePct <= 0) || trade.OpenPrice <= 0 || trade.Leverage <= 0 {
+		return trade
+	}
+	margin := trade.Quantity * trade.OpenPrice / float64(trade.Leverage)
+	if p.PositionSizePct > 0 && equity > 0 {
+		margin = equity * p.PositionSizePct / 100
+	}
+	if p.MaxLeverage > 0 && trade.Leverage > p.MaxLeverage {
+		trade.Leverage = p.MaxLeverage
+	}
+	trade.Quantity = margin * float64(trade.Leverage) / trade.OpenPrice
+
+	if trade.Side == "long" {
+		trade.ActualPnL = trade.Quantity * (trade.ClosePrice - trade.OpenPrice)
+	} else {
+		trade.ActualPnL = trade.Quantity * (trade.OpenPrice - trade.ClosePrice)
+	}
+	if margin > 0 {
+		trade.ActualPnLPct = trade.ActualPnL / margin * 100
+	}
+	return trade
+}
+
+// bestGridRuns the best run per metric (the first in grid order on ties)
+func bestGridRuns(runs []GridRun) map[string]GridRun {
+	best := make(map[string]GridRun, len(gridMetrics))
+	if len(runs) == 0 {
+		return best
+	}
+	for _, metric := range gridMetrics {
+		top := runs[0]
+		for _, run := range runs[1:] {
+			if gridBetter(run.Result, top.Result, metric) {
+				top = run
+			}
+		}
+		best[metric] = top
+	}
+	return best
+}
+
+// gridBetter whether a beats b on metric
+func gridBetter(a, b StrategyResult, metric string) bool {
+	switch metric {
+	case "total_pnl":
+		return a.TotalPnL > b.TotalPnL
+	case "sharpe_ratio":
+		return a.SharpeRatio > b.SharpeRatio
+	case "win_rate":
+		return a.WinRate > b.WinRate
+	case "profit_factor":
+		return a.ProfitFactor > b.ProfitFactor
+	case "max_drawdown":
+		return a.MaxDrawdown < b.MaxDrawdown
+	}
+	return false
+}
+
+// SaveGridBacktestResult writes the grid report as <prefix>.json (every run and the best per metric) and
+// <prefix>.csv (one row per run)
+func SaveGridBacktestResult(result *GridBacktestResult, prefix string) error {
+	if dir := filepath.Dir(prefix); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := os.WriteFile(prefix+".json", data, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+
+	file, err := os.Create(prefix + ".csv")
+	if err != nil {
+		return fmt.Errorf("failed to create CSV: %w", err)
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+	w.Write([]string{
+		"auto_close_pct", "max_leverage", "position_size_pct", "min_confidence",
+		"trades", "skipped_trades", "total_pnl", "return_pct", "final_equity", "win_rate", "profit_factor",
+		"sharpe_ratio", "max_drawdown", "avg_win", "avg_loss", "early_closes", "best_for",
+	})
+	for _, run := range result.Runs {
+		r := run.Result
+		w.Write([]string{
+			formatGridFloat(run.Params.AutoClosePct), strconv.Itoa(run.Params.MaxLeverage),
+			formatGridFloat(run.Params.PositionSizePct), strconv.Itoa(run.Params.MinConfidence),
+			strconv.Itoa(r.TotalTrades), strconv.Itoa(run.SkippedTrades), formatGridFloat(r.TotalPnL),
+			formatGridFloat(run.ReturnPct), formatGridFloat(run.FinalEquity), formatGridFloat(r.WinRate),
+			formatGridFloat(r.ProfitFactor), formatGridFloat(r.SharpeRatio), formatGridFloat(r.MaxDrawdown),
+			formatGridFloat(r.AvgWin), formatGridFloat(r.AvgLoss), strconv.Itoa(r.EarlyCloses),
+			strings.Join(result.bestFor(run.Params), ";"),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// bestFor the metrics the combination is best for
+func (r *GridBacktestResult) bestFor(params GridParams) []string {
+	var metrics []string
+	for _, metric := range gridMetrics {
+		if best, ok := r.Best[metric]; ok && best.Params == params {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// formatGridFloat a CSV number
+func formatGridFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// PrintGridBacktestSummary prints the best configuration per metric and the top runs by Sharpe ratio
+func PrintGridBacktestSummary(result *GridBacktestResult, top int) {
+	fmt.Println("\n" + strings.Repeat("=", 100))
+	fmt.Println("🧪 GRID BACKTEST RESULTS")
+	fmt.Println(strings.Repeat("=", 100))
+	fmt.Printf("Trader: %s\n", result.TraderID)
+	fmt.Printf("Period: %s to %s (%d cycles, %d trades)\n", result.StartTime.Format("2006-01-02 15:04"),
+		result.EndTime.Format("2006-01-02 15:04"), result.TotalCycles, result.TotalTrades)
+	fmt.Printf("Runs: %d from %.2f USDT\n", len(result.Runs), result.Grid.InitialEquity)
+	fmt.Println(strings.Repeat("-", 100))
+
+	fmt.Printf("\n🏆 Best per metric:\n\n")
+	for _, metric := range gridMetrics {
+		run, ok := result.Best[metric]
+		if !ok {
+			continue
+		}
+		r := run.Result
+		fmt.Printf("%-14s %s\n", metric+":", run.Params)
+		fmt.Printf("%-14s P&L: $%.2f (%+.2f%%) | Sharpe: %.2f | Win Rate: %.1f%% | PF: %.2f | Max DD: %.2f%%\n",
+			"", r.TotalPnL, run.ReturnPct, r.SharpeRatio, r.WinRate, r.ProfitFactor, r.MaxDrawdown)
+	}
+
+	ranked := append([]GridRun(nil), result.Runs...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Result.SharpeRatio > ranked[j].Result.SharpeRatio })
+	if top > 0 && len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	fmt.Printf("\n📊 Top %d by Sharpe:\n\n", len(ranked))
+	fmt.Printf("%-8s | %-9s | %-7s | %-5s | %10s | %6s | %7s | %8s | %8s\n",
+		"Auto%", "Leverage", "Size%", "Conf", "Total P&L", "Trades", "Win%", "Sharpe", "Max DD")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, run := range ranked {
+		p, r := run.Params, run.Result
+		fmt.Printf("%-8.2f | %-9d | %-7.1f | %-5d | %10.2f | %6d | %6.1f%% | %8.2f | %7.2f%%\n",
+			p.AutoClosePct, p.MaxLeverage, p.PositionSizePct, p.MinConfidence, r.TotalPnL, r.TotalTrades, r.WinRate, r.SharpeRatio, r.MaxDrawdown)
+	}
+	fmt.Println(strings.Repeat("=", 100))
+}
@@ -0,0 +1,162 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// MonteCarloConfig bootstrap settings: each run draws as many trades as the backtest had, with replacement,
+// from its simulated trade P&Ls and replays them from InitialEquity
+type MonteCarloConfig struct {
+	Runs            int     `json:"runs"`              // Resampled sequences (default 1000)
+	InitialEquity   float64 `json:"initial_equity"`    // Starting equity of every run (default 10000, as the backtest)
+	RuinDrawdownPct float64 `json:"ruin_drawdown_pct"` // A run is ruined once its drawdown reaches this % (default 50)
+	Seed            int64   `json:"seed"`              // Random seed, so reports are reproducible (default 1)
+}
+
+// applyDefaults fills unset settings
+func (c *MonteCarloConfig) applyDefaults() {
+	if c.Runs <= 0 {
+		c.Runs = 1000
+	}
+	if c.InitialEquity <= 0 {
+		c.InitialEquity = 10000
+	}
+	if c.RuinDrawdownPct <= 0 || c.RuinDrawdownPct > 100 {
+		c.RuinDrawdownPct = 50
+	}
+	if c.Seed == 0 {
+		c.Seed = 1
+	}
+}
+
+// PercentileBands a distribution's 5th, 25th, 50th, 75th and 95th percentiles
+type PercentileBands struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+// MonteCarloResult distributions over the resampled trade sequences
+type MonteCarloResult struct {
+	Config          MonteCarloConfig `json:"config"`
+	Trades          int              `json:"trades"`           // Trades per run
+	FinalPnL        PercentileBands  `json:"final_pnl"`        // USDT
+	MaxDrawdown     PercentileBands  `json:"max_drawdown"`     // %
+	LossProbability float64          `json:"loss_probability"` // % of runs ending below the initial equity
+	RuinProbability float64          `json:"ruin_probability"` // % of runs whose drawdown reached RuinDrawdownPct
+}
+
+// MonteCarlo bootstraps the trade P&L sequence (nil = no trades to resample)
+func MonteCarlo(tradePnLs []float64, cfg MonteCarloConfig) *MonteCarloResult {
+	if len(tradePnLs) == 0 {
+		return nil
+	}
+	cfg.applyDefaults()
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	finals := make([]float64, cfg.Runs)
+	drawdowns := make([]float64, cfg.Runs)
+	losses, ruins := 0, 0
+	for run := 0; run < cfg.Runs; run++ {
+		equity, peak, maxDrawdown := cfg.InitialEquity, cfg.InitialEquity, 0.0
+		for range tradePnLs {
+			equity += tradePnLs[rng.Intn(len(tradePnLs))]
+			peak = math.Max(peak, equity)
+			if drawdown := (peak - equity) / peak * 100; drawdown > maxDrawdown {
+				maxDrawdown = drawdown
+			}
+		}
+		finals[run] = equity - cfg.InitialEquity
+		drawdowns[run] = maxDrawdown
+		if equity < cfg.InitialEquity {
+			losses++
+		}
+		if maxDrawdown >= cfg.RuinDrawdownPct {
+			ruins++
+		}
+	}
+
+	return &MonteCarloResult{
+		Config:          cfg,
+		Trades:          len(tradePnLs),
+		FinalPnL:        percentileBands(finals),
+		MaxDrawdown:     percentileBands(drawdowns),
+		LossProbability: float64(losses) / float64(cfg.Runs) * 100,
+		RuinProbability: float64(ruins) / float64(cfg.Runs) * 100,
+	}
+}
+
+// AddMonteCarlo attaches a Monte Carlo simulation to every strategy that recorded its trade P&Ls
+func (r *BacktestResult) AddMonteCarlo(cfg MonteCarloConfig) {
+	if len(r.Strategies) == 0 {
+		return
+	}
+	for i := range r.Strategies {
+		r.Strategies[i].MonteCarlo = MonteCarlo(r.Strategies[i].TradePnLs, cfg)
+	}
+	r.BestStrategy = findBestBySharpe(r.Strategies)
+	r.BestTotalPnL = findBestByTotalPnL(r.Strategies)
+	r.BestWinRate = findBestByWinRate(r.Strategies)
+}
+
+// PrintMonteCarloBands prints each strategy's point estimates next to its Monte Carlo percentile bands
+// (strategies without a simulation are listed as not simulated)
+func PrintMonteCarloBands(strategies []StrategyResult) {
+	var mc *MonteCarloResult
+	for _, s := range strategies {
+		if s.MonteCarlo != nil {
+			mc = s.MonteCarlo
+			break
+		}
+	}
+	if mc == nil {
+		if len(strategies) > 0 {
+			fmt.Println("\n🎲 Monte Carlo: not available (saved without trade P&Ls, rerun cmd/backtest)")
+		}
+		return
+	}
+
+	fmt.Printf("\n🎲 Monte Carlo (%d resampled trade sequences, ruin = %.0f%% drawdown):\n\n", mc.Config.Runs, mc.Config.RuinDrawdownPct)
+	fmt.Printf("%-8s | %10s | %-30s | %8s | %-18s | %6s | %6s\n",
+		"Auto%", "P&L", "P&L p5 / p50 / p95", "Max DD", "DD p50 / p95", "Loss%", "Ruin%")
+	fmt.Println(strings.Repeat("-", 105))
+	for _, s := range strategies {
+		if s.MonteCarlo == nil {
+			fmt.Printf("%-8.1f | %10.2f | %-30s | %7.2f%% | %-18s | %6s | %6s\n", s.AutoClosePct, s.TotalPnL, "not simulated", s.MaxDrawdown, "-", "-", "-")
+			continue
+		}
+		m := s.MonteCarlo
+		fmt.Printf("%-8.1f | %10.2f | %-30s | %7.2f%% | %-18s | %5.1f%% | %5.1f%%\n",
+			s.AutoClosePct, s.TotalPnL, fmt.Sprintf("%.2f / %.2f / %.2f", m.FinalPnL.P5, m.FinalPnL.P50, m.FinalPnL.P95),
+			s.MaxDrawdown, fmt.Sprintf("%.2f%% / %.2f%%", m.MaxDrawdown.P50, m.MaxDrawdown.P95), m.LossProbability, m.RuinProbability)
+	}
+}
+
+// percentileBands the bands of values (sorted in place)
+func percentileBands(values []float64) PercentileBands {
+	sort.Float64s(values)
+	return PercentileBands{
+		P5:  percentile(values, 5),
+		P25: percentile(values, 25),
+		P50: percentile(values, 50),
+		P75: percentile(values, 75),
+		P95: percentile(values, 95),
+	}
+}
+
+// percentile the p-th percentile of sorted values, interpolated between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
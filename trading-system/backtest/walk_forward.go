@@ -0,0 +1,238 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/logger"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WalkForwardConfig rolling train/test windows over the trade history: each fold picks the best grid
+// combination on its train window (in-sample) and trades it on the test window that follows (out-of-sample)
+type WalkForwardConfig struct {
+	Grid           GridSpec
+	TrainWindow    time.Duration // In-sample history per fold
+	TestWindow     time.Duration // Out-of-sample period per fold
+	Step           time.Duration // Shift between folds (default TestWindow, so test windows do not overlap)
+	Metric         string        // Selection metric (default sharpe_ratio, see gridMetrics)
+	MinTrainTrades int           // Folds with fewer train trades are skipped (default 10)
+}
+
+// applyDefaults fills unset settings
+func (c *WalkForwardConfig) applyDefaults() {
+	if len(c.Grid.AutoClosePcts) == 0 {
+		c.Grid.AutoClosePcts = defaultAutoCloseStrategies
+	}
+	c.Grid.applyDefaults()
+	if c.Step <= 0 {
+		c.Step = c.TestWindow
+	}
+	if c.Metric == "" {
+		c.Metric = "sharpe_ratio"
+	}
+	if c.MinTrainTrades <= 0 {
+		c.MinTrainTrades = 10
+	}
+}
+
+// validate rejects windows and metrics the analysis cannot use
+func (c *WalkForwardConfig) validate() error {
+	if c.TrainWindow <= 0 || c.TestWindow <= 0 {
+		return fmt.Errorf("walk-forward needs a train and a test window")
+	}
+	known := false
+	for _, metric := range gridMetrics {
+		known = known || metric == c.Metric
+	}
+	if !known {
+		return fmt.Errorf("unknown metric '%s' (one of %s)", c.Metric, strings.Join(gridMetrics, ", "))
+	}
+	return c.Grid.validate()
+}
+
+// WalkForwardFold one train/test split
+type WalkForwardFold struct {
+	Index       int            `json:"index"`
+	TrainStart  time.Time      `json:"train_start"`
+	TrainEnd    time.Time      `json:"train_end"` // = test start
+	TestEnd     time.Time      `json:"test_end"`
+	TrainTrades int            `json:"train_trades"`
+	TestTrades  int            `json:"test_trades"`
+	Params      GridParams     `json:"params"`        // Chosen in-sample
+	InSample    StrategyResult `json:"in_sample"`     // Chosen params on the train window
+	OutOfSample StrategyResult `json:"out_of_sample"` // Chosen params on the test window
+	Skipped     string         `json:"skipped,omitempty"`
+}
+
+// WalkForwardResult the folds and the out-of-sample performance of the chosen params chained over all test windows
+type WalkForwardResult struct {
+	TraderID       string            `json:"trader_id"`
+	StartTime      time.Time         `json:"start_time"`
+	EndTime        time.Time         `json:"end_time"`
+	TotalTrades    int               `json:"total_trades"`
+	Metric         string            `json:"metric"`
+	TrainWindow    string            `json:"train_window"`
+	TestWindow     string            `json:"test_window"`
+	Step           string            `json:"step"`
+	Grid           GridSpec          `json:"grid"`
+	Folds          []WalkForwardFold `json:"folds"`
+	OutOfSample    StrategyResult    `json:"out_of_sample"`     // All test windows, each with its fold's params, from Grid.InitialEquity
+	OOSReturnPct   float64           `json:"oos_return_pct"`    // Out-of-sample P&L % of the initial equity
+	InSampleSharpe float64           `json:"in_sample_sharpe"`  // Average Sharpe of the chosen params on their train windows
+	FullHistory    GridRun           `json:"full_history_best"` // What optimizing on the entire history picks (in-sample only)
+}
+
+// WalkForward runs the walk-forward analysis on the trader's decision logs
+func WalkForward(traderID string, decisionLogDir string, cfg WalkForwardConfig) (*WalkForwardResult, error) {
+	cfg.applyDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	records, err := logger.NewDecisionLogger(decisionLogDir).GetAllRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical records: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no historical records found")
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	trades := extractTrades(records)
+	if len(trades) == 0 {
+		return nil, fmt.Errorf("no completed trades found")
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].CloseTime.Before(trades[j].CloseTime) })
+
+	combos := cfg.Grid.params()
+	first, last := trades[0].CloseTime, trades[len(trades)-1].CloseTime
+	log.Printf("🧪 Walk-forward for %s: %d trades from %s to %s, train %v / test %v / step %v, %d combinations by %s",
+		traderID, len(trades), first.Format("2006-01-02 15:04"), last.Format("2006-01-02 15:04"),
+		cfg.TrainWindow, cfg.TestWindow, cfg.Step, len(combos), cfg.Metric)
+
+	result := &WalkForwardResult{
+		TraderID:    traderID,
+		StartTime:   records[0].Timestamp,
+		EndTime:     records[len(records)-1].Timestamp,
+		TotalTrades: len(trades),
+		Metric:      cfg.Metric,
+		TrainWindow: cfg.TrainWindow.String(),
+		TestWindow:  cfg.TestWindow.String(),
+		Step:        cfg.Step.String(),
+		Grid:        cfg.Grid,
+		Folds:       []WalkForwardFold{},
+	}
+
+	// Out-of-sample trades of all folds, in order, with the params chosen for their fold
+	var oosTrades []Trade
+	var oosParams []GridParams
+	inSampleSharpe, chosen := 0.0, 0
+	for start := first; !start.Add(cfg.TrainWindow).After(last); start = start.Add(cfg.Step) {
+		fold := WalkForwardFold{
+			Index:      len(result.Folds) + 1,
+			TrainStart: start,
+			TrainEnd:   start.Add(cfg.TrainWindow),
+			TestEnd:    start.Add(cfg.TrainWindow + cfg.TestWindow),
+		}
+		train := tradesClosedBetween(trades, fold.TrainStart, fold.TrainEnd)
+		test := tradesClosedBetween(trades, fold.TrainEnd, fold.TestEnd)
+		fold.TrainTrades, fold.TestTrades = len(train), len(test)
+
+		if len(train) < cfg.MinTrainTrades {
+			fold.Skipped = fmt.Sprintf("%d train trades (minimum %d)", len(train), cfg.MinTrainTrades)
+			result.Folds = append(result.Folds, fold)
+			continue
+		}
+
+		best := bestGridRuns(runGrid(train, combos, cfg.Grid.InitialEquity, cfg.Grid.Workers))[cfg.Metric]
+		fold.Params, fold.InSample = best.Params, best.Result
+		fold.OutOfSample = runGridCombination(test, best.Params, cfg.Grid.InitialEquity).Result
+		inSampleSharpe += best.Result.SharpeRatio
+		chosen++
+
+		kept, _ := best.Params.filter(test)
+		for _, trade := range kept {
+			oosTrades = append(oosTrades, trade)
+			oosParams = append(oosParams, best.Params)
+		}
+		result.Folds = append(result.Folds, fold)
+	}
+
+	result.OutOfSample = simulateTrades(oosTrades, 0, cfg.Grid.InitialEquity, func(i int, trade Trade, equity float64) (Trade, float64) {
+		return oosParams[i].resize(trade, equity), oosParams[i].AutoClosePct
+	})
+	result.OOSReturnPct = result.OutOfSample.TotalPnL / cfg.Grid.InitialEquity * 100
+	if chosen > 0 {
+		result.InSampleSharpe = inSampleSharpe / float64(chosen)
+	}
+	result.FullHistory = bestGridRuns(runGrid(trades, combos, cfg.Grid.InitialEquity, cfg.Grid.Workers))[cfg.Metric]
+	return result, nil
+}
+
+// tradesClosedBetween the trades that closed in [from, to) (a trade belongs to the window it closed in)
+func tradesClosedBetween(trades []Trade, from, to time.Time) []Trade {
+	var window []Trade
+	for _, trade := range trades {
+		if !trade.CloseTime.Before(from) && trade.CloseTime.Before(to) {
+			window = append(window, trade)
+		}
+	}
+	return window
+}
+
+// SaveWalkForwardResult writes the walk-forward report as JSON
+func SaveWalkForwardResult(result *WalkForwardResult, path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// PrintWalkForwardSummary prints the folds and the out-of-sample against the in-sample performance
+func PrintWalkForwardSummary(result *WalkForwardResult) {
+	fmt.Println("\n" + strings.Repeat("=", 140))
+	fmt.Println("🧪 WALK-FORWARD RESULTS")
+	fmt.Println(strings.Repeat("=", 140))
+	fmt.Printf("Trader: %s (%d trades)\n", result.TraderID, result.TotalTrades)
+	fmt.Printf("Windows: train %s, test %s, step %s | Selected by: %s\n", result.TrainWindow, result.TestWindow, result.Step, result.Metric)
+	fmt.Println(strings.Repeat("-", 140))
+
+	fmt.Printf("%-4s | %-16s | %-16s | %5s | %5s | %-60s | %7s | %7s\n",
+		"Fold", "Test from", "Test to", "Train", "Test", "Chosen params", "IS Shp", "OOS Shp")
+	fmt.Println(strings.Repeat("-", 140))
+	for _, fold := range result.Folds {
+		if fold.Skipped != "" {
+			fmt.Printf("%-4d | %-16s | %-16s | %5d | %5d | skipped: %s\n", fold.Index,
+				fold.TrainEnd.Format("2006-01-02 15:04"), fold.TestEnd.Format("2006-01-02 15:04"), fold.TrainTrades, fold.TestTrades, fold.Skipped)
+			continue
+		}
+		fmt.Printf("%-4d | %-16s | %-16s | %5d | %5d | %-60s | %7.2f | %7.2f\n", fold.Index,
+			fold.TrainEnd.Format("2006-01-02 15:04"), fold.TestEnd.Format("2006-01-02 15:04"), fold.TrainTrades, fold.TestTrades,
+			fold.Params, fold.InSample.SharpeRatio, fold.OutOfSample.SharpeRatio)
+	}
+	fmt.Println(strings.Repeat("-", 140))
+
+	oos := result.OutOfSample
+	fmt.Printf("\n📊 Out-of-sample (all test windows chained): %d trades, P&L $%.2f (%+.2f%%), Sharpe %.2f, win rate %.1f%%, max DD %.2f%%\n",
+		oos.TotalTrades, oos.TotalPnL, result.OOSReturnPct, oos.SharpeRatio, oos.WinRate, oos.MaxDrawdown)
+	fmt.Printf("📈 In-sample Sharpe of the chosen params (fold average): %.2f\n", result.InSampleSharpe)
+	full := result.FullHistory
+	fmt.Printf("⚠️  Optimized on the entire history: %s → Sharpe %.2f, P&L $%.2f (in-sample, optimistic)\n",
+		full.Params, full.Result.SharpeRatio, full.Result.TotalPnL)
+	fmt.Println(strings.Repeat("=", 140))
+}
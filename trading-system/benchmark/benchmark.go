@@ -0,0 +1,238 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Benchmark an imported trade history (a human trader, buy-and-hold, ...) replayed into an equity curve, shown
+// in the competition next to the AI traders
+type Benchmark struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	InitialBalance float64   `json:"initial_balance"`
+	Source         string    `json:"source,omitempty"` // Imported CSV file
+	ImportedAt     time.Time `json:"imported_at"`
+	TradeCount     int       `json:"trade_count"` // Opens and closes (marks excluded)
+	Trades         []Trade   `json:"trades"`
+	Curve          []Point   `json:"curve"`
+}
+
+// Trade one row of an imported history
+type Trade struct {
+	Time     time.Time `json:"time"`
+	Symbol   string    `json:"symbol"`
+	Action   string    `json:"action"`             // open_long, open_short, close_long, close_short, mark (buy/sell are resolved on replay)
+	Quantity float64   `json:"quantity,omitempty"` // 0 on a close = the whole position
+	Price    float64   `json:"price"`
+	Fee      float64   `json:"fee,omitempty"`
+	line     int       // CSV line (error messages)
+}
+
+// Point the benchmark's account after a trade or mark
+type Point struct {
+	Time          time.Time `json:"time"`
+	Equity        float64   `json:"equity"`
+	RealizedPnL   float64   `json:"realized_pnl"` // Closed P&L minus every fee paid
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+	PositionCount int       `json:"position_count"`
+}
+
+// validActions the actions a history row may have
+var validActions = map[string]bool{
+	"open_long": true, "open_short": true, "close_long": true, "close_short": true,
+	"buy": true, "sell": true, "mark": true,
+}
+
+// validID benchmark IDs double as file names and trader IDs in the API
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// position an open benchmark position
+type position struct {
+	quantity float64
+	avgPrice float64
+}
+
+// Replay runs the trades in time order from initialBalance and records a point after each one. buy and sell
+// close an opposite position first and open in their direction otherwise; closes larger than the position fail
+func Replay(id, name string, initialBalance float64, trades []Trade) (*Benchmark, error) {
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("invalid benchmark ID '%s' (letters, digits, _ and - only)", id)
+	}
+	if initialBalance <= 0 {
+		return nil, fmt.Errorf("initial balance must be positive")
+	}
+	if name == "" {
+		name = id
+	}
+
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	b := &Benchmark{ID: id, Name: name, InitialBalance: initialBalance, ImportedAt: time.Now().UTC()}
+	positions := make(map[string]*position) // key: symbol_side
+	lastPrice := make(map[string]float64)
+	realized := 0.0
+
+	for _, trade := range sorted {
+		where := fmt.Sprintf("%s %s %s", trade.Time.Format(time.RFC3339), trade.Symbol, trade.Action)
+		if trade.line > 0 {
+			where = fmt.Sprintf("line %d (%s)", trade.line, where)
+		}
+		lastPrice[trade.Symbol] = trade.Price
+		trade.Action = resolveAction(trade.Action, trade.Symbol, positions)
+
+		switch trade.Action {
+		case "open_long", "open_short":
+			key := trade.Symbol + "_" + strings.TrimPrefix(trade.Action, "open_")
+			pos := positions[key]
+			if pos == nil {
+				pos = &position{}
+				positions[key] = pos
+			}
+			pos.avgPrice = (pos.avgPrice*pos.quantity + trade.Price*trade.Quantity) / (pos.quantity + trade.Quantity)
+			pos.quantity += trade.Quantity
+			b.TradeCount++
+		case "close_long", "close_short":
+			side := strings.TrimPrefix(trade.Action, "close_")
+			key := trade.Symbol + "_" + side
+			pos := positions[key]
+			if pos == nil {
+				return nil, fmt.Errorf("%s: no open %s position", where, side)
+			}
+			quantity := trade.Quantity
+			if quantity == 0 {
+				quantity = pos.quantity
+			}
+			if quantity > pos.quantity*(1+1e-9) {
+				return nil, fmt.Errorf("%s: closes %g but only %g is open", where, quantity, pos.quantity)
+			}
+			quantity = math.Min(quantity, pos.quantity)
+			pnl := (trade.Price - pos.avgPrice) * quantity
+			if side == "short" {
+				pnl = -pnl
+			}
+			realized += pnl
+			pos.quantity -= quantity
+			if pos.quantity <= 1e-12 {
+				delete(positions, key)
+			}
+			trade.Quantity = quantity
+			b.TradeCount++
+		}
+		realized -= trade.Fee
+
+		unrealized := 0.0
+		for key, pos := range positions {
+			symbol, side := splitKey(key)
+			pnl := (lastPrice[symbol] - pos.avgPrice) * pos.quantity
+			if side == "short" {
+				pnl = -pnl
+			}
+			unrealized += pnl
+		}
+		b.Trades = append(b.Trades, trade)
+		b.Curve = append(b.Curve, Point{
+			Time:          trade.Time,
+			Equity:        initialBalance + realized + unrealized,
+			RealizedPnL:   realized,
+			UnrealizedPnL: unrealized,
+			PositionCount: len(positions),
+		})
+	}
+	return b, nil
+}
+
+// resolveAction the open/close action of a buy or sell given the open positions
+func resolveAction(action, symbol string, positions map[string]*position) string {
+	switch action {
+	case "buy":
+		if positions[symbol+"_short"] != nil {
+			return "close_short"
+		}
+		return "open_long"
+	case "sell":
+		if positions[symbol+"_long"] != nil {
+			return "close_long"
+		}
+		return "open_short"
+	}
+	return action
+}
+
+// splitKey symbol and side of a position key
+func splitKey(key string) (string, string) {
+	i := strings.LastIndex(key, "_")
+	return key[:i], key[i+1:]
+}
+
+// Latest the last point of the curve (the initial balance before any trade)
+func (b *Benchmark) Latest() Point {
+	if len(b.Curve) == 0 {
+		return Point{Time: b.ImportedAt, Equity: b.InitialBalance}
+	}
+	return b.Curve[len(b.Curve)-1]
+}
+
+// Symbols the symbols the benchmark traded
+func Symbols(trades []Trade) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, trade := range trades {
+		if trade.Action != "mark" && !seen[trade.Symbol] {
+			seen[trade.Symbol] = true
+			symbols = append(symbols, trade.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Save writes the benchmark to dir/<id>.json
+func (b *Benchmark) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, b.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// LoadAll reads every benchmark in dir, by ID (none when the directory does not exist)
+func LoadAll(dir string) ([]*Benchmark, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var benchmarks []*Benchmark
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var b Benchmark
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if !validID.MatchString(b.ID) {
+			return nil, fmt.Errorf("%s: invalid benchmark ID '%s'", path, b.ID)
+		}
+		benchmarks = append(benchmarks, &b)
+	}
+	sort.Slice(benchmarks, func(i, j int) bool { return benchmarks[i].ID < benchmarks[j].ID })
+	return benchmarks, nil
+}
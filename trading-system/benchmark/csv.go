@@ -0,0 +1,125 @@
+package benchmark
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns accepted header names of each field
+var csvColumns = map[string][]string{
+	"time":     {"time", "timestamp", "date", "datetime"},
+	"symbol":   {"symbol", "pair", "coin"},
+	"action":   {"action", "side", "type"},
+	"quantity": {"quantity", "qty", "size", "amount"},
+	"price":    {"price", "fill_price", "avg_price"},
+	"fee":      {"fee", "fees", "commission"},
+}
+
+// ParseCSV reads a trade history with a header row: time,symbol,action,quantity,price[,fee] (columns in any
+// order, see csvColumns for alternative names). Actions are open_long, open_short, close_long, close_short,
+// buy, sell or mark (a price observation that revalues open positions)
+func ParseCSV(r io.Reader) ([]Trade, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for field, names := range csvColumns {
+			for _, alias := range names {
+				if name == alias {
+					if _, ok := index[field]; !ok {
+						index[field] = i
+					}
+				}
+			}
+		}
+	}
+	for _, field := range []string{"time", "symbol", "action", "price"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("missing '%s' column (header: %s)", field, strings.Join(header, ","))
+		}
+	}
+
+	var trades []Trade
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value := func(field string) string {
+			if i, ok := index[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		if strings.Join(row, "") == "" {
+			continue
+		}
+
+		trade := Trade{
+			Symbol: strings.ToUpper(value("symbol")),
+			Action: strings.ToLower(value("action")),
+			line:   line,
+		}
+		if trade.Time, err = parseTime(value("time")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !validActions[trade.Action] {
+			return nil, fmt.Errorf("line %d: unknown action '%s'", line, value("action"))
+		}
+		if trade.Price, err = parseNumber(value("price")); err != nil || trade.Price <= 0 {
+			return nil, fmt.Errorf("line %d: invalid price '%s'", line, value("price"))
+		}
+		if trade.Quantity, err = parseNumber(value("quantity")); err != nil || trade.Quantity < 0 {
+			return nil, fmt.Errorf("line %d: invalid quantity '%s'", line, value("quantity"))
+		}
+		if trade.Quantity == 0 && (trade.Action == "buy" || trade.Action == "sell" || strings.HasPrefix(trade.Action, "open_")) {
+			return nil, fmt.Errorf("line %d: %s needs a quantity", line, trade.Action)
+		}
+		if trade.Fee, err = parseNumber(value("fee")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid fee '%s'", line, value("fee"))
+		}
+		trades = append(trades, trade)
+	}
+	if len(trades) == 0 {
+		return nil, fmt.Errorf("no trades")
+	}
+	return trades, nil
+}
+
+// parseNumber a CSV number (empty = 0)
+func parseNumber(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseTime RFC3339, "2006-01-02 15:04:05" (UTC), a date, or Unix seconds / milliseconds
+func parseTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s'", s)
+}
@@ -0,0 +1,89 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/config"
+	"time"
+)
+
+// Topics published by the trading engine
+const (
+	TopicStatus      = "status"
+	TopicAccount     = "account"
+	TopicPositions   = "positions"
+	TopicDecisions   = "decisions" // Latest decision records, newest first
+	TopicStatistics  = "statistics"
+	TopicContext     = "context"     // Context of the latest AI decision
+	TopicCompetition = "competition" // System-wide: comparison data of all traders
+	TopicTraders     = "traders"     // System-wide: trader list
+)
+
+const (
+	dialTimeout       = 5 * time.Second
+	writeTimeout      = 5 * time.Second
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// Bus a publish/subscribe transport between the trading engine and the API process
+type Bus interface {
+	// Publish sends data on subject (fire-and-forget: subscribers that are not connected miss it)
+	Publish(subject string, data []byte) error
+	// SubscribeAll delivers every message published under prefix to handler until Close,
+	// reconnecting in the background when the connection drops
+	SubscribeAll(prefix string, handler func(data []byte)) error
+	// Close releases the connections
+	Close() error
+}
+
+// Message envelope of a published state snapshot
+type Message struct {
+	TraderID string          `json:"trader_id,omitempty"` // Empty for system-wide topics
+	Topic    string          `json:"topic"`
+	Time     time.Time       `json:"time"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Subject the channel/subject a message is published on: <prefix>.trader.<id>.<topic> or <prefix>.system.<topic>
+func Subject(prefix string, msg Message) string {
+	if msg.TraderID == "" {
+		return fmt.Sprintf("%s.system.%s", prefix, msg.Topic)
+	}
+	return fmt.Sprintf("%s.trader.%s.%s", prefix, msg.TraderID, msg.Topic)
+}
+
+// PublishJSON wraps v in a Message and publishes it under prefix
+func PublishJSON(b Bus, prefix, traderID, topic string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s: %w", topic, err)
+	}
+	msg := Message{TraderID: traderID, Topic: topic, Time: time.Now(), Data: data}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s message: %w", topic, err)
+	}
+	return b.Publish(Subject(prefix, msg), payload)
+}
+
+// New connects to the configured message bus
+func New(cfg config.MessageBusConfig) (Bus, error) {
+	switch cfg.Type {
+	case config.BusRedis:
+		return newRedisBus(cfg.URL)
+	case config.BusNATS:
+		return newNATSBus(cfg.URL)
+	default:
+		return nil, fmt.Errorf("unsupported message bus type: %s", cfg.Type)
+	}
+}
+
+// nextDelay doubles a reconnect delay up to reconnectMaxDelay
+func nextDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > reconnectMaxDelay {
+		return reconnectMaxDelay
+	}
+	return delay
+}
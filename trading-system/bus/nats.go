@@ -0,0 +1,270 @@
+package bus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsSub a subscription re-sent after every reconnect
+type natsSub struct {
+	subject string
+	handler func(data []byte)
+}
+
+// natsBus NATS core protocol client (one connection for publishing and subscriptions)
+type natsBus struct {
+	addr  string
+	user  string
+	pass  string
+	token string
+
+	mu     sync.Mutex // Guards conn and writes to it
+	conn   net.Conn
+	subs   []natsSub // sid = index + 1
+	closed chan struct{}
+}
+
+// newNATSBus parses nats://[user:pass@|token@]host[:port] and connects
+func newNATSBus(rawURL string) (*natsBus, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("invalid nats url '%s' (expected nats://[user:pass@]host:port)", rawURL)
+	}
+	b := &natsBus{addr: u.Host, closed: make(chan struct{})}
+	if u.Port() == "" {
+		b.addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			b.user, b.pass = u.User.Username(), pass
+		} else {
+			b.token = u.User.Username()
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.connectLocked(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// connectLocked dials, performs the CONNECT/PING handshake, re-sends subscriptions and starts
+// the read loop (caller holds mu)
+func (b *natsBus) connectLocked() error {
+	conn, err := net.DialTimeout("tcp", b.addr, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats %s: %w", b.addr, err)
+	}
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(dialTimeout))
+	if line, err := readLine(reader); err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("nats handshake failed: expected INFO (%v)", err)
+	}
+
+	options, _ := json.Marshal(map[string]interface{}{
+		"verbose":    false,
+		"pedantic":   false,
+		"name":       "lia",
+		"lang":       "go",
+		"version":    "1.0",
+		"user":       b.user,
+		"pass":       b.pass,
+		"auth_token": b.token,
+	})
+	if err := writeAll(conn, []byte("CONNECT "+string(options)+"\r\nPING\r\n")); err != nil {
+		conn.Close()
+		return err
+	}
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("nats handshake failed: %w", err)
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		if line == "PONG" {
+			break
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	for i, sub := range b.subs {
+		if err := writeAll(conn, []byte(fmt.Sprintf("SUB %s %d\r\n", sub.subject, i+1))); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	b.conn = conn
+	go b.readLoop(conn, reader)
+	return nil
+}
+
+// Publish PUB subject data (reconnects once if the connection dropped)
+func (b *natsBus) Publish(subject string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	frame := make([]byte, 0, len(subject)+len(data)+32)
+	frame = append(frame, "PUB "+subject+" "+strconv.Itoa(len(data))+"\r\n"...)
+	frame = append(frame, data...)
+	frame = append(frame, '\r', '\n')
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.conn == nil {
+			if err = b.connectLocked(); err != nil {
+				continue
+			}
+		}
+		if err = writeAll(b.conn, frame); err == nil {
+			return nil
+		}
+		b.conn.Close()
+		b.conn = nil
+	}
+	return fmt.Errorf("nats publish to %s failed: %w", subject, err)
+}
+
+// SubscribeAll SUB <prefix>.> (restored automatically after reconnects)
+func (b *natsBus) SubscribeAll(prefix string, handler func(data []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, natsSub{subject: prefix + ".>", handler: handler})
+	if b.conn == nil {
+		return b.connectLocked() // Sends every subscription
+	}
+	return writeAll(b.conn, []byte(fmt.Sprintf("SUB %s %d\r\n", prefix+".>", len(b.subs))))
+}
+
+// readLoop handles MSG/PING/-ERR until the connection fails, then reconnects if there are subscriptions
+func (b *natsBus) readLoop(conn net.Conn, reader *bufio.Reader) {
+	err := b.readFrames(conn, reader)
+	conn.Close()
+
+	b.mu.Lock()
+	if b.conn == conn {
+		b.conn = nil
+	}
+	resubscribe := len(b.subs) > 0
+	b.mu.Unlock()
+
+	select {
+	case <-b.closed:
+		return
+	default:
+	}
+	log.Printf("⚠️  NATS connection lost: %v", err)
+	if resubscribe {
+		b.reconnect()
+	}
+}
+
+// readFrames processes server frames until an error
+func (b *natsBus) readFrames(conn net.Conn, reader *bufio.Reader) error {
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				return fmt.Errorf("malformed MSG: %q", line)
+			}
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return fmt.Errorf("malformed MSG size: %q", line)
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return err
+			}
+			sid, _ := strconv.Atoi(fields[2])
+			b.mu.Lock()
+			var handler func(data []byte)
+			if sid >= 1 && sid <= len(b.subs) {
+				handler = b.subs[sid-1].handler
+			}
+			b.mu.Unlock()
+			if handler != nil {
+				handler(payload[:size])
+			}
+		case line == "PING":
+			b.mu.Lock()
+			err := writeAll(conn, []byte("PONG\r\n"))
+			b.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			log.Printf("⚠️  NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// reconnect re-establishes the connection with backoff (unless a publish already did)
+func (b *natsBus) reconnect() {
+	delay := reconnectMinDelay
+	for {
+		select {
+		case <-b.closed:
+			return
+		case <-time.After(delay):
+		}
+		b.mu.Lock()
+		if b.conn != nil {
+			b.mu.Unlock()
+			return
+		}
+		err := b.connectLocked()
+		b.mu.Unlock()
+		if err == nil {
+			log.Printf("✓ NATS connection restored (%s)", b.addr)
+			return
+		}
+		delay = nextDelay(delay)
+		log.Printf("⚠️  NATS reconnect failed: %v - retrying in %s", err, delay)
+	}
+}
+
+// Close closes the connection and stops reconnecting
+func (b *natsBus) Close() error {
+	select {
+	case <-b.closed:
+		return nil
+	default:
+		close(b.closed)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn != nil {
+		err := b.conn.Close()
+		b.conn = nil
+		return err
+	}
+	return nil
+}
+
+// writeAll writes data with the write timeout
+func writeAll(conn net.Conn, data []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := conn.Write(data)
+	return err
+}
@@ -0,0 +1,283 @@
+package bus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// redisBus Redis pub/sub over RESP (PUBLISH on one connection, PSUBSCRIBE on another)
+type redisBus struct {
+	addr     string
+	username string // Redis 6 ACL user (empty = default user)
+	password string
+
+	mu     sync.Mutex // Guards the publish connection
+	conn   net.Conn
+	reader *bufio.Reader
+
+	closed  chan struct{}
+	subMu   sync.Mutex
+	subConn net.Conn
+}
+
+// newRedisBus parses redis://[[user]:password@]host[:port] and connects the publish connection
+func newRedisBus(rawURL string) (*redisBus, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid redis url '%s' (expected redis://[:password@]host:port)", rawURL)
+	}
+	b := &redisBus{addr: u.Host, closed: make(chan struct{})}
+	if u.Port() == "" {
+		b.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if password, ok := u.User.Password(); ok {
+		b.username, b.password = u.User.Username(), password
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.connectLocked(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// dial opens an authenticated connection
+func (b *redisBus) dial() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", b.addr, dialTimeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to redis %s: %w", b.addr, err)
+	}
+	reader := bufio.NewReader(conn)
+	if b.password != "" {
+		args := []string{"AUTH", b.password}
+		if b.username != "" {
+			args = []string{"AUTH", b.username, b.password}
+		}
+		if err := writeCommand(conn, args...); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		if _, err := readReply(reader); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("redis AUTH failed: %w", err)
+		}
+	}
+	return conn, reader, nil
+}
+
+// connectLocked (re)opens the publish connection (caller holds mu)
+func (b *redisBus) connectLocked() error {
+	conn, reader, err := b.dial()
+	if err != nil {
+		return err
+	}
+	b.conn, b.reader = conn, reader
+	return nil
+}
+
+// Publish PUBLISH subject data (reconnects once if the connection dropped)
+func (b *redisBus) Publish(subject string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.conn == nil {
+			if err = b.connectLocked(); err != nil {
+				continue
+			}
+		}
+		if err = writeCommand(b.conn, "PUBLISH", subject, string(data)); err == nil {
+			b.conn.SetReadDeadline(time.Now().Add(writeTimeout))
+			if _, err = readReply(b.reader); err == nil {
+				return nil
+			}
+		}
+		b.conn.Close()
+		b.conn = nil
+	}
+	return fmt.Errorf("redis publish to %s failed: %w", subject, err)
+}
+
+// SubscribeAll PSUBSCRIBE <prefix>.* on a dedicated connection, resubscribing after disconnects
+func (b *redisBus) SubscribeAll(prefix string, handler func(data []byte)) error {
+	conn, reader, err := b.dial()
+	if err != nil {
+		return err
+	}
+	pattern := prefix + ".*"
+	if err := writeCommand(conn, "PSUBSCRIBE", pattern); err != nil {
+		conn.Close()
+		return err
+	}
+
+	go func() {
+		delay := reconnectMinDelay
+		for {
+			b.subMu.Lock()
+			b.subConn = conn
+			b.subMu.Unlock()
+
+			err := b.readMessages(reader, handler)
+			conn.Close()
+			select {
+			case <-b.closed:
+				return
+			default:
+			}
+			log.Printf("⚠️  Redis subscription lost: %v - reconnecting in %s", err, delay)
+
+			for {
+				select {
+				case <-b.closed:
+					return
+				case <-time.After(delay):
+				}
+				conn, reader, err = b.dial()
+				if err == nil {
+					if err = writeCommand(conn, "PSUBSCRIBE", pattern); err == nil {
+						break
+					}
+					conn.Close()
+				}
+				delay = nextDelay(delay)
+				log.Printf("⚠️  Redis reconnect failed: %v - retrying in %s", err, delay)
+			}
+			log.Printf("✓ Redis subscription restored (%s)", pattern)
+			delay = reconnectMinDelay
+		}
+	}()
+	return nil
+}
+
+// readMessages delivers pmessage payloads until the connection fails
+func (b *redisBus) readMessages(reader *bufio.Reader, handler func(data []byte)) error {
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		// ["pmessage", pattern, channel, payload]; subscribe confirmations are skipped
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 4 {
+			continue
+		}
+		if kind, _ := parts[0].(string); kind != "pmessage" {
+			continue
+		}
+		if payload, ok := parts[3].(string); ok {
+			handler([]byte(payload))
+		}
+	}
+}
+
+// Close closes both connections and stops resubscribing
+func (b *redisBus) Close() error {
+	select {
+	case <-b.closed:
+		return nil
+	default:
+		close(b.closed)
+	}
+	b.subMu.Lock()
+	if b.subConn != nil {
+		b.subConn.Close()
+	}
+	b.subMu.Unlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn != nil {
+		return b.conn.Close()
+	}
+	return nil
+}
+
+// writeCommand writes a RESP array of bulk strings
+func writeCommand(conn net.Conn, args ...string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := conn.Write(buf)
+	return err
+}
+
+// readReply reads one RESP reply: simple strings and bulk strings as string, integers as int64,
+// arrays as []interface{}, nil bulk/array as nil; error replies are returned as errors
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty redis reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis bulk length: %w", err)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis array length: %w", err)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply: %q", line)
+	}
+}
+
+// readLine reads a CRLF-terminated line without the terminator
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed line: %q", line)
+	}
+	return line[:len(line)-2], nil
+}
@@ -2,14 +2,47 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"lia/backtest"
 	"log"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// backtest replays a trader's completed trades from its decision logs.
+//
+//	go run ./cmd/backtest -trader qwen_trader_single -dir decision_logs/qwen_trader_single
+//	go run ./cmd/backtest -trader qwen_trader_single -dir decision_logs/qwen_trader_single \
+//	    -auto-close 0,1,2,3 -max-leverage 0,5,10 -position-size 0,10,20 -min-confidence 0,70,80
+//	go run ./cmd/backtest -trader qwen_trader_single -dir decision_logs/qwen_trader_single \
+//	    -walk-forward -train-days 14 -test-days 7 -auto-close 0,1,2,3 -max-leverage 0,5,10
+//
+// Without grid flags it runs the auto-close sweep with a Monte Carlo of each strategy (backtest_<time>.json). Any of -auto-close, -max-leverage,
+// -position-size or -min-confidence runs every combination in parallel and writes backtest_grid_<time>.json
+// and .csv with the best configuration per metric. -walk-forward picks the best combination on rolling train
+// windows instead, trades it on the test window that follows and writes backtest_walkforward_<time>.json
 func main() {
 	traderID := flag.String("trader", "", "Trader ID to backtest (e.g., qwen_trader_single)")
 	decisionLogDir := flag.String("dir", "", "Decision logs directory (e.g., decision_logs/qwen_trader_single)")
+	autoClose := flag.String("auto-close", "", "grid: comma-separated auto-close % values (0 = off)")
+	maxLeverage := flag.String("max-leverage", "", "grid: comma-separated leverage caps (0 = as traded)")
+	positionSize := flag.String("position-size", "", "grid: comma-separated margin per trade as % of equity (0 = as traded)")
+	minConfidence := flag.String("min-confidence", "", "grid: comma-separated confidence thresholds (0 = all trades)")
+	equity := flag.Float64("equity", 10000, "grid: simulated starting equity in USDT")
+	workers := flag.Int("workers", 0, "grid: runs in parallel (default the number of CPUs)")
+	top := flag.Int("top", 20, "grid: runs listed in the summary")
+	output := flag.String("out", "", "grid / walk-forward: report path without extension (default <dir>/backtest_grid_<time>)")
+	walkForward := flag.Bool("walk-forward", false, "walk-forward analysis over the grid (default grid: the auto-close sweep)")
+	trainDays := flag.Float64("train-days", 14, "walk-forward: in-sample window in days")
+	testDays := flag.Float64("test-days", 7, "walk-forward: out-of-sample window in days")
+	stepDays := flag.Float64("step-days", 0, "walk-forward: shift between folds in days (default -test-days)")
+	metric := flag.String("metric", "sharpe_ratio", "walk-forward: selection metric (total_pnl, sharpe_ratio, win_rate, profit_factor, max_drawdown)")
+	minTrainTrades := flag.Int("min-train-trades", 10, "walk-forward: skip folds with fewer train trades")
+	mcRuns := flag.Int("mc-runs", 1000, "auto-close sweep: Monte Carlo resampled trade sequences per strategy")
+	ruinDrawdown := flag.Float64("ruin-dd", 50, "auto-close sweep: drawdown % counted as ruin in the Monte Carlo")
+	seed := flag.Int64("seed", 1, "auto-close sweep: Monte Carlo random seed")
 	flag.Parse()
 
 	if *traderID == "" || *decisionLogDir == "" {
@@ -25,8 +58,101 @@ func main() {
 	log.Printf("🧪 Starting backtest for trader: %s", *traderID)
 	log.Printf("📁 Decision logs directory: %s", absDir)
 
-	if err := backtest.RunBacktest(*traderID, absDir); err != nil {
+	if !*walkForward && *autoClose == "" && *maxLeverage == "" && *positionSize == "" && *minConfidence == "" {
+		mc := backtest.MonteCarloConfig{Runs: *mcRuns, RuinDrawdownPct: *ruinDrawdown, Seed: *seed}
+		if err := backtest.RunBacktest(*traderID, absDir, mc); err != nil {
+			log.Fatalf("Backtest failed: %v", err)
+		}
+		return
+	}
+
+	spec := backtest.GridSpec{InitialEquity: *equity, Workers: *workers}
+	if spec.AutoClosePcts, err = parseFloats(*autoClose); err != nil {
+		log.Fatalf("Invalid -auto-close: %v", err)
+	}
+	if spec.MaxLeverages, err = parseInts(*maxLeverage); err != nil {
+		log.Fatalf("Invalid -max-leverage: %v", err)
+	}
+	if spec.PositionSizePcts, err = parseFloats(*positionSize); err != nil {
+		log.Fatalf("Invalid -position-size: %v", err)
+	}
+	if spec.MinConfidences, err = parseInts(*minConfidence); err != nil {
+		log.Fatalf("Invalid -min-confidence: %v", err)
+	}
+
+	if *walkForward {
+		day := float64(24 * time.Hour)
+		cfg := backtest.WalkForwardConfig{
+			Grid:           spec,
+			TrainWindow:    time.Duration(*trainDays * day),
+			TestWindow:     time.Duration(*testDays * day),
+			Step:           time.Duration(*stepDays * day),
+			Metric:         *metric,
+			MinTrainTrades: *minTrainTrades,
+		}
+		result, err := backtest.WalkForward(*traderID, absDir, cfg)
+		if err != nil {
+			log.Fatalf("Walk-forward failed: %v", err)
+		}
+		path := *output
+		if path == "" {
+			path = filepath.Join(absDir, fmt.Sprintf("backtest_walkforward_%s", time.Now().Format("20060102_150405")))
+		}
+		path = strings.TrimSuffix(path, ".json") + ".json"
+		if err := backtest.SaveWalkForwardResult(result, path); err != nil {
+			log.Fatalf("Failed to save results: %v", err)
+		}
+		log.Printf("✅ Walk-forward complete! Results saved to: %s", path)
+		backtest.PrintWalkForwardSummary(result)
+		return
+	}
+
+	result, err := backtest.BacktestGrid(*traderID, absDir, spec)
+	if err != nil {
 		log.Fatalf("Backtest failed: %v", err)
 	}
+
+	prefix := *output
+	if prefix == "" {
+		prefix = filepath.Join(absDir, fmt.Sprintf("backtest_grid_%s", time.Now().Format("20060102_150405")))
+	}
+	prefix = strings.TrimSuffix(strings.TrimSuffix(prefix, ".json"), ".csv")
+	if err := backtest.SaveGridBacktestResult(result, prefix); err != nil {
+		log.Fatalf("Failed to save results: %v", err)
+	}
+	log.Printf("✅ Grid backtest complete! Results saved to: %s.json and %s.csv", prefix, prefix)
+
+	backtest.PrintGridBacktestSummary(result, *top)
 }
 
+// parseFloats a comma-separated list of numbers ("" = none)
+func parseFloats(s string) ([]float64, error) {
+	var values []float64
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// parseInts a comma-separated list of integers ("" = none)
+func parseInts(s string) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
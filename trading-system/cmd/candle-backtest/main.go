@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"lia/backtest"
+	"lia/config"
+	"lia/decision"
+	"lia/mcp"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// candle-backtest replays historical candles through a decision strategy with simulated fills.
+//
+//	go run ./cmd/candle-backtest -symbols BTCUSDT,ETHUSDT -start 2026-09-01 -end 2026-09-15 -strategy ema_trend
+//	go run ./cmd/candle-backtest -config config.json -trader ema_trader -symbols SOLUSDT -start 2026-09-01 -end 2026-09-08
+//	go run ./cmd/candle-backtest -csv data/ -symbols BTCUSDT -start 2026-01-01 -end 2026-02-01 -honor-stops
+//
+// With -config/-trader the trader's strategy, strategy_params, initial balance, stop_loss_mode and AI
+// settings (and the global leverage) are used; explicit flags override them
+func main() {
+	configFile := flag.String("config", "", "config.json to take the trader settings from")
+	traderID := flag.String("trader", "", "trader ID in the config")
+	symbols := flag.String("symbols", "", "comma-separated symbols (e.g. BTCUSDT,ETHUSDT)")
+	start := flag.String("start", "", "start time (2006-01-02 or RFC3339, UTC)")
+	end := flag.String("end", "", "end time (2006-01-02 or RFC3339, UTC; default now)")
+	cycle := flag.Duration("cycle", 3*time.Minute, "time between decision cycles (multiple of 3m)")
+	strategy := flag.String("strategy", "", "strategy name (default: the trader's strategy, or ai)")
+	params := flag.String("params", "", "strategy_params JSON")
+	balance := flag.Float64("balance", 0, "initial balance in USDT (default: trader's, or 1000)")
+	btcEthLeverage := flag.Int("btc-eth-leverage", 0, "maximum BTC/ETH leverage (default: config, or 5)")
+	altcoinLeverage := flag.Int("altcoin-leverage", 0, "maximum altcoin leverage (default: config, or 5)")
+	honorStops := flag.Bool("honor-stops", false, "place and fill stop orders (stop_loss_mode honor_stops)")
+	fee := flag.Float64("fee", 0, "taker fee per fill as a fraction of notional (default 0.0004, -1 = none)")
+	slippage := flag.Float64("slippage-bps", 0, "adverse slippage per market fill in bps (default 5, -1 = none)")
+	autoClose := flag.Float64("auto-close", 0, "leveraged profit % closed by the background monitor (default 4.5, -1 = off)")
+	csvDir := flag.String("csv", "", "directory with <SYMBOL>_3m.csv (and optional <SYMBOL>_4h.csv) instead of Binance")
+	output := flag.String("out", "", "result JSON file (default backtest_results/candles_<strategy>_<time>.json)")
+	flag.Parse()
+
+	cfg := backtest.CandleBacktestConfig{
+		CycleInterval: *cycle,
+		TakerFeeRate:  *fee,
+		SlippageBps:   *slippage,
+		AutoClosePct:  *autoClose,
+		CSVDir:        *csvDir,
+	}
+
+	if *configFile != "" {
+		if err := applyTraderConfig(&cfg, *configFile, *traderID); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	for _, s := range strings.Split(*symbols, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.Symbols = append(cfg.Symbols, strings.ToUpper(s))
+		}
+	}
+	if len(cfg.Symbols) == 0 || *start == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	if cfg.Start, err = parseTime(*start); err != nil {
+		log.Fatalf("❌ Invalid -start: %v", err)
+	}
+	cfg.End = time.Now().UTC()
+	if *end != "" {
+		if cfg.End, err = parseTime(*end); err != nil {
+			log.Fatalf("❌ Invalid -end: %v", err)
+		}
+	}
+
+	if *strategy != "" {
+		cfg.Strategy = *strategy
+	}
+	if *params != "" {
+		if !json.Valid([]byte(*params)) {
+			log.Fatalf("❌ -params is not valid JSON")
+		}
+		cfg.StrategyParams = json.RawMessage(*params)
+	}
+	if *balance > 0 {
+		cfg.InitialBalance = *balance
+	}
+	if *btcEthLeverage > 0 {
+		cfg.BTCETHLeverage = *btcEthLeverage
+	}
+	if *altcoinLeverage > 0 {
+		cfg.AltcoinLeverage = *altcoinLeverage
+	}
+	if *honorStops {
+		cfg.HonorStops = true
+	}
+
+	if cfg.Strategy == "" || cfg.Strategy == decision.StrategyAI {
+		if cfg.AIClient == nil {
+			log.Fatalf("❌ The AI strategy needs the trader's AI settings: pass -config and -trader (or choose a rule-based -strategy: %v)",
+				decision.StrategyNames())
+		}
+		cycles := int(cfg.End.Sub(cfg.Start) / cfg.CycleInterval)
+		log.Printf("⚠️  The AI strategy calls the model once per cycle (~%d requests)", cycles)
+	}
+
+	result, err := backtest.RunCandleBacktest(cfg)
+	if err != nil {
+		log.Fatalf("❌ Backtest failed: %v", err)
+	}
+
+	outputFile := *output
+	if outputFile == "" {
+		outputFile = fmt.Sprintf("backtest_results/candles_%s_%s.json", result.Strategy, time.Now().Format("20060102_150405"))
+	}
+	if err := backtest.SaveCandleBacktestResult(result, outputFile); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	log.Printf("✅ Backtest complete! Results saved to: %s", outputFile)
+
+	backtest.PrintCandleBacktestSummary(result)
+}
+
+// applyTraderConfig takes a configured trader's strategy, balance, stop mode, background take profit, leverage
+// and AI client
+func applyTraderConfig(cfg *backtest.CandleBacktestConfig, path, traderID string) error {
+	if traderID == "" {
+		return fmt.Errorf("-trader is required with -config")
+	}
+	appConfig, err := config.LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var tc *config.TraderConfig
+	for i := range appConfig.Traders {
+		if appConfig.Traders[i].ID == traderID {
+			tc = &appConfig.Traders[i]
+			break
+		}
+	}
+	if tc == nil {
+		return fmt.Errorf("trader '%s' not found in %s", traderID, path)
+	}
+
+	cfg.Strategy = tc.Strategy
+	cfg.StrategyParams = tc.StrategyParams
+	cfg.InitialBalance = tc.InitialBalance
+	cfg.HonorStops = tc.HonorsStops()
+	cfg.BTCETHLeverage = appConfig.Leverage.BTCETHLeverage
+	cfg.AltcoinLeverage = appConfig.Leverage.AltcoinLeverage
+	if cfg.AutoClosePct == 0 { // -auto-close overrides the trader's background take profit
+		cfg.AutoClosePct = tc.BackgroundTakeProfitPct
+	}
+	if tc.UsesAI() {
+		cfg.AIClient = newAIClient(tc)
+	}
+	log.Printf("📋 Using trader '%s' settings from %s", tc.Name, path)
+	return nil
+}
+
+// newAIClient configures an AI client from the trader's AI settings (same provider selection as the trader)
+func newAIClient(tc *config.TraderConfig) *mcp.Client {
+	client := mcp.New()
+	switch tc.AIModel {
+	case "custom":
+		client.SetCustomAPI(tc.CustomAPIURL, tc.CustomAPIKey, tc.CustomModelName)
+	case "qwen":
+		client.SetQwenAPIKey(tc.QwenKey, "")
+	case "deepseek":
+		client.SetDeepSeekAPIKey(tc.DeepSeekKey)
+	case "anthropic":
+		client.SetAnthropicAPIKey(tc.AnthropicKey, tc.AnthropicModel)
+	case "gemini":
+		client.SetGeminiAPIKey(tc.GeminiKey, tc.GeminiModel)
+	default:
+		client.SetGroqAPIKey(tc.GroqKey, tc.GroqModel)
+	}
+	return client
+}
+
+// parseTime parses a date (UTC midnight) or an RFC3339 timestamp
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"lia/decision/decisiontest"
+	"log"
+	"os"
+	"strings"
+)
+
+// decision-fixtures rewrites the golden files of the decision fixtures after an intended prompt or parser
+// change. The fixtures are checked by go test ./decision/decisiontest/
+//
+//	go run ./cmd/decision-fixtures -update              # rewrite every fixture's golden files
+//	go run ./cmd/decision-fixtures -update -run partial # only fixtures whose name contains "partial"
+func main() {
+	dir := flag.String("dir", "decision/testdata/fixtures", "fixture directory")
+	update := flag.Bool("update", false, "rewrite golden files with current output")
+	run := flag.String("run", "", "only update fixtures whose name contains this string")
+	verbose := flag.Bool("v", false, "show decision engine logs")
+	flag.Parse()
+
+	if !*update {
+		fmt.Println("Fixtures are checked by: go test ./decision/decisiontest/")
+		fmt.Println("Pass -update to rewrite their golden files with the current output")
+		os.Exit(2)
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	fixtures, err := decisiontest.LoadFixtures(*dir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	updated := 0
+	for _, f := range fixtures {
+		if *run != "" && !strings.Contains(f.Name, *run) {
+			continue
+		}
+		if err := f.UpdateGolden(f.Run()); err != nil {
+			fmt.Printf("❌ %s: %v\n", f.Name, err)
+			failed++
+			continue
+		}
+		updated++
+		fmt.Printf("📝 %s: golden files updated\n", f.Name)
+	}
+
+	fmt.Printf("\n%d fixtures updated, %d failed\n", updated, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lia/benchmark"
+	"lia/config"
+	"lia/market"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// import-benchmark replays a CSV trade history (a human trader, buy-and-hold, ...) into a benchmark that
+// /api/competition and /api/equity-history show next to the AI traders. Open positions are marked to market on
+// -mark-interval candles between trades (Binance, or -prices for offline CSVs).
+//
+//	go run ./cmd/import-benchmark -csv btc_hold.csv -id btc_hold -name "BTC buy & hold" -initial-balance 10000
+//	go run ./cmd/import-benchmark -csv alice.csv -id alice -initial-balance 5000 -mark-interval 15m -end 2026-10-01T00:00:00Z
+//
+// The CSV has a header row: time,symbol,action,quantity,price[,fee]. Actions are open_long, open_short,
+// close_long, close_short (quantity 0 = the whole position), buy, sell or mark
+func main() {
+	csvFile := flag.String("csv", "", "trade history CSV")
+	id := flag.String("id", "", "benchmark ID (shown as its trader_id)")
+	name := flag.String("name", "", "display name (default: the ID)")
+	initialBalance := flag.Float64("initial-balance", 0, "starting equity in USDT")
+	configFile := flag.String("config", "config.json", "config.json to take benchmarks_dir from (optional)")
+	dir := flag.String("dir", "", "benchmark directory (default: benchmarks_dir, or benchmarks)")
+	markInterval := flag.String("mark-interval", "1h", "candle interval open positions are marked to market at (empty = trade prices only)")
+	pricesDir := flag.String("prices", "", "directory with <SYMBOL>_<interval>.csv candles instead of Binance")
+	endFlag := flag.String("end", "", "mark open positions until this time (RFC3339, default now)")
+	flag.Parse()
+
+	if *csvFile == "" || *id == "" || *initialBalance <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *dir == "" {
+		*dir = "benchmarks"
+		if cfg, err := config.LoadConfig(*configFile); err == nil {
+			*dir = cfg.BenchmarksDir
+		}
+	}
+
+	f, err := os.Open(*csvFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	trades, err := benchmark.ParseCSV(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("❌ %s: %v", *csvFile, err)
+	}
+
+	if *markInterval != "" {
+		end := time.Now().UTC()
+		if *endFlag != "" {
+			if end, err = time.Parse(time.RFC3339, *endFlag); err != nil {
+				log.Fatalf("❌ Invalid -end: %v", err)
+			}
+		}
+		marks, err := loadMarks(trades, *markInterval, *pricesDir, end)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		trades = append(trades, marks...)
+	}
+
+	b, err := benchmark.Replay(*id, *name, *initialBalance, trades)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	b.Source = filepath.Base(*csvFile)
+	path, err := b.Save(*dir)
+	if err != nil {
+		log.Fatalf("❌ Failed to save benchmark: %v", err)
+	}
+
+	latest := b.Latest()
+	pnl := latest.Equity - b.InitialBalance
+	log.Printf("✅ Benchmark '%s': %d trades, %d curve points, equity %.2f USDT (%+.2f%%) as of %s",
+		b.Name, b.TradeCount, len(b.Curve), latest.Equity, pnl/b.InitialBalance*100, latest.Time.Format(time.RFC3339))
+	log.Printf("💾 Saved to %s (loaded at the next start)", path)
+}
+
+// loadMarks a mark row per candle close of every traded symbol, from the first trade to end
+func loadMarks(trades []benchmark.Trade, interval, pricesDir string, end time.Time) ([]benchmark.Trade, error) {
+	start := trades[0].Time
+	for _, trade := range trades {
+		if trade.Time.Before(start) {
+			start = trade.Time
+		}
+	}
+
+	var marks []benchmark.Trade
+	for _, symbol := range benchmark.Symbols(trades) {
+		var klines []market.Kline
+		var err error
+		if pricesDir != "" {
+			klines, err = market.LoadKlinesCSV(filepath.Join(pricesDir, fmt.Sprintf("%s_%s.csv", symbol, interval)), interval)
+		} else {
+			klines, err = market.GetKlinesRange(symbol, interval, start, end)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s prices: %w", symbol, err)
+		}
+		count := 0
+		for _, k := range klines {
+			closeTime := time.UnixMilli(k.CloseTime + 1).UTC()
+			if closeTime.Before(start) || closeTime.After(end) {
+				continue
+			}
+			marks = append(marks, benchmark.Trade{Time: closeTime, Symbol: symbol, Action: "mark", Price: k.Close})
+			count++
+		}
+		log.Printf("📈 %s: %d %s marks", symbol, count, interval)
+	}
+	return marks, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lia/config"
+	"lia/logger"
+	"log"
+	"os"
+)
+
+// prune runs the decision log retention pass: records older than decision_retention.full_text_days lose their
+// input prompt and raw response and keep a chain-of-thought summary. Runs against each trader's SQLite log, or
+// the Supabase / MySQL database when the config enables one.
+//
+//	go run ./cmd/prune -config config.json
+//	go run ./cmd/prune -config config.json -trader binance_trader -full-text-days 3 -dry-run
+func main() {
+	configFile := flag.String("config", "config.json", "config.json with the traders, decision_retention and database")
+	traderID := flag.String("trader", "", "only this trader (default: every trader in the config)")
+	logDir := flag.String("logs", "", "decision log directory (with -trader; default decision_logs/<trader>)")
+	fullTextDays := flag.Int("full-text-days", 0, "keep full text this many days (default: decision_retention.full_text_days)")
+	summaryChars := flag.Int("summary-chars", 0, "chain-of-thought characters kept (default: decision_retention.summary_chars)")
+	dryRun := flag.Bool("dry-run", false, "only count the records that would be stripped")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+	policy := logger.RetentionPolicy{
+		FullTextDays: cfg.DecisionRetention.FullTextDays,
+		SummaryChars: cfg.DecisionRetention.SummaryChars,
+	}
+	if *fullTextDays > 0 {
+		policy.FullTextDays = *fullTextDays
+	}
+	if *summaryChars > 0 {
+		policy.SummaryChars = *summaryChars
+	}
+	if policy.FullTextDays <= 0 {
+		log.Fatalf("❌ No retention: set decision_retention.full_text_days or -full-text-days")
+	}
+
+	var traderIDs []string
+	for _, tc := range cfg.Traders {
+		if *traderID == "" || tc.ID == *traderID {
+			traderIDs = append(traderIDs, tc.ID)
+		}
+	}
+	if len(traderIDs) == 0 {
+		log.Fatalf("❌ Trader '%s' not found in %s", *traderID, *configFile)
+	}
+
+	var supabaseConfig *logger.SupabaseConfig
+	if driver, url := cfg.DecisionDatabase(); driver != "" {
+		supabaseConfig = &logger.SupabaseConfig{
+			UseSupabase: true,
+			Driver:      driver,
+			DatabaseURL: url,
+			Schema:      cfg.SupabaseSchema,
+		}
+	}
+
+	failed := false
+	for _, id := range traderIDs {
+		dir := fmt.Sprintf("decision_logs/%s", id)
+		if *logDir != "" && *traderID != "" {
+			dir = *logDir
+		}
+		if _, err := os.Stat(dir); err != nil && supabaseConfig == nil {
+			log.Printf("⚠️  %s: no decision log at %s, skipped", id, dir)
+			continue
+		}
+		decisionLogger := logger.NewDecisionLoggerWithConfig(dir, id, supabaseConfig)
+
+		if *dryRun {
+			n, err := decisionLogger.CountRetentionCandidates(policy)
+			if err != nil {
+				log.Printf("❌ %s: %v", id, err)
+				failed = true
+				continue
+			}
+			log.Printf("🔍 %s: %d records older than %d days would be stripped to summaries", id, n, policy.FullTextDays)
+			continue
+		}
+		n, err := decisionLogger.ApplyRetention(policy)
+		if err != nil {
+			log.Printf("❌ %s: retention stopped after %d records: %v", id, n, err)
+			failed = true
+			continue
+		}
+		log.Printf("🧹 %s: stripped %d records older than %d days to summaries", id, n, policy.FullTextDays)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"lia/backtest"
+	"lia/config"
+	"lia/logger"
+	"lia/trader"
+	"log"
+	"os"
+	"time"
+)
+
+// replay re-runs a stored decision record (prompt + AI response) through parsing, validation and simulated
+// execution against historical prices, to see why a decision was rejected or how other validation settings
+// would have treated it.
+//
+//	go run ./cmd/replay -trader binance_trader -cycle 412
+//	go run ./cmd/replay -trader binance_trader -cycle 412 -config config.json -altcoin-leverage 10 -min-confidence 80
+//	go run ./cmd/replay -record decision_logs/paper_trader/decision_20261012_140300_cycle412.json -horizon 6h
+//
+// With -config the global leverage limits (and Supabase, when enabled) are used; explicit flags override them
+func main() {
+	recordFile := flag.String("record", "", "decision record JSON file (instead of -trader/-cycle)")
+	traderID := flag.String("trader", "", "trader ID whose decision log to read")
+	cycle := flag.Int("cycle", 0, "cycle number of the record to replay")
+	logDir := flag.String("logs", "", "decision log directory (default decision_logs/<trader>)")
+	configFile := flag.String("config", "", "config.json to take the leverage limits, the trader's risk policy and database from")
+	equity := flag.Float64("equity", 0, "account equity validation sizes against (default: the record's)")
+	btcEthLeverage := flag.Int("btc-eth-leverage", 0, "maximum BTC/ETH leverage (default: config, or 5)")
+	altcoinLeverage := flag.Int("altcoin-leverage", 0, "maximum altcoin leverage (default: config, or 5)")
+	minConfidence := flag.Int("min-confidence", 0, "minimum confidence for opens (default: not enforced)")
+	horizon := flag.Duration("horizon", 24*time.Hour, "how long simulated opens run before closing at market")
+	csvDir := flag.String("csv", "", "directory with <SYMBOL>_3m.csv (and optional <SYMBOL>_4h.csv) instead of Binance")
+	output := flag.String("out", "", "also write the result as JSON to this file")
+	verbose := flag.Bool("v", false, "show decision engine logs")
+	flag.Parse()
+
+	if *recordFile == "" && (*traderID == "" || *cycle <= 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := backtest.DecisionReplayConfig{
+		Equity:        *equity,
+		MinConfidence: *minConfidence,
+		Horizon:       *horizon,
+		CSVDir:        *csvDir,
+	}
+	var appConfig *config.Config
+	if *configFile != "" {
+		var err error
+		if appConfig, err = config.LoadConfig(*configFile); err != nil {
+			log.Fatalf("❌ Failed to load config: %v", err)
+		}
+		cfg.BTCETHLeverage = appConfig.Leverage.BTCETHLeverage
+		cfg.AltcoinLeverage = appConfig.Leverage.AltcoinLeverage
+		for _, tc := range appConfig.Traders {
+			if tc.ID == *traderID && tc.RiskPolicy != nil {
+				cfg.RiskPolicy = trader.NewRiskPolicy(tc.RiskPolicy)
+			}
+		}
+	}
+	if *btcEthLeverage > 0 {
+		cfg.BTCETHLeverage = *btcEthLeverage
+	}
+	if *altcoinLeverage > 0 {
+		cfg.AltcoinLeverage = *altcoinLeverage
+	}
+
+	var record *logger.DecisionRecord
+	var err error
+	if *recordFile != "" {
+		record, err = loadRecordFile(*recordFile)
+	} else {
+		record, err = loadRecord(*traderID, *cycle, *logDir, appConfig)
+	}
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	result, err := backtest.ReplayDecision(record, cfg)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatalf("❌ Replay failed: %v", err)
+	}
+
+	backtest.PrintDecisionReplay(result)
+	if *output != "" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatalf("❌ Failed to marshal result: %v", err)
+		}
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			log.Fatalf("❌ Failed to write result: %v", err)
+		}
+		log.Printf("✅ Result saved to: %s", *output)
+	}
+}
+
+// loadRecordFile reads a decision record saved as JSON (JSON decision logs, API exports)
+func loadRecordFile(path string) (*logger.DecisionRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record: %w", err)
+	}
+	var record logger.DecisionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse record: %w", err)
+	}
+	return &record, nil
+}
+
+// loadRecord finds a cycle's record in the trader's decision log (SQLite, or Supabase / MySQL when the config enables one)
+func loadRecord(traderID string, cycle int, logDir string, appConfig *config.Config) (*logger.DecisionRecord, error) {
+	if logDir == "" {
+		logDir = fmt.Sprintf("decision_logs/%s", traderID)
+	}
+	var dbDriver, dbURL string
+	if appConfig != nil {
+		dbDriver, dbURL = appConfig.DecisionDatabase()
+	}
+	if _, err := os.Stat(logDir); err != nil && dbDriver == "" {
+		return nil, fmt.Errorf("no decision log for trader '%s': %w", traderID, err)
+	}
+	var supabaseConfig *logger.SupabaseConfig
+	if dbDriver != "" {
+		supabaseConfig = &logger.SupabaseConfig{
+			UseSupabase: true,
+			Driver:      dbDriver,
+			DatabaseURL: dbURL,
+			Schema:      appConfig.SupabaseSchema,
+		}
+	}
+	decisionLogger := logger.NewDecisionLoggerWithConfig(logDir, traderID, supabaseConfig)
+
+	records, err := decisionLogger.GetAllRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load decision records: %w", err)
+	}
+	for _, r := range records {
+		if r.CycleNumber == cycle {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("cycle #%d not found in the decision log of trader '%s' (%d records)", cycle, traderID, len(records))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"lia/backtest"
+	"lia/logger"
+	"lia/report"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// report writes a self-contained HTML performance report (equity curve, drawdown, per-symbol breakdown and
+// trade table) from a candle backtest result or from a trader's decision logs.
+//
+//	go run ./cmd/report -backtest backtest_results/candles_ema_trend_20260915_120000.json
+//	go run ./cmd/report -trader qwen_trader_single -dir decision_logs/qwen_trader_single -balance 1000
+func main() {
+	backtestFile := flag.String("backtest", "", "candle backtest result JSON (cmd/candle-backtest)")
+	traderID := flag.String("trader", "", "trader ID (report title of a live history)")
+	decisionLogDir := flag.String("dir", "", "decision logs directory of the trader")
+	balance := flag.Float64("balance", 0, "live: initial balance in USDT (default: the first logged equity)")
+	limit := flag.Int("limit", 0, "live: latest decision cycles on the equity curve (0 = all)")
+	output := flag.String("out", "", "HTML file (default: next to the backtest JSON, or <dir>/report_<time>.html)")
+	flag.Parse()
+
+	var r *report.Report
+	var err error
+	path := *output
+	switch {
+	case *backtestFile != "":
+		if r, err = fromCandleBacktest(*backtestFile); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if path == "" {
+			path = strings.TrimSuffix(*backtestFile, filepath.Ext(*backtestFile)) + ".html"
+		}
+	case *decisionLogDir != "":
+		name := *traderID
+		if name == "" {
+			name = filepath.Base(*decisionLogDir)
+		}
+		if r, err = report.FromDecisionLog(name, logger.NewDecisionLogger(*decisionLogDir), *balance, *limit); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if path == "" {
+			path = filepath.Join(*decisionLogDir, fmt.Sprintf("report_%s.html", time.Now().Format("20060102_150405")))
+		}
+	default:
+		log.Fatal("Usage: go run ./cmd/report -backtest <result.json> | -dir <decision_logs_dir> [-trader <trader_id>]")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("❌ Failed to create %s: %v", path, err)
+	}
+	if err := r.Render(f); err != nil {
+		f.Close()
+		log.Fatalf("❌ Failed to render report: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", path, err)
+	}
+	log.Printf("✅ Report (%d trades, %d equity points) written to: %s", len(r.Trades), len(r.Equity), path)
+}
+
+// fromCandleBacktest the report of a saved candle backtest result
+func fromCandleBacktest(path string) (*report.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var result backtest.CandleBacktestResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	r := &report.Report{
+		Title: fmt.Sprintf("Backtest: %s", result.Strategy),
+		Subtitle: fmt.Sprintf("%s · %s → %s · %d cycles of %.0fm · Sharpe %.2f",
+			strings.Join(result.Symbols, ", "), result.StartTime.UTC().Format("2006-01-02 15:04"),
+			result.EndTime.UTC().Format("2006-01-02 15:04"), result.TotalCycles, result.CycleMinutes, result.SharpeRatio),
+		InitialEquity: result.InitialBalance,
+	}
+	for _, p := range result.EquityCurve {
+		r.Equity = append(r.Equity, report.EquityPoint{Time: p.Time, Equity: p.Equity})
+	}
+	for _, t := range result.Trades {
+		r.Trades = append(r.Trades, report.Trade{
+			Symbol:     t.Symbol,
+			Side:       t.Side,
+			Leverage:   t.Leverage,
+			Quantity:   t.Quantity,
+			EntryPrice: t.EntryPrice,
+			ExitPrice:  t.ExitPrice,
+			OpenTime:   t.EntryTime,
+			CloseTime:  t.ExitTime,
+			Fees:       t.Fees,
+			PnL:        t.PnL,
+			Reason:     t.ExitReason,
+		})
+	}
+	if result.RejectedDecisions > 0 || result.FailedActions > 0 || result.StrategyErrors > 0 {
+		r.Notes = append(r.Notes, fmt.Sprintf("%d rejected decisions, %d failed actions, %d strategy errors",
+			result.RejectedDecisions, result.FailedActions, result.StrategyErrors))
+	}
+	return r, nil
+}
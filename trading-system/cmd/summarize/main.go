@@ -56,6 +56,10 @@ func main() {
 			continue
 		}
 
+		// Results saved before the Monte Carlo existed are simulated here when they carry their trade P&Ls
+		if len(result.Strategies) > 0 && result.Strategies[0].MonteCarlo == nil {
+			result.AddMonteCarlo(backtest.MonteCarloConfig{})
+		}
 		allResults[traderID] = &result
 	}
 
@@ -83,6 +87,8 @@ func main() {
 				s.AutoClosePct, s.TotalPnL, s.TotalTrades, s.WinRate, s.SharpeRatio, s.AvgWin, s.AvgLoss)
 		}
 
+		backtest.PrintMonteCarloBands(result.Strategies)
+
 		fmt.Println()
 		fmt.Printf("   🏆 Best Sharpe: %.2f%% (Sharpe: %.2f, P&L: $%.2f, Win Rate: %.1f%%)\n",
 			result.BestStrategy.AutoClosePct, result.BestStrategy.SharpeRatio,
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// envRefPattern matches ${VAR}, ${VAR:-default} (default when unset or empty) and ${VAR-default} (default when unset)
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?-)([^}]*))?\}`)
+
+// envNamePattern a bare variable name (whole-value $VAR / env:VAR placeholders)
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UnresolvedVar an environment variable referenced by the config that is not set (and has no default)
+type UnresolvedVar struct {
+	Path string // Config field, e.g. traders[1].groq_key
+	Name string // Variable name
+}
+
+// envInterpolator expands environment variable references in config string values
+type envInterpolator struct {
+	lookup     func(string) (string, bool)
+	unresolved []UnresolvedVar
+}
+
+// interpolateEnv expands environment variable references in every string field of the config:
+//   - ${VAR} anywhere in a value, ${VAR:-default}, ${VAR-default} ($${ escapes a literal ${)
+//   - whole-value $VAR and env:VAR placeholders
+//
+// Inside a trader entry, <TRADER_ID>_VAR takes precedence over VAR (trader ID upper-cased, non-alphanumerics
+// replaced by _), so traders can share ${GROQ_KEY} while one of them overrides it with QWEN_TRADER_GROQ_KEY.
+// Unset variables without a default expand to "" and are returned so validation can report them.
+func (c *Config) interpolateEnv() []UnresolvedVar {
+	in := &envInterpolator{lookup: os.LookupEnv}
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+		if field.Name == "Traders" {
+			for j := range c.Traders {
+				scoped := &envInterpolator{lookup: traderEnvLookup(c.Traders[j].ID)}
+				scoped.walk(reflect.ValueOf(&c.Traders[j]).Elem(), fmt.Sprintf("traders[%d]", j))
+				in.unresolved = append(in.unresolved, scoped.unresolved...)
+			}
+			continue
+		}
+		in.walk(v.Field(i), name)
+	}
+	return in.unresolved
+}
+
+// traderEnvLookup looks up <TRADER_ID>_VAR before VAR
+func traderEnvLookup(traderID string) func(string) (string, bool) {
+	prefix := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, traderID)
+	return func(name string) (string, bool) {
+		if prefix != "" {
+			if value, ok := os.LookupEnv(prefix + "_" + name); ok {
+				return value, true
+			}
+		}
+		return os.LookupEnv(name)
+	}
+}
+
+// walk expands references in all settable strings reachable from v
+func (in *envInterpolator) walk(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(in.expand(v.String(), path))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			in.walk(v.Elem(), path)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if name := jsonFieldName(t.Field(i)); name != "" {
+				in.walk(v.Field(i), path+"."+name)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			in.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			expanded := in.expand(v.MapIndex(key).String(), fmt.Sprintf("%s.%v", path, key))
+			v.SetMapIndex(key, reflect.ValueOf(expanded).Convert(v.Type().Elem()))
+		}
+	}
+}
+
+// expand resolves the references in one value
+func (in *envInterpolator) expand(value, path string) string {
+	if !strings.Contains(value, "$") && !strings.HasPrefix(value, "env:") {
+		return value
+	}
+
+	// Whole-value placeholders: $VAR, env:VAR
+	trimmed := strings.TrimSpace(value)
+	var whole string
+	switch {
+	case strings.HasPrefix(trimmed, "env:"):
+		whole = strings.TrimSpace(trimmed[4:])
+	case strings.HasPrefix(trimmed, "$") && !strings.HasPrefix(trimmed, "${") && !strings.HasPrefix(trimmed, "$$"):
+		whole = trimmed[1:]
+	}
+	if whole != "" && envNamePattern.MatchString(whole) {
+		if envVal, ok := in.lookup(whole); ok {
+			return envVal
+		}
+		in.unresolved = append(in.unresolved, UnresolvedVar{Path: path, Name: whole})
+		return ""
+	}
+
+	// ${...} references anywhere in the value ($${ is a literal ${)
+	const escaped = "\x00"
+	value = strings.ReplaceAll(value, "$${", escaped)
+	value = envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		m := envRefPattern.FindStringSubmatch(ref)
+		name, op, def := m[1], m[2], m[3]
+		envVal, ok := in.lookup(name)
+		switch {
+		case op == ":-" && (!ok || envVal == ""):
+			return def
+		case op == "-" && !ok:
+			return def
+		case !ok:
+			in.unresolved = append(in.unresolved, UnresolvedVar{Path: path, Name: name})
+			return ""
+		}
+		return envVal
+	})
+	return strings.ReplaceAll(value, escaped, "${")
+}
+
+// jsonFieldName the JSON key of a struct field ("" if the field is not serialized)
+func jsonFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return "" // unexported
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationReport result of checking a config file without starting the system
+type ValidationReport struct {
+	File           string
+	Traders        int             // Configured traders (0 if the file didn't parse)
+	EnabledTraders int             // Traders with enabled: true
+	UnresolvedVars []UnresolvedVar // Referenced environment variables that are not set and have no default
+	UnknownFields  []string        // Keys not part of the config schema (ignored when loading, usually typos)
+	Errors         []string        // Read, parse, type and validation errors
+}
+
+// OK whether the config can be started as-is (unknown fields are only warnings)
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors) == 0 && len(r.UnresolvedVars) == 0
+}
+
+// ValidateFile checks a config file: JSON syntax and field types, unknown keys, unresolved
+// environment variables and the same validation LoadConfig applies
+func ValidateFile(filename string) *ValidationReport {
+	report := &ValidationReport{File: filename}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to read config file: %v", err))
+		return report
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("invalid JSON: %v", describeJSONError(data, err)))
+		return report
+	}
+	report.UnknownFields = unknownFields(raw, reflect.TypeOf(Config{}), "")
+
+	config, unresolved, err := parseConfig(data)
+	if err != nil {
+		report.Errors = append(report.Errors, describeJSONError(data, err))
+		return report
+	}
+	report.UnresolvedVars = unresolved
+	report.Traders = len(config.Traders)
+	for _, trader := range config.Traders {
+		if trader.Enabled {
+			report.EnabledTraders++
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("configuration validation failed: %v", err))
+	}
+	return report
+}
+
+// describeJSONError adds the line number to JSON syntax/type errors
+func describeJSONError(data []byte, err error) string {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err.Error()
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line := 1 + strings.Count(string(data[:offset]), "\n")
+	return fmt.Sprintf("line %d: %v", line, err)
+}
+
+// unknownFields lists JSON keys that don't map to a field of t (recursing into nested objects and arrays)
+func unknownFields(raw interface{}, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var unknown []string
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct {
+			return nil // maps and interface{} fields accept any key
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			if name := jsonFieldName(t.Field(i)); name != "" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			fieldType, ok := fields[key]
+			if !ok {
+				unknown = append(unknown, fieldPath)
+				continue
+			}
+			unknown = append(unknown, unknownFields(value[key], fieldType, fieldPath)...)
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		for i, item := range value {
+			unknown = append(unknown, unknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return unknown
+}
+
+// formatUnresolved renders unresolved variables for logs ("NAME (path), ...")
+func formatUnresolved(unresolved []UnresolvedVar) string {
+	parts := make([]string, 0, len(unresolved))
+	for _, u := range unresolved {
+		parts = append(parts, fmt.Sprintf("%s (%s)", u.Name, u.Path))
+	}
+	return strings.Join(parts, ", ")
+}
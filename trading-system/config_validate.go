@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"lia/config"
+)
+
+// runConfigValidate implements `config-validate [config.json]`: checks the config without starting
+// the system and returns the process exit code (0 = valid, 1 = errors or unresolved variables)
+func runConfigValidate(args []string) int {
+	configFile := "config.json"
+	if len(args) > 0 {
+		configFile = args[0]
+	}
+
+	fmt.Printf("📋 Validating configuration file: %s\n\n", configFile)
+	report := config.ValidateFile(configFile)
+
+	if report.Traders > 0 {
+		fmt.Printf("✓ Parsed %d traders (%d enabled)\n", report.Traders, report.EnabledTraders)
+	}
+	for _, field := range report.UnknownFields {
+		fmt.Printf("⚠️  Unknown field (ignored): %s\n", field)
+	}
+	for _, u := range report.UnresolvedVars {
+		fmt.Printf("❌ Unresolved environment variable %s at %s (set it, or give a default with ${%s:-value})\n", u.Name, u.Path, u.Name)
+	}
+	for _, err := range report.Errors {
+		fmt.Printf("❌ %s\n", err)
+	}
+
+	fmt.Println()
+	if !report.OK() {
+		fmt.Printf("❌ %s is not valid: %d errors, %d unresolved variables\n", configFile, len(report.Errors), len(report.UnresolvedVars))
+		return 1
+	}
+	fmt.Printf("✅ %s is valid\n", configFile)
+	return 0
+}
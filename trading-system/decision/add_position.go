@@ -0,0 +1,155 @@
+package decision
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// IsAddAction reports whether action adds to an existing position (add_long / add_short)
+func IsAddAction(action string) bool {
+	return action == "add_long" || action == "add_short"
+}
+
+// IsEntryAction reports whether action raises a position's size: an open or an add
+func IsEntryAction(action string) bool {
+	switch action {
+	case "open_long", "open_short", "add_long", "add_short":
+		return true
+	}
+	return false
+}
+
+// EntrySide the position side an open or add action trades ("long" or "short", "" = neither)
+func EntrySide(action string) string {
+	switch action {
+	case "open_long", "add_long":
+		return "long"
+	case "open_short", "add_short":
+		return "short"
+	}
+	return ""
+}
+
+// findPosition the context's open position on symbol and side (nil = none)
+func findPosition(ctx *Context, symbol, side string) *PositionInfo {
+	for i := range ctx.Positions {
+		if ctx.Positions[i].Symbol == symbol && ctx.Positions[i].Side == side {
+			return &ctx.Positions[i]
+		}
+	}
+	return nil
+}
+
+// validateAddDecision validates an add_long / add_short against the position it adds to. The add uses the
+// position's leverage; stop_loss / take_profit (default: the position's current levels) become the levels of the
+// whole averaged position. The policy's margin limit applies to the position's total margin, and its risk cap to
+// the whole position stopping out (the added margin is reduced to respect it)
+func validateAddDecision(d *Decision, ctx *Context) error {
+	policy := ctx.riskPolicy()
+	accountEquity := ctx.Account.TotalEquity
+	side := EntrySide(d.Action)
+	pos := findPosition(ctx, d.Symbol, side)
+	if pos == nil {
+		return fmt.Errorf("%s: no %s position on %s to add to (use open_%s)", d.Action, side, d.Symbol, side)
+	}
+	d.Side = side
+
+	if d.OrderType = strings.ToLower(strings.TrimSpace(d.OrderType)); d.OrderType != "" && d.OrderType != OrderTypeMarket {
+		return fmt.Errorf("%s supports market entries only, got order_type %q", d.Action, d.OrderType)
+	}
+	d.OrderType, d.LimitPrice = "", 0
+
+	// One leverage per symbol: adding at another one would re-leverage the existing position
+	maxLeverage := ctx.AltcoinLeverage
+	if isBTCOrETH(d.Symbol) {
+		maxLeverage = ctx.BTCETHLeverage
+	}
+	if pos.Leverage > 0 {
+		d.Leverage = pos.Leverage
+	}
+	if d.Leverage <= 0 || d.Leverage > maxLeverage {
+		return fmt.Errorf("leverage must be between 1-%d (%s, current config limit %dx): %d", maxLeverage, d.Symbol, maxLeverage, d.Leverage)
+	}
+
+	if d.PositionSizeUSD <= 0 {
+		return fmt.Errorf("%s requires position_size_usd (margin to add) greater than 0", d.Action)
+	}
+	maxMargin := policy.MaxOpenMargin(d.Symbol, accountEquity)
+	if total := pos.MarginUsed + d.PositionSizeUSD; total > maxMargin {
+		return fmt.Errorf("%s: total %s %s margin would be %.0f USDT (%.0f held + %.0f added), above the %.0f USDT limit",
+			d.Action, d.Symbol, side, total, pos.MarginUsed, d.PositionSizeUSD, maxMargin)
+	}
+
+	if d.StopLoss <= 0 {
+		d.StopLoss = pos.StopLoss
+	}
+	if d.TakeProfit <= 0 {
+		d.TakeProfit = pos.TakeProfit
+	}
+	if d.StopLoss <= 0 || d.TakeProfit <= 0 {
+		return fmt.Errorf("%s requires stop_loss and take_profit for the whole position (the position has none set)", d.Action)
+	}
+
+	currentPrice, err := priceSource(d.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to fetch market data for %s: %w", d.Symbol, err)
+	}
+	if currentPrice <= 0 {
+		return fmt.Errorf("invalid market price for %s", d.Symbol)
+	}
+
+	// Risk and reward of the added quantity from the current price
+	riskPerUnit, rewardPerUnit := currentPrice-d.StopLoss, d.TakeProfit-currentPrice
+	if side == "short" {
+		riskPerUnit, rewardPerUnit = -riskPerUnit, -rewardPerUnit
+	}
+	if riskPerUnit <= 0 {
+		return fmt.Errorf("stop loss %.4f must be on the correct side of current price %.4f", d.StopLoss, currentPrice)
+	}
+	if rewardPerUnit <= 0 {
+		return fmt.Errorf("take profit %.4f must be on the profit side of current price %.4f", d.TakeProfit, currentPrice)
+	}
+	if stopPct := riskPerUnit / currentPrice * 100; stopPct > policy.MaxStopPct(d.Symbol) {
+		return fmt.Errorf("stop loss distance too wide for risk planning: %.2f%% (max allowed: %.1f%% for %s)",
+			stopPct, policy.MaxStopPct(d.Symbol), d.Symbol)
+	}
+	if ratio := rewardPerUnit / riskPerUnit; ratio < policy.MinRiskReward {
+		return fmt.Errorf("risk-reward ratio too low (%.2f:1) from the current price, must be ≥%.1f:1 [stop loss:%.4f take profit:%.4f price:%.4f]",
+			ratio, policy.MinRiskReward, d.StopLoss, d.TakeProfit, currentPrice)
+	}
+
+	// Whole position stopping out: what the held quantity loses from its entry, plus the added quantity
+	maxRiskUSD := policy.MaxRiskUSD(accountEquity)
+	heldLoss := pos.Quantity * (pos.EntryPrice - d.StopLoss)
+	if side == "short" {
+		heldLoss = pos.Quantity * (d.StopLoss - pos.EntryPrice)
+	}
+	roomUSD := maxRiskUSD - math.Max(heldLoss, 0)
+	if roomUSD <= 0 {
+		return fmt.Errorf("risk cap %.2f USDT: the %s position already risks %.2f USDT at stop %.4f – tighten the stop before adding",
+			maxRiskUSD, side, heldLoss, d.StopLoss)
+	}
+	allowedMargin, _ := decimal.NewFromFloat(roomUSD).
+		Mul(decimal.NewFromFloat(currentPrice)).
+		Div(decimal.NewFromFloat(riskPerUnit)).
+		Div(decimal.NewFromInt(int64(d.Leverage))).
+		RoundFloor(8).Float64()
+	if d.PositionSizeUSD > allowedMargin {
+		log.Printf("⚠️  %s %s margin reduced from %.2f to %.2f USDT to respect %.2f USDT risk cap on the whole position",
+			d.Symbol, d.Action, d.PositionSizeUSD, allowedMargin, maxRiskUSD)
+		d.PositionSizeUSD = allowedMargin
+	}
+	return nil
+}
+
+// AveragedEntry the entry price of a position of quantity at entryPrice after adding addQuantity at price
+func AveragedEntry(quantity, entryPrice, addQuantity, price float64) float64 {
+	if quantity+addQuantity <= 0 {
+		return price
+	}
+	return (quantity*entryPrice + addQuantity*price) / (quantity + addQuantity)
+}
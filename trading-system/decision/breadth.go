@@ -0,0 +1,178 @@
+package decision
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dominance trend (BTC vs altcoin relative strength)
+const (
+	DominanceRising  = "rising"  // BTC outperforming alts (money rotating into BTC)
+	DominanceFalling = "falling" // Alts outperforming BTC (alt season conditions)
+	DominanceFlat    = "flat"
+)
+
+const (
+	dominanceThresholdPP = 1.0              // 4h BTC-vs-alts spread (percentage points) that counts as a trend
+	oiSampleMinAge       = 45 * time.Minute // OI samples used for the 1h change must be 45-90 minutes old
+	oiSampleMaxAge       = 90 * time.Minute
+	oiHistoryRetention   = 2 * time.Hour
+)
+
+// MarketBreadth market-wide metrics computed over the symbols with market data this cycle
+type MarketBreadth struct {
+	Symbols           int     `json:"symbols"`             // Symbols the metrics are computed over (candidates + positions)
+	AboveEMA20Pct     float64 `json:"above_ema20_pct"`     // % of symbols trading above their EMA20
+	AdvancersPct      float64 `json:"advancers_pct"`       // % of symbols up over the last hour
+	AvgChange1h       float64 `json:"avg_change_1h"`       // Average 1h price change (%)
+	AvgChange4h       float64 `json:"avg_change_4h"`       // Average 4h price change (%)
+	BTCChange4h       float64 `json:"btc_change_4h"`       // BTC 4h price change (%)
+	AltAvgChange4h    float64 `json:"alt_avg_change_4h"`   // Average 4h change of the non-BTC symbols (%)
+	BTCRelStrength4h  float64 `json:"btc_rel_strength_4h"` // BTC 4h change minus the alt average (percentage points)
+	BTCDominanceTrend string  `json:"btc_dominance_trend"` // rising / falling / flat (relative strength proxy)
+	TotalOIChangePct  float64 `json:"total_oi_change_pct"` // Notional-weighted 1h open interest change (%)
+	OICoverage        int     `json:"oi_coverage"`         // Symbols with a 1h OI change (OI Top data or a sample from ~1h ago)
+}
+
+// oiSample open interest notional of a symbol at a point in time
+type oiSample struct {
+	notional float64
+	at       time.Time
+}
+
+// oiHistory recent open interest samples per symbol (shared by all traders)
+var oiHistory = struct {
+	sync.Mutex
+	samples map[string][]oiSample
+}{samples: make(map[string][]oiSample)}
+
+// recordOI stores a sample and returns the % change against a sample taken 45-90 minutes ago (ok=false if none)
+func recordOI(symbol string, notional float64, now time.Time) (change float64, ok bool) {
+	oiHistory.Lock()
+	defer oiHistory.Unlock()
+
+	samples := oiHistory.samples[symbol]
+	var best *oiSample
+	for i := range samples {
+		age := now.Sub(samples[i].at)
+		if age < oiSampleMinAge || age > oiSampleMaxAge || samples[i].notional <= 0 {
+			continue
+		}
+		// Closest to one hour
+		if best == nil || absDuration(age-time.Hour) < absDuration(now.Sub(best.at)-time.Hour) {
+			best = &samples[i]
+		}
+	}
+	if best != nil {
+		change, ok = (notional-best.notional)/best.notional*100, true
+	}
+
+	kept := samples[:0]
+	for _, s := range samples {
+		if now.Sub(s.at) <= oiHistoryRetention {
+			kept = append(kept, s)
+		}
+	}
+	oiHistory.samples[symbol] = append(kept, oiSample{notional: notional, at: now})
+	return change, ok
+}
+
+// absDuration absolute value of d
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// computeBreadth computes market breadth over the symbols with market data (nil if there are none)
+func computeBreadth(ctx *Context) *MarketBreadth {
+	if len(ctx.MarketDataMap) == 0 {
+		return nil
+	}
+	sampledAt := now()
+	b := &MarketBreadth{BTCDominanceTrend: DominanceFlat}
+
+	var aboveEMA, advancers, alts int
+	var oiWeighted, oiNotional float64
+	hasBTC := false
+	for symbol, data := range ctx.MarketDataMap {
+		if data == nil || data.CurrentPrice <= 0 {
+			continue
+		}
+		b.Symbols++
+		if data.CurrentEMA20 > 0 && data.CurrentPrice > data.CurrentEMA20 {
+			aboveEMA++
+		}
+		if data.PriceChange1h > 0 {
+			advancers++
+		}
+		b.AvgChange1h += data.PriceChange1h
+		b.AvgChange4h += data.PriceChange4h
+		if symbol == "BTCUSDT" {
+			hasBTC = true
+			b.BTCChange4h = data.PriceChange4h
+		} else {
+			alts++
+			b.AltAvgChange4h += data.PriceChange4h
+		}
+
+		// 1h OI change: OI Top delta when listed, otherwise our own sample from ~1h ago
+		if data.OpenInterest == nil || data.OpenInterest.Latest <= 0 {
+			continue
+		}
+		notional := data.OpenInterest.Latest * data.CurrentPrice
+		change, ok := recordOI(symbol, notional, sampledAt)
+		if oiTop, listed := ctx.OITopDataMap[symbol]; listed && oiTop != nil {
+			change, ok = oiTop.OIDeltaPercent, true
+		}
+		if ok {
+			b.OICoverage++
+			oiWeighted += change * notional
+			oiNotional += notional
+		}
+	}
+	if b.Symbols == 0 {
+		return nil
+	}
+
+	n := float64(b.Symbols)
+	b.AboveEMA20Pct = float64(aboveEMA) / n * 100
+	b.AdvancersPct = float64(advancers) / n * 100
+	b.AvgChange1h /= n
+	b.AvgChange4h /= n
+	if alts > 0 {
+		b.AltAvgChange4h /= float64(alts)
+	}
+	if hasBTC && alts > 0 {
+		b.BTCRelStrength4h = b.BTCChange4h - b.AltAvgChange4h
+		switch {
+		case b.BTCRelStrength4h >= dominanceThresholdPP:
+			b.BTCDominanceTrend = DominanceRising
+		case b.BTCRelStrength4h <= -dominanceThresholdPP:
+			b.BTCDominanceTrend = DominanceFalling
+		}
+	}
+	if oiNotional > 0 {
+		b.TotalOIChangePct = oiWeighted / oiNotional
+	}
+	return b
+}
+
+// writeMarketBreadth renders the breadth block of the user prompt
+func writeMarketBreadth(sb *strings.Builder, b *MarketBreadth) {
+	if b == nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("**Market Breadth** (%d coins): %.0f%% above EMA20 | %.0f%% up 1h | avg 1h %+.2f%%, 4h %+.2f%%",
+		b.Symbols, b.AboveEMA20Pct, b.AdvancersPct, b.AvgChange1h, b.AvgChange4h))
+	if b.BTCRelStrength4h != 0 || b.BTCDominanceTrend != DominanceFlat {
+		sb.WriteString(fmt.Sprintf(" | BTC dominance %s (BTC 4h %+.2f%% vs alts %+.2f%%)", b.BTCDominanceTrend, b.BTCChange4h, b.AltAvgChange4h))
+	}
+	if b.OICoverage > 0 {
+		sb.WriteString(fmt.Sprintf(" | OI 1h %+.2f%% (%d coins)", b.TotalOIChangePct, b.OICoverage))
+	}
+	sb.WriteString("\n\n")
+}
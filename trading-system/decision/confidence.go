@@ -0,0 +1,82 @@
+package decision
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// DefaultMinConfidence fixed confidence required to open a position when no adaptive threshold is set
+const DefaultMinConfidence = 85
+
+// ConfidenceBucket realized results of closed trades opened within a confidence band
+type ConfidenceBucket struct {
+	Min       int     `json:"min"`
+	Max       int     `json:"max"`
+	Trades    int     `json:"trades"`
+	WinRate   float64 `json:"win_rate"`    // % of trades profitable after costs
+	AvgNetPnL float64 `json:"avg_net_pnl"` // USDT per trade after estimated fees
+}
+
+// ConfidenceThreshold minimum confidence for opening positions, derived from the trader's calibration
+// history: the lowest confidence at which its trades have been profitable after costs
+type ConfidenceThreshold struct {
+	Threshold      int                `json:"threshold"`
+	Adaptive       bool               `json:"adaptive"`          // false = too few calibrated trades, default used
+	Trades         int                `json:"trades"`            // Calibrated trades (closed, with the opening confidence known)
+	AboveTrades    int                `json:"above_trades"`      // Of which opened at or above the threshold
+	AboveAvgNetPnL float64            `json:"above_avg_net_pnl"` // Their average P&L after estimated fees
+	Buckets        []ConfidenceBucket `json:"buckets,omitempty"`
+	Reason         string             `json:"reason"`
+}
+
+// minConfidence the confidence required to open positions in this context
+func minConfidence(ctx *Context) int {
+	if ctx != nil && ctx.ConfidenceThreshold != nil && ctx.ConfidenceThreshold.Threshold > 0 {
+		return ctx.ConfidenceThreshold.Threshold
+	}
+	return DefaultMinConfidence
+}
+
+// applyConfidenceThreshold moves open and add decisions below the threshold to the rejected list
+// (a decision left with nothing to execute waits)
+func applyConfidenceThreshold(d *FullDecision, threshold *ConfidenceThreshold) {
+	if d == nil || threshold == nil || threshold.Threshold <= 0 {
+		return
+	}
+	var kept []Decision
+	for _, dec := range d.Decisions {
+		if IsEntryAction(dec.Action) && dec.Confidence < threshold.Threshold {
+			reason := fmt.Sprintf("confidence %d below the trader's threshold %d (%s)", dec.Confidence, threshold.Threshold, threshold.Reason)
+			log.Printf("  🚫 %s %s rejected: %s", dec.Symbol, dec.Action, reason)
+			d.Rejected = append(d.Rejected, RejectedDecision{Decision: dec, Reason: reason, Category: RejectConfidence})
+			continue
+		}
+		kept = append(kept, dec)
+	}
+	if len(kept) == 0 && len(d.Decisions) > 0 {
+		kept = []Decision{{
+			Symbol:    "ALL",
+			Action:    "wait",
+			Reasoning: fmt.Sprintf("All open decisions below the confidence threshold %d - waiting for next cycle", threshold.Threshold),
+		}}
+	}
+	d.Decisions = kept
+}
+
+// writeConfidenceCalibration renders the confidence threshold and calibration table of the user prompt
+func writeConfidenceCalibration(sb *strings.Builder, threshold *ConfidenceThreshold) {
+	if threshold == nil {
+		return
+	}
+	if !threshold.Adaptive {
+		sb.WriteString(fmt.Sprintf("**Confidence Threshold**: %d (%s)\n\n", threshold.Threshold, threshold.Reason))
+		return
+	}
+	sb.WriteString(fmt.Sprintf("**Confidence Threshold**: %d - adaptive from your %d calibrated trades: %s. Opens below %d are REJECTED.\n",
+		threshold.Threshold, threshold.Trades, threshold.Reason, threshold.Threshold))
+	for _, b := range threshold.Buckets {
+		sb.WriteString(fmt.Sprintf("  • Confidence %d-%d: %d trades, %.0f%% win, %+.2f USDT avg after fees\n", b.Min, b.Max, b.Trades, b.WinRate, b.AvgNetPnL))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,41 @@
+package decisiontest
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+// fixtureDir the recorded scenarios (go run ./cmd/decision-fixtures -update rewrites their golden files)
+const fixtureDir = "../testdata/fixtures"
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard) // The decision engine logs every parse and validation step
+	os.Exit(m.Run())
+}
+
+func TestGoldenFixtures(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixtures in %s", fixtureDir)
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			mismatches, err := f.Compare(f.Run())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range mismatches {
+				t.Errorf("%s: %s", m.File, m.Diff)
+			}
+			if len(mismatches) > 0 {
+				t.Logf("%s; if the change is intended, run: go run ./cmd/decision-fixtures -update -run %s", f.Description, f.Name)
+			}
+		})
+	}
+}
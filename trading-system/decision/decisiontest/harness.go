@@ -0,0 +1,249 @@
+// Package decisiontest replays recorded AI responses against golden files so prompt
+// and parser changes in the decision package can be checked without calling an AI.
+//
+// Each fixture is a directory containing:
+//
+//	fixture.json               - context, market data and leverage config
+//	response.txt               - recorded AI response (may be malformed on purpose)
+//	system_prompt.golden       - expected buildSystemPrompt output
+//	user_prompt.golden         - expected buildUserPrompt output
+//	decisions.golden.json      - expected parse → validate → size-adjust output
+package decisiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/decision"
+	"lia/market"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	fixtureFile         = "fixture.json"
+	responseFile        = "response.txt"
+	systemPromptGolden  = "system_prompt.golden"
+	userPromptGolden    = "user_prompt.golden"
+	decisionsGoldenFile = "decisions.golden.json"
+)
+
+// Fixture a recorded decision scenario
+type Fixture struct {
+	Name            string                  `json:"-"` // Directory name
+	Dir             string                  `json:"-"`
+	Description     string                  `json:"description"`
+	Context         decision.Context        `json:"context"`
+	MarketData      map[string]*market.Data `json:"market_data"`
+	Performance     map[string]interface{}  `json:"performance,omitempty"`
+	BTCETHLeverage  int                     `json:"btc_eth_leverage"`
+	AltcoinLeverage int                     `json:"altcoin_leverage"`
+	Response        string                  `json:"-"` // Loaded from response.txt
+
+	// Extra prompt timeframes by symbol and the prompt budget (see config.PromptDataConfig)
+	TimeframeData   map[string][]*market.TimeframeData `json:"timeframe_data,omitempty"`
+	MaxPromptTokens int                                `json:"max_prompt_tokens,omitempty"`
+}
+
+// Result output of replaying a fixture
+type Result struct {
+	SystemPrompt string
+	UserPrompt   string
+	Outcome      Outcome
+}
+
+// Outcome parse → validate → size-adjust output compared against decisions.golden.json
+type Outcome struct {
+	Decisions []decision.Decision         `json:"decisions"`
+	Rejected  []decision.RejectedDecision `json:"rejected,omitempty"`
+	CoTTrace  string                      `json:"cot_trace"`
+	Error     string                      `json:"error,omitempty"`
+}
+
+// Mismatch a difference between replay output and a golden file
+type Mismatch struct {
+	File string
+	Diff string
+}
+
+// LoadFixtures loads every fixture directory under dir (sorted by name)
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture directory: %w", err)
+	}
+
+	var fixtures []*Fixture
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		f, err := LoadFixture(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, f)
+	}
+
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Name < fixtures[j].Name })
+	return fixtures, nil
+}
+
+// LoadFixture loads a single fixture directory
+func LoadFixture(dir string) (*Fixture, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fixtureFile))
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: failed to read %s: %w", dir, fixtureFile, err)
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s: failed to parse %s: %w", dir, fixtureFile, err)
+	}
+
+	response, err := os.ReadFile(filepath.Join(dir, responseFile))
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: failed to read %s: %w", dir, responseFile, err)
+	}
+
+	f.Name = filepath.Base(dir)
+	f.Dir = dir
+	f.Response = string(response)
+	return &f, nil
+}
+
+// buildContext assembles the decision context the engine would see after fetching market data
+func (f *Fixture) buildContext() *decision.Context {
+	ctx := f.Context
+	ctx.BTCETHLeverage = f.BTCETHLeverage
+	ctx.AltcoinLeverage = f.AltcoinLeverage
+	ctx.MarketDataMap = f.MarketData
+	if ctx.MarketDataMap == nil {
+		ctx.MarketDataMap = make(map[string]*market.Data)
+	}
+	ctx.OITopDataMap = make(map[string]*decision.OITopData)
+	ctx.TimeframeDataMap = f.TimeframeData
+	ctx.MaxPromptTokens = f.MaxPromptTokens
+	if f.Performance != nil {
+		ctx.Performance = f.Performance
+	}
+	return &ctx
+}
+
+// priceFromMarketData resolves validation prices from the fixture's recorded market data
+func (f *Fixture) priceFromMarketData(symbol string) (float64, error) {
+	if data, ok := f.MarketData[symbol]; ok && data != nil {
+		return data.CurrentPrice, nil
+	}
+	return 0, fmt.Errorf("no recorded market data for %s", symbol)
+}
+
+// Run replays the fixture: builds both prompts and pushes the recorded response through validation
+func (f *Fixture) Run() *Result {
+	ctx := f.buildContext()
+
+	decision.SetPriceSource(f.priceFromMarketData)
+	defer decision.SetPriceSource(nil)
+
+	systemPrompt, userPrompt := decision.BuildPrompts(ctx)
+	full, err := decision.ReplayResponse(f.Response, ctx)
+
+	outcome := Outcome{}
+	if full != nil {
+		outcome.Decisions = full.Decisions
+		outcome.Rejected = full.Rejected
+		outcome.CoTTrace = full.CoTTrace
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+
+	return &Result{
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		Outcome:      outcome,
+	}
+}
+
+// goldenFiles renders the result in golden file form (file name -> content)
+func (r *Result) goldenFiles() (map[string]string, error) {
+	var outcomeJSON strings.Builder
+	enc := json.NewEncoder(&outcomeJSON)
+	enc.SetEscapeHTML(false) // Keep prompts and reasoning readable in golden files
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.Outcome); err != nil {
+		return nil, fmt.Errorf("failed to serialize outcome: %w", err)
+	}
+	return map[string]string{
+		systemPromptGolden:  r.SystemPrompt,
+		userPromptGolden:    r.UserPrompt,
+		decisionsGoldenFile: outcomeJSON.String(),
+	}, nil
+}
+
+// Compare checks the result against the fixture's golden files
+func (f *Fixture) Compare(r *Result) ([]Mismatch, error) {
+	files, err := r.goldenFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []Mismatch
+	for _, name := range sortedKeys(files) {
+		want, err := os.ReadFile(filepath.Join(f.Dir, name))
+		if err != nil {
+			mismatches = append(mismatches, Mismatch{File: name, Diff: fmt.Sprintf("golden file missing: %v", err)})
+			continue
+		}
+		if diff := firstDiff(string(want), files[name]); diff != "" {
+			mismatches = append(mismatches, Mismatch{File: name, Diff: diff})
+		}
+	}
+	return mismatches, nil
+}
+
+// UpdateGolden rewrites the fixture's golden files from the result
+func (f *Fixture) UpdateGolden(r *Result) error {
+	files, err := r.goldenFiles()
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(files) {
+		if err := os.WriteFile(filepath.Join(f.Dir, name), []byte(files[name]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// firstDiff describes the first differing line between want and got ("" if equal)
+func firstDiff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return fmt.Sprintf("length differs (want %d bytes, got %d bytes)", len(want), len(got))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
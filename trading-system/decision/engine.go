@@ -1,19 +1,17 @@
 package decision
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"lia/market"
 	"lia/mcp"
-	"lia/pool"
 	"log"
-	"math"
 	"strings"
 	"time"
-)
 
-const (
-	maxRiskPerTradeFraction = 0.02
+	"github.com/shopspring/decimal"
 )
 
 // PositionInfo position information
@@ -28,7 +26,9 @@ type PositionInfo struct {
 	UnrealizedPnLPct float64 `json:"unrealized_pnl_pct"`
 	LiquidationPrice float64 `json:"liquidation_price"`
 	MarginUsed       float64 `json:"margin_used"`
-	UpdateTime       int64   `json:"update_time"` // Position update timestamp (milliseconds)
+	UpdateTime       int64   `json:"update_time"`           // Position update timestamp (milliseconds)
+	StopLoss         float64 `json:"stop_loss,omitempty"`   // Active stop level set via adjust_stop (0 = none)
+	TakeProfit       float64 `json:"take_profit,omitempty"` // Active take profit level (0 = none)
 }
 
 // AccountInfo account information
@@ -41,12 +41,14 @@ type AccountInfo struct {
 	MarginUsed       float64 `json:"margin_used"`       // Used margin
 	MarginUsedPct    float64 `json:"margin_used_pct"`   // Margin usage rate
 	PositionCount    int     `json:"position_count"`    // Position count
+	RealizedPnL      float64 `json:"realized_pnl"`      // Banked P&L (closed trades, fees, funding)
+	UnrealizedPnL    float64 `json:"unrealized_pnl"`    // Open position P&L (not yet banked)
 }
 
 // CandidateCoin candidate coin (from coin pool)
 type CandidateCoin struct {
 	Symbol  string   `json:"symbol"`
-	Sources []string `json:"sources"` // Sources: "ai500" and/or "oi_top"
+	Sources []string `json:"sources"` // Sources: "ai500", "oi_top" and/or custom source names ("history" when added by the adaptive pool)
 }
 
 // OITopData Open Interest Top data (for AI decision reference)
@@ -59,6 +61,17 @@ type OITopData struct {
 	NetShort          float64 // Net short positions
 }
 
+// SymbolThrottleInfo cross-trader entry throttle state for a symbol (shared account)
+type SymbolThrottleInfo struct {
+	Symbol         string   `json:"symbol"`
+	Entries        int      `json:"entries"`          // Combined entries by all traders in the window
+	MaxEntries     int      `json:"max_entries"`      // Allowed entries per window
+	WindowMinutes  int      `json:"window_minutes"`   // Throttle window length
+	Saturated      bool     `json:"saturated"`        // True if no new entries are allowed right now
+	ResetInSeconds int      `json:"reset_in_seconds"` // Seconds until a slot frees up (when saturated)
+	Traders        []string `json:"traders"`          // Trader IDs that entered in the window
+}
+
 // Context trading context (complete information passed to AI)
 type Context struct {
 	CurrentTime     string                  `json:"current_time"`
@@ -67,26 +80,140 @@ type Context struct {
 	Account         AccountInfo             `json:"account"`
 	Positions       []PositionInfo          `json:"positions"`
 	CandidateCoins  []CandidateCoin         `json:"candidate_coins"`
-	MarketDataMap   map[string]*market.Data `json:"-"` // Not serialized, but used internally
-	OITopDataMap    map[string]*OITopData   `json:"-"` // OI Top data mapping
-	Performance     interface{}             `json:"-"` // Historical performance analysis (logger.PerformanceAnalysis)
-	BTCETHLeverage  int                     `json:"-"` // BTC/ETH leverage multiplier (read from config)
-	AltcoinLeverage int                     `json:"-"` // Altcoin leverage multiplier (read from config)
+	MarketDataMap   map[string]*market.Data `json:"-"`                          // Not serialized, but used internally
+	OITopDataMap    map[string]*OITopData   `json:"-"`                          // OI Top data mapping
+	Performance     interface{}             `json:"-"`                          // Historical performance analysis (logger.PerformanceAnalysis)
+	BTCETHLeverage  int                     `json:"-"`                          // BTC/ETH leverage multiplier (read from config)
+	AltcoinLeverage int                     `json:"-"`                          // Altcoin leverage multiplier (read from config)
+	SymbolThrottles []SymbolThrottleInfo    `json:"-"`                          // Cross-trader symbol entry throttle state
+	CoinPoolNotes   []string                `json:"coin_pool_notes,omitempty"`  // Set when the coin pool came from a stale snapshot/fallback
+	PoolAdjustments []PoolAdjustment        `json:"pool_adjustments,omitempty"` // Candidate pool changes based on this trader's track record
+	Memory          TradeMemory             `json:"-"`                          // Trade memory queried for relevant past trades (nil = disabled)
+	RelevantTrades  []MemoryEpisode         `json:"relevant_trades,omitempty"`  // Past trades retrieved for this cycle's setups
+	MemorySize      int                     `json:"memory_size,omitempty"`      // Trades in the memory index (> 0 replaces the recent trade list)
+	RejectedTrades  *RejectedTradeSummary   `json:"rejected_trades,omitempty"`  // Simulated outcome of recently rejected opens (nil = none)
+
+	// Timeout of the compact-prompt retry after the AI request timed out (0 = no retry)
+	CompactRetryTimeout time.Duration `json:"-"`
+
+	// Market-wide metrics over the candidate pool (set after market data is fetched, nil = unavailable)
+	Breadth *MarketBreadth `json:"breadth,omitempty"`
+
+	// Adaptive minimum confidence for opens, enforced after validation (nil = fixed 85, not enforced)
+	ConfidenceThreshold *ConfidenceThreshold `json:"confidence_threshold,omitempty"`
+
+	// Opens place a stop order at their stop_loss (false = stops are for risk planning only, losers are held)
+	HonorStops bool `json:"honor_stops,omitempty"`
+
+	// Per-symbol net of the positions (set with the positions; hedged symbols are shown netted in the prompt)
+	NetExposure []NetExposure `json:"net_exposure,omitempty"`
+	// Opens against an opposite position on the same symbol are rejected (no simultaneous long and short)
+	ProhibitHedging bool `json:"prohibit_hedging,omitempty"`
+
+	// Limit / post_only opens are offered, unfilled ones are cancelled after this many minutes (0 = market only)
+	LimitEntryTimeoutMinutes int            `json:"limit_entry_timeout_minutes,omitempty"`
+	PendingEntries           []PendingEntry `json:"pending_entries,omitempty"` // Limit entries resting on the exchange
+
+	// Extra candle timeframes shown for each coin (nil = 3m/4h only), loaded with the market data
+	PromptTimeframes []string                           `json:"-"`
+	TimeframeSeries  int                                `json:"-"` // Candles per extra timeframe series
+	TimeframeDataMap map[string][]*market.TimeframeData `json:"-"`
+
+	// Estimated token budget of the user prompt, trimmed section by section to fit (0 = unlimited)
+	MaxPromptTokens int `json:"-"`
+
+	// The AI answers with one {"reasoning", "decisions"} object (JSON mode / function calling) instead of
+	// chain of thought followed by a JSON array
+	StructuredOutput bool `json:"structured_output,omitempty"`
+
+	// Open interest value (USD) below which candidates are skipped (0 = defaultMinOIUSD, < 0 = no filter)
+	MinOIUSD float64 `json:"-"`
+
+	// Templates the system and user prompts are rendered from (nil = built-in)
+	PromptTemplate *PromptTemplate `json:"-"`
+
+	// Limits decisions are validated against (nil = DefaultRiskPolicy)
+	RiskPolicy *RiskPolicy `json:"-"`
+
+	// Multi-agent chair: the committee's proposals, appended to the user prompt after the template ("" = none)
+	CommitteeBriefing string `json:"-"`
+
+	// Recent headlines per position / candidate symbol and market-wide ones (nil = news disabled or none)
+	News       map[string][]NewsHeadline `json:"news,omitempty"`
+	MarketNews []NewsHeadline            `json:"market_news,omitempty"`
+
+	// Recent liquidations per position / candidate symbol, market-wide and the large ones (nil = liquidation
+	// data disabled or none within the window)
+	Liquidations       map[string]*market.LiquidationSummary `json:"liquidations,omitempty"`
+	MarketLiquidations *market.LiquidationSummary            `json:"market_liquidations,omitempty"`
+	LargeLiquidations  []market.LiquidationEvent             `json:"large_liquidations,omitempty"`
+}
+
+// Adaptive pool adjustment types
+const (
+	PoolBoosted  = "boosted"  // Proven edge - moved to the front of the pool
+	PoolAdded    = "added"    // Proven edge - added although the coin pool did not list it this cycle
+	PoolDemoted  = "demoted"  // Negative expectancy - moved to the back of the pool
+	PoolExcluded = "excluded" // Chronic loser - removed from the pool
+)
+
+// PoolAdjustment a candidate pool change driven by the trader's per-symbol performance
+type PoolAdjustment struct {
+	Symbol  string  `json:"symbol"`
+	Type    string  `json:"type"`
+	Trades  int     `json:"trades"`
+	WinRate float64 `json:"win_rate"` // %
+	AvgPnL  float64 `json:"avg_pnl"`  // USDT per trade
 }
 
 // Decision AI trading decision
 type Decision struct {
 	Symbol          string  `json:"symbol"`
-	Action          string  `json:"action"` // "open_long", "open_short", "close_long", "close_short", "hold", "wait"
+	Action          string  `json:"action"`         // "open_long", "open_short", "add_long", "add_short", "close_long", "close_short", "hold", "wait" or an amend action
+	Side            string  `json:"side,omitempty"` // Position side for amend and add actions: "long" or "short"
 	Leverage        int     `json:"leverage,omitempty"`
-	PositionSizeUSD float64 `json:"position_size_usd,omitempty"`
+	PositionSizeUSD float64 `json:"position_size_usd,omitempty"` // Margin to open (open_*), to buy more with (add_*) or to add (add_margin)
 	StopLoss        float64 `json:"stop_loss,omitempty"`
 	TakeProfit      float64 `json:"take_profit,omitempty"`
-	Confidence      int     `json:"confidence,omitempty"` // Confidence level (0-100)
-	RiskUSD         float64 `json:"risk_usd,omitempty"`   // Maximum USD risk
+	OrderType       string  `json:"order_type,omitempty"`  // Entry order of an open: "market" (default), "limit" or "post_only"
+	LimitPrice      float64 `json:"limit_price,omitempty"` // Price a limit / post_only open rests at
+	ReducePct       float64 `json:"reduce_pct,omitempty"`  // Percentage of the position to close (reduce_size)
+	ClosePct        float64 `json:"close_pct,omitempty"`   // Percentage of the position to close (close_long/close_short; 0 or 100 = all)
+	Confidence      int     `json:"confidence,omitempty"`  // Confidence level (0-100)
+	RiskUSD         float64 `json:"risk_usd,omitempty"`    // Maximum USD risk
 	Reasoning       string  `json:"reasoning"`
 }
 
+// validActions every action the AI may output
+var validActions = map[string]bool{
+	"open_long":     true,
+	"open_short":    true,
+	"add_long":      true,
+	"add_short":     true,
+	"close_long":    true,
+	"close_short":   true,
+	"adjust_stop":   true,
+	"adjust_target": true,
+	"add_margin":    true,
+	"reduce_size":   true,
+	"hold":          true,
+	"wait":          true,
+}
+
+// IsAmendAction reports whether action modifies an existing position without fully closing it
+func IsAmendAction(action string) bool {
+	switch action {
+	case "adjust_stop", "adjust_target", "add_margin", "reduce_size":
+		return true
+	}
+	return false
+}
+
+// IsPartialClose reports whether a close_long/close_short closes only part of the position
+func (d *Decision) IsPartialClose() bool {
+	return (d.Action == "close_long" || d.Action == "close_short") && d.ClosePct > 0 && d.ClosePct < 100
+}
+
 // FullDecision AI complete decision (including chain of thought)
 type FullDecision struct {
 	UserPrompt  string     `json:"user_prompt"`  // Input prompt sent to AI
@@ -94,10 +221,25 @@ type FullDecision struct {
 	Decisions   []Decision `json:"decisions"`    // Specific decision list
 	RawResponse string     `json:"raw_response"` // Raw AI response (for debugging)
 	Timestamp   time.Time  `json:"timestamp"`
+
+	// Decisions removed by validation (not executed; recorded for outcome simulation)
+	Rejected []RejectedDecision `json:"rejected,omitempty"`
+
+	// Token usage and estimated cost of the AI calls behind this decision (nil = no AI call completed)
+	Usage *mcp.Usage `json:"usage,omitempty"`
+
+	// Prompt template (name and version) the AI prompts were rendered from ("" = no AI prompt)
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	PromptVersion  string `json:"prompt_version,omitempty"`
 }
 
 // GetFullDecision gets AI's complete trading decision (batch analysis of all coins and positions)
 func GetFullDecision(ctx *Context, mcpClient *mcp.Client) (*FullDecision, error) {
+	return GetFullDecisionWithContext(context.Background(), ctx, mcpClient)
+}
+
+// GetFullDecisionWithContext is GetFullDecision with cancellation: cancelling reqCtx aborts the AI request
+func GetFullDecisionWithContext(reqCtx context.Context, ctx *Context, mcpClient *mcp.Client) (*FullDecision, error) {
 	// 1. Get market data for all coins
 	if err := fetchMarketDataForContext(ctx); err != nil {
 		log.Printf("⚠️  Failed to fetch market data: %v - using fallback 'wait' decision", err)
@@ -111,16 +253,43 @@ func GetFullDecision(ctx *Context, mcpClient *mcp.Client) (*FullDecision, error)
 					Reasoning: fmt.Sprintf("Market data unavailable: %v - waiting for next cycle", err),
 				},
 			},
-			Timestamp: time.Now(),
+			Timestamp: now(),
 		}, nil
 	}
 
+	// 1.1 Market-wide breadth over the fetched symbols
+	ctx.Breadth = computeBreadth(ctx)
+
+	// 1.2 Retrieve relevant past trades (needs market data to describe the current regime)
+	retrieveRelevantTrades(ctx)
+
 	// 2. Build System Prompt (fixed rules) and User Prompt (dynamic data)
-	systemPrompt := buildSystemPrompt(ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage)
-	userPrompt := buildUserPrompt(ctx)
+	var schema *mcp.ResponseSchema
+	ctx.StructuredOutput = mcpClient.OutputMode != mcp.OutputText
+	if ctx.StructuredOutput {
+		schema = decisionResponseSchema()
+	}
+	systemPrompt := buildSystemPrompt(ctx)
+	userPrompt := buildBudgetedUserPrompt(ctx)
 
 	// 3. Call AI API (using system + user prompt)
-	aiResponse, err := mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	var usage mcp.Usage
+	aiResponse, callUsage, err := mcpClient.CallStructured(reqCtx, systemPrompt, userPrompt, schema)
+	usage.Add(callUsage)
+
+	// 3.1 Timed out: retry once with a compact prompt under a shorter timeout
+	if err != nil && errors.Is(err, mcp.ErrRequestTimeout) && ctx.CompactRetryTimeout > 0 && reqCtx.Err() == nil {
+		compactPrompt := buildUserPrompt(compactContext(ctx))
+		log.Printf("⏱  AI request timed out - retrying once with a compact prompt (%d → %d chars, timeout %v)",
+			len(userPrompt), len(compactPrompt), ctx.CompactRetryTimeout)
+		retryCtx, cancel := context.WithTimeout(reqCtx, ctx.CompactRetryTimeout)
+		aiResponse, callUsage, err = mcpClient.CallStructured(retryCtx, systemPrompt, compactPrompt, schema)
+		cancel()
+		usage.Add(callUsage)
+		if err == nil {
+			userPrompt = compactPrompt
+		}
+	}
 	if err != nil {
 		log.Printf("⚠️  Failed to call AI API: %v - using fallback 'wait' decision", err)
 		// Return fallback decision instead of nil to prevent cycle failure
@@ -134,13 +303,16 @@ func GetFullDecision(ctx *Context, mcpClient *mcp.Client) (*FullDecision, error)
 					Reasoning: fmt.Sprintf("AI API unavailable: %v - waiting for next cycle", err),
 				},
 			},
-			Timestamp:  time.Now(),
-			UserPrompt: userPrompt, // Save prompt for debugging
+			Timestamp:      now(),
+			UserPrompt:     userPrompt, // Save prompt for debugging
+			PromptTemplate: ctx.promptTemplate().Name,
+			PromptVersion:  ctx.promptTemplate().Version,
 		}, nil
 	}
 
 	// 4. Parse AI response
-	decision, err := parseFullDecisionResponse(aiResponse, ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage)
+	decision, err := parseFullDecisionResponse(aiResponse, ctx)
+	applyConfidenceThreshold(decision, ctx.ConfidenceThreshold)
 
 	// CRITICAL: parseFullDecisionResponse ALWAYS returns a decision (with fallback mechanism)
 	// If it returns nil decision, that means a critical error occurred - we should handle it
@@ -168,8 +340,11 @@ func GetFullDecision(ctx *Context, mcpClient *mcp.Client) (*FullDecision, error)
 			log.Printf("⚠️  Parsing had issues but fallback decisions exist - continuing cycle successfully")
 			err = nil
 		}
-		decision.Timestamp = time.Now()
-		decision.UserPrompt = userPrompt  // Save input prompt
+		decision.Timestamp = now()
+		decision.Usage = &usage
+		decision.UserPrompt = userPrompt // Save input prompt
+		decision.PromptTemplate = ctx.promptTemplate().Name
+		decision.PromptVersion = ctx.promptTemplate().Version
 		decision.RawResponse = aiResponse // Save raw response for debugging
 		return decision, nil              // Always return nil error when we have decisions
 	}
@@ -178,6 +353,9 @@ func GetFullDecision(ctx *Context, mcpClient *mcp.Client) (*FullDecision, error)
 	return nil, fmt.Errorf("failed to parse AI response: no decisions available (fallback mechanism failed)")
 }
 
+// defaultMinOIUSD open interest value below which candidates are skipped unless the trader configures another
+const defaultMinOIUSD = 15_000_000
+
 // fetchMarketDataForContext fetches market data and OI data for all coins in the context
 func fetchMarketDataForContext(ctx *Context) error {
 	ctx.MarketDataMap = make(map[string]*market.Data)
@@ -200,31 +378,38 @@ func fetchMarketDataForContext(ctx *Context) error {
 		symbolSet[coin.Symbol] = true
 	}
 
-	// Concurrently fetch market data
 	// Position coin set (for determining whether to skip OI check)
 	positionSymbols := make(map[string]bool)
 	for _, pos := range ctx.Positions {
 		positionSymbols[pos.Symbol] = true
 	}
 
+	minOIUSD := ctx.MinOIUSD
+	if minOIUSD == 0 {
+		minOIUSD = defaultMinOIUSD
+	}
+	symbols := make([]string, 0, len(symbolSet))
 	for symbol := range symbolSet {
-		data, err := market.Get(symbol)
-		if err != nil {
-			// Single coin failure doesn't affect overall, just log error
+		symbols = append(symbols, symbol)
+	}
+	// A single coin's failure doesn't affect the others (it is left out)
+	fetched := fetchSymbolData(symbols)
+	for _, symbol := range symbols {
+		data, ok := fetched[symbol]
+		if !ok {
 			continue
 		}
 
-		// ⚠️ Liquidity filter: coins with open interest value below 15M USD are skipped (both long and short)
+		// ⚠️ Liquidity filter: coins with open interest value below the minimum (default 15M USD) are skipped (both long and short)
 		// Open interest value = open interest × current price
 		// But existing positions must be retained (need to decide whether to close)
 		isExistingPosition := positionSymbols[symbol]
-		if !isExistingPosition && data.OpenInterest != nil && data.CurrentPrice > 0 {
+		if !isExistingPosition && minOIUSD > 0 && data.OpenInterest != nil && data.CurrentPrice > 0 {
 			// Calculate open interest value (USD) = open interest × current price
 			oiValue := data.OpenInterest.Latest * data.CurrentPrice
-			oiValueInMillions := oiValue / 1_000_000 // Convert to millions USD
-			if oiValueInMillions < 15 {
-				log.Printf("⚠️  %s open interest value too low (%.2fM USD < 15M), skipping this coin [OI:%.0f × Price:%.4f]",
-					symbol, oiValueInMillions, data.OpenInterest.Latest, data.CurrentPrice)
+			if oiValue < minOIUSD {
+				log.Printf("⚠️  %s open interest value too low (%.2fM USD < %.2fM), skipping this coin [OI:%.0f × Price:%.4f]",
+					symbol, oiValue/1_000_000, minOIUSD/1_000_000, data.OpenInterest.Latest, data.CurrentPrice)
 				continue
 			}
 		}
@@ -232,8 +417,14 @@ func fetchMarketDataForContext(ctx *Context) error {
 		ctx.MarketDataMap[symbol] = data
 	}
 
+	// Extra timeframes of the coins that made it into the context
+	fetchTimeframesForContext(ctx)
+
 	// Load OI Top data (doesn't affect main flow)
-	oiPositions, err := pool.GetOITopPositions()
+	if oiTopSource == nil {
+		return nil
+	}
+	oiPositions, err := oiTopSource()
 	if err == nil {
 		for _, pos := range oiPositions {
 			// Normalize symbol matching
@@ -255,275 +446,45 @@ func fetchMarketDataForContext(ctx *Context) error {
 // calculateMaxCandidates calculates the number of candidate coins to analyze based on account status
 func calculateMaxCandidates(ctx *Context) int {
 	// Directly return the total number of coins in candidate pool
-	// Because candidate pool has already been filtered in auto_trader.go
-	// Fixed to analyze top 20 highest-scored coins (from AI500)
+	// Because candidate pool has already been filtered (and capped) in auto_trader.go
 	return len(ctx.CandidateCoins)
 }
 
-// buildSystemPrompt 构建 System Prompt（固定规则，可缓存）
-func buildSystemPrompt(accountEquity float64, btcEthLeverage, altcoinLeverage int) string {
-	var sb strings.Builder
-
-	// === Core Mission ===
-	sb.WriteString("You are a professional cryptocurrency trading AI, conducting autonomous trading in the Binance futures market.\n\n")
-	sb.WriteString("**IMPORTANT: All your responses, including chain of thought analysis and reasoning fields, must be in English.**\n\n")
-	sb.WriteString("# 🎯 Core Objective\n\n")
-	sb.WriteString("**Maximize Sharpe Ratio**\n\n")
-	sb.WriteString("Sharpe Ratio = Average Return / Return Volatility\n\n")
-	sb.WriteString("**This means**:\n")
-	sb.WriteString("- ✅ High-quality trades (high win rate, large profit/loss ratio) → Increase Sharpe\n")
-	sb.WriteString("- ✅ Stable returns, control drawdowns → Increase Sharpe\n")
-	sb.WriteString("- ✅ Patient holding, let profits run → Increase Sharpe\n")
-	sb.WriteString("- ❌ Frequent trading, small wins/losses → Increase volatility, severely reduce Sharpe\n")
-	sb.WriteString("- ❌ Overtrading, fee drain → Direct losses\n")
-	sb.WriteString("- ❌ Premature exits, frequent in/out → Miss big opportunities\n\n")
-	sb.WriteString("**CRITICAL FOR REAL TRADING**:\n")
-	sb.WriteString("- Binance fees: 0.02%% maker / 0.04%% taker per trade\n")
-	sb.WriteString("- Each round-trip trade costs 0.04-0.08%% in fees\n")
-	sb.WriteString("- Only trade if expected profit > 0.2%% (to cover fees + profit)\n")
-	sb.WriteString("- Hold positions minimum 5-10 minutes (let trends develop)\n")
-	sb.WriteString("- Maximum 2-3 trades per hour (quality over quantity)\n\n")
-	sb.WriteString("**Key insight**: The system scans every 3 minutes, but this doesn't mean you must trade every time!\n")
-	sb.WriteString("Most of the time should be `wait` or `hold`, only open positions at excellent opportunities.\n\n")
-
-	// === Hard Constraints (Risk Control) ===
-	sb.WriteString("# ⚖️ Hard Constraints (Risk Control)\n\n")
-	sb.WriteString(fmt.Sprintf("1. **Max Risk Per Trade**: ≤ %.1f%% of equity (≈ %.2f USDT right now)\n",
-		maxRiskPerTradeFraction*100, accountEquity*maxRiskPerTradeFraction))
-	sb.WriteString("   - ALWAYS size positions + stop losses so the worst-case loss stays under this cap\n")
-	sb.WriteString("   - Closing a losing position is REQUIRED when the stop is hit—protect capital first\n")
-	sb.WriteString("2. **Risk-Reward Ratio**: Must be ≥ 1:3 (risk 1%, earn 3%+ return)\n")
-	sb.WriteString("3. **Maximum Positions**: 6 positions TOTAL (HARD LIMIT - system will reject excess)\n")
-	sb.WriteString("   - ⚠️ CRITICAL: If you already have positions, count them! Don't open more than 6 total!\n")
-	sb.WriteString("   - ✅ ALLOWED: Multiple positions in the same coin are allowed (e.g., 2 ETHUSDT long positions)\n")
-	sb.WriteString("   - ⚠️ CRITICAL: Build gradually - add one position at a time and reassess\n")
-	sb.WriteString("   - ⚠️ CRITICAL: Opening too many positions at once = margin exhaustion = all fail!\n")
-	sb.WriteString("4. **Per-Position Size (MARGIN - Actual USDT Used)**: Use meaningful sizes to overcome fees\n")
-	sb.WriteString(fmt.Sprintf("   - Altcoins: $%.0f-$%.0f MARGIN per position (15-25%% of equity) | BTC/ETH: $%.0f-$%.0f MARGIN per position (20-35%% of equity)\n",
-		accountEquity*0.15, accountEquity*0.25, accountEquity*0.20, accountEquity*0.35))
-	sb.WriteString("   - 💡 IMPORTANT: `position_size_usd` is the MARGIN (actual USDT used), NOT the notional value!\n")
-	sb.WriteString(fmt.Sprintf("   - 💡 With %dx leverage, $%.0f margin = $%.0f notional position (%.0f × %d)\n", altcoinLeverage, accountEquity*0.20, accountEquity*0.20*float64(altcoinLeverage), accountEquity*0.20, altcoinLeverage))
-	sb.WriteString(fmt.Sprintf("   - 💡 With %.0f USDT available, you can open ~%.0f positions of $%.0f margin each\n", accountEquity*0.93, (accountEquity*0.93)/(accountEquity*0.20), accountEquity*0.20))
-	sb.WriteString("   - ⚠️ Positions below minimum are rejected (too small to overcome fees)\n")
-	sb.WriteString("5. **Margin**: Total usage ≤ 90% (keep some available for new opportunities)\n")
-	sb.WriteString("6. **Position Opening Strategy**:\n")
-	sb.WriteString("   - If 0-2 positions: Can open 1-2 new positions (build gradually)\n")
-	sb.WriteString("   - If 3-4 positions: Can open 1-2 more (max 6 total) - use available capital!\n")
-	sb.WriteString("   - If 5 positions: Can open 1 more (max 6 total)\n")
-	sb.WriteString("   - If 6 positions: WAIT - close one before opening another\n")
-	marginPerPos := accountEquity * 0.20
-	maxPos := (accountEquity * 0.93) / marginPerPos
-	sb.WriteString(fmt.Sprintf("   - 💡 Current: With %.0f USDT available, you can open ~%.0f positions of $%.0f margin each - don't be too conservative!\n\n",
-		accountEquity*0.93, maxPos, marginPerPos))
-
-	// === Long/Short Balance ===
-	sb.WriteString("# 📉 Long/Short Balance\n\n")
-	sb.WriteString("**Important**: Shorting in downtrends = Longing in uptrends in terms of profit\n\n")
-	sb.WriteString("- Uptrend → Go long\n")
-	sb.WriteString("- Downtrend → Go short\n")
-	sb.WriteString("- Range-bound market → Wait\n\n")
-	sb.WriteString("**Don't have long bias! Shorting is one of your core tools**\n\n")
-
-	// === Market Regime Detection (CRITICAL) ===
-	sb.WriteString("# 🚨 Market Regime Detection (CRITICAL)\n\n")
-	sb.WriteString("**CRASH DETECTION RULES**:\n")
-	sb.WriteString("1. **Check BTC first** - BTC is the market leader\n")
-	sb.WriteString("   - If BTC 1h < -1.0%% AND 4h < -0.5%% → Market is CRASHING\n")
-	sb.WriteString("   - If BTC 4h EMA20 < EMA50 AND price < EMA20 → Downtrend confirmed\n")
-	sb.WriteString("2. **During crashes**:\n")
-	sb.WriteString("   - 🚫 DO NOT open LONG positions (even if individual coins show 'bounce' signals)\n")
-	sb.WriteString("   - ✅ SHORT opportunities are valid (but require high confidence)\n")
-	sb.WriteString("   - ✅ WAIT is often the safest option during crashes\n")
-	sb.WriteString("3. **Why**: During crashes, oversold bounces (RSI < 30) are TRAPS\n")
-	sb.WriteString("   - Price can stay oversold for hours\n")
-	sb.WriteString("   - MACD 'improving' during crashes is NOT a buy signal\n")
-	sb.WriteString("   - Altcoins fall MORE than BTC during crashes (higher correlation)\n\n")
-	sb.WriteString("**BULL MARKET DETECTION**:\n")
-	sb.WriteString("- BTC 1h > +0.5%% AND 4h > +0.3%% → Bullish\n")
-	sb.WriteString("- BTC price > EMA20 > EMA50 → Uptrend\n")
-	sb.WriteString("- During bull markets, LONG positions are preferred\n\n")
-	sb.WriteString("**NEUTRAL MARKET**:\n")
-	sb.WriteString("- If neither crash nor bull market detected → Be cautious, wait for clear signals\n\n")
-
-	// === Trading Frequency Awareness ===
-	sb.WriteString("# ⏱️ Trading Frequency Awareness\n\n")
-	sb.WriteString("**Quantitative Standards**:\n")
-	sb.WriteString("- Excellent traders: 2-4 trades per day = 0.1-0.2 trades per hour\n")
-	sb.WriteString("- Overtrading: >2 trades per hour = serious problem\n")
-	sb.WriteString("- Optimal rhythm: Hold positions for at least 30-60 minutes after opening\n\n")
-	sb.WriteString("**Self-check**:\n")
-	sb.WriteString("If you find yourself trading every cycle → standards are too low\n")
-	sb.WriteString("If you find yourself closing positions <30 minutes → too impatient\n\n")
-
-	// === Opening Signal Strength ===
-	sb.WriteString("# 🎯 Opening Standards (Strict)\n\n")
-	sb.WriteString("Only open positions on **strong signals**, wait if uncertain.\n\n")
-	sb.WriteString("**Complete data you have**:\n")
-	sb.WriteString("- 📊 **Raw sequences**: 3-minute price sequence (MidPrices array) + 4-hour candlestick sequence\n")
-	sb.WriteString("- 📈 **Technical sequences**: EMA20, MACD, RSI7, RSI14 sequences\n")
-	sb.WriteString("- 💰 **Capital sequences**: Volume sequence, Open Interest (OI) sequence, funding rate\n")
-	sb.WriteString("- 🎯 **Filter tags**: AI500 score / OI_Top ranking (if marked)\n\n")
-	sb.WriteString("**Analysis methods** (completely your decision):\n")
-	sb.WriteString("- Freely use sequence data, you can perform but not limited to: trend analysis, pattern recognition, support/resistance, technical resistance levels, Fibonacci, volatility band calculations\n")
-	sb.WriteString("- Multi-dimensional cross-validation (price + volume + OI + indicators + sequence patterns)\n")
-	sb.WriteString("- Use the methods you consider most effective to discover high-confidence opportunities\n")
-	sb.WriteString("- Only open positions when comprehensive confidence ≥ 85 (STRICT: real trading requires higher confidence)\n")
-	sb.WriteString("- ⚠️ CRITICAL: Each trade costs 0.02-0.04% in fees. With small positions, fees = 20-50% of profit!\n")
-	sb.WriteString(fmt.Sprintf("- ⚠️ CRITICAL: Use MEANINGFUL position sizes to overcome fees (with %.0f USDT equity, you have ~%.0f USDT available)\n", accountEquity, accountEquity*0.97))
-	sb.WriteString(fmt.Sprintf("  • BTC/ETH: Target $%.0f-$%.0f per position (20-35%% of equity) - use leverage to maximize notional value\n", accountEquity*0.20, accountEquity*0.35))
-	sb.WriteString(fmt.Sprintf("  • Altcoins: Target $%.0f-$%.0f per position (15-25%% of equity) - use leverage to maximize notional value\n", accountEquity*0.15, accountEquity*0.25))
-	sb.WriteString(fmt.Sprintf("- ⚠️ CRITICAL: System will REJECT positions < $%.0f (BTC/ETH) or < $%.0f (altcoins) - too small to overcome fees!\n", accountEquity*0.20, accountEquity*0.15))
-	sb.WriteString("- ⚠️ CRITICAL: Only trade if expected profit > 1% to overcome fees + slippage\n")
-	sb.WriteString("- ⚠️ CRITICAL: Hold positions minimum 15-20 minutes. Don't close positions < 15 minutes old unless stop loss hit\n")
-	sb.WriteString("- 💡 Strategy: Fewer, larger trades = less fees, more profit. Quality over quantity!\n")
-	sb.WriteString("- 💡 REAL EXAMPLE: $15 position with $0.006 fee = 0.04% fee. $50 position with $0.02 fee = 0.04% fee. Same % but 3x profit potential!\n\n")
-	sb.WriteString("**Avoid low-quality signals**:\n")
-	sb.WriteString("- Single dimension (only looking at one indicator)\n")
-	sb.WriteString("- Contradictory (price up but volume shrinking)\n")
-	sb.WriteString("- Range-bound oscillation\n")
-	sb.WriteString("- Recently closed (<15 minutes ago)\n\n")
-	sb.WriteString("**🚨 CRITICAL: Position Management Rules - READ CAREFULLY 🚨**:\n")
-	sb.WriteString("**⚠️ IMPORTANT: Stop loss orders are DISABLED** - Positions will NOT be automatically closed by stop losses.\n")
-	sb.WriteString("- 🚫 **NEVER close losing positions** - The system will reject any attempt to close positions with negative P&L\n")
-	sb.WriteString("- ✅ **ONLY close profitable positions** - Wait for positions to become profitable before closing\n")
-	sb.WriteString("- ✅ **Let losing positions recover** - Hold losing positions until they become profitable or you decide to wait longer\n")
-	sb.WriteString("- ✅ **Take profits on winners** - Close profitable positions to lock in gains (≥3-5%%+ profit recommended)\n")
-	sb.WriteString(fmt.Sprintf("- 💡 **Risk Management**: Still size trades appropriately - max risk ≤ %.1f%% of equity (≈ %.2f USDT) per trade\n",
-		maxRiskPerTradeFraction*100, accountEquity*maxRiskPerTradeFraction))
-	sb.WriteString("- 💡 **Position Sizing**: Use appropriate position sizes and leverage to manage risk, even without stop loss orders\n\n")
-	sb.WriteString("**Take Profit Strategy**:\n")
-	sb.WriteString("- ✅ Take profits when positions are significantly profitable (≥3-5%+ unrealized P&L)\n")
-	sb.WriteString("- ✅ Close positions that have reached or exceeded take profit targets\n")
-	sb.WriteString("- ✅ If position is profitable but trend is reversing, take profit to lock in gains\n")
-	sb.WriteString("- 💡 Balance: Don't close too early (<2% profit), but don't be greedy - take profits when good (3-5%+)\n")
-	sb.WriteString("- 💡 Example: ETH +5.51%% is excellent profit - consider closing to lock in gains, especially if trend weakening\n")
-	sb.WriteString("- ⚠️ Remember: Fees are already paid when opening - closing profitable positions locks in real profit!\n\n")
-	sb.WriteString("**CRITICAL: Position Limit Rules**:\n")
-	sb.WriteString("- ⚠️ MAXIMUM 6 POSITIONS TOTAL (HARD LIMIT - system will reject excess)\n")
-	sb.WriteString("- ✅ ALLOWED: Multiple positions in the same coin are allowed (e.g., 2 ETHUSDT long, 1 ETHUSDT short)\n")
-	sb.WriteString("- ⚠️ Check current positions before deciding to open new ones!\n")
-	sb.WriteString("- ⚠️ Build gradually: add one position at a time and reassess before adding more\n")
-	marginPerPos3 := accountEquity * 0.20
-	maxPos3 := (accountEquity * 0.93) / marginPerPos3
-	sb.WriteString(fmt.Sprintf("- 💡 With %.0f USDT available, you can open ~%.0f positions of $%.0f margin each - don't be too conservative!\n",
-		accountEquity*0.93, maxPos3, marginPerPos3))
-	sb.WriteString("- ⚠️ If you already have 4-5 positions, HOLD unless a high-conviction setup appears\n")
-	sb.WriteString("- 💡 Strategy: Quality over quantity - but use available capital efficiently!\n\n")
-
-	// === Sharpe Ratio Self-Evolution ===
-	sb.WriteString("# 🧬 Sharpe Ratio Self-Evolution & Learning from Mistakes\n\n")
-	sb.WriteString("You will receive **Sharpe Ratio** and **Historical Performance** as feedback each cycle:\n\n")
-	sb.WriteString("**CRITICAL: You MUST learn from your mistakes!**\n")
-	sb.WriteString("- If you see recent losses, analyze WHY they happened\n")
-	sb.WriteString("- If a symbol consistently loses, avoid it or be extra cautious\n")
-	sb.WriteString("- If your win rate is low, reduce trading frequency and only take highest confidence setups\n")
-	sb.WriteString("- If losses are large, your position sizing or stop loss placement may be wrong\n\n")
-	sb.WriteString("**Sharpe Ratio < -0.5** (sustained losses):\n")
-	sb.WriteString("  → 🛑 Stop trading, wait at least 6 cycles (18 minutes)\n")
-	sb.WriteString("  → 🔍 Deep reflection:\n")
-	sb.WriteString("     • Trading frequency too high? (>2 trades/hour is excessive)\n")
-	sb.WriteString("     • Holding time too short? (<30 minutes is premature exit)\n")
-	sb.WriteString("     • Signal strength insufficient? (confidence <75)\n")
-	sb.WriteString("     • Are you shorting? (one-sided long-only is wrong)\n\n")
-	sb.WriteString("**Sharpe Ratio -0.5 ~ 0** (slight losses):\n")
-	sb.WriteString("  → ⚠️ Strict control: only trades with confidence ≥85\n")
-	sb.WriteString("  → Reduce frequency: maximum 1 new position per 30 minutes\n")
-	sb.WriteString("  → Patient holding: hold at least 20+ minutes (fees require longer holds)\n")
-	sb.WriteString(fmt.Sprintf("  → ⚠️ FEES MATTER: Use meaningful position sizes — target $%.0f-$%.0f (BTC/ETH) or $%.0f-$%.0f (altcoins) per position\n", accountEquity*0.20, accountEquity*0.35, accountEquity*0.15, accountEquity*0.25))
-	sb.WriteString("  → 💡 Trade less, hold longer, but if decent profit and can cover fees, just close it\n\n")
-	sb.WriteString("**Sharpe Ratio 0 ~ 0.7** (positive returns):\n")
-	sb.WriteString("  → ✅ Maintain current strategy\n\n")
-	sb.WriteString("**Sharpe Ratio > 0.7** (excellent performance):\n")
-	sb.WriteString("  → 🚀 Can moderately increase position size\n\n")
-	sb.WriteString("**Key**: Sharpe Ratio is the only metric, it naturally penalizes frequent trading and excessive in/out.\n\n")
-
-	// === Decision Process ===
-	sb.WriteString("# 📋 Decision Process\n\n")
-	sb.WriteString("1. **Check Market Regime FIRST** (CRITICAL - DO THIS BEFORE ANYTHING ELSE):\n")
-	sb.WriteString("   - Is BTC crashing? (1h < -1.0%% AND 4h < -0.5%%) → SHORT or WAIT, DO NOT LONG\n")
-	sb.WriteString("   - Is BTC bullish? (1h > +0.5%% AND 4h > +0.3%%) → LONG opportunities valid\n")
-	sb.WriteString("   - Is market neutral? → Wait for clear signals, be cautious\n")
-	sb.WriteString("   - ⚠️ **REMEMBER**: Market regime OVERRIDES individual coin signals!\n")
-	sb.WriteString("   - If market is crashing, individual 'bounce' signals are likely FALSE - ignore them\n")
-	sb.WriteString("2. **Analyze Sharpe Ratio**: Is current strategy effective? Need adjustment?\n")
-	sb.WriteString("3. **Evaluate positions**: Has trend changed? Should take profit/stop loss?\n")
-	sb.WriteString("   - 🚫 **CRITICAL**: DO NOT close positions with negative P&L (losing positions)\n")
-	sb.WriteString("   - ✅ **ONLY**: Close positions with positive P&L (profitable positions) to lock in gains\n")
-	sb.WriteString("   - 💡 **Remember**: The system will automatically reject any attempt to close a losing position\n")
-	sb.WriteString("4. **Find new opportunities**: Any strong signals? Long/short opportunities?\n")
-	sb.WriteString("   - ⚠️ **CRITICAL**: If market regime is CRASHING, only consider SHORT or WAIT\n")
-	sb.WriteString("   - ⚠️ **CRITICAL**: If market regime is CRASHING, ignore oversold bounce signals (they're traps)\n")
-	sb.WriteString("5. **Output decision**: Chain of thought analysis + JSON\n\n")
-
-	// === Output Format ===
-	sb.WriteString("# 📤 Output Format\n\n")
-	sb.WriteString("**CRITICAL: You MUST output BOTH parts. The JSON array is MANDATORY, even if all decisions are \"wait\".**\n\n")
-	sb.WriteString("**Step 1: Chain of Thought (plain text)**\n")
-	sb.WriteString("Concisely analyze your thinking process in English\n\n")
-	sb.WriteString("**Step 2: JSON Decision Array (REQUIRED)**\n")
-	sb.WriteString("After your chain of thought, you MUST include a JSON array with your decisions.\n")
-	sb.WriteString("Even if you decide to wait, output an array with at least one decision (e.g., `{\"symbol\": \"ALL\", \"action\": \"wait\", \"reasoning\": \"...\"}`).\n\n")
-	sb.WriteString("Format example:\n")
-	sb.WriteString("```json\n[\n")
-	sb.WriteString(fmt.Sprintf("  {\"symbol\": \"BTCUSDT\", \"action\": \"open_short\", \"leverage\": 4, \"position_size_usd\": %.0f, \"stop_loss\": 97000, \"take_profit\": 91000, \"confidence\": 80, \"risk_usd\": 40, \"reasoning\": \"Downtrend + MACD bearish crossover (lower confidence 80%% - using conservative 4x leverage)\"},\n", accountEquity*0.25))
-	sb.WriteString(fmt.Sprintf("  {\"symbol\": \"ETHUSDT\", \"action\": \"open_long\", \"leverage\": 5, \"position_size_usd\": %.0f, \"stop_loss\": 2700, \"take_profit\": 2900, \"confidence\": 87, \"risk_usd\": 30, \"reasoning\": \"Uptrend + RSI recovery (moderate confidence 87%% - using balanced 5x leverage)\"},\n", accountEquity*0.20))
-	sb.WriteString(fmt.Sprintf("  {\"symbol\": \"ADAUSDT\", \"action\": \"open_long\", \"leverage\": 7, \"position_size_usd\": %.0f, \"stop_loss\": 0.5200, \"take_profit\": 0.5750, \"confidence\": 95, \"risk_usd\": 20, \"reasoning\": \"Oversold bounce + volume expansion (high confidence 95%% - using maximum 7x leverage)\"},\n", accountEquity*0.20))
-	sb.WriteString("  {\"symbol\": \"SOLUSDT\", \"action\": \"close_long\", \"reasoning\": \"Take profit exit - position is profitable (+5.2%%)\"}\n")
-	sb.WriteString("]\n```\n")
-	sb.WriteString("⚠️ **CRITICAL REMINDER**: Only close positions that are PROFITABLE (positive P&L). If a position is losing (negative P&L), DO NOT attempt to close it - the system will reject it automatically. Example: If BNBUSDT is -2.5%%, wait until it becomes positive before closing.\n\n")
-	sb.WriteString(fmt.Sprintf("⚠️ Note: Position sizes should be meaningful ($%.0f-$%.0f for BTC/ETH, $%.0f-$%.0f for altcoins). Smaller trades get eaten by fees; oversized trades tie up margin.\n\n", accountEquity*0.20, accountEquity*0.35, accountEquity*0.15, accountEquity*0.25))
-	sb.WriteString("**Field descriptions**:\n")
-	sb.WriteString("- `action`: open_long | open_short | close_long | close_short | hold | wait\n")
-	sb.WriteString("- `confidence`: 0-100 (REQUIRE ≥85 for opening positions - fees require higher confidence)\n")
-	sb.WriteString("- `leverage`: MUST vary based on confidence! Higher confidence = higher leverage, lower confidence = lower leverage:\n")
-	if btcEthLeverage == altcoinLeverage {
-		sb.WriteString(fmt.Sprintf("  • Range: 1-%dx (BTC/ETH and altcoins both max at %dx)\n", btcEthLeverage, btcEthLeverage))
-	} else {
-		sb.WriteString(fmt.Sprintf("  • Range: 1-%dx for BTC/ETH, 1-%dx for altcoins\n", btcEthLeverage, altcoinLeverage))
+// compactMaxCandidates candidate coins kept in the compact prompt
+const compactMaxCandidates = 5
+
+// compactContext a reduced copy of ctx for the retry after a timeout: account, positions and the top
+// candidates only (no history, memory, pool or rejected-trade sections)
+func compactContext(ctx *Context) *Context {
+	compact := *ctx
+	compact.CandidateCoins = nil
+	for _, coin := range ctx.CandidateCoins {
+		if _, ok := ctx.MarketDataMap[coin.Symbol]; !ok {
+			continue
+		}
+		compact.CandidateCoins = append(compact.CandidateCoins, coin)
+		if len(compact.CandidateCoins) >= compactMaxCandidates {
+			break
+		}
 	}
-	sb.WriteString("  • Confidence 75-85%: Use 3-5x leverage (lower confidence - conservative approach)\n")
-	sb.WriteString("  • Confidence 85-90%: Use 5-6x leverage (moderate - balanced risk/reward)\n")
-	sb.WriteString(fmt.Sprintf("  • Confidence 90%%+: Use %dx leverage (maximum - highest conviction only)\n", max(btcEthLeverage, altcoinLeverage)))
-	sb.WriteString("  • ⚠️ CRITICAL: Do NOT always use maximum leverage! Adjust leverage based on signal quality and confidence.\n")
-	sb.WriteString("  • 💡 Strategy: Lower confidence (75-85%) → conservative leverage (3-5x), moderate confidence (85-90%) → balanced leverage (5-6x), high confidence (90%+) → maximum leverage (7x)\n")
-	sb.WriteString(fmt.Sprintf("  • 💡 Example: 80%% confidence → 4x leverage, 87%% confidence → 5x leverage, 92%% confidence → 7x leverage, 98%% confidence → 7x leverage\n"))
-	sb.WriteString("- `position_size_usd`: MARGIN (actual USDT used), NOT notional! Use meaningful sizes based on your equity:\n")
-	sb.WriteString(fmt.Sprintf("  • BTC/ETH: MINIMUM $%.0f MARGIN (20%% of equity) – TARGET $%.0f-$%.0f MARGIN (20-35%% of equity)\n", accountEquity*0.20, accountEquity*0.20, accountEquity*0.35))
-	sb.WriteString(fmt.Sprintf("  • Altcoins: MINIMUM $%.0f MARGIN (15%% of equity) – TARGET $%.0f-$%.0f MARGIN (15-25%% of equity)\n", accountEquity*0.15, accountEquity*0.15, accountEquity*0.25))
-	sb.WriteString(fmt.Sprintf("  • 💡 CRITICAL: With %dx leverage, $%.0f margin = $%.0f notional position (%.0f × %d)\n", altcoinLeverage, accountEquity*0.20, accountEquity*0.20*float64(altcoinLeverage), accountEquity*0.20, altcoinLeverage))
-	sb.WriteString(fmt.Sprintf("  • 💡 Example: $%.0f margin with %dx leverage creates a $%.0f notional position\n", accountEquity*0.20, altcoinLeverage, accountEquity*0.20*float64(altcoinLeverage)))
-	marginPerPosition := accountEquity * 0.20
-	maxPositions := (accountEquity * 0.93) / marginPerPosition
-	sb.WriteString(fmt.Sprintf("  • 💡 With %.0f USDT available, you can open ~%.0f positions of $%.0f margin each\n", accountEquity*0.93, maxPositions, marginPerPosition))
-	sb.WriteString("  • ⚠️ Positions below the minimum are rejected automatically (too small to overcome fees)\n")
-	sb.WriteString(fmt.Sprintf("  • ⚠️ Maximum: $%.0f margin for BTC/ETH, $%.0f margin for altcoins (to keep margin available for other opportunities)\n", accountEquity*0.50, accountEquity*0.40))
-	sb.WriteString("  • 💡 IMPORTANT: Calculate position size as a percentage of the ACTUAL equity shown in the account section, not a fixed dollar amount\n")
-	sb.WriteString(fmt.Sprintf("  • 💡 Example: If equity is 210 USDT, 25%% = 52.5 USDT MARGIN, 30%% = 63 USDT MARGIN. With %dx leverage, this creates $%.0f-$%.0f notional positions\n", altcoinLeverage, 52.5*float64(altcoinLeverage), 63*float64(altcoinLeverage)))
-	sb.WriteString("- Required for opening: leverage, position_size_usd, stop_loss (for risk planning only - not executed), take_profit, confidence, risk_usd, reasoning\n")
-	sb.WriteString("  • Note: `stop_loss` is required for risk calculation but will NOT be set as an order (losing positions cannot be closed)\n")
-	sb.WriteString("- If no actions: use `{\"symbol\": \"ALL\", \"action\": \"wait\", \"reasoning\": \"your reason\"}`\n\n")
-
-	// === Key Reminders ===
-	sb.WriteString("---\n\n")
-	sb.WriteString("🚨 **CRITICAL REMINDER**: NEVER attempt to close positions with negative P&L (losing positions). The system will automatically reject such decisions. Only close positions when they are profitable (positive P&L). This is a hard rule that cannot be overridden. If you see a position is losing money, wait for it to recover before closing.\n\n")
-	sb.WriteString("**Remember**: \n")
-	sb.WriteString("- Goal is Sharpe Ratio, not trading frequency\n")
-	sb.WriteString("- Short = Long, both are profit tools\n")
-	sb.WriteString("- Better to miss than make low-quality trades\n")
-	sb.WriteString("- Risk-reward ratio 1:3 is the bottom line\n")
-
-	return sb.String()
+	compact.Performance = nil
+	compact.RelevantTrades = nil
+	compact.MemorySize = 0
+	compact.PoolAdjustments = nil
+	compact.RejectedTrades = nil
+	compact.News = nil              // Market-wide headlines are kept
+	compact.LargeLiquidations = nil // Market-wide and per-symbol totals are kept
+	return &compact
 }
 
-// buildUserPrompt 构建 User Prompt（动态数据）
-func buildUserPrompt(ctx *Context) string {
-	var sb strings.Builder
-
-	// System status
+// writeStatus the time / cycle / runtime line
+func writeStatus(sb *strings.Builder, ctx *Context) {
 	sb.WriteString(fmt.Sprintf("**Time**: %s | **Cycle**: #%d | **Runtime**: %d minutes\n\n",
 		ctx.CurrentTime, ctx.CallCount, ctx.RuntimeMinutes))
+}
 
-	// BTC market (with crash detection)
+// writeBTCOverview BTC price with crash, bull and 4h downtrend warnings
+func writeBTCOverview(sb *strings.Builder, ctx *Context) {
 	if btcData, hasBTC := ctx.MarketDataMap["BTCUSDT"]; hasBTC {
 		sb.WriteString(fmt.Sprintf("**BTC**: %.2f (1h: %+.2f%%, 4h: %+.2f%%) | MACD: %.4f | RSI: %.2f\n\n",
 			btcData.CurrentPrice, btcData.PriceChange1h, btcData.PriceChange4h,
@@ -552,8 +513,10 @@ func buildUserPrompt(ctx *Context) string {
 			}
 		}
 	}
+}
 
-	// Account
+// writeAccount equity, P&L split and the per-trade risk guardrail
+func writeAccount(sb *strings.Builder, ctx *Context) {
 	sb.WriteString(fmt.Sprintf("**Account**: Equity %.2f | Balance %.2f (%.1f%%) | P&L %+.2f%% | Margin %.1f%% | Positions %d\n\n",
 		ctx.Account.TotalEquity,
 		ctx.Account.AvailableBalance,
@@ -561,19 +524,24 @@ func buildUserPrompt(ctx *Context) string {
 		ctx.Account.TotalPnLPct,
 		ctx.Account.MarginUsedPct,
 		ctx.Account.PositionCount))
+	sb.WriteString(fmt.Sprintf("**P&L Split**: Realized (banked) %+.2f USDT | Unrealized (open positions) %+.2f USDT - open profit is not banked until closed\n\n",
+		ctx.Account.RealizedPnL, ctx.Account.UnrealizedPnL))
 
 	// Risk budget reminder
+	policy := ctx.riskPolicy()
 	sb.WriteString(fmt.Sprintf("**Risk Guardrail**: Max %.2f USDT (%.1f%% of equity) loss per trade. Stops + sizing MUST respect this cap.\n\n",
-		ctx.Account.TotalEquity*maxRiskPerTradeFraction, maxRiskPerTradeFraction*100))
+		policy.MaxRiskUSD(ctx.Account.TotalEquity), policy.MaxRiskPerTradePct))
+}
 
-	// Current positions (full market data)
+// writePositions open positions with their full market data
+func writePositions(sb *strings.Builder, ctx *Context) {
 	if len(ctx.Positions) > 0 {
 		sb.WriteString("## Current Positions\n")
 		for i, pos := range ctx.Positions {
 			// Calculate holding duration
 			holdingDuration := ""
 			if pos.UpdateTime > 0 {
-				durationMs := time.Now().UnixMilli() - pos.UpdateTime
+				durationMs := now().UnixMilli() - pos.UpdateTime
 				durationMin := durationMs / (1000 * 60) // Convert to minutes
 				if durationMin < 60 {
 					holdingDuration = fmt.Sprintf(" | Holding for %d minutes", durationMin)
@@ -584,36 +552,47 @@ func buildUserPrompt(ctx *Context) string {
 				}
 			}
 
-			sb.WriteString(fmt.Sprintf("%d. %s %s | Entry %.4f Current %.4f | P&L %+.2f%% | Leverage %dx | Margin %.0f | Liq Price %.4f%s\n\n",
+			// Active protection levels (so amend actions can reference them)
+			protection := ""
+			if pos.StopLoss > 0 {
+				protection += fmt.Sprintf(" | Stop %.4f", pos.StopLoss)
+			}
+			if pos.TakeProfit > 0 {
+				protection += fmt.Sprintf(" | Target %.4f", pos.TakeProfit)
+			}
+
+			sb.WriteString(fmt.Sprintf("%d. %s %s | Entry %.4f Current %.4f | P&L %+.2f%% | Leverage %dx | Margin %.0f | Liq Price %.4f%s%s\n\n",
 				i+1, pos.Symbol, strings.ToUpper(pos.Side),
 				pos.EntryPrice, pos.MarkPrice, pos.UnrealizedPnLPct,
-				pos.Leverage, pos.MarginUsed, pos.LiquidationPrice, holdingDuration))
+				pos.Leverage, pos.MarginUsed, pos.LiquidationPrice, protection, holdingDuration))
 
 			// 使用FormatMarketData输出完整市场数据
 			if marketData, ok := ctx.MarketDataMap[pos.Symbol]; ok {
 				sb.WriteString(market.Format(marketData))
+				sb.WriteString(formatTimeframes(ctx, pos.Symbol))
+				sb.WriteString(formatLiquidations(ctx, pos.Symbol))
 				sb.WriteString("\n")
 			}
 		}
+		writeNetExposure(sb, ctx)
 	} else {
 		sb.WriteString("**Current Positions**: None\n\n")
 	}
+}
 
-	// Market-wide context (before candidate coins)
+// writeMarketRegime the market regime derived from BTC
+func writeMarketRegime(sb *strings.Builder, ctx *Context) {
 	sb.WriteString("## 🌍 Market-Wide Context\n\n")
 	if btcData, hasBTC := ctx.MarketDataMap["BTCUSDT"]; hasBTC {
-		// Calculate market regime
-		isCrashing := btcData.PriceChange1h < -1.0 && btcData.PriceChange4h < -0.5
-		isBullish := btcData.PriceChange1h > 0.5 && btcData.PriceChange4h > 0.3
-
-		if isCrashing {
+		regime, _ := BTCRegime(ctx)
+		if regime == RegimeCrashing {
 			sb.WriteString("🚨 **MARKET REGIME: CRASHING**\n")
 			sb.WriteString(fmt.Sprintf("- BTC is down significantly (1h: %.2f%%, 4h: %.2f%%)\n", btcData.PriceChange1h, btcData.PriceChange4h))
 			sb.WriteString("- Altcoins will likely fall MORE than BTC (higher correlation during crashes)\n")
 			sb.WriteString("- **STRATEGY**: SHORT or WAIT. DO NOT open LONG positions.\n")
 			sb.WriteString("- Oversold bounces (RSI < 30) are TRAPS during crashes - price can stay oversold for hours.\n")
 			sb.WriteString("- MACD 'improving' during crashes is NOT a buy signal - wait for market recovery.\n\n")
-		} else if isBullish {
+		} else if regime == RegimeBullish {
 			sb.WriteString("✅ **MARKET REGIME: BULLISH**\n")
 			sb.WriteString(fmt.Sprintf("- BTC is rising (1h: %.2f%%, 4h: %.2f%%)\n", btcData.PriceChange1h, btcData.PriceChange4h))
 			sb.WriteString("- LONG positions are preferred during bull markets\n")
@@ -627,9 +606,43 @@ func buildUserPrompt(ctx *Context) string {
 	} else {
 		sb.WriteString("⚠️ **BTC data unavailable** - Cannot determine market regime. Be extra cautious.\n\n")
 	}
+}
 
-	// Candidate coins (full market data)
+// writeSymbolThrottles symbols saturated by entries across traders (shared account)
+func writeSymbolThrottles(sb *strings.Builder, ctx *Context) {
+	if len(ctx.SymbolThrottles) > 0 {
+		sb.WriteString("## ⏳ Symbol Entry Throttle (shared across all traders)\n\n")
+		for _, t := range ctx.SymbolThrottles {
+			if t.Saturated {
+				sb.WriteString(fmt.Sprintf("- %s: **SATURATED** (%d/%d entries in last %d min) - opening %s will be rejected for ~%d min\n",
+					t.Symbol, t.Entries, t.MaxEntries, t.WindowMinutes, t.Symbol, (t.ResetInSeconds+59)/60))
+			} else {
+				sb.WriteString(fmt.Sprintf("- %s: %d/%d entries in last %d min\n",
+					t.Symbol, t.Entries, t.MaxEntries, t.WindowMinutes))
+			}
+		}
+		sb.WriteString("Do NOT open new positions in SATURATED symbols - pick another candidate or wait.\n\n")
+	}
+}
+
+// writeCandidates candidate coins with pool notes, adaptive pool changes and full market data
+func writeCandidates(sb *strings.Builder, ctx *Context) {
 	sb.WriteString(fmt.Sprintf("## Candidate Coins (%d)\n\n", len(ctx.MarketDataMap)))
+	if len(ctx.CoinPoolNotes) > 0 {
+		sb.WriteString("⚠️ **Stale pool**: the coin pool service is unavailable, candidates below come from fallback data:\n")
+		for _, note := range ctx.CoinPoolNotes {
+			sb.WriteString(fmt.Sprintf("- %s\n", note))
+		}
+		sb.WriteString("Candidate rankings may be outdated - rely on the live market data for each coin and demand stronger confirmation before opening.\n\n")
+	}
+	if len(ctx.PoolAdjustments) > 0 {
+		sb.WriteString("🎯 **Adaptive pool** (based on your own closed trades per symbol):\n")
+		for _, adj := range ctx.PoolAdjustments {
+			sb.WriteString(fmt.Sprintf("- %s %s: %d trades, %.0f%% win rate, avg %+.2f USDT/trade\n",
+				adj.Symbol, adj.Type, adj.Trades, adj.WinRate, adj.AvgPnL))
+		}
+		sb.WriteString("Boosted/added coins are listed first, demoted coins last, excluded coins are not offered. Still require a valid setup - past edge is not a signal by itself.\n\n")
+	}
 	displayedCount := 0
 	for _, coin := range ctx.CandidateCoins {
 		marketData, hasData := ctx.MarketDataMap[coin.Symbol]
@@ -639,20 +652,30 @@ func buildUserPrompt(ctx *Context) string {
 		displayedCount++
 
 		sourceTags := ""
-		if len(coin.Sources) > 1 {
+		if len(coin.Sources) == 2 && coin.Sources[0] == "ai500" && coin.Sources[1] == "oi_top" {
 			sourceTags = " (AI500+OI_Top dual signal)"
+		} else if len(coin.Sources) > 1 {
+			sourceTags = fmt.Sprintf(" (%d sources: %s)", len(coin.Sources), strings.Join(coin.Sources, "+"))
 		} else if len(coin.Sources) == 1 && coin.Sources[0] == "oi_top" {
 			sourceTags = " (OI_Top open interest growth)"
+		} else if len(coin.Sources) == 1 && coin.Sources[0] == "history" {
+			sourceTags = " (added from your trading history)"
+		} else if len(coin.Sources) == 1 && coin.Sources[0] != "ai500" {
+			sourceTags = fmt.Sprintf(" (source: %s)", coin.Sources[0])
 		}
 
 		// Use FormatMarketData to output full market data
 		sb.WriteString(fmt.Sprintf("### %d. %s%s\n\n", displayedCount, coin.Symbol, sourceTags))
 		sb.WriteString(market.Format(marketData))
+		sb.WriteString(formatTimeframes(ctx, coin.Symbol))
+		sb.WriteString(formatLiquidations(ctx, coin.Symbol))
 		sb.WriteString("\n")
 	}
 	sb.WriteString("\n")
+}
 
-	// Historical Performance & Learning Data
+// writePerformance historical performance and the trades to learn from
+func writePerformance(sb *strings.Builder, ctx *Context) {
 	if ctx.Performance != nil {
 		// Extract performance data
 		type PerformanceData struct {
@@ -675,6 +698,15 @@ func buildUserPrompt(ctx *Context) string {
 			} `json:"recent_trades"`
 			BestSymbol  string `json:"best_symbol"`
 			WorstSymbol string `json:"worst_symbol"`
+
+			StartTime        time.Time `json:"start_time"`
+			EndTime          time.Time `json:"end_time"`
+			ReturnPct        float64   `json:"return_pct"`
+			MaxDrawdownPct   float64   `json:"max_drawdown_pct"`
+			AvgDrawdownHours float64   `json:"avg_drawdown_hours"`
+			CalmarRatio      float64   `json:"calmar_ratio"`
+			Return24hPct     *float64  `json:"return_24h_pct"`
+			Return7dPct      *float64  `json:"return_7d_pct"`
 		}
 		var perfData PerformanceData
 		if jsonData, err := json.Marshal(ctx.Performance); err == nil {
@@ -691,14 +723,32 @@ func buildUserPrompt(ctx *Context) string {
 					}
 				}
 
+				// Equity curve: how deep and how long the losing stretches were, and the recent trend
+				if perfData.EndTime.After(perfData.StartTime) {
+					line := fmt.Sprintf("**Equity Curve**: Return %+.2f%% | Max Drawdown %.2f%% (avg %.1fh to recover)",
+						perfData.ReturnPct, perfData.MaxDrawdownPct, perfData.AvgDrawdownHours)
+					if perfData.CalmarRatio != 0 {
+						line += fmt.Sprintf(" | Calmar: %.2f", perfData.CalmarRatio)
+					}
+					if perfData.Return24hPct != nil {
+						line += fmt.Sprintf(" | 24h: %+.2f%%", *perfData.Return24hPct)
+					}
+					if perfData.Return7dPct != nil {
+						line += fmt.Sprintf(" | 7d: %+.2f%%", *perfData.Return7dPct)
+					}
+					sb.WriteString(line + "\n\n")
+				}
+
 				// Best/worst symbols
 				if perfData.BestSymbol != "" {
 					sb.WriteString(fmt.Sprintf("**Best Symbol**: %s | **Worst Symbol**: %s\n\n",
 						perfData.BestSymbol, perfData.WorstSymbol))
 				}
 
-				// Recent trades (last 5-10 for learning)
-				if len(perfData.RecentTrades) > 0 {
+				// Relevant past trades from trade memory, otherwise recent trades (last 5-10 for learning)
+				if ctx.MemorySize > 0 {
+					writeRelevantTrades(sb, ctx)
+				} else if len(perfData.RecentTrades) > 0 {
 					sb.WriteString("**Recent Trades (Learn from these)**:\n")
 					displayCount := len(perfData.RecentTrades)
 					if displayCount > 10 {
@@ -773,33 +823,51 @@ func buildUserPrompt(ctx *Context) string {
 			}
 		}
 	}
+}
 
+// writeOutputFormat the required output format reminder (JSON object or chain of thought + JSON array)
+func writeOutputFormat(sb *strings.Builder, ctx *Context) {
 	sb.WriteString("---\n\n")
 	sb.WriteString("**REQUIRED OUTPUT FORMAT:**\n")
+	if ctx.StructuredOutput {
+		sb.WriteString("ONE JSON object: `reasoning` (chain of thought analysis, in English) and `decisions` (MANDATORY - must include even if all decisions are \"wait\")\n\n")
+		sb.WriteString("Now please analyze and output your decision. Remember: output only the JSON object, with at least one decision (use \"wait\" action if no trades). All analysis and reasoning must be in English.\n")
+		return
+	}
 	sb.WriteString("1. Chain of thought analysis (plain text, in English)\n")
 	sb.WriteString("2. JSON array with decisions (MANDATORY - must include even if all decisions are \"wait\")\n\n")
 	sb.WriteString("Now please analyze and output your decision. Remember: the JSON array is REQUIRED - output at least one decision (use \"wait\" action if no trades). All analysis and reasoning must be in English.\n")
-
-	return sb.String()
 }
 
-// parseFullDecisionResponse parses AI's complete decision response
-func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthLeverage, altcoinLeverage int) (*FullDecision, error) {
-	// 1. Extract chain of thought
-	cotTrace := extractCoTTrace(aiResponse)
-
-	// Ensure CoTTrace is not empty (even if extraction fails, save at least part of response)
-	if cotTrace == "" && len(aiResponse) > 0 {
-		// If extraction fails, save first 1000 characters as CoTTrace
-		if len(aiResponse) > 1000 {
-			cotTrace = aiResponse[:1000] + "..."
-		} else {
-			cotTrace = aiResponse
+// parseFullDecisionResponse parses AI's complete decision response and validates it against ctx (equity,
+// leverage limits, positions and risk policy)
+func parseFullDecisionResponse(aiResponse string, ctx *Context) (*FullDecision, error) {
+	var (
+		cotTrace  string
+		decisions []Decision
+		err       error
+	)
+	if structured, ok := parseStructuredResponse(aiResponse); ok {
+		// Structured output (JSON mode / function calling): reasoning and decisions come as typed fields
+		cotTrace = structured.Reasoning
+		decisions = structured.Decisions
+	} else {
+		// 1. Extract chain of thought
+		cotTrace = extractCoTTrace(aiResponse)
+
+		// Ensure CoTTrace is not empty (even if extraction fails, save at least part of response)
+		if cotTrace == "" && len(aiResponse) > 0 {
+			// If extraction fails, save first 1000 characters as CoTTrace
+			if len(aiResponse) > 1000 {
+				cotTrace = aiResponse[:1000] + "..."
+			} else {
+				cotTrace = aiResponse
+			}
 		}
-	}
 
-	// 2. Extract JSON decision list
-	decisions, err := extractDecisions(aiResponse)
+		// 2. Extract JSON decision list
+		decisions, err = extractDecisions(aiResponse)
+	}
 	usedFallback := false
 	if err != nil {
 		// Fallback: Create a default "wait" decision if JSON extraction fails
@@ -864,13 +932,24 @@ func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthL
 	// The fallback mechanism ONLY activates when JSON extraction completely fails - it does NOT affect valid decisions.
 	if !usedFallback {
 		// Valid decisions from AI: Apply full validation with all risk controls
-		if err := validateDecisions(decisions, accountEquity, btcEthLeverage, altcoinLeverage); err != nil {
-			// Validation failed - return error with decisions for debugging
-			// This preserves the AI's attempted decisions for analysis
+		valid, rejected, err := validateDecisions(ctx, decisions)
+		if err != nil {
+			// Validation failed - only the decisions that passed are executed, rejected ones are kept separately
+			// for analysis (outcome simulation). If nothing passed, wait this cycle
+			if len(valid) == 0 {
+				valid = []Decision{
+					{
+						Symbol:    "ALL",
+						Action:    "wait",
+						Reasoning: fmt.Sprintf("All %d decisions rejected by validation - waiting for next cycle", len(rejected)),
+					},
+				}
+			}
 			return &FullDecision{
 				CoTTrace:    cotTrace,
 				RawResponse: aiResponse,
-				Decisions:   decisions,
+				Decisions:   valid,
+				Rejected:    rejected,
 			}, fmt.Errorf("decision validation failed: %w\n\n=== AI Chain of Thought Analysis ===\n%s", err, cotTrace)
 		}
 		// Valid decisions pass through unchanged - no modifications, full risk controls applied
@@ -886,10 +965,6 @@ func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthL
 				decisions[i].Symbol = "ALL"
 			}
 			// Ensure action is valid (should always be "wait" for fallback)
-			validActions := map[string]bool{
-				"open_long": true, "open_short": true, "close_long": true,
-				"close_short": true, "hold": true, "wait": true,
-			}
 			if !validActions[decisions[i].Action] {
 				decisions[i].Action = "wait"
 			}
@@ -1269,11 +1344,58 @@ func fixMissingQuotes(jsonStr string) string {
 	return jsonStr
 }
 
-// validateDecisions validates all decisions (requires account info and leverage config)
-func validateDecisions(decisions []Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int) error {
-	for i, decision := range decisions {
-		if err := validateDecision(&decision, accountEquity, btcEthLeverage, altcoinLeverage); err != nil {
-			return fmt.Errorf("decision #%d validation failed: %w", i+1, err)
+// validateDecisions validates all decisions against ctx (account equity, leverage config, open positions and the
+// risk policy). Returns the decisions that passed, the rejected ones, and an error describing the first rejection
+func validateDecisions(ctx *Context, decisions []Decision) ([]Decision, []RejectedDecision, error) {
+	var valid []Decision
+	var rejected []RejectedDecision
+	var firstErr error
+	for i := range decisions {
+		// Validate in place so risk-cap margin adjustments are kept
+		if err := validateDecision(&decisions[i], ctx); err != nil {
+			rejected = append(rejected, RejectedDecision{
+				Decision: decisions[i],
+				Reason:   err.Error(),
+				Category: rejectionCategory(err),
+			})
+			if firstErr == nil {
+				firstErr = fmt.Errorf("decision #%d validation failed: %w", i+1, err)
+			}
+			continue
+		}
+		valid = append(valid, decisions[i])
+	}
+	return valid, rejected, firstErr
+}
+
+// validateAmendDecision validates adjust_stop / adjust_target / add_margin / reduce_size parameters
+func validateAmendDecision(d *Decision, accountEquity float64, policy *RiskPolicy) error {
+	d.Side = strings.ToLower(d.Side)
+	if d.Side != "" && d.Side != "long" && d.Side != "short" {
+		return fmt.Errorf("%s: side must be \"long\" or \"short\", got %q", d.Action, d.Side)
+	}
+
+	switch d.Action {
+	case "adjust_stop":
+		if d.StopLoss <= 0 {
+			return fmt.Errorf("adjust_stop requires stop_loss greater than 0")
+		}
+	case "adjust_target":
+		if d.TakeProfit <= 0 {
+			return fmt.Errorf("adjust_target requires take_profit greater than 0")
+		}
+	case "add_margin":
+		if d.PositionSizeUSD <= 0 {
+			return fmt.Errorf("add_margin requires position_size_usd (margin to add) greater than 0")
+		}
+		maxAdd := policy.MaxAddMarginUSD(accountEquity)
+		if d.PositionSizeUSD > maxAdd {
+			return fmt.Errorf("add_margin cannot exceed %.0f USDT (%g%% of equity) per decision, actual: %.0f",
+				maxAdd, policy.MaxAddMarginPct, d.PositionSizeUSD)
+		}
+	case "reduce_size":
+		if d.ReducePct <= 0 || d.ReducePct >= 100 {
+			return fmt.Errorf("reduce_size requires reduce_pct between 0 and 100 (use close_long/close_short to exit fully): %.1f", d.ReducePct)
 		}
 	}
 	return nil
@@ -1301,28 +1423,71 @@ func findMatchingBracket(s string, start int) int {
 	return -1
 }
 
-// validateDecision validates a single decision's validity
-func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int) error {
-	// Validate action
-	validActions := map[string]bool{
-		"open_long":   true,
-		"open_short":  true,
-		"close_long":  true,
-		"close_short": true,
-		"hold":        true,
-		"wait":        true,
+// Market regimes derived from BTC's 1h and 4h price change
+const (
+	RegimeCrashing = "crashing"
+	RegimeBullish  = "bullish"
+	RegimeNeutral  = "neutral"
+)
+
+// BTCRegime the market regime the prompt states ("" = no BTC data) and whether BTC is in a 4h downtrend
+// (EMA20 < EMA50 and price < EMA20)
+func BTCRegime(ctx *Context) (regime string, downtrend bool) {
+	btcData, hasBTC := ctx.MarketDataMap["BTCUSDT"]
+	if !hasBTC || btcData == nil {
+		return "", false
+	}
+	switch {
+	case btcData.PriceChange1h < -1.0 && btcData.PriceChange4h < -0.5:
+		regime = RegimeCrashing
+	case btcData.PriceChange1h > 0.5 && btcData.PriceChange4h > 0.3:
+		regime = RegimeBullish
+	default:
+		regime = RegimeNeutral
 	}
+	if lt := btcData.LongerTermContext; lt != nil {
+		downtrend = lt.EMA20 < lt.EMA50 && btcData.CurrentPrice < lt.EMA20
+	}
+	return regime, downtrend
+}
 
+// validateDecision validates a single decision's validity
+func validateDecision(d *Decision, ctx *Context) error {
+	accountEquity := ctx.Account.TotalEquity
+	btcEthLeverage, altcoinLeverage := ctx.BTCETHLeverage, ctx.AltcoinLeverage
+	policy := ctx.riskPolicy()
+
+	// Validate action
 	if !validActions[d.Action] {
 		return fmt.Errorf("invalid action: %s", d.Action)
 	}
 
+	// Amend actions only need their own parameter (price checks happen at execution against the live position)
+	if IsAmendAction(d.Action) {
+		return validateAmendDecision(d, accountEquity, policy)
+	}
+	if IsAddAction(d.Action) {
+		return validateAddDecision(d, ctx)
+	}
+
+	if d.Action == "close_long" || d.Action == "close_short" {
+		if d.ClosePct < 0 || d.ClosePct > 100 {
+			return fmt.Errorf("close_pct must be between 0 and 100 (omit it to close the whole position): %.1f", d.ClosePct)
+		}
+	}
+
 	// Opening positions must provide complete parameters
 	if d.Action == "open_long" || d.Action == "open_short" {
+		// One position per symbol and side: more size goes through add_* so the entry is averaged
+		if side := EntrySide(d.Action); findPosition(ctx, d.Symbol, side) != nil {
+			return fmt.Errorf("%s position on %s already open: use add_%s to add to it", side, d.Symbol, side)
+		} else if ctx.ProhibitHedging && opposingPosition(ctx, d.Symbol, side) != nil {
+			return fmt.Errorf("hedging is prohibited for this trader: %s already has an opposite position, close it before opening %s", d.Symbol, side)
+		}
+
 		// Use configured leverage limits based on coin type
 		maxLeverage := altcoinLeverage // Altcoins use configured leverage
-		isBTCOrETH := d.Symbol == "BTCUSDT" || d.Symbol == "ETHUSDT"
-		if isBTCOrETH {
+		if isBTCOrETH(d.Symbol) {
 			maxLeverage = btcEthLeverage // BTC and ETH use configured leverage
 		}
 
@@ -1334,26 +1499,23 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 		}
 
 		// Establish baseline minimum margin (trade must be meaningful)
-		minMargin := math.Max(13, accountEquity*0.15) // Altcoins: 15% of equity minimum margin
-		if isBTCOrETH {
-			minMargin = math.Max(15, accountEquity*0.20) // BTC/ETH: 20% of equity minimum margin
-		}
+		minMargin := policy.MinOpenMargin(d.Symbol, accountEquity)
 
 		// Validate position margin upper limit (position_size_usd is now MARGIN, not notional)
-		maxMargin := accountEquity * 0.50 // Max 50% of equity as margin for BTC/ETH
-		if !isBTCOrETH {
-			maxMargin = accountEquity * 0.40 // Max 40% of equity as margin for altcoins
-		}
+		maxMargin := policy.MaxOpenMargin(d.Symbol, accountEquity)
 		if d.PositionSizeUSD > maxMargin {
-			if isBTCOrETH {
-				return fmt.Errorf("BTC/ETH position margin cannot exceed %.0f USDT (50%% of equity), actual: %.0f", maxMargin, d.PositionSizeUSD)
-			} else {
-				return fmt.Errorf("altcoin position margin cannot exceed %.0f USDT (40%% of equity), actual: %.0f", maxMargin, d.PositionSizeUSD)
+			kind, maxPct := "altcoin", policy.MaxMarginPctAltcoin
+			if isBTCOrETH(d.Symbol) {
+				kind, maxPct = "BTC/ETH", policy.MaxMarginPctBTCETH
 			}
+			return fmt.Errorf("%s position margin cannot exceed %.0f USDT (%g%% of equity), actual: %.0f", kind, maxMargin, maxPct, d.PositionSizeUSD)
 		}
 		if d.StopLoss <= 0 || d.TakeProfit <= 0 {
 			return fmt.Errorf("stop loss and take profit must be greater than 0")
 		}
+		if err := normalizeOrderType(d); err != nil {
+			return err
+		}
 
 		// Validate stop loss/take profit reasonableness
 		if d.Action == "open_long" {
@@ -1366,7 +1528,7 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 			}
 		}
 
-		// Validate risk-reward ratio (must be ≥1:3)
+		// Validate risk-reward ratio (must be ≥ the policy's minimum)
 		// Calculate entry price (assuming current market price)
 		var assumedEntryPrice float64
 		if d.Action == "open_long" {
@@ -1392,22 +1554,29 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 			}
 		}
 
-		// Hard constraint: risk-reward ratio must be ≥3.0
-		if riskRewardRatio < 3.0 {
-			return fmt.Errorf("risk-reward ratio too low (%.2f:1), must be ≥3.0:1 [risk:%.2f%% reward:%.2f%%] [stop loss:%.2f take profit:%.2f]",
-				riskRewardRatio, riskPercent, rewardPercent, d.StopLoss, d.TakeProfit)
+		// Hard constraint: risk-reward ratio must reach the policy's minimum
+		if riskRewardRatio < policy.MinRiskReward {
+			return fmt.Errorf("risk-reward ratio too low (%.2f:1), must be ≥%.1f:1 [risk:%.2f%% reward:%.2f%%] [stop loss:%.2f take profit:%.2f]",
+				riskRewardRatio, policy.MinRiskReward, riskPercent, rewardPercent, d.StopLoss, d.TakeProfit)
 		}
 
 		// Enforce absolute dollar risk cap using live market price
-		marketData, err := market.Get(d.Symbol)
+		currentPrice, err := priceSource(d.Symbol)
 		if err != nil {
 			return fmt.Errorf("failed to fetch market data for %s: %w", d.Symbol, err)
 		}
-		currentPrice := marketData.CurrentPrice
 		if currentPrice <= 0 {
 			return fmt.Errorf("invalid market price for %s", d.Symbol)
 		}
 
+		// A limit entry fills at limit_price: stop distance and risk are measured from there
+		if d.IsLimitEntry() {
+			if err := validateLimitEntry(d, currentPrice); err != nil {
+				return err
+			}
+			currentPrice = d.LimitPrice
+		}
+
 		var riskPerUnit float64
 		var stopLossDistancePercent float64
 		if d.Action == "open_long" {
@@ -1421,16 +1590,12 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 			return fmt.Errorf("stop loss %.4f must be on the correct side of current price %.4f", d.StopLoss, currentPrice)
 		}
 
-		// Validate stop loss distance for risk planning (stop loss orders are disabled, but we still validate for risk management)
+		// Validate stop loss distance (a placed stop order with honor_stops, risk planning only otherwise)
 		// With 7x leverage, a -10% price move = -70% loss on margin!
-		// Reuse isBTCOrETH from earlier in function
-		maxStopLossPercent := 5.0 // Max 5% stop loss for altcoins (for risk planning)
-		if isBTCOrETH {
-			maxStopLossPercent = 3.0 // Max 3% stop loss for BTC/ETH (for risk planning)
-		}
+		maxStopLossPercent := policy.MaxStopPct(d.Symbol)
 
 		if stopLossDistancePercent > maxStopLossPercent {
-			return fmt.Errorf("stop loss distance too wide for risk planning: %.2f%% (max allowed: %.1f%% for %s). With %dx leverage, this would represent %.1f%% potential loss on margin. Note: Stop loss orders are disabled - this is for risk calculation only",
+			return fmt.Errorf("stop loss distance too wide for risk planning: %.2f%% (max allowed: %.1f%% for %s). With %dx leverage, this would represent %.1f%% potential loss on margin",
 				stopLossDistancePercent, maxStopLossPercent, d.Symbol, d.Leverage, stopLossDistancePercent*float64(d.Leverage))
 		}
 
@@ -1439,14 +1604,19 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 			return fmt.Errorf("invalid notional value computed for %s: %.4f", d.Symbol, notional)
 		}
 
-		maxRiskUSD := accountEquity * maxRiskPerTradeFraction
+		maxRiskUSD := policy.MaxRiskUSD(accountEquity)
 
 		if maxRiskUSD <= 0 {
 			return fmt.Errorf("invalid account equity %.2f for risk calculation", accountEquity)
 		}
 
-		allowedNotional := maxRiskUSD * currentPrice / riskPerUnit
-		allowedMargin := allowedNotional / float64(d.Leverage)
+		// Margin at which a stop-out loses exactly maxRiskUSD (decimal, so the adjusted size has no float noise)
+		stopDistance := decimal.NewFromFloat(currentPrice).Sub(decimal.NewFromFloat(d.StopLoss)).Abs()
+		allowedMargin, _ := decimal.NewFromFloat(maxRiskUSD).
+			Mul(decimal.NewFromFloat(currentPrice)).
+			Div(stopDistance).
+			Div(decimal.NewFromInt(int64(d.Leverage))).
+			RoundFloor(8).Float64()
 
 		if allowedMargin < minMargin {
 			return fmt.Errorf("risk cap %.2f USDT + stop %.4f allow max %.2f USDT margin (min required %.2f) – tighten stop or reduce leverage",
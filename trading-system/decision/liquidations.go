@@ -0,0 +1,77 @@
+package decision
+
+import (
+	"fmt"
+	"lia/market"
+	"strings"
+)
+
+// writeLiquidations market-wide liquidations and the recent large ones
+func writeLiquidations(sb *strings.Builder, ctx *Context) {
+	if ctx.MarketLiquidations == nil && len(ctx.LargeLiquidations) == 0 {
+		return
+	}
+	sb.WriteString("## 💥 Liquidations (Binance futures)\n\n")
+	if s := ctx.MarketLiquidations; s != nil {
+		sb.WriteString(fmt.Sprintf("**All markets, last %dm**: longs %s, shorts %s (%d orders) | last %dm: longs %s, shorts %s%s\n\n",
+			s.WindowMinutes, formatUSD(s.LongUSD), formatUSD(s.ShortUSD), s.Count,
+			int(market.RecentLiquidationWindow.Minutes()), formatUSD(s.RecentLongUSD), formatUSD(s.RecentShortUSD), formatCascade(s.Cascade)))
+	}
+	if len(ctx.LargeLiquidations) > 0 {
+		sb.WriteString("**Large liquidations** (newest first):\n")
+		for _, e := range ctx.LargeLiquidations {
+			sb.WriteString(fmt.Sprintf("- [%s ago] %s %s liquidated: %s at %.4f\n",
+				formatNewsAge(int(now().Sub(e.Time).Minutes())), e.Symbol, e.Side, formatUSD(e.USD), e.Price))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Cascading long liquidations push price down (forced selling), short liquidations push it up. Binance reports at most one liquidation per symbol per second, so totals are lower bounds.\n\n")
+}
+
+// formatLiquidations a coin's liquidation line, weighted by its open interest value ("" = none in the window)
+func formatLiquidations(ctx *Context, symbol string) string {
+	s := ctx.Liquidations[symbol]
+	if s == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Liquidations (last %dm): longs %s, shorts %s (%d orders)",
+		s.WindowMinutes, formatUSD(s.LongUSD), formatUSD(s.ShortUSD), s.Count))
+	if data, ok := ctx.MarketDataMap[symbol]; ok && data.OpenInterest != nil && data.OpenInterest.Latest > 0 && data.CurrentPrice > 0 {
+		oiValue := data.OpenInterest.Latest * data.CurrentPrice
+		sb.WriteString(fmt.Sprintf(" = %.2f%% of open interest (%s)", (s.LongUSD+s.ShortUSD)/oiValue*100, formatUSD(oiValue)))
+	}
+	sb.WriteString(fmt.Sprintf(" | last %dm: longs %s, shorts %s%s\n\n",
+		int(market.RecentLiquidationWindow.Minutes()), formatUSD(s.RecentLongUSD), formatUSD(s.RecentShortUSD), formatCascade(s.Cascade)))
+	if len(s.Clusters) > 0 {
+		sb.WriteString("Liquidation clusters:")
+		for i, c := range s.Clusters {
+			if i > 0 {
+				sb.WriteString(";")
+			}
+			sb.WriteString(fmt.Sprintf(" %.4f-%.4f longs %s shorts %s", c.PriceLow, c.PriceHigh, formatUSD(c.LongUSD), formatUSD(c.ShortUSD)))
+		}
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// formatCascade the cascade marker of a summary
+func formatCascade(side string) string {
+	if side == "" {
+		return ""
+	}
+	return fmt.Sprintf(" ⚠️ %s liquidation cascade forming", side)
+}
+
+// formatUSD "$850", "$12.3K", "$4.56M"
+func formatUSD(value float64) string {
+	switch {
+	case value >= 1_000_000:
+		return fmt.Sprintf("$%.2fM", value/1_000_000)
+	case value >= 1_000:
+		return fmt.Sprintf("$%.1fK", value/1_000)
+	default:
+		return fmt.Sprintf("$%.0f", value)
+	}
+}
@@ -0,0 +1,61 @@
+package decision
+
+import (
+	"lia/market"
+	"sort"
+	"sync"
+)
+
+// marketFetchConcurrency market data requests in flight at once while a context is built (1 = one symbol after
+// the other, the default)
+var marketFetchConcurrency = 1
+
+// marketDataOverridden whether SetMarketDataSource replaced live market data (overrides are fetched serially)
+var marketDataOverridden bool
+
+// SetMarketFetchConcurrency sets how many symbols a cycle fetches at once (<= 1 = serially). Traders asking for
+// the same symbol at the same time still share one fetch (see market.Get)
+func SetMarketFetchConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	marketFetchConcurrency = n
+}
+
+// fetchSymbolData the market data of symbols (failed symbols are left out)
+func fetchSymbolData(symbols []string) map[string]*market.Data {
+	sort.Strings(symbols)
+	result := make(map[string]*market.Data, len(symbols))
+	concurrency := marketFetchConcurrency
+	if concurrency <= 1 || marketDataOverridden {
+		for _, symbol := range symbols {
+			if data, err := marketDataSource(symbol); err == nil {
+				result[symbol] = data
+			}
+		}
+		return result
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(symbol string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			data, err := marketDataSource(symbol)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			result[symbol] = data
+			mu.Unlock()
+		}(symbol)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,65 @@
+package decision
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TradeMemory retrieves the past trades most relevant to this cycle's candidates and positions
+// Set on the context by the trader when trade memory is enabled; called once market data is loaded
+type TradeMemory interface {
+	// Retrieve returns the relevant episodes and the number of trades in the index
+	Retrieve(ctx *Context) (episodes []MemoryEpisode, indexed int)
+}
+
+// MemoryEpisode a past trade retrieved from the trader's memory
+type MemoryEpisode struct {
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Regime     string  `json:"regime,omitempty"` // Market regime at entry ("" if not captured)
+	OpenPrice  float64 `json:"open_price"`
+	ClosePrice float64 `json:"close_price"`
+	PnL        float64 `json:"pnl"`
+	PnLPct     float64 `json:"pnl_pct"`
+	Duration   string  `json:"duration"`
+	ClosedAt   string  `json:"closed_at"`
+	MatchedFor string  `json:"matched_for"` // Current candidate/position this episode was retrieved for
+	Similarity float64 `json:"similarity"`  // Cosine similarity to the current setup (0-1)
+}
+
+// retrieveRelevantTrades fills RelevantTrades from the trade memory (requires market data)
+func retrieveRelevantTrades(ctx *Context) {
+	if ctx.Memory == nil {
+		return
+	}
+	ctx.RelevantTrades, ctx.MemorySize = ctx.Memory.Retrieve(ctx)
+}
+
+// writeRelevantTrades renders retrieved episodes in place of the verbatim recent trade list
+func writeRelevantTrades(sb *strings.Builder, ctx *Context) {
+	if len(ctx.RelevantTrades) == 0 {
+		sb.WriteString(fmt.Sprintf("**🧠 Relevant Past Trades**: none of your %d indexed trades resemble the current candidates or positions - no direct precedent, rely on current signals\n\n",
+			ctx.MemorySize))
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("**🧠 Relevant Past Trades** (%d of %d indexed trades, retrieved by symbol and market regime similarity):\n",
+		len(ctx.RelevantTrades), ctx.MemorySize))
+	for i, ep := range ctx.RelevantTrades {
+		outcome := "✅ WIN"
+		sign := "+"
+		if ep.PnL <= 0 {
+			outcome = "❌ LOSS"
+			sign = ""
+		}
+		regime := ep.Regime
+		if regime == "" {
+			regime = "not recorded"
+		}
+		sb.WriteString(fmt.Sprintf("  %d. %s %s %s | Entry regime: %s | Entry: %.4f → Exit: %.4f | P&L: %s%.2f USDT (%s%.2f%%) | Duration: %s | Closed: %s\n",
+			i+1, ep.Symbol, strings.ToUpper(ep.Side), outcome, regime,
+			ep.OpenPrice, ep.ClosePrice, sign, ep.PnL, sign, ep.PnLPct, ep.Duration, ep.ClosedAt))
+		sb.WriteString(fmt.Sprintf("     ↳ similar to current %s (similarity %.2f)\n", ep.MatchedFor, ep.Similarity))
+	}
+	sb.WriteString("  💡 **Key Questions**: Does the current setup repeat a losing pattern above? Do the conditions of the winners apply now?\n\n")
+}
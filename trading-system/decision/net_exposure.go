@@ -0,0 +1,89 @@
+package decision
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NetExposure a symbol's positions netted: a long and a short on the same symbol (hedge mode) offset each other
+type NetExposure struct {
+	Symbol         string  `json:"symbol"`
+	LongQuantity   float64 `json:"long_quantity"`
+	ShortQuantity  float64 `json:"short_quantity"`
+	NetQuantity    float64 `json:"net_quantity"`   // Long minus short (> 0 = net long)
+	NetNotional    float64 `json:"net_notional"`   // Net delta in USDT at the mark price (> 0 = net long)
+	GrossNotional  float64 `json:"gross_notional"` // Both sides together, in USDT
+	CombinedMargin float64 `json:"combined_margin"`
+	UnrealizedPnL  float64 `json:"unrealized_pnl"` // Both sides together
+	Direction      string  `json:"direction"`      // "long", "short" or "flat" (the sides fully offset)
+	Hedged         bool    `json:"hedged"`         // Holds both a long and a short
+}
+
+// NetExposures the net exposure of each symbol with positions, in the order the symbols first appear
+func NetExposures(positions []PositionInfo) []NetExposure {
+	var exposures []NetExposure
+	index := make(map[string]int)
+	for _, pos := range positions {
+		i, ok := index[pos.Symbol]
+		if !ok {
+			i = len(exposures)
+			index[pos.Symbol] = i
+			exposures = append(exposures, NetExposure{Symbol: pos.Symbol})
+		}
+		e := &exposures[i]
+		quantity := math.Abs(pos.Quantity)
+		notional := quantity * pos.MarkPrice
+		if pos.Side == "short" {
+			e.ShortQuantity += quantity
+			e.NetQuantity -= quantity
+			e.NetNotional -= notional
+		} else {
+			e.LongQuantity += quantity
+			e.NetQuantity += quantity
+			e.NetNotional += notional
+		}
+		e.GrossNotional += notional
+		e.CombinedMargin += pos.MarginUsed
+		e.UnrealizedPnL += pos.UnrealizedPnL
+		e.Hedged = e.LongQuantity > 0 && e.ShortQuantity > 0
+	}
+	for i := range exposures {
+		switch e := &exposures[i]; {
+		case e.NetQuantity > 0:
+			e.Direction = "long"
+		case e.NetQuantity < 0:
+			e.Direction = "short"
+		default:
+			e.Direction = "flat"
+		}
+	}
+	return exposures
+}
+
+// writeNetExposure the net exposure of the hedged symbols (nothing when no symbol holds both sides)
+func writeNetExposure(sb *strings.Builder, ctx *Context) {
+	var hedged []NetExposure
+	for _, e := range ctx.NetExposure {
+		if e.Hedged {
+			hedged = append(hedged, e)
+		}
+	}
+	if len(hedged) == 0 {
+		return
+	}
+	sb.WriteString("**Net exposure of hedged coins** (a long and a short on one coin offset each other):\n")
+	for _, e := range hedged {
+		sb.WriteString(fmt.Sprintf("- %s: long %.4f / short %.4f → net %s %.4f (delta %+.2f USDT of %.2f gross) | Combined margin %.2f | Combined P&L %+.2f USDT\n",
+			e.Symbol, e.LongQuantity, e.ShortQuantity, e.Direction, math.Abs(e.NetQuantity), e.NetNotional, e.GrossNotional, e.CombinedMargin, e.UnrealizedPnL))
+	}
+	sb.WriteString("\n")
+}
+
+// opposingPosition the open position on symbol opposite to side (nil = none)
+func opposingPosition(ctx *Context, symbol, side string) *PositionInfo {
+	if side == "long" {
+		return findPosition(ctx, symbol, "short")
+	}
+	return findPosition(ctx, symbol, "long")
+}
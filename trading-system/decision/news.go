@@ -0,0 +1,72 @@
+package decision
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewsHeadline a recent headline shown to the AI
+type NewsHeadline struct {
+	Title      string `json:"title"`
+	Source     string `json:"source"`
+	AgeMinutes int    `json:"age_minutes"`         // Minutes since publication at the start of the cycle
+	Sentiment  string `json:"sentiment,omitempty"` // "positive", "negative" or "" (unknown / neutral)
+	Important  bool   `json:"important,omitempty"`
+}
+
+// writeNews market-wide headlines, then the headlines of each position and candidate
+func writeNews(sb *strings.Builder, ctx *Context) {
+	if len(ctx.MarketNews) == 0 && len(ctx.News) == 0 {
+		return
+	}
+	sb.WriteString("## 📰 News\n\n")
+	if len(ctx.MarketNews) > 0 {
+		sb.WriteString("**Market**:\n")
+		writeHeadlines(sb, ctx.MarketNews)
+	}
+
+	seen := make(map[string]bool)
+	symbols := make([]string, 0, len(ctx.Positions)+len(ctx.CandidateCoins))
+	for _, pos := range ctx.Positions {
+		symbols = append(symbols, pos.Symbol)
+	}
+	for _, coin := range ctx.CandidateCoins {
+		symbols = append(symbols, coin.Symbol)
+	}
+	for _, symbol := range symbols {
+		headlines := ctx.News[symbol]
+		if seen[symbol] || len(headlines) == 0 {
+			continue
+		}
+		seen[symbol] = true
+		sb.WriteString(fmt.Sprintf("**%s**:\n", symbol))
+		writeHeadlines(sb, headlines)
+	}
+	sb.WriteString("Headlines are unverified and may already be priced in: weigh them against the price action, and be cautious around hacks, delistings and regulatory news.\n\n")
+}
+
+// writeHeadlines one line per headline: age, sentiment / importance markers, title and source
+func writeHeadlines(sb *strings.Builder, headlines []NewsHeadline) {
+	for _, h := range headlines {
+		marker := ""
+		switch h.Sentiment {
+		case "positive":
+			marker = "🟢 "
+		case "negative":
+			marker = "🔴 "
+		}
+		if h.Important {
+			marker += "❗ "
+		}
+		sb.WriteString(fmt.Sprintf("- [%s ago] %s%s (%s)\n", formatNewsAge(h.AgeMinutes), marker, h.Title, h.Source))
+	}
+	sb.WriteString("\n")
+}
+
+// formatNewsAge "45m" / "3h"
+func formatNewsAge(minutes int) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", max(minutes, 0))
+	}
+	return fmt.Sprintf("%dh", minutes/60)
+}
@@ -0,0 +1,93 @@
+package decision
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Entry order types of open decisions
+const (
+	OrderTypeMarket   = "market"    // Fill now at market (taker fee)
+	OrderTypeLimit    = "limit"     // Rest at limit_price (fills at that price or better)
+	OrderTypePostOnly = "post_only" // Rest at limit_price as a maker order only (rejected if it would fill immediately)
+)
+
+// maxLimitDistancePct how far from the current price a limit entry may rest
+const maxLimitDistancePct = 2.0
+
+// PendingEntry a limit / post_only open resting on the exchange
+type PendingEntry struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"` // "long" or "short"
+	OrderType        string  `json:"order_type"`
+	LimitPrice       float64 `json:"limit_price"`
+	Quantity         float64 `json:"quantity"`
+	AgeMinutes       int     `json:"age_minutes"`
+	ExpiresInMinutes int     `json:"expires_in_minutes"` // Cancelled if still unfilled then
+}
+
+// IsLimitEntry reports whether an open rests as a limit order (limit / post_only) instead of filling at market
+func (d *Decision) IsLimitEntry() bool {
+	return (d.Action == "open_long" || d.Action == "open_short") &&
+		(d.OrderType == OrderTypeLimit || d.OrderType == OrderTypePostOnly)
+}
+
+// normalizeOrderType lower-cases order_type ("" = market) and rejects unknown types
+func normalizeOrderType(d *Decision) error {
+	d.OrderType = strings.ToLower(strings.TrimSpace(d.OrderType))
+	switch d.OrderType {
+	case "", OrderTypeMarket:
+		d.OrderType = ""
+		d.LimitPrice = 0
+		return nil
+	case OrderTypeLimit, OrderTypePostOnly:
+		return nil
+	}
+	return fmt.Errorf("invalid order_type %q (market, limit or post_only)", d.OrderType)
+}
+
+// validateLimitEntry checks the limit_price of a limit / post_only open against its stop, target and the current
+// price: it must lie between stop_loss and take_profit, within maxLimitDistancePct of the price, and a post_only
+// order must not cross the price (below it for longs, above it for shorts)
+func validateLimitEntry(d *Decision, currentPrice float64) error {
+	if d.LimitPrice <= 0 {
+		return fmt.Errorf("order_type %s requires limit_price greater than 0", d.OrderType)
+	}
+	long := d.Action == "open_long"
+	low, high := d.StopLoss, d.TakeProfit
+	if !long {
+		low, high = d.TakeProfit, d.StopLoss
+	}
+	if d.LimitPrice <= low || d.LimitPrice >= high {
+		return fmt.Errorf("limit_price %.4f must be between stop_loss %.4f and take_profit %.4f", d.LimitPrice, d.StopLoss, d.TakeProfit)
+	}
+
+	distancePct := math.Abs(d.LimitPrice-currentPrice) / currentPrice * 100
+	if distancePct > maxLimitDistancePct {
+		return fmt.Errorf("limit_price %.4f is %.2f%% from the current price %.4f (max %.1f%%)",
+			d.LimitPrice, distancePct, currentPrice, maxLimitDistancePct)
+	}
+	if d.OrderType == OrderTypePostOnly {
+		if long && d.LimitPrice >= currentPrice {
+			return fmt.Errorf("post_only limit_price %.4f must be below the current price %.4f for longs (it would fill as a taker)", d.LimitPrice, currentPrice)
+		}
+		if !long && d.LimitPrice <= currentPrice {
+			return fmt.Errorf("post_only limit_price %.4f must be above the current price %.4f for shorts (it would fill as a taker)", d.LimitPrice, currentPrice)
+		}
+	}
+	return nil
+}
+
+// writePendingEntries renders the limit entries still resting on the exchange
+func writePendingEntries(sb *strings.Builder, entries []PendingEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	sb.WriteString("## ⏳ Pending Limit Entries (resting, not positions yet)\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("- %s %s %s @ %.4f, quantity %.4f, placed %d min ago, cancelled in %d min if unfilled\n",
+			e.Symbol, strings.ToUpper(e.Side), e.OrderType, e.LimitPrice, e.Quantity, e.AgeMinutes, e.ExpiresInMinutes))
+	}
+	sb.WriteString("Do not open the same symbol and side again while its entry is pending.\n\n")
+}
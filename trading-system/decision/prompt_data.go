@@ -0,0 +1,151 @@
+package decision
+
+import (
+	"lia/market"
+	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// timeframeFetchConcurrency extra timeframe requests in flight at once
+const timeframeFetchConcurrency = 4
+
+// timeframeSource returns a symbol's extra timeframe data (nil while market data is overridden - no history)
+var timeframeSource = market.GetTimeframe
+
+// fetchTimeframesForContext loads ctx.PromptTimeframes for every coin with market data (a timeframe that fails
+// to load is left out of the prompt)
+func fetchTimeframesForContext(ctx *Context) {
+	ctx.TimeframeDataMap = nil
+	if len(ctx.PromptTimeframes) == 0 || timeframeSource == nil {
+		return
+	}
+	ctx.TimeframeDataMap = make(map[string][]*market.TimeframeData)
+
+	type result struct {
+		index int
+		data  *market.TimeframeData
+	}
+	results := make(map[string][]result)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, timeframeFetchConcurrency)
+	for symbol := range ctx.MarketDataMap {
+		for i, interval := range ctx.PromptTimeframes {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(symbol, interval string, index int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				data, err := timeframeSource(symbol, interval, ctx.TimeframeSeries)
+				if err != nil {
+					log.Printf("⚠️  %s %s data unavailable, left out of the prompt: %v", symbol, interval, err)
+					return
+				}
+				mu.Lock()
+				results[symbol] = append(results[symbol], result{index: index, data: data})
+				mu.Unlock()
+			}(symbol, interval, i)
+		}
+	}
+	wg.Wait()
+
+	// Keep the configured timeframe order
+	for symbol, loaded := range results {
+		ordered := make([]*market.TimeframeData, len(ctx.PromptTimeframes))
+		for _, r := range loaded {
+			ordered[r.index] = r.data
+		}
+		for _, data := range ordered {
+			if data != nil {
+				ctx.TimeframeDataMap[symbol] = append(ctx.TimeframeDataMap[symbol], data)
+			}
+		}
+	}
+}
+
+// formatTimeframes the extra timeframe sections of a coin ("" = none)
+func formatTimeframes(ctx *Context, symbol string) string {
+	var sb strings.Builder
+	for _, data := range ctx.TimeframeDataMap[symbol] {
+		sb.WriteString(market.FormatTimeframe(data))
+	}
+	return sb.String()
+}
+
+// estimatePromptTokens rough token count of a prompt (~4 characters per token)
+func estimatePromptTokens(prompt string) int {
+	return utf8.RuneCountInString(prompt) / 4
+}
+
+// buildBudgetedUserPrompt builds the user prompt within ctx.MaxPromptTokens. Over budget, it drops in order:
+// the extra timeframes of candidates (positions keep theirs), candidates beyond the compact prompt's, the
+// history, memory, pool and per-symbol news sections, then the remaining candidates from the bottom of the list
+func buildBudgetedUserPrompt(ctx *Context) string {
+	prompt := buildUserPrompt(ctx)
+	budget := ctx.MaxPromptTokens
+	full := estimatePromptTokens(prompt)
+	if budget <= 0 || full <= budget {
+		return prompt
+	}
+
+	var dropped []string
+	fits := func(c *Context, step string) bool {
+		prompt = buildUserPrompt(c)
+		if len(dropped) == 0 || dropped[len(dropped)-1] != step {
+			dropped = append(dropped, step)
+		}
+		return estimatePromptTokens(prompt) <= budget
+	}
+	done := func() string {
+		tokens := estimatePromptTokens(prompt)
+		if tokens > budget {
+			log.Printf("⚠️  Prompt still ~%d tokens after trimming to max_prompt_tokens %d (dropped %s)", tokens, budget, strings.Join(dropped, ", "))
+		} else {
+			log.Printf("✂️  Prompt trimmed to max_prompt_tokens %d: ~%d → ~%d tokens (dropped %s)", budget, full, tokens, strings.Join(dropped, ", "))
+		}
+		return prompt
+	}
+
+	trimmed := *ctx
+
+	// 1. Extra timeframes of candidates
+	if len(ctx.TimeframeDataMap) > 0 {
+		positionTimeframes := make(map[string][]*market.TimeframeData)
+		for _, pos := range ctx.Positions {
+			if data, ok := ctx.TimeframeDataMap[pos.Symbol]; ok {
+				positionTimeframes[pos.Symbol] = data
+			}
+		}
+		trimmed.TimeframeDataMap = positionTimeframes
+		if fits(&trimmed, "candidate timeframes") {
+			return done()
+		}
+	}
+
+	// 2. Candidates beyond the compact prompt's, lowest ranked first
+	for len(trimmed.CandidateCoins) > compactMaxCandidates {
+		trimmed.CandidateCoins = trimmed.CandidateCoins[:len(trimmed.CandidateCoins)-1]
+		if fits(&trimmed, "low-ranked candidates") {
+			return done()
+		}
+	}
+
+	// 3. History, memory, pool and rejected-trade sections
+	compact := compactContext(&trimmed)
+	if fits(compact, "history sections") {
+		return done()
+	}
+
+	// 4. Remaining candidates
+	for len(compact.CandidateCoins) > 0 {
+		compact.CandidateCoins = compact.CandidateCoins[:len(compact.CandidateCoins)-1]
+		if fits(compact, "top candidates") {
+			return done()
+		}
+	}
+	return done()
+}
@@ -0,0 +1,301 @@
+package decision
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Prompt template files of a template directory (a missing file falls back to the built-in one)
+const (
+	SystemPromptFile = "system.tmpl"
+	UserPromptFile   = "user.tmpl"
+)
+
+// DefaultPromptTemplateName name of the built-in templates (decision/prompts/default)
+const DefaultPromptTemplateName = "default"
+
+//go:embed prompts/default/system.tmpl prompts/default/user.tmpl
+var defaultPromptFiles embed.FS
+
+// PromptTemplate the Go text/template sources a trader's system and user prompts are rendered from
+type PromptTemplate struct {
+	Name         string // Template directory name ("default" = built-in)
+	Version      string // Short SHA-256 of both sources: the same text always has the same version
+	SystemSource string
+	UserSource   string
+
+	system *template.Template
+	user   *template.Template
+}
+
+// SystemPromptData fields available to system.tmpl
+type SystemPromptData struct {
+	Equity            float64
+	BTCETHLeverage    int
+	AltcoinLeverage   int
+	MaxLeverage       int // Higher of the two leverage limits
+	MinConfidence     int
+	HonorStops        bool // Opens place a stop order at their stop_loss
+	ProhibitHedging   bool // A coin may not hold a long and a short at once
+	Structured        bool // The AI answers with one {"reasoning", "decisions"} object
+	LimitEntryTimeout int  // Minutes before unfilled limit entries are cancelled (0 = market entries only)
+
+	MaxRiskPct          float64 // Max loss per trade, % of equity
+	MaxRiskUSD          float64 // Max loss per trade, USDT
+	MaxAddMarginUSD     float64 // Max margin one add_margin decision may add
+	MaxLimitDistancePct float64 // How far from the current price a limit entry may rest
+
+	RiskPolicy *RiskPolicy // Limits the decisions are validated against (min reward/risk, margins, max positions)
+}
+
+// Of fraction of the account equity (e.g. {{usd (.Of 0.20)}} = 20% of equity in whole USDT)
+func (d SystemPromptData) Of(fraction float64) float64 {
+	return d.Equity * fraction
+}
+
+// OfPct percentage of the account equity (e.g. {{usd (.OfPct .RiskPolicy.MinMarginPctAltcoin)}})
+func (d SystemPromptData) OfPct(pct float64) float64 {
+	return d.Equity * pct / 100
+}
+
+// UserPromptData fields available to user.tmpl: each section pre-rendered (empty when it has nothing to show)
+// and the full decision context for templates that render their own
+type UserPromptData struct {
+	Ctx *Context
+
+	Status          string // Time, cycle number and runtime
+	BTC             string // BTC price with crash / bull / downtrend warnings
+	Breadth         string // Market breadth over the candidate pool
+	Account         string // Equity, P&L split and the risk guardrail
+	Positions       string // Open positions with full market data
+	PendingEntries  string // Limit entries resting on the exchange
+	MarketRegime    string // Market-wide context derived from BTC
+	News            string // Recent market-wide headlines and those of the positions and candidates
+	Liquidations    string // Market-wide liquidation totals and the recent large liquidations
+	SymbolThrottles string // Symbols saturated across traders
+	Candidates      string // Candidate coins with full market data
+	Performance     string // Historical performance and trades to learn from
+	RejectedTrades  string // Simulated outcome of decisions rejected by validation
+	Confidence      string // Confidence calibration of opens
+	OutputFormat    string // Required output format reminder
+}
+
+// promptTemplateFuncs helpers available to both templates
+var promptTemplateFuncs = template.FuncMap{
+	"usd": func(v float64) string { return fmt.Sprintf("%.0f", v) },
+	"mul": func(v float64, n int) float64 { return v * float64(n) },
+	"div": func(a, b float64) float64 { return a / b },
+}
+
+var (
+	defaultPromptTemplate     *PromptTemplate
+	defaultPromptTemplateOnce sync.Once
+)
+
+// DefaultPromptTemplate the built-in templates
+func DefaultPromptTemplate() *PromptTemplate {
+	defaultPromptTemplateOnce.Do(func() {
+		system, err := defaultPromptFiles.ReadFile("prompts/default/" + SystemPromptFile)
+		if err != nil {
+			panic(err)
+		}
+		user, err := defaultPromptFiles.ReadFile("prompts/default/" + UserPromptFile)
+		if err != nil {
+			panic(err)
+		}
+		defaultPromptTemplate, err = NewPromptTemplate(DefaultPromptTemplateName, string(system), string(user))
+		if err != nil {
+			panic(fmt.Sprintf("built-in prompt template: %v", err))
+		}
+	})
+	return defaultPromptTemplate
+}
+
+// NewPromptTemplate parses the system and user template sources and checks that both render
+func NewPromptTemplate(name, systemSource, userSource string) (*PromptTemplate, error) {
+	system, err := template.New(SystemPromptFile).Funcs(promptTemplateFuncs).Parse(systemSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", SystemPromptFile, err)
+	}
+	user, err := template.New(UserPromptFile).Funcs(promptTemplateFuncs).Parse(userSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", UserPromptFile, err)
+	}
+	t := &PromptTemplate{
+		Name:         name,
+		Version:      PromptVersion(systemSource, userSource),
+		SystemSource: systemSource,
+		UserSource:   userSource,
+		system:       system,
+		user:         user,
+	}
+
+	// Unknown fields and wrong function arguments only fail at execution - render a sample context now
+	sample := &Context{Account: AccountInfo{TotalEquity: 1000}, BTCETHLeverage: 5, AltcoinLeverage: 5}
+	if _, err := t.renderSystem(systemPromptData(sample)); err != nil {
+		return nil, err
+	}
+	if _, err := t.renderUser(sample); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// LoadPromptTemplate loads system.tmpl and user.tmpl from dir; a missing file uses the built-in template
+func LoadPromptTemplate(dir string) (*PromptTemplate, error) {
+	if dir == "" {
+		return DefaultPromptTemplate(), nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("prompt template directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("prompt template '%s' is not a directory", dir)
+	}
+
+	def := DefaultPromptTemplate()
+	sources := []struct {
+		file   string
+		source string
+	}{{SystemPromptFile, def.SystemSource}, {UserPromptFile, def.UserSource}}
+	found := 0
+	for i := range sources {
+		data, err := os.ReadFile(filepath.Join(dir, sources[i].file))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		sources[i].source = string(data)
+		found++
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("prompt template '%s' has neither %s nor %s", dir, SystemPromptFile, UserPromptFile)
+	}
+
+	t, err := NewPromptTemplate(filepath.Base(filepath.Clean(dir)), sources[0].source, sources[1].source)
+	if err != nil {
+		return nil, fmt.Errorf("prompt template '%s': %w", dir, err)
+	}
+	return t, nil
+}
+
+// PromptVersion short SHA-256 identifying a pair of template sources
+func PromptVersion(systemSource, userSource string) string {
+	h := sha256.New()
+	h.Write([]byte(systemSource))
+	h.Write([]byte{0})
+	h.Write([]byte(userSource))
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// renderSystem executes system.tmpl
+func (t *PromptTemplate) renderSystem(data SystemPromptData) (string, error) {
+	var sb strings.Builder
+	if err := t.system.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", SystemPromptFile, err)
+	}
+	return sb.String(), nil
+}
+
+// renderUser renders the user prompt sections and executes user.tmpl
+func (t *PromptTemplate) renderUser(ctx *Context) (string, error) {
+	var sb strings.Builder
+	if err := t.user.Execute(&sb, userPromptData(ctx)); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", UserPromptFile, err)
+	}
+	return sb.String(), nil
+}
+
+// promptTemplate the context's template (the built-in one when none is set)
+func (ctx *Context) promptTemplate() *PromptTemplate {
+	if ctx.PromptTemplate != nil {
+		return ctx.PromptTemplate
+	}
+	return DefaultPromptTemplate()
+}
+
+// systemPromptData the system template fields for ctx
+func systemPromptData(ctx *Context) SystemPromptData {
+	equity := ctx.Account.TotalEquity
+	policy := ctx.riskPolicy()
+	return SystemPromptData{
+		Equity:              equity,
+		BTCETHLeverage:      ctx.BTCETHLeverage,
+		AltcoinLeverage:     ctx.AltcoinLeverage,
+		MaxLeverage:         max(ctx.BTCETHLeverage, ctx.AltcoinLeverage),
+		MinConfidence:       minConfidence(ctx),
+		HonorStops:          ctx.HonorStops,
+		ProhibitHedging:     ctx.ProhibitHedging,
+		Structured:          ctx.StructuredOutput,
+		LimitEntryTimeout:   ctx.LimitEntryTimeoutMinutes,
+		MaxRiskPct:          policy.MaxRiskPerTradePct,
+		MaxRiskUSD:          policy.MaxRiskUSD(equity),
+		MaxAddMarginUSD:     policy.MaxAddMarginUSD(equity),
+		MaxLimitDistancePct: maxLimitDistancePct,
+		RiskPolicy:          policy,
+	}
+}
+
+// userPromptData renders each section of the user prompt for ctx
+func userPromptData(ctx *Context) UserPromptData {
+	section := func(write func(sb *strings.Builder)) string {
+		var sb strings.Builder
+		write(&sb)
+		return sb.String()
+	}
+	return UserPromptData{
+		Ctx:             ctx,
+		Status:          section(func(sb *strings.Builder) { writeStatus(sb, ctx) }),
+		BTC:             section(func(sb *strings.Builder) { writeBTCOverview(sb, ctx) }),
+		Breadth:         section(func(sb *strings.Builder) { writeMarketBreadth(sb, ctx.Breadth) }),
+		Account:         section(func(sb *strings.Builder) { writeAccount(sb, ctx) }),
+		Positions:       section(func(sb *strings.Builder) { writePositions(sb, ctx) }),
+		PendingEntries:  section(func(sb *strings.Builder) { writePendingEntries(sb, ctx.PendingEntries) }),
+		MarketRegime:    section(func(sb *strings.Builder) { writeMarketRegime(sb, ctx) }),
+		News:            section(func(sb *strings.Builder) { writeNews(sb, ctx) }),
+		Liquidations:    section(func(sb *strings.Builder) { writeLiquidations(sb, ctx) }),
+		SymbolThrottles: section(func(sb *strings.Builder) { writeSymbolThrottles(sb, ctx) }),
+		Candidates:      section(func(sb *strings.Builder) { writeCandidates(sb, ctx) }),
+		Performance:     section(func(sb *strings.Builder) { writePerformance(sb, ctx) }),
+		RejectedTrades:  section(func(sb *strings.Builder) { writeRejectedTrades(sb, ctx.RejectedTrades) }),
+		Confidence:      section(func(sb *strings.Builder) { writeConfidenceCalibration(sb, ctx.ConfidenceThreshold) }),
+		OutputFormat:    section(func(sb *strings.Builder) { writeOutputFormat(sb, ctx) }),
+	}
+}
+
+// buildSystemPrompt builds the system prompt (fixed rules) from the context's template, falling back to the
+// built-in template when it fails to render
+func buildSystemPrompt(ctx *Context) string {
+	data := systemPromptData(ctx)
+	t := ctx.promptTemplate()
+	prompt, err := t.renderSystem(data)
+	if err != nil {
+		log.Printf("⚠️  Prompt template %s (%s): %v - using the built-in system prompt", t.Name, t.Version, err)
+		prompt, _ = DefaultPromptTemplate().renderSystem(data)
+	}
+	return prompt
+}
+
+// buildUserPrompt builds the user prompt (dynamic data) from the context's template, falling back to the
+// built-in template when it fails to render
+func buildUserPrompt(ctx *Context) string {
+	t := ctx.promptTemplate()
+	prompt, err := t.renderUser(ctx)
+	if err != nil {
+		log.Printf("⚠️  Prompt template %s (%s): %v - using the built-in user prompt", t.Name, t.Version, err)
+		prompt, _ = DefaultPromptTemplate().renderUser(ctx)
+	}
+	return prompt + ctx.CommitteeBriefing
+}
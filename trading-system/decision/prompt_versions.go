@@ -0,0 +1,181 @@
+package decision
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PromptVersionRecord a stored snapshot of a prompt template version, so a DecisionRecord's prompt_version can be
+// traced back to the exact template text
+type PromptVersionRecord struct {
+	Version   string    `json:"version"`
+	Name      string    `json:"name"`
+	FirstSeen time.Time `json:"first_seen"`
+	System    string    `json:"system"`
+	User      string    `json:"user"`
+}
+
+// promptVersionPattern valid version IDs (also keeps API input out of other paths)
+var promptVersionPattern = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// SavePromptVersion stores the template's sources as <dir>/<version>.json (kept as-is when already stored)
+func SavePromptVersion(dir string, t *PromptTemplate) error {
+	path := filepath.Join(dir, t.Version+".json")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create prompt versions directory: %w", err)
+	}
+	data, err := json.MarshalIndent(PromptVersionRecord{
+		Version:   t.Version,
+		Name:      t.Name,
+		FirstSeen: time.Now().UTC(),
+		System:    t.SystemSource,
+		User:      t.UserSource,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save prompt version: %w", err)
+	}
+	return nil
+}
+
+// LoadPromptVersion reads a stored version
+func LoadPromptVersion(dir, version string) (*PromptVersionRecord, error) {
+	if !promptVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid prompt version '%s'", version)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, version+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("prompt version '%s' not found", version)
+		}
+		return nil, err
+	}
+	var record PromptVersionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt version '%s': %w", version, err)
+	}
+	return &record, nil
+}
+
+// ListPromptVersions every stored version, oldest first (none when the directory does not exist yet)
+func ListPromptVersions(dir string) ([]*PromptVersionRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []*PromptVersionRecord
+	for _, e := range entries {
+		version, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok || !promptVersionPattern.MatchString(version) {
+			continue
+		}
+		record, err := LoadPromptVersion(dir, version)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].FirstSeen.Before(records[j].FirstSeen) })
+	return records, nil
+}
+
+// diffContextLines unchanged lines shown around each change
+const diffContextLines = 3
+
+// DiffPromptText a unified diff (without file headers) between two template sources ("" = identical)
+func DiffPromptText(from, to string) string {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	// Longest common subsequence table, then walk it into a line script
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	type line struct {
+		op           byte // ' ', '-' or '+'
+		text         string
+		aLine, bLine int // 1-based line numbers before / after
+	}
+	var script []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			script = append(script, line{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			script = append(script, line{'-', a[i], i + 1, j + 1})
+			i++
+		default:
+			script = append(script, line{'+', b[j], i + 1, j + 1})
+			j++
+		}
+	}
+
+	// Group changes with their context into hunks
+	var sb strings.Builder
+	for start := 0; start < len(script); {
+		if script[start].op == ' ' {
+			start++
+			continue
+		}
+		first := max(0, start-diffContextLines)
+		end := start
+		for end < len(script) {
+			if script[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(script) && script[next].op == ' ' {
+				next++
+			}
+			if next == len(script) || next-end > 2*diffContextLines {
+				end = min(end+diffContextLines, len(script))
+				break
+			}
+			end = next
+		}
+		aCount, bCount := 0, 0
+		for _, l := range script[first:end] {
+			if l.op != '+' {
+				aCount++
+			}
+			if l.op != '-' {
+				bCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", script[first].aLine, aCount, script[first].bLine, bCount))
+		for _, l := range script[first:end] {
+			sb.WriteString(fmt.Sprintf("%c%s\n", l.op, l.text))
+		}
+		start = end
+	}
+	return sb.String()
+}
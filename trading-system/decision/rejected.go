@@ -0,0 +1,91 @@
+package decision
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rejection categories (why validation rejected a decision)
+const (
+	RejectRiskReward   = "risk_reward"   // Risk-reward ratio below 3:1
+	RejectStopDistance = "stop_distance" // Stop loss too wide or on the wrong side of price
+	RejectSizing       = "sizing"        // Margin above the cap, or risk cap leaves less than the minimum margin
+	RejectLeverage     = "leverage"      // Leverage outside the configured limit
+	RejectInvalid      = "invalid"       // Missing/inconsistent parameters, unknown action
+	RejectConfidence   = "confidence"    // Open below the trader's adaptive confidence threshold
+)
+
+// RejectedDecision a decision removed by validation (not executed)
+type RejectedDecision struct {
+	Decision Decision `json:"decision"`
+	Reason   string   `json:"reason"`
+	Category string   `json:"category"`
+}
+
+// RejectedTradeSummary aggregate simulated outcome of rejected open decisions (fed back to the AI)
+type RejectedTradeSummary struct {
+	WindowDays int                     `json:"window_days"`
+	Rejected   int                     `json:"rejected"` // Rejected opens in the window
+	Pending    int                     `json:"pending"`  // Simulation still running (neither stop nor target hit yet)
+	Resolved   int                     `json:"resolved"`
+	TakeProfit int                     `json:"take_profit"` // Would have hit take profit
+	StopLoss   int                     `json:"stop_loss"`   // Would have hit stop loss
+	Expired    int                     `json:"expired"`     // Neither hit within the horizon (closed at market)
+	TotalPnL   float64                 `json:"total_pnl"`   // Hypothetical P&L of resolved simulations (USDT, before fees)
+	ByCategory []RejectedCategoryStats `json:"by_category"`
+}
+
+// RejectedCategoryStats simulated outcome of rejected opens for one rejection category
+type RejectedCategoryStats struct {
+	Category string  `json:"category"`
+	Rejected int     `json:"rejected"`
+	Resolved int     `json:"resolved"`
+	Wins     int     `json:"wins"`
+	PnL      float64 `json:"pnl"`
+}
+
+// rejectionCategory classifies a validation error
+func rejectionCategory(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "risk-reward"):
+		return RejectRiskReward
+	case strings.Contains(msg, "stop loss distance") || strings.Contains(msg, "correct side of current price"):
+		return RejectStopDistance
+	case strings.Contains(msg, "margin") || strings.Contains(msg, "risk cap"):
+		return RejectSizing
+	case strings.Contains(msg, "leverage must be"):
+		return RejectLeverage
+	}
+	return RejectInvalid
+}
+
+// writeRejectedTrades renders the simulated outcome of rejected open decisions
+func writeRejectedTrades(sb *strings.Builder, summary *RejectedTradeSummary) {
+	if summary == nil || summary.Resolved == 0 {
+		return
+	}
+
+	sign := ""
+	if summary.TotalPnL > 0 {
+		sign = "+"
+	}
+	sb.WriteString(fmt.Sprintf("**🚫 Rejected Trades (last %d days)**: %d of your open decisions were rejected by validation and NOT executed. %d simulated to completion: %d would have hit take profit, %d stop loss, %d expired → your rejected trades would have made %s%.2f USDT (before fees)",
+		summary.WindowDays, summary.Rejected, summary.Resolved, summary.TakeProfit, summary.StopLoss, summary.Expired, sign, summary.TotalPnL))
+	if summary.Pending > 0 {
+		sb.WriteString(fmt.Sprintf(", %d still open", summary.Pending))
+	}
+	sb.WriteString("\n")
+	for _, c := range summary.ByCategory {
+		if c.Resolved == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  • %s: %d rejected, %d/%d simulated winners, %+.2f USDT\n",
+			c.Category, c.Rejected, c.Wins, c.Resolved, c.PnL))
+	}
+	if summary.TotalPnL < 0 {
+		sb.WriteString("  💡 Validation saved you money: the rejected setups were losers - don't try to work around the rules.\n\n")
+	} else {
+		sb.WriteString("  💡 The rejected setups were profitable: keep the thesis but fix the parameters (tighter stop, ≥3:1 target, smaller size) so they pass validation.\n\n")
+	}
+}
@@ -0,0 +1,80 @@
+package decision
+
+import (
+	"lia/market"
+	"lia/pool"
+	"time"
+)
+
+// priceSource returns the current price used by validation (live market data by default)
+var priceSource = defaultPriceSource
+
+// defaultPriceSource fetches the live price from Binance futures market data
+func defaultPriceSource(symbol string) (float64, error) {
+	data, err := market.Get(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return data.CurrentPrice, nil
+}
+
+// SetPriceSource overrides the price lookup used by decision validation (nil restores live market data)
+// Used by the fixture replay harness so recorded responses validate against recorded prices
+func SetPriceSource(fn func(symbol string) (float64, error)) {
+	if fn == nil {
+		priceSource = defaultPriceSource
+		return
+	}
+	priceSource = fn
+}
+
+// marketDataSource returns a symbol's market data for the context (live, cached Binance data by default)
+var marketDataSource = market.Get
+
+// oiTopSource returns the OI Top ranking (nil when market data is overridden - no historical rankings)
+var oiTopSource = pool.GetOITopPositions
+
+// SetMarketDataSource overrides the market data loaded into trading contexts (nil restores live market data)
+// Used by the candle backtester to feed historical candles through the same context and strategy path;
+// OI Top rankings and extra prompt timeframes are skipped while overridden
+func SetMarketDataSource(fn func(symbol string) (*market.Data, error)) {
+	if fn == nil {
+		marketDataSource = market.Get
+		oiTopSource = pool.GetOITopPositions
+		timeframeSource = market.GetTimeframe
+		marketDataOverridden = false
+		return
+	}
+	marketDataSource = fn
+	marketDataOverridden = true
+	oiTopSource = nil
+	timeframeSource = nil
+}
+
+// now returns the current time (simulated time during backtests)
+var now = time.Now
+
+// SetClock overrides the time used for position holding durations and OI samples (nil restores the wall clock)
+func SetClock(fn func() time.Time) {
+	if fn == nil {
+		now = time.Now
+		return
+	}
+	now = fn
+}
+
+// BuildPrompts builds the system and user prompts for a context without calling the AI
+// ctx.MarketDataMap must already be populated
+func BuildPrompts(ctx *Context) (systemPrompt, userPrompt string) {
+	systemPrompt = buildSystemPrompt(ctx)
+	userPrompt = buildBudgetedUserPrompt(ctx)
+	return systemPrompt, userPrompt
+}
+
+// ReplayResponse runs a recorded AI response through the parse → validate → size-adjust pipeline
+// Same path as GetFullDecision after the AI call, without fetching market data or calling the AI
+func ReplayResponse(aiResponse string, ctx *Context) (*FullDecision, error) {
+	decision, err := parseFullDecisionResponse(aiResponse, ctx)
+	applyConfidenceThreshold(decision, ctx.ConfidenceThreshold)
+	return decision, err
+}
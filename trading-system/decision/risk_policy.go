@@ -0,0 +1,114 @@
+package decision
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+)
+
+// RiskPolicy the limits validation holds each decision to. Traders can run different policies side by side;
+// each decision records the Label of the policy that validated it
+type RiskPolicy struct {
+	Name                string  `json:"name"`
+	MinRiskReward       float64 `json:"min_risk_reward"`        // Reward/risk an open must plan (entry assumed 20% into the stop-target range)
+	MaxRiskPerTradePct  float64 `json:"max_risk_per_trade_pct"` // Loss of a stopped-out open, % of equity (larger opens are downsized)
+	MinMarginPctBTCETH  float64 `json:"min_margin_pct_btc_eth"` // Smallest open margin, % of equity
+	MinMarginPctAltcoin float64 `json:"min_margin_pct_altcoin"`
+	MaxMarginPctBTCETH  float64 `json:"max_margin_pct_btc_eth"` // Largest open margin, % of equity
+	MaxMarginPctAltcoin float64 `json:"max_margin_pct_altcoin"`
+	MaxStopPctBTCETH    float64 `json:"max_stop_pct_btc_eth"` // Widest stop distance from the entry, % of price
+	MaxStopPctAltcoin   float64 `json:"max_stop_pct_altcoin"`
+	MaxPositions        int     `json:"max_positions"`      // Open positions at once (excess opens are skipped)
+	MaxAddMarginPct     float64 `json:"max_add_margin_pct"` // Margin one add_margin decision may add, % of equity
+}
+
+// DefaultRiskPolicyName name of the built-in policy
+const DefaultRiskPolicyName = "default"
+
+// DefaultRiskPolicy the built-in limits: 1:3 reward/risk, 2% risk per trade, 20-50% margin for BTC/ETH and
+// 15-40% for altcoins, 3% / 5% max stop, 6 positions
+func DefaultRiskPolicy() *RiskPolicy {
+	return &RiskPolicy{
+		Name:                DefaultRiskPolicyName,
+		MinRiskReward:       3,
+		MaxRiskPerTradePct:  2,
+		MinMarginPctBTCETH:  20,
+		MinMarginPctAltcoin: 15,
+		MaxMarginPctBTCETH:  50,
+		MaxMarginPctAltcoin: 40,
+		MaxStopPctBTCETH:    3,
+		MaxStopPctAltcoin:   5,
+		MaxPositions:        6,
+		MaxAddMarginPct:     20,
+	}
+}
+
+// Version short SHA-256 of the limits (the name is left out, so renaming a policy keeps its version)
+func (p *RiskPolicy) Version() string {
+	source := fmt.Sprintf("rr=%g risk=%g minm=%g/%g maxm=%g/%g stop=%g/%g pos=%d add=%g",
+		p.MinRiskReward, p.MaxRiskPerTradePct,
+		p.MinMarginPctBTCETH, p.MinMarginPctAltcoin,
+		p.MaxMarginPctBTCETH, p.MaxMarginPctAltcoin,
+		p.MaxStopPctBTCETH, p.MaxStopPctAltcoin,
+		p.MaxPositions, p.MaxAddMarginPct)
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Label "name@version", the policy identifier stored with each decision
+func (p *RiskPolicy) Label() string {
+	return p.Name + "@" + p.Version()
+}
+
+// MaxRiskUSD largest loss one open may risk
+func (p *RiskPolicy) MaxRiskUSD(accountEquity float64) float64 {
+	return accountEquity * p.MaxRiskPerTradePct / 100
+}
+
+// MaxAddMarginUSD largest margin one add_margin decision may add
+func (p *RiskPolicy) MaxAddMarginUSD(accountEquity float64) float64 {
+	return accountEquity * p.MaxAddMarginPct / 100
+}
+
+// MinOpenMargin smallest margin an open on symbol may use (at least 15 USDT for BTC/ETH, 13 for altcoins)
+func (p *RiskPolicy) MinOpenMargin(symbol string, accountEquity float64) float64 {
+	if isBTCOrETH(symbol) {
+		return math.Max(15, accountEquity*p.MinMarginPctBTCETH/100)
+	}
+	return math.Max(13, accountEquity*p.MinMarginPctAltcoin/100)
+}
+
+// MaxOpenMargin largest margin an open on symbol may use
+func (p *RiskPolicy) MaxOpenMargin(symbol string, accountEquity float64) float64 {
+	if isBTCOrETH(symbol) {
+		return accountEquity * p.MaxMarginPctBTCETH / 100
+	}
+	return accountEquity * p.MaxMarginPctAltcoin / 100
+}
+
+// MaxStopPct widest stop distance of an open on symbol, % of price
+func (p *RiskPolicy) MaxStopPct(symbol string) float64 {
+	if isBTCOrETH(symbol) {
+		return p.MaxStopPctBTCETH
+	}
+	return p.MaxStopPctAltcoin
+}
+
+// riskPolicy the context's policy (the built-in one when none is set)
+func (ctx *Context) riskPolicy() *RiskPolicy {
+	if ctx.RiskPolicy != nil {
+		return ctx.RiskPolicy
+	}
+	return DefaultRiskPolicy()
+}
+
+// MinOpenMargin smallest margin validation accepts for an open on symbol under the context's policy
+func (ctx *Context) MinOpenMargin(symbol string) float64 {
+	return ctx.riskPolicy().MinOpenMargin(symbol, ctx.Account.TotalEquity)
+}
+
+// isBTCOrETH whether symbol gets the BTC/ETH limits
+func isBTCOrETH(symbol string) bool {
+	return symbol == "BTCUSDT" || symbol == "ETHUSDT"
+}
@@ -0,0 +1,84 @@
+package decision
+
+import (
+	"encoding/json"
+	"lia/mcp"
+	"sort"
+	"strings"
+)
+
+// decisionResponseSchemaName function / schema name of the structured decision response
+const decisionResponseSchemaName = "submit_trading_decisions"
+
+// structuredResponse the single JSON object answered in structured output mode
+type structuredResponse struct {
+	Reasoning string     `json:"reasoning"` // Chain of thought analysis
+	Decisions []Decision `json:"decisions"`
+}
+
+// decisionResponseSchema typed JSON schema of structuredResponse (actions from validActions)
+func decisionResponseSchema() *mcp.ResponseSchema {
+	actions := make([]string, 0, len(validActions))
+	for action := range validActions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	number := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "number", "description": description}
+	}
+	decision := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"symbol":            map[string]interface{}{"type": "string", "description": "Coin symbol, e.g. BTCUSDT (ALL for wait)"},
+			"action":            map[string]interface{}{"type": "string", "enum": actions},
+			"side":              map[string]interface{}{"type": "string", "enum": []string{"long", "short"}, "description": "Position side, required for amend actions (add_* derive it from the action)"},
+			"leverage":          map[string]interface{}{"type": "integer", "description": "Leverage of an open"},
+			"position_size_usd": number("Margin in USDT to open (open_*), to buy more with (add_*) or to add (add_margin)"),
+			"stop_loss":         number("Stop loss price"),
+			"take_profit":       number("Take profit price"),
+			"order_type":        map[string]interface{}{"type": "string", "enum": []string{OrderTypeMarket, OrderTypeLimit, OrderTypePostOnly}, "description": "Entry order of an open (default market)"},
+			"limit_price":       number("Price a limit / post_only open rests at"),
+			"reduce_pct":        number("Percent of the position to close (reduce_size)"),
+			"close_pct":         number("Percent of the position to close (close_long/close_short, omit to close all)"),
+			"confidence":        map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 100},
+			"risk_usd":          number("Maximum USD risk"),
+			"reasoning":         map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"symbol", "action", "reasoning"},
+	}
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"reasoning": map[string]interface{}{"type": "string", "description": "Chain of thought analysis, in English"},
+			"decisions": map[string]interface{}{"type": "array", "items": decision, "minItems": 1},
+		},
+		"required": []string{"reasoning", "decisions"},
+	}
+
+	raw, _ := json.Marshal(schema) // Static schema - cannot fail
+	return &mcp.ResponseSchema{
+		Name:        decisionResponseSchemaName,
+		Description: "Submit this cycle's chain of thought and trading decisions",
+		Schema:      raw,
+	}
+}
+
+// parseStructuredResponse parses a structured output response: one {"reasoning", "decisions"} object,
+// optionally in a code fence (false = not one, parse it as free text)
+func parseStructuredResponse(aiResponse string) (*structuredResponse, bool) {
+	text := strings.TrimSpace(aiResponse)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(strings.TrimPrefix(text, "```json"), "```")
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+	}
+	if !strings.HasPrefix(text, "{") {
+		return nil, false
+	}
+
+	var response structuredResponse
+	if err := json.Unmarshal([]byte(text), &response); err != nil || response.Decisions == nil {
+		return nil, false
+	}
+	return &response, true
+}
@@ -0,0 +1,128 @@
+package decision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lia/mcp"
+	"sort"
+	"sync"
+)
+
+// StrategyAI the LLM decision engine (default strategy)
+const StrategyAI = "ai"
+
+// Strategy turns a trading context into a cycle's decisions. The LLM engine is the default; rule-based,
+// indicator-driven or hybrid strategies are registered with RegisterStrategy and selected per trader
+// in config.json ("strategy" / "strategy_params")
+type Strategy interface {
+	// Name the name the strategy is registered under
+	Name() string
+
+	// Decide returns this cycle's decisions (never nil without an error); cancelling reqCtx aborts in-flight work
+	Decide(reqCtx context.Context, ctx *Context) (*FullDecision, error)
+}
+
+// StrategyFactory builds a strategy for one trader from its strategy_params (nil when unset).
+// mcpClient is the trader's configured AI client, for strategies that consult the LLM
+type StrategyFactory func(params json.RawMessage, mcpClient *mcp.Client) (Strategy, error)
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]StrategyFactory{
+		StrategyAI:  newAIStrategy,
+		"ema_trend": newEMATrendStrategy,
+	}
+)
+
+// RegisterStrategy makes a strategy selectable by name in config.json (replaces an existing registration)
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = factory
+}
+
+// NewStrategy builds the named strategy ("" = the AI engine)
+func NewStrategy(name string, params json.RawMessage, mcpClient *mcp.Client) (Strategy, error) {
+	if name == "" {
+		name = StrategyAI
+	}
+	strategiesMu.RLock()
+	factory, ok := strategies[name]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy '%s' (registered: %v)", name, StrategyNames())
+	}
+	strategy, err := factory(params, mcpClient)
+	if err != nil {
+		return nil, fmt.Errorf("strategy '%s': %w", name, err)
+	}
+	return strategy, nil
+}
+
+// StrategyNames registered strategy names, sorted
+func StrategyNames() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// aiStrategy the LLM decision engine
+type aiStrategy struct {
+	client *mcp.Client
+}
+
+// newAIStrategy creates the AI engine strategy (takes no params)
+func newAIStrategy(params json.RawMessage, mcpClient *mcp.Client) (Strategy, error) {
+	if mcpClient == nil {
+		return nil, fmt.Errorf("AI client not configured")
+	}
+	return &aiStrategy{client: mcpClient}, nil
+}
+
+// Name implements Strategy
+func (s *aiStrategy) Name() string {
+	return StrategyAI
+}
+
+// Decide implements Strategy
+func (s *aiStrategy) Decide(reqCtx context.Context, ctx *Context) (*FullDecision, error) {
+	return GetFullDecisionWithContext(reqCtx, ctx, s.client)
+}
+
+// PrepareMarketData loads market data, OI data and market breadth into the context. Strategies that do not go
+// through GetFullDecision call it before reading ctx.MarketDataMap
+func PrepareMarketData(ctx *Context) error {
+	if err := fetchMarketDataForContext(ctx); err != nil {
+		return err
+	}
+	ctx.Breadth = computeBreadth(ctx)
+	return nil
+}
+
+// FinalizeDecisions runs a strategy's decisions through the same validation as AI decisions (leverage limits
+// and the risk policy's margin limits, stop distance and risk cap, confidence threshold). Rejected opens are kept in Rejected; with
+// nothing left to execute the result is a wait decision
+func FinalizeDecisions(ctx *Context, decisions []Decision, reasoning string) *FullDecision {
+	valid, rejected, _ := validateDecisions(ctx, decisions)
+	full := &FullDecision{
+		CoTTrace:  reasoning,
+		Decisions: valid,
+		Rejected:  rejected,
+		Timestamp: now(),
+	}
+	applyConfidenceThreshold(full, ctx.ConfidenceThreshold)
+	if len(full.Decisions) == 0 {
+		full.Decisions = []Decision{{
+			Symbol:    "ALL",
+			Action:    "wait",
+			Reasoning: "No valid setups this cycle - waiting",
+		}}
+	}
+	return full
+}
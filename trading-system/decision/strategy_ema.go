@@ -0,0 +1,184 @@
+package decision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lia/mcp"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+// emaTrendParams strategy_params of the "ema_trend" strategy
+type emaTrendParams struct {
+	MinChange4hPct  float64 `json:"min_change_4h_pct"` // Minimum 4h move in the trend direction to enter (default 1.0)
+	StopATRMultiple float64 `json:"stop_atr_multiple"` // Stop distance in 4h ATR14 (default 1.5, capped by the validation limits)
+	RewardRisk      float64 `json:"reward_risk"`       // Take profit distance in stop distances (default 3)
+	MarginPct       float64 `json:"margin_pct"`        // Margin per position in % of equity (default 25)
+	MaxPositions    int     `json:"max_positions"`     // Open positions held at most (default 3)
+	Confidence      int     `json:"confidence"`        // Confidence reported on opens (default 85)
+}
+
+// emaTrendStrategy rule-based trend follower on 4h EMAs: long when price > EMA20 > EMA50 with positive MACD
+// and a 4h move of at least MinChange4hPct (short mirrored); exits when price crosses back through the 4h EMA20
+type emaTrendStrategy struct {
+	params emaTrendParams
+}
+
+// newEMATrendStrategy creates the ema_trend strategy from its params (unset values take the defaults)
+func newEMATrendStrategy(raw json.RawMessage, _ *mcp.Client) (Strategy, error) {
+	params := emaTrendParams{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("invalid strategy_params: %w", err)
+		}
+	}
+	if params.MinChange4hPct <= 0 {
+		params.MinChange4hPct = 1.0
+	}
+	if params.StopATRMultiple <= 0 {
+		params.StopATRMultiple = 1.5
+	}
+	if params.RewardRisk <= 0 {
+		params.RewardRisk = 3
+	}
+	if params.MarginPct <= 0 {
+		params.MarginPct = 25
+	}
+	if params.MaxPositions <= 0 {
+		params.MaxPositions = 3
+	}
+	if params.Confidence <= 0 {
+		params.Confidence = DefaultMinConfidence
+	}
+	if params.MarginPct > 40 {
+		return nil, fmt.Errorf("margin_pct cannot exceed 40 (altcoin margin limit), got %.1f", params.MarginPct)
+	}
+	return &emaTrendStrategy{params: params}, nil
+}
+
+// Name implements Strategy
+func (s *emaTrendStrategy) Name() string {
+	return "ema_trend"
+}
+
+// emaTrendSetup an entry signal
+type emaTrendSetup struct {
+	decision Decision
+	strength float64 // |4h change|, strongest setups are taken first
+}
+
+// Decide implements Strategy
+func (s *emaTrendStrategy) Decide(reqCtx context.Context, ctx *Context) (*FullDecision, error) {
+	if err := PrepareMarketData(ctx); err != nil {
+		log.Printf("⚠️  Failed to fetch market data: %v - using fallback 'wait' decision", err)
+		return &FullDecision{
+			CoTTrace:  fmt.Sprintf("Market data fetch failed: %v", err),
+			Decisions: []Decision{{Symbol: "ALL", Action: "wait", Reasoning: fmt.Sprintf("Market data unavailable: %v - waiting for next cycle", err)}},
+			Timestamp: now(),
+		}, nil
+	}
+
+	var decisions []Decision
+	var trace strings.Builder
+	trace.WriteString(fmt.Sprintf("ema_trend: 4h EMA20/EMA50 trend, min 4h move %.1f%%, stop %.1f ATR, %.0f:1 target\n",
+		s.params.MinChange4hPct, s.params.StopATRMultiple, s.params.RewardRisk))
+
+	// 1. Exits: price back through the 4h EMA20
+	held := make(map[string]bool)
+	for _, pos := range ctx.Positions {
+		held[pos.Symbol] = true
+		data := ctx.MarketDataMap[pos.Symbol]
+		if data == nil || data.LongerTermContext == nil || data.LongerTermContext.EMA20 <= 0 {
+			continue
+		}
+		ema20 := data.LongerTermContext.EMA20
+		if pos.Side == "long" && data.CurrentPrice < ema20 {
+			decisions = append(decisions, Decision{Symbol: pos.Symbol, Action: "close_long",
+				Reasoning: fmt.Sprintf("Price %.4f below 4h EMA20 %.4f - trend lost", data.CurrentPrice, ema20)})
+		} else if pos.Side == "short" && data.CurrentPrice > ema20 {
+			decisions = append(decisions, Decision{Symbol: pos.Symbol, Action: "close_short",
+				Reasoning: fmt.Sprintf("Price %.4f above 4h EMA20 %.4f - trend lost", data.CurrentPrice, ema20)})
+		}
+	}
+
+	// 2. Entries: strongest trends first, up to the free position slots
+	slots := s.params.MaxPositions - len(ctx.Positions)
+	var setups []emaTrendSetup
+	for _, coin := range ctx.CandidateCoins {
+		if held[coin.Symbol] || slots <= 0 {
+			continue
+		}
+		if setup, ok := s.entry(ctx, coin.Symbol); ok {
+			setups = append(setups, setup)
+		}
+	}
+	sort.SliceStable(setups, func(i, j int) bool { return setups[i].strength > setups[j].strength })
+	for i := 0; i < len(setups) && i < slots; i++ {
+		decisions = append(decisions, setups[i].decision)
+	}
+
+	for _, d := range decisions {
+		trace.WriteString(fmt.Sprintf("- %s %s: %s\n", d.Symbol, d.Action, d.Reasoning))
+	}
+	if len(decisions) == 0 {
+		trace.WriteString("- No exits or entries triggered\n")
+	}
+	return FinalizeDecisions(ctx, decisions, trace.String()), nil
+}
+
+// entry the open decision for symbol if its trend qualifies
+func (s *emaTrendStrategy) entry(ctx *Context, symbol string) (emaTrendSetup, bool) {
+	data := ctx.MarketDataMap[symbol]
+	if data == nil || data.CurrentPrice <= 0 || data.LongerTermContext == nil {
+		return emaTrendSetup{}, false
+	}
+	lt := data.LongerTermContext
+	if lt.EMA20 <= 0 || lt.EMA50 <= 0 || lt.ATR14 <= 0 {
+		return emaTrendSetup{}, false
+	}
+	price := data.CurrentPrice
+
+	action := ""
+	switch {
+	case price > lt.EMA20 && lt.EMA20 > lt.EMA50 && data.CurrentMACD > 0 && data.PriceChange4h >= s.params.MinChange4hPct:
+		action = "open_long"
+	case price < lt.EMA20 && lt.EMA20 < lt.EMA50 && data.CurrentMACD < 0 && data.PriceChange4h <= -s.params.MinChange4hPct:
+		action = "open_short"
+	default:
+		return emaTrendSetup{}, false
+	}
+
+	// Stop distance in ATR, kept inside the risk policy's limits: its max stop distance, and a stop-out at the
+	// minimum margin losing no more than the per-trade risk cap
+	policy := ctx.riskPolicy()
+	leverage := ctx.AltcoinLeverage
+	if isBTCOrETH(symbol) {
+		leverage = ctx.BTCETHLeverage
+	}
+	if leverage <= 0 || ctx.Account.TotalEquity <= 0 {
+		return emaTrendSetup{}, false
+	}
+	riskStopPct := policy.MaxRiskUSD(ctx.Account.TotalEquity) / (policy.MinOpenMargin(symbol, ctx.Account.TotalEquity) * float64(leverage)) * 100
+	stopDistance := math.Min(lt.ATR14*s.params.StopATRMultiple, price*math.Min(policy.MaxStopPct(symbol), riskStopPct)*0.9/100)
+
+	d := Decision{
+		Symbol:          symbol,
+		Action:          action,
+		Leverage:        leverage,
+		PositionSizeUSD: ctx.Account.TotalEquity * s.params.MarginPct / 100,
+		Confidence:      s.params.Confidence,
+	}
+	if action == "open_long" {
+		d.StopLoss = price - stopDistance
+		d.TakeProfit = price + stopDistance*s.params.RewardRisk
+	} else {
+		d.StopLoss = price + stopDistance
+		d.TakeProfit = price - stopDistance*s.params.RewardRisk
+	}
+	d.Reasoning = fmt.Sprintf("4h trend: price %.4f, EMA20 %.4f, EMA50 %.4f, MACD %.4f, 4h change %+.2f%%",
+		price, lt.EMA20, lt.EMA50, data.CurrentMACD, data.PriceChange4h)
+	return emaTrendSetup{decision: d, strength: math.Abs(data.PriceChange4h)}, true
+}
@@ -0,0 +1,215 @@
+package export
+
+import (
+	"encoding/json"
+	"lia/config"
+	"lia/logger"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types
+const (
+	EventDecision  = "decision"  // A logged decision cycle
+	EventExecution = "execution" // An order placed (or attempted) by a cycle
+	EventEquity    = "equity"    // Account and positions at decision time
+)
+
+const (
+	retryMinDelay = time.Second
+	retryMaxDelay = 30 * time.Second
+	closeTimeout  = 5 * time.Second
+)
+
+// Event envelope of an exported message (the message key is the trader ID, so each trader's events stay
+// ordered within one partition)
+type Event struct {
+	Type        string      `json:"type"`
+	TraderID    string      `json:"trader_id"`
+	CycleNumber int         `json:"cycle_number"`
+	Time        time.Time   `json:"time"`
+	Data        interface{} `json:"data"`
+}
+
+// EquitySnapshot data of an equity event
+type EquitySnapshot struct {
+	Account   logger.AccountSnapshot    `json:"account"`
+	Positions []logger.PositionSnapshot `json:"positions"`
+}
+
+// Exporter streams decision records, executions and equity snapshots to Kafka. Export never blocks a trader:
+// messages are queued and produced in batches by a background goroutine, buffered while the brokers are
+// unreachable and dropped (oldest first) once the buffer is full
+type Exporter struct {
+	cfg      config.KafkaExportConfig
+	producer *kafkaProducer
+	queue    chan kafkaMessage
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+
+	sent    atomic.Int64
+	dropped atomic.Int64
+}
+
+// NewKafkaExporter starts exporting to the configured brokers (unreachable brokers are retried in the background)
+func NewKafkaExporter(cfg config.KafkaExportConfig) *Exporter {
+	acks := acksLeader
+	if cfg.Acks == config.KafkaAcksAll {
+		acks = acksAll
+	}
+	e := &Exporter{
+		cfg:      cfg,
+		producer: newKafkaProducer(cfg.Brokers, cfg.ClientID, acks, cfg.TLS, cfg.SASLUsername, cfg.SASLPassword),
+		queue:    make(chan kafkaMessage, cfg.BufferSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go e.run()
+	log.Printf("📤 Exporting decisions to Kafka %v (decisions: %s, executions: %s, equity: %s)",
+		cfg.Brokers, cfg.DecisionsTopic, cfg.ExecutionsTopic, cfg.EquityTopic)
+	return e
+}
+
+// ExportDecision queues a logged decision record as a decision event, one execution event per action and an
+// equity event (a logger.RecordSink)
+func (e *Exporter) ExportDecision(traderID string, record *logger.DecisionRecord) {
+	decision := *record
+	if !e.cfg.IncludePrompts {
+		decision.InputPrompt = ""
+		decision.RawResponse = ""
+	}
+	e.enqueue(e.cfg.DecisionsTopic, traderID, record, EventDecision, decision)
+	for _, action := range record.Decisions {
+		e.enqueue(e.cfg.ExecutionsTopic, traderID, record, EventExecution, action)
+	}
+	e.enqueue(e.cfg.EquityTopic, traderID, record, EventEquity, EquitySnapshot{
+		Account:   record.AccountState,
+		Positions: record.Positions,
+	})
+}
+
+// enqueue serializes an event and queues it without blocking (topic "-" = stream disabled)
+func (e *Exporter) enqueue(topic, traderID string, record *logger.DecisionRecord, eventType string, data interface{}) {
+	if topic == config.KafkaTopicDisabled {
+		return
+	}
+	value, err := json.Marshal(Event{
+		Type:        eventType,
+		TraderID:    traderID,
+		CycleNumber: record.CycleNumber,
+		Time:        record.Timestamp,
+		Data:        data,
+	})
+	if err != nil {
+		log.Printf("⚠️  Kafka export: failed to serialize %s event: %v", eventType, err)
+		return
+	}
+	msg := kafkaMessage{topic: topic, key: []byte(traderID), value: value, time: record.Timestamp}
+	select {
+	case e.queue <- msg:
+	default:
+		e.drop(1)
+	}
+}
+
+// run batches queued messages and produces them every flush interval (or once a batch is full)
+func (e *Exporter) run() {
+	defer close(e.stopped)
+	defer e.producer.close()
+
+	ticker := time.NewTicker(time.Duration(e.cfg.FlushIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	var pending []kafkaMessage
+	delay := retryMinDelay
+	var retryAt time.Time
+	for {
+		select {
+		case msg := <-e.queue:
+			pending = e.buffer(pending, msg)
+			if len(pending) < e.cfg.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-e.done:
+			for len(e.queue) > 0 {
+				pending = e.buffer(pending, <-e.queue)
+			}
+			if pending = e.send(pending); len(pending) > 0 {
+				e.drop(len(pending))
+			}
+			return
+		}
+
+		if len(pending) == 0 || time.Now().Before(retryAt) {
+			continue
+		}
+		healthy := retryAt.IsZero()
+		if pending = e.send(pending); len(pending) == 0 {
+			if !healthy {
+				log.Printf("✓ Kafka export restored")
+			}
+			retryAt = time.Time{}
+			delay = retryMinDelay
+			continue
+		}
+		retryAt = time.Now().Add(delay)
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
+
+// send produces pending in batches and returns the messages still unsent (logging the first failure)
+func (e *Exporter) send(pending []kafkaMessage) []kafkaMessage {
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > e.cfg.BatchSize {
+			n = e.cfg.BatchSize
+		}
+		failed, err := e.producer.produce(pending[:n])
+		e.sent.Add(int64(n - len(failed)))
+		if err != nil {
+			log.Printf("⚠️  Kafka export failed (%d messages pending, will retry): %v", len(pending), err)
+			return append(failed, pending[n:]...)
+		}
+		pending = pending[n:]
+	}
+	return nil
+}
+
+// buffer appends msg, dropping the oldest message once BufferSize messages are pending
+func (e *Exporter) buffer(pending []kafkaMessage, msg kafkaMessage) []kafkaMessage {
+	pending = append(pending, msg)
+	if len(pending) > e.cfg.BufferSize {
+		pending = pending[1:]
+		e.drop(1)
+	}
+	return pending
+}
+
+// drop counts dropped messages, logging the first and every 1000th
+func (e *Exporter) drop(n int) {
+	before := e.dropped.Load()
+	after := e.dropped.Add(int64(n))
+	if before == 0 || before/1000 != after/1000 {
+		log.Printf("⚠️  Kafka export buffer full: %d messages dropped so far", after)
+	}
+}
+
+// Close flushes queued messages (waiting up to closeTimeout) and closes the broker connections
+func (e *Exporter) Close() {
+	e.once.Do(func() {
+		close(e.done)
+		select {
+		case <-e.stopped:
+		case <-time.After(closeTimeout):
+			log.Printf("⚠️  Kafka export: flush on shutdown timed out")
+		}
+		log.Printf("📤 Kafka export stopped: %d messages sent, %d dropped", e.sent.Load(), e.dropped.Load())
+	})
+}
@@ -0,0 +1,645 @@
+package export
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Kafka API keys and versions used by the producer (Kafka ≥ 1.0, Redpanda)
+const (
+	apiProduce          = 0  // v3: record batches (magic 2)
+	apiMetadata         = 3  // v1
+	apiSaslHandshake    = 17 // v1
+	apiSaslAuthenticate = 36 // v0
+)
+
+const (
+	dialTimeout     = 5 * time.Second
+	requestTimeout  = 10 * time.Second
+	metadataMaxAge  = 5 * time.Minute
+	maxResponseSize = 64 << 20
+)
+
+// Acks required from the broker before a produce request succeeds
+const (
+	acksLeader int16 = 1
+	acksAll    int16 = -1
+)
+
+var (
+	castagnoli       = crc32.MakeTable(crc32.Castagnoli)
+	errShortResponse = errors.New("truncated kafka response")
+)
+
+// kafkaMessage a message waiting to be produced
+type kafkaMessage struct {
+	topic string
+	key   []byte
+	value []byte
+	time  time.Time
+}
+
+// kafkaConn a broker connection
+type kafkaConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// kafkaProducer minimal Kafka producer: metadata discovery, default (murmur2) key partitioning, uncompressed
+// record batches, optional TLS and SASL/PLAIN. Not safe for concurrent use (the exporter owns it)
+type kafkaProducer struct {
+	seeds    []string
+	clientID string
+	acks     int16
+	useTLS   bool
+	saslUser string
+	saslPass string
+
+	brokers     map[int32]string   // node id → host:port
+	leaders     map[string][]int32 // topic → leader node id per partition
+	topicErrors map[string]int16   // topic → error code of the last metadata response
+	metadataAt  time.Time
+	conns       map[string]*kafkaConn // host:port → connection
+	correlation int32
+}
+
+// newKafkaProducer creates a producer for the bootstrap brokers (connections are opened on first use)
+func newKafkaProducer(seeds []string, clientID string, acks int16, useTLS bool, saslUser, saslPass string) *kafkaProducer {
+	return &kafkaProducer{
+		seeds:    seeds,
+		clientID: clientID,
+		acks:     acks,
+		useTLS:   useTLS,
+		saslUser: saslUser,
+		saslPass: saslPass,
+		conns:    make(map[string]*kafkaConn),
+	}
+}
+
+// produce sends msgs to their partition leaders; returns the messages that were not acknowledged
+func (p *kafkaProducer) produce(msgs []kafkaMessage) ([]kafkaMessage, error) {
+	if err := p.ensureMetadata(msgs); err != nil {
+		return msgs, err
+	}
+
+	type partitionKey struct {
+		topic     string
+		partition int32
+	}
+	byLeader := make(map[int32]map[partitionKey][]kafkaMessage)
+	var failed []kafkaMessage
+	var firstErr error
+	for _, m := range msgs {
+		leaders := p.leaders[m.topic]
+		if len(leaders) == 0 {
+			failed = append(failed, m)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("topic %s unavailable: %s", m.topic, kafkaError(p.topicErrors[m.topic]))
+			}
+			continue
+		}
+		partition := partitionFor(m.key, len(leaders))
+		leader := leaders[partition]
+		if _, ok := p.brokers[leader]; !ok {
+			failed = append(failed, m)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s partition %d has no leader", m.topic, partition)
+			}
+			p.metadataAt = time.Time{}
+			continue
+		}
+		if byLeader[leader] == nil {
+			byLeader[leader] = make(map[partitionKey][]kafkaMessage)
+		}
+		key := partitionKey{topic: m.topic, partition: partition}
+		byLeader[leader][key] = append(byLeader[leader][key], m)
+	}
+
+	for leader, batches := range byLeader {
+		topics := make(map[string]map[int32][]kafkaMessage)
+		for key, batch := range batches {
+			if topics[key.topic] == nil {
+				topics[key.topic] = make(map[int32][]kafkaMessage)
+			}
+			topics[key.topic][key.partition] = batch
+		}
+		rejected, err := p.produceTo(p.brokers[leader], topics)
+		if err != nil {
+			failed = append(failed, rejected...)
+			if firstErr == nil {
+				firstErr = err
+			}
+			p.metadataAt = time.Time{} // Leadership may have moved
+		}
+	}
+	return failed, firstErr
+}
+
+// ensureMetadata refreshes partition leaders when they are stale or a topic of msgs is unknown
+func (p *kafkaProducer) ensureMetadata(msgs []kafkaMessage) error {
+	seen := make(map[string]bool)
+	var topics []string
+	missing := false
+	for _, m := range msgs {
+		if seen[m.topic] {
+			continue
+		}
+		seen[m.topic] = true
+		topics = append(topics, m.topic)
+		if len(p.leaders[m.topic]) == 0 {
+			missing = true
+		}
+	}
+	if !missing && time.Since(p.metadataAt) < metadataMaxAge {
+		return nil
+	}
+	for topic := range p.leaders {
+		if !seen[topic] {
+			topics = append(topics, topic) // Keep leaders of topics not in this batch
+		}
+	}
+	return p.refreshMetadata(topics)
+}
+
+// refreshMetadata loads brokers and partition leaders of topics from any reachable broker
+// (brokers with auto.create.topics.enable create missing topics on this request)
+func (p *kafkaProducer) refreshMetadata(topics []string) error {
+	addrs := append([]string{}, p.seeds...)
+	for _, addr := range p.brokers {
+		addrs = append(addrs, addr)
+	}
+
+	e := &encoder{}
+	e.arrayLen(len(topics))
+	for _, topic := range topics {
+		e.string(topic)
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := p.conn(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp, err := p.roundTrip(conn, apiMetadata, 1, e.buf)
+		if err != nil {
+			p.closeConn(addr)
+			lastErr = err
+			continue
+		}
+		if err := p.parseMetadata(resp); err != nil {
+			lastErr = err
+			continue
+		}
+		p.metadataAt = time.Now()
+		return nil
+	}
+	return fmt.Errorf("kafka metadata unavailable: %w", lastErr)
+}
+
+// parseMetadata reads a Metadata v1 response
+func (p *kafkaProducer) parseMetadata(resp []byte) error {
+	d := &decoder{buf: resp}
+	brokers := make(map[int32]string)
+	for i, n := 0, d.arrayLen(); i < n && d.err == nil; i++ {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.nullableString() // rack
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.int32() // controller id
+
+	leaders := make(map[string][]int32)
+	topicErrors := make(map[string]int16)
+	for i, n := 0, d.arrayLen(); i < n && d.err == nil; i++ {
+		code := d.int16()
+		name := d.string()
+		d.bool() // is_internal
+		count := d.arrayLen()
+		partitions := make([]int32, count)
+		for j := 0; j < count && d.err == nil; j++ {
+			d.int16() // partition error code
+			index := d.int32()
+			leader := d.int32()
+			d.skipInt32Array() // replicas
+			d.skipInt32Array() // in-sync replicas
+			if index >= 0 && int(index) < count {
+				partitions[index] = leader
+			}
+		}
+		if code == 0 && count > 0 {
+			leaders[name] = partitions
+		} else {
+			topicErrors[name] = code
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	p.brokers = brokers
+	p.leaders = leaders
+	p.topicErrors = topicErrors
+	return nil
+}
+
+// produceTo sends one Produce v3 request (one record batch per partition) to a broker; returns the messages
+// of partitions that were not acknowledged
+func (p *kafkaProducer) produceTo(addr string, topics map[string]map[int32][]kafkaMessage) ([]kafkaMessage, error) {
+	all := func() []kafkaMessage {
+		var msgs []kafkaMessage
+		for _, partitions := range topics {
+			for _, batch := range partitions {
+				msgs = append(msgs, batch...)
+			}
+		}
+		return msgs
+	}
+
+	e := &encoder{}
+	e.int16(-1) // transactional id: null
+	e.int16(p.acks)
+	e.int32(int32(requestTimeout / time.Millisecond))
+	e.arrayLen(len(topics))
+	for topic, partitions := range topics {
+		e.string(topic)
+		e.arrayLen(len(partitions))
+		for partition, batch := range partitions {
+			e.int32(partition)
+			e.bytes(recordBatch(batch))
+		}
+	}
+
+	conn, err := p.conn(addr)
+	if err != nil {
+		return all(), err
+	}
+	resp, err := p.roundTrip(conn, apiProduce, 3, e.buf)
+	if err != nil {
+		p.closeConn(addr)
+		return all(), fmt.Errorf("kafka produce to %s failed: %w", addr, err)
+	}
+
+	d := &decoder{buf: resp}
+	var failed []kafkaMessage
+	var firstErr error
+	for i, n := 0, d.arrayLen(); i < n && d.err == nil; i++ {
+		topic := d.string()
+		for j, count := 0, d.arrayLen(); j < count && d.err == nil; j++ {
+			partition := d.int32()
+			code := d.int16()
+			d.int64() // base offset
+			d.int64() // log append time
+			if code != 0 && d.err == nil {
+				failed = append(failed, topics[topic][partition]...)
+				if firstErr == nil {
+					firstErr = fmt.Errorf("kafka rejected %s partition %d: %s", topic, partition, kafkaError(code))
+				}
+			}
+		}
+	}
+	if d.err != nil {
+		return all(), d.err
+	}
+	return failed, firstErr
+}
+
+// conn returns the open connection to addr, connecting (and authenticating) if needed
+func (p *kafkaProducer) conn(addr string) (*kafkaConn, error) {
+	if c := p.conns[addr]; c != nil {
+		return c, nil
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if p.useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kafka broker %s: %w", addr, err)
+	}
+	c := &kafkaConn{conn: conn, reader: bufio.NewReader(conn)}
+	if p.saslUser != "" {
+		if err := p.authenticate(c); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("kafka broker %s: %w", addr, err)
+		}
+	}
+	p.conns[addr] = c
+	return c, nil
+}
+
+// authenticate performs the SASL/PLAIN exchange on a new connection
+func (p *kafkaProducer) authenticate(c *kafkaConn) error {
+	e := &encoder{}
+	e.string("PLAIN")
+	resp, err := p.roundTrip(c, apiSaslHandshake, 1, e.buf)
+	if err != nil {
+		return fmt.Errorf("SASL handshake failed: %w", err)
+	}
+	d := &decoder{buf: resp}
+	if code := d.int16(); code != 0 {
+		return fmt.Errorf("SASL handshake failed: %s", kafkaError(code))
+	}
+
+	e = &encoder{}
+	e.bytes([]byte("\x00" + p.saslUser + "\x00" + p.saslPass))
+	resp, err = p.roundTrip(c, apiSaslAuthenticate, 0, e.buf)
+	if err != nil {
+		return fmt.Errorf("SASL authentication failed: %w", err)
+	}
+	d = &decoder{buf: resp}
+	code := d.int16()
+	message := d.nullableString()
+	if code != 0 {
+		return fmt.Errorf("SASL authentication failed: %s %s", kafkaError(code), message)
+	}
+	return d.err
+}
+
+// roundTrip sends a request and returns the response body (after the correlation id)
+func (p *kafkaProducer) roundTrip(c *kafkaConn, apiKey, version int16, body []byte) ([]byte, error) {
+	p.correlation++
+	e := &encoder{}
+	e.int32(0) // Size, filled in below
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(p.correlation)
+	e.string(p.clientID)
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+
+	c.conn.SetDeadline(time.Now().Add(requestTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+	if _, err := c.conn.Write(e.buf); err != nil {
+		return nil, err
+	}
+	var header [8]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(header[:4]))
+	if size < 4 || size > maxResponseSize {
+		return nil, fmt.Errorf("invalid kafka response size %d", size)
+	}
+	if id := int32(binary.BigEndian.Uint32(header[4:])); id != p.correlation {
+		return nil, fmt.Errorf("kafka correlation id mismatch (got %d, expected %d)", id, p.correlation)
+	}
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(c.reader, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// closeConn drops the connection to addr (reopened on next use)
+func (p *kafkaProducer) closeConn(addr string) {
+	if c := p.conns[addr]; c != nil {
+		c.conn.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// close closes all broker connections
+func (p *kafkaProducer) close() {
+	for addr := range p.conns {
+		p.closeConn(addr)
+	}
+}
+
+// recordBatch encodes msgs as an uncompressed v2 record batch (no idempotence, CreateTime timestamps)
+func recordBatch(msgs []kafkaMessage) []byte {
+	first, last := msgs[0].time.UnixMilli(), msgs[0].time.UnixMilli()
+	for _, m := range msgs {
+		ts := m.time.UnixMilli()
+		if ts < first {
+			first = ts
+		}
+		if ts > last {
+			last = ts
+		}
+	}
+
+	records := &encoder{}
+	for i, m := range msgs {
+		r := &encoder{}
+		r.int8(0) // attributes
+		r.varint(m.time.UnixMilli() - first)
+		r.varint(int64(i)) // offset delta
+		r.varbytes(m.key)
+		r.varbytes(m.value)
+		r.varint(0) // headers
+		records.varint(int64(len(r.buf)))
+		records.buf = append(records.buf, r.buf...)
+	}
+
+	// Everything after the CRC field is covered by the CRC
+	body := &encoder{}
+	body.int16(0) // attributes: no compression, CreateTime
+	body.int32(int32(len(msgs) - 1))
+	body.int64(first)
+	body.int64(last)
+	body.int64(-1) // producer id
+	body.int16(-1) // producer epoch
+	body.int32(-1) // base sequence
+	body.int32(int32(len(msgs)))
+	body.buf = append(body.buf, records.buf...)
+
+	batch := &encoder{}
+	batch.int64(0)                                // base offset (assigned by the broker)
+	batch.int32(int32(4 + 1 + 4 + len(body.buf))) // length: leader epoch, magic, crc, body
+	batch.int32(-1)                               // partition leader epoch
+	batch.int8(2)                                 // magic
+	batch.buf = binary.BigEndian.AppendUint32(batch.buf, crc32.Checksum(body.buf, castagnoli))
+	batch.buf = append(batch.buf, body.buf...)
+	return batch.buf
+}
+
+// partitionFor the Java client's default partitioner (murmur2 of the key), so consumers can rely on the
+// same key → partition mapping as other producers of the topic
+func partitionFor(key []byte, partitions int) int32 {
+	return int32((murmur2(key) & 0x7fffffff) % uint32(partitions))
+}
+
+// murmur2 the hash used by Kafka's default partitioner
+func murmur2(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := length &^ 3
+	switch length % 4 {
+	case 3:
+		h ^= uint32(data[tail+2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[tail+1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[tail])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+// kafkaError name of a Kafka error code
+func kafkaError(code int16) string {
+	names := map[int16]string{
+		-1: "UNKNOWN_SERVER_ERROR",
+		0:  "NONE",
+		2:  "CORRUPT_MESSAGE",
+		3:  "UNKNOWN_TOPIC_OR_PARTITION",
+		5:  "LEADER_NOT_AVAILABLE",
+		6:  "NOT_LEADER_OR_FOLLOWER",
+		7:  "REQUEST_TIMED_OUT",
+		10: "MESSAGE_TOO_LARGE",
+		17: "INVALID_TOPIC_EXCEPTION",
+		19: "NOT_ENOUGH_REPLICAS",
+		20: "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+		29: "TOPIC_AUTHORIZATION_FAILED",
+		33: "UNSUPPORTED_SASL_MECHANISM",
+		34: "ILLEGAL_SASL_STATE",
+		35: "UNSUPPORTED_VERSION",
+		58: "SASL_AUTHENTICATION_FAILED",
+		87: "INVALID_RECORD",
+	}
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("error code %d", code)
+}
+
+// encoder appends Kafka protocol primitives (big endian, int16-length strings)
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *encoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) arrayLen(n int) { e.int32(int32(n)) }
+
+// varint zigzag varint, as used inside record batches
+func (e *encoder) varint(v int64) { e.buf = binary.AppendVarint(e.buf, v) }
+
+// varbytes varint length-prefixed bytes (nil = -1)
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// decoder reads Kafka protocol primitives; the first error sticks and later reads return zero values
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = errShortResponse
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) bool() bool {
+	b := d.take(1)
+	return b != nil && b[0] != 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	return string(d.take(int(d.int16())))
+}
+
+func (d *decoder) nullableString() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+// arrayLen array length (null arrays are empty)
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.buf) { // Every element takes at least one byte
+		d.err = errShortResponse
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) skipInt32Array() {
+	d.take(4 * d.arrayLen())
+}
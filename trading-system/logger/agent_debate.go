@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrCycleNotFound no decision record for the requested cycle
+var ErrCycleNotFound = errors.New("cycle not found")
+
+// AgentDebate how the multi-agent committee reached a cycle's decisions
+type AgentDebate struct {
+	ConsensusMode string          `json:"consensus_mode"` // voting / majority / weighted / sharpe / veto / chair / unanimous / best
+	Resolution    string          `json:"resolution"`     // How the consensus was reached (or why it was not)
+	Proposals     []AgentProposal `json:"proposals"`
+	Votes         []AgentVote     `json:"votes"`
+}
+
+// AgentProposal one agent's answer in a cycle
+type AgentProposal struct {
+	AgentID    string          `json:"agent_id"`
+	Name       string          `json:"name,omitempty"`
+	Provider   string          `json:"provider"`
+	Model      string          `json:"model"`
+	Role       string          `json:"role,omitempty"`
+	Weight     float64         `json:"weight,omitempty"` // Normalized weight (weighted mode)
+	Status     string          `json:"status"`           // proposed / error / late / skipped / chair
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+	CoTTrace   string          `json:"cot_trace,omitempty"`
+	Decisions  json.RawMessage `json:"decisions,omitempty"` // The agent's decision array
+}
+
+// AgentVote the agents that proposed the same symbol+action
+type AgentVote struct {
+	Symbol   string   `json:"symbol"`
+	Action   string   `json:"action"`
+	Agents   []string `json:"agents"`
+	Votes    int      `json:"votes"`
+	Share    float64  `json:"share"`    // Share of the proposing agents (weighted mode: of the committee weight)
+	Accepted bool     `json:"accepted"` // Part of the final decisions
+}
+
+// insertAgentDebate stores a record's debate in its decision transaction
+func (l *DecisionLogger) insertAgentDebate(tx *sql.Tx, decisionID int64, debate *AgentDebate) error {
+	if _, err := tx.Exec(l.rebind(`
+		INSERT INTO agent_debates (decision_id, consensus_mode, resolution) VALUES (?, ?, ?)`),
+		decisionID, debate.ConsensusMode, debate.Resolution); err != nil {
+		return fmt.Errorf("failed to save agent debate: %w", err)
+	}
+	for _, p := range debate.Proposals {
+		if _, err := tx.Exec(l.rebind(`
+			INSERT INTO agent_proposals (
+				decision_id, agent_id, agent_name, provider, model, role, weight, status, error, duration_ms,
+				cot_trace, decisions_json
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+			decisionID, p.AgentID, p.Name, p.Provider, p.Model, p.Role, p.Weight, p.Status, p.Error, p.DurationMs,
+			p.CoTTrace, string(p.Decisions)); err != nil {
+			return fmt.Errorf("failed to save agent proposal: %w", err)
+		}
+	}
+	for _, v := range debate.Votes {
+		agents, _ := json.Marshal(v.Agents)
+		if _, err := tx.Exec(l.rebind(`
+			INSERT INTO agent_votes (decision_id, symbol, action, agents, votes, share, accepted)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`),
+			decisionID, v.Symbol, v.Action, string(agents), v.Votes, v.Share, v.Accepted); err != nil {
+			return fmt.Errorf("failed to save agent vote: %w", err)
+		}
+	}
+	return nil
+}
+
+// rebind numbers the ? placeholders of q on PostgreSQL
+func (l *DecisionLogger) rebind(q string) string {
+	if l.isPostgres {
+		return postgresPlaceholders(q)
+	}
+	return q
+}
+
+// GetAgentDebate the multi-agent debate behind a cycle's decisions (nil when the cycle was not decided by the
+// committee, ErrCycleNotFound when there is no such cycle)
+func (l *DecisionLogger) GetAgentDebate(cycle int) (*AgentDebate, error) {
+	if l.db == nil {
+		return l.getAgentDebateFromJSON(cycle)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var decisionID int64
+	var err error
+	if l.isPostgres {
+		err = l.db.QueryRowContext(ctx, `SELECT id FROM decisions WHERE trader_id = $1 AND cycle_number = $2`,
+			l.traderID, cycle).Scan(&decisionID)
+	} else {
+		err = l.db.QueryRowContext(ctx, `SELECT id FROM decisions WHERE cycle_number = ? ORDER BY id DESC LIMIT 1`,
+			cycle).Scan(&decisionID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCycleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	debate := &AgentDebate{}
+	err = l.db.QueryRowContext(ctx, l.rebind(`SELECT consensus_mode, resolution FROM agent_debates WHERE decision_id = ?`),
+		decisionID).Scan(&debate.ConsensusMode, &debate.Resolution)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	rows, err := l.db.QueryContext(ctx, l.rebind(`
+		SELECT agent_id, COALESCE(agent_name, ''), COALESCE(provider, ''), model, COALESCE(role, ''), weight, status, COALESCE(error, ''),
+			duration_ms, COALESCE(cot_trace, ''), COALESCE(decisions_json, '')
+		FROM agent_proposals WHERE decision_id = ? ORDER BY id`), decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	debate.Proposals = []AgentProposal{}
+	for rows.Next() {
+		var p AgentProposal
+		var decisionsJSON string
+		if err := rows.Scan(&p.AgentID, &p.Name, &p.Provider, &p.Model, &p.Role, &p.Weight, &p.Status, &p.Error,
+			&p.DurationMs, &p.CoTTrace, &decisionsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan agent proposal: %w", err)
+		}
+		if decisionsJSON != "" {
+			p.Decisions = json.RawMessage(decisionsJSON)
+		}
+		debate.Proposals = append(debate.Proposals, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	voteRows, err := l.db.QueryContext(ctx, l.rebind(`
+		SELECT symbol, action, agents, votes, share, accepted
+		FROM agent_votes WHERE decision_id = ? ORDER BY id`), decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer voteRows.Close()
+	debate.Votes = []AgentVote{}
+	for voteRows.Next() {
+		var v AgentVote
+		var agents string
+		if err := voteRows.Scan(&v.Symbol, &v.Action, &agents, &v.Votes, &v.Share, &v.Accepted); err != nil {
+			return nil, fmt.Errorf("failed to scan agent vote: %w", err)
+		}
+		json.Unmarshal([]byte(agents), &v.Agents)
+		debate.Votes = append(debate.Votes, v)
+	}
+	return debate, voteRows.Err()
+}
+
+// getAgentDebateFromJSON reads the debate from the cycle's JSON record (fallback method)
+func (l *DecisionLogger) getAgentDebateFromJSON(cycle int) (*AgentDebate, error) {
+	matches, err := filepath.Glob(filepath.Join(l.logDir, fmt.Sprintf("decision_*_cycle%d.json", cycle)))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, ErrCycleNotFound
+	}
+	data, err := os.ReadFile(matches[len(matches)-1])
+	if err != nil {
+		return nil, err
+	}
+	var record DecisionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse cycle #%d record: %w", cycle, err)
+	}
+	return record.AgentDebate, nil
+}
+
+// AgentTrackRecord how the trades opened on an agent's proposals did
+type AgentTrackRecord struct {
+	Trades       int     `json:"trades"`         // Closed trades whose open the agent proposed
+	Wins         int     `json:"wins"`           // Of those, closed with a net profit
+	AvgReturnPct float64 `json:"avg_return_pct"` // Average net return on margin
+	Sharpe       float64 `json:"sharpe"`         // Mean / standard deviation of the per-trade returns (capped at ±3)
+}
+
+// maxAgentSharpe cap of an agent's per-trade Sharpe (few trades without losses have no deviation)
+const maxAgentSharpe = 3.0
+
+// GetAgentTrackRecords credits every trade closed within the last lookbackCycles cycles (<= 0 = all) to the
+// agents that proposed its open in the opening cycle's multi-agent debate
+func (l *DecisionLogger) GetAgentTrackRecords(lookbackCycles int) (map[string]*AgentTrackRecord, error) {
+	trades, err := l.GetTrades(TradeClosed, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trade journal: %w", err)
+	}
+	minCloseCycle := 1
+	if lookbackCycles > 0 {
+		minCloseCycle = l.cycleNumber - lookbackCycles + 1
+	}
+
+	debates := make(map[int]*AgentDebate)
+	returns := make(map[string][]float64)
+	records := make(map[string]*AgentTrackRecord)
+	for _, t := range trades {
+		if t.CloseCycle < minCloseCycle || t.OpenCycle <= 0 {
+			continue
+		}
+		debate, ok := debates[t.OpenCycle]
+		if !ok {
+			if debate, err = l.GetAgentDebate(t.OpenCycle); err != nil && !errors.Is(err, ErrCycleNotFound) {
+				return nil, err
+			}
+			debates[t.OpenCycle] = debate
+		}
+		if debate == nil {
+			continue
+		}
+
+		margin := t.Quantity * t.OpenPrice
+		if t.Leverage > 0 {
+			margin /= float64(t.Leverage)
+		}
+		if margin <= 0 {
+			continue
+		}
+		ret := t.NetPnL / margin
+		for _, p := range debate.Proposals {
+			if p.Status != "proposed" || !proposedOpen(p.Decisions, t.Symbol, "open_"+t.Side) {
+				continue
+			}
+			r := records[p.AgentID]
+			if r == nil {
+				r = &AgentTrackRecord{}
+				records[p.AgentID] = r
+			}
+			r.Trades++
+			if t.NetPnL > 0 {
+				r.Wins++
+			}
+			returns[p.AgentID] = append(returns[p.AgentID], ret)
+		}
+	}
+
+	for id, r := range records {
+		mean, std := meanStd(returns[id])
+		r.AvgReturnPct = mean * 100
+		switch {
+		case std > 0:
+			r.Sharpe = math.Max(-maxAgentSharpe, math.Min(maxAgentSharpe, mean/std))
+		case mean > 0:
+			r.Sharpe = maxAgentSharpe
+		case mean < 0:
+			r.Sharpe = -maxAgentSharpe
+		}
+	}
+	return records, nil
+}
+
+// proposedOpen reports whether an agent's decision array has action on symbol
+func proposedOpen(decisions json.RawMessage, symbol, action string) bool {
+	var proposed []struct {
+		Symbol string `json:"symbol"`
+		Action string `json:"action"`
+	}
+	if json.Unmarshal(decisions, &proposed) != nil {
+		return false
+	}
+	for _, d := range proposed {
+		if strings.EqualFold(d.Symbol, symbol) && d.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// meanStd mean and population standard deviation
+func meanStd(values []float64) (mean, std float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(values)))
+}
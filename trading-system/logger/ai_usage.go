@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AIUsage token usage and estimated cost of the AI calls of a decision cycle
+type AIUsage struct {
+	Model            string  `json:"model,omitempty"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// orNil the usage, or nil when the cycle made no AI call (rows logged before usage was tracked)
+func (u AIUsage) orNil() *AIUsage {
+	if u.Calls == 0 {
+		return nil
+	}
+	return &u
+}
+
+// Add adds another call's usage (Model becomes "mixed" when the calls used different models)
+func (u *AIUsage) Add(other AIUsage) {
+	switch {
+	case u.Model == "":
+		u.Model = other.Model
+	case other.Model != "" && other.Model != u.Model:
+		u.Model = "mixed"
+	}
+	u.Calls += other.Calls
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.CostUSD += other.CostUSD
+}
+
+// CycleAIUsage AI usage of one logged cycle
+type CycleAIUsage struct {
+	Timestamp   time.Time `json:"timestamp"`
+	CycleNumber int       `json:"cycle_number"`
+	AIUsage
+}
+
+// GetAIUsageInRange gets the AI usage of the cycles logged between start and end (oldest first; cycles
+// without AI calls are left out)
+func (l *DecisionLogger) GetAIUsageInRange(start, end time.Time) ([]CycleAIUsage, error) {
+	if l.db != nil {
+		return l.getAIUsageInRangeFromDB(start, end)
+	}
+
+	// Fallback to JSON files
+	return l.getAIUsageInRangeFromJSON(start, end)
+}
+
+// getAIUsageInRangeFromDB gets cycle AI usage from database for a time range
+func (l *DecisionLogger) getAIUsageInRangeFromDB(start, end time.Time) ([]CycleAIUsage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var rows *sql.Rows
+	var err error
+
+	if l.isPostgres {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT timestamp, cycle_number, ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd
+			FROM decisions
+			WHERE trader_id = $1 AND ai_calls > 0 AND timestamp >= $2 AND timestamp <= $3
+			ORDER BY timestamp ASC
+		`, l.traderID, start, end)
+	} else {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT timestamp, cycle_number, ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd
+			FROM decisions
+			WHERE ai_calls > 0 AND timestamp >= ? AND timestamp <= ?
+			ORDER BY timestamp ASC
+		`, start, end)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []CycleAIUsage
+	for rows.Next() {
+		var u CycleAIUsage
+		if err := rows.Scan(&u.Timestamp, &u.CycleNumber, &u.Model, &u.Calls, &u.PromptTokens, &u.CompletionTokens, &u.CostUSD); err != nil {
+			continue
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
+
+// getAIUsageInRangeFromJSON gets cycle AI usage from JSON files for a time range (fallback method)
+func (l *DecisionLogger) getAIUsageInRangeFromJSON(start, end time.Time) ([]CycleAIUsage, error) {
+	records, err := l.getAllRecordsFromJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []CycleAIUsage
+	for _, record := range records {
+		if record.AIUsage == nil || record.Timestamp.Before(start) || record.Timestamp.After(end) {
+			continue
+		}
+		usage = append(usage, CycleAIUsage{Timestamp: record.Timestamp, CycleNumber: record.CycleNumber, AIUsage: *record.AIUsage})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Timestamp.Before(usage[j].Timestamp) })
+	return usage, nil
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AttributionQuery which closed trades to attribute
+type AttributionQuery struct {
+	Symbol   string         // Only this symbol ("" = all)
+	Side     string         // Only long or short ("" = both)
+	Since    time.Time      // Closed at or after (zero = all history)
+	Location *time.Location // Time zone of the hour-of-day buckets (nil = UTC)
+}
+
+// AttributionBucket closed-trade results of one group (net of fees)
+type AttributionBucket struct {
+	Key           string  `json:"key"` // Symbol, side, hour ("00"-"23") or holding duration ("<15m", ...)
+	Trades        int     `json:"trades"`
+	WinningTrades int     `json:"winning_trades"`
+	LosingTrades  int     `json:"losing_trades"`
+	WinRate       float64 `json:"win_rate"`
+	TotalPnL      float64 `json:"total_pnl"`
+	AvgPnL        float64 `json:"avg_pnl"`
+}
+
+// PerformanceAttribution closed trades broken down by symbol, side, hour of day and holding duration
+type PerformanceAttribution struct {
+	TotalTrades  int                 `json:"total_trades"`
+	TotalPnL     float64             `json:"total_pnl"`
+	BySymbol     []AttributionBucket `json:"by_symbol"`      // Best total P&L first
+	BySide       []AttributionBucket `json:"by_side"`        // long, short
+	BySymbolSide []AttributionBucket `json:"by_symbol_side"` // "BTCUSDT long", ... best total P&L first
+	ByHour       []AttributionBucket `json:"by_hour"`        // Hour the trade opened, all 24 hours
+	ByDuration   []AttributionBucket `json:"by_duration"`    // Shortest holding time first
+}
+
+// durationBuckets holding duration buckets: upper bound (exclusive) and key
+var durationBuckets = []struct {
+	max time.Duration
+	key string
+}{
+	{15 * time.Minute, "<15m"},
+	{time.Hour, "15m-1h"},
+	{4 * time.Hour, "1h-4h"},
+	{12 * time.Hour, "4h-12h"},
+	{24 * time.Hour, "12h-24h"},
+	{3 * 24 * time.Hour, "1d-3d"},
+	{0, ">3d"},
+}
+
+// GetPerformanceAttribution attributes the journal's closed trades matching q
+func (l *DecisionLogger) GetPerformanceAttribution(q AttributionQuery) (*PerformanceAttribution, error) {
+	trades, err := l.GetTrades(TradeClosed, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trade journal: %w", err)
+	}
+	return AttributeTrades(trades, q), nil
+}
+
+// AttributeTrades groups the closed trades matching q (see PerformanceAttribution)
+func AttributeTrades(trades []Trade, q AttributionQuery) *PerformanceAttribution {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	bySymbol := make(map[string]*AttributionBucket)
+	bySide := make(map[string]*AttributionBucket)
+	bySymbolSide := make(map[string]*AttributionBucket)
+	byHour := make(map[string]*AttributionBucket)
+	byDuration := make(map[string]*AttributionBucket)
+
+	attribution := &PerformanceAttribution{}
+	for _, t := range trades {
+		if t.Status != TradeClosed ||
+			(q.Symbol != "" && !strings.EqualFold(t.Symbol, q.Symbol)) ||
+			(q.Side != "" && t.Side != q.Side) ||
+			t.CloseTime.Before(q.Since) {
+			continue
+		}
+		attribution.TotalTrades++
+		attribution.TotalPnL += t.NetPnL
+
+		addToBucket(bySymbol, t.Symbol, t.NetPnL)
+		addToBucket(bySide, t.Side, t.NetPnL)
+		addToBucket(bySymbolSide, t.Symbol+" "+t.Side, t.NetPnL)
+		addToBucket(byHour, fmt.Sprintf("%02d", t.OpenTime.In(loc).Hour()), t.NetPnL)
+		addToBucket(byDuration, durationBucket(t.CloseTime.Sub(t.OpenTime)), t.NetPnL)
+	}
+
+	attribution.BySymbol = sortedBuckets(bySymbol, byTotalPnL)
+	attribution.BySymbolSide = sortedBuckets(bySymbolSide, byTotalPnL)
+	attribution.BySide = sortedBuckets(bySide, byKey)
+	for hour := 0; hour < 24; hour++ {
+		key := fmt.Sprintf("%02d", hour)
+		if _, ok := byHour[key]; !ok {
+			byHour[key] = &AttributionBucket{Key: key}
+		}
+	}
+	attribution.ByHour = sortedBuckets(byHour, byKey)
+	attribution.ByDuration = sortedBuckets(byDuration, byDurationOrder)
+	return attribution
+}
+
+// durationBucket the holding duration bucket of a trade
+func durationBucket(d time.Duration) string {
+	for _, bucket := range durationBuckets {
+		if bucket.max == 0 || d < bucket.max {
+			return bucket.key
+		}
+	}
+	return durationBuckets[len(durationBuckets)-1].key
+}
+
+// addToBucket counts a trade's net P&L in its group
+func addToBucket(buckets map[string]*AttributionBucket, key string, pnl float64) {
+	b, ok := buckets[key]
+	if !ok {
+		b = &AttributionBucket{Key: key}
+		buckets[key] = b
+	}
+	b.Trades++
+	b.TotalPnL += pnl
+	if pnl > 0 {
+		b.WinningTrades++
+	} else if pnl < 0 {
+		b.LosingTrades++
+	}
+}
+
+// byTotalPnL best total P&L first
+func byTotalPnL(a, b AttributionBucket) bool {
+	return a.TotalPnL > b.TotalPnL || (a.TotalPnL == b.TotalPnL && a.Key < b.Key)
+}
+
+// byKey alphabetical (hours sort numerically, being zero-padded)
+func byKey(a, b AttributionBucket) bool {
+	return a.Key < b.Key
+}
+
+// byDurationOrder shortest holding duration bucket first
+func byDurationOrder(a, b AttributionBucket) bool {
+	return bucketPosition(a.Key) < bucketPosition(b.Key)
+}
+
+// bucketPosition the position of a duration bucket key in durationBuckets
+func bucketPosition(key string) int {
+	for i, bucket := range durationBuckets {
+		if bucket.key == key {
+			return i
+		}
+	}
+	return len(durationBuckets)
+}
+
+// sortedBuckets the groups with win rate and average P&L filled in
+func sortedBuckets(buckets map[string]*AttributionBucket, less func(a, b AttributionBucket) bool) []AttributionBucket {
+	result := make([]AttributionBucket, 0, len(buckets))
+	for _, b := range buckets {
+		if b.Trades > 0 {
+			b.WinRate = float64(b.WinningTrades) / float64(b.Trades) * 100
+			b.AvgPnL = b.TotalPnL / float64(b.Trades)
+		}
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+	return result
+}
@@ -10,8 +10,10 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL driver for Supabase
@@ -20,6 +22,7 @@ import (
 
 // DecisionRecord decision record
 type DecisionRecord struct {
+	ID             int64              `json:"-"`               // Row ID in the decisions table (position in the history in JSON file mode)
 	Timestamp      time.Time          `json:"timestamp"`       // Decision time
 	CycleNumber    int                `json:"cycle_number"`    // Cycle number
 	InputPrompt    string             `json:"input_prompt"`    // Input prompt sent to AI
@@ -33,8 +36,24 @@ type DecisionRecord struct {
 	ExecutionLog   []string           `json:"execution_log"`   // Execution log
 	Success        bool               `json:"success"`         // Whether successful
 	ErrorMessage   string             `json:"error_message"`   // Error message (if any)
+	AIUsage        *AIUsage           `json:"ai_usage,omitempty"` // AI token usage and estimated cost (nil = no AI call)
+	PromptTemplate string             `json:"prompt_template,omitempty"` // Prompt template the AI prompts were built from
+	PromptVersion  string             `json:"prompt_version,omitempty"`  // Hash of the template sources (see /api/prompts/versions)
+	ConsensusMode  string             `json:"consensus_mode,omitempty"`  // Multi-agent consensus mode that made the decisions ("" = single AI or other engine)
+	AgentDebate    *AgentDebate       `json:"agent_debate,omitempty"`    // Multi-agent proposals and votes (see GetAgentDebate)
+	RiskReview     *RiskReview        `json:"risk_review,omitempty"`     // Risk officer verdicts on the opens (nil = not reviewed)
+	RiskPolicy     string             `json:"risk_policy,omitempty"`     // Risk policy the decisions were validated against ("name@version")
+	Source         string             `json:"source,omitempty"`          // What made the decisions: "" (the trader's AI or strategy), copy, signal, manual or emergency
 }
 
+// Decision sources other than the trader's own AI or strategy (DecisionRecord.Source)
+const (
+	DecisionSourceCopy      = "copy"      // Copied from other traders (copy_from_trader_id)
+	DecisionSourceSignal    = "signal"    // Signal webhook
+	DecisionSourceManual    = "manual"    // Operator trade via POST /api/trade
+	DecisionSourceEmergency = "emergency" // Kill switch (POST /api/emergency/flatten)
+)
+
 // AccountSnapshot account state snapshot
 type AccountSnapshot struct {
 	TotalBalance          float64 `json:"total_balance"`
@@ -67,24 +86,59 @@ type DecisionAction struct {
 	Timestamp time.Time `json:"timestamp"` // Execution time
 	Success   bool      `json:"success"`   // Whether successful
 	Error     string    `json:"error"`     // Error message
+
+	ClientOrderID string   `json:"client_order_id,omitempty"` // Client order ID sent to the exchange (for execution audits)
+	Partial       bool     `json:"partial,omitempty"`         // Close of part of the position (close_pct); the position stays open
+	Fee           float64  `json:"fee,omitempty"`             // Fee reported with the order (trade journal)
+	RealizedPnL   *float64 `json:"realized_pnl,omitempty"`    // P&L the exchange reported for a close (nil = estimated from prices)
 }
 
-// DecisionLogger decision logger (supports SQLite and Supabase/PostgreSQL)
+// DecisionLogger decision logger (supports SQLite, Supabase/PostgreSQL and MySQL/MariaDB)
 type DecisionLogger struct {
 	db          *sql.DB
 	logDir      string
 	cycleNumber int
+	cycleMu     sync.Mutex // Guards cycleNumber against the JSON migration running in the background at startup
 	traderID    string // Trader ID (required for Supabase)
-	isPostgres  bool   // True if using PostgreSQL/Supabase, false for SQLite
+	isPostgres  bool   // True for the shared server databases (PostgreSQL/Supabase, MySQL): trader_id column, $n placeholders
+	isMySQL     bool   // MySQL/MariaDB (isPostgres is also set: the same queries are translated by the connection)
+	sink        RecordSink // Receives every logged record (nil = none)
+	queue       *writeQueue // Asynchronous Supabase writes through a local spill (nil = synchronous)
+}
+
+// RecordSink receives each decision record after it is logged (must not block or modify the record)
+type RecordSink func(traderID string, record *DecisionRecord)
+
+// Database connection pool limits (lowered by low-memory mode)
+var (
+	dbMaxOpenConns = 20
+	dbMaxIdleConns = 10
+	dbPoolLimited  = false // When true, limits also apply to SQLite connections
+)
+
+// SetConnectionPoolLimits overrides database connection pool sizes (used by low-memory mode)
+func SetConnectionPoolLimits(maxOpen, maxIdle int) {
+	if maxOpen <= 0 {
+		return
+	}
+	if maxIdle < 0 || maxIdle > maxOpen {
+		maxIdle = maxOpen
+	}
+	dbMaxOpenConns = maxOpen
+	dbMaxIdleConns = maxIdle
+	dbPoolLimited = true
+	log.Printf("✓ Database connection pool limited (max open: %d, max idle: %d)", maxOpen, maxIdle)
 }
 
 // SupabaseConfig configuration for Supabase database
 type SupabaseConfig struct {
 	UseSupabase         bool
-	DatabaseURL         string // PostgreSQL connection string
+	DatabaseURL         string // PostgreSQL connection string (MySQL URL or DSN with Driver "mysql")
+	Driver              string // "postgres" (default) or "mysql"
 	SupabaseURL         string // Supabase project URL (not used with direct connection)
 	SupabaseKey         string // Supabase API key (not used with direct connection)
 	Schema              string // Database schema (default: "public")
+	WriteQueue          *WriteQueueOptions // Write records asynchronously through a local spill (nil = synchronous)
 }
 
 // NewDecisionLogger creates decision logger (backward compatible - uses SQLite)
@@ -111,28 +165,38 @@ func NewDecisionLoggerWithConfig(logDir string, traderID string, supabaseConfig
 	// Check if Supabase is enabled
 	if supabaseConfig != nil && supabaseConfig.UseSupabase && supabaseConfig.DatabaseURL != "" {
 		logger.isPostgres = true
+		logger.isMySQL = supabaseConfig.Driver == "mysql"
 		logger.traderID = traderID
-		
-		// Add connection parameters for better compatibility
-		connString := supabaseConfig.DatabaseURL
-		// Add connection timeout and other parameters if not already present
-		// Increased timeout to 30 seconds to handle network latency
-		if !strings.Contains(connString, "?") && !strings.Contains(connString, "connect_timeout") {
-			connString += "?connect_timeout=30&sslmode=require"
-		} else if strings.Contains(connString, "?") && !strings.Contains(connString, "connect_timeout") {
-			connString += "&connect_timeout=30&sslmode=require"
+		backendName := "Supabase"
+
+		var db *sql.DB
+		var err error
+		if logger.isMySQL {
+			backendName = "MySQL"
+			db, err = openMySQL(supabaseConfig.DatabaseURL)
+		} else {
+			// Add connection parameters for better compatibility
+			connString := supabaseConfig.DatabaseURL
+			// Add connection timeout and other parameters if not already present
+			// Increased timeout to 30 seconds to handle network latency
+			if !strings.Contains(connString, "?") && !strings.Contains(connString, "connect_timeout") {
+				connString += "?connect_timeout=30&sslmode=require"
+			} else if strings.Contains(connString, "?") && !strings.Contains(connString, "connect_timeout") {
+				connString += "&connect_timeout=30&sslmode=require"
+			}
+
+			// Connect to PostgreSQL/Supabase
+			db, err = sql.Open("postgres", connString)
 		}
-		
-		// Connect to PostgreSQL/Supabase
-		db, err := sql.Open("postgres", connString)
 		if err != nil {
-			log.Printf("⚠ Failed to open Supabase database: %v\n", err)
+			log.Printf("⚠ Failed to open %s database: %v\n", backendName, err)
 			log.Printf("⚠ Falling back to SQLite...\n")
 			logger.isPostgres = false
+			logger.isMySQL = false
 		} else {
 			// Set connection pool settings - increased for better performance
-			db.SetMaxOpenConns(20)
-			db.SetMaxIdleConns(10)
+			db.SetMaxOpenConns(dbMaxOpenConns)
+			db.SetMaxIdleConns(dbMaxIdleConns)
 			db.SetConnMaxLifetime(10 * time.Minute)
 			
 			// Test connection with timeout context - increased to 30 seconds
@@ -140,14 +204,15 @@ func NewDecisionLoggerWithConfig(logDir string, traderID string, supabaseConfig
 			defer cancel()
 			
 			if err := db.PingContext(ctx); err != nil {
-				log.Printf("⚠ Supabase database connection failed: %v\n", err)
+				log.Printf("⚠ %s database connection failed: %v\n", backendName, err)
 				log.Printf("⚠ Connection string (masked): %s\n", maskConnectionString(supabaseConfig.DatabaseURL))
 				log.Printf("⚠ Falling back to SQLite...\n")
 				db.Close()
 				logger.isPostgres = false
+				logger.isMySQL = false
 			} else {
 				logger.db = db
-				log.Printf("✅ Connected to Supabase database (trader_id: %s)\n", traderID)
+				log.Printf("✅ Connected to %s database (trader_id: %s)\n", backendName, traderID)
 			}
 		}
 	}
@@ -172,6 +237,10 @@ func NewDecisionLoggerWithConfig(logDir string, traderID string, supabaseConfig
 				log.Printf("⚠ SQLite database connection failed: %v\n", err)
 				db.Close()
 			} else {
+				if dbPoolLimited {
+					db.SetMaxOpenConns(dbMaxOpenConns)
+					db.SetMaxIdleConns(dbMaxIdleConns)
+				}
 				logger.db = db
 			}
 		}
@@ -196,6 +265,15 @@ func NewDecisionLoggerWithConfig(logDir string, traderID string, supabaseConfig
 			if err := logger.restoreCycleNumber(); err != nil {
 				log.Printf("ℹ️  Unable to restore previous cycle number, starting from 1: %v\n", err)
 			}
+			// Build the trade journal from history the first time (databases created before it existed)
+			if err := logger.backfillTrades(); err != nil {
+				log.Printf("⚠️  Trade journal backfill failed: %v", err)
+			}
+			if supabaseConfig != nil && supabaseConfig.WriteQueue != nil {
+				if err := logger.enableWriteQueue(*supabaseConfig.WriteQueue); err != nil {
+					log.Printf("⚠️  Asynchronous writes unavailable, writing synchronously: %v", err)
+				}
+			}
 			// Try to migrate existing JSON files to database (one-time operation)
 			if !logger.isPostgres {
 				// Only migrate from JSON for SQLite (Supabase should be empty or manually migrated)
@@ -226,131 +304,114 @@ func maskConnectionString(connStr string) string {
 	return "***"
 }
 
-// initDB initializes database table structure
+// PingDB checks the database connection (readiness probe)
+// Returns the storage backend ("postgres", "mysql", "sqlite" or "json") and the ping error (always nil in JSON file mode)
+func (l *DecisionLogger) PingDB(ctx context.Context) (string, error) {
+	if l.db == nil {
+		return "json", nil
+	}
+	backend := "sqlite"
+	if l.isMySQL {
+		backend = "mysql"
+	} else if l.isPostgres {
+		backend = "postgres"
+	}
+	return backend, l.db.PingContext(ctx)
+}
+
+// initDB brings the database schema up to date (see runMigrations)
 func (l *DecisionLogger) initDB() error {
-	var schema string
-	
+	return l.runMigrations()
+}
+
+// addedDecisionColumns columns of the decisions table added after the original schema (AI usage, prompt version)
+var addedDecisionColumns = []struct{ name, definition string }{
+	{"ai_model", "TEXT NOT NULL DEFAULT ''"},
+	{"ai_calls", "INTEGER NOT NULL DEFAULT 0"},
+	{"ai_prompt_tokens", "INTEGER NOT NULL DEFAULT 0"},
+	{"ai_completion_tokens", "INTEGER NOT NULL DEFAULT 0"},
+	{"ai_cost_usd", "REAL NOT NULL DEFAULT 0"},
+	{"prompt_template", "TEXT NOT NULL DEFAULT ''"},
+	{"prompt_version", "TEXT NOT NULL DEFAULT ''"},
+	{"consensus_mode", "TEXT NOT NULL DEFAULT ''"},
+	{"risk_review", "TEXT NOT NULL DEFAULT ''"},
+	{"risk_policy", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// upgradeLegacySchema adds the columns introduced before versioned migrations to databases created before
+// them (run once, ahead of the initial migration)
+func (l *DecisionLogger) upgradeLegacySchema() error {
+	if l.isMySQL {
+		return l.upgradeLegacyMySQLSchema()
+	}
 	if l.isPostgres {
-		// PostgreSQL/Supabase schema (with trader_id column)
-		schema = `
-		CREATE TABLE IF NOT EXISTS decisions (
-			id SERIAL PRIMARY KEY,
-			trader_id TEXT NOT NULL,
-			timestamp TIMESTAMPTZ NOT NULL,
-			cycle_number INTEGER NOT NULL,
-			input_prompt TEXT,
-			cot_trace TEXT,
-			decision_json TEXT,
-			raw_response TEXT,
-			success BOOLEAN NOT NULL DEFAULT true,
-			error_message TEXT,
-			account_total_balance REAL NOT NULL,
-			account_available_balance REAL NOT NULL,
-			account_unrealized_profit REAL NOT NULL,
-			account_position_count INTEGER NOT NULL,
-			account_margin_used_pct REAL NOT NULL,
-			execution_log TEXT,
-			candidate_coins TEXT,
-			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(trader_id, cycle_number)
-		);
-
-		CREATE TABLE IF NOT EXISTS positions (
-			id SERIAL PRIMARY KEY,
-			decision_id INTEGER NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
-			symbol TEXT NOT NULL,
-			side TEXT NOT NULL,
-			position_amt REAL NOT NULL,
-			entry_price REAL NOT NULL,
-			mark_price REAL NOT NULL,
-			unrealized_profit REAL NOT NULL,
-			leverage REAL NOT NULL,
-			liquidation_price REAL NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS decision_actions (
-			id SERIAL PRIMARY KEY,
-			decision_id INTEGER NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
-			action TEXT NOT NULL,
-			symbol TEXT NOT NULL,
-			quantity REAL NOT NULL,
-			leverage INTEGER,
-			price REAL NOT NULL,
-			order_id BIGINT,
-			timestamp TIMESTAMPTZ NOT NULL,
-			success BOOLEAN NOT NULL DEFAULT true,
-			error TEXT
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_decisions_trader_id ON decisions(trader_id);
-		CREATE INDEX IF NOT EXISTS idx_decisions_timestamp ON decisions(timestamp);
-		CREATE INDEX IF NOT EXISTS idx_decisions_cycle ON decisions(trader_id, cycle_number);
-		CREATE INDEX IF NOT EXISTS idx_decisions_success ON decisions(success);
-		CREATE INDEX IF NOT EXISTS idx_positions_decision ON positions(decision_id);
-		CREATE INDEX IF NOT EXISTS idx_actions_decision ON decision_actions(decision_id);
-		`
-	} else {
-		// SQLite schema (backward compatible)
-		schema = `
-		CREATE TABLE IF NOT EXISTS decisions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			timestamp DATETIME NOT NULL,
-			cycle_number INTEGER NOT NULL UNIQUE,
-			input_prompt TEXT,
-			cot_trace TEXT,
-			decision_json TEXT,
-			raw_response TEXT,
-			success BOOLEAN NOT NULL DEFAULT 1,
-			error_message TEXT,
-			account_total_balance REAL NOT NULL,
-			account_available_balance REAL NOT NULL,
-			account_unrealized_profit REAL NOT NULL,
-			account_position_count INTEGER NOT NULL,
-			account_margin_used_pct REAL NOT NULL,
-			execution_log TEXT,
-			candidate_coins TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-
-		CREATE TABLE IF NOT EXISTS positions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			decision_id INTEGER NOT NULL,
-			symbol TEXT NOT NULL,
-			side TEXT NOT NULL,
-			position_amt REAL NOT NULL,
-			entry_price REAL NOT NULL,
-			mark_price REAL NOT NULL,
-			unrealized_profit REAL NOT NULL,
-			leverage REAL NOT NULL,
-			liquidation_price REAL NOT NULL,
-			FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
-		);
-
-		CREATE TABLE IF NOT EXISTS decision_actions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			decision_id INTEGER NOT NULL,
-			action TEXT NOT NULL,
-			symbol TEXT NOT NULL,
-			quantity REAL NOT NULL,
-			leverage INTEGER,
-			price REAL NOT NULL,
-			order_id INTEGER,
-			timestamp DATETIME NOT NULL,
-			success BOOLEAN NOT NULL DEFAULT 1,
-			error TEXT,
-			FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_decisions_timestamp ON decisions(timestamp);
-		CREATE INDEX IF NOT EXISTS idx_decisions_cycle ON decisions(cycle_number);
-		CREATE INDEX IF NOT EXISTS idx_decisions_success ON decisions(success);
-		CREATE INDEX IF NOT EXISTS idx_positions_decision ON positions(decision_id);
-		CREATE INDEX IF NOT EXISTS idx_actions_decision ON decision_actions(decision_id);
-		`
+		if _, err := l.db.Exec(`ALTER TABLE decision_actions ADD COLUMN IF NOT EXISTS client_order_id TEXT`); err != nil {
+			return err
+		}
+		if _, err := l.db.Exec(`ALTER TABLE decision_actions ADD COLUMN IF NOT EXISTS partial BOOLEAN NOT NULL DEFAULT false`); err != nil {
+			return err
+		}
+		for _, col := range addedDecisionColumns {
+			if _, err := l.db.Exec(fmt.Sprintf(`ALTER TABLE decisions ADD COLUMN IF NOT EXISTS %s %s`, col.name, col.definition)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// SQLite has no ADD COLUMN IF NOT EXISTS - check the table info first
+	actionColumns, err := l.sqliteColumns("decision_actions")
+	if err != nil {
+		return err
+	}
+	if !actionColumns["client_order_id"] {
+		if _, err := l.db.Exec(`ALTER TABLE decision_actions ADD COLUMN client_order_id TEXT`); err != nil {
+			return err
+		}
+		log.Printf("✓ Migrated decision_actions: added client_order_id column")
+	}
+	if !actionColumns["partial"] {
+		if _, err := l.db.Exec(`ALTER TABLE decision_actions ADD COLUMN partial BOOLEAN NOT NULL DEFAULT 0`); err != nil {
+			return err
+		}
+		log.Printf("✓ Migrated decision_actions: added partial column")
+	}
+
+	decisionColumns, err := l.sqliteColumns("decisions")
+	if err != nil {
+		return err
+	}
+	for _, col := range addedDecisionColumns {
+		if decisionColumns[col.name] {
+			continue
+		}
+		if _, err := l.db.Exec(fmt.Sprintf(`ALTER TABLE decisions ADD COLUMN %s %s`, col.name, col.definition)); err != nil {
+			return err
+		}
+		log.Printf("✓ Migrated decisions: added %s column", col.name)
+	}
+	return nil
+}
+
+// sqliteColumns column names of a SQLite table
+func (l *DecisionLogger) sqliteColumns(table string) (map[string]bool, error) {
+	rows, err := l.db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	_, err := l.db.Exec(schema)
-	return err
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			continue
+		}
+		columns[name] = true
+	}
+	return columns, nil
 }
 
 // migrateFromJSON migrates from JSON files to database (one-time migration)
@@ -368,7 +429,7 @@ func (l *DecisionLogger) migrateFromJSON() error {
 	maxCycle := 0
 
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -412,9 +473,11 @@ func (l *DecisionLogger) migrateFromJSON() error {
 	}
 
 	// Update cycle number (if larger in database)
+	l.cycleMu.Lock()
 	if maxCycle > l.cycleNumber {
 		l.cycleNumber = maxCycle
 	}
+	l.cycleMu.Unlock()
 
 	return nil
 }
@@ -469,7 +532,7 @@ func (l *DecisionLogger) restoreCycleNumberFromJSON() error {
 
 	maxCycle := 0
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -504,6 +567,15 @@ func (l *DecisionLogger) insertDecisionRecord(record *DecisionRecord) error {
 		return err
 	}
 	defer tx.Rollback()
+	if err := l.insertDecisionRecordTx(tx, record); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertDecisionRecordTx inserts a decision record with its positions, actions and agent debate in tx
+func (l *DecisionLogger) insertDecisionRecordTx(tx *sql.Tx, record *DecisionRecord) error {
+	var err error
 
 	// Serialize array fields to JSON
 	// Optimize: Only keep execution_log for failed decisions (saves ~0.5-2 KB per record)
@@ -527,6 +599,11 @@ func (l *DecisionLogger) insertDecisionRecord(record *DecisionRecord) error {
 		// Only keep it if there was an error for debugging
 		rawResponse = ""
 	}
+
+	var usage AIUsage
+	if record.AIUsage != nil {
+		usage = *record.AIUsage
+	}
 	
 	if l.isPostgres {
 		// PostgreSQL: use RETURNING id to get the inserted ID
@@ -536,14 +613,17 @@ func (l *DecisionLogger) insertDecisionRecord(record *DecisionRecord) error {
 				success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)
 			RETURNING id`,
-			l.traderID, record.Timestamp, record.CycleNumber, record.InputPrompt, record.CoTTrace,
-			record.DecisionJSON, rawResponse, record.Success, record.ErrorMessage,
+			l.traderID, record.Timestamp, record.CycleNumber, encodeText(record.InputPrompt), encodeText(record.CoTTrace),
+			record.DecisionJSON, encodeText(rawResponse), record.Success, record.ErrorMessage,
 			record.AccountState.TotalBalance, record.AccountState.AvailableBalance,
 			record.AccountState.TotalUnrealizedProfit, record.AccountState.PositionCount,
-			record.AccountState.MarginUsedPct, string(executionLogJSON), string(candidateCoinsJSON)).Scan(&decisionID)
+			record.AccountState.MarginUsedPct, string(executionLogJSON), string(candidateCoinsJSON),
+			usage.Model, usage.Calls, usage.PromptTokens, usage.CompletionTokens, usage.CostUSD,
+			record.PromptTemplate, record.PromptVersion, record.ConsensusMode, marshalRiskReview(record.RiskReview), record.RiskPolicy, record.Source).Scan(&decisionID)
 	} else {
 		// SQLite: use Exec + LastInsertId()
 		result, err := tx.Exec(`
@@ -552,13 +632,16 @@ func (l *DecisionLogger) insertDecisionRecord(record *DecisionRecord) error {
 				success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-			record.Timestamp, record.CycleNumber, record.InputPrompt, record.CoTTrace,
-			record.DecisionJSON, rawResponse, record.Success, record.ErrorMessage,
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			record.Timestamp, record.CycleNumber, encodeText(record.InputPrompt), encodeText(record.CoTTrace),
+			record.DecisionJSON, encodeText(rawResponse), record.Success, record.ErrorMessage,
 			record.AccountState.TotalBalance, record.AccountState.AvailableBalance,
 			record.AccountState.TotalUnrealizedProfit, record.AccountState.PositionCount,
-			record.AccountState.MarginUsedPct, string(executionLogJSON), string(candidateCoinsJSON))
+			record.AccountState.MarginUsedPct, string(executionLogJSON), string(candidateCoinsJSON),
+			usage.Model, usage.Calls, usage.PromptTokens, usage.CompletionTokens, usage.CostUSD,
+			record.PromptTemplate, record.PromptVersion, record.ConsensusMode, marshalRiskReview(record.RiskReview), record.RiskPolicy, record.Source)
 		
 		if err != nil {
 			return err
@@ -601,32 +684,42 @@ func (l *DecisionLogger) insertDecisionRecord(record *DecisionRecord) error {
 			_, err = tx.Exec(`
 				INSERT INTO decision_actions (
 					decision_id, action, symbol, quantity, leverage, price, order_id,
-					timestamp, success, error
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+					timestamp, success, error, client_order_id, partial, fee, realized_pnl
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
 				decisionID, action.Action, action.Symbol, action.Quantity, action.Leverage,
-				action.Price, action.OrderID, action.Timestamp, action.Success, action.Error)
+				action.Price, action.OrderID, action.Timestamp, action.Success, action.Error,
+				action.ClientOrderID, action.Partial, action.Fee, action.RealizedPnL)
 		} else {
 			_, err = tx.Exec(`
 				INSERT INTO decision_actions (
 					decision_id, action, symbol, quantity, leverage, price, order_id,
-					timestamp, success, error
-				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+					timestamp, success, error, client_order_id, partial, fee, realized_pnl
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 				decisionID, action.Action, action.Symbol, action.Quantity, action.Leverage,
-				action.Price, action.OrderID, action.Timestamp, action.Success, action.Error)
+				action.Price, action.OrderID, action.Timestamp, action.Success, action.Error,
+				action.ClientOrderID, action.Partial, action.Fee, action.RealizedPnL)
 		}
 		if err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	if record.AgentDebate != nil {
+		if err := l.insertAgentDebate(tx, decisionID, record.AgentDebate); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // LogDecision logs decision
 func (l *DecisionLogger) LogDecision(record *DecisionRecord) error {
 	// Safety check: Verify cycle number with database before logging
 	// This prevents issues if database was reset while backend was running
-	if l.db != nil && l.isPostgres {
+	// (skipped with the write queue: the check would block on the database the queue keeps out of the cycle)
+	l.cycleMu.Lock()
+	if l.db != nil && l.isPostgres && l.queue == nil {
 		var maxCycle sql.NullInt64
 		err := l.db.QueryRow("SELECT MAX(cycle_number) FROM decisions WHERE trader_id = $1", l.traderID).Scan(&maxCycle)
 		if err == nil && maxCycle.Valid {
@@ -653,7 +746,14 @@ func (l *DecisionLogger) LogDecision(record *DecisionRecord) error {
 	
 	l.cycleNumber++
 	record.CycleNumber = l.cycleNumber
+	l.cycleMu.Unlock()
 	record.Timestamp = time.Now()
+	if l.sink != nil {
+		defer l.sink(l.traderID, record)
+	}
+	if l.queue != nil {
+		return l.enqueueRecord(record)
+	}
 
 	// If database is available, use database; otherwise fallback to JSON file
 	if l.db != nil {
@@ -663,6 +763,9 @@ func (l *DecisionLogger) LogDecision(record *DecisionRecord) error {
 			return l.logDecisionToJSON(record)
 		}
 		fmt.Printf("📝 Decision record saved to database: cycle #%d (trader: %s)\n", record.CycleNumber, l.traderID)
+		if err := l.recordTrades(record); err != nil {
+			log.Printf("⚠️  Trade journal update failed (cycle #%d): %v", record.CycleNumber, err)
+		}
 		return nil
 	}
 
@@ -670,6 +773,11 @@ func (l *DecisionLogger) LogDecision(record *DecisionRecord) error {
 	return l.logDecisionToJSON(record)
 }
 
+// SetRecordSink sends every record logged from now on to sink (set before the trader starts)
+func (l *DecisionLogger) SetRecordSink(sink RecordSink) {
+	l.sink = sink
+}
+
 // logDecisionToJSON saves decision record to JSON file (fallback method)
 func (l *DecisionLogger) logDecisionToJSON(record *DecisionRecord) error {
 	filename := fmt.Sprintf("decision_%s_cycle%d.json",
@@ -690,6 +798,15 @@ func (l *DecisionLogger) logDecisionToJSON(record *DecisionRecord) error {
 	return nil
 }
 
+// decisionFilePattern names of the files logDecisionToJSON writes
+var decisionFilePattern = regexp.MustCompile(`^decision_\d{8}_\d{6}_cycle\d+\.json$`)
+
+// isDecisionFile whether a log directory entry is a record written by logDecisionToJSON (the directory also holds
+// the trader's state files, e.g. pnl_ledger.json and decision_quality.json)
+func isDecisionFile(file os.FileInfo) bool {
+	return !file.IsDir() && decisionFilePattern.MatchString(file.Name())
+}
+
 // GetFirstRecord gets first record (cycle #1, used to restore original initial balance)
 func (l *DecisionLogger) GetFirstRecord() (*DecisionRecord, error) {
 	if l.db != nil {
@@ -709,7 +826,8 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 				raw_response, success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 			FROM decisions
 			WHERE trader_id = $1 AND cycle_number = 0
 			ORDER BY timestamp ASC
@@ -721,7 +839,8 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 				raw_response, success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 			FROM decisions
 			WHERE cycle_number = 0
 			ORDER BY timestamp ASC
@@ -731,8 +850,9 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 
 	decisionID := int64(0)
 	record := &DecisionRecord{}
-	var executionLogJSON, candidateCoinsJSON string
+	var executionLogJSON, candidateCoinsJSON, riskReviewJSON string
 	var accountState AccountSnapshot
+	var usage AIUsage
 
 	err := row.Scan(
 		&decisionID,
@@ -751,6 +871,17 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 		&accountState.MarginUsedPct,
 		&executionLogJSON,
 		&candidateCoinsJSON,
+		&usage.Model,
+		&usage.Calls,
+		&usage.PromptTokens,
+		&usage.CompletionTokens,
+		&usage.CostUSD,
+		&record.PromptTemplate,
+		&record.PromptVersion,
+		&record.ConsensusMode,
+		&riskReviewJSON,
+		&record.RiskPolicy,
+		&record.Source,
 	)
 	
 	// If cycle #1 not found, try to get the earliest record by timestamp
@@ -762,7 +893,8 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 					raw_response, success, error_message,
 					account_total_balance, account_available_balance, account_unrealized_profit,
 					account_position_count, account_margin_used_pct,
-					execution_log, candidate_coins
+					execution_log, candidate_coins,
+					ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 				FROM decisions
 				WHERE trader_id = $1
 				ORDER BY timestamp ASC
@@ -774,7 +906,8 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 					raw_response, success, error_message,
 					account_total_balance, account_available_balance, account_unrealized_profit,
 					account_position_count, account_margin_used_pct,
-					execution_log, candidate_coins
+					execution_log, candidate_coins,
+					ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 				FROM decisions
 				ORDER BY timestamp ASC
 				LIMIT 1
@@ -798,6 +931,17 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 			&accountState.MarginUsedPct,
 			&executionLogJSON,
 			&candidateCoinsJSON,
+			&usage.Model,
+			&usage.Calls,
+			&usage.PromptTokens,
+			&usage.CompletionTokens,
+			&usage.CostUSD,
+			&record.PromptTemplate,
+			&record.PromptVersion,
+			&record.ConsensusMode,
+			&riskReviewJSON,
+			&record.RiskPolicy,
+			&record.Source,
 		)
 	}
 	
@@ -805,12 +949,18 @@ func (l *DecisionLogger) getFirstRecordFromDB() (*DecisionRecord, error) {
 		return nil, fmt.Errorf("failed to get first record: %w", err)
 	}
 
+	record.InputPrompt = decodeText(record.InputPrompt)
+	record.CoTTrace = decodeText(record.CoTTrace)
+	record.RawResponse = decodeText(record.RawResponse)
 	record.AccountState = accountState
+	record.AIUsage = usage.orNil()
+	record.RiskReview = unmarshalRiskReview(riskReviewJSON)
 	json.Unmarshal([]byte(executionLogJSON), &record.ExecutionLog)
 	json.Unmarshal([]byte(candidateCoinsJSON), &record.CandidateCoins)
 
 	record.Positions, _ = l.loadPositions(decisionID)
 	record.Decisions, _ = l.loadDecisionActions(decisionID)
+	record.ID = decisionID
 
 	return record, nil
 }
@@ -826,7 +976,7 @@ func (l *DecisionLogger) getFirstRecordFromJSON() (*DecisionRecord, error) {
 	minCycle := 999999
 
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -881,7 +1031,8 @@ func (l *DecisionLogger) getAllRecordsFromDB() ([]*DecisionRecord, error) {
 				raw_response, success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 			FROM decisions
 			WHERE trader_id = $1
 			ORDER BY timestamp ASC
@@ -893,7 +1044,8 @@ func (l *DecisionLogger) getAllRecordsFromDB() ([]*DecisionRecord, error) {
 				raw_response, success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 			FROM decisions
 			ORDER BY timestamp ASC
 		`)
@@ -925,7 +1077,7 @@ func (l *DecisionLogger) getAllRecordsFromJSON() ([]*DecisionRecord, error) {
 
 	var records []*DecisionRecord
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -943,10 +1095,13 @@ func (l *DecisionLogger) getAllRecordsFromJSON() ([]*DecisionRecord, error) {
 		records = append(records, &record)
 	}
 
-	// Sort by time (from old to new)
-	sort.Slice(records, func(i, j int) bool {
+	// Sort by time (from old to new), records with the same time in file name order
+	sort.SliceStable(records, func(i, j int) bool {
 		return records[i].Timestamp.Before(records[j].Timestamp)
 	})
+	for i, record := range records {
+		record.ID = int64(i + 1)
+	}
 
 	return records, nil
 }
@@ -978,7 +1133,8 @@ func (l *DecisionLogger) getLatestRecordsFromDB(n int) ([]*DecisionRecord, error
 				raw_response, success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 			FROM decisions
 			WHERE trader_id = $1
 			ORDER BY timestamp DESC
@@ -991,7 +1147,8 @@ func (l *DecisionLogger) getLatestRecordsFromDB(n int) ([]*DecisionRecord, error
 				raw_response, success, error_message,
 				account_total_balance, account_available_balance, account_unrealized_profit,
 				account_position_count, account_margin_used_pct,
-				execution_log, candidate_coins
+				execution_log, candidate_coins,
+				ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 			FROM decisions
 			ORDER BY timestamp DESC
 			LIMIT ?
@@ -1031,7 +1188,7 @@ func (l *DecisionLogger) getLatestRecordsFromJSON(n int) ([]*DecisionRecord, err
 	count := 0
 	for i := len(files) - 1; i >= 0 && count < n; i-- {
 		file := files[i]
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -1062,8 +1219,9 @@ func (l *DecisionLogger) getLatestRecordsFromJSON(n int) ([]*DecisionRecord, err
 func (l *DecisionLogger) scanDecisionRecord(rows *sql.Rows) (*DecisionRecord, error) {
 	var record DecisionRecord
 	var decisionID int64
-	var executionLogJSON, candidateCoinsJSON string
+	var executionLogJSON, candidateCoinsJSON, riskReviewJSON string
 	var accountState AccountSnapshot
+	var usage AIUsage
 
 	err := rows.Scan(
 		&decisionID,
@@ -1082,12 +1240,28 @@ func (l *DecisionLogger) scanDecisionRecord(rows *sql.Rows) (*DecisionRecord, er
 		&accountState.MarginUsedPct,
 		&executionLogJSON,
 		&candidateCoinsJSON,
+		&usage.Model,
+		&usage.Calls,
+		&usage.PromptTokens,
+		&usage.CompletionTokens,
+		&usage.CostUSD,
+		&record.PromptTemplate,
+		&record.PromptVersion,
+		&record.ConsensusMode,
+		&riskReviewJSON,
+		&record.RiskPolicy,
+		&record.Source,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	record.InputPrompt = decodeText(record.InputPrompt)
+	record.CoTTrace = decodeText(record.CoTTrace)
+	record.RawResponse = decodeText(record.RawResponse)
 	record.AccountState = accountState
+	record.AIUsage = usage.orNil()
+	record.RiskReview = unmarshalRiskReview(riskReviewJSON)
 
 	// Parse JSON array
 	json.Unmarshal([]byte(executionLogJSON), &record.ExecutionLog)
@@ -1096,6 +1270,7 @@ func (l *DecisionLogger) scanDecisionRecord(rows *sql.Rows) (*DecisionRecord, er
 	// Load associated positions and actions
 	record.Positions, _ = l.loadPositions(decisionID)
 	record.Decisions, _ = l.loadDecisionActions(decisionID)
+	record.ID = decisionID
 
 	return &record, nil
 }
@@ -1147,7 +1322,7 @@ func (l *DecisionLogger) loadDecisionActions(decisionID int64) ([]DecisionAction
 	if l.isPostgres {
 		rows, err = l.db.Query(`
 			SELECT action, symbol, quantity, leverage, price, order_id,
-				timestamp, success, error
+				timestamp, success, error, COALESCE(client_order_id, ''), partial, fee, realized_pnl
 			FROM decision_actions
 			WHERE decision_id = $1
 			ORDER BY timestamp
@@ -1155,7 +1330,7 @@ func (l *DecisionLogger) loadDecisionActions(decisionID int64) ([]DecisionAction
 	} else {
 		rows, err = l.db.Query(`
 			SELECT action, symbol, quantity, leverage, price, order_id,
-				timestamp, success, error
+				timestamp, success, error, COALESCE(client_order_id, ''), partial, fee, realized_pnl
 			FROM decision_actions
 			WHERE decision_id = ?
 			ORDER BY timestamp
@@ -1169,17 +1344,177 @@ func (l *DecisionLogger) loadDecisionActions(decisionID int64) ([]DecisionAction
 	var actions []DecisionAction
 	for rows.Next() {
 		var action DecisionAction
+		var realizedPnL sql.NullFloat64
 		if err := rows.Scan(
 			&action.Action, &action.Symbol, &action.Quantity, &action.Leverage,
 			&action.Price, &action.OrderID, &action.Timestamp, &action.Success, &action.Error,
+			&action.ClientOrderID, &action.Partial, &action.Fee, &realizedPnL,
 		); err != nil {
 			continue
 		}
+		if realizedPnL.Valid {
+			action.RealizedPnL = &realizedPnL.Float64
+		}
 		actions = append(actions, action)
 	}
 	return actions, nil
 }
 
+// LoggedAction a decision action with the cycle it was executed in
+type LoggedAction struct {
+	CycleNumber int `json:"cycle_number"`
+	DecisionAction
+}
+
+// GetActionsInRange gets decision actions executed between start and end (oldest first)
+func (l *DecisionLogger) GetActionsInRange(start, end time.Time) ([]LoggedAction, error) {
+	if l.db != nil {
+		return l.getActionsInRangeFromDB(start, end)
+	}
+
+	// Fallback to JSON files
+	return l.getActionsInRangeFromJSON(start, end)
+}
+
+// getActionsInRangeFromDB gets decision actions from database for a time range
+func (l *DecisionLogger) getActionsInRangeFromDB(start, end time.Time) ([]LoggedAction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var rows *sql.Rows
+	var err error
+
+	if l.isPostgres {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT d.cycle_number, a.action, a.symbol, a.quantity, a.leverage, a.price, a.order_id,
+				a.timestamp, a.success, a.error, COALESCE(a.client_order_id, ''), a.partial, a.fee, a.realized_pnl
+			FROM decision_actions a
+			JOIN decisions d ON d.id = a.decision_id
+			WHERE d.trader_id = $1 AND a.timestamp >= $2 AND a.timestamp <= $3
+			ORDER BY a.timestamp ASC
+		`, l.traderID, start, end)
+	} else {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT d.cycle_number, a.action, a.symbol, a.quantity, a.leverage, a.price, a.order_id,
+				a.timestamp, a.success, a.error, COALESCE(a.client_order_id, ''), a.partial, a.fee, a.realized_pnl
+			FROM decision_actions a
+			JOIN decisions d ON d.id = a.decision_id
+			WHERE a.timestamp >= ? AND a.timestamp <= ?
+			ORDER BY a.timestamp ASC
+		`, start, end)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []LoggedAction
+	for rows.Next() {
+		var action LoggedAction
+		var errMsg sql.NullString
+		var realizedPnL sql.NullFloat64
+		if err := rows.Scan(
+			&action.CycleNumber, &action.Action, &action.Symbol, &action.Quantity, &action.Leverage,
+			&action.Price, &action.OrderID, &action.Timestamp, &action.Success, &errMsg,
+			&action.ClientOrderID, &action.Partial, &action.Fee, &realizedPnL,
+		); err != nil {
+			continue
+		}
+		action.Error = errMsg.String
+		if realizedPnL.Valid {
+			action.RealizedPnL = &realizedPnL.Float64
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// getActionsInRangeFromJSON gets decision actions from JSON files for a time range (fallback method)
+func (l *DecisionLogger) getActionsInRangeFromJSON(start, end time.Time) ([]LoggedAction, error) {
+	records, err := l.getAllRecordsFromJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []LoggedAction
+	for _, record := range records {
+		for _, action := range record.Decisions {
+			if action.Timestamp.Before(start) || action.Timestamp.After(end) {
+				continue
+			}
+			actions = append(actions, LoggedAction{CycleNumber: record.CycleNumber, DecisionAction: action})
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Timestamp.Before(actions[j].Timestamp) })
+	return actions, nil
+}
+
+// GetDecisionJSONs gets the decision JSON of the given cycles (cycle number -> decision JSON; cycles without a
+// record are left out)
+func (l *DecisionLogger) GetDecisionJSONs(cycles []int) (map[int]string, error) {
+	result := make(map[int]string)
+	if len(cycles) == 0 {
+		return result, nil
+	}
+
+	if l.db == nil {
+		// Fallback to JSON files
+		wanted := make(map[int]bool)
+		for _, cycle := range cycles {
+			wanted[cycle] = true
+		}
+		records, err := l.getAllRecordsFromJSON()
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if wanted[record.CycleNumber] {
+				result[record.CycleNumber] = record.DecisionJSON
+			}
+		}
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	placeholders := make([]string, len(cycles))
+	args := make([]interface{}, 0, len(cycles)+1)
+	if l.isPostgres {
+		args = append(args, l.traderID)
+	}
+	for i, cycle := range cycles {
+		placeholders[i] = "?"
+		args = append(args, cycle)
+	}
+
+	var rows *sql.Rows
+	var err error
+	if l.isPostgres {
+		rows, err = l.db.QueryContext(ctx, postgresPlaceholders(`
+			SELECT cycle_number, COALESCE(decision_json, '') FROM decisions
+			WHERE trader_id = ? AND cycle_number IN (`+strings.Join(placeholders, ", ")+`)`), args...)
+	} else {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT cycle_number, COALESCE(decision_json, '') FROM decisions
+			WHERE cycle_number IN (`+strings.Join(placeholders, ", ")+`)`, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cycle int
+		var decisionJSON string
+		if err := rows.Scan(&cycle, &decisionJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		result[cycle] = decisionJSON
+	}
+	return result, rows.Err()
+}
+
 // GetRecordByDate gets all records for specified date
 func (l *DecisionLogger) GetRecordByDate(date time.Time) ([]*DecisionRecord, error) {
 	if l.db != nil {
@@ -1200,7 +1535,8 @@ func (l *DecisionLogger) getRecordByDateFromDB(date time.Time) ([]*DecisionRecor
 			raw_response, success, error_message,
 			account_total_balance, account_available_balance, account_unrealized_profit,
 			account_position_count, account_margin_used_pct,
-			execution_log, candidate_coins
+			execution_log, candidate_coins,
+			ai_model, ai_calls, ai_prompt_tokens, ai_completion_tokens, ai_cost_usd, prompt_template, prompt_version, consensus_mode, risk_review, risk_policy, source
 		FROM decisions
 		WHERE timestamp >= ? AND timestamp < ?
 		ORDER BY timestamp ASC
@@ -1282,7 +1618,7 @@ func (l *DecisionLogger) cleanOldRecordsFromJSON(days int) error {
 
 	removedCount := 0
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -1397,6 +1733,22 @@ func (l *DecisionLogger) getStatisticsFromDB() (*Statistics, error) {
 		return nil, fmt.Errorf("failed to query open/close position count: %w", err)
 	}
 
+	// AI token usage and estimated cost
+	usageQuery := `
+		SELECT COALESCE(SUM(ai_calls), 0), COALESCE(SUM(ai_prompt_tokens), 0),
+			COALESCE(SUM(ai_completion_tokens), 0), COALESCE(SUM(ai_cost_usd), 0)
+		FROM decisions
+	`
+	var usageArgs []interface{}
+	if l.isPostgres {
+		usageQuery += " WHERE trader_id = $1"
+		usageArgs = append(usageArgs, l.traderID)
+	}
+	err = l.db.QueryRowContext(ctx, usageQuery, usageArgs...).Scan(&stats.AICalls, &stats.PromptTokens, &stats.CompletionTokens, &stats.AICostUSD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AI usage: %w", err)
+	}
+
 	return stats, nil
 }
 
@@ -1410,7 +1762,7 @@ func (l *DecisionLogger) getStatisticsFromJSON() (*Statistics, error) {
 	stats := &Statistics{}
 
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if !isDecisionFile(file) {
 			continue
 		}
 
@@ -1443,6 +1795,13 @@ func (l *DecisionLogger) getStatisticsFromJSON() (*Statistics, error) {
 		} else {
 			stats.FailedCycles++
 		}
+
+		if record.AIUsage != nil {
+			stats.AICalls += record.AIUsage.Calls
+			stats.PromptTokens += record.AIUsage.PromptTokens
+			stats.CompletionTokens += record.AIUsage.CompletionTokens
+			stats.AICostUSD += record.AIUsage.CostUSD
+		}
 	}
 
 	return stats, nil
@@ -1455,6 +1814,12 @@ type Statistics struct {
 	FailedCycles        int `json:"failed_cycles"`
 	TotalOpenPositions  int `json:"total_open_positions"`
 	TotalClosePositions int `json:"total_close_positions"`
+
+	// AI token usage and estimated cost over all cycles
+	AICalls          int     `json:"ai_calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	AICostUSD        float64 `json:"ai_cost_usd"`
 }
 
 // TradeOutcome single trade result
@@ -1489,6 +1854,20 @@ type PerformanceAnalysis struct {
 	SymbolStats   map[string]*SymbolPerformance `json:"symbol_stats"`   // Performance by symbol
 	BestSymbol    string                        `json:"best_symbol"`    // Best performing symbol
 	WorstSymbol   string                        `json:"worst_symbol"`   // Worst performing symbol
+
+	// Equity curve over the analyzed cycles (see performance_metrics.go)
+	StartTime      time.Time            `json:"start_time"`
+	EndTime        time.Time            `json:"end_time"`
+	ReturnPct      float64              `json:"return_pct"`          // Equity change from the first to the last cycle
+	MaxDrawdownPct float64              `json:"max_drawdown_pct"`    // Largest peak-to-trough equity drop
+	SortinoRatio   float64              `json:"sortino_ratio"`       // Like the Sharpe ratio, counting only losing cycles as risk
+	ExposurePct    float64              `json:"exposure_pct"`        // Share of the time with at least one open position
+	Benchmark      *BenchmarkComparison `json:"benchmark,omitempty"` // Buy-and-hold comparison (CompareToBenchmark)
+
+	AvgDrawdownHours float64  `json:"avg_drawdown_hours"`       // Average time from a peak until equity recovered it (an ongoing drawdown counts until now)
+	CalmarRatio      float64  `json:"calmar_ratio"`             // Annualized return / max drawdown (windows of 24h or more)
+	Return24hPct     *float64 `json:"return_24h_pct,omitempty"` // Equity change over the last 24h (history covering it only)
+	Return7dPct      *float64 `json:"return_7d_pct,omitempty"`  // Equity change over the last 7 days (history covering it only)
 }
 
 // SymbolPerformance symbol performance statistics
@@ -1505,6 +1884,11 @@ type SymbolPerformance struct {
 // AnalyzePerformance analyzes trading performance
 // If lookbackCycles <= 0, analyze all historical records
 func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAnalysis, error) {
+	// Closed trades come from the trade journal; JSON file mode reconstructs them from the records below
+	if l.db != nil {
+		return l.analyzePerformanceFromJournal(lookbackCycles)
+	}
+
 	var records []*DecisionRecord
 	var err error
 
@@ -1565,12 +1949,15 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 				if !action.Success {
 					continue
 				}
+				if action.Partial {
+					continue // Partial close: the trade stays open until the final close
+				}
 
 				symbol := action.Symbol
 				side := ""
-				if action.Action == "open_long" || action.Action == "close_long" {
+				if action.Action == "open_long" || action.Action == "add_long" || action.Action == "close_long" {
 					side = "long"
-				} else if action.Action == "open_short" || action.Action == "close_short" {
+				} else if action.Action == "open_short" || action.Action == "add_short" || action.Action == "close_short" {
 					side = "short"
 				}
 				if side == "" {
@@ -1587,6 +1974,8 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 						"quantity":  action.Quantity,
 						"leverage":  action.Leverage,
 					}
+				case "add_long", "add_short":
+					addToOpenPosition(openPositions, posKey, action)
 				case "close_long", "close_short":
 					// Only delete if it exists - this handles the case where we're tracking
 					// positions opened before the analysis window
@@ -1605,12 +1994,15 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 			if !action.Success {
 				continue
 			}
+			if action.Partial {
+				continue // Partial close: the trade stays open until the final close
+			}
 
 			symbol := action.Symbol
 			side := ""
-			if action.Action == "open_long" || action.Action == "close_long" {
+			if action.Action == "open_long" || action.Action == "add_long" || action.Action == "close_long" {
 				side = "long"
-			} else if action.Action == "open_short" || action.Action == "close_short" {
+			} else if action.Action == "open_short" || action.Action == "add_short" || action.Action == "close_short" {
 				side = "short"
 			}
 			if side == "" {
@@ -1629,6 +2021,9 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 					"leverage":  action.Leverage,
 				}
 
+			case "add_long", "add_short":
+				addToOpenPosition(openPositions, posKey, action)
+
 			case "close_long", "close_short":
 				// Match close with corresponding open position
 				// If no matching open found in current tracking, try to find it from earlier in records
@@ -1722,30 +2117,7 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 						CloseTime:     action.Timestamp,
 					}
 
-					analysis.RecentTrades = append(analysis.RecentTrades, outcome)
-					analysis.TotalTrades++
-
-					if pnl > 0 {
-						analysis.WinningTrades++
-						analysis.AvgWin += pnl
-					} else if pnl < 0 {
-						analysis.LosingTrades++
-						analysis.AvgLoss += pnl
-					}
-
-					if _, exists := analysis.SymbolStats[symbol]; !exists {
-						analysis.SymbolStats[symbol] = &SymbolPerformance{
-							Symbol: symbol,
-						}
-					}
-					stats := analysis.SymbolStats[symbol]
-					stats.TotalTrades++
-					stats.TotalPnL += pnl
-					if pnl > 0 {
-						stats.WinningTrades++
-					} else if pnl < 0 {
-						stats.LosingTrades++
-					}
+					countTradeOutcome(analysis, outcome)
 
 					delete(openPositions, posKey)
 				}
@@ -1753,6 +2125,55 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 		}
 	}
 
+	finishPerformanceAnalysis(analysis)
+	applyEquityMetrics(analysis, equityPoints(records))
+
+	return analysis, nil
+}
+
+// addToOpenPosition averages an add_long / add_short into its tracked open position (skipped when the position
+// was opened before the records)
+func addToOpenPosition(openPositions map[string]map[string]interface{}, posKey string, action DecisionAction) {
+	openPos, exists := openPositions[posKey]
+	if !exists || action.Quantity <= 0 {
+		return
+	}
+	quantity := openPos["quantity"].(float64)
+	openPos["openPrice"] = (openPos["openPrice"].(float64)*quantity + action.Price*action.Quantity) / (quantity + action.Quantity)
+	openPos["quantity"] = quantity + action.Quantity
+}
+
+// countTradeOutcome adds a closed trade to the analysis totals and its symbol's stats
+func countTradeOutcome(analysis *PerformanceAnalysis, outcome TradeOutcome) {
+	analysis.RecentTrades = append(analysis.RecentTrades, outcome)
+	analysis.TotalTrades++
+
+	if outcome.PnL > 0 {
+		analysis.WinningTrades++
+		analysis.AvgWin += outcome.PnL
+	} else if outcome.PnL < 0 {
+		analysis.LosingTrades++
+		analysis.AvgLoss += outcome.PnL
+	}
+
+	if _, exists := analysis.SymbolStats[outcome.Symbol]; !exists {
+		analysis.SymbolStats[outcome.Symbol] = &SymbolPerformance{
+			Symbol: outcome.Symbol,
+		}
+	}
+	stats := analysis.SymbolStats[outcome.Symbol]
+	stats.TotalTrades++
+	stats.TotalPnL += outcome.PnL
+	if outcome.PnL > 0 {
+		stats.WinningTrades++
+	} else if outcome.PnL < 0 {
+		stats.LosingTrades++
+	}
+}
+
+// finishPerformanceAnalysis derives win rate, averages, per-symbol stats and the recent trade list (newest first)
+// from the counted trades
+func finishPerformanceAnalysis(analysis *PerformanceAnalysis) {
 	// Calculate statistics
 	if analysis.TotalTrades > 0 {
 		analysis.WinRate = (float64(analysis.WinningTrades) / float64(analysis.TotalTrades)) * 100
@@ -1804,26 +2225,10 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 			analysis.RecentTrades[i], analysis.RecentTrades[j] = analysis.RecentTrades[j], analysis.RecentTrades[i]
 		}
 	}
-
-	analysis.SharpeRatio = l.calculateSharpeRatio(records)
-
-	return analysis, nil
 }
 
-// calculateSharpeRatio calculates Sharpe ratio
-func (l *DecisionLogger) calculateSharpeRatio(records []*DecisionRecord) float64 {
-	if len(records) < 2 {
-		return 0.0
-	}
-
-	var equities []float64
-	for _, record := range records {
-		equity := record.AccountState.TotalBalance
-		if equity > 0 {
-			equities = append(equities, equity)
-		}
-	}
-
+// sharpeRatio Sharpe ratio of the per-cycle returns of an equity series (oldest first)
+func sharpeRatio(equities []float64) float64 {
 	if len(equities) < 2 {
 		return 0.0
 	}
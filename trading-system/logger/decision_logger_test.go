@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONRecordsSkipTraderStateFiles(t *testing.T) {
+	dir := t.TempDir()
+	l := &DecisionLogger{logDir: dir}
+	if err := l.logDecisionToJSON(&DecisionRecord{Timestamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), CycleNumber: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// State files the trader keeps in the same directory
+	for _, name := range []string{"pnl_ledger.json", "decision_quality.json", "risk_state.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`{"events": []}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := l.getAllRecordsFromJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].CycleNumber != 1 {
+		t.Fatalf("%d records, want only the cycle 1 decision", len(records))
+	}
+}
@@ -0,0 +1,227 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecisionPageQuery a page of decision records, counted from the newest
+type DecisionPageQuery struct {
+	Limit  int            // Records per page (<= 0 = all)
+	Offset int            // Newest records to skip (with a Limit, not with a cursor)
+	Before DecisionCursor // Only records older than the cursor (zero = from the newest)
+
+	// Large text fields, left empty unless requested
+	InputPrompt bool
+	CoTTrace    bool
+	RawResponse bool
+}
+
+// DecisionPage one page of decision records
+type DecisionPage struct {
+	Records    []*DecisionRecord // Oldest first, like GetLatestRecords
+	Total      int               // Records of the trader, regardless of the page
+	NextCursor DecisionCursor    // Before of the next (older) page (zero = this was the last one)
+}
+
+// DecisionCursor the position of a record in the newest-first history: its timestamp, then its ID among records
+// with the same timestamp (a timestamp alone would skip the records sharing the oldest timestamp of a page)
+type DecisionCursor struct {
+	Timestamp time.Time
+	ID        int64 // 0 = excludes every record at Timestamp (cursors from before IDs were added)
+}
+
+// IsZero whether the cursor is unset
+func (c DecisionCursor) IsZero() bool {
+	return c.Timestamp.IsZero()
+}
+
+// String the cursor as passed to the API: "<RFC 3339 timestamp>_<ID>"
+func (c DecisionCursor) String() string {
+	return c.Timestamp.Format(time.RFC3339Nano) + "_" + strconv.FormatInt(c.ID, 10)
+}
+
+// ParseDecisionCursor parses a cursor built by String (a bare timestamp is accepted too)
+func ParseDecisionCursor(s string) (DecisionCursor, error) {
+	timestamp, id := s, int64(0)
+	if i := strings.LastIndex(s, "_"); i >= 0 {
+		var err error
+		if id, err = strconv.ParseInt(s[i+1:], 10, 64); err != nil || id < 0 {
+			return DecisionCursor{}, fmt.Errorf("invalid cursor ID %q", s[i+1:])
+		}
+		timestamp = s[:i]
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return DecisionCursor{}, err
+	}
+	return DecisionCursor{Timestamp: t, ID: id}, nil
+}
+
+// precedes whether the record is older than the cursor, i.e. belongs to the following pages
+func (c DecisionCursor) precedes(record *DecisionRecord) bool {
+	if record.Timestamp.Before(c.Timestamp) {
+		return true
+	}
+	return c.ID > 0 && record.Timestamp.Equal(c.Timestamp) && record.ID < c.ID
+}
+
+// errOffsetWithCursor an offset counts from the newest record, a cursor from the end of the previous page
+var errOffsetWithCursor = errors.New("offset and cursor cannot be combined")
+
+// GetDecisionPage loads a page of decision records. Prompts and responses are only read when requested, so
+// listing a long history stays small
+func (l *DecisionLogger) GetDecisionPage(q DecisionPageQuery) (*DecisionPage, error) {
+	if q.Offset > 0 && !q.Before.IsZero() {
+		return nil, errOffsetWithCursor
+	}
+	var page *DecisionPage
+	var err error
+	if l.db != nil {
+		page, err = l.getDecisionPageFromDB(q)
+	} else {
+		page, err = l.getDecisionPageFromJSON(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The backends read one record past the page: when it exists there is an older page, starting after the
+	// oldest record of this one
+	records := page.Records
+	hasMore := q.Limit > 0 && len(records) > q.Limit
+	if hasMore {
+		records = records[:q.Limit]
+	}
+	// Reverse to oldest first
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	page.Records = records
+	if hasMore {
+		page.NextCursor = DecisionCursor{Timestamp: records[0].Timestamp, ID: records[0].ID}
+	}
+	return page, nil
+}
+
+// decisionPageColumns the decision columns scanDecisionRecord reads, with unrequested text fields blanked
+func decisionPageColumns(q DecisionPageQuery) string {
+	text := func(column string, include bool) string {
+		if include {
+			return column
+		}
+		return "'' AS " + column
+	}
+	return strings.Join([]string{
+		"id", "timestamp", "cycle_number",
+		text("input_prompt", q.InputPrompt), text("cot_trace", q.CoTTrace), "decision_json",
+		text("raw_response", q.RawResponse), "success", "error_message",
+		"account_total_balance", "account_available_balance", "account_unrealized_profit",
+		"account_position_count", "account_margin_used_pct",
+		"execution_log", "candidate_coins",
+		"ai_model", "ai_calls", "ai_prompt_tokens", "ai_completion_tokens", "ai_cost_usd",
+		"prompt_template", "prompt_version", "consensus_mode", "risk_review", "risk_policy", "source",
+	}, ", ")
+}
+
+// getDecisionPageFromDB newest first (by timestamp, then ID), up to q.Limit+1 records (the extra one tells whether
+// an older page exists)
+func (l *DecisionLogger) getDecisionPageFromDB(q DecisionPageQuery) (*DecisionPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Placeholders: $n on PostgreSQL (which also filters by trader), ? on SQLite
+	var where []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		if l.isPostgres {
+			return fmt.Sprintf("$%d", len(args))
+		}
+		return "?"
+	}
+	if l.isPostgres {
+		where = append(where, "trader_id = "+arg(l.traderID))
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	page := &DecisionPage{}
+	if err := l.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM decisions"+whereSQL, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("count failed: %w", err)
+	}
+
+	if !q.Before.IsZero() {
+		if q.Before.ID > 0 {
+			where = append(where, "(timestamp < "+arg(q.Before.Timestamp)+" OR (timestamp = "+arg(q.Before.Timestamp)+
+				" AND id < "+arg(q.Before.ID)+"))")
+		} else {
+			where = append(where, "timestamp < "+arg(q.Before.Timestamp))
+		}
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+	query := "SELECT " + decisionPageColumns(q) + " FROM decisions" + whereSQL + " ORDER BY timestamp DESC, id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT " + arg(q.Limit+1)
+		if q.Offset > 0 {
+			query += " OFFSET " + arg(q.Offset)
+		}
+	}
+
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := l.scanDecisionRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision record: %w", err)
+		}
+		page.Records = append(page.Records, record)
+	}
+	return page, rows.Err()
+}
+
+// getDecisionPageFromJSON newest first, up to q.Limit+1 records like getDecisionPageFromDB (JSON file mode reads
+// every record)
+func (l *DecisionLogger) getDecisionPageFromJSON(q DecisionPageQuery) (*DecisionPage, error) {
+	all, err := l.getAllRecordsFromJSON()
+	if err != nil {
+		return nil, err
+	}
+	page := &DecisionPage{Total: len(all)}
+
+	skipped := 0
+	for i := len(all) - 1; i >= 0; i-- {
+		record := all[i]
+		if !q.Before.IsZero() && !q.Before.precedes(record) {
+			continue
+		}
+		if q.Limit > 0 && skipped < q.Offset {
+			skipped++
+			continue
+		}
+		if q.Limit > 0 && len(page.Records) > q.Limit {
+			break
+		}
+		if !q.InputPrompt {
+			record.InputPrompt = ""
+		}
+		if !q.CoTTrace {
+			record.CoTTrace = ""
+		}
+		if !q.RawResponse {
+			record.RawResponse = ""
+		}
+		page.Records = append(page.Records, record)
+	}
+	return page, nil
+}
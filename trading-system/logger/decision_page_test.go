@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecisionPageCursorKeepsEqualTimestamps(t *testing.T) {
+	l := NewDecisionLogger(t.TempDir())
+	if l.db == nil {
+		t.Fatal("SQLite decision logger not opened")
+	}
+	defer l.db.Close()
+
+	// Cycles 2-4 share a timestamp, so every page boundary below falls inside a run of equal timestamps
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamps := []time.Time{base, base.Add(time.Minute), base.Add(time.Minute), base.Add(time.Minute), base.Add(2 * time.Minute)}
+	for i, ts := range timestamps {
+		if err := l.insertDecisionRecord(&DecisionRecord{Timestamp: ts, CycleNumber: i + 1, Success: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cycles []int
+	var cursor DecisionCursor
+	for pages := 0; ; pages++ {
+		if pages > len(timestamps) {
+			t.Fatalf("pagination did not end, cycles so far %v", cycles)
+		}
+		page, err := l.GetDecisionPage(DecisionPageQuery{Limit: 2, Before: cursor})
+		if err != nil {
+			t.Fatalf("GetDecisionPage: %v", err)
+		}
+		if page.Total != len(timestamps) {
+			t.Errorf("Total = %d, want %d", page.Total, len(timestamps))
+		}
+		// Pages are oldest first, collect them newest first
+		for i := len(page.Records) - 1; i >= 0; i-- {
+			cycles = append(cycles, page.Records[i].CycleNumber)
+		}
+		if page.NextCursor.IsZero() {
+			break
+		}
+		if parsed, err := ParseDecisionCursor(page.NextCursor.String()); err != nil || parsed != page.NextCursor {
+			t.Fatalf("cursor %q round trip = %+v, %v", page.NextCursor, parsed, err)
+		}
+		cursor = page.NextCursor
+	}
+
+	want := []int{5, 4, 3, 2, 1}
+	if len(cycles) != len(want) {
+		t.Fatalf("cycles = %v, want %v", cycles, want)
+	}
+	for i := range want {
+		if cycles[i] != want[i] {
+			t.Fatalf("cycles = %v, want %v", cycles, want)
+		}
+	}
+
+	if _, err := l.GetDecisionPage(DecisionPageQuery{Limit: 2, Offset: 2, Before: cursor}); err == nil {
+		t.Error("offset with a cursor: want an error")
+	}
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EquitySnapshot the account at one moment of the fixed-schedule snapshotter (independent of decision cycles)
+type EquitySnapshot struct {
+	Timestamp        time.Time          `json:"timestamp"`
+	TotalEquity      float64            `json:"total_equity"`
+	AvailableBalance float64            `json:"available_balance"`
+	UnrealizedPnL    float64            `json:"unrealized_pnl"`
+	PositionCount    int                `json:"position_count"`
+	MarginUsedPct    float64            `json:"margin_used_pct"`
+	Positions        []PositionSnapshot `json:"positions,omitempty"`
+}
+
+// SaveEquitySnapshot stores a snapshot (no-op in JSON file mode)
+func (l *DecisionLogger) SaveEquitySnapshot(s *EquitySnapshot) error {
+	if l.db == nil {
+		return nil
+	}
+	positions, err := json.Marshal(s.Positions)
+	if err != nil {
+		return fmt.Errorf("failed to encode positions: %w", err)
+	}
+
+	if l.isPostgres {
+		_, err = l.db.Exec(`
+			INSERT INTO equity_snapshots (
+				trader_id, timestamp, total_equity, available_balance, unrealized_pnl, position_count, margin_used_pct, positions
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			l.traderID, s.Timestamp, s.TotalEquity, s.AvailableBalance, s.UnrealizedPnL, s.PositionCount, s.MarginUsedPct, string(positions))
+		return err
+	}
+	_, err = l.db.Exec(`
+		INSERT INTO equity_snapshots (
+			timestamp, total_equity, available_balance, unrealized_pnl, position_count, margin_used_pct, positions
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		s.Timestamp, s.TotalEquity, s.AvailableBalance, s.UnrealizedPnL, s.PositionCount, s.MarginUsedPct, string(positions))
+	return err
+}
+
+// GetEquitySnapshots the snapshots taken from start to end, oldest first, downsampled to at most maxPoints
+// (0 = all). Positions are only loaded when withPositions is set
+func (l *DecisionLogger) GetEquitySnapshots(start, end time.Time, maxPoints int, withPositions bool) ([]EquitySnapshot, error) {
+	if l.db == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	positionsColumn := "''"
+	if withPositions {
+		positionsColumn = "COALESCE(positions, '')"
+	}
+	columns := `timestamp, total_equity, available_balance, unrealized_pnl, position_count, margin_used_pct, ` + positionsColumn
+
+	var rows *sql.Rows
+	var err error
+	if l.isPostgres {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT `+columns+` FROM equity_snapshots
+			WHERE trader_id = $1 AND timestamp >= $2 AND timestamp <= $3
+			ORDER BY timestamp ASC`, l.traderID, start, end)
+	} else {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT `+columns+` FROM equity_snapshots
+			WHERE timestamp >= ? AND timestamp <= ?
+			ORDER BY timestamp ASC`, start, end)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []EquitySnapshot
+	for rows.Next() {
+		var s EquitySnapshot
+		var positions string
+		if err := rows.Scan(&s.Timestamp, &s.TotalEquity, &s.AvailableBalance, &s.UnrealizedPnL, &s.PositionCount, &s.MarginUsedPct, &positions); err != nil {
+			return nil, fmt.Errorf("failed to scan equity snapshot: %w", err)
+		}
+		if positions != "" {
+			if err := json.Unmarshal([]byte(positions), &s.Positions); err != nil {
+				return nil, fmt.Errorf("failed to decode snapshot positions: %w", err)
+			}
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return DownsampleEquitySnapshots(snapshots, maxPoints), nil
+}
+
+// PruneEquitySnapshots deletes the snapshots taken before cutoff. Returns how many were deleted
+func (l *DecisionLogger) PruneEquitySnapshots(cutoff time.Time) (int64, error) {
+	if l.db == nil {
+		return 0, nil
+	}
+	var result sql.Result
+	var err error
+	if l.isPostgres {
+		result, err = l.db.Exec(`DELETE FROM equity_snapshots WHERE trader_id = $1 AND timestamp < $2`, l.traderID, cutoff)
+	} else {
+		result, err = l.db.Exec(`DELETE FROM equity_snapshots WHERE timestamp < ?`, cutoff)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DownsampleEquitySnapshots reduces chronological snapshots to at most maxPoints (<= 0 = unchanged): the time
+// range is split into equal buckets and each keeps its last snapshot, so the curve ends on the latest value
+func DownsampleEquitySnapshots(snapshots []EquitySnapshot, maxPoints int) []EquitySnapshot {
+	if maxPoints <= 0 || len(snapshots) <= maxPoints {
+		return snapshots
+	}
+	first, last := snapshots[0].Timestamp, snapshots[len(snapshots)-1].Timestamp
+	span := last.Sub(first)
+	if span <= 0 {
+		return snapshots[len(snapshots)-1:]
+	}
+	bucketOf := func(t time.Time) int {
+		return min(int(float64(t.Sub(first))/float64(span)*float64(maxPoints)), maxPoints-1)
+	}
+
+	sampled := make([]EquitySnapshot, 0, maxPoints)
+	for i, s := range snapshots {
+		if i+1 < len(snapshots) && bucketOf(snapshots[i+1].Timestamp) == bucketOf(s.Timestamp) {
+			continue // A later snapshot closes this bucket
+		}
+		sampled = append(sampled, s)
+	}
+	return sampled
+}
@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema changes are versioned SQL files in migrations/<dialect>/NNNN_name.sql. Each database records the
+// versions it has applied in schema_migrations and runs the missing ones in order at startup. A schema change
+// is a new file in every dialect's directory; applied files must never be edited
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockKey PostgreSQL advisory lock held while a migration runs (traders share the database and
+// start concurrently)
+const migrationLockKey = 74_105_097
+
+// migration one versioned schema change
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// dialect the migrations directory of the connected database
+func (l *DecisionLogger) dialect() string {
+	switch {
+	case l.isMySQL:
+		return "mysql"
+	case l.isPostgres:
+		return "postgres"
+	default:
+		return "sqlite"
+	}
+}
+
+// loadMigrations the migrations of a dialect, by version
+func loadMigrations(dialect string) ([]migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	var migrations []migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s/%s: file name must start with a positive version number", dialect, entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+		data, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(data)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// runMigrations creates the version table and applies the missing migrations in order. Databases created
+// before versioned migrations get their missing columns first (upgradeLegacySchema), then the initial
+// migration only adds what is still missing
+func (l *DecisionLogger) runMigrations() error {
+	migrations, err := loadMigrations(l.dialect())
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	versionTable := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`
+	if l.isMySQL {
+		versionTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at DATETIME(6) NOT NULL
+		) ENGINE=InnoDB`
+	}
+	if _, err := l.db.Exec(versionTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := l.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 && l.tableExists("decisions") {
+		if err := l.upgradeLegacySchema(); err != nil {
+			return fmt.Errorf("failed to upgrade pre-migration schema: %w", err)
+		}
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		ran, err := l.applyMigration(m)
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.name, err)
+		}
+		if ran {
+			log.Printf("✓ Applied schema migration %s (%s)", m.name, l.dialect())
+		}
+	}
+	return nil
+}
+
+// appliedMigrations versions recorded in schema_migrations
+func (l *DecisionLogger) appliedMigrations() (map[int]bool, error) {
+	rows, err := l.db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// tableExists whether the table exists (any error reads as missing)
+func (l *DecisionLogger) tableExists(table string) bool {
+	rows, err := l.db.Query(fmt.Sprintf(`SELECT 1 FROM %s LIMIT 1`, table))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// applyMigration runs a migration and records it in one transaction (false when another trader sharing the
+// database applied it first). MySQL commits DDL implicitly, so there it runs statement by statement under a
+// named lock instead
+func (l *DecisionLogger) applyMigration(m migration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if l.isMySQL {
+		return l.applyMySQLMigration(ctx, m)
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if l.isPostgres {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+			return false, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+	}
+	var done int
+	if err := tx.QueryRowContext(ctx, l.rebind(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`), m.version).Scan(&done); err != nil {
+		return false, err
+	}
+	if done > 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, l.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+		m.version, m.name, time.Now().UTC()); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// applyMySQLMigration runs a migration's statements one by one under GET_LOCK (multi-statement queries are off).
+// A migration interrupted halfway is rerun from the start, so its statements should tolerate a partial run
+func (l *DecisionLogger) applyMySQLMigration(ctx context.Context, m migration) (bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var locked int
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK('lia_schema_migrations', 300)`).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	if locked != 1 {
+		return false, fmt.Errorf("timed out waiting for another instance's migration")
+	}
+	defer conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK('lia_schema_migrations')`)
+
+	var done int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, m.version).Scan(&done); err != nil {
+		return false, err
+	}
+	if done > 0 {
+		return false, nil
+	}
+	for _, stmt := range splitSQLStatements(m.sql) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return false, err
+		}
+	}
+	if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, time.Now().UTC()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// splitSQLStatements the statements of a migration file (split at semicolons outside string literals;
+// comment lines are dropped)
+func splitSQLStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	script = strings.Join(lines, "\n")
+
+	var statements []string
+	inString := false
+	start := 0
+	for i := 0; i < len(script); i++ {
+		switch script[i] {
+		case '\'':
+			inString = !inString
+		case ';':
+			if !inString {
+				if stmt := strings.TrimSpace(script[start:i]); stmt != "" {
+					statements = append(statements, stmt)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if stmt := strings.TrimSpace(script[start:]); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
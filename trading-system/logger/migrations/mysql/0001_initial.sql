@@ -0,0 +1,166 @@
+-- Initial MySQL/MariaDB schema (same tables and columns as PostgreSQL). Indexes are declared with the tables:
+-- MySQL has no CREATE INDEX IF NOT EXISTS
+
+CREATE TABLE IF NOT EXISTS decisions (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	trader_id VARCHAR(191) NOT NULL,
+	timestamp DATETIME(6) NOT NULL,
+	cycle_number INT NOT NULL,
+	input_prompt LONGTEXT,
+	cot_trace LONGTEXT,
+	decision_json LONGTEXT,
+	raw_response LONGTEXT,
+	success BOOLEAN NOT NULL DEFAULT TRUE,
+	error_message TEXT,
+	account_total_balance DOUBLE NOT NULL,
+	account_available_balance DOUBLE NOT NULL,
+	account_unrealized_profit DOUBLE NOT NULL,
+	account_position_count INT NOT NULL,
+	account_margin_used_pct DOUBLE NOT NULL,
+	execution_log LONGTEXT,
+	candidate_coins TEXT,
+	ai_model VARCHAR(255) NOT NULL DEFAULT '',
+	ai_calls INT NOT NULL DEFAULT 0,
+	ai_prompt_tokens INT NOT NULL DEFAULT 0,
+	ai_completion_tokens INT NOT NULL DEFAULT 0,
+	ai_cost_usd DOUBLE NOT NULL DEFAULT 0,
+	prompt_template VARCHAR(255) NOT NULL DEFAULT '',
+	prompt_version VARCHAR(255) NOT NULL DEFAULT '',
+	consensus_mode VARCHAR(64) NOT NULL DEFAULT '',
+	risk_review TEXT NOT NULL DEFAULT (''),
+	risk_policy TEXT NOT NULL DEFAULT (''),
+	created_at DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+	UNIQUE KEY uq_decisions_cycle (trader_id, cycle_number),
+	INDEX idx_decisions_timestamp (timestamp),
+	INDEX idx_decisions_success (success)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS positions (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	decision_id BIGINT NOT NULL,
+	symbol VARCHAR(64) NOT NULL,
+	side VARCHAR(16) NOT NULL,
+	position_amt DOUBLE NOT NULL,
+	entry_price DOUBLE NOT NULL,
+	mark_price DOUBLE NOT NULL,
+	unrealized_profit DOUBLE NOT NULL,
+	leverage DOUBLE NOT NULL,
+	liquidation_price DOUBLE NOT NULL,
+	INDEX idx_positions_decision (decision_id),
+	FOREIGN KEY (decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS decision_actions (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	decision_id BIGINT NOT NULL,
+	action VARCHAR(32) NOT NULL,
+	symbol VARCHAR(64) NOT NULL,
+	quantity DOUBLE NOT NULL,
+	leverage INT,
+	price DOUBLE NOT NULL,
+	order_id BIGINT,
+	timestamp DATETIME(6) NOT NULL,
+	success BOOLEAN NOT NULL DEFAULT TRUE,
+	error TEXT,
+	client_order_id VARCHAR(64),
+	partial BOOLEAN NOT NULL DEFAULT FALSE,
+	INDEX idx_actions_decision (decision_id),
+	FOREIGN KEY (decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS trades (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	trader_id VARCHAR(191) NOT NULL,
+	symbol VARCHAR(64) NOT NULL,
+	side VARCHAR(16) NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'open',
+	quantity DOUBLE NOT NULL,
+	closed_quantity DOUBLE NOT NULL DEFAULT 0,
+	leverage INT NOT NULL DEFAULT 0,
+	open_price DOUBLE NOT NULL,
+	close_price DOUBLE NOT NULL DEFAULT 0,
+	open_time DATETIME(6) NOT NULL,
+	close_time DATETIME(6) NULL,
+	open_cycle INT NOT NULL,
+	close_cycle INT NOT NULL DEFAULT 0,
+	realized_pnl DOUBLE NOT NULL DEFAULT 0,
+	fees DOUBLE NOT NULL DEFAULT 0,
+	duration_seconds BIGINT NOT NULL DEFAULT 0,
+	close_reason TEXT,
+	last_mark_price DOUBLE NOT NULL DEFAULT 0,
+	INDEX idx_trades_status (trader_id, status),
+	INDEX idx_trades_close_cycle (trader_id, close_cycle)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS orders (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	trader_id VARCHAR(191) NOT NULL,
+	order_id BIGINT NOT NULL,
+	client_order_id VARCHAR(64),
+	symbol VARCHAR(64) NOT NULL,
+	action VARCHAR(32) NOT NULL,
+	order_type VARCHAR(32) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	quantity DOUBLE NOT NULL,
+	filled_quantity DOUBLE NOT NULL DEFAULT 0,
+	mark_price DOUBLE NOT NULL DEFAULT 0,
+	avg_price DOUBLE NOT NULL DEFAULT 0,
+	fee DOUBLE NOT NULL DEFAULT 0,
+	submitted_at DATETIME(6) NOT NULL,
+	updated_at DATETIME(6) NOT NULL,
+	INDEX idx_orders_order_id (trader_id, order_id),
+	INDEX idx_orders_status (trader_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS equity_snapshots (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	trader_id VARCHAR(191) NOT NULL,
+	timestamp DATETIME(6) NOT NULL,
+	total_equity DOUBLE NOT NULL,
+	available_balance DOUBLE NOT NULL DEFAULT 0,
+	unrealized_pnl DOUBLE NOT NULL DEFAULT 0,
+	position_count INT NOT NULL DEFAULT 0,
+	margin_used_pct DOUBLE NOT NULL DEFAULT 0,
+	positions LONGTEXT,
+	INDEX idx_equity_snapshots_time (trader_id, timestamp)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS agent_debates (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	decision_id BIGINT NOT NULL,
+	consensus_mode VARCHAR(64) NOT NULL,
+	resolution TEXT NOT NULL,
+	INDEX idx_agent_debates_decision (decision_id),
+	FOREIGN KEY (decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS agent_proposals (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	decision_id BIGINT NOT NULL,
+	agent_id VARCHAR(191) NOT NULL,
+	agent_name VARCHAR(255),
+	provider VARCHAR(64),
+	model VARCHAR(255) NOT NULL,
+	role VARCHAR(64),
+	weight DOUBLE NOT NULL DEFAULT 0,
+	status VARCHAR(32) NOT NULL,
+	error TEXT,
+	duration_ms BIGINT NOT NULL DEFAULT 0,
+	cot_trace LONGTEXT,
+	decisions_json LONGTEXT,
+	INDEX idx_agent_proposals_decision (decision_id),
+	FOREIGN KEY (decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS agent_votes (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	decision_id BIGINT NOT NULL,
+	symbol VARCHAR(64) NOT NULL,
+	action VARCHAR(32) NOT NULL,
+	agents TEXT NOT NULL,
+	votes INT NOT NULL,
+	share DOUBLE NOT NULL,
+	accepted BOOLEAN NOT NULL DEFAULT FALSE,
+	INDEX idx_agent_votes_decision (decision_id),
+	FOREIGN KEY (decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
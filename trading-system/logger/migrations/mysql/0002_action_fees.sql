@@ -0,0 +1,5 @@
+-- Fee and exchange-reported P&L of each executed action, so the trade journal rebuilt from the database keeps them
+
+ALTER TABLE decision_actions
+	ADD COLUMN fee DOUBLE NOT NULL DEFAULT 0,
+	ADD COLUMN realized_pnl DOUBLE NULL;
@@ -0,0 +1,4 @@
+-- Fee and exchange-reported P&L of each executed action, so the trade journal rebuilt from the database keeps them
+
+ALTER TABLE decision_actions ADD COLUMN IF NOT EXISTS fee REAL NOT NULL DEFAULT 0;
+ALTER TABLE decision_actions ADD COLUMN IF NOT EXISTS realized_pnl REAL;
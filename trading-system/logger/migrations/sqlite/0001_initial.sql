@@ -0,0 +1,162 @@
+-- Initial SQLite schema: one database file per trader
+
+CREATE TABLE IF NOT EXISTS decisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp DATETIME NOT NULL,
+	cycle_number INTEGER NOT NULL UNIQUE,
+	input_prompt TEXT,
+	cot_trace TEXT,
+	decision_json TEXT,
+	raw_response TEXT,
+	success BOOLEAN NOT NULL DEFAULT 1,
+	error_message TEXT,
+	account_total_balance REAL NOT NULL,
+	account_available_balance REAL NOT NULL,
+	account_unrealized_profit REAL NOT NULL,
+	account_position_count INTEGER NOT NULL,
+	account_margin_used_pct REAL NOT NULL,
+	execution_log TEXT,
+	candidate_coins TEXT,
+	ai_model TEXT NOT NULL DEFAULT '',
+	ai_calls INTEGER NOT NULL DEFAULT 0,
+	ai_prompt_tokens INTEGER NOT NULL DEFAULT 0,
+	ai_completion_tokens INTEGER NOT NULL DEFAULT 0,
+	ai_cost_usd REAL NOT NULL DEFAULT 0,
+	prompt_template TEXT NOT NULL DEFAULT '',
+	prompt_version TEXT NOT NULL DEFAULT '',
+	consensus_mode TEXT NOT NULL DEFAULT '',
+	risk_review TEXT NOT NULL DEFAULT '',
+	risk_policy TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS positions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	decision_id INTEGER NOT NULL,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	position_amt REAL NOT NULL,
+	entry_price REAL NOT NULL,
+	mark_price REAL NOT NULL,
+	unrealized_profit REAL NOT NULL,
+	leverage REAL NOT NULL,
+	liquidation_price REAL NOT NULL,
+	FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS decision_actions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	decision_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	quantity REAL NOT NULL,
+	leverage INTEGER,
+	price REAL NOT NULL,
+	order_id INTEGER,
+	timestamp DATETIME NOT NULL,
+	success BOOLEAN NOT NULL DEFAULT 1,
+	error TEXT,
+	client_order_id TEXT,
+	partial BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS trades (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'open',
+	quantity REAL NOT NULL,
+	closed_quantity REAL NOT NULL DEFAULT 0,
+	leverage INTEGER NOT NULL DEFAULT 0,
+	open_price REAL NOT NULL,
+	close_price REAL NOT NULL DEFAULT 0,
+	open_time DATETIME NOT NULL,
+	close_time DATETIME,
+	open_cycle INTEGER NOT NULL,
+	close_cycle INTEGER NOT NULL DEFAULT 0,
+	realized_pnl REAL NOT NULL DEFAULT 0,
+	fees REAL NOT NULL DEFAULT 0,
+	duration_seconds INTEGER NOT NULL DEFAULT 0,
+	close_reason TEXT,
+	last_mark_price REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	order_id INTEGER NOT NULL,
+	client_order_id TEXT,
+	symbol TEXT NOT NULL,
+	action TEXT NOT NULL,
+	order_type TEXT NOT NULL,
+	status TEXT NOT NULL,
+	quantity REAL NOT NULL,
+	filled_quantity REAL NOT NULL DEFAULT 0,
+	mark_price REAL NOT NULL DEFAULT 0,
+	avg_price REAL NOT NULL DEFAULT 0,
+	fee REAL NOT NULL DEFAULT 0,
+	submitted_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS equity_snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp DATETIME NOT NULL,
+	total_equity REAL NOT NULL,
+	available_balance REAL NOT NULL DEFAULT 0,
+	unrealized_pnl REAL NOT NULL DEFAULT 0,
+	position_count INTEGER NOT NULL DEFAULT 0,
+	margin_used_pct REAL NOT NULL DEFAULT 0,
+	positions TEXT
+);
+
+CREATE TABLE IF NOT EXISTS agent_debates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	decision_id INTEGER NOT NULL,
+	consensus_mode TEXT NOT NULL,
+	resolution TEXT NOT NULL,
+	FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS agent_proposals (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	decision_id INTEGER NOT NULL,
+	agent_id TEXT NOT NULL,
+	agent_name TEXT,
+	provider TEXT,
+	model TEXT NOT NULL,
+	role TEXT,
+	weight REAL NOT NULL DEFAULT 0,
+	status TEXT NOT NULL,
+	error TEXT,
+	duration_ms INTEGER NOT NULL DEFAULT 0,
+	cot_trace TEXT,
+	decisions_json TEXT,
+	FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS agent_votes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	decision_id INTEGER NOT NULL,
+	symbol TEXT NOT NULL,
+	action TEXT NOT NULL,
+	agents TEXT NOT NULL,
+	votes INTEGER NOT NULL,
+	share REAL NOT NULL,
+	accepted BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY(decision_id) REFERENCES decisions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_decisions_timestamp ON decisions(timestamp);
+CREATE INDEX IF NOT EXISTS idx_decisions_cycle ON decisions(cycle_number);
+CREATE INDEX IF NOT EXISTS idx_decisions_success ON decisions(success);
+CREATE INDEX IF NOT EXISTS idx_positions_decision ON positions(decision_id);
+CREATE INDEX IF NOT EXISTS idx_actions_decision ON decision_actions(decision_id);
+CREATE INDEX IF NOT EXISTS idx_trades_status ON trades(status);
+CREATE INDEX IF NOT EXISTS idx_trades_close_cycle ON trades(close_cycle);
+CREATE INDEX IF NOT EXISTS idx_orders_order_id ON orders(order_id);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
+CREATE INDEX IF NOT EXISTS idx_equity_snapshots_time ON equity_snapshots(timestamp);
+CREATE INDEX IF NOT EXISTS idx_agent_debates_decision ON agent_debates(decision_id);
+CREATE INDEX IF NOT EXISTS idx_agent_proposals_decision ON agent_proposals(decision_id);
+CREATE INDEX IF NOT EXISTS idx_agent_votes_decision ON agent_votes(decision_id);
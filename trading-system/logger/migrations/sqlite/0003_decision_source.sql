@@ -0,0 +1,3 @@
+-- What produced each decision record: '' (the trader's AI or strategy), copy, signal or manual
+
+ALTER TABLE decisions ADD COLUMN source TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Order statuses after which an order no longer changes (exchange names)
+var finalOrderStatuses = map[string]bool{
+	"FILLED":   true,
+	"CANCELED": true,
+	"EXPIRED":  true,
+	"REJECTED": true,
+}
+
+// OrderRecord one order submitted by the trader and what the exchange last reported for it
+type OrderRecord struct {
+	ID             int64     `json:"id"`
+	OrderID        int64     `json:"order_id"`
+	ClientOrderID  string    `json:"client_order_id,omitempty"`
+	Symbol         string    `json:"symbol"`
+	Action         string    `json:"action"`     // open_long, open_short, close_long, close_short
+	OrderType      string    `json:"order_type"` // MARKET / LIMIT
+	Status         string    `json:"status"`
+	Quantity       float64   `json:"quantity"`        // Requested
+	FilledQuantity float64   `json:"filled_quantity"` // Executed so far
+	MarkPrice      float64   `json:"mark_price"`      // Price the decision was executed at (0 if not from a decision)
+	AvgPrice       float64   `json:"avg_price"`       // Average fill price (0 until the exchange reports it)
+	Fee            float64   `json:"fee"`
+	SubmittedAt    time.Time `json:"submitted_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Settled reports whether the order is done and its fill is known (nothing left to reconcile)
+func (o *OrderRecord) Settled() bool {
+	if !finalOrderStatuses[o.Status] {
+		return false
+	}
+	return o.Status != "FILLED" || (o.AvgPrice > 0 && o.FilledQuantity > 0)
+}
+
+// SaveOrder inserts a new order record (ID 0) or updates an existing one (no-op in JSON file mode)
+func (l *DecisionLogger) SaveOrder(o *OrderRecord) error {
+	if l.db == nil {
+		return nil
+	}
+
+	if o.ID == 0 {
+		if l.isPostgres {
+			return l.db.QueryRow(`
+				INSERT INTO orders (
+					trader_id, order_id, client_order_id, symbol, action, order_type, status, quantity,
+					filled_quantity, mark_price, avg_price, fee, submitted_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+				RETURNING id`,
+				l.traderID, o.OrderID, o.ClientOrderID, o.Symbol, o.Action, o.OrderType, o.Status, o.Quantity,
+				o.FilledQuantity, o.MarkPrice, o.AvgPrice, o.Fee, o.SubmittedAt, o.UpdatedAt).Scan(&o.ID)
+		}
+		result, err := l.db.Exec(`
+			INSERT INTO orders (
+				order_id, client_order_id, symbol, action, order_type, status, quantity,
+				filled_quantity, mark_price, avg_price, fee, submitted_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			o.OrderID, o.ClientOrderID, o.Symbol, o.Action, o.OrderType, o.Status, o.Quantity,
+			o.FilledQuantity, o.MarkPrice, o.AvgPrice, o.Fee, o.SubmittedAt, o.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		o.ID, err = result.LastInsertId()
+		return err
+	}
+
+	var err error
+	if l.isPostgres {
+		_, err = l.db.Exec(`
+			UPDATE orders SET status = $1, filled_quantity = $2, mark_price = $3, avg_price = $4, fee = $5, updated_at = $6
+			WHERE id = $7`,
+			o.Status, o.FilledQuantity, o.MarkPrice, o.AvgPrice, o.Fee, o.UpdatedAt, o.ID)
+	} else {
+		_, err = l.db.Exec(`
+			UPDATE orders SET status = ?, filled_quantity = ?, mark_price = ?, avg_price = ?, fee = ?, updated_at = ?
+			WHERE id = ?`,
+			o.Status, o.FilledQuantity, o.MarkPrice, o.AvgPrice, o.Fee, o.UpdatedAt, o.ID)
+	}
+	return err
+}
+
+// GetOrders gets submitted orders, newest first, at most limit (<= 0 = all). unsettledOnly keeps the orders
+// that are still open or whose fill is not known yet (JSON file mode keeps no order records)
+func (l *DecisionLogger) GetOrders(unsettledOnly bool, limit int) ([]OrderRecord, error) {
+	if l.db == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const columns = `id, order_id, COALESCE(client_order_id, ''), symbol, action, order_type, status, quantity,
+		filled_quantity, mark_price, avg_price, fee, submitted_at, updated_at`
+	// Unsettled: not in a final status, or filled without a known fill
+	const unsettled = `(status NOT IN ('FILLED', 'CANCELED', 'EXPIRED', 'REJECTED')
+		OR (status = 'FILLED' AND (avg_price <= 0 OR filled_quantity <= 0)))`
+	if limit <= 0 {
+		limit = -1 // SQLite: LIMIT -1 = no limit
+	}
+
+	var rows *sql.Rows
+	var err error
+	if l.isPostgres {
+		query := `SELECT ` + columns + ` FROM orders WHERE trader_id = $1`
+		if unsettledOnly {
+			query += ` AND ` + unsettled
+		}
+		query += ` ORDER BY submitted_at DESC, id DESC`
+		if limit > 0 {
+			query += fmt.Sprintf(` LIMIT %d`, limit)
+		}
+		rows, err = l.db.QueryContext(ctx, query, l.traderID)
+	} else {
+		query := `SELECT ` + columns + ` FROM orders`
+		if unsettledOnly {
+			query += ` WHERE ` + unsettled
+		}
+		query += ` ORDER BY submitted_at DESC, id DESC LIMIT ?`
+		rows, err = l.db.QueryContext(ctx, query, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []OrderRecord
+	for rows.Next() {
+		var o OrderRecord
+		if err := rows.Scan(&o.ID, &o.OrderID, &o.ClientOrderID, &o.Symbol, &o.Action, &o.OrderType, &o.Status,
+			&o.Quantity, &o.FilledQuantity, &o.MarkPrice, &o.AvgPrice, &o.Fee, &o.SubmittedAt, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+// ApplyOrderFill replaces the pre-trade price and requested quantity of the logged actions of an order with its
+// average fill price and executed quantity. Returns the number of actions updated (0 in JSON file mode)
+func (l *DecisionLogger) ApplyOrderFill(o *OrderRecord) (int64, error) {
+	if l.db == nil || o.OrderID == 0 {
+		return 0, nil
+	}
+
+	// Unfilled orders (cancelled limit entries) keep their logged price
+	set, args := "quantity = ?", []interface{}{o.FilledQuantity}
+	if o.AvgPrice > 0 {
+		set, args = "price = ?, quantity = ?", []interface{}{o.AvgPrice, o.FilledQuantity}
+	}
+	args = append(args, o.OrderID, o.Symbol)
+
+	var result sql.Result
+	var err error
+	if l.isPostgres {
+		args = append(args, l.traderID)
+		result, err = l.db.Exec(postgresPlaceholders(`
+			UPDATE decision_actions SET `+set+`
+			WHERE order_id = ? AND symbol = ?
+				AND decision_id IN (SELECT id FROM decisions WHERE trader_id = ?)`), args...)
+	} else {
+		result, err = l.db.Exec(`
+			UPDATE decision_actions SET `+set+`
+			WHERE order_id = ? AND symbol = ?`, args...)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// postgresPlaceholders numbers the ? placeholders of a query ($1, $2, ...)
+func postgresPlaceholders(query string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString(fmt.Sprintf("$%d", n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,295 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// equityPoint the account at one logged cycle
+type equityPoint struct {
+	time          time.Time
+	equity        float64
+	positionCount int
+}
+
+// BenchmarkComparison the account's return against holding the benchmark symbol over the same window
+type BenchmarkComparison struct {
+	Symbol     string  `json:"symbol"`
+	StartPrice float64 `json:"start_price"`
+	EndPrice   float64 `json:"end_price"`
+	ReturnPct  float64 `json:"return_pct"` // Buy-and-hold return of the benchmark
+	AlphaPct   float64 `json:"alpha_pct"`  // Account return minus the benchmark return (percentage points)
+}
+
+// equityPoints the equity curve of decision records (oldest first, cycles without equity skipped)
+func equityPoints(records []*DecisionRecord) []equityPoint {
+	var points []equityPoint
+	for _, record := range records {
+		if record.AccountState.TotalBalance > 0 {
+			points = append(points, equityPoint{
+				time:          record.Timestamp,
+				equity:        record.AccountState.TotalBalance,
+				positionCount: record.AccountState.PositionCount,
+			})
+		}
+	}
+	return points
+}
+
+// applyEquityMetrics sets the window, return, Sharpe, Sortino and Calmar ratios, drawdowns, exposure time and
+// rolling returns of the analysis from its equity curve (oldest first)
+func applyEquityMetrics(analysis *PerformanceAnalysis, points []equityPoint) {
+	equities := make([]float64, len(points))
+	for i, p := range points {
+		equities[i] = p.equity
+	}
+	analysis.SharpeRatio = sharpeRatio(equities)
+	if len(points) < 2 {
+		return
+	}
+
+	first, last := points[0], points[len(points)-1]
+	analysis.StartTime = first.time
+	analysis.EndTime = last.time
+	analysis.ReturnPct = (last.equity - first.equity) / first.equity * 100
+	analysis.MaxDrawdownPct = maxDrawdownPct(equities)
+	analysis.SortinoRatio = sortinoRatio(equities)
+	analysis.AvgDrawdownHours = avgDrawdownDuration(points).Hours()
+	analysis.CalmarRatio = calmarRatio(analysis.ReturnPct, analysis.MaxDrawdownPct, last.time.Sub(first.time))
+	analysis.Return24hPct = rollingReturnPct(points, 24*time.Hour)
+	analysis.Return7dPct = rollingReturnPct(points, 7*24*time.Hour)
+
+	// Time-weighted: the time until the next cycle counts as exposed when positions were open
+	var exposed, total time.Duration
+	for i := 1; i < len(points); i++ {
+		gap := points[i].time.Sub(points[i-1].time)
+		if gap <= 0 {
+			continue
+		}
+		total += gap
+		if points[i-1].positionCount > 0 {
+			exposed += gap
+		}
+	}
+	if total > 0 {
+		analysis.ExposurePct = float64(exposed) / float64(total) * 100
+	}
+}
+
+// maxDrawdownPct the largest peak-to-trough drop of an equity series, in percent of the peak
+func maxDrawdownPct(equities []float64) float64 {
+	peak, maxDrawdown := 0.0, 0.0
+	for _, equity := range equities {
+		if equity > peak {
+			peak = equity
+		}
+		if peak > 0 {
+			maxDrawdown = math.Max(maxDrawdown, (peak-equity)/peak*100)
+		}
+	}
+	return maxDrawdown
+}
+
+// avgDrawdownDuration the average time from a peak until equity got back to it. A drawdown still open at the
+// last point lasts until then
+func avgDrawdownDuration(points []equityPoint) time.Duration {
+	var total time.Duration
+	count := 0
+	peak, peakTime := 0.0, time.Time{}
+	inDrawdown := false
+	for _, p := range points {
+		if p.equity >= peak {
+			if inDrawdown {
+				total += p.time.Sub(peakTime)
+				count++
+				inDrawdown = false
+			}
+			peak, peakTime = p.equity, p.time
+		} else {
+			inDrawdown = true
+		}
+	}
+	if inDrawdown {
+		total += points[len(points)-1].time.Sub(peakTime)
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+// calmarRatio annualized return (simple, not compounded: short windows would explode) over max drawdown. 0 for
+// windows under a day; 999 when a profitable window had no drawdown
+func calmarRatio(returnPct, maxDrawdownPct float64, window time.Duration) float64 {
+	if window < 24*time.Hour {
+		return 0.0
+	}
+	annualizedPct := returnPct * (365 * 24) / window.Hours()
+	if maxDrawdownPct == 0 {
+		if annualizedPct > 0 {
+			return 999.0
+		}
+		return 0.0
+	}
+	return annualizedPct / maxDrawdownPct
+}
+
+// rollingReturnPct the equity change from the last point at least period before the end (nil when the history
+// is shorter than period)
+func rollingReturnPct(points []equityPoint, period time.Duration) *float64 {
+	last := points[len(points)-1]
+	cutoff := last.time.Add(-period)
+	for i := len(points) - 1; i >= 0; i-- {
+		if !points[i].time.After(cutoff) {
+			pct := (last.equity - points[i].equity) / points[i].equity * 100
+			return &pct
+		}
+	}
+	return nil
+}
+
+// sortinoRatio mean per-cycle return over the downside deviation (losing cycles only; gains are not penalized)
+func sortinoRatio(equities []float64) float64 {
+	var returns []float64
+	for i := 1; i < len(equities); i++ {
+		if equities[i-1] > 0 {
+			returns = append(returns, (equities[i]-equities[i-1])/equities[i-1])
+		}
+	}
+	if len(returns) == 0 {
+		return 0.0
+	}
+
+	sum, downside := 0.0, 0.0
+	for _, r := range returns {
+		sum += r
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	mean := sum / float64(len(returns))
+	downsideDev := math.Sqrt(downside / float64(len(returns)))
+
+	if downsideDev == 0 {
+		if mean > 0 {
+			return 999.0
+		} else if mean < 0 {
+			return -999.0
+		}
+		return 0.0
+	}
+	return mean / downsideDev
+}
+
+// CompareToBenchmark sets Benchmark: the return of buying the symbol at the window start and holding it to the
+// window end, and the account's alpha over it. priceAt looks up historical prices (market.PriceAt)
+func (a *PerformanceAnalysis) CompareToBenchmark(symbol string, priceAt func(symbol string, t time.Time) (float64, error)) error {
+	if a.StartTime.IsZero() || !a.EndTime.After(a.StartTime) {
+		return fmt.Errorf("not enough equity history to compare against %s", symbol)
+	}
+	startPrice, err := priceAt(symbol, a.StartTime)
+	if err != nil {
+		return fmt.Errorf("failed to get %s start price: %w", symbol, err)
+	}
+	endPrice, err := priceAt(symbol, a.EndTime)
+	if err != nil {
+		return fmt.Errorf("failed to get %s end price: %w", symbol, err)
+	}
+	if startPrice <= 0 {
+		return fmt.Errorf("invalid %s start price %v", symbol, startPrice)
+	}
+
+	returnPct := (endPrice - startPrice) / startPrice * 100
+	a.Benchmark = &BenchmarkComparison{
+		Symbol:     strings.ToUpper(symbol),
+		StartPrice: startPrice,
+		EndPrice:   endPrice,
+		ReturnPct:  returnPct,
+		AlphaPct:   a.ReturnPct - returnPct,
+	}
+	return nil
+}
+
+// EquityWindow the account over the logged cycles of a leaderboard window
+type EquityWindow struct {
+	Cycles         int       `json:"cycles"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	StartEquity    float64   `json:"start_equity"`
+	EndEquity      float64   `json:"end_equity"`
+	SharpeRatio    float64   `json:"sharpe_ratio"`
+	MaxDrawdownPct float64   `json:"max_drawdown_pct"`
+}
+
+// GetEquityWindow the logged equity of the cycles at or after since and sinceCycle (zero = no bound). Cycles is
+// 0 when none fall in the window
+func (l *DecisionLogger) GetEquityWindow(since time.Time, sinceCycle int) (*EquityWindow, error) {
+	var points []equityPoint
+	if l.db != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var rows *sql.Rows
+		var err error
+		if l.isPostgres {
+			rows, err = l.db.QueryContext(ctx, `
+				SELECT timestamp, account_total_balance, account_position_count FROM decisions
+				WHERE trader_id = $1 AND timestamp >= $2 AND cycle_number >= $3 AND account_total_balance > 0
+				ORDER BY cycle_number ASC
+			`, l.traderID, since, sinceCycle)
+		} else {
+			rows, err = l.db.QueryContext(ctx, `
+				SELECT timestamp, account_total_balance, account_position_count FROM decisions
+				WHERE timestamp >= ? AND cycle_number >= ? AND account_total_balance > 0
+				ORDER BY cycle_number ASC
+			`, since, sinceCycle)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var p equityPoint
+			if err := rows.Scan(&p.time, &p.equity, &p.positionCount); err != nil {
+				return nil, err
+			}
+			points = append(points, p)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		// Fallback to JSON files
+		records, err := l.getAllRecordsFromJSON()
+		if err != nil {
+			return nil, err
+		}
+		var inWindow []*DecisionRecord
+		for _, record := range records {
+			if !record.Timestamp.Before(since) && record.CycleNumber >= sinceCycle {
+				inWindow = append(inWindow, record)
+			}
+		}
+		points = equityPoints(inWindow)
+	}
+
+	window := &EquityWindow{Cycles: len(points)}
+	if len(points) == 0 {
+		return window, nil
+	}
+	equities := make([]float64, len(points))
+	for i, p := range points {
+		equities[i] = p.equity
+	}
+	first, last := points[0], points[len(points)-1]
+	window.StartTime, window.EndTime = first.time, last.time
+	window.StartEquity, window.EndEquity = first.equity, last.equity
+	window.SharpeRatio = sharpeRatio(equities)
+	window.MaxDrawdownPct = maxDrawdownPct(equities)
+	return window, nil
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// compressedTextPrefix marks a gzip-compressed, base64-encoded input_prompt / cot_trace / raw_response
+const compressedTextPrefix = "gz:"
+
+// minCompressedTextLen shorter texts are stored as-is (compression would not pay for the encoding)
+const minCompressedTextLen = 512
+
+// retentionBatchSize records stripped per query of a retention pass
+const retentionBatchSize = 200
+
+// compressText whether new decision records store their large text fields compressed (see SetTextCompression)
+var compressText = false
+
+// SetTextCompression turns gzip compression of new records' input_prompt, cot_trace and raw_response on or off.
+// Reads decompress either way, so the setting can change at any time
+func SetTextCompression(enabled bool) {
+	compressText = enabled
+	if enabled {
+		log.Printf("✓ Decision log text compression enabled (gzip)")
+	}
+}
+
+// encodeText the stored form of a large text field (compressed when enabled and worth it)
+func encodeText(text string) string {
+	if !compressText || len(text) < minCompressedTextLen {
+		return text
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(text)); err != nil {
+		return text
+	}
+	if err := zw.Close(); err != nil {
+		return text
+	}
+	return compressedTextPrefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// decodeText the text of a stored field (as-is unless compressed; undecodable values are returned unchanged)
+func decodeText(stored string) string {
+	if !strings.HasPrefix(stored, compressedTextPrefix) {
+		return stored
+	}
+	data, err := base64.StdEncoding.DecodeString(stored[len(compressedTextPrefix):])
+	if err != nil {
+		return stored
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return stored
+	}
+	defer zr.Close()
+	text, err := io.ReadAll(zr)
+	if err != nil {
+		return stored
+	}
+	return string(text)
+}
+
+// RetentionPolicy how long decision records keep their full text
+type RetentionPolicy struct {
+	FullTextDays int // Older records keep a chain-of-thought summary only (<= 0 = keep everything)
+	SummaryChars int // Length of the summary (the start of the chain of thought)
+}
+
+// summarize the start of text, at most chars characters long
+func summarize(text string, chars int) string {
+	if utf8.RuneCountInString(text) <= chars {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:chars-1]) + "…"
+}
+
+// retentionFilter WHERE clause and arguments of the records the policy still has to strip
+func (l *DecisionLogger) retentionFilter(policy RetentionPolicy) (string, []interface{}) {
+	cutoff := time.Now().AddDate(0, 0, -policy.FullTextDays)
+	needsStrip := `(COALESCE(input_prompt, '') <> '' OR COALESCE(raw_response, '') <> ''
+		OR cot_trace LIKE '` + compressedTextPrefix + `%' OR `
+	if l.isPostgres {
+		// CHAR_LENGTH: MySQL's LENGTH counts bytes
+		return ` WHERE trader_id = $1 AND timestamp < $2 AND ` + needsStrip + `CHAR_LENGTH(cot_trace) > $3)`,
+			[]interface{}{l.traderID, cutoff, policy.SummaryChars}
+	}
+	return ` WHERE timestamp < ? AND ` + needsStrip + `LENGTH(cot_trace) > ?)`, []interface{}{cutoff, policy.SummaryChars}
+}
+
+// CountRetentionCandidates how many records the policy would strip (0 in JSON file mode)
+func (l *DecisionLogger) CountRetentionCandidates(policy RetentionPolicy) (int, error) {
+	if l.db == nil || policy.FullTextDays <= 0 {
+		return 0, nil
+	}
+	where, args := l.retentionFilter(policy)
+	var count int
+	if err := l.db.QueryRow(`SELECT COUNT(*) FROM decisions`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count failed: %w", err)
+	}
+	return count, nil
+}
+
+// ApplyRetention strips the records older than policy.FullTextDays to summaries: the input prompt and raw
+// response are dropped and the chain of thought is cut to policy.SummaryChars. Decisions, account state and
+// executions are kept. Returns how many records were stripped (no-op in JSON file mode)
+func (l *DecisionLogger) ApplyRetention(policy RetentionPolicy) (int, error) {
+	if l.db == nil || policy.FullTextDays <= 0 {
+		return 0, nil
+	}
+	if policy.SummaryChars <= 0 {
+		return 0, fmt.Errorf("summary length must be positive, got %d", policy.SummaryChars)
+	}
+	where, args := l.retentionFilter(policy)
+	update := `UPDATE decisions SET input_prompt = '', raw_response = '', cot_trace = ? WHERE id = ?`
+	if l.isPostgres {
+		update = `UPDATE decisions SET input_prompt = '', raw_response = '', cot_trace = $1 WHERE id = $2`
+	}
+
+	stripped := 0
+	for {
+		// Stripped records no longer match, so each batch starts from the top
+		batch, err := l.retentionBatch(where, args)
+		if err != nil {
+			return stripped, err
+		}
+		if len(batch) == 0 {
+			return stripped, nil
+		}
+		for id, cot := range batch {
+			if _, err := l.db.Exec(update, summarize(decodeText(cot), policy.SummaryChars), id); err != nil {
+				return stripped, fmt.Errorf("failed to strip record %d: %w", id, err)
+			}
+			stripped++
+		}
+	}
+}
+
+// retentionBatch the id and stored chain of thought of the next records to strip
+func (l *DecisionLogger) retentionBatch(where string, args []interface{}) (map[int64]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rows, err := l.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, COALESCE(cot_trace, '') FROM decisions%s LIMIT %d`,
+		where, retentionBatchSize), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	batch := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var cot string
+		if err := rows.Scan(&id, &cot); err != nil {
+			return nil, fmt.Errorf("failed to scan record: %w", err)
+		}
+		batch[id] = cot
+	}
+	return batch, rows.Err()
+}
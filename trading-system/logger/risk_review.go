@@ -0,0 +1,53 @@
+package logger
+
+import "encoding/json"
+
+// RiskReview the risk officer's review of a cycle's decisions before execution
+type RiskReview struct {
+	Reviewer string        `json:"reviewer"`           // "rules" or "ai:<model>" (the AI reviews after the rules)
+	Summary  string        `json:"summary,omitempty"`  // The AI reviewer's overall rationale
+	Error    string        `json:"error,omitempty"`    // Why the AI review failed (the rule verdicts still apply)
+	AIUsage  *AIUsage      `json:"ai_usage,omitempty"` // The AI review call (also counted in the record's ai_usage)
+	Verdicts []RiskVerdict `json:"verdicts"`
+}
+
+// RiskVerdict the risk officer's ruling on one reviewed open
+type RiskVerdict struct {
+	Symbol     string  `json:"symbol"`
+	Action     string  `json:"action"`
+	Verdict    string  `json:"verdict"`     // approve / veto / downsize
+	SizeBefore float64 `json:"size_before"` // Margin the decision asked for (USDT)
+	SizeAfter  float64 `json:"size_after"`  // Margin left to execute (0 = vetoed)
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// Risk verdicts
+const (
+	RiskApprove  = "approve"
+	RiskVeto     = "veto"
+	RiskDownsize = "downsize"
+)
+
+// marshalRiskReview the review as stored in the decisions table ("" = not reviewed)
+func marshalRiskReview(review *RiskReview) string {
+	if review == nil {
+		return ""
+	}
+	data, err := json.Marshal(review)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// unmarshalRiskReview a stored review (nil = not reviewed)
+func unmarshalRiskReview(data string) *RiskReview {
+	if data == "" {
+		return nil
+	}
+	var review RiskReview
+	if json.Unmarshal([]byte(data), &review) != nil {
+		return nil
+	}
+	return &review
+}
@@ -0,0 +1,544 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trade journal statuses and close reasons
+const (
+	TradeOpen   = "open"
+	TradeClosed = "closed"
+
+	TradeCloseExternal = "external" // Gone from the position snapshot without a logged close (stop loss, take profit, monitor, liquidation)
+)
+
+// Trade one position from its first open to its final close, matched from the logged actions. Adds to the
+// position average the entry, partial closes (close_pct, reduce_size) average the exit
+type Trade struct {
+	ID              int64     `json:"id"`
+	Symbol          string    `json:"symbol"`
+	Side            string    `json:"side"` // long/short
+	Status          string    `json:"status"`
+	Quantity        float64   `json:"quantity"`        // Total opened quantity
+	ClosedQuantity  float64   `json:"closed_quantity"` // Closed so far
+	Leverage        int       `json:"leverage"`
+	OpenPrice       float64   `json:"open_price"`  // Average entry
+	ClosePrice      float64   `json:"close_price"` // Average exit (0 while nothing is closed)
+	OpenTime        time.Time `json:"open_time"`
+	CloseTime       time.Time `json:"close_time"` // Zero while open
+	OpenCycle       int       `json:"open_cycle"`
+	CloseCycle      int       `json:"close_cycle"`  // 0 while open
+	RealizedPnL     float64   `json:"realized_pnl"` // P&L of the closed quantity (exchange-reported when available)
+	Fees            float64   `json:"fees"`         // Fees reported with the orders
+	NetPnL          float64   `json:"net_pnl"`      // realized_pnl - fees
+	DurationSeconds int64     `json:"duration_seconds"`
+	CloseReason     string    `json:"close_reason,omitempty"` // Closing action, or "external"
+	LastMarkPrice   float64   `json:"last_mark_price"`        // Mark price of the last position snapshot
+}
+
+// remaining open quantity
+func (t *Trade) remaining() float64 {
+	return t.Quantity - t.ClosedQuantity
+}
+
+// tradeKey open trades are keyed by symbol and side (positions are one-way per side)
+func tradeKey(symbol, side string) string {
+	return symbol + "_" + side
+}
+
+// tradeSide side of a position action ("" = not a position action)
+func tradeSide(action string) string {
+	switch action {
+	case "open_long", "add_long", "close_long":
+		return "long"
+	case "open_short", "add_short", "close_short":
+		return "short"
+	}
+	return ""
+}
+
+// applyTradeRecord updates the open trades with a logged record: successful opens and closes, then the position
+// snapshot (mark prices, and trades closed outside the logged actions). Returns the trades it changed; new trades
+// have ID 0 and finished trades are removed from open
+func applyTradeRecord(open map[string]*Trade, record *DecisionRecord) []*Trade {
+	touched := make(map[*Trade]bool)
+	var changed []*Trade
+	touch := func(t *Trade) {
+		if !touched[t] {
+			touched[t] = true
+			changed = append(changed, t)
+		}
+	}
+	finish := func(t *Trade, at time.Time, reason string) {
+		t.Status = TradeClosed
+		t.CloseTime = at
+		t.CloseCycle = record.CycleNumber
+		t.CloseReason = reason
+		t.DurationSeconds = int64(at.Sub(t.OpenTime).Seconds())
+		t.NetPnL = t.RealizedPnL - t.Fees
+		delete(open, tradeKey(t.Symbol, t.Side))
+	}
+	closeQuantity := func(t *Trade, quantity, price float64, reported *float64) {
+		quantity = math.Min(quantity, t.remaining())
+		if quantity <= 0 {
+			return
+		}
+		pnl := quantity * (price - t.OpenPrice)
+		if t.Side == "short" {
+			pnl = -pnl
+		}
+		if reported != nil {
+			pnl = *reported
+		}
+		t.ClosePrice = (t.ClosePrice*t.ClosedQuantity + price*quantity) / (t.ClosedQuantity + quantity)
+		t.ClosedQuantity += quantity
+		t.RealizedPnL += pnl
+		t.NetPnL = t.RealizedPnL - t.Fees
+	}
+
+	for _, action := range record.Decisions {
+		if !action.Success {
+			continue
+		}
+		at := action.Timestamp
+		if at.IsZero() {
+			at = record.Timestamp
+		}
+
+		switch action.Action {
+		case "open_long", "open_short", "add_long", "add_short":
+			if action.Quantity <= 0 {
+				continue
+			}
+			side := tradeSide(action.Action)
+			t, ok := open[tradeKey(action.Symbol, side)]
+			if !ok && strings.HasPrefix(action.Action, "add_") {
+				continue // Adds to a position opened before the journal started
+			}
+			if !ok {
+				t = &Trade{
+					Symbol:        action.Symbol,
+					Side:          side,
+					Status:        TradeOpen,
+					Leverage:      action.Leverage,
+					OpenTime:      at,
+					OpenCycle:     record.CycleNumber,
+					LastMarkPrice: action.Price,
+				}
+				open[tradeKey(action.Symbol, side)] = t
+			}
+			t.OpenPrice = (t.OpenPrice*t.Quantity + action.Price*action.Quantity) / (t.Quantity + action.Quantity)
+			t.Quantity += action.Quantity
+			t.Fees += action.Fee
+			t.NetPnL = t.RealizedPnL - t.Fees
+			touch(t)
+
+		case "close_long", "close_short":
+			t, ok := open[tradeKey(action.Symbol, tradeSide(action.Action))]
+			if !ok {
+				continue // Opened before the journal started
+			}
+			quantity := t.remaining()
+			if action.Partial {
+				quantity = action.Quantity
+			}
+			t.Fees += action.Fee
+			closeQuantity(t, quantity, action.Price, action.RealizedPnL)
+			if !action.Partial || t.remaining() <= t.Quantity*1e-6 {
+				finish(t, at, action.Action)
+			}
+			touch(t)
+
+		case "reduce_size":
+			// The action has no side: reduce the symbol's only open trade
+			long, hasLong := open[tradeKey(action.Symbol, "long")]
+			short, hasShort := open[tradeKey(action.Symbol, "short")]
+			if hasLong == hasShort {
+				continue
+			}
+			t := long
+			if hasShort {
+				t = short
+			}
+			t.Fees += action.Fee
+			closeQuantity(t, action.Quantity, action.Price, action.RealizedPnL)
+			if t.remaining() <= t.Quantity*1e-6 {
+				finish(t, at, action.Action)
+			}
+			touch(t)
+		}
+	}
+
+	// The snapshot is complete only for records of full cycles (a manual close logs no positions)
+	if record.AccountState.TotalBalance <= 0 || len(record.Positions) != record.AccountState.PositionCount {
+		return changed
+	}
+	marks := make(map[string]float64, len(record.Positions))
+	for _, pos := range record.Positions {
+		marks[tradeKey(pos.Symbol, strings.ToLower(pos.Side))] = pos.MarkPrice
+	}
+	for key, t := range open {
+		if mark, ok := marks[key]; ok {
+			if mark > 0 && mark != t.LastMarkPrice {
+				t.LastMarkPrice = mark
+				touch(t)
+			}
+			continue
+		}
+		if t.OpenCycle == record.CycleNumber {
+			continue // Opened this cycle; the snapshot may predate it
+		}
+		// Closed without a logged action: estimate the exit at the last mark price seen
+		price := t.LastMarkPrice
+		if price <= 0 {
+			price = t.OpenPrice
+		}
+		closeQuantity(t, t.remaining(), price, nil)
+		finish(t, record.Timestamp, TradeCloseExternal)
+		touch(t)
+	}
+	return changed
+}
+
+// recordTrades applies a logged record to the trade journal in the database
+func (l *DecisionLogger) recordTrades(record *DecisionRecord) error {
+	open, err := l.loadTrades(TradeOpen, 0)
+	if err != nil {
+		return err
+	}
+	book := make(map[string]*Trade, len(open))
+	for i := range open {
+		book[tradeKey(open[i].Symbol, open[i].Side)] = &open[i]
+	}
+
+	changed := applyTradeRecord(book, record)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, t := range changed {
+		if err := l.saveTrade(tx, t); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// saveTrade inserts a new trade or updates an existing one
+func (l *DecisionLogger) saveTrade(tx *sql.Tx, t *Trade) error {
+	var closeTime interface{}
+	if !t.CloseTime.IsZero() {
+		closeTime = t.CloseTime
+	}
+
+	if t.ID == 0 {
+		if l.isPostgres {
+			return tx.QueryRow(`
+				INSERT INTO trades (
+					trader_id, symbol, side, status, quantity, closed_quantity, leverage, open_price, close_price,
+					open_time, close_time, open_cycle, close_cycle, realized_pnl, fees, duration_seconds,
+					close_reason, last_mark_price
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+				RETURNING id`,
+				l.traderID, t.Symbol, t.Side, t.Status, t.Quantity, t.ClosedQuantity, t.Leverage, t.OpenPrice, t.ClosePrice,
+				t.OpenTime, closeTime, t.OpenCycle, t.CloseCycle, t.RealizedPnL, t.Fees, t.DurationSeconds,
+				t.CloseReason, t.LastMarkPrice).Scan(&t.ID)
+		}
+		result, err := tx.Exec(`
+			INSERT INTO trades (
+				symbol, side, status, quantity, closed_quantity, leverage, open_price, close_price,
+				open_time, close_time, open_cycle, close_cycle, realized_pnl, fees, duration_seconds,
+				close_reason, last_mark_price
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.Symbol, t.Side, t.Status, t.Quantity, t.ClosedQuantity, t.Leverage, t.OpenPrice, t.ClosePrice,
+			t.OpenTime, closeTime, t.OpenCycle, t.CloseCycle, t.RealizedPnL, t.Fees, t.DurationSeconds,
+			t.CloseReason, t.LastMarkPrice)
+		if err != nil {
+			return err
+		}
+		t.ID, err = result.LastInsertId()
+		return err
+	}
+
+	var err error
+	if l.isPostgres {
+		_, err = tx.Exec(`
+			UPDATE trades SET status = $1, quantity = $2, closed_quantity = $3, open_price = $4, close_price = $5,
+				close_time = $6, close_cycle = $7, realized_pnl = $8, fees = $9, duration_seconds = $10,
+				close_reason = $11, last_mark_price = $12
+			WHERE id = $13`,
+			t.Status, t.Quantity, t.ClosedQuantity, t.OpenPrice, t.ClosePrice,
+			closeTime, t.CloseCycle, t.RealizedPnL, t.Fees, t.DurationSeconds,
+			t.CloseReason, t.LastMarkPrice, t.ID)
+	} else {
+		_, err = tx.Exec(`
+			UPDATE trades SET status = ?, quantity = ?, closed_quantity = ?, open_price = ?, close_price = ?,
+				close_time = ?, close_cycle = ?, realized_pnl = ?, fees = ?, duration_seconds = ?,
+				close_reason = ?, last_mark_price = ?
+			WHERE id = ?`,
+			t.Status, t.Quantity, t.ClosedQuantity, t.OpenPrice, t.ClosePrice,
+			closeTime, t.CloseCycle, t.RealizedPnL, t.Fees, t.DurationSeconds,
+			t.CloseReason, t.LastMarkPrice, t.ID)
+	}
+	return err
+}
+
+// loadTrades trades with status ("" = all) closed at or after minCloseCycle (0 = any), oldest first
+func (l *DecisionLogger) loadTrades(status string, minCloseCycle int) ([]Trade, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var rows *sql.Rows
+	var err error
+	const columns = `id, symbol, side, status, quantity, closed_quantity, leverage, open_price, close_price,
+		open_time, close_time, open_cycle, close_cycle, realized_pnl, fees, duration_seconds,
+		COALESCE(close_reason, ''), last_mark_price`
+
+	if l.isPostgres {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT `+columns+`
+			FROM trades
+			WHERE trader_id = $1 AND ($2 = '' OR status = $2) AND close_cycle >= $3
+			ORDER BY open_time ASC, id ASC
+		`, l.traderID, status, minCloseCycle)
+	} else {
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT `+columns+`
+			FROM trades
+			WHERE (? = '' OR status = ?) AND close_cycle >= ?
+			ORDER BY open_time ASC, id ASC
+		`, status, status, minCloseCycle)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var trades []Trade
+	for rows.Next() {
+		var t Trade
+		var closeTime sql.NullTime
+		if err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.Status, &t.Quantity, &t.ClosedQuantity, &t.Leverage,
+			&t.OpenPrice, &t.ClosePrice, &t.OpenTime, &closeTime, &t.OpenCycle, &t.CloseCycle, &t.RealizedPnL,
+			&t.Fees, &t.DurationSeconds, &t.CloseReason, &t.LastMarkPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		}
+		if closeTime.Valid {
+			t.CloseTime = closeTime.Time
+		}
+		t.NetPnL = t.RealizedPnL - t.Fees
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+// replayTrades rebuilds the journal from records (oldest first): JSON file mode and the one-time backfill
+func replayTrades(records []*DecisionRecord) []*Trade {
+	open := make(map[string]*Trade)
+	var trades []*Trade
+	seen := make(map[*Trade]bool)
+	for _, record := range records {
+		for _, t := range applyTradeRecord(open, record) {
+			if !seen[t] {
+				seen[t] = true
+				trades = append(trades, t)
+			}
+		}
+	}
+	return trades
+}
+
+// GetTrades gets journal trades with status ("" = all, "open", "closed"), newest first, at most limit
+// (<= 0 = all)
+func (l *DecisionLogger) GetTrades(status string, limit int) ([]Trade, error) {
+	var trades []Trade
+	if l.db != nil {
+		var err error
+		if trades, err = l.loadTrades(status, 0); err != nil {
+			return nil, err
+		}
+	} else {
+		// Fallback to JSON files
+		records, err := l.getAllRecordsFromJSON()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range replayTrades(records) {
+			if status == "" || t.Status == status {
+				trades = append(trades, *t)
+			}
+		}
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].OpenTime.After(trades[j].OpenTime) })
+	if limit > 0 && len(trades) > limit {
+		trades = trades[:limit]
+	}
+	return trades, nil
+}
+
+// backfillTrades builds the journal from the logged history the first time a database without trades starts
+func (l *DecisionLogger) backfillTrades() error {
+	var count int
+	var err error
+	if l.isPostgres {
+		err = l.db.QueryRow(`SELECT COUNT(*) FROM trades WHERE trader_id = $1`, l.traderID).Scan(&count)
+	} else {
+		err = l.db.QueryRow(`SELECT COUNT(*) FROM trades`).Scan(&count)
+	}
+	if err != nil || count > 0 {
+		return err
+	}
+	// Nothing to match until the trader has opened a position
+	if l.isPostgres {
+		err = l.db.QueryRow(`
+			SELECT COUNT(*) FROM decision_actions a JOIN decisions d ON a.decision_id = d.id
+			WHERE d.trader_id = $1 AND a.success AND a.action IN ('open_long', 'open_short')`, l.traderID).Scan(&count)
+	} else {
+		err = l.db.QueryRow(`
+			SELECT COUNT(*) FROM decision_actions WHERE success AND action IN ('open_long', 'open_short')`).Scan(&count)
+	}
+	if err != nil || count == 0 {
+		return err
+	}
+
+	records, err := l.getAllRecordsFromDB()
+	if err != nil {
+		return err
+	}
+	trades := replayTrades(records)
+	if len(trades) == 0 {
+		return nil
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, t := range trades {
+		if err := l.saveTrade(tx, t); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("✓ Trade journal backfilled: %d trades from %d decision records", len(trades), len(records))
+	return nil
+}
+
+// analyzePerformanceFromJournal performance of the trades closed within the last lookbackCycles cycles (<= 0 = all)
+func (l *DecisionLogger) analyzePerformanceFromJournal(lookbackCycles int) (*PerformanceAnalysis, error) {
+	minCloseCycle := 1
+	if lookbackCycles > 0 {
+		minCloseCycle = l.cycleNumber - lookbackCycles + 1
+	}
+	trades, err := l.loadTrades(TradeClosed, minCloseCycle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trade journal: %w", err)
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].CloseTime.Before(trades[j].CloseTime) })
+
+	analysis := &PerformanceAnalysis{
+		RecentTrades: []TradeOutcome{},
+		SymbolStats:  make(map[string]*SymbolPerformance),
+	}
+	for _, t := range trades {
+		positionValue := t.Quantity * t.OpenPrice
+		marginUsed := positionValue
+		if t.Leverage > 0 {
+			marginUsed = positionValue / float64(t.Leverage)
+		}
+		pnlPct := 0.0
+		if marginUsed > 0 {
+			pnlPct = t.NetPnL / marginUsed * 100
+		}
+		countTradeOutcome(analysis, TradeOutcome{
+			Symbol:        t.Symbol,
+			Side:          t.Side,
+			Quantity:      t.Quantity,
+			Leverage:      t.Leverage,
+			OpenPrice:     t.OpenPrice,
+			ClosePrice:    t.ClosePrice,
+			PositionValue: positionValue,
+			MarginUsed:    marginUsed,
+			PnL:           t.NetPnL,
+			PnLPct:        pnlPct,
+			Duration:      t.CloseTime.Sub(t.OpenTime).String(),
+			OpenTime:      t.OpenTime,
+			CloseTime:     t.CloseTime,
+			// Closed outside the logged actions at a loss: the exchange stop (or liquidation) filled
+			WasStopLoss: t.CloseReason == TradeCloseExternal && t.NetPnL < 0,
+		})
+	}
+	finishPerformanceAnalysis(analysis)
+
+	points, err := l.equitySeries(lookbackCycles)
+	if err != nil {
+		return nil, err
+	}
+	applyEquityMetrics(analysis, points)
+	return analysis, nil
+}
+
+// equitySeries account equity of the last n logged cycles (<= 0 = all), oldest first
+func (l *DecisionLogger) equitySeries(n int) ([]equityPoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	limit := -1 // SQLite: no limit
+	var rows *sql.Rows
+	var err error
+	if l.isPostgres {
+		var pgLimit interface{} // NULL = no limit
+		if n > 0 {
+			pgLimit = n
+		}
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT timestamp, account_total_balance, account_position_count FROM decisions
+			WHERE trader_id = $1 AND account_total_balance > 0
+			ORDER BY cycle_number DESC
+			LIMIT $2
+		`, l.traderID, pgLimit)
+	} else {
+		if n > 0 {
+			limit = n
+		}
+		rows, err = l.db.QueryContext(ctx, `
+			SELECT timestamp, account_total_balance, account_position_count FROM decisions
+			WHERE account_total_balance > 0
+			ORDER BY cycle_number DESC
+			LIMIT ?
+		`, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var points []equityPoint
+	for rows.Next() {
+		var p equityPoint
+		if err := rows.Scan(&p.time, &p.equity, &p.positionCount); err != nil {
+			continue
+		}
+		points = append(points, p)
+	}
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,366 @@
+package logger
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// WriteQueueOptions asynchronous Supabase writes: records are spilled to a local SQLite file first and synced
+// to Supabase in the background, so a slow or unreachable database never stalls a cycle
+type WriteQueueOptions struct {
+	SyncInterval time.Duration // How often pending records are retried while Supabase is failing
+	BatchSize    int           // Records inserted per Supabase transaction
+}
+
+// pendingWritesFile the local spill database in the trader's log directory
+const pendingWritesFile = "pending_writes.db"
+
+// writeQueue local spill of records not yet written to Supabase. Records that can never be written (corrupt in
+// the spill, or rejected by the database for their data) are moved to the spill's failed_records table so they
+// do not hold back the records behind them
+type writeQueue struct {
+	spill   *sql.DB
+	opts    WriteQueueOptions
+	wake    chan struct{}
+	syncMu  sync.Mutex // One sync at a time
+	mu      sync.Mutex
+	pending int   // Records waiting in the spill
+	lastErr error // Error of the last failed sync (nil = in sync)
+}
+
+// enableWriteQueue opens the spill database and starts the background sync (Supabase only). Records left by a
+// previous run are synced first, so the history reads at startup see them when Supabase is reachable
+func (l *DecisionLogger) enableWriteQueue(opts WriteQueueOptions) error {
+	if l.db == nil || !l.isPostgres {
+		return nil
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = 15 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+
+	spill, err := openSpill(filepath.Join(l.logDir, pendingWritesFile))
+	if err != nil {
+		return err
+	}
+
+	q := &writeQueue{spill: spill, opts: opts, wake: make(chan struct{}, 1)}
+	var maxCycle sql.NullInt64
+	if err := spill.QueryRow(`SELECT COUNT(*), MAX(cycle_number) FROM pending_records`).Scan(&q.pending, &maxCycle); err != nil {
+		spill.Close()
+		return fmt.Errorf("failed to read spill database: %w", err)
+	}
+	// Pending records are newer than anything in Supabase: continue after them
+	if maxCycle.Valid && int(maxCycle.Int64) > l.cycleNumber {
+		l.cycleNumber = int(maxCycle.Int64)
+	}
+	l.queue = q
+
+	if q.pending > 0 {
+		log.Printf("📤 [%s] %d decision records from the last run waiting to sync to Supabase", l.traderID, q.pending)
+		l.syncPendingWrites()
+	}
+	go l.runWriteQueue()
+	log.Printf("✓ [%s] Asynchronous decision log writes enabled (spill: %s)", l.traderID, filepath.Join(l.logDir, pendingWritesFile))
+	return nil
+}
+
+// openSpill opens (creating if needed) a spill database
+func openSpill(path string) (*sql.DB, error) {
+	spill, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill database: %w", err)
+	}
+	for _, stmt := range []string{`
+		CREATE TABLE IF NOT EXISTS pending_records (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			cycle_number INTEGER NOT NULL,
+			record TEXT NOT NULL
+		)`, `
+		CREATE TABLE IF NOT EXISTS failed_records (
+			id INTEGER PRIMARY KEY,
+			cycle_number INTEGER NOT NULL,
+			record TEXT NOT NULL,
+			error TEXT NOT NULL,
+			failed_at DATETIME NOT NULL
+		)`} {
+		if _, err := spill.Exec(stmt); err != nil {
+			spill.Close()
+			return nil, fmt.Errorf("failed to create spill table: %w", err)
+		}
+	}
+	return spill, nil
+}
+
+// enqueueRecord spills a record for the background sync (falls back to a JSON file when the spill fails)
+func (l *DecisionLogger) enqueueRecord(record *DecisionRecord) error {
+	data, err := json.Marshal(record)
+	if err == nil {
+		_, err = l.queue.spill.Exec(`INSERT INTO pending_records (cycle_number, record) VALUES (?, ?)`, record.CycleNumber, string(data))
+	}
+	if err != nil {
+		log.Printf("⚠ Spilling decision record failed (cycle #%d): %v - falling back to JSON file", record.CycleNumber, err)
+		return l.logDecisionToJSON(record)
+	}
+
+	l.queue.mu.Lock()
+	l.queue.pending++
+	l.queue.mu.Unlock()
+	select {
+	case l.queue.wake <- struct{}{}:
+	default: // A sync is already due
+	}
+	return nil
+}
+
+// runWriteQueue syncs on every new record and every SyncInterval (retrying while Supabase fails)
+func (l *DecisionLogger) runWriteQueue() {
+	ticker := time.NewTicker(l.queue.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.queue.wake:
+		case <-ticker.C:
+		}
+		l.syncPendingWrites()
+	}
+}
+
+// syncPendingWrites writes the spilled records to Supabase in order, a batch per transaction, until the spill
+// is empty or a batch fails for a transient reason (retried on the next sync). A batch the database rejects for
+// its data is written record by record, and the rejected records are moved to failed_records
+func (l *DecisionLogger) syncPendingWrites() {
+	q := l.queue
+	q.syncMu.Lock()
+	defer q.syncMu.Unlock()
+
+	for {
+		ids, records, err := q.nextBatch()
+		if err != nil {
+			l.setSyncError(err)
+			return
+		}
+		if len(records) == 0 {
+			l.setSyncError(nil)
+			return
+		}
+		if err := l.insertBatch(records); err != nil {
+			if !permanentWriteError(err) {
+				l.setSyncError(err)
+				return
+			}
+			if err := l.syncOneByOne(ids, records); err != nil {
+				l.setSyncError(err)
+				return
+			}
+			continue
+		}
+		if err := q.remove(ids); err != nil {
+			// Written but still spilled: stop before the records are inserted twice
+			log.Printf("❌ [%s] Failed to clear synced records from the spill: %v", l.traderID, err)
+			l.setSyncError(err)
+			return
+		}
+		for _, record := range records {
+			l.recordSynced(record)
+		}
+	}
+}
+
+// syncOneByOne writes a batch the database rejected one record per transaction, moving the records rejected for
+// their data to failed_records. Stops at the first transient failure
+func (l *DecisionLogger) syncOneByOne(ids []int64, records []*DecisionRecord) error {
+	q := l.queue
+	for i, record := range records {
+		err := l.insertBatch([]*DecisionRecord{record})
+		switch {
+		case err == nil:
+			if err := q.remove(ids[i : i+1]); err != nil {
+				log.Printf("❌ [%s] Failed to clear synced records from the spill: %v", l.traderID, err)
+				return err
+			}
+			l.recordSynced(record)
+		case permanentWriteError(err):
+			log.Printf("❌ [%s] Decision record rejected by the database, moved to failed_records (cycle #%d): %v",
+				l.traderID, record.CycleNumber, err)
+			if err := q.deadLetter(ids[i], err); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// recordSynced reports a record written to the database and adds it to the trade journal
+func (l *DecisionLogger) recordSynced(record *DecisionRecord) {
+	fmt.Printf("📝 Decision record saved to database: cycle #%d (trader: %s)\n", record.CycleNumber, l.traderID)
+	if err := l.recordTrades(record); err != nil {
+		log.Printf("⚠️  Trade journal update failed (cycle #%d): %v", record.CycleNumber, err)
+	}
+}
+
+// permanentWriteError whether the database rejected a write for the record's data (invalid value, constraint
+// violation), which retrying cannot fix. Connection failures, timeouts, outages and schema errors (a migration
+// not applied yet would reject every record) are transient
+func permanentWriteError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "22", "23": // Data exception, integrity constraint violation
+			return true
+		}
+		return false
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1048, 1062, 1264, 1292, 1366, 1406, 1452, 3140: // Null, duplicate, out of range, bad value, too long, foreign key, bad JSON
+			return true
+		}
+	}
+	return false
+}
+
+// insertBatch inserts records in one transaction
+func (l *DecisionLogger) insertBatch(records []*DecisionRecord) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, record := range records {
+		if err := l.insertDecisionRecordTx(tx, record); err != nil {
+			return fmt.Errorf("cycle #%d: %w", record.CycleNumber, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// setSyncError logs when syncing starts failing and when it recovers (not every retry)
+func (l *DecisionLogger) setSyncError(err error) {
+	q := l.queue
+	q.mu.Lock()
+	wasFailing, pending := q.lastErr != nil, q.pending
+	q.lastErr = err
+	q.mu.Unlock()
+	switch {
+	case err != nil && !wasFailing:
+		log.Printf("⚠ [%s] Supabase write failed, %d decision records kept locally and retried every %v: %v",
+			l.traderID, pending, q.opts.SyncInterval, err)
+	case err == nil && wasFailing:
+		log.Printf("✅ [%s] Supabase writes recovered, pending decision records synced", l.traderID)
+	}
+}
+
+// nextBatch the oldest spilled records. Records that cannot be decoded are moved to failed_records (the batch
+// is filled from the records behind them)
+func (q *writeQueue) nextBatch() ([]int64, []*DecisionRecord, error) {
+	for {
+		ids, records, corrupt, err := q.readBatch()
+		if err != nil || len(corrupt) == 0 {
+			return ids, records, err
+		}
+		for id, decodeErr := range corrupt {
+			log.Printf("❌ Corrupt spilled decision record %d moved to failed_records: %v", id, decodeErr)
+			if err := q.deadLetter(id, decodeErr); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+// readBatch reads the oldest spilled records, returning the ones that cannot be decoded apart with their error
+func (q *writeQueue) readBatch() ([]int64, []*DecisionRecord, map[int64]error, error) {
+	rows, err := q.spill.Query(`SELECT id, record FROM pending_records ORDER BY id LIMIT ?`, q.opts.BatchSize)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read spill: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var records []*DecisionRecord
+	corrupt := make(map[int64]error)
+	for rows.Next() {
+		var id int64
+		var data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read spill: %w", err)
+		}
+		var record DecisionRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			corrupt[id] = err
+			continue
+		}
+		ids = append(ids, id)
+		records = append(records, &record)
+	}
+	return ids, records, corrupt, rows.Err()
+}
+
+// deadLetter moves a spilled record that can never be written to failed_records, with the reason
+func (q *writeQueue) deadLetter(id int64, reason error) error {
+	tx, err := q.spill.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to move spilled record %d: %w", id, err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO failed_records (id, cycle_number, record, error, failed_at)
+		SELECT id, cycle_number, record, ?, ? FROM pending_records WHERE id = ?`, reason.Error(), time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to move spilled record %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM pending_records WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to move spilled record %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to move spilled record %d: %w", id, err)
+	}
+	q.mu.Lock()
+	q.pending--
+	q.mu.Unlock()
+	return nil
+}
+
+// remove deletes synced records from the spill
+func (q *writeQueue) remove(ids []int64) error {
+	tx, err := q.spill.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM pending_records WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.pending -= len(ids)
+	q.mu.Unlock()
+	return nil
+}
+
+// WriteQueueStatus records waiting to be written to Supabase and the last sync error (enabled = false when
+// writes are synchronous)
+func (l *DecisionLogger) WriteQueueStatus() (enabled bool, pending int, lastErr error) {
+	if l.queue == nil {
+		return false, 0, nil
+	}
+	l.queue.mu.Lock()
+	defer l.queue.mu.Unlock()
+	return true, l.queue.pending, l.queue.lastErr
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestNextBatchMovesCorruptRecordsAside(t *testing.T) {
+	spill, err := openSpill(filepath.Join(t.TempDir(), pendingWritesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spill.Close()
+
+	rows := []string{record(t, 1), `{"cycle_number": 2, "timestamp":`, record(t, 3)}
+	for i, data := range rows {
+		if _, err := spill.Exec(`INSERT INTO pending_records (cycle_number, record) VALUES (?, ?)`, i+1, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q := &writeQueue{spill: spill, opts: WriteQueueOptions{BatchSize: 10}, pending: len(rows)}
+
+	ids, records, err := q.nextBatch()
+	if err != nil {
+		t.Fatalf("nextBatch: %v", err)
+	}
+	if len(records) != 2 || records[0].CycleNumber != 1 || records[1].CycleNumber != 3 {
+		t.Fatalf("batch = %v, want cycles 1 and 3", cycles(records))
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Fatalf("ids = %v, want [1 3]", ids)
+	}
+	if q.pending != 2 {
+		t.Errorf("pending = %d, want 2", q.pending)
+	}
+
+	var cycle int
+	var reason string
+	if err := spill.QueryRow(`SELECT cycle_number, error FROM failed_records WHERE id = 2`).Scan(&cycle, &reason); err != nil {
+		t.Fatalf("corrupt record not in failed_records: %v", err)
+	}
+	if cycle != 2 || reason == "" {
+		t.Errorf("failed record = cycle %d, error %q", cycle, reason)
+	}
+
+	// The spill keeps syncing past the corrupt record
+	if err := q.remove(ids); err != nil {
+		t.Fatal(err)
+	}
+	ids, records, err = q.nextBatch()
+	if err != nil || len(records) != 0 || len(ids) != 0 {
+		t.Fatalf("after sync: %d records, err %v, want an empty spill", len(records), err)
+	}
+}
+
+func TestPermanentWriteError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "22P02"}, true},                             // Invalid text representation
+		{fmt.Errorf("cycle #4: %w", &pq.Error{Code: "23505"}), true}, // Unique violation
+		{&pq.Error{Code: "08006"}, false},                            // Connection failure
+		{&pq.Error{Code: "42P01"}, false},                            // Undefined table
+		{&mysql.MySQLError{Number: 1366}, true},                      // Incorrect value
+		{&mysql.MySQLError{Number: 1205}, false},                     // Lock wait timeout
+		{errors.New("dial tcp: connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := permanentWriteError(tt.err); got != tt.want {
+			t.Errorf("permanentWriteError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func record(t *testing.T, cycle int) string {
+	t.Helper()
+	data, err := json.Marshal(&DecisionRecord{CycleNumber: cycle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func cycles(records []*DecisionRecord) []int {
+	var numbers []int
+	for _, r := range records {
+		numbers = append(numbers, r.CycleNumber)
+	}
+	return numbers
+}
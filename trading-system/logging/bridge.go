@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// traderPrefixPattern the "[trader name]" prefix of the traders' log lines
+var traderPrefixPattern = regexp.MustCompile(`^\s*\[([^\]]+)\]`)
+
+// Markers the existing log lines use for errors and warnings
+var (
+	errorMarkers = []string{"❌", "🚨", "💥", "PANIC", "FATAL"}
+	warnMarkers  = []string{"⚠"}
+)
+
+// stdBridge routes the standard library logger's lines through the structured logger. It is the fallback for
+// packages not logging through a Logger: their level is guessed from the line's markers
+type stdBridge struct{}
+
+// Write logs one standard library log line (the log package writes whole lines and serializes calls)
+func (stdBridge) Write(p []byte) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+
+	root.mu.RLock()
+	logger := root.logger
+	root.mu.RUnlock()
+
+	event := logger.WithLevel(inferLevel(line))
+	if event == nil {
+		return len(p), nil
+	}
+	if m := traderPrefixPattern.FindStringSubmatch(line); m != nil {
+		event = event.Str(FieldTrader, m[1])
+	}
+	event.Msg(line)
+	return len(p), nil
+}
+
+// inferLevel the level of an unstructured log line from its markers (info when it has none)
+func inferLevel(line string) zerolog.Level {
+	for _, marker := range errorMarkers {
+		if strings.Contains(line, marker) {
+			return zerolog.ErrorLevel
+		}
+	}
+	for _, marker := range warnMarkers {
+		if strings.Contains(line, marker) {
+			return zerolog.WarnLevel
+		}
+	}
+	return zerolog.InfoLevel
+}
@@ -0,0 +1,197 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Output formats
+const (
+	FormatConsole = "console" // Human-readable lines, as the standard logger prints them (default)
+	FormatJSON    = "json"    // One JSON object per line (level, time, message, trader and other fields) for Loki/Datadog
+)
+
+// consoleTimeFormat the standard logger's timestamp, kept in console output
+const consoleTimeFormat = "2006/01/02 15:04:05"
+
+var root = struct {
+	mu     sync.RWMutex
+	logger zerolog.Logger
+	format string
+}{
+	logger: newLogger(os.Stderr, FormatConsole, zerolog.InfoLevel),
+	format: FormatConsole,
+}
+
+// Init sets the level (debug, info, warn, error) and format (console, json) of all logging, including the
+// standard library log calls, which are routed through the structured logger with an inferred level and the
+// trader named by their "[name]" prefix
+func Init(level, format string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = FormatConsole
+	}
+	if format != FormatConsole && format != FormatJSON {
+		return fmt.Errorf("unknown log format '%s' (console or json)", format)
+	}
+
+	root.mu.Lock()
+	root.logger = newLogger(os.Stderr, format, lvl)
+	root.format = format
+	root.mu.Unlock()
+
+	log.SetFlags(0) // The structured logger adds the timestamp
+	log.SetOutput(stdBridge{})
+	return nil
+}
+
+// SetOutput sends all logging, the standard library log calls included, to out instead of stderr, keeping its
+// level and format (tests discard it)
+func SetOutput(out io.Writer) {
+	root.mu.Lock()
+	root.logger = newLogger(out, root.format, root.logger.GetLevel())
+	root.mu.Unlock()
+
+	log.SetFlags(0)
+	log.SetOutput(stdBridge{})
+}
+
+// ParseLevel parses a level name ("" = info)
+func ParseLevel(level string) (zerolog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "info":
+		return zerolog.InfoLevel, nil
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "warn", "warning":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	}
+	return zerolog.NoLevel, fmt.Errorf("unknown log level '%s' (debug, info, warn or error)", level)
+}
+
+// newLogger builds the root logger writing to out
+func newLogger(out io.Writer, format string, level zerolog.Level) zerolog.Logger {
+	if format == FormatJSON {
+		return zerolog.New(out).Level(level).With().Timestamp().Logger()
+	}
+	console := zerolog.ConsoleWriter{
+		Out:           out,
+		NoColor:       true,
+		TimeFormat:    consoleTimeFormat,
+		PartsOrder:    []string{zerolog.TimestampFieldName, zerolog.MessageFieldName},
+		FieldsExclude: []string{FieldTrader, FieldTraderID, FieldComponent},
+	}
+	return zerolog.New(console).Level(level).With().Timestamp().Logger()
+}
+
+// Field names shared by all log lines
+const (
+	FieldTrader    = "trader"    // Trader name
+	FieldTraderID  = "trader_id" // Trader ID
+	FieldComponent = "component" // Subsystem (alert, manager, ...)
+)
+
+// Logger a structured logger carrying fields (trader, component, ...). The zero value logs through the root logger
+type Logger struct {
+	fields []field
+	prefix string // Console message prefix ("[trader name] ")
+}
+
+// field one key/value attached to every line of a Logger
+type field struct {
+	key   string
+	value interface{}
+}
+
+// ForTrader a logger for one trader: JSON lines carry trader and trader_id, console lines keep the "[name]" prefix
+func ForTrader(id, name string) Logger {
+	return Logger{
+		fields: []field{{FieldTraderID, id}, {FieldTrader, name}},
+		prefix: "[" + name + "] ",
+	}
+}
+
+// Component a logger for a subsystem
+func Component(name string) Logger {
+	return Logger{fields: []field{{FieldComponent, name}}}
+}
+
+// With returns a logger adding key=value to every line
+func (l Logger) With(key string, value interface{}) Logger {
+	fields := make([]field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	l.fields = append(fields, field{key, value})
+	return l
+}
+
+// Debugf logs at debug level
+func (l Logger) Debugf(format string, args ...interface{}) {
+	l.logf(zerolog.DebugLevel, format, args...)
+}
+
+// Infof logs at info level
+func (l Logger) Infof(format string, args ...interface{}) {
+	l.logf(zerolog.InfoLevel, format, args...)
+}
+
+// Warnf logs at warn level
+func (l Logger) Warnf(format string, args ...interface{}) {
+	l.logf(zerolog.WarnLevel, format, args...)
+}
+
+// Errorf logs at error level
+func (l Logger) Errorf(format string, args ...interface{}) {
+	l.logf(zerolog.ErrorLevel, format, args...)
+}
+
+// logf writes one line at level
+func (l Logger) logf(level zerolog.Level, format string, args ...interface{}) {
+	root.mu.RLock()
+	logger, console := root.logger, root.format == FormatConsole
+	root.mu.RUnlock()
+
+	event := logger.WithLevel(level)
+	if event == nil {
+		return // Below the configured level
+	}
+	for _, f := range l.fields {
+		event = event.Interface(f.key, f.value)
+	}
+	msg := fmt.Sprintf(format, args...)
+	if console {
+		msg = l.prefix + msg
+	}
+	event.Msg(msg)
+}
+
+// Debugf logs at debug level through the root logger
+func Debugf(format string, args ...interface{}) {
+	Logger{}.Debugf(format, args...)
+}
+
+// Infof logs at info level through the root logger
+func Infof(format string, args ...interface{}) {
+	Logger{}.Infof(format, args...)
+}
+
+// Warnf logs at warn level through the root logger
+func Warnf(format string, args ...interface{}) {
+	Logger{}.Warnf(format, args...)
+}
+
+// Errorf logs at error level through the root logger
+func Errorf(format string, args ...interface{}) {
+	Logger{}.Errorf(format, args...)
+}
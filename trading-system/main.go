@@ -1,22 +1,48 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"lia/alert"
 	"lia/api"
+	"lia/bus"
 	"lia/config"
+	"lia/export"
+	"lia/logger"
+	"lia/logging"
 	"lia/manager"
+	"lia/market"
+	"lia/mcp"
+	"lia/news"
 	"lia/pool"
+	"lia/ratelimit"
+	"lia/trader"
 	"log"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	// `lia config-validate [config.json]`: check the config (and its environment variables) and exit
+	if len(os.Args) > 1 && os.Args[1] == "config-validate" {
+		// Load .env so variables defined there count as set
+		_ = godotenv.Load()
+		os.Exit(runConfigValidate(os.Args[2:]))
+	}
+
+	// `lia api-server [config.json]`: read-only API fed by the engine over the message bus
+	if len(os.Args) > 1 && os.Args[1] == "api-server" {
+		_ = godotenv.Load()
+		os.Exit(runAPIServer(os.Args[2:]))
+	}
+
 	fmt.Println("╔════════════════════════════════════════════════════════════╗")
 	fmt.Println("║    🤖 AI-Driven Cryptocurrency Trading System             ║")
 	fmt.Println("║              OpenAI vs Qwen Competition                    ║")
@@ -43,6 +69,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("❌ Failed to load configuration: %v", err)
 	}
+	if err := logging.Init(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		log.Fatalf("❌ Failed to configure logging: %v", err)
+	}
 
 	// Override API server port with Render's PORT environment variable if set
 	if renderPort := os.Getenv("PORT"); renderPort != "" {
@@ -55,6 +84,14 @@ func main() {
 	log.Printf("✓ Configuration loaded successfully, %d traders participating", len(cfg.Traders))
 	fmt.Println()
 
+	// Apply low-memory profile (Render free tier etc.)
+	if cfg.LowMemory.Enabled {
+		applyLowMemoryProfile(cfg.LowMemory)
+	}
+
+	// Decision logs store new records' prompts and AI responses compressed
+	logger.SetTextCompression(cfg.DecisionRetention.CompressText)
+
 	// Set default coin list
 	pool.SetDefaultCoins(cfg.DefaultCoins)
 
@@ -73,10 +110,109 @@ func main() {
 		pool.SetOITopAPI(cfg.OITopAPIURL)
 		log.Printf("✓ OI Top API configured")
 	}
+	pool.SetStaleAlertThreshold(time.Duration(cfg.CoinPoolStaleAlertMinutes) * time.Minute)
+
+	// Custom coin sources and the merge rules (the defaults keep AI500 + OI Top as they are)
+	customSources := make([]pool.CustomSource, 0, len(cfg.CoinSources.Sources))
+	for _, source := range cfg.CoinSources.Sources {
+		customSources = append(customSources, pool.CustomSource{
+			Name:        source.Name,
+			Type:        source.Type,
+			URL:         source.URL,
+			SymbolsPath: source.SymbolsPath,
+			Headers:     source.Headers,
+			Symbols:     source.Symbols,
+			Weight:      source.Weight,
+			Limit:       source.Limit,
+			CacheTTL:    time.Duration(source.CacheSeconds) * time.Second,
+		})
+	}
+	if err := pool.SetCustomSources(customSources, pool.MergeRules{
+		AI500Weight: cfg.CoinSources.AI500Weight,
+		OITopWeight: cfg.CoinSources.OITopWeight,
+		MinScore:    cfg.CoinSources.MinScore,
+		MaxSymbols:  cfg.CoinSources.MaxSymbols,
+		Exclude:     cfg.CoinSources.Exclude,
+	}); err != nil {
+		log.Fatalf("❌ Failed to configure coin sources: %v", err)
+	}
+
+	// Email alerts for critical failures (SMTP settings from ALERT_* environment variables)
+	alert.Init(alert.LoadConfig())
+
+	// Shared Binance request budgets (queue requests across traders instead of hitting the exchange's limits)
+	ratelimit.Configure(cfg.RateLimit.BinanceWeightPerMinute, cfg.RateLimit.AccountWeightPerMinute)
+	log.Printf("✓ Binance request budget: %d weight/min (%d per account)", cfg.RateLimit.BinanceWeightPerMinute, cfg.RateLimit.AccountWeightPerMinute)
+
+	// Shared market data cache (traders requesting the same symbols within the TTL share one fetch)
+	stopMarketRefresh := func() {}
+	if cfg.MarketData.Enabled {
+		market.SetCacheTTL(time.Duration(cfg.MarketData.CacheTTLSeconds) * time.Second)
+		if cfg.MarketData.RefreshIntervalSeconds > 0 {
+			stopMarketRefresh = market.StartRefresh(time.Duration(cfg.MarketData.RefreshIntervalSeconds)*time.Second,
+				cfg.MarketData.MaxSymbols, cfg.MarketData.Concurrency)
+			log.Printf("✓ Shared market data cache: %ds TTL, %d most recent symbols refreshed every %ds",
+				cfg.MarketData.CacheTTLSeconds, cfg.MarketData.MaxSymbols, cfg.MarketData.RefreshIntervalSeconds)
+		} else {
+			log.Printf("✓ Shared market data cache: %ds TTL (no background refresh)", cfg.MarketData.CacheTTLSeconds)
+		}
+	}
+
+	// Concurrent context building, a per-tick market snapshot shared by all traders and staggered AI calls
+	manager.ConfigureCycleCoordination(cfg.CycleCoordination)
+
+	// News headlines for the AI prompts (polled sources plus POST /api/news)
+	stopNews := func() {}
+	if cfg.News.Enabled {
+		news.Configure(news.Options{
+			MaxAge:         time.Duration(cfg.News.MaxAgeHours * float64(time.Hour)),
+			MaxPerSymbol:   cfg.News.MaxPerSymbol,
+			MaxMarket:      cfg.News.MaxMarketHeadlines,
+			MarketKeywords: cfg.News.MarketKeywords,
+			Aliases:        cfg.News.Aliases,
+		})
+		var sources []news.Source
+		for _, source := range cfg.News.Sources {
+			switch source.Type {
+			case "cryptopanic":
+				sources = append(sources, news.NewCryptoPanic(source.Name, source.URL, source.APIKey))
+			case "rss":
+				sources = append(sources, news.NewRSS(source.Name, source.URL))
+			}
+		}
+		if len(sources) > 0 {
+			stopNews = news.Start(sources, time.Duration(cfg.News.RefreshMinutes)*time.Minute)
+		}
+		log.Printf("✓ News feed: %d source(s) polled every %dm, headlines kept %.0fh", len(sources), cfg.News.RefreshMinutes, cfg.News.MaxAgeHours)
+	}
+
+	// Binance liquidation stream for the AI prompts
+	stopLiquidations := func() {}
+	if cfg.Liquidations.Enabled {
+		market.ConfigureLiquidations(market.LiquidationOptions{
+			Window:   time.Duration(cfg.Liquidations.WindowMinutes) * time.Minute,
+			LargeUSD: cfg.Liquidations.LargeUSD,
+			MaxLarge: cfg.Liquidations.MaxLarge,
+			Clusters: cfg.Liquidations.Clusters,
+		})
+		stopLiquidations = market.StartLiquidationStream()
+		log.Printf("✓ Liquidation stream: last %dm per symbol, large from $%.0f", cfg.Liquidations.WindowMinutes, cfg.Liquidations.LargeUSD)
+	}
+
+	// Shared AI response cache (identical prompts to the same model are paid for once)
+	if cfg.AIRequest.CacheTTLSeconds > 0 {
+		mcp.SetResponseCacheTTL(time.Duration(cfg.AIRequest.CacheTTLSeconds) * time.Second)
+		log.Printf("✓ AI response cache enabled: identical prompts reused for %ds", cfg.AIRequest.CacheTTLSeconds)
+	}
 
 	// Create TraderManager
 	traderManager := manager.NewTraderManager()
 
+	// Shared per-symbol entry throttle (prevents traders on one account piling into the same coin)
+	if cfg.SymbolThrottle.Enabled {
+		traderManager.EnableSymbolThrottle(cfg.SymbolThrottle.MaxEntries, cfg.SymbolThrottle.GetWindow())
+	}
+
 	// Add all enabled traders
 	enabledCount := 0
 	for i, traderCfg := range cfg.Traders {
@@ -109,6 +245,16 @@ func main() {
 		log.Fatalf("❌ No enabled traders found, please set at least one trader's enabled=true in config.json")
 	}
 
+	// Competition seasons (frozen configs are checked before any trader starts)
+	if err := traderManager.ConfigureSeasons(cfg.Seasons, cfg.SeasonArchiveDir); err != nil {
+		log.Fatalf("❌ Failed to configure seasons: %v", err)
+	}
+
+	// Imported benchmark trade histories compared against the traders (see cmd/import-benchmark)
+	if err := traderManager.LoadBenchmarks(cfg.BenchmarksDir); err != nil {
+		log.Printf("⚠️  Failed to load benchmarks: %v", err)
+	}
+
 	fmt.Println()
 	fmt.Println("🏁 Competition Participants:")
 	for _, traderCfg := range cfg.Traders {
@@ -134,28 +280,116 @@ func main() {
 	fmt.Println(strings.Repeat("=", 60))
 	fmt.Println()
 
-	// Create and start API server
-	apiServer := api.NewServer(traderManager, cfg.APIServerPort)
-	go func() {
-		if err := apiServer.Start(); err != nil {
-			log.Printf("❌ API server error: %v", err)
+	// Create and start API server (split deployments serve reads from `lia api-server` instead)
+	var apiServer *api.Server
+	if cfg.MessageBus.Enabled && cfg.MessageBus.DisableEngineAPI {
+		log.Printf("ℹ️  Engine API disabled (message_bus.disable_engine_api): HTTP traffic is served by the api-server process")
+	} else {
+		apiServer = api.NewServer(traderManager, cfg.APIServerPort)
+		if cfg.LowMemory.Enabled {
+			apiServer.SetLowMemoryMode(cfg.LowMemory.MaxHistoryRecords)
+		}
+		apiServer.SetCloseSafety(cfg.CloseSafety)
+		apiServer.SetAPIAuth(cfg.APIAuth)
+		apiServer.SetServerOptions(cfg.APIServer)
+		apiServer.SetPromptVersionsDir(cfg.PromptVersionsDir)
+		apiServer.SetPerformanceBenchmark(cfg.PerformanceBenchmark)
+		go func() {
+			if err := apiServer.Start(); err != nil {
+				log.Printf("❌ API server error: %v", err)
+			}
+		}()
+	}
+
+	// Publish engine state for the separate API process
+	stopPublisher := func() {}
+	if cfg.MessageBus.Enabled {
+		stateBus, err := bus.New(cfg.MessageBus)
+		if err != nil {
+			log.Printf("⚠️  Message bus unavailable, engine state will not be published: %v", err)
+		} else {
+			defer stateBus.Close()
+			stopPublisher = traderManager.StartStatePublisher(stateBus, cfg.MessageBus.SubjectPrefix,
+				time.Duration(cfg.MessageBus.PublishIntervalSeconds)*time.Second)
+		}
+	}
+
+	// Stream decision records, executions and equity snapshots to Kafka
+	var onTraderStart func(*trader.AutoTrader)
+	if cfg.KafkaExport.Enabled {
+		exporter := export.NewKafkaExporter(cfg.KafkaExport)
+		defer exporter.Close()
+		onTraderStart = func(t *trader.AutoTrader) {
+			t.GetDecisionLogger().SetRecordSink(exporter.ExportDecision)
 		}
-	}()
+		for _, t := range traderManager.GetAllTraders() {
+			onTraderStart(t)
+		}
+	}
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// Warm exchange metadata, coin pool and market data before the traders' first cycles
+	if cfg.Warmup.Enabled {
+		traderManager.WarmUp(cfg.Warmup)
+	}
+
+	// Set leverage and margin mode for the candidate universe so sizing never meets an unavailable leverage mid-cycle
+	if cfg.LeverageSetup.Enabled {
+		traderManager.SetupLeverage(cfg.LeverageSetup)
+	}
+
 	// Start all traders
 	traderManager.StartAll()
+	stopAlertMonitor := traderManager.StartAlertMonitor()
+
+	// Apply config.json edits (traders enabled/disabled, scan interval, leverage, auto take profit) without a restart
+	stopConfigWatch := func() {}
+	if cfg.ConfigReload.Enabled {
+		stop, err := traderManager.WatchConfig(configFile, time.Duration(cfg.ConfigReload.IntervalSeconds)*time.Second, onTraderStart)
+		if err != nil {
+			log.Printf("⚠️  Config hot reload unavailable: %v", err)
+		} else {
+			stopConfigWatch = stop
+		}
+	}
 
 	// Wait for shutdown signal
 	<-sigChan
 	fmt.Println()
 	fmt.Println()
 	log.Println("📛 Received shutdown signal, stopping all traders...")
-	traderManager.StopAll()
+	if apiServer != nil {
+		// Let in-flight requests (manual closes, trader controls) finish before the traders stop
+		ctx, cancel := context.WithTimeout(context.Background(), apiServer.ShutdownTimeout())
+		if err := apiServer.Stop(ctx); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+		cancel()
+	}
+	stopConfigWatch()
+	stopAlertMonitor()
+	stopMarketRefresh()
+	stopNews()
+	stopLiquidations()
+	stopPublisher()
+	traderManager.StopAll(time.Duration(cfg.CycleShutdownTimeoutSeconds) * time.Second)
 
 	fmt.Println()
 	fmt.Println("👋 Thank you for using the AI Automated Traders System!")
 }
+
+// applyLowMemoryProfile tunes the runtime and shared components for a ~256MB RSS budget
+func applyLowMemoryProfile(lm config.LowMemoryConfig) {
+	// Soft memory limit makes the GC work harder as the heap approaches the budget
+	debug.SetMemoryLimit(int64(lm.MemoryLimitMB) * 1024 * 1024)
+	debug.SetGCPercent(50)
+
+	// Fewer database connections (each one holds its own buffers)
+	logger.SetConnectionPoolLimits(lm.DBMaxOpenConns, lm.DBMaxIdleConns)
+
+	log.Printf("🪶 Low-memory mode enabled: memory limit %dMB, AI500 top %d, max %d candidates, raw responses kept: %v",
+		lm.MemoryLimitMB, lm.AI500Limit, lm.MaxCandidateCoins, lm.KeepRawResponse)
+}
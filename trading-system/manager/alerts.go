@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"lia/alert"
+	"lia/logging"
+	"lia/trader"
+	"time"
+)
+
+// alertCheckInterval how often the alert monitor checks the traders' databases
+const alertCheckInterval = time.Minute
+
+// StartAlertMonitor checks every trader's decision database each minute and alerts once one has been unreachable
+// for the configured time (ALERT_DB_DOWN_MINUTES). Returns a function that stops it
+func (tm *TraderManager) StartAlertMonitor() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(alertCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				tm.checkDatabases()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// checkDatabases pings each trader's decision database (JSON file storage has nothing to check)
+func (tm *TraderManager) checkDatabases() {
+	after := alert.Settings().DBDownAfter
+	for id, at := range tm.GetAllTraders() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		backend, err := at.GetDecisionLogger().PingDB(ctx)
+		cancel()
+		if backend == "json" {
+			continue
+		}
+		key := "database:" + id
+		subject := fmt.Sprintf("%s: %s database unreachable", at.GetName(), backend)
+		if err != nil {
+			alert.Failing(key, after, subject, err)
+		} else {
+			alert.Recovered(key, subject)
+		}
+	}
+}
+
+// alertTraderCrash alerts a trader goroutine that panicked or stopped with an error
+func alertTraderCrash(at *trader.AutoTrader, cause string) {
+	logging.ForTrader(at.GetID(), at.GetName()).Errorf("🚨 Crashed: %s", cause)
+	alert.Critical("crash:"+at.GetID(), fmt.Sprintf("%s crashed", at.GetName()),
+		fmt.Sprintf("Trader %s (%s, %s) stopped unexpectedly.\n\n%s", at.GetName(), at.GetID(), at.GetExchange(), cause))
+}
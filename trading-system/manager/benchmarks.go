@@ -0,0 +1,86 @@
+package manager
+
+import (
+	"lia/benchmark"
+	"sort"
+)
+
+// LoadBenchmarks loads the imported benchmarks (see cmd/import-benchmark) shown in the competition. A
+// benchmark whose ID belongs to a trader is skipped
+func (tm *TraderManager) LoadBenchmarks(dir string) error {
+	benchmarks, err := benchmark.LoadAll(dir)
+	if err != nil {
+		return err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.benchmarks = make(map[string]*benchmark.Benchmark)
+	for _, b := range benchmarks {
+		if _, exists := tm.traders[b.ID]; exists {
+			managerLog.Warnf("⚠️  Benchmark '%s' skipped: a trader has the same ID", b.ID)
+			continue
+		}
+		tm.benchmarks[b.ID] = b
+		latest := b.Latest()
+		managerLog.Infof("📏 Benchmark '%s' loaded: %d trades, equity %.2f USDT as of %s",
+			b.Name, b.TradeCount, latest.Equity, latest.Time.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// GetBenchmark an imported benchmark by ID
+func (tm *TraderManager) GetBenchmark(id string) (*benchmark.Benchmark, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	b, ok := tm.benchmarks[id]
+	return b, ok
+}
+
+// GetBenchmarks every imported benchmark, by ID
+func (tm *TraderManager) GetBenchmarks() []*benchmark.Benchmark {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	benchmarks := make([]*benchmark.Benchmark, 0, len(tm.benchmarks))
+	for _, b := range tm.benchmarks {
+		benchmarks = append(benchmarks, b)
+	}
+	sortBenchmarks(benchmarks)
+	return benchmarks
+}
+
+// appendBenchmarks adds the benchmarks to the competition entries, flagged "benchmark" (caller holds tm.mu)
+func (tm *TraderManager) appendBenchmarks(traders []map[string]interface{}) []map[string]interface{} {
+	benchmarks := make([]*benchmark.Benchmark, 0, len(tm.benchmarks))
+	for _, b := range tm.benchmarks {
+		benchmarks = append(benchmarks, b)
+	}
+	sortBenchmarks(benchmarks)
+
+	for _, b := range benchmarks {
+		latest := b.Latest()
+		pnl := latest.Equity - b.InitialBalance
+		traders = append(traders, map[string]interface{}{
+			"trader_id":       b.ID,
+			"trader_name":     b.Name,
+			"ai_model":        "benchmark",
+			"total_equity":    latest.Equity,
+			"total_pnl":       pnl,
+			"total_pnl_pct":   pnl / b.InitialBalance * 100,
+			"position_count":  latest.PositionCount,
+			"margin_used_pct": 0.0,
+			"call_count":      0,
+			"is_running":      false,
+			"completed":       false,
+			"benchmark":       true,
+			"trade_count":     b.TradeCount,
+			"as_of":           latest.Time,
+		})
+	}
+	return traders
+}
+
+// sortBenchmarks by ID
+func sortBenchmarks(benchmarks []*benchmark.Benchmark) {
+	sort.Slice(benchmarks, func(i, j int) bool { return benchmarks[i].ID < benchmarks[j].ID })
+}
@@ -0,0 +1,255 @@
+package manager
+
+import (
+	"fmt"
+	"lia/config"
+	"lia/logging"
+	"lia/trader"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fields applied to running traders by a reload (json names); changes to any other field need a restart
+var (
+	reloadableGlobalFields = []string{"traders", "leverage", "auto_take_profit_pct"}
+	reloadableTraderFields = []string{"enabled", "scan_interval_minutes"}
+)
+
+// configReloader polls the config file and applies safe changes to the running traders
+type configReloader struct {
+	tm      *TraderManager
+	path    string
+	current *config.Config
+	modTime time.Time
+	size    int64
+	onStart func(*trader.AutoTrader) // Called for each trader a reload starts (nil = none)
+}
+
+// WatchConfig polls the config file every interval and applies changes to the running traders: traders are
+// started or stopped as they are enabled or disabled, and scan_interval_minutes, leverage and auto_take_profit_pct
+// take effect from the next cycle. Other changes (credentials included) are logged as needing a restart.
+// onStart is called for each trader a reload starts, before its first cycle. Returns a function that stops watching
+func (tm *TraderManager) WatchConfig(path string, interval time.Duration, onStart func(*trader.AutoTrader)) (func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch config: %w", err)
+	}
+	current, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config baseline: %w", err)
+	}
+
+	r := &configReloader{
+		tm:      tm,
+		path:    path,
+		current: current,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		onStart: onStart,
+	}
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.check()
+			}
+		}
+	}()
+
+	managerLog.Infof("✓ Config hot reload enabled: checking %s every %v", path, interval)
+	var once sync.Once
+	return func() { once.Do(func() { close(stop) }) }, nil
+}
+
+// check reloads the config file if it changed since the last check
+func (r *configReloader) check() {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		managerLog.Warnf("⚠️  Config reload: %v", err)
+		return
+	}
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return
+	}
+	r.modTime, r.size = info.ModTime(), info.Size()
+
+	next, err := config.LoadConfig(r.path)
+	if err != nil {
+		// Also hit when an editor is midway through writing the file; the finished write is picked up next check
+		managerLog.Warnf("⚠️  Config reload: %s is invalid, keeping the running config: %v", r.path, err)
+		return
+	}
+	managerLog.Infof("🔄 Config change detected in %s", r.path)
+	r.apply(next)
+	r.current = next
+}
+
+// apply starts, stops and updates traders for the new config and logs the changes that need a restart
+func (r *configReloader) apply(next *config.Config) {
+	restart := changedFields(*r.current, *next, reloadableGlobalFields)
+
+	previous := make(map[string]config.TraderConfig, len(r.current.Traders))
+	for _, tc := range r.current.Traders {
+		previous[tc.ID] = tc
+	}
+
+	for _, tc := range next.Traders {
+		old, existed := previous[tc.ID]
+		delete(previous, tc.ID)
+		_, err := r.tm.GetTrader(tc.ID)
+		running := err == nil
+
+		switch {
+		case tc.Enabled && !running:
+			r.startTrader(tc, next)
+			continue // Started with the new config, nothing else to compare
+		case !tc.Enabled && running:
+			r.stopTrader(tc.ID)
+			continue
+		case running:
+			r.updateTrader(tc, next)
+		default:
+			continue // Disabled and not running
+		}
+		if existed {
+			for _, field := range changedFields(old, tc, reloadableTraderFields) {
+				restart = append(restart, fmt.Sprintf("traders[%s].%s", tc.ID, field))
+			}
+		}
+	}
+
+	// Traders removed from the file
+	for id := range previous {
+		if _, err := r.tm.GetTrader(id); err == nil {
+			r.stopTrader(id)
+		}
+	}
+
+	if len(restart) > 0 {
+		sort.Strings(restart)
+		managerLog.Warnf("⚠️  Config reload: restart required to apply: %s", strings.Join(restart, ", "))
+	}
+}
+
+// startTrader adds and starts a trader enabled by the reload
+func (r *configReloader) startTrader(tc config.TraderConfig, cfg *config.Config) {
+	err := r.tm.AddTrader(tc, cfg.CoinPoolAPIURL, cfg.MaxDailyLoss, cfg.MaxDrawdown, cfg.StopTradingMinutes, cfg.Leverage, cfg)
+	if err != nil {
+		logging.ForTrader(tc.ID, tc.Name).Errorf("❌ Config reload: failed to start trader: %v", err)
+		return
+	}
+	at, err := r.tm.GetTrader(tc.ID)
+	if err != nil {
+		return
+	}
+	if r.onStart != nil {
+		r.onStart(at)
+	}
+	logging.ForTrader(tc.ID, tc.Name).Infof("▶️  Config reload: trader enabled")
+	go runTrader(at)
+}
+
+// stopTrader stops a trader disabled (or removed) by the reload, unless a running season froze its config
+func (r *configReloader) stopTrader(id string) {
+	traderLog := managerLog.With(logging.FieldTraderID, id)
+	if season := r.tm.activeSeason(id); season != "" {
+		traderLog.Warnf("⚠️  Config reload: trader '%s' stays enabled - season %s is running and its config is frozen", id, season)
+		return
+	}
+	if err := r.tm.StopTrader(id); err != nil {
+		traderLog.Errorf("❌ Config reload: failed to stop trader '%s': %v", id, err)
+		return
+	}
+	traderLog.Infof("⏹  Config reload: trader '%s' disabled", id)
+}
+
+// updateTrader applies the hot-reloadable settings to a running trader
+func (r *configReloader) updateTrader(tc config.TraderConfig, cfg *config.Config) {
+	at, err := r.tm.GetTrader(tc.ID)
+	if err != nil {
+		return
+	}
+	settings := trader.RuntimeSettings{
+		ScanInterval:      tc.GetScanInterval(),
+		BTCETHLeverage:    cfg.Leverage.BTCETHLeverage,
+		AltcoinLeverage:   cfg.Leverage.AltcoinLeverage,
+		AutoTakeProfitPct: cfg.AutoTakeProfitPct,
+	}
+	if settings == at.RuntimeSettings() {
+		return
+	}
+	if season := r.tm.activeSeason(tc.ID); season != "" {
+		logging.ForTrader(tc.ID, tc.Name).Warnf("⚠️  Config reload: changes ignored - season %s is running and its config is frozen", season)
+		return
+	}
+
+	at.ApplyRuntimeSettings(settings)
+
+	// A season starting later freezes the settings the trader actually runs with
+	r.tm.mu.Lock()
+	if frozen, ok := r.tm.seasonSettings[tc.ID]; ok {
+		frozen.ScanIntervalMinutes = tc.ScanIntervalMinutes
+		frozen.BTCETHLeverage = cfg.Leverage.BTCETHLeverage
+		frozen.AltcoinLeverage = cfg.Leverage.AltcoinLeverage
+		frozen.AutoTakeProfitPct = cfg.AutoTakeProfitPct
+		r.tm.seasonSettings[tc.ID] = frozen
+	}
+	r.tm.mu.Unlock()
+}
+
+// activeSeason ID of the running season that froze the trader's config ("" = none)
+func (tm *TraderManager) activeSeason(traderID string) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	for _, season := range tm.seasons {
+		if _, frozen := season.FrozenConfigs[traderID]; frozen && season.Status == SeasonActive {
+			return season.ID
+		}
+	}
+	return ""
+}
+
+// changedFields json names of the struct fields that differ between a and b, except skip. Credential
+// fields are marked as such; their values are never logged
+func changedFields(a, b interface{}, skip []string) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var changed []string
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		if slices.Contains(skip, name) || reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+		if isCredentialField(name) {
+			name += " (credentials)"
+		}
+		changed = append(changed, name)
+	}
+	return changed
+}
+
+// isCredentialField whether a config field holds an API key, secret or similar
+func isCredentialField(name string) bool {
+	for _, marker := range []string{"key", "secret", "passphrase", "password", "token"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
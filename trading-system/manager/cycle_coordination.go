@@ -0,0 +1,53 @@
+package manager
+
+import (
+	"lia/config"
+	"lia/decision"
+	"lia/market"
+	"lia/mcp"
+	"sort"
+	"time"
+)
+
+// aiProviders the providers a default concurrency limit applies to
+var aiProviders = []mcp.Provider{mcp.ProviderGroq, mcp.ProviderQwen, mcp.ProviderDeepSeek, mcp.ProviderAnthropic, mcp.ProviderGemini, mcp.ProviderCustom}
+
+// ConfigureCycleCoordination applies cycle_coordination to every trader of the process: concurrent market data
+// fetches within a cycle, a market data snapshot shared by the traders of a tick (unless market_data already
+// caches it) and per-provider AI call limits
+func ConfigureCycleCoordination(cfg config.CycleCoordinationConfig) {
+	if !cfg.Enabled {
+		return
+	}
+
+	decision.SetMarketFetchConcurrency(cfg.FetchConcurrency)
+	if market.CacheTTL() <= 0 {
+		market.SetCacheTTL(time.Duration(cfg.SnapshotTTLSeconds) * time.Second)
+		managerLog.Infof("✓ Cycle coordination: market data shared between traders for %ds", cfg.SnapshotTTLSeconds)
+	}
+	managerLog.Infof("✓ Cycle coordination: %d market data requests in flight per cycle", cfg.FetchConcurrency)
+
+	if cfg.DefaultAIConcurrent > 0 {
+		for _, provider := range aiProviders {
+			if _, ok := cfg.AIProviderLimits[string(provider)]; !ok {
+				mcp.SetProviderLimit(provider, mcp.ProviderLimit{MaxConcurrent: cfg.DefaultAIConcurrent})
+			}
+		}
+		managerLog.Infof("✓ Cycle coordination: at most %d AI calls in flight per provider", cfg.DefaultAIConcurrent)
+	}
+
+	providers := make([]string, 0, len(cfg.AIProviderLimits))
+	for provider := range cfg.AIProviderLimits {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		limit := cfg.AIProviderLimits[provider]
+		mcp.SetProviderLimit(mcp.Provider(provider), mcp.ProviderLimit{
+			MaxConcurrent: limit.MaxConcurrent,
+			MinSpacing:    time.Duration(limit.MinSpacingMs) * time.Millisecond,
+		})
+		managerLog.Infof("✓ Cycle coordination: %s AI calls limited to %d in flight, %dms apart (0 = no limit)",
+			provider, limit.MaxConcurrent, limit.MinSpacingMs)
+	}
+}
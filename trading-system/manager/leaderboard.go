@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"fmt"
+	"lia/logger"
+	"sort"
+	"time"
+)
+
+// LeaderboardWindow the part of the history a leaderboard ranks
+type LeaderboardWindow struct {
+	Label      string    // As requested ("24h", "7d", "since_cycle=120", "all")
+	Since      time.Time // Cycles at or after this time (zero = no bound)
+	SinceCycle int       // Cycles at or after this cycle number (0 = no bound)
+}
+
+// LeaderboardEntry a trader's results over the window
+type LeaderboardEntry struct {
+	Rank           int       `json:"rank"` // 0 = no cycles in the window
+	TraderID       string    `json:"trader_id"`
+	TraderName     string    `json:"trader_name"`
+	AIModel        string    `json:"ai_model"`
+	IsRunning      bool      `json:"is_running"`
+	StartEquity    float64   `json:"start_equity"` // First cycle in the window
+	Equity         float64   `json:"equity"`       // Live equity (the last cycle's when the exchange is unreachable)
+	PnL            float64   `json:"pnl"`
+	PnLPct         float64   `json:"pnl_pct"`
+	SharpeRatio    float64   `json:"sharpe_ratio"` // Per-cycle returns in the window
+	MaxDrawdownPct float64   `json:"max_drawdown_pct"`
+	Cycles         int       `json:"cycles"`
+	Trades         int       `json:"trades"` // Closed in the window
+	WinRate        float64   `json:"win_rate"`
+	StartTime      time.Time `json:"start_time,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Leaderboard traders ranked by P&L % over a window
+type Leaderboard struct {
+	Window     string             `json:"window"`
+	Since      *time.Time         `json:"since,omitempty"`
+	SinceCycle int                `json:"since_cycle,omitempty"`
+	AsOf       time.Time          `json:"as_of"`
+	Entries    []LeaderboardEntry `json:"entries"`
+}
+
+// GetLeaderboard ranks every trader by P&L % since the window's first logged cycle. Traders without cycles in
+// the window are listed last, unranked
+func (tm *TraderManager) GetLeaderboard(window LeaderboardWindow) *Leaderboard {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	board := &Leaderboard{
+		Window:     window.Label,
+		SinceCycle: window.SinceCycle,
+		AsOf:       time.Now(),
+		Entries:    make([]LeaderboardEntry, 0, len(tm.traders)),
+	}
+	if !window.Since.IsZero() {
+		board.Since = &window.Since
+	}
+	for id, t := range tm.traders {
+		entry := LeaderboardEntry{
+			TraderID:   id,
+			TraderName: t.GetName(),
+			AIModel:    t.GetAIModel(),
+		}
+		if status := t.GetStatus(); status != nil {
+			entry.IsRunning, _ = status["is_running"].(bool)
+		}
+
+		decisionLogger := t.GetDecisionLogger()
+		if decisionLogger == nil {
+			entry.Error = "no decision log"
+			board.Entries = append(board.Entries, entry)
+			continue
+		}
+		equityWindow, err := decisionLogger.GetEquityWindow(window.Since, window.SinceCycle)
+		if err != nil {
+			entry.Error = fmt.Sprintf("failed to read equity history: %v", err)
+			board.Entries = append(board.Entries, entry)
+			continue
+		}
+		entry.Cycles = equityWindow.Cycles
+		if equityWindow.Cycles == 0 {
+			board.Entries = append(board.Entries, entry)
+			continue
+		}
+
+		entry.StartTime = equityWindow.StartTime
+		entry.StartEquity = equityWindow.StartEquity
+		entry.Equity = equityWindow.EndEquity
+		if equity, err := traderEquity(t); err == nil {
+			entry.Equity = equity
+		}
+		entry.PnL = entry.Equity - entry.StartEquity
+		entry.PnLPct = entry.PnL / entry.StartEquity * 100
+		entry.SharpeRatio = equityWindow.SharpeRatio
+		entry.MaxDrawdownPct = equityWindow.MaxDrawdownPct
+
+		trades, err := decisionLogger.GetTrades(logger.TradeClosed, 0)
+		if err != nil {
+			managerLog.Warnf("⚠️  Leaderboard: failed to load %s trades: %v", id, err)
+		}
+		wins := 0
+		for _, trade := range trades {
+			if trade.CloseTime.Before(equityWindow.StartTime) {
+				continue
+			}
+			entry.Trades++
+			if trade.NetPnL > 0 {
+				wins++
+			}
+		}
+		if entry.Trades > 0 {
+			entry.WinRate = float64(wins) / float64(entry.Trades) * 100
+		}
+		board.Entries = append(board.Entries, entry)
+	}
+
+	sort.SliceStable(board.Entries, func(i, j int) bool {
+		a, b := board.Entries[i], board.Entries[j]
+		if (a.Cycles > 0) != (b.Cycles > 0) {
+			return a.Cycles > 0
+		}
+		if a.PnLPct != b.PnLPct {
+			return a.PnLPct > b.PnLPct
+		}
+		return a.TraderID < b.TraderID
+	})
+	for i := range board.Entries {
+		if board.Entries[i].Cycles > 0 {
+			board.Entries[i].Rank = i + 1
+		}
+	}
+	return board
+}
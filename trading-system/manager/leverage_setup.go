@@ -0,0 +1,23 @@
+package manager
+
+import (
+	"lia/config"
+	"time"
+)
+
+// SetupLeverage configures leverage and margin mode for every trader's candidate universe before StartAll.
+// Traders run one after another (they may share an exchange account); failures are logged and never block startup
+func (tm *TraderManager) SetupLeverage(cfg config.LeverageSetupConfig) {
+	traders := tm.GetAllTraders()
+	managerLog.Infof("🎚️  Configuring leverage for %d traders...", len(traders))
+	start := time.Now()
+
+	clamped, failed := 0, 0
+	for _, at := range traders {
+		report := at.SetupLeverage(cfg.ExtraSymbols)
+		clamped += len(report.Clamped)
+		failed += len(report.Failed)
+	}
+	managerLog.Infof("✓ Leverage setup finished in %s (%d symbols with reduced leverage, %d failed - see /api/status leverage_setup)",
+		time.Since(start).Round(time.Millisecond), clamped, failed)
+}
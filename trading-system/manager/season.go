@@ -0,0 +1,484 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/config"
+	"lia/logging"
+	"lia/trader"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Season status
+const (
+	SeasonScheduled = "scheduled" // Start time not reached
+	SeasonActive    = "active"    // Running: baselines taken, configs frozen
+	SeasonArchived  = "archived"  // Ended: final standings written to the archive
+)
+
+const seasonClockInterval = time.Minute
+
+// SeasonTraderConfig competition-relevant trader settings frozen for the duration of a season (credentials excluded)
+type SeasonTraderConfig struct {
+	AIModel                 string  `json:"ai_model"`
+	Exchange                string  `json:"exchange"`
+	GroqModel               string  `json:"groq_model,omitempty"`
+	AnthropicModel          string  `json:"anthropic_model,omitempty"`
+	GeminiModel             string  `json:"gemini_model,omitempty"`
+	CustomAPIURL            string  `json:"custom_api_url,omitempty"`
+	CustomModelName         string  `json:"custom_model_name,omitempty"`
+	InitialBalance          float64 `json:"initial_balance"`
+	ScanIntervalMinutes     float64 `json:"scan_interval_minutes"`
+	BTCETHLeverage          int     `json:"btc_eth_leverage"`
+	AltcoinLeverage         int     `json:"altcoin_leverage"`
+	MaxDailyLoss            float64 `json:"max_daily_loss"`
+	MaxDrawdown             float64 `json:"max_drawdown"`
+	AutoTakeProfitPct       float64 `json:"auto_take_profit_pct"`
+	BackgroundTakeProfitPct float64 `json:"background_take_profit_pct,omitempty"` // Omitted by seasons frozen before it was configurable
+	CopyFromTraderID        string  `json:"copy_from_trader_id,omitempty"`
+	AdaptivePool            bool    `json:"adaptive_pool"`
+	TradeMemory             bool    `json:"trade_memory"`
+}
+
+// newSeasonTraderConfig extracts the settings frozen by a season
+func newSeasonTraderConfig(cfg config.TraderConfig, maxDailyLoss, maxDrawdown float64, leverage config.LeverageConfig, globalConfig *config.Config) SeasonTraderConfig {
+	settings := SeasonTraderConfig{
+		AIModel:                 cfg.AIModel,
+		Exchange:                cfg.Exchange,
+		GroqModel:               cfg.GroqModel,
+		AnthropicModel:          cfg.AnthropicModel,
+		GeminiModel:             cfg.GeminiModel,
+		CustomAPIURL:            cfg.CustomAPIURL,
+		CustomModelName:         cfg.CustomModelName,
+		InitialBalance:          cfg.InitialBalance,
+		ScanIntervalMinutes:     cfg.ScanIntervalMinutes,
+		BTCETHLeverage:          leverage.BTCETHLeverage,
+		AltcoinLeverage:         leverage.AltcoinLeverage,
+		MaxDailyLoss:            maxDailyLoss,
+		MaxDrawdown:             maxDrawdown,
+		CopyFromTraderID:        cfg.CopyFromTraderID,
+		BackgroundTakeProfitPct: cfg.BackgroundTakeProfitPct,
+	}
+	if globalConfig != nil {
+		settings.AutoTakeProfitPct = globalConfig.AutoTakeProfitPct
+		settings.AdaptivePool = globalConfig.AdaptivePool.Enabled
+		settings.TradeMemory = globalConfig.TradeMemory.Enabled
+	}
+	return settings
+}
+
+// diff lists the settings that differ from the frozen ones
+func (s SeasonTraderConfig) diff(frozen SeasonTraderConfig) []string {
+	var current, original map[string]interface{}
+	a, _ := json.Marshal(s)
+	b, _ := json.Marshal(frozen)
+	json.Unmarshal(a, &current)
+	json.Unmarshal(b, &original)
+
+	var changed []string
+	for key, value := range original {
+		if fmt.Sprint(current[key]) != fmt.Sprint(value) {
+			changed = append(changed, fmt.Sprintf("%s: %v → %v", key, value, current[key]))
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// SeasonBaseline trader equity when the season started
+type SeasonBaseline struct {
+	Equity  float64   `json:"equity"`
+	TakenAt time.Time `json:"taken_at"`
+	Late    bool      `json:"late,omitempty"` // Taken after the season start (system was down at start)
+}
+
+// SeasonStanding a trader's seasonal leaderboard entry
+type SeasonStanding struct {
+	Rank           int     `json:"rank"`
+	TraderID       string  `json:"trader_id"`
+	TraderName     string  `json:"trader_name"`
+	AIModel        string  `json:"ai_model"`
+	BaselineEquity float64 `json:"baseline_equity"`
+	Equity         float64 `json:"equity"`
+	PnL            float64 `json:"pnl"`
+	PnLPct         float64 `json:"pnl_pct"`
+	Opens          int     `json:"opens"`  // Positions opened during the season
+	Closes         int     `json:"closes"` // Positions closed during the season
+	Error          string  `json:"error,omitempty"`
+}
+
+// SeasonRecord persisted season state (<season_archive_dir>/<id>.json)
+type SeasonRecord struct {
+	ID             string                        `json:"id"`
+	Name           string                        `json:"name"`
+	Start          time.Time                     `json:"start"`
+	End            time.Time                     `json:"end"`
+	Traders        []string                      `json:"traders"`
+	Status         string                        `json:"status"`
+	FrozenConfigs  map[string]SeasonTraderConfig `json:"frozen_configs,omitempty"`
+	Baselines      map[string]SeasonBaseline     `json:"baselines,omitempty"`
+	FinalStandings []SeasonStanding              `json:"final_standings,omitempty"`
+	ArchivedAt     time.Time                     `json:"archived_at,omitempty"`
+}
+
+// SeasonLeaderboard standings for a season (live while active, final once archived)
+type SeasonLeaderboard struct {
+	SeasonID  string           `json:"season_id"`
+	Name      string           `json:"name"`
+	Status    string           `json:"status"`
+	Start     time.Time        `json:"start"`
+	End       time.Time        `json:"end"`
+	AsOf      time.Time        `json:"as_of"`
+	Final     bool             `json:"final"`
+	Standings []SeasonStanding `json:"standings"`
+}
+
+// seasonPath file holding a season's state
+func (tm *TraderManager) seasonPath(id string) string {
+	return filepath.Join(tm.seasonDir, id+".json")
+}
+
+// ConfigureSeasons loads season state, freezes/validates trader configs for running seasons and
+// starts the season clock (call after all traders are added). Returns an error if a trader's config
+// was changed while a season it participates in is running.
+func (tm *TraderManager) ConfigureSeasons(seasons []config.SeasonConfig, dir string) error {
+	if len(seasons) == 0 {
+		return nil
+	}
+
+	tm.mu.Lock()
+	tm.seasonDir = dir
+	for _, sc := range seasons {
+		start, _ := sc.GetStart()
+		end, _ := sc.GetEnd()
+		record := &SeasonRecord{ID: sc.ID, Name: sc.Name, Start: start, End: end, Traders: sc.Traders, Status: SeasonScheduled}
+		if len(record.Traders) == 0 {
+			for id := range tm.traders {
+				record.Traders = append(record.Traders, id)
+			}
+			sort.Strings(record.Traders)
+		}
+
+		if saved, err := loadSeasonRecord(tm.seasonPath(sc.ID)); err != nil {
+			tm.mu.Unlock()
+			return fmt.Errorf("season %s: %w", sc.ID, err)
+		} else if saved != nil {
+			if saved.Status != SeasonScheduled && (!saved.Start.Equal(start) || !saved.End.Equal(end)) {
+				tm.mu.Unlock()
+				return fmt.Errorf("season %s: dates cannot change once the season has started (saved %s → %s)",
+					sc.ID, saved.Start.Format(time.RFC3339), saved.End.Format(time.RFC3339))
+			}
+			if saved.Status != SeasonScheduled {
+				record = saved
+			}
+		}
+
+		// Frozen configs: a running season's participants must keep their settings
+		if record.Status == SeasonActive {
+			for traderID, frozen := range record.FrozenConfigs {
+				current, ok := tm.seasonSettings[traderID]
+				if !ok {
+					continue
+				}
+				if changed := current.diff(frozen); len(changed) > 0 {
+					tm.mu.Unlock()
+					return fmt.Errorf("season %s is running and trader %s config is frozen until %s - revert: %s",
+						record.ID, traderID, record.End.Format(time.RFC3339), strings.Join(changed, "; "))
+				}
+			}
+		}
+		tm.seasons = append(tm.seasons, record)
+	}
+	tm.mu.Unlock()
+
+	tm.updateSeasons(time.Now())
+	go func() {
+		ticker := time.NewTicker(seasonClockInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			tm.updateSeasons(now)
+		}
+	}()
+	managerLog.Infof("✓ Competition seasons configured: %d (state in %s)", len(seasons), dir)
+	return nil
+}
+
+// updateSeasons starts seasons whose start time has passed and archives the ones that ended
+func (tm *TraderManager) updateSeasons(now time.Time) {
+	tm.mu.RLock()
+	seasons := append([]*SeasonRecord(nil), tm.seasons...)
+	tm.mu.RUnlock()
+
+	for _, season := range seasons {
+		switch {
+		case season.Status == SeasonScheduled && !now.Before(season.Start) && now.Before(season.End):
+			tm.startSeason(season, now)
+		case season.Status != SeasonArchived && !now.Before(season.End):
+			if season.Status == SeasonScheduled {
+				// Down for the whole season: archive with whatever baseline we can take now
+				tm.startSeason(season, now)
+			}
+			tm.archiveSeason(season, now)
+		}
+	}
+}
+
+// startSeason takes baseline equity snapshots and freezes participant configs
+func (tm *TraderManager) startSeason(season *SeasonRecord, now time.Time) {
+	late := now.Sub(season.Start) > 2*seasonClockInterval
+	baselines := make(map[string]SeasonBaseline)
+	frozen := make(map[string]SeasonTraderConfig)
+
+	tm.mu.RLock()
+	for _, traderID := range season.Traders {
+		t, ok := tm.traders[traderID]
+		if !ok {
+			continue
+		}
+		equity, err := traderEquity(t)
+		if err != nil {
+			managerLog.With(logging.FieldTraderID, traderID).Warnf("⚠️  Season %s: failed to snapshot %s baseline equity: %v - using initial balance", season.ID, traderID, err)
+			equity = t.GetInitialBalance()
+		}
+		baselines[traderID] = SeasonBaseline{Equity: equity, TakenAt: now, Late: late}
+		frozen[traderID] = tm.seasonSettings[traderID]
+	}
+	tm.mu.RUnlock()
+
+	tm.mu.Lock()
+	season.Baselines = baselines
+	season.FrozenConfigs = frozen
+	season.Status = SeasonActive
+	tm.mu.Unlock()
+
+	if late {
+		managerLog.Infof("🏁 Season %s started (baseline taken late at %s, season start was %s)",
+			season.ID, now.Format(time.RFC3339), season.Start.Format(time.RFC3339))
+	} else {
+		managerLog.Infof("🏁 Season %s started: %d traders, ends %s", season.ID, len(baselines), season.End.Format(time.RFC3339))
+	}
+	tm.saveSeason(season)
+}
+
+// archiveSeason records the final standings and marks the season archived
+func (tm *TraderManager) archiveSeason(season *SeasonRecord, now time.Time) {
+	standings := tm.seasonStandings(season, season.End)
+
+	tm.mu.Lock()
+	season.FinalStandings = standings
+	season.Status = SeasonArchived
+	season.ArchivedAt = now
+	tm.mu.Unlock()
+
+	if len(standings) > 0 {
+		managerLog.Infof("🏆 Season %s archived - winner: %s (%+.2f%%)", season.ID, standings[0].TraderName, standings[0].PnLPct)
+	} else {
+		managerLog.Infof("🏆 Season %s archived (no participants)", season.ID)
+	}
+	tm.saveSeason(season)
+}
+
+// seasonStandings ranks a season's traders by equity change since their baseline
+func (tm *TraderManager) seasonStandings(season *SeasonRecord, until time.Time) []SeasonStanding {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if now := time.Now(); until.After(now) {
+		until = now
+	}
+
+	standings := make([]SeasonStanding, 0, len(season.Traders))
+	for _, traderID := range season.Traders {
+		t, ok := tm.traders[traderID]
+		baseline, hasBaseline := season.Baselines[traderID]
+		if !ok || !hasBaseline {
+			continue
+		}
+
+		standing := SeasonStanding{
+			TraderID:       traderID,
+			TraderName:     t.GetName(),
+			AIModel:        t.GetAIModel(),
+			BaselineEquity: baseline.Equity,
+			Equity:         baseline.Equity,
+		}
+		if equity, err := traderEquity(t); err != nil {
+			standing.Error = fmt.Sprintf("failed to get equity: %v", err)
+		} else {
+			standing.Equity = equity
+		}
+		standing.PnL = standing.Equity - baseline.Equity
+		if baseline.Equity > 0 {
+			standing.PnLPct = standing.PnL / baseline.Equity * 100
+		}
+
+		if decisionLogger := t.GetDecisionLogger(); decisionLogger != nil {
+			actions, err := decisionLogger.GetActionsInRange(baseline.TakenAt, until)
+			if err != nil {
+				managerLog.With(logging.FieldTraderID, traderID).Warnf("⚠️  Season %s: failed to load %s actions: %v", season.ID, traderID, err)
+			}
+			for _, action := range actions {
+				if !action.Success {
+					continue
+				}
+				if strings.HasPrefix(action.Action, "open_") {
+					standing.Opens++
+				} else if strings.HasPrefix(action.Action, "close_") {
+					standing.Closes++
+				}
+			}
+		}
+		standings = append(standings, standing)
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].PnLPct > standings[j].PnLPct
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+// GetSeasons returns every configured season (without standings)
+func (tm *TraderManager) GetSeasons() []SeasonRecord {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	seasons := make([]SeasonRecord, 0, len(tm.seasons))
+	for _, season := range tm.seasons {
+		summary := *season
+		summary.FinalStandings = nil
+		seasons = append(seasons, summary)
+	}
+	return seasons
+}
+
+// GetSeasonLeaderboard returns a season's standings ("" = the running season, else the most recent one)
+func (tm *TraderManager) GetSeasonLeaderboard(seasonID string) (*SeasonLeaderboard, error) {
+	season := tm.findSeason(seasonID)
+	if season == nil {
+		if seasonID == "" {
+			return nil, fmt.Errorf("no seasons configured")
+		}
+		return nil, fmt.Errorf("season %s not found", seasonID)
+	}
+
+	tm.mu.RLock()
+	board := &SeasonLeaderboard{
+		SeasonID: season.ID,
+		Name:     season.Name,
+		Status:   season.Status,
+		Start:    season.Start,
+		End:      season.End,
+		AsOf:     time.Now(),
+	}
+	status := season.Status
+	final := season.FinalStandings
+	tm.mu.RUnlock()
+
+	switch status {
+	case SeasonArchived:
+		board.Final = true
+		board.AsOf = season.End
+		board.Standings = final
+	case SeasonActive:
+		board.Standings = tm.seasonStandings(season, season.End)
+	default:
+		board.Standings = []SeasonStanding{}
+	}
+	return board, nil
+}
+
+// findSeason finds a season by ID; "" picks the running season, else the latest started one, else the next one
+func (tm *TraderManager) findSeason(seasonID string) *SeasonRecord {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if seasonID != "" {
+		for _, season := range tm.seasons {
+			if season.ID == seasonID {
+				return season
+			}
+		}
+		return nil
+	}
+
+	var latest, next *SeasonRecord
+	for _, season := range tm.seasons {
+		switch season.Status {
+		case SeasonActive:
+			return season
+		case SeasonArchived:
+			if latest == nil || season.End.After(latest.End) {
+				latest = season
+			}
+		default:
+			if next == nil || season.Start.Before(next.Start) {
+				next = season
+			}
+		}
+	}
+	if latest != nil {
+		return latest
+	}
+	return next
+}
+
+// traderEquity current total equity of a trader
+func traderEquity(t *trader.AutoTrader) (float64, error) {
+	account, err := t.GetAccountInfo()
+	if err != nil {
+		return 0, err
+	}
+	equity, ok := account["total_equity"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("account info has no total_equity")
+	}
+	return equity, nil
+}
+
+// loadSeasonRecord reads a saved season (nil if it was never started)
+func loadSeasonRecord(path string) (*SeasonRecord, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read season state: %w", err)
+	}
+	var record SeasonRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse season state %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// saveSeason persists a season's state
+func (tm *TraderManager) saveSeason(season *SeasonRecord) {
+	tm.mu.RLock()
+	data, err := json.MarshalIndent(season, "", "  ")
+	path := tm.seasonPath(season.ID)
+	tm.mu.RUnlock()
+	if err != nil {
+		managerLog.Warnf("⚠️  Failed to serialize season %s: %v", season.ID, err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		managerLog.Warnf("⚠️  Failed to create season directory: %v", err)
+		return
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		managerLog.Warnf("⚠️  Failed to write season %s: %v", season.ID, err)
+		return
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		managerLog.Warnf("⚠️  Failed to save season %s: %v", season.ID, err)
+	}
+}
@@ -0,0 +1,82 @@
+package manager
+
+import (
+	"lia/bus"
+	"lia/trader"
+	"time"
+)
+
+// StartStatePublisher publishes every trader's state (status, account, positions, latest decisions,
+// statistics, decision context) and the competition overview to the bus every interval, so a separate
+// API process can serve read traffic without touching the engine. Returns a function that stops it
+func (tm *TraderManager) StartStatePublisher(b bus.Bus, prefix string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			tm.publishState(b, prefix)
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+	managerLog.Infof("📡 Publishing engine state to the message bus every %s (prefix '%s')", interval, prefix)
+	return func() { close(done) }
+}
+
+// publishState publishes one snapshot of all traders; failures are logged and retried next interval
+func (tm *TraderManager) publishState(b bus.Bus, prefix string) {
+	traders := tm.GetAllTraders()
+	failed := 0
+	publish := func(traderID, topic string, v interface{}) {
+		if err := bus.PublishJSON(b, prefix, traderID, topic, v); err != nil {
+			failed++
+			if failed == 1 {
+				managerLog.Warnf("⚠️  Failed to publish state: %v", err)
+			}
+		}
+	}
+
+	list := make([]map[string]interface{}, 0, len(traders))
+	for id, t := range traders {
+		list = append(list, map[string]interface{}{
+			"trader_id":   t.GetID(),
+			"trader_name": t.GetName(),
+			"ai_model":    t.GetAIModel(),
+		})
+		tm.publishTrader(id, t, publish)
+	}
+	publish("", bus.TopicTraders, list)
+
+	if comparison, err := tm.GetComparisonData(); err == nil {
+		publish("", bus.TopicCompetition, comparison)
+	}
+}
+
+// publishTrader publishes one trader's snapshots (a topic whose data cannot be read this time is skipped)
+func (tm *TraderManager) publishTrader(id string, t *trader.AutoTrader, publish func(traderID, topic string, v interface{})) {
+	publish(id, bus.TopicStatus, t.GetStatus())
+
+	if account, err := t.GetAccountInfo(); err == nil {
+		publish(id, bus.TopicAccount, account)
+	}
+	if positions, err := t.GetPositions(); err == nil {
+		publish(id, bus.TopicPositions, positions)
+	}
+	if records, err := t.GetDecisionLogger().GetLatestRecords(10); err == nil {
+		// Newest first, as served by /api/decisions/latest
+		for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+			records[i], records[j] = records[j], records[i]
+		}
+		publish(id, bus.TopicDecisions, records)
+	}
+	if stats, err := t.GetDecisionLogger().GetStatistics(); err == nil {
+		publish(id, bus.TopicStatistics, stats)
+	}
+	if ctx := t.GetLastContext(); ctx != nil {
+		publish(id, bus.TopicContext, ctx)
+	}
+}
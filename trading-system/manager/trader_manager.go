@@ -1,26 +1,68 @@
 package manager
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"lia/benchmark"
 	"lia/config"
+	"lia/decision"
+	"lia/logging"
 	"lia/trader"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 )
 
+// managerLog the trader manager's logger
+var managerLog = logging.Component("manager")
+
 // TraderManager manages multiple trader instances
 type TraderManager struct {
-	traders map[string]*trader.AutoTrader // key: trader ID
-	mu      sync.RWMutex
+	traders        map[string]*trader.AutoTrader // key: trader ID
+	symbolThrottle *trader.SymbolThrottle        // Shared per-symbol entry throttle (nil = disabled)
+	ownership      *trader.OwnershipLedger       // Position ownership of traders sharing an exchange account
+	mu             sync.RWMutex
+
+	// Competition seasons (see season.go)
+	seasons        []*SeasonRecord
+	seasonDir      string
+	seasonSettings map[string]SeasonTraderConfig // key: trader ID - settings a running season freezes
+
+	// Imported benchmark trade histories shown in the competition (see benchmarks.go)
+	benchmarks map[string]*benchmark.Benchmark // key: benchmark ID
 }
 
 // NewTraderManager creates trader manager
 func NewTraderManager() *TraderManager {
 	return &TraderManager{
-		traders: make(map[string]*trader.AutoTrader),
+		traders:        make(map[string]*trader.AutoTrader),
+		ownership:      trader.NewOwnershipLedger("decision_logs/ownership.json"),
+		seasonSettings: make(map[string]SeasonTraderConfig),
+	}
+}
+
+// EnableSymbolThrottle limits combined entries per symbol across all traders (call before AddTrader)
+func (tm *TraderManager) EnableSymbolThrottle(maxEntries int, window time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.symbolThrottle = trader.NewSymbolThrottle(maxEntries, window)
+	for _, t := range tm.traders {
+		t.SetSymbolThrottle(tm.symbolThrottle)
+	}
+	managerLog.Infof("✓ Symbol entry throttle enabled: max %d entries per symbol per %v across all traders", maxEntries, window)
+}
+
+// GetSymbolThrottleState gets current per-symbol throttle state (enabled=false if throttling is disabled)
+func (tm *TraderManager) GetSymbolThrottleState() (state []decision.SymbolThrottleInfo, enabled bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tm.symbolThrottle == nil {
+		return nil, false
 	}
+	return tm.symbolThrottle.Snapshot(), true
 }
 
 // AddTrader adds a trader
@@ -46,15 +88,28 @@ func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string,
 		AsterUser:             cfg.AsterUser,
 		AsterSigner:           cfg.AsterSigner,
 		AsterPrivateKey:       cfg.AsterPrivateKey,
+		OKXAPIKey:             cfg.OKXAPIKey,
+		OKXSecretKey:          cfg.OKXSecretKey,
+		OKXPassphrase:         cfg.OKXPassphrase,
+		BybitAPIKey:           cfg.BybitAPIKey,
+		BybitSecretKey:        cfg.BybitSecretKey,
+		BybitTestnet:          cfg.BybitTestnet,
 		CoinPoolAPIURL:        coinPoolURL,
 		UseQwen:               cfg.AIModel == "qwen",
 		DeepSeekKey:           cfg.DeepSeekKey,
 		QwenKey:               cfg.QwenKey,
 		GroqKey:               cfg.GroqKey,
 		GroqModel:             cfg.GroqModel,
+		AnthropicKey:          cfg.AnthropicKey,
+		AnthropicModel:        cfg.AnthropicModel,
+		GeminiKey:             cfg.GeminiKey,
+		GeminiModel:           cfg.GeminiModel,
 		CustomAPIURL:          cfg.CustomAPIURL,
 		CustomAPIKey:          cfg.CustomAPIKey,
 		CustomModelName:       cfg.CustomModelName,
+		AIFailover:            cfg.AIFailover,
+		DisableAICache:        cfg.DisableAICache,
+		AIOutput:              cfg.AIOutput,
 		ScanInterval:          cfg.GetScanInterval(),
 		InitialBalance:        cfg.InitialBalance,
 		BTCETHLeverage:        leverage.BTCETHLeverage,  // Use configured leverage multiplier
@@ -66,25 +121,95 @@ func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string,
 		CopyFromTraderID:       cfg.CopyFromTraderID,           // Copy trading: ID of trader to copy from
 	}
 
-	// Build Supabase config if enabled
+	// Apply low-memory profile limits
+	if globalConfig != nil && globalConfig.LowMemory.Enabled {
+		lm := globalConfig.LowMemory
+		traderConfig.AI500Limit = lm.AI500Limit
+		traderConfig.MaxCandidateCoins = lm.MaxCandidateCoins
+		traderConfig.PerformanceLookback = lm.PerformanceLookback
+		traderConfig.DropRawResponse = !lm.KeepRawResponse
+	}
+
+	// Per-trader candidate pool limits (the low-memory caps stay upper bounds)
+	if cp := cfg.CandidatePool; cp != nil {
+		traderConfig.MinOIUSD = cp.MinOIUSD
+		if traderConfig.AI500Limit == 0 || cp.AI500Limit < traderConfig.AI500Limit {
+			traderConfig.AI500Limit = cp.AI500Limit
+		}
+		if cp.MaxCandidateCoins > 0 && (traderConfig.MaxCandidateCoins == 0 || cp.MaxCandidateCoins < traderConfig.MaxCandidateCoins) {
+			traderConfig.MaxCandidateCoins = cp.MaxCandidateCoins
+		}
+	}
+
+	if globalConfig != nil {
+		traderConfig.AdaptivePool = globalConfig.AdaptivePool
+		traderConfig.TradeMemory = globalConfig.TradeMemory
+		traderConfig.PaperFills = globalConfig.PaperFills
+		traderConfig.PaperShorts = globalConfig.PaperShorts
+		traderConfig.PaperCosts = globalConfig.PaperCosts
+		traderConfig.Simulation = globalConfig.Simulation
+		traderConfig.AIRequest = globalConfig.AIRequest
+		traderConfig.AutoCloseWhatIf = globalConfig.AutoCloseWhatIf
+		traderConfig.AdaptiveConfidence = globalConfig.AdaptiveConfidence
+		traderConfig.DecisionRetention = globalConfig.DecisionRetention
+	}
+	traderConfig.EndConditions = cfg.EndConditions
+	traderConfig.CycleAlignment = cfg.CycleAlignment
+	traderConfig.CycleTriggers = cfg.CycleTriggers
+	traderConfig.EquitySnapshots = cfg.EquitySnapshots
+	traderConfig.CopyTrading = cfg.CopyTrading
+	traderConfig.SignalWebhook = cfg.SignalWebhook
+	traderConfig.Strategy = cfg.Strategy
+	traderConfig.StrategyParams = cfg.StrategyParams
+	traderConfig.StopLossMode = cfg.StopLossMode
+	traderConfig.ProhibitHedging = cfg.ProhibitHedging
+	traderConfig.DryRun = cfg.DryRun
+	traderConfig.PaperShadow = cfg.PaperShadow
+	traderConfig.BackgroundTakeProfitPct = cfg.BackgroundTakeProfitPct
+	traderConfig.MonitorInterval = cfg.GetMonitorInterval()
+	traderConfig.LimitOrderTimeout = cfg.GetLimitOrderTimeout()
+	traderConfig.PromptData = cfg.PromptData
+	traderConfig.PromptTemplateDir = cfg.PromptTemplate
+	traderConfig.RiskOfficer = cfg.RiskOfficer
+	traderConfig.RiskPolicy = cfg.RiskPolicy
+	if globalConfig != nil {
+		traderConfig.PromptVersionsDir = globalConfig.PromptVersionsDir
+	}
+
+	// Build the server database config (Supabase/PostgreSQL or MySQL) if enabled
 	var supabaseConfig *trader.SupabaseConfig
-	if globalConfig != nil && globalConfig.UseSupabase && globalConfig.SupabaseDatabaseURL != "" {
+	var dbDriver, dbURL string
+	if globalConfig != nil {
+		dbDriver, dbURL = globalConfig.DecisionDatabase()
+	}
+	if dbDriver != "" {
 		supabaseConfig = &trader.SupabaseConfig{
 			UseSupabase: true,
-			DatabaseURL: globalConfig.SupabaseDatabaseURL,
+			Driver:      dbDriver,
+			DatabaseURL: dbURL,
 			Schema:      globalConfig.SupabaseSchema,
 		}
 		if supabaseConfig.Schema == "" {
 			supabaseConfig.Schema = "public"
 		}
-		log.Printf("📊 Supabase enabled for trader '%s'", cfg.Name)
+		if wq := globalConfig.SupabaseWriteQueue; wq.Enabled {
+			supabaseConfig.WriteQueue = &trader.WriteQueueOptions{
+				SyncInterval: time.Duration(wq.SyncIntervalSeconds) * time.Second,
+				BatchSize:    wq.BatchSize,
+			}
+		}
+		if dbDriver == "mysql" {
+			logging.ForTrader(cfg.ID, cfg.Name).Infof("📊 MySQL decision log enabled")
+		} else {
+			logging.ForTrader(cfg.ID, cfg.Name).Infof("📊 Supabase enabled")
+		}
 	}
 
 	// Pass multi-agent config if enabled
 	var multiAgentConfig interface{}
 	if globalConfig != nil && globalConfig.MultiAgent != nil && globalConfig.MultiAgent.Enabled {
 		multiAgentConfig = globalConfig.MultiAgent
-		log.Printf("🤖 Multi-agent enabled for trader '%s'", cfg.Name)
+		logging.ForTrader(cfg.ID, cfg.Name).Infof("🤖 Multi-agent enabled")
 	}
 
 	// Create trader instance
@@ -95,12 +220,15 @@ func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string,
 
 	// Set trader manager reference for copy trading
 	at.SetTraderManager(tm)
+	at.SetSymbolThrottle(tm.symbolThrottle)
+	at.SetOwnershipLedger(tm.ownership)
 
 	tm.traders[cfg.ID] = at
+	tm.seasonSettings[cfg.ID] = newSeasonTraderConfig(cfg, maxDailyLoss, maxDrawdown, leverage, globalConfig)
 	if cfg.CopyFromTraderID != "" {
-		log.Printf("✓ Trader '%s' (%s) added - will copy from '%s'", cfg.Name, cfg.AIModel, cfg.CopyFromTraderID)
+		logging.ForTrader(cfg.ID, cfg.Name).Infof("✓ Trader (%s) added - will copy from '%s'", cfg.AIModel, cfg.CopyFromTraderID)
 	} else {
-	log.Printf("✓ Trader '%s' (%s) added", cfg.Name, cfg.AIModel)
+	logging.ForTrader(cfg.ID, cfg.Name).Infof("✓ Trader (%s) added", cfg.AIModel)
 	}
 	return nil
 }
@@ -117,6 +245,87 @@ func (tm *TraderManager) GetTrader(id string) (*trader.AutoTrader, error) {
 	return t, nil
 }
 
+// PauseTrader pauses the scheduled decision cycles of one trader (false = it was already paused)
+func (tm *TraderManager) PauseTrader(id string) (bool, error) {
+	t, err := tm.GetTrader(id)
+	if err != nil {
+		return false, err
+	}
+	return t.Pause(), nil
+}
+
+// ResumeTrader resumes the scheduled decision cycles of one trader (false = it was not paused)
+func (tm *TraderManager) ResumeTrader(id string) (bool, error) {
+	t, err := tm.GetTrader(id)
+	if err != nil {
+		return false, err
+	}
+	return t.Resume(), nil
+}
+
+// ClearTraderRiskStop ends the daily loss / drawdown pause of one trader, rebasing its drawdown peak on the current
+// equity when resetPeak is set (false = no stop was active)
+func (tm *TraderManager) ClearTraderRiskStop(id string, resetPeak bool) (bool, error) {
+	t, err := tm.GetTrader(id)
+	if err != nil {
+		return false, err
+	}
+	return t.ClearRiskStop(resetPeak), nil
+}
+
+// SubmitSignal authenticates a signal webhook body for one trader and queues it for execution
+func (tm *TraderManager) SubmitSignal(id string, body []byte, signature string) (*trader.Signal, error) {
+	t, err := tm.GetTrader(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitSignal(body, signature)
+}
+
+// SubmitManualTrade queues an operator's decisions for one trader
+func (tm *TraderManager) SubmitManualTrade(id string, decisions []decision.Decision, comment, operator string) (*trader.Signal, error) {
+	t, err := tm.GetTrader(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitManualTrade(decisions, comment, operator)
+}
+
+// EmergencyFlatten the kill switch: pauses every trader first (no new cycles start), then flattens them all
+// concurrently (position locks keep traders on a shared account from closing the same position twice)
+func (tm *TraderManager) EmergencyFlatten(reason, operator string) []*trader.FlattenReport {
+	traders := tm.GetAllTraders()
+	for _, t := range traders {
+		t.Pause()
+	}
+
+	reports := make([]*trader.FlattenReport, 0, len(traders))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, t := range traders {
+		wg.Add(1)
+		go func(t *trader.AutoTrader) {
+			defer wg.Done()
+			report := t.EmergencyFlatten(reason, operator)
+			mu.Lock()
+			reports = append(reports, report)
+			mu.Unlock()
+		}(t)
+	}
+	wg.Wait()
+	sort.Slice(reports, func(i, j int) bool { return reports[i].TraderID < reports[j].TraderID })
+	return reports
+}
+
+// RunTraderCycle queues an immediate decision cycle for one trader
+func (tm *TraderManager) RunTraderCycle(id string) error {
+	t, err := tm.GetTrader(id)
+	if err != nil {
+		return err
+	}
+	return t.RunCycleNow()
+}
+
 // GetAllTraders gets all traders
 func (tm *TraderManager) GetAllTraders() map[string]*trader.AutoTrader {
 	tm.mu.RLock()
@@ -146,32 +355,62 @@ func (tm *TraderManager) StartAll() {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
 
-	log.Println("🚀 Starting all Traders...")
-	for id, t := range tm.traders {
-		go func(traderID string, at *trader.AutoTrader) {
-			// Add panic recovery to prevent goroutine crashes
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("🚨 PANIC in %s goroutine: %v\n%s", at.GetName(), r, getStackTrace())
-					log.Printf("🔄 Attempting to restart %s...", at.GetName())
-					// Attempt to restart the trader
-					time.Sleep(5 * time.Second)
-					go func() {
-						if err := at.Run(); err != nil {
-							log.Printf("❌ %s restart failed: %v", at.GetName(), err)
-						}
-					}()
-				}
-			}()
-			
-			log.Printf("▶️  Starting %s...", at.GetName())
-			if err := at.Run(); err != nil {
-				log.Printf("❌ %s runtime error: %v", at.GetName(), err)
-			}
-		}(id, t)
+	managerLog.Infof("🚀 Starting all Traders...")
+	for _, t := range tm.traders {
+		go runTrader(t)
+	}
+}
+
+// runTrader supervises a trader's main loop: a panic is recovered and the loop restarted with an exponential
+// backoff (5s doubling up to 5 minutes on repeated crashes), unless the trader was stopped meanwhile
+func runTrader(at *trader.AutoTrader) {
+	traderLog := logging.ForTrader(at.GetID(), at.GetName())
+	traderLog.Infof("▶️  Starting...")
+	for {
+		at.RecordStart()
+		cause, stack, panicked := runTraderOnce(at)
+		if !panicked {
+			return
+		}
+
+		delay := at.RecordCrash(cause)
+		traderLog.Errorf("🚨 PANIC in trader goroutine: %s\n%s", cause, stack)
+		alertTraderCrash(at, fmt.Sprintf("Panic: %s (restarting in %v)\n\n%s", cause, delay, stack))
+		if !waitForRestart(at, delay) {
+			at.CancelRestart()
+			traderLog.Infof("⏹ Stopped while waiting to restart after a crash")
+			return
+		}
+		traderLog.Infof("🔄 Restarting after a crash...")
 	}
 }
 
+// runTraderOnce runs the trader's loop until it returns or panics
+func runTraderOnce(at *trader.AutoTrader) (cause, stack string, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			cause, stack, panicked = fmt.Sprint(r), getStackTrace(), true
+		}
+	}()
+	if err := at.Run(); err != nil {
+		logging.ForTrader(at.GetID(), at.GetName()).Errorf("❌ Runtime error: %v", err)
+		alertTraderCrash(at, fmt.Sprintf("Runtime error: %v", err))
+	}
+	return "", "", false
+}
+
+// waitForRestart waits out a crash backoff; false if the trader was stopped meanwhile
+func waitForRestart(at *trader.AutoTrader, delay time.Duration) bool {
+	deadline := time.Now().Add(delay)
+	for time.Now().Before(deadline) {
+		if !at.IsRunning() {
+			return false
+		}
+		time.Sleep(time.Second)
+	}
+	return at.IsRunning()
+}
+
 // getStackTrace returns the current stack trace as a string
 func getStackTrace() string {
 	buf := make([]byte, 4096)
@@ -179,15 +418,42 @@ func getStackTrace() string {
 	return string(buf[:n])
 }
 
-// StopAll stops all traders
-func (tm *TraderManager) StopAll() {
+// StopTrader stops one trader and removes it from the manager
+func (tm *TraderManager) StopTrader(id string) error {
+	tm.mu.Lock()
+	t, exists := tm.traders[id]
+	if !exists {
+		tm.mu.Unlock()
+		return fmt.Errorf("trader ID '%s' does not exist", id)
+	}
+	delete(tm.traders, id)
+	delete(tm.seasonSettings, id)
+	tm.mu.Unlock()
+
+	t.Stop()
+	logging.ForTrader(t.GetID(), t.GetName()).Infof("⏹  Trader stopped and removed")
+	return nil
+}
+
+// StopAll stops all traders, waiting up to timeout for the cycles in progress to finish or abort between orders
+func (tm *TraderManager) StopAll(timeout time.Duration) {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
 
-	log.Println("⏹  Stopping all Traders...")
+	managerLog.Infof("⏹  Stopping all Traders...")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var wg sync.WaitGroup
 	for _, t := range tm.traders {
-		t.Stop()
+		wg.Add(1)
+		go func(t *trader.AutoTrader) {
+			defer wg.Done()
+			if err := t.Shutdown(ctx); err != nil {
+				managerLog.Warnf("⚠️  %v", err)
+			}
+		}(t)
 	}
+	wg.Wait()
 }
 
 // GetComparisonData gets comparison data
@@ -199,8 +465,9 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 	traders := make([]map[string]interface{}, 0, len(tm.traders))
 
 	if len(tm.traders) == 0 {
+		traders = tm.appendBenchmarks(traders)
 		comparison["traders"] = traders
-		comparison["count"] = 0
+		comparison["count"] = len(traders)
 		return comparison, nil
 	}
 
@@ -216,9 +483,9 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 		}
 		initialBalances[t.GetID()] = initialBalance
 		totalInitialBalance += initialBalance
-		log.Printf("📊 [GetComparisonData] Trader %s: initial_balance=%.2f", t.GetID(), initialBalance)
+		managerLog.Infof("📊 [GetComparisonData] Trader %s: initial_balance=%.2f", t.GetID(), initialBalance)
 	}
-	log.Printf("📊 [GetComparisonData] Total initial balance: %.2f", totalInitialBalance)
+	managerLog.Infof("📊 [GetComparisonData] Total initial balance: %.2f", totalInitialBalance)
 
 	// Get first trader's account to check if all share same balance
 	var sharedAccountEquity float64 = 0.0
@@ -253,7 +520,7 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 			if allSame {
 				hasSharedAccount = true
 				sharedAccountEquity = firstEquity
-				log.Printf("🔍 [GetComparisonData] Detected shared account: %d traders sharing %.2f USDT equity", len(tm.traders), sharedAccountEquity)
+				managerLog.Infof("🔍 [GetComparisonData] Detected shared account: %d traders sharing %.2f USDT equity", len(tm.traders), sharedAccountEquity)
 			}
 		}
 	}
@@ -266,7 +533,7 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 
 		// If account info fails (e.g., invalid API keys), use demo data
 		if err != nil {
-			log.Printf("⚠️  [%s] Failed to get account info, using demo data: %v", t.GetName(), err)
+			logging.ForTrader(t.GetID(), t.GetName()).Warnf("⚠️  Failed to get account info, using demo data: %v", err)
 			
 			traders = append(traders, map[string]interface{}{
 				"trader_id":       t.GetID(),
@@ -279,6 +546,8 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 				"margin_used_pct": 0.0,
 				"call_count":      status["call_count"],
 				"is_running":      status["is_running"],
+				"completed":       status["completed"],
+				"completion":      t.GetCompletion(),
 			})
 			continue
 		}
@@ -296,7 +565,7 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 			if initialBalance > 0 {
 				pnlPct = (pnl / initialBalance) * 100
 			}
-			log.Printf("📊 [GetComparisonData] %s: initial=%.2f, proportion=%.4f, equity=%.2f, pnl=%.2f, pnlPct=%.2f%%",
+			managerLog.Infof("📊 [GetComparisonData] %s: initial=%.2f, proportion=%.4f, equity=%.2f, pnl=%.2f, pnlPct=%.2f%%",
 				t.GetID(), initialBalance, proportion, equity, pnl, pnlPct)
 			
 			// For positions, we can't split them, so show all positions for each trader
@@ -340,9 +609,12 @@ func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 			"margin_used_pct": marginUsedPct,
 			"call_count":      status["call_count"],
 			"is_running":      status["is_running"],
+			"completed":       status["completed"],
+			"completion":      t.GetCompletion(),
 		})
 	}
 
+	traders = tm.appendBenchmarks(traders)
 	comparison["traders"] = traders
 	comparison["count"] = len(traders)
 
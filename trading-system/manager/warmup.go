@@ -0,0 +1,112 @@
+package manager
+
+import (
+	"lia/config"
+	"lia/logging"
+	"lia/market"
+	"lia/pool"
+	"lia/trader"
+	"sync"
+	"time"
+)
+
+// warmupAnchorSymbols always prefetched (used by every trader for market context)
+var warmupAnchorSymbols = []string{"BTCUSDT", "ETHUSDT"}
+
+// WarmUp loads exchange metadata, the coin pool and market data for likely candidates before StartAll,
+// so the traders' first cycles are served from cache instead of all hitting the APIs at once.
+// Returns when warmup completes or the timeout expires; failures are logged and never block startup.
+func (tm *TraderManager) WarmUp(cfg config.WarmupConfig) {
+	// The shared market data cache (market_data), when enabled, keeps its own TTL
+	if market.CacheTTL() <= 0 {
+		market.SetCacheTTL(time.Duration(cfg.CacheTTLSeconds) * time.Second)
+	}
+	pool.SetLiveCacheTTL(time.Duration(cfg.CacheTTLSeconds) * time.Second)
+
+	traders := tm.GetAllTraders()
+	managerLog.Infof("🔥 Warming caches for %d traders (timeout %ds)...", len(traders), cfg.TimeoutSeconds)
+	start := time.Now()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tm.warmUp(traders, cfg)
+	}()
+
+	select {
+	case <-done:
+		managerLog.Infof("✓ Cache warmup finished in %s", time.Since(start).Round(time.Millisecond))
+	case <-time.After(time.Duration(cfg.TimeoutSeconds) * time.Second):
+		managerLog.Warnf("⚠️  Cache warmup timed out after %ds, starting traders anyway (warmup continues in background)", cfg.TimeoutSeconds)
+	}
+}
+
+// warmUp runs the warmup phases: exchange caches per trader, then the coin pool, then market data
+func (tm *TraderManager) warmUp(traders map[string]*trader.AutoTrader, cfg config.WarmupConfig) {
+	// 1. Exchange metadata and account caches (one goroutine per trader)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	positionSymbols := make(map[string]bool)
+	ai500Limit := 0
+	failed := 0
+	for id, at := range traders {
+		wg.Add(1)
+		go func(traderID string, at *trader.AutoTrader) {
+			defer wg.Done()
+			symbols, limit, err := at.WarmUp()
+			mu.Lock()
+			defer mu.Unlock()
+			if limit > ai500Limit {
+				ai500Limit = limit
+			}
+			if err != nil {
+				failed++
+				managerLog.With(logging.FieldTraderID, traderID).Warnf("⚠️  Warmup failed for trader '%s': %v", traderID, err)
+				return
+			}
+			for _, symbol := range symbols {
+				positionSymbols[symbol] = true
+			}
+		}(id, at)
+	}
+	wg.Wait()
+	if ai500Limit == 0 {
+		ai500Limit = 20
+	}
+
+	// 2. Coin pool (AI500 + OI Top), stored in the live cache for the first cycles
+	var candidates []string
+	mergedPool, err := pool.GetMergedCoinPool(ai500Limit)
+	if err != nil {
+		managerLog.Warnf("⚠️  Warmup: failed to get coin pool: %v", err)
+	} else {
+		candidates = mergedPool.AllSymbols
+	}
+
+	// 3. Market data: open positions first, then anchors, then candidates in pool order
+	symbols := make([]string, 0, cfg.MaxSymbols)
+	seen := make(map[string]bool)
+	add := func(symbol string) {
+		if len(symbols) >= cfg.MaxSymbols || seen[symbol] {
+			return
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	for symbol := range positionSymbols {
+		add(symbol)
+	}
+	for _, symbol := range warmupAnchorSymbols {
+		add(symbol)
+	}
+	for _, symbol := range candidates {
+		add(symbol)
+	}
+
+	failedSymbols := market.Prefetch(symbols, cfg.Concurrency)
+	managerLog.Infof("  ✓ Warmup: %d/%d traders' exchange caches, %d candidates in pool, market data for %d/%d symbols",
+		len(traders)-failed, len(traders), len(candidates), len(symbols)-len(failedSymbols), len(symbols))
+	if len(failedSymbols) > 0 {
+		managerLog.Warnf("  ⚠️  Warmup: market data unavailable for %v", failedSymbols)
+	}
+}
@@ -0,0 +1,211 @@
+package market
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Short-lived market data cache shared by every trader of the process. Disabled by default (every Get hits
+// Binance); the market_data config or the startup warmup enable it, so traders evaluating the same symbols
+// within the TTL share one fetch.
+var dataCache = struct {
+	entries   map[string]cachedData
+	inflight  map[string]*dataFetch // Fetches in progress: concurrent Gets of a symbol wait for the same one
+	requested map[string]time.Time  // Last Get per symbol (the symbols the background refresh keeps warm)
+	ttl       time.Duration
+	hits      int64
+	misses    int64
+	mu        sync.RWMutex
+}{
+	entries:   make(map[string]cachedData),
+	inflight:  make(map[string]*dataFetch),
+	requested: make(map[string]time.Time),
+}
+
+// cachedData market data and when it was fetched
+type cachedData struct {
+	data      *Data
+	fetchedAt time.Time
+}
+
+// dataFetch a fetch in progress
+type dataFetch struct {
+	done chan struct{}
+	data *Data
+	err  error
+}
+
+// refreshActiveWindow symbols requested within this window are kept warm by the background refresh
+const refreshActiveWindow = 10 * time.Minute
+
+// SetCacheTTL sets how long fetched market data is reused (<= 0 disables the cache)
+func SetCacheTTL(ttl time.Duration) {
+	dataCache.mu.Lock()
+	defer dataCache.mu.Unlock()
+	dataCache.ttl = ttl
+	if ttl <= 0 {
+		dataCache.entries = make(map[string]cachedData)
+	}
+}
+
+// CacheTTL how long fetched market data is reused (0 = cache disabled)
+func CacheTTL() time.Duration {
+	dataCache.mu.RLock()
+	defer dataCache.mu.RUnlock()
+	return dataCache.ttl
+}
+
+// CacheStats cache hits and misses (fetches) of Get since startup
+func CacheStats() (hits, misses int64) {
+	dataCache.mu.RLock()
+	defer dataCache.mu.RUnlock()
+	return dataCache.hits, dataCache.misses
+}
+
+// Get gets market data for specified token
+func Get(symbol string) (*Data, error) {
+	// Normalize symbol
+	symbol = Normalize(symbol)
+	if source := currentSource(); source != nil {
+		return source.Data(symbol)
+	}
+
+	dataCache.mu.Lock()
+	ttl := dataCache.ttl
+	if ttl > 0 {
+		dataCache.requested[symbol] = time.Now()
+		if entry, ok := dataCache.entries[symbol]; ok && time.Since(entry.fetchedAt) < ttl {
+			dataCache.hits++
+			dataCache.mu.Unlock()
+			return entry.data, nil
+		}
+		dataCache.misses++
+	}
+	dataCache.mu.Unlock()
+
+	return fetchShared(symbol)
+}
+
+// fetchShared fetches symbol once for every caller asking at the same time and stores the result when the
+// cache is enabled
+func fetchShared(symbol string) (*Data, error) {
+	dataCache.mu.Lock()
+	if fetch, ok := dataCache.inflight[symbol]; ok {
+		dataCache.mu.Unlock()
+		<-fetch.done
+		return fetch.data, fetch.err
+	}
+	fetch := &dataFetch{done: make(chan struct{})}
+	dataCache.inflight[symbol] = fetch
+	dataCache.mu.Unlock()
+
+	fetch.data, fetch.err = fetchData(symbol)
+
+	dataCache.mu.Lock()
+	delete(dataCache.inflight, symbol)
+	if fetch.err == nil && dataCache.ttl > 0 {
+		dataCache.entries[symbol] = cachedData{data: fetch.data, fetchedAt: time.Now()}
+		// Drop expired entries so rotating candidate pools don't grow the cache
+		for key, e := range dataCache.entries {
+			if time.Since(e.fetchedAt) >= dataCache.ttl {
+				delete(dataCache.entries, key)
+			}
+		}
+	}
+	dataCache.mu.Unlock()
+	close(fetch.done)
+
+	if fetch.err != nil {
+		return nil, fetch.err
+	}
+	return fetch.data, nil
+}
+
+// StartRefresh re-fetches the market data of the symbols traders requested recently (their candidate pools and
+// positions) every interval, up to maxSymbols of the most recently requested, so cycles read refreshed data
+// from the cache instead of fetching it themselves. Returns a function stopping the refresh
+func StartRefresh(interval time.Duration, maxSymbols, concurrency int) func() {
+	stop := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				symbols := activeSymbols(maxSymbols)
+				if failed := forEachSymbol(symbols, concurrency, fetchShared); len(failed) > 0 {
+					log.Printf("⚠️  Market data refresh: %d/%d symbols failed", len(failed), len(symbols))
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+// activeSymbols symbols requested within refreshActiveWindow, most recent first, at most maxSymbols (older
+// requests are forgotten)
+func activeSymbols(maxSymbols int) []string {
+	dataCache.mu.Lock()
+	defer dataCache.mu.Unlock()
+	symbols := make([]string, 0, len(dataCache.requested))
+	for symbol, at := range dataCache.requested {
+		if time.Since(at) >= refreshActiveWindow {
+			delete(dataCache.requested, symbol)
+			continue
+		}
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		return dataCache.requested[symbols[i]].After(dataCache.requested[symbols[j]])
+	})
+	if maxSymbols > 0 && len(symbols) > maxSymbols {
+		symbols = symbols[:maxSymbols]
+	}
+	return symbols
+}
+
+// Prefetch loads market data for symbols with bounded concurrency (fills the cache when enabled)
+// Returns the symbols that failed to load
+func Prefetch(symbols []string, concurrency int) []string {
+	return forEachSymbol(symbols, concurrency, func(symbol string) (*Data, error) {
+		data, err := Get(symbol)
+		if err != nil {
+			log.Printf("⚠️  Prefetch %s market data failed: %v", symbol, err)
+		}
+		return data, err
+	})
+}
+
+// forEachSymbol runs fetch for symbols with bounded concurrency and returns the symbols that failed
+func forEachSymbol(symbols []string, concurrency int, fetch func(string) (*Data, error)) []string {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		failed []string
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(symbol string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := fetch(symbol); err != nil {
+				mu.Lock()
+				failed = append(failed, symbol)
+				mu.Unlock()
+			}
+		}(symbol)
+	}
+	wg.Wait()
+	return failed
+}
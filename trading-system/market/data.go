@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"lia/ratelimit"
 	"math"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
+// binanceClient HTTP client for Binance market data, charged to the process-wide Binance request budget
+var binanceClient = &http.Client{Transport: ratelimit.NewBinanceTransport(nil, "")}
+
 // Data market data structure
 type Data struct {
 	Symbol            string
@@ -63,11 +67,8 @@ type Kline struct {
 	CloseTime int64
 }
 
-// Get gets market data for specified token
-func Get(symbol string) (*Data, error) {
-	// Normalize symbol
-	symbol = Normalize(symbol)
-
+// fetchData fetches market data for specified token from Binance (symbol already normalized)
+func fetchData(symbol string) (*Data, error) {
 	// Get 3-minute candlestick data (recent 10)
 	klines3m, err := getKlines(symbol, "3m", 40) // Get more for calculation
 	if err != nil {
@@ -80,6 +81,25 @@ func Get(symbol string) (*Data, error) {
 		return nil, fmt.Errorf("failed to get 4-hour candlesticks: %v", err)
 	}
 
+	data := BuildData(symbol, klines3m, klines4h)
+
+	// Get OI data
+	oiData, err := getOpenInterestData(symbol)
+	if err != nil {
+		// OI failure doesn't affect overall, use default values
+		oiData = &OIData{Latest: 0, Average: 0}
+	}
+	data.OpenInterest = oiData
+
+	// Get Funding Rate
+	data.FundingRate, _ = getFundingRate(symbol)
+
+	return data, nil
+}
+
+// BuildData computes market data from 3-minute and 4-hour candlesticks (oldest first, the last one is the
+// current candle). Open interest and funding rate are left unset (nil OpenInterest skips the liquidity filter)
+func BuildData(symbol string, klines3m, klines4h []Kline) *Data {
 	// Calculate current indicators (based on latest 3-minute data)
 	currentPrice := klines3m[len(klines3m)-1].Close
 	currentEMA20 := calculateEMA(klines3m, 20)
@@ -105,16 +125,6 @@ func Get(symbol string) (*Data, error) {
 		}
 	}
 
-	// Get OI data
-	oiData, err := getOpenInterestData(symbol)
-	if err != nil {
-		// OI failure doesn't affect overall, use default values
-		oiData = &OIData{Latest: 0, Average: 0}
-	}
-
-	// Get Funding Rate
-	fundingRate, _ := getFundingRate(symbol)
-
 	// Calculate intraday series data
 	intradayData := calculateIntradaySeries(klines3m)
 
@@ -129,19 +139,25 @@ func Get(symbol string) (*Data, error) {
 		CurrentEMA20:      currentEMA20,
 		CurrentMACD:       currentMACD,
 		CurrentRSI7:       currentRSI7,
-		OpenInterest:      oiData,
-		FundingRate:       fundingRate,
 		IntradaySeries:    intradayData,
 		LongerTermContext: longerTermData,
-	}, nil
+	}
+}
+
+// GetKlines gets candlestick data for specified token (oldest first)
+func GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	return getKlines(Normalize(symbol), interval, limit)
 }
 
-// getKlines gets candlestick data from Binance
+// getKlines gets candlestick data from Binance (or the installed Source)
 func getKlines(symbol, interval string, limit int) ([]Kline, error) {
+	if source := currentSource(); source != nil {
+		return source.Klines(symbol, interval, limit)
+	}
 	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?symbol=%s&interval=%s&limit=%d",
 		symbol, interval, limit)
 
-	resp, err := http.Get(url)
+	resp, err := binanceClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -152,6 +168,11 @@ func getKlines(symbol, interval string, limit int) ([]Kline, error) {
 		return nil, err
 	}
 
+	return parseKlines(body)
+}
+
+// parseKlines parses a Binance klines response
+func parseKlines(body []byte) ([]Kline, error) {
 	var rawData [][]interface{}
 	if err := json.Unmarshal(body, &rawData); err != nil {
 		return nil, err
@@ -390,7 +411,7 @@ func calculateLongerTermData(klines []Kline) *LongerTermData {
 func getOpenInterestData(symbol string) (*OIData, error) {
 	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/openInterest?symbol=%s", symbol)
 
-	resp, err := http.Get(url)
+	resp, err := binanceClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +444,7 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 func getFundingRate(symbol string) (float64, error) {
 	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/premiumIndex?symbol=%s", symbol)
 
-	resp, err := http.Get(url)
+	resp, err := binanceClient.Get(url)
 	if err != nil {
 		return 0, err
 	}
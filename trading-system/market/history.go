@@ -0,0 +1,222 @@
+package market
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const klinesPageLimit = 1500 // Binance futures klines maximum per request
+
+// klineIntervals Binance kline intervals usable for historical data
+var klineIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// IntervalDuration length of a kline interval ("3m", "4h", ...)
+func IntervalDuration(interval string) (time.Duration, error) {
+	d, ok := klineIntervals[interval]
+	if !ok {
+		return 0, fmt.Errorf("unsupported kline interval '%s'", interval)
+	}
+	return d, nil
+}
+
+// GetKlinesRange gets every candlestick opened in [start, end) from Binance, paging through the history
+// (oldest first)
+func GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	step, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	symbol = Normalize(symbol)
+
+	var klines []Kline
+	from := start.UnixMilli()
+	to := end.UnixMilli() - 1
+	for from <= to {
+		url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?symbol=%s&interval=%s&startTime=%d&endTime=%d&limit=%d",
+			symbol, interval, from, to, klinesPageLimit)
+		page, err := getKlinesPage(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %s candlesticks from %s: %w",
+				symbol, interval, time.UnixMilli(from).UTC().Format(time.RFC3339), err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		klines = append(klines, page...)
+		from = page[len(page)-1].OpenTime + step.Milliseconds()
+	}
+	return klines, nil
+}
+
+// PriceAt the close of the 1m candlestick containing t (the latest price for a t in the current minute)
+func PriceAt(symbol string, t time.Time) (float64, error) {
+	start := t.Truncate(time.Minute)
+	klines, err := GetKlinesRange(symbol, "1m", start, start.Add(time.Minute))
+	if err != nil {
+		return 0, err
+	}
+	if len(klines) == 0 {
+		return 0, fmt.Errorf("no %s price at %s", Normalize(symbol), t.UTC().Format(time.RFC3339))
+	}
+	return klines[0].Close, nil
+}
+
+// getKlinesPage fetches one page of klines (non-200 responses are errors)
+func getKlinesPage(url string) ([]Kline, error) {
+	resp, err := binanceClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return parseKlines(body)
+}
+
+// LoadKlinesCSV loads candlesticks from a CSV file in the Binance data export layout:
+// open_time,open,high,low,close,volume[,close_time,...] with an optional header row. Times are Unix
+// milliseconds or RFC3339; a missing close_time is derived from the interval (oldest first)
+func LoadKlinesCSV(path, interval string) ([]Kline, error) {
+	step, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var klines []Kline
+	for line := 1; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(row) < 6 {
+			return nil, fmt.Errorf("%s line %d: expected at least 6 columns (open_time,open,high,low,close,volume), got %d",
+				path, line, len(row))
+		}
+
+		openTime, err := parseCSVTime(row[0])
+		if err != nil {
+			if line == 1 {
+				continue // Header row
+			}
+			return nil, fmt.Errorf("%s line %d: invalid open_time '%s'", path, line, row[0])
+		}
+
+		var values [5]float64
+		for i := range values {
+			values[i], err = strconv.ParseFloat(strings.TrimSpace(row[i+1]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid number '%s'", path, line, row[i+1])
+			}
+		}
+
+		closeTime := openTime + step.Milliseconds() - 1
+		if len(row) > 6 {
+			if t, err := parseCSVTime(row[6]); err == nil {
+				closeTime = t
+			}
+		}
+
+		klines = append(klines, Kline{
+			OpenTime:  openTime,
+			Open:      values[0],
+			High:      values[1],
+			Low:       values[2],
+			Close:     values[3],
+			Volume:    values[4],
+			CloseTime: closeTime,
+		})
+	}
+
+	sort.Slice(klines, func(i, j int) bool { return klines[i].OpenTime < klines[j].OpenTime })
+	return klines, nil
+}
+
+// parseCSVTime parses Unix milliseconds (seconds are converted) or an RFC3339 timestamp
+func parseCSVTime(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if ms < 1e11 { // Unix seconds
+			ms *= 1000
+		}
+		return ms, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixMilli(), nil
+}
+
+// AggregateKlines merges candlesticks into a longer interval aligned to UTC (e.g. 3m → 4h). The last candle
+// is partial when the input ends mid-interval
+func AggregateKlines(klines []Kline, interval string) ([]Kline, error) {
+	step, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	stepMs := step.Milliseconds()
+
+	var out []Kline
+	for _, k := range klines {
+		bucket := k.OpenTime - k.OpenTime%stepMs
+		if n := len(out); n > 0 && out[n-1].OpenTime == bucket {
+			last := &out[n-1]
+			if k.High > last.High {
+				last.High = k.High
+			}
+			if k.Low < last.Low {
+				last.Low = k.Low
+			}
+			last.Close = k.Close
+			last.Volume += k.Volume
+			continue
+		}
+		out = append(out, Kline{
+			OpenTime:  bucket,
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			CloseTime: bucket + stepMs - 1,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,336 @@
+package market
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// RecentLiquidationWindow the "last minutes" span of a liquidation summary, compared with the whole window to
+// spot cascades forming
+const RecentLiquidationWindow = 5 * time.Minute
+
+// liquidationClusterBandPct width of a price band of the liquidation clusters, in percent of the latest price
+const liquidationClusterBandPct = 0.5
+
+// maxLiquidationsPerSymbol events kept per symbol (the oldest are dropped first)
+const maxLiquidationsPerSymbol = 2000
+
+// cascadeRateFactor how much faster than the window average a side must be liquidated in the recent window to
+// count as a cascade
+const cascadeRateFactor = 3.0
+
+// LiquidationEvent a forced liquidation order of the exchange
+type LiquidationEvent struct {
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"` // Side of the liquidated position: "long" (sold) or "short" (bought back)
+	Price    float64   `json:"price"`
+	Quantity float64   `json:"quantity"`
+	USD      float64   `json:"usd"`
+	Time     time.Time `json:"time"`
+}
+
+// LiquidationCluster liquidations within one price band (the observed liquidation heatmap)
+type LiquidationCluster struct {
+	PriceLow  float64 `json:"price_low"`
+	PriceHigh float64 `json:"price_high"`
+	LongUSD   float64 `json:"long_usd"`
+	ShortUSD  float64 `json:"short_usd"`
+	Count     int     `json:"count"`
+}
+
+// LiquidationSummary liquidations of a symbol (or the whole market) over the window
+type LiquidationSummary struct {
+	WindowMinutes  int                  `json:"window_minutes"`
+	LongUSD        float64              `json:"long_usd"`  // Longs liquidated over the window
+	ShortUSD       float64              `json:"short_usd"` // Shorts liquidated over the window
+	Count          int                  `json:"count"`
+	RecentLongUSD  float64              `json:"recent_long_usd"` // Within RecentLiquidationWindow
+	RecentShortUSD float64              `json:"recent_short_usd"`
+	Largest        *LiquidationEvent    `json:"largest,omitempty"`
+	Clusters       []LiquidationCluster `json:"clusters,omitempty"` // Largest price bands first (per symbol only)
+	Cascade        string               `json:"cascade,omitempty"`  // "long" / "short" when that side is being liquidated well above its window rate
+}
+
+// LiquidationOptions how long liquidations are kept and what counts as large
+type LiquidationOptions struct {
+	Window   time.Duration // Span of the summaries; older events are dropped
+	LargeUSD float64       // Size of a large liquidation (feed and cascade threshold)
+	MaxLarge int           // Large liquidations kept for the feed
+	Clusters int           // Price bands shown per symbol
+}
+
+// Process-wide liquidation store fed by the Binance forceOrder stream. Disabled until ConfigureLiquidations is
+// called: every read is empty, so traders without it build the same prompts as before
+var liquidations = struct {
+	mu      sync.RWMutex
+	enabled bool
+	options LiquidationOptions
+	events  map[string][]LiquidationEvent // Per symbol, oldest first
+	large   []LiquidationEvent            // Market-wide large liquidations, oldest first
+}{}
+
+// ConfigureLiquidations enables the liquidation store with options (events arrive through
+// StartLiquidationStream or RecordLiquidation)
+func ConfigureLiquidations(options LiquidationOptions) {
+	liquidations.mu.Lock()
+	defer liquidations.mu.Unlock()
+	liquidations.enabled = true
+	liquidations.options = options
+	if liquidations.events == nil {
+		liquidations.events = make(map[string][]LiquidationEvent)
+	}
+}
+
+// LiquidationsEnabled whether the liquidation store is configured
+func LiquidationsEnabled() bool {
+	liquidations.mu.RLock()
+	defer liquidations.mu.RUnlock()
+	return liquidations.enabled
+}
+
+// StartLiquidationStream subscribes to Binance's all-market liquidation stream and records every event,
+// reconnecting with a backoff when the connection drops. Returns a function stopping the stream
+func StartLiquidationStream() func() {
+	stop := make(chan struct{})
+	var once sync.Once
+	go func() {
+		backoff := 5 * time.Second
+		for {
+			doneC, stopC, err := futures.WsAllLiquidationOrderServe(handleLiquidationEvent, func(err error) {
+				log.Printf("⚠️  Liquidation stream: %v", err)
+			})
+			if err != nil {
+				log.Printf("⚠️  Liquidation stream connection failed (retry in %v): %v", backoff, err)
+				select {
+				case <-time.After(backoff):
+					backoff = min(backoff*2, time.Minute)
+					continue
+				case <-stop:
+					return
+				}
+			}
+			backoff = 5 * time.Second
+			select {
+			case <-doneC:
+				log.Printf("⚠️  Liquidation stream disconnected, reconnecting")
+			case <-stop:
+				close(stopC)
+				<-doneC
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+// handleLiquidationEvent records a forceOrder stream event
+func handleLiquidationEvent(event *futures.WsLiquidationOrderEvent) {
+	order := event.LiquidationOrder
+	price, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	if price <= 0 {
+		price, _ = strconv.ParseFloat(order.Price, 64)
+	}
+	quantity, _ := strconv.ParseFloat(order.AccumulatedFilledQty, 64)
+	if quantity <= 0 {
+		quantity, _ = strconv.ParseFloat(order.OrigQuantity, 64)
+	}
+	side := "long" // A liquidated long is sold
+	if order.Side == futures.SideTypeBuy {
+		side = "short"
+	}
+	RecordLiquidation(LiquidationEvent{
+		Symbol:   order.Symbol,
+		Side:     side,
+		Price:    price,
+		Quantity: quantity,
+		Time:     time.UnixMilli(order.TradeTime),
+	})
+}
+
+// RecordLiquidation stores a liquidation (USD defaults to price × quantity, a zero time to now)
+func RecordLiquidation(event LiquidationEvent) {
+	if event.USD == 0 {
+		event.USD = event.Price * event.Quantity
+	}
+	if event.Time.IsZero() || event.Time.After(time.Now()) {
+		event.Time = time.Now()
+	}
+	if event.Symbol == "" || event.USD <= 0 {
+		return
+	}
+
+	liquidations.mu.Lock()
+	defer liquidations.mu.Unlock()
+	if !liquidations.enabled {
+		return
+	}
+	cutoff := time.Now().Add(-liquidations.options.Window)
+	events := append(pruneLiquidations(liquidations.events[event.Symbol], cutoff), event)
+	if len(events) > maxLiquidationsPerSymbol {
+		events = events[len(events)-maxLiquidationsPerSymbol:]
+	}
+	liquidations.events[event.Symbol] = events
+
+	if event.USD >= liquidations.options.LargeUSD {
+		liquidations.large = append(liquidations.large, event)
+		if len(liquidations.large) > liquidations.options.MaxLarge {
+			liquidations.large = liquidations.large[len(liquidations.large)-liquidations.options.MaxLarge:]
+		}
+	}
+}
+
+// pruneLiquidations drops the events (oldest first) before cutoff
+func pruneLiquidations(events []LiquidationEvent, cutoff time.Time) []LiquidationEvent {
+	i := sort.Search(len(events), func(i int) bool { return !events[i].Time.Before(cutoff) })
+	return events[i:]
+}
+
+// SymbolLiquidations the liquidation summary of each symbol with liquidations within the window (symbols
+// without any are left out)
+func SymbolLiquidations(symbols []string) map[string]*LiquidationSummary {
+	liquidations.mu.RLock()
+	defer liquidations.mu.RUnlock()
+	if !liquidations.enabled {
+		return nil
+	}
+	now := time.Now()
+	result := make(map[string]*LiquidationSummary)
+	for _, symbol := range symbols {
+		if _, done := result[symbol]; done {
+			continue
+		}
+		events := pruneLiquidations(liquidations.events[symbol], now.Add(-liquidations.options.Window))
+		if len(events) == 0 {
+			continue
+		}
+		summary := summarizeLiquidations(events, liquidations.options, now)
+		summary.Clusters = liquidationClusters(events, liquidations.options.Clusters)
+		result[symbol] = summary
+	}
+	return result
+}
+
+// MarketLiquidations the liquidation summary of every symbol together (nil = disabled or none within the window)
+func MarketLiquidations() *LiquidationSummary {
+	liquidations.mu.RLock()
+	defer liquidations.mu.RUnlock()
+	if !liquidations.enabled {
+		return nil
+	}
+	now := time.Now()
+	var all []LiquidationEvent
+	for _, events := range liquidations.events {
+		all = append(all, pruneLiquidations(events, now.Add(-liquidations.options.Window))...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	return summarizeLiquidations(all, liquidations.options, now)
+}
+
+// LargeLiquidations the large liquidations within the window, newest first, at most limit (<= 0 = all kept)
+func LargeLiquidations(limit int) []LiquidationEvent {
+	liquidations.mu.RLock()
+	defer liquidations.mu.RUnlock()
+	if !liquidations.enabled {
+		return nil
+	}
+	cutoff := time.Now().Add(-liquidations.options.Window)
+	var result []LiquidationEvent
+	for i := len(liquidations.large) - 1; i >= 0; i-- {
+		if liquidations.large[i].Time.Before(cutoff) || (limit > 0 && len(result) >= limit) {
+			break
+		}
+		result = append(result, liquidations.large[i])
+	}
+	return result
+}
+
+// summarizeLiquidations totals of events within the window. A side is cascading when its recent rate is
+// cascadeRateFactor times its window rate and at least one large liquidation's worth
+func summarizeLiquidations(events []LiquidationEvent, options LiquidationOptions, now time.Time) *LiquidationSummary {
+	summary := &LiquidationSummary{WindowMinutes: int(options.Window.Minutes()), Count: len(events)}
+	recentCutoff := now.Add(-RecentLiquidationWindow)
+	for i, e := range events {
+		recent := !e.Time.Before(recentCutoff)
+		if e.Side == "long" {
+			summary.LongUSD += e.USD
+			if recent {
+				summary.RecentLongUSD += e.USD
+			}
+		} else {
+			summary.ShortUSD += e.USD
+			if recent {
+				summary.RecentShortUSD += e.USD
+			}
+		}
+		if summary.Largest == nil || e.USD > summary.Largest.USD {
+			summary.Largest = &events[i]
+		}
+	}
+	if summary.Largest != nil {
+		largest := *summary.Largest
+		summary.Largest = &largest
+	}
+
+	// Compare the recent rate with the window average (excluding the recent part, so a burst does not raise it)
+	cascading := func(recentUSD, totalUSD float64) bool {
+		if recentUSD < options.LargeUSD || options.Window <= RecentLiquidationWindow {
+			return false
+		}
+		earlierRate := (totalUSD - recentUSD) / float64(options.Window-RecentLiquidationWindow)
+		return recentUSD/float64(RecentLiquidationWindow) >= cascadeRateFactor*earlierRate
+	}
+	switch {
+	case cascading(summary.RecentLongUSD, summary.LongUSD) && summary.RecentLongUSD >= summary.RecentShortUSD:
+		summary.Cascade = "long"
+	case cascading(summary.RecentShortUSD, summary.ShortUSD):
+		summary.Cascade = "short"
+	}
+	return summary
+}
+
+// liquidationClusters the events grouped into price bands of liquidationClusterBandPct of the latest price,
+// the largest limit bands first
+func liquidationClusters(events []LiquidationEvent, limit int) []LiquidationCluster {
+	if limit <= 0 || len(events) == 0 {
+		return nil
+	}
+	band := events[len(events)-1].Price * liquidationClusterBandPct / 100
+	if band <= 0 {
+		return nil
+	}
+	clusters := make(map[int64]*LiquidationCluster)
+	for _, e := range events {
+		index := int64(math.Floor(e.Price / band))
+		c := clusters[index]
+		if c == nil {
+			c = &LiquidationCluster{PriceLow: float64(index) * band, PriceHigh: float64(index+1) * band}
+			clusters[index] = c
+		}
+		if e.Side == "long" {
+			c.LongUSD += e.USD
+		} else {
+			c.ShortUSD += e.USD
+		}
+		c.Count++
+	}
+
+	result := make([]LiquidationCluster, 0, len(clusters))
+	for _, c := range clusters {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LongUSD+result[i].ShortUSD > result[j].LongUSD+result[j].ShortUSD
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"lia/ratelimit"
+	"net/http"
+	"time"
+)
+
+// orderBookClient HTTP client for depth snapshots (fills must not hang on a slow exchange)
+var orderBookClient = &http.Client{Timeout: 5 * time.Second, Transport: ratelimit.NewBinanceTransport(nil, "")}
+
+// BookLevel one price level of the order book
+type BookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBook depth snapshot (bids best-first descending, asks best-first ascending)
+type OrderBook struct {
+	Symbol string
+	Bids   []BookLevel
+	Asks   []BookLevel
+}
+
+// Fill result of walking the order book with a market order
+type Fill struct {
+	AvgPrice   float64 // Volume-weighted average fill price
+	BestPrice  float64 // Top of book on the taken side
+	WorstPrice float64 // Deepest level consumed
+	Levels     int     // Price levels consumed
+	Exhausted  bool    // Snapshot depth ran out; the remainder was filled at WorstPrice
+}
+
+// GetOrderBook gets the Binance futures order book (limit: 5, 10, 20, 50, 100, 500 or 1000 levels per side)
+func GetOrderBook(symbol string, limit int) (*OrderBook, error) {
+	symbol = Normalize(symbol)
+	if currentSource() != nil {
+		return nil, errNoOrderBook
+	}
+	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/depth?symbol=%s&limit=%d", symbol, limit)
+
+	resp, err := orderBookClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("depth request failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var raw struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	book := &OrderBook{Symbol: symbol}
+	if book.Bids, err = parseBookLevels(raw.Bids); err != nil {
+		return nil, err
+	}
+	if book.Asks, err = parseBookLevels(raw.Asks); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// parseBookLevels converts [price, quantity] string pairs
+func parseBookLevels(raw [][]string) ([]BookLevel, error) {
+	levels := make([]BookLevel, 0, len(raw))
+	for _, level := range raw {
+		if len(level) < 2 {
+			continue
+		}
+		price, err := parseFloat(level[0])
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := parseFloat(level[1])
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, BookLevel{Price: price, Quantity: quantity})
+	}
+	return levels, nil
+}
+
+// WalkFill simulates a market order of quantity against the book: "BUY" takes asks, "SELL" takes bids
+func (ob *OrderBook) WalkFill(side string, quantity float64) (*Fill, error) {
+	levels := ob.Asks
+	if side == "SELL" {
+		levels = ob.Bids
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("%s order book has no %s liquidity", ob.Symbol, side)
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("invalid fill quantity: %f", quantity)
+	}
+
+	fill := &Fill{BestPrice: levels[0].Price}
+	remaining := quantity
+	var cost float64
+	for _, level := range levels {
+		take := level.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		cost += take * level.Price
+		remaining -= take
+		fill.WorstPrice = level.Price
+		fill.Levels++
+		if remaining <= 0 {
+			break
+		}
+	}
+	if remaining > 0 {
+		// Deeper than the snapshot: assume the rest fills at the last visible level
+		fill.Exhausted = true
+		cost += remaining * fill.WorstPrice
+	}
+	fill.AvgPrice = cost / quantity
+	return fill, nil
+}
@@ -0,0 +1,38 @@
+package market
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Source replaces Binance as the origin of market data and candlesticks (the simulate exchange mode feeds
+// recorded or synthetic price paths through it, so no network calls are made)
+type Source interface {
+	// Data market data of symbol at the source's current time
+	Data(symbol string) (*Data, error)
+	// Klines the last limit candlesticks of interval (oldest first)
+	Klines(symbol, interval string, limit int) ([]Kline, error)
+}
+
+var dataSource = struct {
+	source Source
+	mu     sync.RWMutex
+}{}
+
+// SetSource routes Get, GetKlines and GetTimeframe through source (nil restores Binance). Order books are not
+// available while a source is set. Process-wide: affects every trader
+func SetSource(source Source) {
+	dataSource.mu.Lock()
+	defer dataSource.mu.Unlock()
+	dataSource.source = source
+}
+
+// currentSource the installed source (nil = Binance)
+func currentSource() Source {
+	dataSource.mu.RLock()
+	defer dataSource.mu.RUnlock()
+	return dataSource.source
+}
+
+// errNoOrderBook order books are not simulated
+var errNoOrderBook = fmt.Errorf("order book not available from a simulated market data source")
@@ -0,0 +1,136 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeframeWarmup candles fetched before the series so its first points have settled indicators
+const timeframeWarmup = 50
+
+// TimeframeData indicators and recent series of one extra candle timeframe (e.g. 15m, 1h, 1d)
+type TimeframeData struct {
+	Interval    string
+	Change      float64 // Price change % over the series
+	EMA20       float64
+	EMA50       float64
+	ATR14       float64
+	Closes      []float64
+	MACDValues  []float64
+	RSI14Values []float64
+}
+
+// Timeframe data cache (shares the market data TTL; traders asking for different series lengths get separate entries)
+var timeframeCache = struct {
+	entries map[string]cachedTimeframe
+	mu      sync.Mutex
+}{
+	entries: make(map[string]cachedTimeframe),
+}
+
+// cachedTimeframe timeframe data and when it was fetched
+type cachedTimeframe struct {
+	data      *TimeframeData
+	fetchedAt time.Time
+}
+
+// GetTimeframe gets the last points candles of interval for symbol with their indicators
+func GetTimeframe(symbol, interval string, points int) (*TimeframeData, error) {
+	symbol = Normalize(symbol)
+	key := fmt.Sprintf("%s_%s_%d", symbol, interval, points)
+
+	dataCache.mu.RLock()
+	ttl := dataCache.ttl
+	dataCache.mu.RUnlock()
+	if currentSource() != nil {
+		ttl = 0 // Simulated time: cached candles would be from another point of the path
+	}
+
+	if ttl > 0 {
+		timeframeCache.mu.Lock()
+		entry, ok := timeframeCache.entries[key]
+		timeframeCache.mu.Unlock()
+		if ok && time.Since(entry.fetchedAt) < ttl {
+			return entry.data, nil
+		}
+	}
+
+	klines, err := getKlines(symbol, interval, points+timeframeWarmup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s candlesticks: %w", interval, err)
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no %s candlesticks for %s", interval, symbol)
+	}
+	data := BuildTimeframe(interval, klines, points)
+
+	if ttl > 0 {
+		timeframeCache.mu.Lock()
+		timeframeCache.entries[key] = cachedTimeframe{data: data, fetchedAt: time.Now()}
+		for k, e := range timeframeCache.entries {
+			if time.Since(e.fetchedAt) >= ttl {
+				delete(timeframeCache.entries, k)
+			}
+		}
+		timeframeCache.mu.Unlock()
+	}
+	return data, nil
+}
+
+// BuildTimeframe computes timeframe data from candlesticks (oldest first, the last one is the current candle),
+// keeping the last points values of each series
+func BuildTimeframe(interval string, klines []Kline, points int) *TimeframeData {
+	data := &TimeframeData{
+		Interval:    interval,
+		EMA20:       calculateEMA(klines, 20),
+		EMA50:       calculateEMA(klines, 50),
+		ATR14:       calculateATR(klines, 14),
+		Closes:      make([]float64, 0, points),
+		MACDValues:  make([]float64, 0, points),
+		RSI14Values: make([]float64, 0, points),
+	}
+
+	start := len(klines) - points
+	if start < 0 {
+		start = 0
+	}
+	if first := klines[start].Close; first > 0 {
+		data.Change = (klines[len(klines)-1].Close - first) / first * 100
+	}
+
+	for i := start; i < len(klines); i++ {
+		data.Closes = append(data.Closes, klines[i].Close)
+		if i >= 25 {
+			data.MACDValues = append(data.MACDValues, calculateMACD(klines[:i+1]))
+		}
+		if i >= 14 {
+			data.RSI14Values = append(data.RSI14Values, calculateRSI(klines[:i+1], 14))
+		}
+	}
+	return data
+}
+
+// FormatTimeframe formats timeframe data for the prompt
+func FormatTimeframe(data *TimeframeData) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s timeframe (last %d candles, oldest → latest): change %+.2f%%\n\n",
+		data.Interval, len(data.Closes), data.Change))
+
+	sb.WriteString(fmt.Sprintf("Close prices: %s\n\n", formatFloatSlice(data.Closes)))
+
+	sb.WriteString(fmt.Sprintf("20‑Period EMA: %.3f vs. 50‑Period EMA: %.3f | 14‑Period ATR: %.3f\n\n",
+		data.EMA20, data.EMA50, data.ATR14))
+
+	if len(data.MACDValues) > 0 {
+		sb.WriteString(fmt.Sprintf("MACD indicators: %s\n\n", formatFloatSlice(data.MACDValues)))
+	}
+
+	if len(data.RSI14Values) > 0 {
+		sb.WriteString(fmt.Sprintf("RSI indicators (14‑Period): %s\n\n", formatFloatSlice(data.RSI14Values)))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+// anthropicVersion Messages API version sent with every request
+const anthropicVersion = "2023-06-01"
+
+// SetAnthropicAPIKey 设置Anthropic Claude API密钥（model 为空时使用 claude-sonnet-4-5）
+func (cfg *Client) SetAnthropicAPIKey(apiKey, model string) {
+	cfg.Provider = ProviderAnthropic
+	cfg.APIKey = apiKey
+	cfg.BaseURL = "https://api.anthropic.com/v1"
+	if model == "" {
+		cfg.Model = "claude-sonnet-4-5"
+	} else {
+		cfg.Model = model // e.g. "claude-opus-4-1", "claude-haiku-4-5"
+	}
+	cfg.Timeout = 180 * time.Second // Long prompts with a chain of thought take a while to stream
+}
+
+// anthropicEvent a Messages API stream event (only the fields used)
+type anthropicEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	} `json:"message"` // message_start
+	ContentBlock struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"content_block"` // content_block_start
+	Delta struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"` // content_block_delta, message_delta
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"` // message_delta (cumulative)
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callAnthropic one streamed Messages API call. Claude has no JSON mode, so every structured OutputMode asks for
+// the schema as a forced tool call and returns its input
+func (cfg *Client) callAnthropic(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema) (string, Usage, error) {
+	requestBody := map[string]interface{}{
+		"model": cfg.Model,
+		"messages": []map[string]string{
+			{"role": "user", "content": userPrompt},
+		},
+		"temperature": 0.5,
+		"max_tokens":  4000,
+		"stream":      true,
+	}
+	if systemPrompt != "" {
+		requestBody["system"] = systemPrompt
+	}
+	if schema != nil && cfg.OutputMode != OutputText {
+		requestBody["tools"] = []map[string]interface{}{{
+			"name":         schema.Name,
+			"description":  schema.Description,
+			"input_schema": schema.Schema,
+		}}
+		requestBody["tool_choice"] = map[string]string{"type": "tool", "name": schema.Name}
+	}
+
+	headers := map[string]string{
+		"x-api-key":         cfg.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var (
+		text, toolInput strings.Builder
+		inTool, stopped bool
+		inputTokens     int
+		outputTokens    int
+		stopReason      string
+	)
+	err := cfg.postStream(ctx, cfg.BaseURL+"/messages", headers, requestBody, func(data []byte) error {
+		var event anthropicEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			inputTokens = event.Message.Usage.InputTokens
+			outputTokens = event.Message.Usage.OutputTokens
+		case "content_block_start":
+			inTool = event.ContentBlock.Type == "tool_use" && schema != nil && event.ContentBlock.Name == schema.Name
+		case "content_block_delta":
+			switch {
+			case event.Delta.Type == "text_delta":
+				text.WriteString(event.Delta.Text)
+			case event.Delta.Type == "input_json_delta" && inTool:
+				toolInput.WriteString(event.Delta.PartialJSON)
+			}
+		case "message_delta":
+			outputTokens = event.Usage.OutputTokens
+			stopReason = event.Delta.StopReason
+		case "message_stop":
+			stopped = true
+		case "error":
+			// Overloaded/API errors arrive mid-stream with a 200 status; 529 keeps them retryable
+			status := 400
+			if event.Error.Type == "overloaded_error" || event.Error.Type == "api_error" {
+				status = 529
+			}
+			return &statusError{status: status, body: event.Error.Type + ": " + event.Error.Message}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if !stopped {
+		return "", Usage{}, fmt.Errorf("读取流式响应失败: stream ended before message_stop: %w", io.ErrUnexpectedEOF)
+	}
+	if stopReason == "max_tokens" {
+		log.Printf("⚠️  Claude response hit max_tokens - it may be truncated")
+	}
+
+	usage := cfg.usageOf(inputTokens, outputTokens)
+	if toolInput.Len() > 0 {
+		return toolInput.String(), usage, nil
+	}
+	if text.Len() == 0 {
+		return "", Usage{}, fmt.Errorf("API返回空响应")
+	}
+	return text.String(), usage, nil
+}
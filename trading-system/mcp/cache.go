@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Short-lived AI response cache shared by every client. Disabled by default; with a TTL, identical prompts sent
+// to the same model within it (traders running the same config, a trader restarted mid-cycle) are paid for once,
+// and a request already in flight for the same prompt is waited for instead of sent again
+var responseCache = struct {
+	entries  map[string]cachedResponse
+	inflight map[string]chan struct{}
+	ttl      time.Duration
+	mu       sync.Mutex
+}{
+	entries:  make(map[string]cachedResponse),
+	inflight: make(map[string]chan struct{}),
+}
+
+// cachedResponse an AI response, the model that produced it and when
+type cachedResponse struct {
+	content  string
+	model    string
+	storedAt time.Time
+}
+
+// SetResponseCacheTTL sets how long AI responses are reused for identical prompts (<= 0 disables the cache)
+func SetResponseCacheTTL(ttl time.Duration) {
+	responseCache.mu.Lock()
+	defer responseCache.mu.Unlock()
+	responseCache.ttl = ttl
+	if ttl <= 0 {
+		responseCache.entries = make(map[string]cachedResponse)
+	}
+}
+
+// responseCacheKey hash of the endpoint, model, prompts and requested output format
+func (cfg *Client) responseCacheKey(systemPrompt, userPrompt string, schema *ResponseSchema) string {
+	format := ""
+	if schema != nil && cfg.OutputMode != OutputText {
+		format = string(cfg.OutputMode) + ":" + schema.Name
+	}
+	h := sha256.New()
+	for _, part := range []string{cfg.BaseURL, cfg.Model, format, systemPrompt, userPrompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedCall answers from the response cache when possible, otherwise runs call and caches its successful
+// result. A cache hit returns the usage with no calls (nothing was paid) and CacheHits 1
+func (cfg *Client) cachedCall(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema, call func() (string, Usage, error)) (string, Usage, error) {
+	responseCache.mu.Lock()
+	ttl := responseCache.ttl
+	responseCache.mu.Unlock()
+	if ttl <= 0 || cfg.DisableCache {
+		return call()
+	}
+
+	key := cfg.responseCacheKey(systemPrompt, userPrompt, schema)
+	var done chan struct{}
+	for done == nil {
+		responseCache.mu.Lock()
+		if entry, ok := responseCache.entries[key]; ok && time.Since(entry.storedAt) < ttl {
+			responseCache.mu.Unlock()
+			log.Printf("♻️  AI response cache hit (%s, %v old) - request not sent", entry.model, time.Since(entry.storedAt).Round(time.Second))
+			return entry.content, Usage{Model: entry.model, CacheHits: 1}, nil
+		}
+		if wait, ok := responseCache.inflight[key]; ok {
+			responseCache.mu.Unlock()
+			// Same prompt already in flight: use its response, or send our own if it failed
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return "", Usage{}, fmt.Errorf("AI request cancelled: %w", ctx.Err())
+			}
+		}
+		done = make(chan struct{})
+		responseCache.inflight[key] = done
+		responseCache.mu.Unlock()
+	}
+
+	result, usage, err := call()
+
+	responseCache.mu.Lock()
+	delete(responseCache.inflight, key)
+	close(done)
+	if err == nil {
+		responseCache.entries[key] = cachedResponse{content: result, model: usage.Model, storedAt: time.Now()}
+		// Drop expired entries so the cache only holds the last TTL's responses
+		for k, e := range responseCache.entries {
+			if time.Since(e.storedAt) >= ttl {
+				delete(responseCache.entries, k)
+			}
+		}
+	}
+	responseCache.mu.Unlock()
+	return result, usage, err
+}
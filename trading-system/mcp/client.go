@@ -2,9 +2,12 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net"
 	"net/http"
 	"strings"
@@ -15,23 +18,58 @@ import (
 type Provider string
 
 const (
-	ProviderDeepSeek Provider = "deepseek"
-	ProviderQwen     Provider = "qwen"
-	ProviderGroq     Provider = "groq"
-	ProviderCustom   Provider = "custom"
+	ProviderDeepSeek  Provider = "deepseek"
+	ProviderQwen      Provider = "qwen"
+	ProviderGroq      Provider = "groq"
+	ProviderAnthropic Provider = "anthropic" // Claude Messages API (not OpenAI-compatible)
+	ProviderGemini    Provider = "gemini"    // Google Gemini generateContent API (not OpenAI-compatible)
+	ProviderCustom    Provider = "custom"
 )
 
+// ErrRequestTimeout an AI request exceeded the client timeout (not retried with the same prompt)
+var ErrRequestTimeout = errors.New("AI request timed out")
+
+// Retry defaults (attempts per provider, backoff before the first retry and its cap)
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = 5 * time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// RetryPolicy retries of a failed request on the same provider (zero values = defaults)
+type RetryPolicy struct {
+	MaxAttempts    int           // Attempts including the first
+	InitialBackoff time.Duration // Wait before the first retry, doubled for each further retry
+	MaxBackoff     time.Duration // Cap on the wait
+}
+
+// statusError the API answered with a non-200 status
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("API返回错误 (status %d): %s", e.status, e.body)
+}
+
 // Client AI API配置
 type Client struct {
-	Provider   Provider
-	APIKey     string
-	SecretKey  string // 阿里云需要
-	BaseURL    string
-	Model      string
-	Timeout    time.Duration
-	UseFullURL bool            // 是否使用完整URL（不添加/chat/completions）
-	transport  *http.Transport // 可复用的HTTP传输层，用于连接池
-	httpClient *http.Client    // 可复用的HTTP客户端
+	Provider     Provider
+	APIKey       string
+	SecretKey    string // 阿里云需要
+	BaseURL      string
+	Model        string
+	Timeout      time.Duration
+	UseFullURL   bool                  // 是否使用完整URL（不添加/chat/completions）
+	Prices       map[string]ModelPrice // Per-model prices overriding the built-in list (lowercase model names)
+	Retry        RetryPolicy           // Retries on this provider before failing over
+	Fallbacks    []*Client             // Providers tried in order when this one still fails after its retries
+	DisableCache bool                  // Always send requests, even when the shared response cache is enabled
+	OutputMode   OutputMode            // Structured output requested for calls with a schema (OutputText = prompt only)
+	script       *scriptedResponses    // Responses of ProviderScripted
+	transport    *http.Transport       // 可复用的HTTP传输层，用于连接池
+	httpClient   *http.Client          // 可复用的HTTP客户端
 }
 
 func New() *Client {
@@ -99,6 +137,14 @@ func (cfg *Client) SetCustomAPI(apiURL, apiKey, modelName string) {
 	cfg.Timeout = 120 * time.Second
 }
 
+// SetTimeout sets the per-request timeout (applies to the next request)
+func (cfg *Client) SetTimeout(timeout time.Duration) {
+	cfg.Timeout = timeout
+	if cfg.transport != nil {
+		cfg.resetConnection()
+	}
+}
+
 // SetClient 设置完整的AI配置（高级用户）
 func (cfg *Client) SetClient(Client Client) {
 	if Client.Timeout == 0 {
@@ -109,65 +155,141 @@ func (cfg *Client) SetClient(Client Client) {
 
 // CallWithMessages 使用 system + user prompt 调用AI API（推荐）
 func (cfg *Client) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return cfg.CallWithMessagesContext(context.Background(), systemPrompt, userPrompt)
+}
+
+// CallWithMessagesContext calls the AI API; cancelling ctx aborts the request and any pending retry.
+// A request that exceeds the timeout returns ErrRequestTimeout without retrying the same prompt
+func (cfg *Client) CallWithMessagesContext(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	result, _, err := cfg.CallWithUsage(ctx, systemPrompt, userPrompt)
+	return result, err
+}
+
+// CallWithUsage is CallWithMessagesContext that also returns the token usage and estimated cost of the
+// successful call (failed attempts are not billed by the providers and are not counted). When the provider
+// still fails after its retries, the Fallbacks are tried in order; the usage names the model that answered.
+// Identical prompts are answered from the response cache when it is enabled (see SetResponseCacheTTL)
+func (cfg *Client) CallWithUsage(ctx context.Context, systemPrompt, userPrompt string) (string, Usage, error) {
+	return cfg.CallStructured(ctx, systemPrompt, userPrompt, nil)
+}
+
+// callWithFailover calls this provider, then the Fallbacks in order until one succeeds
+func (cfg *Client) callWithFailover(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema) (string, Usage, error) {
+	result, usage, err := cfg.callWithRetries(ctx, systemPrompt, userPrompt, schema)
+	if err == nil || ctx.Err() != nil || len(cfg.Fallbacks) == 0 {
+		return result, usage, err
+	}
+
+	failed := cfg
+	for _, fallback := range cfg.Fallbacks {
+		log.Printf("🔀 AI provider %s (%s) failed: %v - failing over to %s (%s)", failed.Provider, failed.Model, err, fallback.Provider, fallback.Model)
+		result, usage, err = fallback.callWithRetries(ctx, systemPrompt, userPrompt, schema)
+		if err == nil {
+			return result, usage, nil
+		}
+		if ctx.Err() != nil {
+			return "", Usage{}, err
+		}
+		failed = fallback
+	}
+	return "", Usage{}, fmt.Errorf("all %d AI providers failed, last %s: %w", len(cfg.Fallbacks)+1, failed.Provider, err)
+}
+
+// backoff wait before retry attempt+1: InitialBackoff doubled per attempt, capped at MaxBackoff
+func (r RetryPolicy) backoff(attempt int) time.Duration {
+	initial, limit := r.InitialBackoff, r.MaxBackoff
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+	if limit <= 0 {
+		limit = DefaultMaxBackoff
+	}
+	wait := initial
+	for i := 1; i < attempt && wait < limit; i++ {
+		wait *= 2
+	}
+	if wait > limit {
+		wait = limit
+	}
+	return wait
+}
+
+// callWithRetries calls this provider, retrying network errors, rate limits and 5xx responses with backoff
+func (cfg *Client) callWithRetries(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema) (string, Usage, error) {
+	if cfg.Provider == ProviderScripted {
+		return cfg.callScripted(ctx)
+	}
 	if cfg.APIKey == "" {
-		return "", fmt.Errorf("AI API密钥未设置，请先调用 SetGroqAPIKey(), SetDeepSeekAPIKey() 或 SetQwenAPIKey()")
+		return "", Usage{}, fmt.Errorf("AI API密钥未设置，请先调用 SetGroqAPIKey(), SetDeepSeekAPIKey(), SetQwenAPIKey(), SetAnthropicAPIKey() 或 SetGeminiAPIKey()")
 	}
 
-	// 重试配置 - 增加重试次数以应对网络不稳定
-	maxRetries := 5
+	// 重试配置 - 默认5次，以应对网络不稳定
+	maxAttempts := cfg.Retry.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
 	var lastErr error
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		if attempt > 1 {
-			fmt.Printf("⚠️  AI API调用失败，正在重试 (%d/%d)...\n", attempt, maxRetries)
+			fmt.Printf("⚠️  AI API调用失败，正在重试 (%d/%d)...\n", attempt, maxAttempts)
 		}
 
-		result, err := cfg.callOnce(systemPrompt, userPrompt)
+		release, err := acquireProvider(ctx, cfg.Provider)
+		if err != nil {
+			return "", Usage{}, fmt.Errorf("AI request cancelled: %w", err)
+		}
+		result, usage, err := cfg.callOnce(ctx, systemPrompt, userPrompt, schema)
+		release()
 		if err == nil {
 			if attempt > 1 {
 				fmt.Printf("✓ AI API重试成功\n")
 			}
-			return result, nil
+			return result, usage, nil
+		}
+
+		if ctx.Err() != nil {
+			return "", Usage{}, fmt.Errorf("AI request cancelled: %w", ctx.Err())
+		}
+		if isTimeoutError(err) {
+			return "", Usage{}, fmt.Errorf("%w after %v: %v", ErrRequestTimeout, cfg.Timeout, err)
 		}
 
 		lastErr = err
-		// 如果不是网络错误，不重试
+		// 如果不是网络错误、限流或服务端错误，不重试
 		if !isRetryableError(err) {
-			return "", err
+			return "", Usage{}, err
 		}
 
-		// 如果是连接错误，重置HTTP客户端以强制新连接
+		// 重置HTTP客户端以强制新连接
 		// 这可以避免重用被服务器关闭的stale连接
-		if isRetryableError(err) {
-			cfg.resetConnection()
-		}
+		cfg.resetConnection()
 
-		// 重试前等待 - 使用指数退避，但增加初始延迟 (5s, 10s, 20s, 30s)
-		// 给Groq服务器更多时间恢复
-		if attempt < maxRetries {
-			var waitTime time.Duration
-			switch attempt {
-			case 1:
-				waitTime = 5 * time.Second // 第一次重试等待5秒
-			case 2:
-				waitTime = 10 * time.Second // 第二次重试等待10秒
-			case 3:
-				waitTime = 20 * time.Second // 第三次重试等待20秒
-			case 4:
-				waitTime = 30 * time.Second // 第四次重试等待30秒
-			default:
-				waitTime = 30 * time.Second
-			}
+		// 重试前等待 - 指数退避（默认 5s, 10s, 20s, 30s）
+		if attempt < maxAttempts {
+			waitTime := cfg.Retry.backoff(attempt)
 			fmt.Printf("⏳ 等待%v后重试...\n", waitTime)
-			time.Sleep(waitTime)
+			select {
+			case <-time.After(waitTime):
+			case <-ctx.Done():
+				return "", Usage{}, fmt.Errorf("AI request cancelled: %w", ctx.Err())
+			}
 		}
 	}
 
-	return "", fmt.Errorf("重试%d次后仍然失败: %w", maxRetries, lastErr)
+	return "", Usage{}, fmt.Errorf("重试%d次后仍然失败: %w", maxAttempts, lastErr)
 }
 
-// callOnce 单次调用AI API（内部使用）
-func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
+// callOnce 单次调用AI API（内部使用）；schema 非nil时按 OutputMode 请求结构化输出
+func (cfg *Client) callOnce(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema) (string, Usage, error) {
+	// Claude 和 Gemini 使用各自的消息格式和流式接口
+	switch cfg.Provider {
+	case ProviderAnthropic:
+		return cfg.callAnthropic(ctx, systemPrompt, userPrompt, schema)
+	case ProviderGemini:
+		return cfg.callGemini(ctx, systemPrompt, userPrompt, schema)
+	}
+
 	// 构建 messages 数组
 	messages := []map[string]string{}
 
@@ -193,12 +315,13 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 		"max_tokens":  4000, // 增加token限制以支持完整的chain of thought + JSON响应
 	}
 
-	// 注意：response_format 参数仅 OpenAI 支持，DeepSeek/Qwen 不支持
-	// 我们通过强化 prompt 和后处理来确保 JSON 格式正确
+	// 结构化输出（JSON mode / function calling）需要 provider 支持，由 OutputMode 按 trader 配置开启
+	// 未开启时通过强化 prompt 和后处理来确保 JSON 格式正确
+	cfg.applyOutputFormat(requestBody, schema)
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
-		return "", fmt.Errorf("序列化请求失败: %w", err)
+		return "", Usage{}, fmt.Errorf("序列化请求失败: %w", err)
 	}
 
 	// 创建HTTP请求
@@ -210,9 +333,9 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 		// 默认行为：添加/chat/completions
 		url = fmt.Sprintf("%s/chat/completions", cfg.BaseURL)
 	}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %w", err)
+		return "", Usage{}, fmt.Errorf("创建请求失败: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -239,38 +362,49 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 	}
 	resp, err := cfg.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("发送请求失败: %w", err)
+		return "", Usage{}, fmt.Errorf("发送请求失败: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %w", err)
+		return "", Usage{}, fmt.Errorf("读取响应失败: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+		return "", Usage{}, &statusError{status: resp.StatusCode, body: string(body)}
 	}
 
 	// 解析响应
 	var result struct {
 		Choices []struct {
 			Message struct {
-				Content string `json:"content"`
+				Content   string     `json:"content"`
+				ToolCalls []toolCall `json:"tool_calls"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("解析响应失败: %w", err)
+		return "", Usage{}, fmt.Errorf("解析响应失败: %w", err)
 	}
 
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("API返回空响应")
+		return "", Usage{}, fmt.Errorf("API返回空响应")
 	}
 
-	return result.Choices[0].Message.Content, nil
+	// OpenAI兼容接口在 usage 中返回token用量（缺失时记为0）
+	usage := cfg.usageOf(result.Usage.PromptTokens, result.Usage.CompletionTokens)
+	// Function calling answers with the arguments of the forced tool call instead of message content
+	if args, ok := toolCallArguments(result.Choices[0].Message.ToolCalls, schema); ok {
+		return args, usage, nil
+	}
+	return result.Choices[0].Message.Content, usage, nil
 }
 
 // initConnection 初始化HTTP连接（创建新的transport和client）
@@ -291,7 +425,7 @@ func (cfg *Client) initConnection() {
 			KeepAlive: 15 * time.Second, // 减少KeepAlive时间，更快检测断开的连接
 		}).DialContext,
 		TLSHandshakeTimeout:   10 * time.Second,
-		ResponseHeaderTimeout: 30 * time.Second,
+		ResponseHeaderTimeout: cfg.Timeout, // Non-streaming completions send headers only when generation is done
 		ExpectContinueTimeout: 1 * time.Second,
 		// 强制HTTP/1.1（某些代理可能不支持HTTP/2）
 		ForceAttemptHTTP2: false,
@@ -314,8 +448,26 @@ func (cfg *Client) resetConnection() {
 	cfg.initConnection()
 }
 
+// isTimeoutError whether err is a request timeout (client timeout or deadline)
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return strings.Contains(err.Error(), "Client.Timeout exceeded") || strings.Contains(err.Error(), "timeout awaiting response headers")
+}
+
 // isRetryableError 判断错误是否可重试
 func isRetryableError(err error) bool {
+	// 限流(429)和服务端错误(5xx)可以重试，其他HTTP错误不重试
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status == http.StatusTooManyRequests || statusErr.status >= 500
+	}
+
 	errStr := err.Error()
 	// 网络错误、超时、EOF等可以重试
 	retryableErrors := []string{
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Embed 调用OpenAI兼容的 /embeddings 接口，返回与 inputs 一一对应的向量
+func (cfg *Client) Embed(model string, inputs []string) ([][]float64, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("embedding API密钥未设置")
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	requestBody := map[string]interface{}{
+		"model": model,
+		"input": inputs,
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	url := strings.TrimSuffix(cfg.BaseURL, "/") + "/embeddings"
+	if cfg.UseFullURL {
+		url = cfg.BaseURL
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.APIKey))
+
+	if cfg.transport == nil {
+		cfg.initConnection()
+	}
+	resp, err := cfg.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(result.Data) != len(inputs) {
+		return nil, fmt.Errorf("API返回 %d 个向量，期望 %d 个", len(result.Data), len(inputs))
+	}
+
+	vectors := make([][]float64, len(inputs))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(inputs) {
+			return nil, fmt.Errorf("API返回无效的向量索引: %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// SetGeminiAPIKey 设置Google Gemini API密钥（model 为空时使用 gemini-2.5-flash）
+func (cfg *Client) SetGeminiAPIKey(apiKey, model string) {
+	cfg.Provider = ProviderGemini
+	cfg.APIKey = apiKey
+	cfg.BaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	if model == "" {
+		cfg.Model = "gemini-2.5-flash"
+	} else {
+		cfg.Model = model // e.g. "gemini-2.5-pro"
+	}
+	cfg.Timeout = 180 * time.Second // Thinking models stream their answer after a long pause
+}
+
+// geminiChunk a streamGenerateContent chunk (only the fields used)
+type geminiChunk struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text         string `json:"text"`
+				Thought      bool   `json:"thought"`
+				FunctionCall *struct {
+					Name string          `json:"name"`
+					Args json.RawMessage `json:"args"`
+				} `json:"functionCall"`
+			} `json:"parts"`
+		} `json:"content"`
+		FinishReason string `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"` // Billed as output
+	} `json:"usageMetadata"` // Cumulative, complete in the last chunk
+}
+
+// callGemini one streamed generateContent call. json/json_schema use the response MIME type (with the schema),
+// tool forces a function call and returns its arguments
+func (cfg *Client) callGemini(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema) (string, Usage, error) {
+	generationConfig := map[string]interface{}{
+		"temperature":     0.5,
+		"maxOutputTokens": 4000,
+	}
+	requestBody := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{"role": "user", "parts": []map[string]string{{"text": userPrompt}}},
+		},
+		"generationConfig": generationConfig,
+	}
+	if systemPrompt != "" {
+		requestBody["systemInstruction"] = map[string]interface{}{
+			"parts": []map[string]string{{"text": systemPrompt}},
+		}
+	}
+	if schema != nil {
+		switch cfg.OutputMode {
+		case OutputJSON:
+			generationConfig["responseMimeType"] = "application/json"
+		case OutputJSONSchema:
+			generationConfig["responseMimeType"] = "application/json"
+			generationConfig["responseJsonSchema"] = schema.Schema
+		case OutputTool:
+			requestBody["tools"] = []map[string]interface{}{{
+				"functionDeclarations": []map[string]interface{}{{
+					"name":                 schema.Name,
+					"description":          schema.Description,
+					"parametersJsonSchema": schema.Schema,
+				}},
+			}}
+			requestBody["toolConfig"] = map[string]interface{}{
+				"functionCallingConfig": map[string]interface{}{
+					"mode":                 "ANY",
+					"allowedFunctionNames": []string{schema.Name},
+				},
+			}
+		}
+	}
+
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", cfg.BaseURL, cfg.Model)
+	headers := map[string]string{"x-goog-api-key": cfg.APIKey}
+
+	var (
+		text         strings.Builder
+		functionArgs string
+		promptTokens int
+		outputTokens int
+		finishReason string
+		blockReason  string
+	)
+	err := cfg.postStream(ctx, url, headers, requestBody, func(data []byte) error {
+		var chunk geminiChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.PromptFeedback.BlockReason != "" {
+			blockReason = chunk.PromptFeedback.BlockReason
+		}
+		if chunk.UsageMetadata.PromptTokenCount > 0 {
+			promptTokens = chunk.UsageMetadata.PromptTokenCount
+			outputTokens = chunk.UsageMetadata.CandidatesTokenCount + chunk.UsageMetadata.ThoughtsTokenCount
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+		candidate := chunk.Candidates[0]
+		if candidate.FinishReason != "" {
+			finishReason = candidate.FinishReason
+		}
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.FunctionCall != nil && schema != nil && part.FunctionCall.Name == schema.Name:
+				functionArgs = string(part.FunctionCall.Args)
+			case !part.Thought:
+				text.WriteString(part.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if blockReason != "" {
+		return "", Usage{}, fmt.Errorf("prompt blocked by Gemini: %s", blockReason)
+	}
+	if finishReason == "MAX_TOKENS" {
+		log.Printf("⚠️  Gemini response hit maxOutputTokens - it may be truncated")
+	}
+
+	usage := cfg.usageOf(promptTokens, outputTokens)
+	if functionArgs != "" {
+		return functionArgs, usage, nil
+	}
+	if text.Len() == 0 {
+		return "", Usage{}, fmt.Errorf("API返回空响应 (finishReason: %s)", finishReason)
+	}
+	return text.String(), usage, nil
+}
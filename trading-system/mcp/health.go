@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Ping 检查AI服务是否可达（不消耗token：请求 /models，任何非5xx响应都视为可达）
+func (cfg *Client) Ping(ctx context.Context) error {
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("AI API地址未设置")
+	}
+
+	url := strings.TrimSuffix(cfg.BaseURL, "/") + "/models"
+	if cfg.UseFullURL {
+		url = cfg.BaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	if cfg.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.APIKey))
+	}
+
+	// 使用独立的客户端，避免与进行中的AI调用共享（并重建）连接池
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("API返回错误 (status %d)", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ProviderScripted answers with recorded responses instead of calling an AI API (the simulate exchange mode)
+const ProviderScripted Provider = "scripted"
+
+// scriptedResponses recorded responses handed out in order, starting over after the last one
+type scriptedResponses struct {
+	responses []string
+	next      int
+	mu        sync.Mutex
+}
+
+// SetScriptedResponses makes the client answer every call with the next of responses (cycling) without any
+// network call, so the decision pipeline runs deterministically. Failover providers and the response cache
+// are disabled
+func (cfg *Client) SetScriptedResponses(responses []string) {
+	cfg.Provider = ProviderScripted
+	cfg.Model = string(ProviderScripted)
+	cfg.Fallbacks = nil
+	cfg.DisableCache = true
+	cfg.script = &scriptedResponses{responses: responses}
+}
+
+// callScripted returns the next scripted response
+func (cfg *Client) callScripted(ctx context.Context) (string, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return "", Usage{}, err
+	}
+	s := cfg.script
+	if s == nil || len(s.responses) == 0 {
+		return "", Usage{}, fmt.Errorf("no scripted AI responses configured")
+	}
+	s.mu.Lock()
+	response := s.responses[s.next%len(s.responses)]
+	s.next++
+	s.mu.Unlock()
+	return response, Usage{Model: cfg.Model, Calls: 1}, nil
+}
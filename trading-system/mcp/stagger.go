@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ProviderLimit how the AI calls of one provider are spread out across every trader of the process
+type ProviderLimit struct {
+	MaxConcurrent int           // Calls in flight at once (0 = unlimited)
+	MinSpacing    time.Duration // Minimum time between the starts of two calls (0 = none)
+}
+
+// providerGate admits a provider's calls within its limit, in arrival order
+type providerGate struct {
+	limit ProviderLimit
+	slots chan struct{} // nil = unlimited concurrency
+
+	mu   sync.Mutex
+	next time.Time // Earliest start of the next call
+}
+
+// staggerLogThreshold waits at least this long are logged
+const staggerLogThreshold = time.Second
+
+var providerGates = struct {
+	gates map[Provider]*providerGate
+	mu    sync.RWMutex
+}{
+	gates: make(map[Provider]*providerGate),
+}
+
+// SetProviderLimit limits the calls of provider across all clients (a zero limit removes it)
+func SetProviderLimit(provider Provider, limit ProviderLimit) {
+	providerGates.mu.Lock()
+	defer providerGates.mu.Unlock()
+	if limit.MaxConcurrent <= 0 && limit.MinSpacing <= 0 {
+		delete(providerGates.gates, provider)
+		return
+	}
+	gate := &providerGate{limit: limit}
+	if limit.MaxConcurrent > 0 {
+		gate.slots = make(chan struct{}, limit.MaxConcurrent)
+	}
+	providerGates.gates[provider] = gate
+}
+
+// acquireProvider waits until provider may take another call; release must be called when it returns
+func acquireProvider(ctx context.Context, provider Provider) (release func(), err error) {
+	providerGates.mu.RLock()
+	gate := providerGates.gates[provider]
+	providerGates.mu.RUnlock()
+	if gate == nil {
+		return func() {}, nil
+	}
+
+	queued := time.Now()
+	if gate.slots != nil {
+		select {
+		case gate.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release = func() {
+		if gate.slots != nil {
+			<-gate.slots
+		}
+	}
+
+	if gate.limit.MinSpacing > 0 {
+		gate.mu.Lock()
+		start := time.Now()
+		if gate.next.After(start) {
+			start = gate.next
+		}
+		gate.next = start.Add(gate.limit.MinSpacing)
+		gate.mu.Unlock()
+
+		if wait := time.Until(start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if waited := time.Since(queued); waited >= staggerLogThreshold {
+		log.Printf("⏳ AI provider %s: call staggered %v (limit: %d concurrent, %v apart)",
+			provider, waited.Round(100*time.Millisecond), gate.limit.MaxConcurrent, gate.limit.MinSpacing)
+	}
+	return release, nil
+}
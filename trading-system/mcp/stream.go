@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// postStream sends a JSON request to an endpoint answering with server-sent events and calls onEvent with the
+// data of each event until the stream ends (non-200 responses return a statusError)
+func (cfg *Client) postStream(ctx context.Context, url string, headers map[string]string, requestBody interface{}, onEvent func(data []byte) error) error {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if cfg.transport == nil {
+		cfg.initConnection()
+	}
+	resp, err := cfg.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &statusError{status: resp.StatusCode, body: string(body)}
+	}
+
+	// Both providers send each event's JSON on a single "data:" line
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+			data = strings.TrimSpace(data)
+			if data != "" && data != "[DONE]" {
+				if err := onEvent([]byte(data)); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取流式响应失败: %w", err)
+		}
+	}
+}
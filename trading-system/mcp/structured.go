@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// OutputMode how a call with a response schema asks the provider for structured output
+type OutputMode string
+
+const (
+	OutputText       OutputMode = ""            // Prompt only; the schema is not sent
+	OutputJSON       OutputMode = "json"        // JSON mode: response_format {"type":"json_object"} (OpenAI, Groq, DeepSeek, Qwen)
+	OutputJSONSchema OutputMode = "json_schema" // response_format with the schema (OpenAI, Groq models that support it)
+	OutputTool       OutputMode = "tool"        // Function calling: the schema as a tool the model is forced to call
+)
+
+// ResponseSchema JSON schema of a structured response
+type ResponseSchema struct {
+	Name        string          // Schema / function name (letters, digits, _ and -)
+	Description string          // What the response is (tool description)
+	Schema      json.RawMessage // JSON schema of the response object
+}
+
+// toolCall a function call in a chat completion message
+type toolCall struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// CallStructured is CallWithUsage that asks for a response matching schema in the client's OutputMode
+// (nil schema or OutputText = plain text call). The response is the JSON text of the object: the message
+// content in the JSON modes, the arguments of the forced tool call in tool mode. Providers that ignore the
+// format still answer as instructed by the prompt, so callers keep parsing text responses
+func (cfg *Client) CallStructured(ctx context.Context, systemPrompt, userPrompt string, schema *ResponseSchema) (string, Usage, error) {
+	return cfg.cachedCall(ctx, systemPrompt, userPrompt, schema, func() (string, Usage, error) {
+		return cfg.callWithFailover(ctx, systemPrompt, userPrompt, schema)
+	})
+}
+
+// applyOutputFormat adds the structured output parameters of the client's OutputMode to a request body
+func (cfg *Client) applyOutputFormat(requestBody map[string]interface{}, schema *ResponseSchema) {
+	if schema == nil {
+		return
+	}
+	switch cfg.OutputMode {
+	case OutputJSON:
+		requestBody["response_format"] = map[string]interface{}{"type": "json_object"}
+	case OutputJSONSchema:
+		requestBody["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   schema.Name,
+				"schema": schema.Schema,
+				"strict": false, // Optional fields are left out rather than sent as null
+			},
+		}
+	case OutputTool:
+		requestBody["tools"] = []map[string]interface{}{{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        schema.Name,
+				"description": schema.Description,
+				"parameters":  schema.Schema,
+			},
+		}}
+		requestBody["tool_choice"] = map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": schema.Name},
+		}
+	}
+}
+
+// toolCallArguments the arguments of the schema's function call (false when the model answered in content)
+func toolCallArguments(calls []toolCall, schema *ResponseSchema) (string, bool) {
+	if schema == nil {
+		return "", false
+	}
+	for _, call := range calls {
+		if call.Function.Name == schema.Name && call.Function.Arguments != "" {
+			return call.Function.Arguments, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"log"
+	"strings"
+	"sync"
+)
+
+// ModelPrice USD per million tokens of a model
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// defaultModelPrices list prices of the models the providers are usually run with (lowercase model names;
+// override or extend with SetPrices)
+var defaultModelPrices = map[string]ModelPrice{
+	// DeepSeek
+	"deepseek-chat":     {InputPerMillion: 0.27, OutputPerMillion: 1.10},
+	"deepseek-reasoner": {InputPerMillion: 0.55, OutputPerMillion: 2.19},
+	// Qwen (DashScope international)
+	"qwen-turbo": {InputPerMillion: 0.05, OutputPerMillion: 0.20},
+	"qwen-plus":  {InputPerMillion: 0.40, OutputPerMillion: 1.20},
+	"qwen-max":   {InputPerMillion: 1.60, OutputPerMillion: 6.40},
+	// Groq
+	"llama-3.1-70b-versatile": {InputPerMillion: 0.59, OutputPerMillion: 0.79},
+	"llama-3.3-70b-versatile": {InputPerMillion: 0.59, OutputPerMillion: 0.79},
+	"llama-3.1-8b-instant":    {InputPerMillion: 0.05, OutputPerMillion: 0.08},
+	"openai/gpt-oss-120b":     {InputPerMillion: 0.15, OutputPerMillion: 0.75},
+	"openai/gpt-oss-20b":      {InputPerMillion: 0.10, OutputPerMillion: 0.50},
+	"qwen/qwen3-32b":          {InputPerMillion: 0.29, OutputPerMillion: 0.59},
+	// Anthropic
+	"claude-opus-4-1":   {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+	"claude-sonnet-4-5": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-haiku-4-5":  {InputPerMillion: 1.00, OutputPerMillion: 5.00},
+	// Google Gemini
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-flash-lite": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	// OpenAI-compatible custom endpoints
+	"gpt-4o":      {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+}
+
+// unpricedModels models already warned about having no price (warned once per process)
+var unpricedModels sync.Map
+
+// Usage token usage and estimated cost of one or more AI calls
+type Usage struct {
+	Model            string  `json:"model,omitempty"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`             // 0 when the model has no known price
+	CacheHits        int     `json:"cache_hits,omitempty"` // Calls answered from the response cache (not paid for)
+}
+
+// Add adds another call's usage (Model becomes "mixed" when the calls used different models)
+func (u *Usage) Add(other Usage) {
+	switch {
+	case u.Model == "":
+		u.Model = other.Model
+	case other.Model != "" && other.Model != u.Model:
+		u.Model = "mixed"
+	}
+	u.Calls += other.Calls
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.CostUSD += other.CostUSD
+	u.CacheHits += other.CacheHits
+}
+
+// SetPrices sets per-model prices (model name → price) used instead of the built-in list
+func (cfg *Client) SetPrices(prices map[string]ModelPrice) {
+	cfg.Prices = make(map[string]ModelPrice, len(prices))
+	for model, price := range prices {
+		cfg.Prices[strings.ToLower(model)] = price
+	}
+}
+
+// price the configured or built-in price of the client's model
+func (cfg *Client) price() (ModelPrice, bool) {
+	model := strings.ToLower(cfg.Model)
+	if price, ok := cfg.Prices[model]; ok {
+		return price, true
+	}
+	price, ok := defaultModelPrices[model]
+	return price, ok
+}
+
+// usageOf the usage of one call with its estimated cost
+func (cfg *Client) usageOf(promptTokens, completionTokens int) Usage {
+	usage := Usage{
+		Model:            cfg.Model,
+		Calls:            1,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+	}
+	price, ok := cfg.price()
+	if !ok {
+		if _, warned := unpricedModels.LoadOrStore(cfg.Model, true); !warned {
+			log.Printf("⚠️  No price for AI model '%s' - its cost is recorded as 0 (set ai_request.prices to estimate it)", cfg.Model)
+		}
+		return usage
+	}
+	usage.CostUSD = (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1e6
+	return usage
+}
@@ -0,0 +1,78 @@
+package multiagent
+
+import (
+	"fmt"
+	"lia/decision"
+	"log"
+	"strings"
+	"time"
+)
+
+// chairReasoningChars how much of each proposal's reasoning the chair sees
+const chairReasoningChars = 300
+
+// chairConsensus the chair model reads every collected proposal next to the market data and makes the final
+// decisions itself
+func chairConsensus(ctx *decision.Context, results []AgentResult, config *MultiAgentConfig, transcript *Transcript) (*decision.FullDecision, error) {
+	chair := config.Chair
+	client := createAgentClient(*chair)
+	if client == nil {
+		return nil, fmt.Errorf("chair %s: unsupported provider '%s'", chair.ID, chair.Provider)
+	}
+	log.Printf("🪑 Chair %s (%s) deciding from %d proposals", chair.ID, client.Model, len(results))
+
+	chairCtx := cloneContext(ctx)
+	chairCtx.CommitteeBriefing = committeeBriefing(results, config)
+
+	start := time.Now()
+	final, err := decision.GetFullDecision(chairCtx, client)
+	transcript.addChair(chair, AgentResult{Decision: final, Err: err, AgentID: chair.ID, Duration: time.Since(start)})
+	if err != nil {
+		return nil, fmt.Errorf("chair %s: %w", chair.ID, err)
+	}
+	return final, nil
+}
+
+// committeeBriefing the collected proposals as a user prompt section for the chair
+func committeeBriefing(results []AgentResult, config *MultiAgentConfig) string {
+	roles := make(map[string]string)
+	for _, agent := range config.Agents {
+		roles[agent.ID] = agent.Role
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n## 🪑 Committee Proposals\n\n")
+	sb.WriteString(fmt.Sprintf("You chair a committee of %d trading agents. Check each proposal below against the market data above, "+
+		"then output the final decisions in the required format: adopt, combine or reject proposals, or wait.\n\n", len(results)))
+	for _, result := range results {
+		if role := roles[result.AgentID]; role != "" {
+			sb.WriteString(fmt.Sprintf("### Agent %s (%s)\n", result.AgentID, role))
+		} else {
+			sb.WriteString(fmt.Sprintf("### Agent %s\n", result.AgentID))
+		}
+		for _, d := range result.Decision.Decisions {
+			sb.WriteString(fmt.Sprintf("- %s %s", d.Symbol, d.Action))
+			if d.Leverage > 0 {
+				sb.WriteString(fmt.Sprintf(" %dx", d.Leverage))
+			}
+			if d.PositionSizeUSD > 0 {
+				sb.WriteString(fmt.Sprintf(" margin %.0f", d.PositionSizeUSD))
+			}
+			if d.StopLoss > 0 || d.TakeProfit > 0 {
+				sb.WriteString(fmt.Sprintf(" SL %.4f TP %.4f", d.StopLoss, d.TakeProfit))
+			}
+			if d.Confidence > 0 {
+				sb.WriteString(fmt.Sprintf(" (confidence %d)", d.Confidence))
+			}
+			if reasoning := strings.Join(strings.Fields(d.Reasoning), " "); reasoning != "" {
+				if runes := []rune(reasoning); len(runes) > chairReasoningChars {
+					reasoning = string(runes[:chairReasoningChars]) + "..."
+				}
+				sb.WriteString(": " + reasoning)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -6,9 +6,10 @@ import "fmt"
 type AgentConfig struct {
 	ID        string  `json:"id"`         // Unique agent ID
 	Name      string  `json:"name"`       // Agent display name
-	Model     string  `json:"model"`      // "groq", "qwen", "deepseek", or "custom"
+	Provider  string  `json:"provider"`   // "groq", "qwen", "deepseek", "anthropic", "gemini" or "custom"
+	Model     string  `json:"model"`      // Model name ("" = the provider's default)
 	APIKey    string  `json:"api_key"`    // API key for this agent
-	GroqModel string  `json:"groq_model,omitempty"` // Groq model name (if using Groq)
+	APIURL    string  `json:"api_url,omitempty"` // OpenAI-compatible endpoint (custom provider)
 	Role      string  `json:"role,omitempty"`       // Agent role: "technical", "momentum", "risk", "trend"
 	Weight    float64 `json:"weight,omitempty"`     // Weight for weighted consensus (0.0-1.0)
 }
@@ -16,11 +17,18 @@ type AgentConfig struct {
 // MultiAgentConfig configuration for multi-agent system
 type MultiAgentConfig struct {
 	Enabled       bool          `json:"enabled"`        // Enable multi-agent mode
-	ConsensusMode string        `json:"consensus_mode"` // "voting", "weighted", "unanimous", "best"
+	ConsensusMode string        `json:"consensus_mode"` // "voting"/"majority", "weighted", "sharpe", "veto", "chair", "unanimous", "best"
 	FastFirst     bool          `json:"fast_first"`    // Use fast-first (don't wait for all)
 	MinAgents     int           `json:"min_agents"`    // Minimum agents needed (for fast-first)
 	MaxWaitTime   int           `json:"max_wait_time"` // Max wait time in seconds
 	Agents        []AgentConfig `json:"agents"`        // List of agents
+
+	VetoAgent string       `json:"veto_agent,omitempty"` // veto: ID of the risk agent whose objections block opens
+	Chair     *AgentConfig `json:"chair,omitempty"`      // chair: the model that reads every proposal and decides
+
+	// AgentSharpe per-trade Sharpe ratio of each agent's proposals with enough closed trades (sharpe mode, set
+	// by the trader every cycle)
+	AgentSharpe map[string]float64 `json:"-"`
 }
 
 // Validate validates multi-agent configuration
@@ -39,12 +47,22 @@ func (c *MultiAgentConfig) Validate() error {
 
 	validModes := map[string]bool{
 		"voting":    true,
+		"majority":  true,
 		"weighted":  true,
+		"sharpe":    true,
+		"veto":      true,
+		"chair":     true,
 		"unanimous": true,
 		"best":      true,
 	}
 	if !validModes[c.ConsensusMode] {
-		return fmt.Errorf("invalid consensus_mode: %s (must be: voting, weighted, unanimous, best)", c.ConsensusMode)
+		return fmt.Errorf("invalid consensus_mode: %s (must be: voting, majority, weighted, sharpe, veto, chair, unanimous, best)", c.ConsensusMode)
+	}
+	if c.ConsensusMode == "chair" && c.Chair == nil {
+		return fmt.Errorf("chair consensus needs a chair agent")
+	}
+	if c.ConsensusMode == "veto" && c.VetoAgent == "" {
+		return fmt.Errorf("veto consensus needs veto_agent")
 	}
 
 	// Validate each agent
@@ -62,14 +80,16 @@ func (c *MultiAgentConfig) Validate() error {
 			return fmt.Errorf("agent[%d]: Name cannot be empty", i)
 		}
 
-		validModels := map[string]bool{
-			"groq":     true,
-			"qwen":     true,
-			"deepseek": true,
-			"custom":   true,
+		validProviders := map[string]bool{
+			"groq":      true,
+			"qwen":      true,
+			"deepseek":  true,
+			"anthropic": true,
+			"gemini":    true,
+			"custom":    true,
 		}
-		if !validModels[agent.Model] {
-			return fmt.Errorf("agent[%d]: invalid model '%s'", i, agent.Model)
+		if !validProviders[agent.Provider] {
+			return fmt.Errorf("agent[%d]: invalid provider '%s'", i, agent.Provider)
 		}
 
 		if agent.APIKey == "" {
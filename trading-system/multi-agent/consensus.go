@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"lia/decision"
 	"log"
+	"slices"
+	"strings"
+	"time"
 )
 
 // AgentResult represents a single agent's decision result
@@ -13,6 +16,7 @@ type AgentResult struct {
 	Err      error
 	AgentID  string
 	AgentIdx int
+	Duration time.Duration // How long the agent's AI call took
 }
 
 // ApplyConsensus applies consensus logic to combine multiple agent decisions
@@ -21,6 +25,12 @@ func ApplyConsensus(results []AgentResult, config *MultiAgentConfig) (*decision.
 		return nil, fmt.Errorf("no results to apply consensus")
 	}
 
+	// The risk agent's objections apply even when a single agent proposed trades
+	if config.ConsensusMode == "veto" {
+		final, _, err := vetoConsensus(results, config)
+		return final, err
+	}
+
 	// Filter out nil decisions
 	validResults := make([]AgentResult, 0)
 	for _, r := range results {
@@ -49,9 +59,9 @@ func ApplyConsensus(results []AgentResult, config *MultiAgentConfig) (*decision.
 
 	// Apply consensus based on mode
 	switch config.ConsensusMode {
-	case "voting":
+	case "voting", "majority":
 		return votingConsensus(validResults, config)
-	case "weighted":
+	case "weighted", "sharpe":
 		return weightedConsensus(validResults, config)
 	case "unanimous":
 		return unanimousConsensus(validResults, config)
@@ -134,26 +144,12 @@ func votingConsensus(results []AgentResult, config *MultiAgentConfig) (*decision
 	}, nil
 }
 
-// weightedConsensus uses weighted voting based on agent weights
+// weightedConsensus uses weighted voting based on agent weights (sharpe mode: their historical Sharpe ratio)
 func weightedConsensus(results []AgentResult, config *MultiAgentConfig) (*decision.FullDecision, error) {
-	log.Printf("⚖️  Applying weighted consensus with %d agents", len(results))
-
-	// Create map of agent ID to weight
-	agentWeights := make(map[string]float64)
-	totalWeight := 0.0
-	for _, agent := range config.Agents {
-		weight := agent.Weight
-		if weight == 0 {
-			weight = 1.0 / float64(len(config.Agents)) // Equal weight if not specified
-		}
-		agentWeights[agent.ID] = weight
-		totalWeight += weight
-	}
+	log.Printf("⚖️  Applying %s consensus with %d agents", config.ConsensusMode, len(results))
 
-	// Normalize weights
-	for id := range agentWeights {
-		agentWeights[id] /= totalWeight
-	}
+	// Normalized weight of each agent
+	weights := consensusWeights(config)
 
 	// Group decisions by symbol+action with weighted votes
 	type decisionKey struct {
@@ -165,7 +161,7 @@ func weightedConsensus(results []AgentResult, config *MultiAgentConfig) (*decisi
 	confidenceSum := make(map[decisionKey]float64)
 
 	for _, result := range results {
-		weight := agentWeights[result.AgentID]
+		weight := weights[result.AgentID]
 		for _, d := range result.Decision.Decisions {
 			key := decisionKey{symbol: d.Symbol, action: d.Action}
 			weightedVotes[key] += weight
@@ -207,7 +203,7 @@ func weightedConsensus(results []AgentResult, config *MultiAgentConfig) (*decisi
 	for i, result := range results {
 		if result.Decision.CoTTrace != "" {
 			combinedCoT += fmt.Sprintf("=== Agent %s (weight: %.1f%%) ===\n%s\n\n",
-				result.AgentID, agentWeights[result.AgentID]*100, result.Decision.CoTTrace)
+				result.AgentID, weights[result.AgentID]*100, result.Decision.CoTTrace)
 		}
 		if i >= 2 {
 			break
@@ -222,6 +218,100 @@ func weightedConsensus(results []AgentResult, config *MultiAgentConfig) (*decisi
 	}, nil
 }
 
+// vetoConsensus majority vote of the other agents, then the risk agent vetoes the opens it objects to (returned
+// with the final decision)
+func vetoConsensus(results []AgentResult, config *MultiAgentConfig) (*decision.FullDecision, []decision.Decision, error) {
+	log.Printf("🛡️  Applying veto consensus (risk agent: %s)", config.VetoAgent)
+
+	var others []AgentResult
+	for _, r := range results {
+		if r.AgentID != config.VetoAgent && r.Decision != nil && len(r.Decision.Decisions) > 0 {
+			others = append(others, r)
+		}
+	}
+	if len(others) == 0 {
+		return &decision.FullDecision{
+			Decisions: []decision.Decision{
+				{
+					Symbol:    "ALL",
+					Action:    "wait",
+					Reasoning: "No proposals besides the risk agent",
+				},
+			},
+		}, nil, nil
+	}
+
+	proposed := others[0].Decision
+	if len(others) > 1 {
+		var err error
+		if proposed, err = votingConsensus(others, config); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	kept, vetoed := applyVeto(results, proposed.Decisions, config.VetoAgent)
+	if len(vetoed) == 0 {
+		return proposed, nil, nil
+	}
+	for _, d := range vetoed {
+		log.Printf("🛡️  Risk agent %s vetoed %s %s", config.VetoAgent, d.Symbol, d.Action)
+	}
+	if len(kept) == 0 {
+		return &decision.FullDecision{
+			Decisions: []decision.Decision{
+				{
+					Symbol:    "ALL",
+					Action:    "wait",
+					Reasoning: fmt.Sprintf("Risk agent %s vetoed every proposed open", config.VetoAgent),
+				},
+			},
+			CoTTrace:   proposed.CoTTrace,
+			UserPrompt: proposed.UserPrompt,
+		}, vetoed, nil
+	}
+
+	final := *proposed
+	final.Decisions = kept
+	final.RawResponse = fmt.Sprintf("Veto consensus from %d agents (%d vetoed)", len(results), len(vetoed))
+	return &final, vetoed, nil
+}
+
+// applyVeto splits decisions into the ones the risk agent lets through and the opens it vetoes: every open when
+// it did not answer or proposed waiting on ALL symbols, else opens on symbols where it proposed something else
+func applyVeto(results []AgentResult, decisions []decision.Decision, vetoAgent string) (kept, vetoed []decision.Decision) {
+	var risk *decision.FullDecision
+	for _, r := range results {
+		if r.AgentID == vetoAgent && r.Err == nil {
+			risk = r.Decision
+		}
+	}
+
+	vetoAll := risk == nil
+	riskActions := make(map[string][]string)
+	if risk != nil {
+		for _, d := range risk.Decisions {
+			if d.Symbol == "ALL" && d.Action == "wait" {
+				vetoAll = true
+			}
+			riskActions[d.Symbol] = append(riskActions[d.Symbol], d.Action)
+		}
+	}
+
+	for _, d := range decisions {
+		if !strings.HasPrefix(d.Action, "open_") {
+			kept = append(kept, d) // Closes and amendments are never vetoed
+			continue
+		}
+		actions, mentioned := riskActions[d.Symbol]
+		if vetoAll || (mentioned && !slices.Contains(actions, d.Action)) {
+			vetoed = append(vetoed, d)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	return kept, vetoed
+}
+
 // unanimousConsensus requires all agents to agree
 func unanimousConsensus(results []AgentResult, config *MultiAgentConfig) (*decision.FullDecision, error) {
 	log.Printf("🤝 Applying unanimous consensus (all %d agents must agree)", len(results))
@@ -278,11 +368,11 @@ func bestConsensus(results []AgentResult, config *MultiAgentConfig) (*decision.F
 	// Prioritize any action over "wait", even if confidence is 0
 	for _, result := range results {
 		for _, d := range result.Decision.Decisions {
-			// Prioritize actual trades over "wait" (include close actions)
+			// Prioritize actual trades over "wait" (include close and amend actions)
 			isTrade := d.Action != "wait" && d.Symbol != "ALL" &&
-				(d.Action == "open_long" || d.Action == "open_short" ||
+				(decision.IsEntryAction(d.Action) ||
 					d.Action == "close_long" || d.Action == "close_short" ||
-					d.Action == "hold")
+					decision.IsAmendAction(d.Action) || d.Action == "hold")
 			// Accept trades even with confidence 0 (close actions often don't have confidence)
 			if isTrade {
 				// If this is a trade and has higher confidence, or if we haven't found a trade yet
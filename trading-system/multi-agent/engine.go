@@ -9,34 +9,23 @@ import (
 	"time"
 )
 
-// GetMultiAgentDecision gets trading decision from multiple agents using consensus
-func GetMultiAgentDecision(ctx *decision.Context, config *MultiAgentConfig) (*decision.FullDecision, error) {
+// GetMultiAgentDecision gets trading decision from multiple agents using consensus, with the transcript of
+// what each agent proposed (also returned on error once the agents were called)
+func GetMultiAgentDecision(ctx *decision.Context, config *MultiAgentConfig) (*decision.FullDecision, *Transcript, error) {
 	if !config.Enabled || len(config.Agents) == 0 {
-		return nil, fmt.Errorf("multi-agent not enabled or no agents configured")
+		return nil, nil, fmt.Errorf("multi-agent not enabled or no agents configured")
 	}
+	transcript := newTranscript(config)
 
 	log.Printf("🤖 [Multi-Agent] Starting decision with %d agents (mode: %s)", len(config.Agents), config.ConsensusMode)
 
 	// 1. Create MCP clients for each agent
 	clients := make([]*mcp.Client, len(config.Agents))
 	for i, agent := range config.Agents {
-		client := mcp.New()
-
-		switch agent.Model {
-		case "groq":
-			client.SetGroqAPIKey(agent.APIKey, agent.GroqModel)
-		case "qwen":
-			// Qwen via Groq or direct - for now assume Groq
-			client.SetGroqAPIKey(agent.APIKey, agent.GroqModel)
-		case "deepseek":
-			client.SetDeepSeekAPIKey(agent.APIKey)
-		case "custom":
-			// Would need custom API URL - skip for now
-			log.Printf("⚠️  Agent %s: Custom API not fully supported yet", agent.ID)
-			continue
+		clients[i] = createAgentClient(agent)
+		if clients[i] == nil {
+			log.Printf("⚠️  Agent %s: unsupported provider '%s' - skipped", agent.ID, agent.Provider)
 		}
-
-		clients[i] = client
 	}
 
 	// 2. Call all agents in parallel
@@ -50,6 +39,7 @@ func GetMultiAgentDecision(ctx *decision.Context, config *MultiAgentConfig) (*de
 			continue // Skip invalid clients
 		}
 
+		transcript.Proposals[i].Status = AgentLate // Until it answers
 		wg.Add(1)
 		go func(idx int, c *mcp.Client, agentID string) {
 			defer wg.Done()
@@ -68,6 +58,7 @@ func GetMultiAgentDecision(ctx *decision.Context, config *MultiAgentConfig) (*de
 				Err:      err,
 				AgentID:  agentID,
 				AgentIdx: idx,
+				Duration: agentDuration,
 			}
 		}(i, client, config.Agents[i].ID)
 	}
@@ -92,6 +83,7 @@ func GetMultiAgentDecision(ctx *decision.Context, config *MultiAgentConfig) (*de
 				done = true
 				break
 			}
+			transcript.record(result)
 			if result.Err == nil && result.Decision != nil {
 				results = append(results, result)
 				log.Printf("📥 Received decision from agent %s (%d/%d)",
@@ -118,31 +110,68 @@ func GetMultiAgentDecision(ctx *decision.Context, config *MultiAgentConfig) (*de
 		totalDuration.Seconds(), len(results), len(config.Agents))
 
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no agents returned valid decisions")
+		transcript.Resolution = "no agents returned valid decisions"
+		return nil, transcript, fmt.Errorf("no agents returned valid decisions")
 	}
 
-	// 4. Apply consensus logic
-	finalDecision, err := ApplyConsensus(results, config)
+	// 4. Apply consensus logic (the chair makes its own AI call)
+	var finalDecision *decision.FullDecision
+	var vetoed []decision.Decision
+	var err error
+	switch config.ConsensusMode {
+	case "chair":
+		finalDecision, err = chairConsensus(ctx, results, config, transcript)
+	case "veto":
+		finalDecision, vetoed, err = vetoConsensus(results, config)
+	default:
+		finalDecision, err = ApplyConsensus(results, config)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("consensus failed: %w", err)
+		transcript.Resolution = "consensus failed: " + err.Error()
+		return nil, transcript, fmt.Errorf("consensus failed: %w", err)
+	}
+	transcript.resolve(results, finalDecision, vetoed, config)
+
+	// Usage of the agents whose decisions were collected (late fast-first responses are not counted)
+	var usage mcp.Usage
+	if config.ConsensusMode == "chair" && finalDecision.Usage != nil {
+		usage.Add(*finalDecision.Usage) // The chair's own call
+	}
+	for _, result := range results {
+		if result.Decision.Usage != nil {
+			usage.Add(*result.Decision.Usage)
+		}
+	}
+	if usage.Calls > 0 {
+		finalDecision.Usage = &usage
 	}
 
 	log.Printf("✅ Consensus reached: %d decisions merged", len(finalDecision.Decisions))
-	return finalDecision, nil
+	return finalDecision, transcript, nil
 }
 
-// createAgentClient creates an MCP client for an agent (helper function)
+// createAgentClient creates an MCP client for an agent's provider and model (nil = unsupported provider)
 func createAgentClient(agent AgentConfig) *mcp.Client {
 	client := mcp.New()
 
-	switch agent.Model {
+	switch agent.Provider {
 	case "groq":
-		client.SetGroqAPIKey(agent.APIKey, agent.GroqModel)
+		client.SetGroqAPIKey(agent.APIKey, agent.Model)
 	case "qwen":
-		// For now, use Groq with Qwen model
-		client.SetGroqAPIKey(agent.APIKey, agent.GroqModel)
+		client.SetQwenAPIKey(agent.APIKey, "")
 	case "deepseek":
 		client.SetDeepSeekAPIKey(agent.APIKey)
+	case "anthropic":
+		client.SetAnthropicAPIKey(agent.APIKey, agent.Model)
+	case "gemini":
+		client.SetGeminiAPIKey(agent.APIKey, agent.Model)
+	case "custom":
+		client.SetCustomAPI(agent.APIURL, agent.APIKey, agent.Model)
+	default:
+		return nil
+	}
+	if agent.Model != "" {
+		client.Model = agent.Model // Qwen and DeepSeek setters pick their default model
 	}
 
 	return client
@@ -172,6 +201,10 @@ func cloneContext(original *decision.Context) *decision.Context {
 		Performance:     original.Performance, // Interface, shared is fine (read-only)
 		BTCETHLeverage:  original.BTCETHLeverage,
 		AltcoinLeverage: original.AltcoinLeverage,
+		SymbolThrottles: original.SymbolThrottles, // Read-only snapshot
+		CoinPoolNotes:   original.CoinPoolNotes,   // Read-only
+		PoolAdjustments: original.PoolAdjustments, // Read-only
+		Memory:          original.Memory,          // Safe for concurrent use (retrieval cached per cycle)
 	}
 
 	return cloned
@@ -0,0 +1,265 @@
+package multiagent
+
+import (
+	"fmt"
+	"lia/decision"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Agent statuses in a transcript
+const (
+	AgentProposed = "proposed" // Decisions collected before consensus
+	AgentFailed   = "error"    // The agent's AI call failed
+	AgentLate     = "late"     // No answer before consensus (fast-first or max_wait_time)
+	AgentSkipped  = "skipped"  // No client for the agent's provider
+	AgentChair    = "chair"    // The chair's own decisions (chair mode)
+)
+
+// AgentProposal what one agent proposed in a cycle
+type AgentProposal struct {
+	AgentID   string
+	Name      string
+	Provider  string
+	Model     string
+	Role      string
+	Weight    float64 // Normalized share of the committee weight (weighted and sharpe modes), else 0
+	Status    string
+	Error     string
+	Duration  time.Duration
+	CoTTrace  string
+	Decisions []decision.Decision
+}
+
+// ConsensusVote the agents that proposed the same symbol+action
+type ConsensusVote struct {
+	Symbol   string
+	Action   string
+	Agents   []string
+	Votes    int
+	Share    float64 // Share of the proposing agents (weighted and sharpe modes: share of the committee weight)
+	Accepted bool    // Part of the final decisions
+}
+
+// Transcript how the committee reached a cycle's decisions
+type Transcript struct {
+	ConsensusMode string
+	Proposals     []AgentProposal // In config order
+	Votes         []ConsensusVote
+	Resolution    string
+}
+
+// newTranscript every configured agent, skipped until it is started
+func newTranscript(config *MultiAgentConfig) *Transcript {
+	t := &Transcript{ConsensusMode: config.ConsensusMode, Proposals: make([]AgentProposal, len(config.Agents))}
+	weights := consensusWeights(config)
+	for i, agent := range config.Agents {
+		t.Proposals[i] = AgentProposal{
+			AgentID:  agent.ID,
+			Name:     agent.Name,
+			Provider: agent.Provider,
+			Model:    agent.Model,
+			Role:     agent.Role,
+			Status:   AgentSkipped,
+		}
+		if isWeighted(config) {
+			t.Proposals[i].Weight = weights[agent.ID]
+		}
+	}
+	return t
+}
+
+// isWeighted reports whether votes count by agent weight
+func isWeighted(config *MultiAgentConfig) bool {
+	return config.ConsensusMode == "weighted" || config.ConsensusMode == "sharpe"
+}
+
+// consensusWeights normalized agent weights of the consensus mode: historical Sharpe ratios in sharpe mode,
+// the configured weights otherwise
+func consensusWeights(config *MultiAgentConfig) map[string]float64 {
+	if config.ConsensusMode == "sharpe" {
+		if weights := sharpeWeights(config); weights != nil {
+			return weights
+		}
+	}
+	return agentWeights(config)
+}
+
+// sharpeWeights weights agents by their Sharpe ratio (negative = 0); agents without enough closed trades get
+// the average of the rated ones (nil = no agent rated, or none with a positive Sharpe)
+func sharpeWeights(config *MultiAgentConfig) map[string]float64 {
+	rated, sum := 0, 0.0
+	for _, agent := range config.Agents {
+		if sharpe, ok := config.AgentSharpe[agent.ID]; ok {
+			rated++
+			sum += max(sharpe, 0)
+		}
+	}
+	if rated == 0 || sum == 0 {
+		return nil
+	}
+
+	neutral := sum / float64(rated)
+	weights := make(map[string]float64)
+	total := 0.0
+	for _, agent := range config.Agents {
+		weight := neutral
+		if sharpe, ok := config.AgentSharpe[agent.ID]; ok {
+			weight = max(sharpe, 0)
+		}
+		weights[agent.ID] = weight
+		total += weight
+	}
+	for id := range weights {
+		weights[id] /= total
+	}
+	return weights
+}
+
+// agentWeights normalized agent weights, as weightedConsensus counts them
+func agentWeights(config *MultiAgentConfig) map[string]float64 {
+	weights := make(map[string]float64)
+	total := 0.0
+	for _, agent := range config.Agents {
+		weight := agent.Weight
+		if weight == 0 {
+			weight = 1.0 / float64(len(config.Agents))
+		}
+		weights[agent.ID] = weight
+		total += weight
+	}
+	for id := range weights {
+		weights[id] /= total
+	}
+	return weights
+}
+
+// record stores an agent's answer (started agents stay late until they answer)
+func (t *Transcript) record(result AgentResult) {
+	fillProposal(&t.Proposals[result.AgentIdx], result)
+}
+
+// addChair appends the chair's decisions after the agents' proposals
+func (t *Transcript) addChair(chair *AgentConfig, result AgentResult) {
+	p := AgentProposal{AgentID: chair.ID, Name: chair.Name, Provider: chair.Provider, Model: chair.Model, Role: "chair"}
+	fillProposal(&p, result)
+	if p.Status == AgentProposed {
+		p.Status = AgentChair
+	}
+	t.Proposals = append(t.Proposals, p)
+}
+
+// fillProposal stores an answer in p
+func fillProposal(p *AgentProposal, result AgentResult) {
+	p.Duration = result.Duration
+	switch {
+	case result.Err != nil:
+		p.Status = AgentFailed
+		p.Error = result.Err.Error()
+	case result.Decision == nil:
+		p.Status = AgentFailed
+		p.Error = "no decision returned"
+	default:
+		p.Status = AgentProposed
+	}
+	if result.Decision != nil {
+		p.CoTTrace = result.Decision.CoTTrace
+		p.Decisions = result.Decision.Decisions
+	}
+}
+
+// resolve tallies the collected proposals and describes how final was reached (vetoed: opens the risk agent
+// blocked in veto mode)
+func (t *Transcript) resolve(results []AgentResult, final *decision.FullDecision, vetoed []decision.Decision, config *MultiAgentConfig) {
+	type voteKey struct {
+		symbol string
+		action string
+	}
+	accepted := make(map[voteKey]bool)
+	for _, d := range final.Decisions {
+		accepted[voteKey{d.Symbol, d.Action}] = true
+	}
+
+	weights := consensusWeights(config)
+	proposing := 0
+	index := make(map[voteKey]int)
+	for _, result := range results {
+		if len(result.Decision.Decisions) == 0 {
+			continue
+		}
+		proposing++
+		seen := make(map[voteKey]bool)
+		for _, d := range result.Decision.Decisions {
+			key := voteKey{d.Symbol, d.Action}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			i, ok := index[key]
+			if !ok {
+				i = len(t.Votes)
+				index[key] = i
+				t.Votes = append(t.Votes, ConsensusVote{Symbol: d.Symbol, Action: d.Action, Accepted: accepted[key]})
+			}
+			t.Votes[i].Agents = append(t.Votes[i].Agents, result.AgentID)
+			t.Votes[i].Votes++
+			if isWeighted(config) {
+				t.Votes[i].Share += weights[result.AgentID]
+			}
+		}
+	}
+	for i := range t.Votes {
+		if !isWeighted(config) {
+			t.Votes[i].Share = float64(t.Votes[i].Votes) / float64(proposing)
+		}
+	}
+	sort.SliceStable(t.Votes, func(i, j int) bool { return t.Votes[i].Votes > t.Votes[j].Votes })
+
+	switch config.ConsensusMode {
+	case "chair":
+		t.Resolution = fmt.Sprintf("chair: %s decided %d decision(s) from %d proposals", config.Chair.ID, len(final.Decisions), proposing)
+	case "veto":
+		if len(vetoed) > 0 {
+			passed := fmt.Sprintf("%d decision(s) passed", len(final.Decisions))
+			if isFallbackWait(final) {
+				passed = "nothing passed"
+			}
+			t.Resolution = fmt.Sprintf("veto: %s; risk agent %s vetoed %s", passed, config.VetoAgent, describeDecisions(vetoed))
+			return
+		}
+		fallthrough
+	default:
+		t.Resolution = describeResolution(results, final, proposing, t.ConsensusMode)
+	}
+}
+
+// isFallbackWait reports whether final is the single "ALL wait" decision consensus falls back to
+func isFallbackWait(final *decision.FullDecision) bool {
+	return len(final.Decisions) == 1 && final.Decisions[0].Symbol == "ALL" && final.Decisions[0].Action == "wait"
+}
+
+// describeDecisions "BTCUSDT open_long, ETHUSDT open_short"
+func describeDecisions(decisions []decision.Decision) string {
+	parts := make([]string, len(decisions))
+	for i, d := range decisions {
+		parts[i] = d.Symbol + " " + d.Action
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeResolution one line on how ApplyConsensus arrived at final
+func describeResolution(results []AgentResult, final *decision.FullDecision, proposing int, mode string) string {
+	for _, result := range results {
+		if result.Decision == final {
+			if proposing == 1 {
+				return fmt.Sprintf("%s: only agent %s proposed decisions", mode, result.AgentID)
+			}
+			return fmt.Sprintf("%s: adopted agent %s's decisions", mode, result.AgentID)
+		}
+	}
+	if isFallbackWait(final) {
+		return fmt.Sprintf("%s: %s", mode, final.Decisions[0].Reasoning)
+	}
+	return fmt.Sprintf("%s: %d decision(s) reached the threshold across %d agents", mode, len(final.Decisions), proposing)
+}
@@ -0,0 +1,247 @@
+package news
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headline a news item from a source or the ingest endpoint
+type Headline struct {
+	ID          string    `json:"id"` // Dedup key: the URL, else source + title
+	Title       string    `json:"title"`
+	Source      string    `json:"source"`
+	URL         string    `json:"url,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	Symbols     []string  `json:"symbols,omitempty"`   // Base assets the source tagged (e.g. "BTC")
+	Sentiment   string    `json:"sentiment,omitempty"` // "positive", "negative" or "" (unknown / neutral)
+	Important   bool      `json:"important,omitempty"` // Flagged as important by the source
+}
+
+// Source a headline provider polled by the feed
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) ([]Headline, error)
+}
+
+// Options how long headlines are kept and which of them reach a prompt
+type Options struct {
+	MaxAge         time.Duration       // Headlines older than this are dropped
+	MaxPerSymbol   int                 // Headlines shown per symbol
+	MaxMarket      int                 // Market-wide headlines shown
+	MarketKeywords []string            // Words that make a headline market-wide (hacks, ETF decisions, ...)
+	Aliases        map[string][]string // Extra names per base asset, on top of the built-in ones
+}
+
+// maxStored headlines kept in memory (newest first)
+const maxStored = 500
+
+// Process-wide headline store shared by every trader. Disabled until Configure is called: every read is
+// empty, so traders without news build the same prompts as before
+var feed = struct {
+	mu        sync.RWMutex
+	enabled   bool
+	options   Options
+	headlines []Headline // Newest first
+	seen      map[string]bool
+	fetchedAt time.Time
+}{}
+
+// Configure enables the feed with options (sources are polled by Start; Ingest works without them)
+func Configure(options Options) {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	feed.enabled = true
+	feed.options = options
+	if feed.seen == nil {
+		feed.seen = make(map[string]bool)
+	}
+}
+
+// Enabled whether the news feed is configured
+func Enabled() bool {
+	feed.mu.RLock()
+	defer feed.mu.RUnlock()
+	return feed.enabled
+}
+
+// Start fetches every source now and then every interval. Returns a function stopping the polling
+func Start(sources []Source, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			refresh(ctx, sources)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// refresh fetches every source and stores the new headlines
+func refresh(ctx context.Context, sources []Source) {
+	for _, source := range sources {
+		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		headlines, err := source.Fetch(fetchCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("⚠️  News source %s: %v", source.Name(), err)
+			}
+			continue
+		}
+		if added := Ingest(headlines); added > 0 {
+			log.Printf("📰 News source %s: %d new headlines", source.Name(), added)
+		}
+	}
+	feed.mu.Lock()
+	feed.fetchedAt = time.Now()
+	feed.mu.Unlock()
+}
+
+// Ingest stores headlines not seen before and younger than the max age. Returns how many were added
+func Ingest(headlines []Headline) int {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	if !feed.enabled {
+		return 0
+	}
+
+	cutoff := time.Now().Add(-feed.options.MaxAge)
+	added := 0
+	for _, h := range headlines {
+		h.Title = strings.Join(strings.Fields(h.Title), " ")
+		if h.PublishedAt.IsZero() || h.PublishedAt.After(time.Now()) {
+			h.PublishedAt = time.Now()
+		}
+		if h.Title == "" || h.PublishedAt.Before(cutoff) {
+			continue
+		}
+		if h.ID == "" {
+			h.ID = h.URL
+		}
+		if h.ID == "" {
+			h.ID = h.Source + "|" + h.Title
+		}
+		if feed.seen[h.ID] {
+			continue
+		}
+		symbols := make([]string, len(h.Symbols))
+		for i, symbol := range h.Symbols {
+			symbols[i] = strings.ToUpper(symbol)
+		}
+		h.Symbols = symbols
+		feed.seen[h.ID] = true
+		feed.headlines = append(feed.headlines, h)
+		added++
+	}
+	if added > 0 {
+		sort.SliceStable(feed.headlines, func(i, j int) bool {
+			return feed.headlines[i].PublishedAt.After(feed.headlines[j].PublishedAt)
+		})
+	}
+	pruneLocked(cutoff)
+	return added
+}
+
+// pruneLocked drops headlines older than cutoff and beyond maxStored (feed.mu held)
+func pruneLocked(cutoff time.Time) {
+	kept := feed.headlines[:0]
+	for _, h := range feed.headlines {
+		if h.PublishedAt.Before(cutoff) || len(kept) >= maxStored {
+			delete(feed.seen, h.ID)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	feed.headlines = kept
+}
+
+// current the stored headlines within the max age, newest first
+func current() ([]Headline, Options) {
+	feed.mu.RLock()
+	defer feed.mu.RUnlock()
+	if !feed.enabled {
+		return nil, Options{}
+	}
+	cutoff := time.Now().Add(-feed.options.MaxAge)
+	var headlines []Headline
+	for _, h := range feed.headlines {
+		if h.PublishedAt.Before(cutoff) {
+			break
+		}
+		headlines = append(headlines, h)
+	}
+	return headlines, feed.options
+}
+
+// ForSymbols the most recent relevant headlines of each symbol (e.g. "SOLUSDT"), at most MaxPerSymbol each.
+// Symbols without news are left out
+func ForSymbols(symbols []string) map[string][]Headline {
+	headlines, options := current()
+	if len(headlines) == 0 {
+		return nil
+	}
+	result := make(map[string][]Headline)
+	for _, symbol := range symbols {
+		if _, done := result[symbol]; done {
+			continue
+		}
+		base := BaseAsset(symbol)
+		for _, h := range headlines {
+			if len(result[symbol]) >= options.MaxPerSymbol {
+				break
+			}
+			if mentions(h, base, options.Aliases) {
+				result[symbol] = append(result[symbol], h)
+			}
+		}
+	}
+	return result
+}
+
+// Market the most recent market-wide headlines (matching a market keyword), at most MaxMarket
+func Market() []Headline {
+	headlines, options := current()
+	var result []Headline
+	for _, h := range headlines {
+		if len(result) >= options.MaxMarket {
+			break
+		}
+		if matchesAny(h.Title, options.MarketKeywords) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// Recent the stored headlines, newest first, optionally only those relevant to symbol ("" = all)
+func Recent(symbol string, limit int) []Headline {
+	headlines, options := current()
+	var result []Headline
+	base := BaseAsset(symbol)
+	for _, h := range headlines {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		if symbol == "" || mentions(h, base, options.Aliases) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// LastFetch when the sources were last polled (zero = not yet)
+func LastFetch() time.Time {
+	feed.mu.RLock()
+	defer feed.mu.RUnlock()
+	return feed.fetchedAt
+}
@@ -0,0 +1,114 @@
+package news
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// quoteAssets suffixes stripped from a trading pair to get its base asset
+var quoteAssets = []string{"USDT", "USDC", "BUSD", "FDUSD", "USD"}
+
+// builtinAliases names headlines use for the base assets instead of the ticker
+var builtinAliases = map[string][]string{
+	"BTC":  {"Bitcoin"},
+	"ETH":  {"Ethereum", "Ether"},
+	"SOL":  {"Solana"},
+	"XRP":  {"Ripple"},
+	"BNB":  {"BNB Chain"},
+	"DOGE": {"Dogecoin"},
+	"ADA":  {"Cardano"},
+	"AVAX": {"Avalanche"},
+	"LINK": {"Chainlink"},
+	"DOT":  {"Polkadot"},
+	"LTC":  {"Litecoin"},
+	"TRX":  {"Tron"},
+	"TON":  {"Toncoin"},
+	"SHIB": {"Shiba Inu"},
+	"ARB":  {"Arbitrum"},
+	"OP":   {"Optimism"},
+	"HYPE": {"Hyperliquid"},
+}
+
+// BaseAsset the base asset of a trading pair ("SOLUSDT" → "SOL", "1000PEPEUSDT" → "PEPE")
+func BaseAsset(symbol string) string {
+	base := strings.ToUpper(symbol)
+	for _, quote := range quoteAssets {
+		if trimmed, ok := strings.CutSuffix(base, quote); ok && trimmed != "" {
+			base = trimmed
+			break
+		}
+	}
+	if trimmed := strings.TrimLeft(base, "0123456789"); trimmed != "" {
+		base = trimmed
+	}
+	return base
+}
+
+// mentions reports whether a headline is about base: tagged by its source, the ticker in capitals as a word
+// (lowercase tickers like "op" or "near" are ordinary words), or one of the asset's names in any case
+func mentions(h Headline, base string, aliases map[string][]string) bool {
+	for _, symbol := range h.Symbols {
+		if symbol == base {
+			return true
+		}
+	}
+	if containsWord(h.Title, base, true) {
+		return true
+	}
+	for _, name := range builtinAliases[base] {
+		if containsWord(h.Title, name, false) {
+			return true
+		}
+	}
+	for _, name := range aliases[base] {
+		if containsWord(h.Title, name, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether text contains one of the keywords as a word (any case)
+func matchesAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if containsWord(text, keyword, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether word occurs in text between non-alphanumeric characters
+func containsWord(text, word string, caseSensitive bool) bool {
+	if word == "" {
+		return false
+	}
+	if !caseSensitive {
+		text, word = strings.ToLower(text), strings.ToLower(word)
+	}
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		if !isWordRune(text[:start], true) && !isWordRune(text[end:], false) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isWordRune reports whether the rune next to a match (the last of before, or the first of after) is a letter
+// or digit
+func isWordRune(s string, last bool) bool {
+	if s == "" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	if last {
+		r, _ = utf8.DecodeLastRuneInString(s)
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,213 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpClient fetches every source
+var httpClient = &http.Client{Timeout: 20 * time.Second}
+
+// DefaultCryptoPanicURL posts endpoint of the CryptoPanic API
+const DefaultCryptoPanicURL = "https://cryptopanic.com/api/v1/posts/"
+
+// CryptoPanic latest news posts of the CryptoPanic API, tagged with currencies and community votes
+type CryptoPanic struct {
+	name      string
+	endpoint  string
+	authToken string
+}
+
+// NewCryptoPanic a CryptoPanic source ("" endpoint = DefaultCryptoPanicURL)
+func NewCryptoPanic(name, endpoint, authToken string) *CryptoPanic {
+	if name == "" {
+		name = "cryptopanic"
+	}
+	if endpoint == "" {
+		endpoint = DefaultCryptoPanicURL
+	}
+	return &CryptoPanic{name: name, endpoint: endpoint, authToken: authToken}
+}
+
+// Name the source's name in logs
+func (c *CryptoPanic) Name() string { return c.name }
+
+// Fetch the latest public news posts
+func (c *CryptoPanic) Fetch(ctx context.Context) ([]Headline, error) {
+	query := url.Values{}
+	query.Set("auth_token", c.authToken)
+	query.Set("public", "true")
+	query.Set("kind", "news")
+	body, err := get(ctx, c.endpoint+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Results []struct {
+			ID          json.Number `json:"id"`
+			Title       string      `json:"title"`
+			URL         string      `json:"url"`
+			PublishedAt time.Time   `json:"published_at"`
+			Source      struct {
+				Title string `json:"title"`
+			} `json:"source"`
+			Currencies []struct {
+				Code string `json:"code"`
+			} `json:"currencies"`
+			Votes struct {
+				Positive  int `json:"positive"`
+				Negative  int `json:"negative"`
+				Important int `json:"important"`
+			} `json:"votes"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse CryptoPanic response: %w", err)
+	}
+
+	headlines := make([]Headline, 0, len(response.Results))
+	for _, post := range response.Results {
+		h := Headline{
+			ID:          "cryptopanic:" + post.ID.String(),
+			Title:       post.Title,
+			Source:      post.Source.Title,
+			URL:         post.URL,
+			PublishedAt: post.PublishedAt,
+			Important:   post.Votes.Important > 0,
+		}
+		if h.Source == "" {
+			h.Source = c.name
+		}
+		for _, currency := range post.Currencies {
+			h.Symbols = append(h.Symbols, currency.Code)
+		}
+		switch {
+		case post.Votes.Positive > post.Votes.Negative:
+			h.Sentiment = "positive"
+		case post.Votes.Negative > post.Votes.Positive:
+			h.Sentiment = "negative"
+		}
+		headlines = append(headlines, h)
+	}
+	return headlines, nil
+}
+
+// RSS items of an RSS 2.0 or Atom feed
+type RSS struct {
+	name string
+	url  string
+}
+
+// NewRSS an RSS / Atom feed source ("" name = the feed's host)
+func NewRSS(name, feedURL string) *RSS {
+	if name == "" {
+		if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+			name = strings.TrimPrefix(u.Host, "www.")
+		} else {
+			name = feedURL
+		}
+	}
+	return &RSS{name: name, url: feedURL}
+}
+
+// Name the source's name in logs
+func (r *RSS) Name() string { return r.name }
+
+// Fetch the feed's items
+func (r *RSS) Fetch(ctx context.Context) ([]Headline, error) {
+	body, err := get(ctx, r.url)
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		Items []struct {
+			Title   string `xml:"title"`
+			Link    string `xml:"link"`
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"channel>item"`
+		Entries []struct {
+			Title string `xml:"title"`
+			ID    string `xml:"id"`
+			Link  struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var headlines []Headline
+	for _, item := range document.Items {
+		id := item.GUID
+		if id == "" {
+			id = item.Link
+		}
+		headlines = append(headlines, Headline{ID: id, Title: item.Title, Source: r.name, URL: item.Link, PublishedAt: parseFeedTime(item.PubDate)})
+	}
+	for _, entry := range document.Entries {
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		id := entry.ID
+		if id == "" {
+			id = entry.Link.Href
+		}
+		headlines = append(headlines, Headline{ID: id, Title: entry.Title, Source: r.name, URL: entry.Link.Href, PublishedAt: parseFeedTime(published)})
+	}
+	return headlines, nil
+}
+
+// feedTimeLayouts date formats seen in RSS and Atom feeds
+var feedTimeLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02 15:04:05"}
+
+// parseFeedTime a feed date (zero when no layout matches: Ingest stamps it with the time it was seen)
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// get the body of a successful GET
+func get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "lia-news/1.0")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err // Without the URL, which may carry an API token
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), 200)])))
+	}
+	return body, nil
+}
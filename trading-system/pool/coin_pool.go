@@ -96,15 +96,22 @@ func GetCoinPool() ([]CoinInfo, error) {
 	// 优先检查是否启用默认币种列表
 	if coinPoolConfig.UseDefaultCoins {
 		log.Printf("✓ Default mainstream coin list enabled")
+		recordSourceStatus("ai500", SourceModeDisabled, time.Time{}, nil)
 		return convertSymbolsToCoins(defaultMainstreamCoins), nil
 	}
 
 	// 检查API URL是否配置
 	if strings.TrimSpace(coinPoolConfig.APIURL) == "" {
 		log.Printf("⚠️  Coin pool API URL not configured, using default mainstream coin list")
+		recordSourceStatus("ai500", SourceModeDisabled, time.Time{}, nil)
 		return convertSymbolsToCoins(defaultMainstreamCoins), nil
 	}
 
+	// Reuse a fresh live fetch (warmup / traders starting together)
+	if coins, ok := cachedLiveCoinPool(); ok {
+		return coins, nil
+	}
+
 	maxRetries := 3
 	var lastErr error
 
@@ -124,6 +131,8 @@ func GetCoinPool() ([]CoinInfo, error) {
 			if err := saveCoinPoolCache(coins); err != nil {
 				log.Printf("⚠️  Failed to save coin pool cache: %v", err)
 			}
+			recordSourceStatus("ai500", SourceModeLive, time.Now(), nil)
+			storeLiveCoinPool(coins)
 			return coins, nil
 		}
 
@@ -133,14 +142,16 @@ func GetCoinPool() ([]CoinInfo, error) {
 
 	// API获取失败，尝试使用缓存
 	log.Printf("⚠️  All API requests failed, trying to use cached data...")
-	cachedCoins, err := loadCoinPoolCache()
+	cachedCoins, fetchedAt, err := loadCoinPoolCache()
 	if err == nil {
 		log.Printf("✓ Using cached data (%d coins)", len(cachedCoins))
+		recordSourceStatus("ai500", SourceModeSnapshot, fetchedAt, lastErr)
 		return cachedCoins, nil
 	}
 
 	// Cache also failed, use default mainstream coins
 	log.Printf("⚠️  Unable to load cache data (last error: %v), using default mainstream coin list", lastErr)
+	recordSourceStatus("ai500", SourceModeDefault, time.Time{}, lastErr)
 	return convertSymbolsToCoins(defaultMainstreamCoins), nil
 }
 
@@ -218,23 +229,31 @@ func saveCoinPoolCache(coins []CoinInfo) error {
 	return nil
 }
 
-// loadCoinPoolCache loads coin pool from cache file
-func loadCoinPoolCache() ([]CoinInfo, error) {
+// loadCoinPoolCache loads coin pool from cache file (returns when the snapshot was fetched)
+func loadCoinPoolCache() ([]CoinInfo, time.Time, error) {
 	cachePath := filepath.Join(coinPoolConfig.CacheDir, "latest.json")
 
 	// Check if file exists
 	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("cache file does not exist")
+		return nil, time.Time{}, fmt.Errorf("cache file does not exist")
 	}
 
 	data, err := ioutil.ReadFile(cachePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to read cache file: %w", err)
 	}
 
 	var cache CoinPoolCache
 	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, fmt.Errorf("failed to parse cache data: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to parse cache data: %w", err)
+	}
+	if len(cache.Coins) == 0 {
+		return nil, time.Time{}, fmt.Errorf("cache file has no coins")
+	}
+
+	// IsAvailable is not persisted - every cached coin came from a successful fetch
+	for i := range cache.Coins {
+		cache.Coins[i].IsAvailable = true
 	}
 
 	// Check cache age
@@ -247,7 +266,7 @@ func loadCoinPoolCache() ([]CoinInfo, error) {
 			cacheAge.Minutes())
 	}
 
-	return cache.Coins, nil
+	return cache.Coins, cache.FetchedAt, nil
 }
 
 // GetAvailableCoins gets available coin list (filtered unavailable ones)
@@ -279,7 +298,11 @@ func GetTopRatedCoins(limit int) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return topRatedSymbols(coins, limit)
+}
 
+// topRatedSymbols picks the top N available coins by score
+func topRatedSymbols(coins []CoinInfo, limit int) ([]string, error) {
 	// Filter available coins
 	var availableCoins []CoinInfo
 	for _, coin := range coins {
@@ -423,9 +446,15 @@ func GetOITopPositions() ([]OIPosition, error) {
 	// 检查API URL是否配置
 	if strings.TrimSpace(oiTopConfig.APIURL) == "" {
 		log.Printf("⚠️  OI Top API URL not configured, skipping OI Top data fetch")
+		recordSourceStatus("oi_top", SourceModeDisabled, time.Time{}, nil)
 		return []OIPosition{}, nil // 返回空列表，不是错误
 	}
 
+	// Reuse a fresh live fetch (warmup / traders starting together)
+	if positions, ok := cachedLiveOITop(); ok {
+		return positions, nil
+	}
+
 	maxRetries := 3
 	var lastErr error
 
@@ -445,6 +474,8 @@ func GetOITopPositions() ([]OIPosition, error) {
 			if err := saveOITopCache(positions); err != nil {
 				log.Printf("⚠️  保存OI Top缓存失败: %v", err)
 			}
+			recordSourceStatus("oi_top", SourceModeLive, time.Now(), nil)
+			storeLiveOITop(positions)
 			return positions, nil
 		}
 
@@ -454,14 +485,16 @@ func GetOITopPositions() ([]OIPosition, error) {
 
 	// API获取失败，尝试使用缓存
 	log.Printf("⚠️  OI Top API请求全部失败，尝试使用历史缓存数据...")
-	cachedPositions, err := loadOITopCache()
+	cachedPositions, fetchedAt, err := loadOITopCache()
 	if err == nil {
 		log.Printf("✓ 使用历史OI Top缓存数据（共%d个币种）", len(cachedPositions))
+		recordSourceStatus("oi_top", SourceModeSnapshot, fetchedAt, lastErr)
 		return cachedPositions, nil
 	}
 
 	// 缓存也失败，返回空列表（OI Top是可选的）
 	log.Printf("⚠️  无法加载OI Top缓存数据（最后错误: %v），跳过OI Top数据", lastErr)
+	recordSourceStatus("oi_top", SourceModeUnavailable, time.Time{}, lastErr)
 	return []OIPosition{}, nil
 }
 
@@ -533,22 +566,22 @@ func saveOITopCache(positions []OIPosition) error {
 	return nil
 }
 
-// loadOITopCache 从缓存加载OI Top数据
-func loadOITopCache() ([]OIPosition, error) {
+// loadOITopCache 从缓存加载OI Top数据（返回快照获取时间）
+func loadOITopCache() ([]OIPosition, time.Time, error) {
 	cachePath := filepath.Join(oiTopConfig.CacheDir, "oi_top_latest.json")
 
 	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("OI Top缓存文件不存在")
+		return nil, time.Time{}, fmt.Errorf("OI Top缓存文件不存在")
 	}
 
 	data, err := ioutil.ReadFile(cachePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取OI Top缓存文件失败: %w", err)
+		return nil, time.Time{}, fmt.Errorf("读取OI Top缓存文件失败: %w", err)
 	}
 
 	var cache OITopCache
 	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, fmt.Errorf("解析OI Top缓存数据失败: %w", err)
+		return nil, time.Time{}, fmt.Errorf("解析OI Top缓存数据失败: %w", err)
 	}
 
 	cacheAge := time.Since(cache.FetchedAt)
@@ -560,7 +593,7 @@ func loadOITopCache() ([]OIPosition, error) {
 			cacheAge.Minutes())
 	}
 
-	return cache.Positions, nil
+	return cache.Positions, cache.FetchedAt, nil
 }
 
 // GetOITopSymbols 获取OI Top的币种符号列表
@@ -569,77 +602,109 @@ func GetOITopSymbols() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return oiTopSymbols(positions), nil
+}
 
+// oiTopSymbols normalizes OI Top position symbols
+func oiTopSymbols(positions []OIPosition) []string {
 	var symbols []string
 	for _, pos := range positions {
 		symbol := normalizeSymbol(pos.Symbol)
 		symbols = append(symbols, symbol)
 	}
-
-	return symbols, nil
+	return symbols
 }
 
-// MergedCoinPool 合并的币种池（AI500 + OI Top）
+// MergedCoinPool 合并的币种池（AI500 + OI Top + custom sources）
 type MergedCoinPool struct {
 	AI500Coins    []CoinInfo          // AI500评分币种
 	OITopCoins    []OIPosition        // 持仓量增长Top20
-	AllSymbols    []string            // 所有不重复的币种符号
-	SymbolSources map[string][]string // 每个币种的来源（"ai500"/"oi_top"）
+	AllSymbols    []string            // 所有不重复的币种符号 (highest score first)
+	SymbolSources map[string][]string // 每个币种的来源（"ai500"/"oi_top"/custom source names）
+	Scores        map[string]float64  // Sum of the weights of each symbol's sources
+	Stale         bool                // At least one source is serving snapshot/fallback data
+	StaleNotes    []string            // Per-source description of the fallback in use (for the AI prompt)
 }
 
 // GetMergedCoinPool 获取合并后的币种池（AI500 + OI Top，去重）
 func GetMergedCoinPool(ai500Limit int) (*MergedCoinPool, error) {
-	// 1. 获取AI500数据
-	ai500TopSymbols, err := GetTopRatedCoins(ai500Limit)
+	// 1. 获取AI500数据（上游失败时回退到磁盘快照）
+	ai500Coins, _ := GetCoinPool()
+	ai500TopSymbols, err := topRatedSymbols(ai500Coins, ai500Limit)
 	if err != nil {
 		log.Printf("⚠️  获取AI500数据失败: %v", err)
 		ai500TopSymbols = []string{} // 失败时用空列表
 	}
 
 	// 2. 获取OI Top数据
-	oiTopSymbols, err := GetOITopSymbols()
-	if err != nil {
-		log.Printf("⚠️  获取OI Top数据失败: %v", err)
-		oiTopSymbols = []string{} // 失败时用空列表
-	}
+	oiTopPositions, _ := GetOITopPositions()
+	oiTopSymbols := oiTopSymbols(oiTopPositions)
 
-	// 3. 合并并去重
-	symbolSet := make(map[string]bool)
+	// 3. 合并并去重 (a symbol listed twice by one source counts once)
+	rules, custom := mergeRules()
 	symbolSources := make(map[string][]string)
-
-	// 添加AI500币种
-	for _, symbol := range ai500TopSymbols {
-		symbolSet[symbol] = true
-		symbolSources[symbol] = append(symbolSources[symbol], "ai500")
-	}
-
-	// 添加OI Top币种
-	for _, symbol := range oiTopSymbols {
-		if !symbolSet[symbol] {
-			symbolSet[symbol] = true
+	weights := make(map[string]float64)
+	add := func(source string, weight float64, symbols []string) {
+		weights[source] = weight
+		for _, symbol := range symbols {
+			if sources := symbolSources[symbol]; len(sources) == 0 || sources[len(sources)-1] != source {
+				symbolSources[symbol] = append(sources, source)
+			}
 		}
-		symbolSources[symbol] = append(symbolSources[symbol], "oi_top")
 	}
-
-	// 转换为数组
-	var allSymbols []string
-	for symbol := range symbolSet {
-		allSymbols = append(allSymbols, symbol)
+	if rules.AI500Weight >= 0 {
+		add("ai500", rules.AI500Weight, ai500TopSymbols)
+	}
+	if rules.OITopWeight >= 0 {
+		add("oi_top", rules.OITopWeight, oiTopSymbols)
+	}
+	customCounts := make([]string, 0, len(custom))
+	for _, source := range custom {
+		symbols := customSourceSymbols(source)
+		add(source.Name, source.Weight, symbols)
+		customCounts = append(customCounts, fmt.Sprintf("%s=%d", source.Name, len(symbols)))
 	}
 
-	// 获取完整数据
-	ai500Coins, _ := GetCoinPool()
-	oiTopPositions, _ := GetOITopPositions()
+	// 按得分排序
+	allSymbols, scores := scoreSymbols(symbolSources, weights, rules)
 
 	merged := &MergedCoinPool{
 		AI500Coins:    ai500Coins,
 		OITopCoins:    oiTopPositions,
 		AllSymbols:    allSymbols,
 		SymbolSources: symbolSources,
+		Scores:        scores,
+	}
+
+	// 标记过期数据源
+	health := GetPoolHealth()
+	var notes []string
+	if rules.AI500Weight >= 0 {
+		notes = append(notes, staleNote("AI500", health.AI500))
+	}
+	if rules.OITopWeight >= 0 {
+		notes = append(notes, staleNote("OI Top", health.OITop))
+	}
+	for _, source := range custom {
+		notes = append(notes, staleNote(source.Name, health.Custom[source.Name]))
+	}
+	for _, note := range notes {
+		if note != "" {
+			merged.Stale = true
+			merged.StaleNotes = append(merged.StaleNotes, note)
+		}
+	}
+	if merged.Stale {
+		log.Printf("⚠️  Coin pool is stale: %s", strings.Join(merged.StaleNotes, "; "))
 	}
 
-	log.Printf("📊 Coin pool merge completed: AI500=%d, OI_Top=%d, Total (deduplicated)=%d",
-		len(ai500TopSymbols), len(oiTopSymbols), len(allSymbols))
+	if len(customCounts) > 0 {
+		log.Printf("📊 Coin pool merge completed: AI500=%d, OI_Top=%d, %s, Total (deduplicated)=%d",
+			len(ai500TopSymbols), len(oiTopSymbols), strings.Join(customCounts, ", "), len(allSymbols))
+	} else {
+		log.Printf("📊 Coin pool merge completed: AI500=%d, OI_Top=%d, Total (deduplicated)=%d",
+			len(ai500TopSymbols), len(oiTopSymbols), len(allSymbols))
+	}
 
 	return merged, nil
 }
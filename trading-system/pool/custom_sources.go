@@ -0,0 +1,316 @@
+package pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Custom coin source types
+const (
+	CustomSourceHTTP   = "http"   // JSON endpoint, symbols selected with a JSONPath
+	CustomSourceStatic = "static" // Fixed symbol list from the config
+)
+
+// CustomSource a user-declared coin source merged into the pool next to AI500 and OI Top
+type CustomSource struct {
+	Name        string
+	Type        string            // CustomSourceHTTP or CustomSourceStatic
+	URL         string            // HTTP: the JSON endpoint
+	SymbolsPath string            // HTTP: JSONPath of the symbols in the response, e.g. "$.data.coins[*].pair"
+	Headers     map[string]string // HTTP: request headers (API keys)
+	Symbols     []string          // Static: the symbols
+	Weight      float64           // Score a listed symbol gets from this source
+	Limit       int               // First symbols taken from the source (0 = all)
+	CacheTTL    time.Duration     // HTTP: how long a fetch is reused
+}
+
+// MergeRules how the sources are combined. A symbol's score is the sum of the weights of the sources
+// listing it; the pool is ordered by score
+type MergeRules struct {
+	AI500Weight float64  // Weight of AI500 (< 0 = AI500 left out of the pool)
+	OITopWeight float64  // Weight of OI Top (< 0 = OI Top left out of the pool)
+	MinScore    float64  // Symbols scoring below are dropped (e.g. 2 with weights of 1 = listed by two sources)
+	MaxSymbols  int      // Highest scoring symbols kept (0 = all)
+	Exclude     []string // Symbols never offered
+}
+
+// customSources the configured sources and the last successful fetch of each HTTP source
+var customSources = struct {
+	sources []CustomSource
+	rules   MergeRules
+	fetched map[string]customFetch
+	mu      sync.Mutex
+}{
+	rules:   MergeRules{AI500Weight: 1, OITopWeight: 1},
+	fetched: make(map[string]customFetch),
+}
+
+// customFetch symbols of an HTTP source and when they were fetched
+type customFetch struct {
+	symbols   []string
+	fetchedAt time.Time
+}
+
+// customSourceClient HTTP client of the custom sources
+var customSourceClient = &http.Client{Timeout: 30 * time.Second}
+
+// SetCustomSources configures the custom sources and the merge rules
+func SetCustomSources(sources []CustomSource, rules MergeRules) error {
+	for _, source := range sources {
+		if source.Type != CustomSourceHTTP {
+			continue
+		}
+		if _, err := parseJSONPath(source.SymbolsPath); err != nil {
+			return fmt.Errorf("coin source %s: %w", source.Name, err)
+		}
+	}
+
+	customSources.mu.Lock()
+	customSources.sources = sources
+	customSources.rules = rules
+	customSources.mu.Unlock()
+
+	poolHealth.mu.Lock()
+	for _, source := range sources {
+		if _, ok := poolHealth.sources[source.Name]; !ok {
+			poolHealth.sources[source.Name] = &SourceStatus{Mode: SourceModeDisabled}
+		}
+	}
+	poolHealth.mu.Unlock()
+	log.Printf("✓ Custom coin sources configured: %d", len(sources))
+	return nil
+}
+
+// mergeRules the configured merge rules and custom sources
+func mergeRules() (MergeRules, []CustomSource) {
+	customSources.mu.Lock()
+	defer customSources.mu.Unlock()
+	return customSources.rules, customSources.sources
+}
+
+// customSourceSymbols the symbols of a custom source. A failed HTTP fetch falls back to the last successful
+// one (nil when there is none)
+func customSourceSymbols(source CustomSource) []string {
+	var symbols []string
+	if source.Type == CustomSourceStatic {
+		symbols = source.Symbols
+		recordSourceStatus(source.Name, SourceModeLive, time.Now(), nil)
+	} else {
+		symbols = fetchCustomSourceCached(source)
+	}
+
+	normalized := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if source.Limit > 0 && len(normalized) >= source.Limit {
+			break
+		}
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			normalized = append(normalized, normalizeSymbol(symbol))
+		}
+	}
+	return normalized
+}
+
+// fetchCustomSourceCached fetches an HTTP source unless its last fetch is younger than the cache TTL
+func fetchCustomSourceCached(source CustomSource) []string {
+	customSources.mu.Lock()
+	last, ok := customSources.fetched[source.Name]
+	customSources.mu.Unlock()
+	if ok && time.Since(last.fetchedAt) < source.CacheTTL {
+		return last.symbols
+	}
+
+	symbols, err := fetchCustomSource(source)
+	if err == nil {
+		customSources.mu.Lock()
+		customSources.fetched[source.Name] = customFetch{symbols: symbols, fetchedAt: time.Now()}
+		customSources.mu.Unlock()
+		recordSourceStatus(source.Name, SourceModeLive, time.Now(), nil)
+		return symbols
+	}
+
+	log.Printf("⚠️  Coin source %s failed: %v", source.Name, err)
+	if ok {
+		recordSourceStatus(source.Name, SourceModeSnapshot, last.fetchedAt, err)
+		return last.symbols
+	}
+	recordSourceStatus(source.Name, SourceModeUnavailable, time.Time{}, err)
+	return nil
+}
+
+// fetchCustomSource requests an HTTP source and selects its symbols
+func fetchCustomSource(source CustomSource) ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range source.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := customSourceClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), 200)])))
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	values, err := selectJSONPath(document, source.SymbolsPath)
+	if err != nil {
+		return nil, err
+	}
+	var symbols []string
+	for _, value := range values {
+		if symbol, ok := value.(string); ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols at %s", source.SymbolsPath)
+	}
+	return symbols, nil
+}
+
+// jsonPathStep one step of a JSONPath: an object key, an array index or a wildcard
+type jsonPathStep struct {
+	key      string
+	index    int // -1 = key step
+	wildcard bool
+}
+
+// selectJSONPath the values at a JSONPath of the subset "$.key.list[*].field", "[n]" and "['key']"
+func selectJSONPath(document any, path string) ([]any, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	values := []any{document}
+	for _, step := range steps {
+		var next []any
+		for _, value := range values {
+			switch {
+			case step.wildcard:
+				switch v := value.(type) {
+				case []any:
+					next = append(next, v...)
+				case map[string]any:
+					for _, item := range v {
+						next = append(next, item)
+					}
+				}
+			case step.index >= 0:
+				if list, ok := value.([]any); ok && step.index < len(list) {
+					next = append(next, list[step.index])
+				}
+			default:
+				if object, ok := value.(map[string]any); ok {
+					if item, exists := object[step.key]; exists {
+						next = append(next, item)
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// parseJSONPath splits a JSONPath into its steps
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var steps []jsonPathStep
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key", path)
+			}
+			if rest[:end] == "*" {
+				steps = append(steps, jsonPathStep{index: -1, wildcard: true})
+			} else {
+				steps = append(steps, jsonPathStep{key: rest[:end], index: -1})
+			}
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unclosed [", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				steps = append(steps, jsonPathStep{index: -1, wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1], index: -1})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid JSONPath %q: bad index [%s]", path, inner)
+				}
+				steps = append(steps, jsonPathStep{index: index})
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: expected . or [ at %q", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+// scoreSymbols merges the sources' symbols: scores, exclusions, the minimum score and the cap. Returns the
+// symbols ordered by score (highest first, then by symbol)
+func scoreSymbols(symbolSources map[string][]string, weights map[string]float64, rules MergeRules) ([]string, map[string]float64) {
+	excluded := make(map[string]bool)
+	for _, symbol := range rules.Exclude {
+		excluded[normalizeSymbol(symbol)] = true
+	}
+	scores := make(map[string]float64)
+	var symbols []string
+	for symbol, sources := range symbolSources {
+		if excluded[symbol] {
+			continue
+		}
+		score := 0.0
+		for _, source := range sources {
+			score += weights[source]
+		}
+		if score < rules.MinScore {
+			continue
+		}
+		scores[symbol] = score
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if scores[symbols[i]] != scores[symbols[j]] {
+			return scores[symbols[i]] > scores[symbols[j]]
+		}
+		return symbols[i] < symbols[j]
+	})
+	if rules.MaxSymbols > 0 && len(symbols) > rules.MaxSymbols {
+		symbols = symbols[:rules.MaxSymbols]
+	}
+	return symbols, scores
+}
@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"sync"
+	"time"
+)
+
+// liveCache reuses successful upstream fetches for a short TTL (disabled by default). Enabled by the
+// startup warmup so the pool loaded before the traders start serves their first cycles, and traders
+// starting together don't each hit the upstream API.
+var liveCache = struct {
+	ttl     time.Duration
+	coins   []CoinInfo
+	coinsAt time.Time
+	oiTop   []OIPosition
+	oiTopAt time.Time
+	mu      sync.Mutex
+}{}
+
+// SetLiveCacheTTL sets how long live coin pool / OI Top results are reused (<= 0 disables reuse)
+func SetLiveCacheTTL(ttl time.Duration) {
+	liveCache.mu.Lock()
+	defer liveCache.mu.Unlock()
+	liveCache.ttl = ttl
+	if ttl <= 0 {
+		liveCache.coins, liveCache.oiTop = nil, nil
+	}
+}
+
+// cachedLiveCoinPool returns the last live AI500 fetch if it is still fresh
+func cachedLiveCoinPool() ([]CoinInfo, bool) {
+	liveCache.mu.Lock()
+	defer liveCache.mu.Unlock()
+	if liveCache.ttl <= 0 || liveCache.coins == nil || time.Since(liveCache.coinsAt) >= liveCache.ttl {
+		return nil, false
+	}
+	return liveCache.coins, true
+}
+
+// storeLiveCoinPool remembers a live AI500 fetch
+func storeLiveCoinPool(coins []CoinInfo) {
+	liveCache.mu.Lock()
+	defer liveCache.mu.Unlock()
+	if liveCache.ttl > 0 {
+		liveCache.coins, liveCache.coinsAt = coins, time.Now()
+	}
+}
+
+// cachedLiveOITop returns the last live OI Top fetch if it is still fresh
+func cachedLiveOITop() ([]OIPosition, bool) {
+	liveCache.mu.Lock()
+	defer liveCache.mu.Unlock()
+	if liveCache.ttl <= 0 || liveCache.oiTop == nil || time.Since(liveCache.oiTopAt) >= liveCache.ttl {
+		return nil, false
+	}
+	return liveCache.oiTop, true
+}
+
+// storeLiveOITop remembers a live OI Top fetch
+func storeLiveOITop(positions []OIPosition) {
+	liveCache.mu.Lock()
+	defer liveCache.mu.Unlock()
+	if liveCache.ttl > 0 {
+		liveCache.oiTop, liveCache.oiTopAt = positions, time.Now()
+	}
+}
@@ -0,0 +1,161 @@
+package pool
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Coin pool source modes
+const (
+	SourceModeLive        = "live"        // Fetched from the upstream API this cycle
+	SourceModeSnapshot    = "snapshot"    // Upstream failed, using the last successful snapshot on disk
+	SourceModeDefault     = "default"     // Upstream failed and no snapshot, using the default coin list
+	SourceModeUnavailable = "unavailable" // Upstream failed and nothing to fall back to
+	SourceModeDisabled    = "disabled"    // Source not configured (or default coin list enabled)
+)
+
+// SourceStatus health of one coin pool source (AI500 or OI Top)
+type SourceStatus struct {
+	Mode               string    `json:"mode"`
+	SnapshotAt         time.Time `json:"snapshot_at"`          // When the data in use was fetched from upstream
+	StaleSince         time.Time `json:"stale_since"`          // First failed fetch since the last live fetch
+	LastError          string    `json:"last_error,omitempty"` // Last upstream error
+	StaleMinutes       float64   `json:"stale_minutes"`        // How long upstream has been unavailable
+	SnapshotAgeMinutes float64   `json:"snapshot_age_minutes"` // Age of the data in use
+	Alerting           bool      `json:"alerting"`             // Stale for longer than the alert threshold
+	lastAlert          time.Time `json:"-"`
+}
+
+// Stale whether the source is serving fallback data
+func (s SourceStatus) Stale() bool {
+	return s.Mode == SourceModeSnapshot || s.Mode == SourceModeDefault || s.Mode == SourceModeUnavailable
+}
+
+// PoolHealth coin pool upstream health
+type PoolHealth struct {
+	AI500              SourceStatus            `json:"ai500"`
+	OITop              SourceStatus            `json:"oi_top"`
+	Custom             map[string]SourceStatus `json:"custom,omitempty"` // Custom coin sources by name
+	AlertThresholdMins float64                 `json:"alert_threshold_minutes"`
+}
+
+var poolHealth = struct {
+	sources        map[string]*SourceStatus // key: "ai500" / "oi_top" / custom source name
+	alertThreshold time.Duration
+	mu             sync.Mutex
+}{
+	sources: map[string]*SourceStatus{
+		"ai500":  {Mode: SourceModeDisabled},
+		"oi_top": {Mode: SourceModeDisabled},
+	},
+	alertThreshold: time.Hour,
+}
+
+// SetStaleAlertThreshold sets how long an upstream may be unavailable before alerting (<= 0 keeps the default of 1 hour)
+func SetStaleAlertThreshold(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	poolHealth.mu.Lock()
+	poolHealth.alertThreshold = d
+	poolHealth.mu.Unlock()
+}
+
+// recordSourceStatus updates a source's health after a fetch attempt and alerts on prolonged staleness
+func recordSourceStatus(source, mode string, snapshotAt time.Time, fetchErr error) {
+	now := time.Now()
+
+	poolHealth.mu.Lock()
+	defer poolHealth.mu.Unlock()
+
+	status := poolHealth.sources[source]
+	status.Mode = mode
+	status.SnapshotAt = snapshotAt
+	if fetchErr != nil {
+		status.LastError = fetchErr.Error()
+	}
+
+	if !status.Stale() {
+		if !status.StaleSince.IsZero() {
+			log.Printf("✅ Coin pool source %s recovered after %.0f minutes", source, now.Sub(status.StaleSince).Minutes())
+		}
+		status.StaleSince = time.Time{}
+		status.LastError = ""
+		status.Alerting = false
+		return
+	}
+
+	if status.StaleSince.IsZero() {
+		status.StaleSince = now
+	}
+	staleFor := now.Sub(status.StaleSince)
+	status.Alerting = staleFor >= poolHealth.alertThreshold
+
+	// Alert at most once per threshold interval while the outage lasts
+	if status.Alerting && now.Sub(status.lastAlert) >= poolHealth.alertThreshold {
+		status.lastAlert = now
+		log.Printf("🚨 ALERT: coin pool source %s unavailable for %.0f minutes (serving %s data, last error: %s)",
+			source, staleFor.Minutes(), mode, status.LastError)
+	}
+}
+
+// sourceSnapshot returns a copy of a source's status with ages filled in
+func sourceSnapshot(source string, now time.Time) SourceStatus {
+	status := *poolHealth.sources[source]
+	if !status.StaleSince.IsZero() {
+		status.StaleMinutes = now.Sub(status.StaleSince).Minutes()
+	}
+	if !status.SnapshotAt.IsZero() {
+		status.SnapshotAgeMinutes = now.Sub(status.SnapshotAt).Minutes()
+	}
+	return status
+}
+
+// GetPoolHealth returns current coin pool upstream health
+func GetPoolHealth() PoolHealth {
+	now := time.Now()
+
+	poolHealth.mu.Lock()
+	defer poolHealth.mu.Unlock()
+
+	health := PoolHealth{
+		AI500:              sourceSnapshot("ai500", now),
+		OITop:              sourceSnapshot("oi_top", now),
+		AlertThresholdMins: poolHealth.alertThreshold.Minutes(),
+	}
+	for source := range poolHealth.sources {
+		if source == "ai500" || source == "oi_top" {
+			continue
+		}
+		if health.Custom == nil {
+			health.Custom = make(map[string]SourceStatus)
+		}
+		health.Custom[source] = sourceSnapshot(source, now)
+	}
+	return health
+}
+
+// staleNote describes a stale source for the AI prompt ("" if the source is live)
+func staleNote(name string, status SourceStatus) string {
+	switch status.Mode {
+	case SourceModeSnapshot:
+		return fmt.Sprintf("%s API unavailable - using snapshot from %s (%s old)",
+			name, status.SnapshotAt.Format("2006-01-02 15:04"), formatAge(status.SnapshotAgeMinutes))
+	case SourceModeDefault:
+		return fmt.Sprintf("%s API unavailable and no snapshot - using the default mainstream coin list", name)
+	case SourceModeUnavailable:
+		return fmt.Sprintf("%s API unavailable and no snapshot - %s candidates missing this cycle", name, name)
+	}
+	return ""
+}
+
+// formatAge formats minutes as "45m" or "3h10m"
+func formatAge(minutes float64) string {
+	m := int(minutes)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
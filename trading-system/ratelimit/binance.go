@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// binanceWeights request weight of Binance USDⓈ-M futures endpoints (path after /fapi/vN/; unlisted = 1)
+var binanceWeights = map[string]int{
+	"account":      5,
+	"balance":      5,
+	"positionRisk": 5,
+	"income":       30,
+	"allOrders":    5,
+	"userTrades":   5,
+	"openOrders":   1,
+}
+
+// defaultRateLimitPause pause after a 429/418 without a Retry-After header
+const defaultRateLimitPause = time.Minute
+
+// BinanceWeight request weight of a Binance futures request (klines and depth scale with limit)
+func BinanceWeight(path string, query url.Values) int {
+	endpoint := path
+	if i := strings.Index(path, "/fapi/v"); i >= 0 {
+		if j := strings.Index(path[i+len("/fapi/v"):], "/"); j >= 0 {
+			endpoint = path[i+len("/fapi/v")+j+1:]
+		}
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	switch endpoint {
+	case "klines", "continuousKlines", "markPriceKlines":
+		if limit == 0 {
+			limit = 500
+		}
+		switch {
+		case limit < 100:
+			return 1
+		case limit < 500:
+			return 2
+		case limit <= 1000:
+			return 5
+		default:
+			return 10
+		}
+	case "depth":
+		if limit == 0 {
+			limit = 500
+		}
+		switch {
+		case limit <= 50:
+			return 2
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 10
+		default:
+			return 20
+		}
+	case "ticker/price", "premiumIndex":
+		if query.Get("symbol") == "" {
+			return 10 // All symbols
+		}
+		return 1
+	}
+	if weight, ok := binanceWeights[endpoint]; ok {
+		return weight
+	}
+	return 1
+}
+
+// binanceTransport waits for request weight before every Binance request and pauses the budget when Binance
+// answers 429 (rate limited) or 418 (IP banned after ignoring 429s)
+type binanceTransport struct {
+	base    http.RoundTripper
+	account *Limiter // nil = public requests only
+}
+
+// NewBinanceTransport an HTTP transport charging requests to the Binance budget and, for signed requests, to the
+// account's budget (accountKey "" = public market data)
+func NewBinanceTransport(base http.RoundTripper, accountKey string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &binanceTransport{base: base}
+	if accountKey != "" {
+		t.account = Account(accountKey)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *binanceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	weight := BinanceWeight(req.URL.Path, req.URL.Query())
+	if t.account != nil {
+		if err := t.account.Wait(req.Context(), weight); err != nil {
+			return nil, err
+		}
+	}
+	if err := Binance().Wait(req.Context(), weight); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		pause := defaultRateLimitPause
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			pause = time.Duration(seconds) * time.Second
+		}
+		log.Printf("🚦 Binance rate limit hit (HTTP %d, %s) - pausing all Binance requests for %v", resp.StatusCode, req.URL.Path, pause)
+		Binance().Pause(pause)
+	}
+	return resp, nil
+}
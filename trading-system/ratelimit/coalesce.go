@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// coalescedCall a shared request: callers arriving while it runs wait for its result, later callers reuse it
+// for the TTL
+type coalescedCall struct {
+	done     chan struct{}
+	value    interface{}
+	err      error
+	finished time.Time
+	stale    bool // Forgotten while in flight (guarded by coalesced.mu)
+}
+
+var coalesced = struct {
+	calls map[string]*coalescedCall
+	mu    sync.Mutex
+}{
+	calls: make(map[string]*coalescedCall),
+}
+
+// Coalesce runs fn once for every caller asking for key at the same time and shares its successful result for
+// ttl afterwards (e.g. traders on one account reading its balance). Errors are returned to the callers that
+// waited for them but not reused
+func Coalesce(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	coalesced.mu.Lock()
+	if call, ok := coalesced.calls[key]; ok {
+		select {
+		case <-call.done:
+			if call.err == nil && !call.stale && time.Since(call.finished) < ttl {
+				coalesced.mu.Unlock()
+				return call.value, nil
+			}
+		default:
+			// In flight: wait for it
+			coalesced.mu.Unlock()
+			<-call.done
+			return call.value, call.err
+		}
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	coalesced.calls[key] = call
+	coalesced.mu.Unlock()
+
+	call.value, call.err = fn()
+	call.finished = time.Now()
+	close(call.done)
+
+	coalesced.mu.Lock()
+	if (call.err != nil || ttl <= 0 || call.stale) && coalesced.calls[key] == call {
+		delete(coalesced.calls, key)
+	}
+	coalesced.mu.Unlock()
+	return call.value, call.err
+}
+
+// Forget drops the shared result of key so the next caller fetches it again (after a change that makes it stale)
+func Forget(key string) {
+	coalesced.mu.Lock()
+	defer coalesced.mu.Unlock()
+	if call, ok := coalesced.calls[key]; ok {
+		select {
+		case <-call.done:
+			delete(coalesced.calls, key)
+		default:
+			// In flight: its result may predate the change, so don't let later callers reuse it
+			call.stale = true
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+)
+
+// Default budgets (request weight per minute). Binance allows 2400 per IP; the defaults leave headroom for
+// other tools running on the same IP
+const (
+	DefaultBinanceWeightPerMinute = 2000
+	DefaultAccountWeightPerMinute = 1000
+)
+
+// logWaitThreshold waits at least this long are logged
+const logWaitThreshold = time.Second
+
+// Limiter a request weight budget per minute, refilled continuously. Requests reserve their weight in
+// arrival order, so callers queue behind each other instead of all retrying at once
+type Limiter struct {
+	name        string
+	perMinute   float64
+	tokens      float64 // Available weight (negative = reserved by waiting requests)
+	last        time.Time
+	pausedUntil time.Time // Set when the exchange reported the budget exceeded
+	mu          sync.Mutex
+}
+
+// NewLimiter a limiter allowing weightPerMinute, starting with a full minute's budget
+func NewLimiter(name string, weightPerMinute int) *Limiter {
+	return &Limiter{
+		name:      name,
+		perMinute: float64(weightPerMinute),
+		tokens:    float64(weightPerMinute),
+		last:      time.Now(),
+	}
+}
+
+// refill adds the weight earned since the last call (capped at one minute's budget)
+func (l *Limiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Minutes() * l.perMinute
+	if l.tokens > l.perMinute {
+		l.tokens = l.perMinute
+	}
+	l.last = now
+}
+
+// Wait blocks until weight is available or ctx is done
+func (l *Limiter) Wait(ctx context.Context, weight int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	l.tokens -= float64(weight)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.perMinute * float64(time.Minute))
+	}
+	if paused := l.pausedUntil.Sub(now); paused > wait {
+		wait = paused
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	if wait >= logWaitThreshold {
+		log.Printf("⏳ %s request budget exhausted - request (weight %d) queued for %v", l.name, weight, wait.Round(100*time.Millisecond))
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			l.mu.Lock()
+			l.tokens += float64(weight) // Give the reservation back
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		// A pause reported while waiting extends the wait
+		l.mu.Lock()
+		pausedUntil := l.pausedUntil
+		l.mu.Unlock()
+		if !time.Now().Before(pausedUntil) {
+			return nil
+		}
+		deadline = pausedUntil
+	}
+}
+
+// Pause holds every request for d (the exchange reported the budget exceeded)
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// setRate changes the budget (the available weight is capped at the new budget)
+func (l *Limiter) setRate(weightPerMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.perMinute = float64(weightPerMinute)
+	if l.tokens > l.perMinute {
+		l.tokens = l.perMinute
+	}
+}
+
+// Process-wide limiters: the Binance IP budget and one budget per exchange account
+var registry = struct {
+	binance          *Limiter
+	accounts         map[string]*Limiter
+	accountPerMinute int
+	mu               sync.Mutex
+}{
+	binance:          NewLimiter("Binance", DefaultBinanceWeightPerMinute),
+	accounts:         make(map[string]*Limiter),
+	accountPerMinute: DefaultAccountWeightPerMinute,
+}
+
+// Configure sets the Binance IP budget and the per-account budget (<= 0 keeps the default)
+func Configure(binanceWeightPerMinute, accountWeightPerMinute int) {
+	if binanceWeightPerMinute <= 0 {
+		binanceWeightPerMinute = DefaultBinanceWeightPerMinute
+	}
+	if accountWeightPerMinute <= 0 {
+		accountWeightPerMinute = DefaultAccountWeightPerMinute
+	}
+	registry.binance.setRate(binanceWeightPerMinute)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.accountPerMinute = accountWeightPerMinute
+	for _, limiter := range registry.accounts {
+		limiter.setRate(accountWeightPerMinute)
+	}
+}
+
+// Binance the process-wide Binance futures budget, charged by every request to Binance (public market data and
+// signed account requests alike - Binance counts request weight per IP)
+func Binance() *Limiter {
+	return registry.binance
+}
+
+// AccountKey identifies an exchange account by its API key without exposing it (same key = same account)
+func AccountKey(exchange, apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return exchange + ":" + hex.EncodeToString(sum[:4])
+}
+
+// Account the budget of one exchange account's signed requests, shared by every trader using the account
+func Account(accountKey string) *Limiter {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	limiter, ok := registry.accounts[accountKey]
+	if !ok {
+		limiter = NewLimiter("account "+accountKey, registry.accountPerMinute)
+		registry.accounts[accountKey] = limiter
+	}
+	return limiter
+}
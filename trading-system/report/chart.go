@@ -0,0 +1,97 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+// Chart geometry (SVG user units; the chart scales to the page width)
+const (
+	chartWidth     = 960
+	chartHeight    = 260
+	chartPadLeft   = 70
+	chartPadRight  = 16
+	chartPadTop    = 12
+	chartPadBottom = 28
+	maxChartPoints = 1500 // Longer series are downsampled (every n-th point, the last one kept)
+)
+
+// lineChart an inline SVG line chart of values over times with a dashed reference line at baseline
+func lineChart(times []time.Time, values []float64, baseline float64, color, unit string) template.HTML {
+	if len(values) < 2 {
+		return ""
+	}
+	times, values = downsample(times, values)
+
+	lo, hi := baseline, baseline
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi-lo < 1e-9 {
+		hi, lo = hi+1, lo-1
+	}
+	margin := (hi - lo) * 0.05
+	lo, hi = lo-margin, hi+margin
+
+	plotW := float64(chartWidth - chartPadLeft - chartPadRight)
+	plotH := float64(chartHeight - chartPadTop - chartPadBottom)
+	start, end := times[0], times[len(times)-1]
+	span := end.Sub(start).Seconds()
+	x := func(i int) float64 {
+		if span <= 0 {
+			return chartPadLeft + plotW*float64(i)/float64(len(times)-1)
+		}
+		return chartPadLeft + plotW*times[i].Sub(start).Seconds()/span
+	}
+	y := func(v float64) float64 { return chartPadTop + plotH*(hi-v)/(hi-lo) }
+
+	var points strings.Builder
+	for i, v := range values {
+		fmt.Fprintf(&points, "%.1f,%.1f ", x(i), y(v))
+	}
+	area := fmt.Sprintf("%.1f,%.1f %s%.1f,%.1f", x(0), y(baseline), points.String(), x(len(values)-1), y(baseline))
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg viewBox="0 0 %d %d" role="img">`, chartWidth, chartHeight)
+	for i := 0; i <= 4; i++ {
+		v := lo + (hi-lo)*float64(i)/4
+		fmt.Fprintf(&svg, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="grid"/>`, chartPadLeft, chartWidth-chartPadRight, y(v), y(v))
+		fmt.Fprintf(&svg, `<text x="%d" y="%.1f" class="axis" text-anchor="end">%s</text>`, chartPadLeft-6, y(v)+4, html.EscapeString(axisLabel(v, unit)))
+	}
+	fmt.Fprintf(&svg, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="baseline"/>`, chartPadLeft, chartWidth-chartPadRight, y(baseline), y(baseline))
+	fmt.Fprintf(&svg, `<polygon points="%s" fill="%s" fill-opacity="0.12"/>`, area, color)
+	fmt.Fprintf(&svg, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, strings.TrimSpace(points.String()), color)
+	fmt.Fprintf(&svg, `<text x="%d" y="%d" class="axis">%s</text>`, chartPadLeft, chartHeight-8, start.UTC().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&svg, `<text x="%d" y="%d" class="axis" text-anchor="end">%s</text>`, chartWidth-chartPadRight, chartHeight-8, end.UTC().Format("2006-01-02 15:04"))
+	svg.WriteString(`</svg>`)
+	return template.HTML(svg.String())
+}
+
+// downsample keeps at most maxChartPoints points, always including the last one
+func downsample(times []time.Time, values []float64) ([]time.Time, []float64) {
+	if len(values) <= maxChartPoints {
+		return times, values
+	}
+	step := int(math.Ceil(float64(len(values)) / maxChartPoints))
+	var t []time.Time
+	var v []float64
+	for i := 0; i < len(values); i += step {
+		t, v = append(t, times[i]), append(v, values[i])
+	}
+	if last := len(values) - 1; !t[len(t)-1].Equal(times[last]) {
+		t, v = append(t, times[last]), append(v, values[last])
+	}
+	return t, v
+}
+
+// axisLabel a y-axis value
+func axisLabel(v float64, unit string) string {
+	if unit == "%" {
+		return fmt.Sprintf("%.1f%%", v)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
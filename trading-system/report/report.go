@@ -0,0 +1,293 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"lia/logger"
+	"math"
+	"sort"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var reportPage = template.Must(template.New("report").Funcs(template.FuncMap{
+	"usdt":     func(v float64) string { return fmt.Sprintf("%+.2f", v) },
+	"price":    func(v float64) string { return fmt.Sprintf("%.4f", v) },
+	"pct":      func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+	"hold":     formatHold,
+	"sign":     sign,
+}).Parse(reportTemplate))
+
+// maxTradeRows trades listed in the trade table (newest kept); totals still cover every trade
+const maxTradeRows = 500
+
+// EquityPoint account equity at a point in time
+type EquityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// Trade a closed trade
+type Trade struct {
+	Symbol     string
+	Side       string
+	Leverage   int
+	Quantity   float64
+	EntryPrice float64
+	ExitPrice  float64
+	OpenTime   time.Time
+	CloseTime  time.Time
+	Fees       float64
+	PnL        float64 // Net of fees (USDT)
+	Reason     string  // Exit reason or closing action
+}
+
+// Report the data of a performance report: an equity curve and the closed trades, from a backtest or a live
+// trader's history
+type Report struct {
+	Title         string
+	Subtitle      string
+	GeneratedAt   time.Time
+	InitialEquity float64
+	Equity        []EquityPoint // Oldest first
+	Trades        []Trade       // By close time, oldest first
+	Notes         []string
+}
+
+// Summary headline statistics of a report
+type Summary struct {
+	InitialEquity  float64
+	FinalEquity    float64
+	PnL            float64
+	ReturnPct      float64
+	MaxDrawdownPct float64
+	Trades         int
+	Wins           int
+	Losses         int
+	WinRate        float64
+	ProfitFactor   float64 // 0 = no losing trade
+	Fees           float64
+	AvgHold        time.Duration
+}
+
+// SymbolStats the closed trades of one symbol
+type SymbolStats struct {
+	Symbol  string
+	Trades  int
+	Longs   int
+	Shorts  int
+	Wins    int
+	WinRate float64
+	PnL     float64
+	AvgPnL  float64
+	Fees    float64
+	Best    float64
+	Worst   float64
+}
+
+// FromDecisionLog builds the report of a live trader from its decision records (equity at each cycle) and its
+// trade journal (closed trades); limit caps the records read (<= 0 = all)
+func FromDecisionLog(title string, decisions *logger.DecisionLogger, initialEquity float64, limit int) (*Report, error) {
+	page, err := decisions.GetDecisionPage(logger.DecisionPageQuery{Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load decision records: %w", err)
+	}
+	trades, err := decisions.GetTrades(logger.TradeClosed, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trades: %w", err)
+	}
+
+	r := &Report{Title: title, InitialEquity: initialEquity}
+	for _, record := range page.Records {
+		if record.AccountState.TotalBalance > 0 {
+			r.Equity = append(r.Equity, EquityPoint{Time: record.Timestamp, Equity: record.AccountState.TotalBalance})
+		}
+	}
+	for _, t := range trades {
+		r.Trades = append(r.Trades, Trade{
+			Symbol:     t.Symbol,
+			Side:       t.Side,
+			Leverage:   t.Leverage,
+			Quantity:   t.ClosedQuantity,
+			EntryPrice: t.OpenPrice,
+			ExitPrice:  t.ClosePrice,
+			OpenTime:   t.OpenTime,
+			CloseTime:  t.CloseTime,
+			Fees:       t.Fees,
+			PnL:        t.NetPnL,
+			Reason:     t.CloseReason,
+		})
+	}
+	sort.SliceStable(r.Trades, func(i, j int) bool { return r.Trades[i].CloseTime.Before(r.Trades[j].CloseTime) })
+	if r.InitialEquity <= 0 && len(r.Equity) > 0 {
+		r.InitialEquity = r.Equity[0].Equity
+	}
+	if len(r.Equity) > 0 {
+		r.Subtitle = fmt.Sprintf("Live history %s → %s (%d cycles)",
+			r.Equity[0].Time.UTC().Format("2006-01-02 15:04"), r.Equity[len(r.Equity)-1].Time.UTC().Format("2006-01-02 15:04"), len(r.Equity))
+	}
+	if page.Total > len(page.Records) {
+		r.Notes = append(r.Notes, fmt.Sprintf("Equity curve limited to the latest %d cycles", len(page.Records)))
+	}
+	return r, nil
+}
+
+// Summary the report's headline statistics
+func (r *Report) Summary() Summary {
+	s := Summary{InitialEquity: r.InitialEquity, FinalEquity: r.InitialEquity, Trades: len(r.Trades)}
+	if len(r.Equity) > 0 {
+		s.FinalEquity = r.Equity[len(r.Equity)-1].Equity
+	}
+	s.PnL = s.FinalEquity - s.InitialEquity
+	if s.InitialEquity > 0 {
+		s.ReturnPct = s.PnL / s.InitialEquity * 100
+	}
+	for _, dd := range r.drawdowns() {
+		s.MaxDrawdownPct = math.Max(s.MaxDrawdownPct, dd)
+	}
+
+	gains, losses := 0.0, 0.0
+	var hold time.Duration
+	for _, t := range r.Trades {
+		switch {
+		case t.PnL > 0:
+			s.Wins++
+			gains += t.PnL
+		case t.PnL < 0:
+			s.Losses++
+			losses -= t.PnL
+		}
+		s.Fees += t.Fees
+		hold += t.CloseTime.Sub(t.OpenTime)
+	}
+	if s.Trades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+		s.AvgHold = hold / time.Duration(s.Trades)
+	}
+	if losses > 0 {
+		s.ProfitFactor = gains / losses
+	}
+	return s
+}
+
+// Symbols the per-symbol breakdown, best net P&L first
+func (r *Report) Symbols() []SymbolStats {
+	bySymbol := make(map[string]*SymbolStats)
+	for _, t := range r.Trades {
+		s, ok := bySymbol[t.Symbol]
+		if !ok {
+			s = &SymbolStats{Symbol: t.Symbol, Best: t.PnL, Worst: t.PnL}
+			bySymbol[t.Symbol] = s
+		}
+		s.Trades++
+		if t.Side == "short" {
+			s.Shorts++
+		} else {
+			s.Longs++
+		}
+		if t.PnL > 0 {
+			s.Wins++
+		}
+		s.PnL += t.PnL
+		s.Fees += t.Fees
+		s.Best = math.Max(s.Best, t.PnL)
+		s.Worst = math.Min(s.Worst, t.PnL)
+	}
+
+	stats := make([]SymbolStats, 0, len(bySymbol))
+	for _, s := range bySymbol {
+		s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+		s.AvgPnL = s.PnL / float64(s.Trades)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].PnL != stats[j].PnL {
+			return stats[i].PnL > stats[j].PnL
+		}
+		return stats[i].Symbol < stats[j].Symbol
+	})
+	return stats
+}
+
+// drawdowns the drawdown (% below the running peak) at every equity point
+func (r *Report) drawdowns() []float64 {
+	drawdowns := make([]float64, len(r.Equity))
+	peak := r.InitialEquity
+	for i, p := range r.Equity {
+		peak = math.Max(peak, p.Equity)
+		if peak > 0 {
+			drawdowns[i] = math.Max(0, (peak-p.Equity)/peak*100)
+		}
+	}
+	return drawdowns
+}
+
+// Render writes the report as a self-contained HTML page (inline CSS and SVG charts, no external assets)
+func (r *Report) Render(w io.Writer) error {
+	times := make([]time.Time, len(r.Equity))
+	equity := make([]float64, len(r.Equity))
+	for i, p := range r.Equity {
+		times[i], equity[i] = p.Time, p.Equity
+	}
+	drawdowns := r.drawdowns()
+	negated := make([]float64, len(drawdowns))
+	for i, dd := range drawdowns {
+		negated[i] = -dd
+	}
+
+	trades := r.Trades
+	truncated := 0
+	if len(trades) > maxTradeRows {
+		truncated = len(trades) - maxTradeRows
+		trades = trades[truncated:]
+	}
+	rows := make([]Trade, len(trades))
+	for i := range trades {
+		rows[i] = trades[len(trades)-1-i] // Newest first
+	}
+
+	generated := r.GeneratedAt
+	if generated.IsZero() {
+		generated = time.Now()
+	}
+	return reportPage.Execute(w, map[string]interface{}{
+		"Report":         r,
+		"GeneratedAt":    generated,
+		"Summary":        r.Summary(),
+		"Symbols":        r.Symbols(),
+		"Trades":         rows,
+		"TruncatedRows":  truncated,
+		"EquityChart":    lineChart(times, equity, r.InitialEquity, "#2563eb", "USDT"),
+		"DrawdownChart":  lineChart(times, negated, 0, "#dc2626", "%"),
+		"HasEquityCurve": len(r.Equity) > 1,
+	})
+}
+
+// formatHold a holding duration for the trade table
+func formatHold(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+// sign the CSS class of a P&L value
+func sign(v float64) string {
+	switch {
+	case v > 0:
+		return "pos"
+	case v < 0:
+		return "neg"
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock simulated time: stands still until advanced
+type Clock struct {
+	now time.Time
+	mu  sync.RWMutex
+}
+
+// NewClock a clock starting at start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start.UTC()}
+}
+
+// Now the simulated time
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d and returns the new time
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set moves the clock to t
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t.UTC()
+}
@@ -0,0 +1,199 @@
+package sim
+
+import (
+	"fmt"
+	"hash/fnv"
+	"lia/market"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	baseInterval    = "3m" // Candle interval of the price paths (the live engine's intraday series)
+	trendInterval   = "4h" // Longer-term candle interval of the market data
+	intradayWindow  = 40   // 3m candles per market data snapshot (same as live)
+	trendWindow     = 60   // 4h candles per market data snapshot (same as live)
+	minIntradayBars = 26   // Closed 3m candles required before a symbol has market data (MACD needs 26)
+
+	syntheticStartPrice = 100.0     // Default synthetic price at the start of the warmup
+	syntheticQuoteVol   = 1000000.0 // Average synthetic 3m candle volume in USDT
+)
+
+// series the price path of one symbol, oldest first
+type series struct {
+	symbol   string
+	klines3m []market.Kline
+	klines4h []market.Kline
+}
+
+// Feed the price paths of the simulated symbols, read at the clock's time. Implements market.Source
+type Feed struct {
+	clock       *Clock
+	series      map[string]*series
+	symbols     []string
+	fundingRate float64
+	start       time.Time // First time every symbol has market data (or the configured start)
+	end         time.Time // Last candle close shared by every symbol
+}
+
+// closedBefore number of 3m candles closed before t (the candles visible at t)
+func (s *series) closedBefore(t time.Time) int {
+	ms := t.UnixMilli()
+	return sort.Search(len(s.klines3m), func(i int) bool { return s.klines3m[i].CloseTime >= ms })
+}
+
+// lookup the series of symbol
+func (f *Feed) lookup(symbol string) (*series, error) {
+	s, ok := f.series[market.Normalize(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a simulated symbol (simulation.symbols: %v)", symbol, f.symbols)
+	}
+	return s, nil
+}
+
+// Data market data at the clock's time from the closed candles only: the last 40 3m candles and the last 4h
+// candles, the current 4h candle rebuilt from the 3m candles so far
+func (f *Feed) Data(symbol string) (*market.Data, error) {
+	s, err := f.lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+	now := f.clock.Now()
+	n := s.closedBefore(now)
+	if n < minIntradayBars {
+		return nil, fmt.Errorf("%s: not enough simulated candles at %s", s.symbol, now.Format(time.RFC3339))
+	}
+	intraday := s.klines3m[max(0, n-intradayWindow):n]
+
+	trendStep, _ := market.IntervalDuration(trendInterval)
+	currentOpen := now.Truncate(trendStep).UnixMilli()
+	closed := sort.Search(len(s.klines4h), func(i int) bool { return s.klines4h[i].OpenTime >= currentOpen })
+	trend := make([]market.Kline, 0, trendWindow)
+	trend = append(trend, s.klines4h[max(0, closed-trendWindow+1):closed]...)
+	from := sort.Search(n, func(i int) bool { return s.klines3m[i].OpenTime >= currentOpen })
+	if from < n {
+		partial, _ := market.AggregateKlines(s.klines3m[from:n], trendInterval)
+		trend = append(trend, partial...)
+	}
+
+	data := market.BuildData(s.symbol, intraday, trend)
+	data.FundingRate = f.fundingRate
+	return data, nil
+}
+
+// Price close of the last candle closed before the clock's time
+func (f *Feed) Price(symbol string) (float64, error) {
+	s, err := f.lookup(symbol)
+	if err != nil {
+		return 0, err
+	}
+	n := s.closedBefore(f.clock.Now())
+	if n == 0 {
+		return 0, fmt.Errorf("no simulated %s price at %s", s.symbol, f.clock.Now().Format(time.RFC3339))
+	}
+	return s.klines3m[n-1].Close, nil
+}
+
+// Klines the last limit candles of interval closed before the clock's time (intervals above 3m end with the
+// current partial candle, like Binance). Intervals that are not multiples of 3m (1m, 5m) are served as the 3m
+// candles covering the same time span
+func (f *Feed) Klines(symbol, interval string, limit int) ([]market.Kline, error) {
+	s, err := f.lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+	step, err := market.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := s.klines3m[:s.closedBefore(f.clock.Now())]
+	baseStep := 3 * time.Minute
+	if step%baseStep != 0 {
+		limit = int((time.Duration(limit)*step + baseStep - 1) / baseStep)
+	} else if interval != baseInterval {
+		klines, _ = market.AggregateKlines(klines, interval)
+	}
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	out := make([]market.Kline, len(klines))
+	copy(out, klines)
+	return out, nil
+}
+
+// Symbols the simulated symbols
+func (f *Feed) Symbols() []string {
+	return append([]string(nil), f.symbols...)
+}
+
+// Start first time every symbol has market data (or the configured start)
+func (f *Feed) Start() time.Time {
+	return f.start
+}
+
+// End close of the last candle every symbol has
+func (f *Feed) End() time.Time {
+	return f.end
+}
+
+// loadRecordedSeries reads <SYMBOL>_3m.csv (and <SYMBOL>_4h.csv when present, else aggregated from the 3m
+// candles) from dir
+func loadRecordedSeries(symbol, dir string) (*series, error) {
+	klines3m, err := market.LoadKlinesCSV(filepath.Join(dir, symbol+"_3m.csv"), baseInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s candles: %w", symbol, err)
+	}
+	if len(klines3m) < intradayWindow {
+		return nil, fmt.Errorf("%s: %d recorded 3m candles, need at least %d", symbol, len(klines3m), intradayWindow)
+	}
+
+	trendPath := filepath.Join(dir, symbol+"_4h.csv")
+	var klines4h []market.Kline
+	if _, statErr := os.Stat(trendPath); statErr == nil {
+		klines4h, err = market.LoadKlinesCSV(trendPath, trendInterval)
+	} else {
+		klines4h, err = market.AggregateKlines(klines3m, trendInterval)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s candles: %w", symbol, err)
+	}
+	return &series{symbol: symbol, klines3m: klines3m, klines4h: klines4h}, nil
+}
+
+// syntheticSeries a seeded geometric random walk of 3m candles over [from, to). Each symbol's path depends on
+// the seed and the symbol only, so adding symbols does not change the others
+func syntheticSeries(symbol string, seed int64, startPrice, volatilityBps, driftBps float64, from, to time.Time) *series {
+	h := fnv.New64a()
+	h.Write([]byte(symbol))
+	rng := rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+
+	step := 3 * time.Minute
+	vol := volatilityBps / 10000
+	drift := driftBps / 10000
+	baseVolume := syntheticQuoteVol / startPrice
+
+	price := startPrice
+	var klines3m []market.Kline
+	for t := from; t.Before(to); t = t.Add(step) {
+		open := price
+		closePrice := open * math.Exp(drift+vol*rng.NormFloat64())
+		wick := vol * math.Abs(rng.NormFloat64()) / 2
+		klines3m = append(klines3m, market.Kline{
+			OpenTime:  t.UnixMilli(),
+			Open:      open,
+			High:      math.Max(open, closePrice) * (1 + wick),
+			Low:       math.Min(open, closePrice) * (1 - wick),
+			Close:     closePrice,
+			Volume:    baseVolume * (0.5 + rng.Float64()),
+			CloseTime: t.Add(step).UnixMilli() - 1,
+		})
+		price = closePrice
+	}
+	klines4h, _ := market.AggregateKlines(klines3m, trendInterval)
+	return &series{symbol: symbol, klines3m: klines3m, klines4h: klines4h}
+}
@@ -0,0 +1,164 @@
+// Package sim runs a trader deterministically: recorded or synthetic price paths read on a simulated clock,
+// seeded randomness and scripted AI responses, with no network calls. Used by the "simulate" exchange mode so
+// AutoTrader cycles and the decision pipeline can be exercised reproducibly (e.g. in CI)
+package sim
+
+import (
+	"fmt"
+	"lia/config"
+	"lia/decision"
+	"lia/market"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// defaultStart first cycle of synthetic simulations without a configured start
+var defaultStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// syntheticWarmup synthetic history generated before the start, so the 4h indicators are complete
+const syntheticWarmup = (trendWindow + 1) * 4 * time.Hour
+
+// Simulation the simulated market, clock and AI of one simulate trader
+type Simulation struct {
+	Clock     *Clock
+	Feed      *Feed
+	Seed      int64    // Seed of the synthetic paths, also used for the paper trader's randomness
+	Responses []string // Scripted AI responses (empty = the trader must use a rule-based strategy)
+	LogDir    string   // Decision logs and trader state of this run
+}
+
+// New builds the price paths and clock described by cfg (see config.SimulationConfig)
+func New(cfg config.SimulationConfig) (*Simulation, error) {
+	var start time.Time
+	if cfg.Start != "" {
+		t, err := time.Parse(time.RFC3339, cfg.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid simulation start: %w", err)
+		}
+		start = t.UTC().Truncate(3 * time.Minute)
+	}
+
+	feed := &Feed{series: make(map[string]*series), fundingRate: cfg.FundingRate}
+	seen := make(map[string]bool)
+	for _, raw := range cfg.Symbols {
+		symbol := market.Normalize(raw)
+		if !seen[symbol] {
+			seen[symbol] = true
+			feed.symbols = append(feed.symbols, symbol)
+		}
+	}
+	if len(feed.symbols) == 0 {
+		return nil, fmt.Errorf("simulation needs at least one symbol")
+	}
+
+	if cfg.CSVDir != "" {
+		// Recorded paths: start once every symbol has a full intraday window, end at the shortest recording
+		for _, symbol := range feed.symbols {
+			s, err := loadRecordedSeries(symbol, cfg.CSVDir)
+			if err != nil {
+				return nil, err
+			}
+			feed.series[symbol] = s
+			ready := time.UnixMilli(s.klines3m[intradayWindow-1].CloseTime + 1).UTC()
+			last := time.UnixMilli(s.klines3m[len(s.klines3m)-1].CloseTime + 1).UTC()
+			if ready.After(feed.start) {
+				feed.start = ready
+			}
+			if feed.end.IsZero() || last.Before(feed.end) {
+				feed.end = last
+			}
+		}
+		if !start.IsZero() {
+			feed.start = start
+		}
+	} else {
+		if start.IsZero() {
+			start = defaultStart
+		}
+		feed.start = start
+		feed.end = start.Add(time.Duration(cfg.DurationHours * float64(time.Hour)))
+		from := start.Add(-syntheticWarmup).Truncate(4 * time.Hour)
+		for _, symbol := range feed.symbols {
+			price := syntheticStartPrice
+			if p, ok := cfg.StartPrices[symbol]; ok {
+				price = p
+			}
+			feed.series[symbol] = syntheticSeries(symbol, cfg.Seed, price, cfg.VolatilityBps, cfg.DriftBps, from, feed.end)
+		}
+	}
+	if !feed.end.After(feed.start) {
+		return nil, fmt.Errorf("simulation ends (%s) before it starts (%s)", feed.end.Format(time.RFC3339), feed.start.Format(time.RFC3339))
+	}
+
+	responses, err := loadResponses(cfg.AIResponsesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	logDir := cfg.LogDir
+	if logDir == "" {
+		if logDir, err = os.MkdirTemp("", "lia-simulate-"); err != nil {
+			return nil, fmt.Errorf("failed to create the simulation log directory: %w", err)
+		}
+	}
+
+	feed.clock = NewClock(feed.start)
+	return &Simulation{
+		Clock:     feed.clock,
+		Feed:      feed,
+		Seed:      cfg.Seed,
+		Responses: responses,
+		LogDir:    logDir,
+	}, nil
+}
+
+// loadResponses reads the *.txt scripted AI responses in dir, in name order ("" = none)
+func loadResponses(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.txt AI responses in %s", dir)
+	}
+	sort.Strings(paths)
+	responses := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read AI response: %w", err)
+		}
+		responses = append(responses, string(data))
+	}
+	return responses, nil
+}
+
+// Install routes market data, candlesticks, decision validation prices and the decision clock through the
+// simulation (process-wide: one simulation at a time)
+func (s *Simulation) Install() {
+	market.SetSource(s.Feed)
+	decision.SetMarketDataSource(s.Feed.Data)
+	decision.SetPriceSource(s.Feed.Price)
+	decision.SetClock(s.Clock.Now)
+	log.Printf("🧪 Simulation: %v, %s → %s (seed %d, logs in %s)", s.Feed.symbols,
+		s.Feed.start.Format(time.RFC3339), s.Feed.end.Format(time.RFC3339), s.Seed, s.LogDir)
+}
+
+// Uninstall restores live market data and the wall clock
+func (s *Simulation) Uninstall() {
+	market.SetSource(nil)
+	decision.SetMarketDataSource(nil)
+	decision.SetPriceSource(nil)
+	decision.SetClock(nil)
+}
+
+// Finished reports whether the clock has reached the end of the price paths
+func (s *Simulation) Finished() bool {
+	return !s.Clock.Now().Before(s.Feed.end)
+}
@@ -0,0 +1,94 @@
+package trader
+
+import (
+	"lia/config"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"sort"
+)
+
+// applyAdaptivePool biases the candidate pool by this trader's per-symbol track record:
+// proven symbols move to the front (or are added), negative-expectancy symbols move to the back,
+// chronic losers are dropped. Symbols with an open position are never dropped.
+func applyAdaptivePool(coins []decisionPkg.CandidateCoin, performance *logger.PerformanceAnalysis,
+	cfg config.AdaptivePoolConfig, openSymbols map[string]bool) ([]decisionPkg.CandidateCoin, []decisionPkg.PoolAdjustment) {
+	if !cfg.Enabled || performance == nil || len(performance.SymbolStats) == 0 {
+		return coins, nil
+	}
+
+	// Classify every symbol with enough closed trades
+	verdicts := make(map[string]string)
+	for symbol, stats := range performance.SymbolStats {
+		if stats.TotalTrades < cfg.MinTrades {
+			continue
+		}
+		switch {
+		case stats.AvgPnL > 0 && stats.WinRate >= cfg.BoostMinWinRate:
+			verdicts[symbol] = decisionPkg.PoolBoosted
+		case stats.AvgPnL < 0 && stats.TotalTrades >= cfg.ExcludeMinTrades && stats.WinRate <= cfg.ExcludeMaxWinRate && !openSymbols[symbol]:
+			verdicts[symbol] = decisionPkg.PoolExcluded
+		case stats.AvgPnL < 0:
+			verdicts[symbol] = decisionPkg.PoolDemoted
+		}
+	}
+	if len(verdicts) == 0 {
+		return coins, nil
+	}
+
+	var boosted, neutral, demoted []decisionPkg.CandidateCoin
+	var adjustments []decisionPkg.PoolAdjustment
+	inPool := make(map[string]bool)
+	for _, coin := range coins {
+		inPool[coin.Symbol] = true
+		verdict := verdicts[coin.Symbol]
+		switch verdict {
+		case decisionPkg.PoolBoosted:
+			boosted = append(boosted, coin)
+		case decisionPkg.PoolDemoted:
+			demoted = append(demoted, coin)
+		case decisionPkg.PoolExcluded:
+			// Dropped
+		default:
+			neutral = append(neutral, coin)
+		}
+		if verdict != "" {
+			adjustments = append(adjustments, poolAdjustment(performance.SymbolStats[coin.Symbol], verdict))
+		}
+	}
+
+	// Proven symbols the coin pool didn't list this cycle
+	if cfg.AddBoosted {
+		for symbol, verdict := range verdicts {
+			if verdict != decisionPkg.PoolBoosted || inPool[symbol] {
+				continue
+			}
+			boosted = append(boosted, decisionPkg.CandidateCoin{Symbol: symbol, Sources: []string{"history"}})
+			adjustments = append(adjustments, poolAdjustment(performance.SymbolStats[symbol], decisionPkg.PoolAdded))
+		}
+	}
+
+	// Strongest edge first within the boosted group, and in the adjustment list
+	sort.SliceStable(boosted, func(i, j int) bool {
+		return performance.SymbolStats[boosted[i].Symbol].AvgPnL > performance.SymbolStats[boosted[j].Symbol].AvgPnL
+	})
+	sort.SliceStable(adjustments, func(i, j int) bool {
+		return adjustments[i].AvgPnL > adjustments[j].AvgPnL
+	})
+
+	result := make([]decisionPkg.CandidateCoin, 0, len(boosted)+len(neutral)+len(demoted))
+	result = append(result, boosted...)
+	result = append(result, neutral...)
+	result = append(result, demoted...)
+	return result, adjustments
+}
+
+// poolAdjustment builds the prompt entry for an adjusted symbol
+func poolAdjustment(stats *logger.SymbolPerformance, verdict string) decisionPkg.PoolAdjustment {
+	return decisionPkg.PoolAdjustment{
+		Symbol:  stats.Symbol,
+		Type:    verdict,
+		Trades:  stats.TotalTrades,
+		WinRate: stats.WinRate,
+		AvgPnL:  stats.AvgPnL,
+	}
+}
@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/market"
+	"strings"
+)
+
+// executeAddWithRecord executes add_long / add_short: buys more of an existing position at its leverage and
+// moves its stop / take profit orders to the decision's levels for the whole (averaged) position
+func (at *AutoTrader) executeAddWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
+	side := decisionPkg.EntrySide(decision.Action)
+	target, err := at.findAmendTarget(decision.Symbol, side)
+	if err != nil {
+		return err
+	}
+	at.log.Infof("  ➕ Adding to %s position: %s (entry %.4f, quantity %.4f)", side, decision.Symbol, target.entryPrice, target.quantity)
+
+	lock := getPositionLock(decision.Symbol, strings.ToUpper(side))
+	lock.Lock()
+	defer lock.Unlock()
+
+	marketData, err := market.Get(decision.Symbol)
+	if err != nil {
+		return err
+	}
+	effectiveMargin, _, err := at.determineExecutableMargin(decision.Symbol, decision.Action, decision.PositionSizeUSD)
+	if err != nil {
+		return err
+	}
+
+	// Keep the position's leverage: another one would re-leverage what is already held
+	leverage := at.currentLeverage(decision.Symbol)
+	if leverage <= 0 {
+		leverage = at.capLeverage(decision.Symbol, decision.Leverage)
+	}
+	decision.Leverage = leverage
+
+	quantity := quantityForMargin(effectiveMargin, leverage, marketData.CurrentPrice)
+	actionRecord.Quantity = quantity
+	actionRecord.Price = marketData.CurrentPrice
+
+	// Exchanges that cannot list orders cancel every order of the symbol before an entry, other traders'
+	// stop / take profit orders included
+	if at.positionProtection.exchange == nil && at.ownership.shared(at.accountKey) {
+		return fmt.Errorf("cannot add to %s %s: the entry would cancel the stop/target orders of the other traders sharing this account",
+			decision.Symbol, side)
+	}
+
+	// Reserve a slot in the cross-trader symbol throttle (shared account protection)
+	if at.symbolThrottle != nil {
+		if err := at.symbolThrottle.TryAcquire(decision.Symbol, at.id); err != nil {
+			return err
+		}
+	}
+
+	var order *Order
+	if side == "long" {
+		order, err = at.trader.OpenLong(decision.Symbol, quantity, leverage)
+	} else {
+		order, err = at.trader.OpenShort(decision.Symbol, quantity, leverage)
+	}
+	if err != nil {
+		if at.symbolThrottle != nil {
+			at.symbolThrottle.Release(decision.Symbol, at.id)
+		}
+		// The entry order cancels this trader's orders of the symbol before it is placed - restore them
+		at.refreshProtectionOrders(decision.Symbol)
+		if isMarginInsufficientAPIError(err) {
+			return fmt.Errorf("%w: exchange rejected %s %s (need %.2f USDT margin, err: %v)",
+				ErrMarginInsufficient, decision.Symbol, decision.Action, effectiveMargin, err)
+		}
+		return fmt.Errorf("failed to add to %s %s: %w", decision.Symbol, side, err)
+	}
+	actionRecord.OrderID = order.OrderID
+	actionRecord.ClientOrderID = order.ClientOrderID
+	actionRecord.Fee = order.Fee
+	if order.Price > 0 {
+		actionRecord.Price = order.Price
+	}
+
+	averaged := decisionPkg.AveragedEntry(target.quantity, target.entryPrice, quantity, actionRecord.Price)
+	at.log.Infof("  ✓ Added %.4f @ %.4f, Order ID: %v → %.4f @ ~%.4f average entry",
+		quantity, actionRecord.Price, order.OrderID, target.quantity+quantity, averaged)
+
+	// Stop / take profit now cover the whole position (re-placed for the new quantity)
+	stop := at.stopOrderPrice(decision)
+	at.positionProtection.update(decision.Symbol, side, func(levels *ProtectedPosition) {
+		levels.TakeProfit = decision.TakeProfit
+		if stop > 0 {
+			levels.StopLoss = stop
+		}
+	})
+	at.refreshProtectionOrders(decision.Symbol)
+	return nil
+}
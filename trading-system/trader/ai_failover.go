@@ -0,0 +1,56 @@
+package trader
+
+import (
+	"lia/logging"
+	"lia/mcp"
+	"time"
+)
+
+// newFailoverClient an AI client for a failover provider using the trader's credentials for it (nil = unknown provider)
+func newFailoverClient(provider string, config AutoTraderConfig) *mcp.Client {
+	client := mcp.New()
+	switch provider {
+	case "groq":
+		client.SetGroqAPIKey(config.GroqKey, config.GroqModel)
+	case "qwen":
+		client.SetQwenAPIKey(config.QwenKey, "")
+	case "deepseek":
+		client.SetDeepSeekAPIKey(config.DeepSeekKey)
+	case "anthropic":
+		client.SetAnthropicAPIKey(config.AnthropicKey, config.AnthropicModel)
+	case "gemini":
+		client.SetGeminiAPIKey(config.GeminiKey, config.GeminiModel)
+	case "custom":
+		client.SetCustomAPI(config.CustomAPIURL, config.CustomAPIKey, config.CustomModelName)
+	default:
+		return nil
+	}
+	return client
+}
+
+// configureAIClient applies the per-provider request timeout, the per-model prices, the retry policy, the
+// response cache opt-out and the structured output mode
+func configureAIClient(client *mcp.Client, config AutoTraderConfig) {
+	if seconds, ok := config.AIRequest.TimeoutSeconds[string(client.Provider)]; ok && seconds > 0 {
+		client.SetTimeout(time.Duration(seconds) * time.Second)
+		logging.ForTrader(config.ID, config.Name).Infof("⏱  AI request timeout (%s): %ds", client.Provider, seconds)
+	}
+
+	if len(config.AIRequest.Prices) > 0 {
+		prices := make(map[string]mcp.ModelPrice, len(config.AIRequest.Prices))
+		for model, price := range config.AIRequest.Prices {
+			prices[model] = mcp.ModelPrice{InputPerMillion: price.InputPerMillion, OutputPerMillion: price.OutputPerMillion}
+		}
+		client.SetPrices(prices)
+	}
+
+	client.Retry = mcp.RetryPolicy{
+		MaxAttempts:    config.AIRequest.MaxAttempts,
+		InitialBackoff: time.Duration(config.AIRequest.BackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(config.AIRequest.MaxBackoffSeconds) * time.Second,
+	}
+	client.DisableCache = config.DisableAICache
+	if config.AIOutput != "text" {
+		client.OutputMode = mcp.OutputMode(config.AIOutput)
+	}
+}
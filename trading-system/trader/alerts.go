@@ -0,0 +1,72 @@
+package trader
+
+import (
+	"fmt"
+	"lia/alert"
+	decisionPkg "lia/decision"
+	"math"
+	"strings"
+)
+
+// authErrorMarkers error texts exchanges return for rejected credentials (Binance -2014/-2015/-1022, OKX
+// 50111/50113, Bybit 10003/10004, HTTP 401)
+var authErrorMarkers = []string{
+	"code=-2014", "code=-2015", "code=-1022", "\"code\":-2014", "\"code\":-2015", "\"code\":-1022",
+	"50111", "50113", "retcode 10003", "retcode 10004", "retCode\":10003", "retCode\":10004",
+	"invalid api-key", "invalid api key", "api-key format invalid", "signature for this request is not valid",
+	"invalid signature", "unauthorized", "status 401", "401 unauthorized",
+}
+
+// isAuthError whether an exchange error means the API credentials were rejected
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range authErrorMarkers {
+		if strings.Contains(msg, strings.ToLower(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// alertExchangeError emails an exchange authentication failure (other errors are left to the cycle's logging)
+func (at *AutoTrader) alertExchangeError(operation string, err error) {
+	if !isAuthError(err) {
+		return
+	}
+	alert.Critical("exchange_auth:"+at.id,
+		fmt.Sprintf("%s: %s authentication failed", at.name, at.exchange),
+		fmt.Sprintf("Trader %s (%s) could not %s: the exchange rejected its API credentials.\n\n"+
+			"The trader cannot trade or manage its open positions until the key is fixed.\n\nError: %v",
+			at.name, at.id, operation, err))
+}
+
+// alertLiquidationRisk emails positions whose mark price is close to their liquidation price and high margin usage
+func (at *AutoTrader) alertLiquidationRisk(positions []decisionPkg.PositionInfo, totalEquity, marginUsedPct float64) {
+	settings := alert.Settings()
+	for _, pos := range positions {
+		if pos.LiquidationPrice <= 0 || pos.MarkPrice <= 0 {
+			continue
+		}
+		distancePct := math.Abs(pos.MarkPrice-pos.LiquidationPrice) / pos.MarkPrice * 100
+		if distancePct > settings.LiqDistance {
+			continue
+		}
+		alert.Critical(fmt.Sprintf("liquidation:%s:%s_%s", at.id, pos.Symbol, pos.Side),
+			fmt.Sprintf("%s: %s %s %.1f%% from liquidation", at.name, pos.Symbol, strings.ToUpper(pos.Side), distancePct),
+			fmt.Sprintf("Trader %s (%s) %s %s position is close to liquidation.\n\n"+
+				"Mark price: %.4f\nLiquidation price: %.4f (%.2f%% away)\nEntry price: %.4f\nQuantity: %.4f\nLeverage: %dx\n"+
+				"Unrealized P&L: %.2f USDT (%.2f%%)",
+				at.name, at.id, pos.Symbol, pos.Side, pos.MarkPrice, pos.LiquidationPrice, distancePct, pos.EntryPrice,
+				pos.Quantity, pos.Leverage, pos.UnrealizedPnL, pos.UnrealizedPnLPct))
+	}
+
+	if marginUsedPct >= settings.MarginUsage {
+		alert.Critical("margin:"+at.id,
+			fmt.Sprintf("%s: margin usage %.1f%%", at.name, marginUsedPct),
+			fmt.Sprintf("Trader %s (%s) uses %.1f%% of its equity (%.2f USDT) as margin across %d position(s): "+
+				"little room is left before a margin call.", at.name, at.id, marginUsedPct, totalEquity, len(positions)))
+	}
+}
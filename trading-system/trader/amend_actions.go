@@ -0,0 +1,198 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"strings"
+)
+
+// ErrMarginAdjustUnsupported returned when the exchange cannot add margin to an open position
+var ErrMarginAdjustUnsupported = errors.New("adding margin to a position is not supported on this exchange")
+
+// MarginAdjuster optional interface for exchanges that can add margin to an isolated position
+type MarginAdjuster interface {
+	// AddPositionMargin adds amount USDT of margin to the position (positionSide: "LONG" or "SHORT")
+	AddPositionMargin(symbol, positionSide string, amount float64) error
+}
+
+// amendTarget the live position an amend action applies to
+type amendTarget struct {
+	side          string // "long" or "short"
+	quantity      float64
+	entryPrice    float64
+	markPrice     float64
+	unrealizedPnl float64
+}
+
+// executeAmendWithRecord executes adjust_stop / adjust_target / add_margin / reduce_size
+func (at *AutoTrader) executeAmendWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
+	target, err := at.findAmendTarget(decision.Symbol, decision.Side)
+	if err != nil {
+		return err
+	}
+	actionRecord.Price = target.markPrice
+
+	switch decision.Action {
+	case "adjust_stop":
+		return at.executeAdjustStop(decision, target)
+	case "adjust_target":
+		return at.executeAdjustTarget(decision, target)
+	case "add_margin":
+		return at.executeAddMargin(decision, target)
+	case "reduce_size":
+		return at.executeReduceSize(decision, target, actionRecord)
+	default:
+		return fmt.Errorf("unknown amend action: %s", decision.Action)
+	}
+}
+
+// findAmendTarget finds the position to amend (side may be empty if the symbol has a single position)
+func (at *AutoTrader) findAmendTarget(symbol, side string) (*amendTarget, error) {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	side = strings.ToLower(side)
+	var matches []*amendTarget
+	for _, pos := range positions {
+		if pos.Symbol != symbol || (side != "" && pos.Side != side) {
+			continue
+		}
+		matches = append(matches, &amendTarget{
+			side:          pos.Side,
+			quantity:      pos.Quantity,
+			entryPrice:    pos.EntryPrice,
+			markPrice:     pos.MarkPrice,
+			unrealizedPnl: pos.UnrealizedProfit,
+		})
+	}
+
+	if len(matches) == 0 {
+		if side == "" {
+			return nil, fmt.Errorf("no position found for %s (may have been closed by another trader)", symbol)
+		}
+		return nil, fmt.Errorf("no %s position found for %s (may have been closed by another trader)", side, symbol)
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("%s has both long and short positions - side is required", symbol)
+	}
+	return matches[0], nil
+}
+
+// executeAdjustStop moves the stop: anywhere on the losing side of the price when stops are honored, otherwise
+// only to a breakeven-or-better level (losing positions are never closed)
+func (at *AutoTrader) executeAdjustStop(decision *decisionPkg.Decision, target *amendTarget) error {
+	stop := decision.StopLoss
+	at.log.Infof("  🛡️ Adjusting stop: %s %s → %.4f (entry %.4f, mark %.4f)",
+		decision.Symbol, strings.ToUpper(target.side), stop, target.entryPrice, target.markPrice)
+
+	if target.side == "long" {
+		if stop >= target.markPrice {
+			return fmt.Errorf("long stop %.4f must be below current price %.4f", stop, target.markPrice)
+		}
+		if stop < target.entryPrice && !at.honorsStops() {
+			return fmt.Errorf("long stop %.4f is below entry %.4f - stops may only lock in breakeven or profit", stop, target.entryPrice)
+		}
+	} else {
+		if stop <= target.markPrice {
+			return fmt.Errorf("short stop %.4f must be above current price %.4f", stop, target.markPrice)
+		}
+		if stop > target.entryPrice && !at.honorsStops() {
+			return fmt.Errorf("short stop %.4f is above entry %.4f - stops may only lock in breakeven or profit", stop, target.entryPrice)
+		}
+	}
+
+	at.positionProtection.update(decision.Symbol, target.side, func(levels *ProtectedPosition) { levels.StopLoss = stop })
+	at.refreshProtectionOrders(decision.Symbol)
+	at.log.Infof("  ✓ Stop updated")
+	return nil
+}
+
+// executeAdjustTarget moves the take profit level
+func (at *AutoTrader) executeAdjustTarget(decision *decisionPkg.Decision, target *amendTarget) error {
+	takeProfit := decision.TakeProfit
+	at.log.Infof("  🎯 Adjusting target: %s %s → %.4f (mark %.4f)",
+		decision.Symbol, strings.ToUpper(target.side), takeProfit, target.markPrice)
+
+	if target.side == "long" && takeProfit <= target.markPrice {
+		return fmt.Errorf("long take profit %.4f must be above current price %.4f", takeProfit, target.markPrice)
+	}
+	if target.side == "short" && takeProfit >= target.markPrice {
+		return fmt.Errorf("short take profit %.4f must be below current price %.4f", takeProfit, target.markPrice)
+	}
+
+	at.positionProtection.update(decision.Symbol, target.side, func(levels *ProtectedPosition) { levels.TakeProfit = takeProfit })
+	at.refreshProtectionOrders(decision.Symbol)
+	at.log.Infof("  ✓ Target updated")
+	return nil
+}
+
+// executeAddMargin adds margin to an existing position
+func (at *AutoTrader) executeAddMargin(decision *decisionPkg.Decision, target *amendTarget) error {
+	amount := decision.PositionSizeUSD
+	at.log.Infof("  💰 Adding margin: %s %s +%.2f USDT", decision.Symbol, strings.ToUpper(target.side), amount)
+
+	adjuster, ok := baseTrader(at.trader).(MarginAdjuster)
+	if !ok {
+		return fmt.Errorf("%w (%s)", ErrMarginAdjustUnsupported, at.exchange)
+	}
+
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return fmt.Errorf("failed to fetch balance before add_margin %s: %w", decision.Symbol, err)
+	}
+	available := balance.AvailableBalance
+	if amount > available-marginSafetyBuffer {
+		return fmt.Errorf("%w: cannot add %.2f USDT margin (available %.2f USDT, buffer %.2f USDT)",
+			ErrMarginInsufficient, amount, available, marginSafetyBuffer)
+	}
+
+	if err := adjuster.AddPositionMargin(decision.Symbol, strings.ToUpper(target.side), amount); err != nil {
+		return err
+	}
+	at.log.Infof("  ✓ Margin added")
+	return nil
+}
+
+// executeReduceSize closes part of a position (same profitability rule as full closes)
+func (at *AutoTrader) executeReduceSize(decision *decisionPkg.Decision, target *amendTarget, actionRecord *logger.DecisionAction) error {
+	side := strings.ToUpper(target.side)
+	at.log.Infof("  ✂️ Reducing position: %s %s by %.0f%%", decision.Symbol, side, decision.ReducePct)
+
+	lock := getPositionLock(decision.Symbol, side)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if target.unrealizedPnl < 0 {
+		at.log.Warnf("  ⚠️ Position %s %s has negative P&L (%.2f USDT) - not reducing", decision.Symbol, side, target.unrealizedPnl)
+		return fmt.Errorf("position is losing money (P&L: %.2f USDT) - holding until profitable. Only reduce profitable positions", target.unrealizedPnl)
+	}
+
+	quantity := target.quantity * decision.ReducePct / 100
+	actionRecord.Quantity = quantity
+
+	var order *Order
+	var err error
+	if target.side == "long" {
+		order, err = at.trader.CloseLong(decision.Symbol, quantity)
+	} else {
+		order, err = at.trader.CloseShort(decision.Symbol, quantity)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reduce %s %s: %w", decision.Symbol, side, err)
+	}
+
+	actionRecord.OrderID = order.OrderID
+	actionRecord.ClientOrderID = order.ClientOrderID
+	actionRecord.Fee = order.Fee
+	actionRecord.RealizedPnL = order.RealizedPnL
+
+	// Closing cancels the symbol's open orders on the exchange - restore stop/target for the remainder
+	at.refreshProtectionOrders(decision.Symbol)
+
+	at.log.Infof("  ✓ Position reduced by %.4f", quantity)
+	return nil
+}
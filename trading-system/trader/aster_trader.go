@@ -8,8 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
-	"math"
+	"lia/logging"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -24,6 +23,9 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// asterLog logs the Aster adapter
+var asterLog = logging.Component("aster")
+
 // AsterTrader Aster交易平台实现
 type AsterTrader struct {
 	ctx        context.Context
@@ -44,6 +46,9 @@ type SymbolPrecision struct {
 	QuantityPrecision int
 	TickSize          float64 // 价格步进值
 	StepSize          float64 // 数量步进值
+	MinQty            float64 // Smallest order quantity
+	MaxQty            float64 // Largest market order quantity
+	MinNotional       float64 // Smallest order value (USDT)
 }
 
 // NewAsterTrader 创建Aster交易器
@@ -80,6 +85,20 @@ func (t *AsterTrader) genNonce() uint64 {
 	return uint64(time.Now().UnixMicro())
 }
 
+// WarmUp loads the exchange precision table and the balance/position caches before the first cycle
+func (t *AsterTrader) WarmUp() error {
+	if _, err := t.getPrecision("BTCUSDT"); err != nil {
+		return fmt.Errorf("exchange info: %w", err)
+	}
+	if _, err := t.GetBalance(); err != nil {
+		return fmt.Errorf("balance: %w", err)
+	}
+	if _, err := t.GetPositions(); err != nil {
+		return fmt.Errorf("positions: %w", err)
+	}
+	return nil
+}
+
 // getPrecision 获取交易对精度信息
 func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	t.mu.RLock()
@@ -130,6 +149,16 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 				if stepSizeStr, ok := filter["stepSize"].(string); ok {
 					prec.StepSize, _ = strconv.ParseFloat(stepSizeStr, 64)
 				}
+				prec.MinQty = parseFilterValue(filter["minQty"])
+				if prec.MaxQty == 0 {
+					prec.MaxQty = parseFilterValue(filter["maxQty"])
+				}
+			case "MARKET_LOT_SIZE":
+				if maxQty := parseFilterValue(filter["maxQty"]); maxQty > 0 {
+					prec.MaxQty = maxQty
+				}
+			case "MIN_NOTIONAL":
+				prec.MinNotional = parseFilterValue(filter["notional"])
 			}
 		}
 
@@ -144,17 +173,28 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	return SymbolPrecision{}, fmt.Errorf("未找到交易对 %s 的精度信息", symbol)
 }
 
+// SymbolFilters returns the symbol's quantity, price and order value filters
+func (t *AsterTrader) SymbolFilters(symbol string) (SymbolFilters, error) {
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return SymbolFilters{}, err
+	}
+	return SymbolFilters{
+		StepSize:    prec.StepSize,
+		MinQty:      prec.MinQty,
+		MaxQty:      prec.MaxQty,
+		TickSize:    prec.TickSize,
+		MinNotional: prec.MinNotional,
+	}, nil
+}
+
 // roundToTickSize 将价格/数量四舍五入到tick size/step size的整数倍
 func roundToTickSize(value float64, tickSize float64) float64 {
 	if tickSize <= 0 {
 		return value
 	}
-	// 计算有多少个tick size
-	steps := value / tickSize
-	// 四舍五入到最近的整数
-	roundedSteps := math.Round(steps)
-	// 乘回tick size
-	return roundedSteps * tickSize
+	// 四舍五入到最近的tick size整数倍（decimal运算，避免0.1*3=0.30000000000000004之类的误差）
+	return roundToStep(value, tickSize)
 }
 
 // formatPrice 格式化价格到正确精度和tick size
@@ -170,8 +210,8 @@ func (t *AsterTrader) formatPrice(symbol string, price float64) (float64, error)
 	}
 
 	// 如果没有tick size，则按精度四舍五入
-	multiplier := math.Pow10(prec.PricePrecision)
-	return math.Round(price*multiplier) / multiplier, nil
+	rounded, _ := dec(price).Round(int32(prec.PricePrecision)).Float64()
+	return rounded, nil
 }
 
 // formatQuantity 格式化数量到正确精度和step size
@@ -187,8 +227,8 @@ func (t *AsterTrader) formatQuantity(symbol string, quantity float64) (float64,
 	}
 
 	// 如果没有step size，则按精度四舍五入
-	multiplier := math.Pow10(prec.QuantityPrecision)
-	return math.Round(quantity*multiplier) / multiplier, nil
+	rounded, _ := dec(quantity).Round(int32(prec.QuantityPrecision)).Float64()
+	return rounded, nil
 }
 
 // formatFloatWithPrecision 将浮点数格式化为指定精度的字符串（去除末尾的0）
@@ -426,7 +466,7 @@ func (t *AsterTrader) doRequest(method, endpoint string, params map[string]inter
 }
 
 // GetBalance 获取账户余额
-func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
+func (t *AsterTrader) GetBalance() (*Balance, error) {
 	params := make(map[string]interface{})
 	body, err := t.request("GET", "/fapi/v3/balance", params)
 	if err != nil {
@@ -458,44 +498,41 @@ func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
 		}
 	}
 
-	// 返回与Binance相同的字段名，确保AutoTrader能正确解析
-	return map[string]interface{}{
-		"totalWalletBalance":    totalBalance,
-		"availableBalance":      availableBalance,
-		"totalUnrealizedProfit": crossUnPnl,
+	return &Balance{
+		WalletBalance:    totalBalance,
+		AvailableBalance: availableBalance,
+		UnrealizedProfit: crossUnPnl,
 	}, nil
 }
 
 // GetPositions 获取持仓信息
-func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *AsterTrader) GetPositions() ([]Position, error) {
 	params := make(map[string]interface{})
 	body, err := t.request("GET", "/fapi/v3/positionRisk", params)
 	if err != nil {
 		return nil, err
 	}
 
-	var positions []map[string]interface{}
+	var positions []struct {
+		Symbol           string `json:"symbol"`
+		PositionAmt      string `json:"positionAmt"`
+		EntryPrice       string `json:"entryPrice"`
+		MarkPrice        string `json:"markPrice"`
+		UnRealizedProfit string `json:"unRealizedProfit"`
+		Leverage         string `json:"leverage"`
+		LiquidationPrice string `json:"liquidationPrice"`
+	}
 	if err := json.Unmarshal(body, &positions); err != nil {
 		return nil, err
 	}
 
-	result := []map[string]interface{}{}
+	result := []Position{}
 	for _, pos := range positions {
-		posAmtStr, ok := pos["positionAmt"].(string)
-		if !ok {
-			continue
-		}
-
-		posAmt, _ := strconv.ParseFloat(posAmtStr, 64)
+		posAmt := parseFloatString(pos.PositionAmt)
 		if posAmt == 0 {
 			continue // 跳过空仓位
 		}
-
-		entryPrice, _ := strconv.ParseFloat(pos["entryPrice"].(string), 64)
-		markPrice, _ := strconv.ParseFloat(pos["markPrice"].(string), 64)
-		unRealizedProfit, _ := strconv.ParseFloat(pos["unRealizedProfit"].(string), 64)
-		leverageVal, _ := strconv.ParseFloat(pos["leverage"].(string), 64)
-		liquidationPrice, _ := strconv.ParseFloat(pos["liquidationPrice"].(string), 64)
+		leverage, _ := strconv.Atoi(pos.Leverage)
 
 		// 判断方向（与Binance一致）
 		side := "long"
@@ -504,16 +541,15 @@ func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
 			posAmt = -posAmt
 		}
 
-		// 返回与Binance相同的字段名
-		result = append(result, map[string]interface{}{
-			"symbol":            pos["symbol"],
-			"side":              side,
-			"positionAmt":       posAmt,
-			"entryPrice":        entryPrice,
-			"markPrice":         markPrice,
-			"unRealizedProfit":  unRealizedProfit,
-			"leverage":          leverageVal,
-			"liquidationPrice":  liquidationPrice,
+		result = append(result, Position{
+			Symbol:           pos.Symbol,
+			Side:             side,
+			Quantity:         posAmt,
+			EntryPrice:       parseFloatString(pos.EntryPrice),
+			MarkPrice:        parseFloatString(pos.MarkPrice),
+			UnrealizedProfit: parseFloatString(pos.UnRealizedProfit),
+			Leverage:         leverage,
+			LiquidationPrice: parseFloatString(pos.LiquidationPrice),
 		})
 	}
 
@@ -521,10 +557,10 @@ func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
 }
 
 // OpenLong 开多单
-func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
 	// 开仓前先取消所有挂单,防止残留挂单导致仓位叠加
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败(继续开仓): %v", err)
+		asterLog.Warnf("  ⚠ 取消挂单失败(继续开仓): %v", err)
 	}
 
 	// 先设置杠杆
@@ -561,7 +597,7 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
+	asterLog.Infof("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
 		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
 
 	params := map[string]interface{}{
@@ -579,8 +615,8 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	result, err := parseAsterOrder(body)
+	if err != nil {
 		return nil, err
 	}
 
@@ -588,10 +624,10 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 }
 
 // OpenShort 开空单
-func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
 	// 开仓前先取消所有挂单,防止残留挂单导致仓位叠加
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败(继续开仓): %v", err)
+		asterLog.Warnf("  ⚠ 取消挂单失败(继续开仓): %v", err)
 	}
 
 	// 先设置杠杆
@@ -628,7 +664,7 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
+	asterLog.Infof("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
 		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
 
 	params := map[string]interface{}{
@@ -646,8 +682,8 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	result, err := parseAsterOrder(body)
+	if err != nil {
 		return nil, err
 	}
 
@@ -655,7 +691,7 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 }
 
 // CloseLong 平多单
-func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *AsterTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -663,17 +699,14 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "long" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		if pos, ok := findPosition(positions, symbol, "long"); ok {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
 			return nil, fmt.Errorf("没有找到 %s 的多仓", symbol)
 		}
-		log.Printf("  📊 获取到多仓数量: %.8f", quantity)
+		asterLog.Infof("  📊 获取到多仓数量: %.8f", quantity)
 	}
 
 	price, err := t.GetMarketPrice(symbol)
@@ -703,7 +736,7 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
+	asterLog.Infof("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
 		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
 
 	params := map[string]interface{}{
@@ -721,23 +754,23 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	result, err := parseAsterOrder(body)
+	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("✓ 平多仓成功: %s 数量: %s", symbol, qtyStr)
+	asterLog.Infof("✓ 平多仓成功: %s 数量: %s", symbol, qtyStr)
 
 	// 平仓后取消该币种的所有挂单(止损止盈单)
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+		asterLog.Warnf("  ⚠ 取消挂单失败: %v", err)
 	}
 
 	return result, nil
 }
 
 // CloseShort 平空单
-func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *AsterTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -745,18 +778,15 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "short" {
-				// Aster的GetPositions已经将空仓数量转换为正数，直接使用
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		// Aster的GetPositions已经将空仓数量转换为正数，直接使用
+		if pos, ok := findPosition(positions, symbol, "short"); ok {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
 			return nil, fmt.Errorf("没有找到 %s 的空仓", symbol)
 		}
-		log.Printf("  📊 获取到空仓数量: %.8f", quantity)
+		asterLog.Infof("  📊 获取到空仓数量: %.8f", quantity)
 	}
 
 	price, err := t.GetMarketPrice(symbol)
@@ -786,7 +816,7 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
+	asterLog.Infof("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
 		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
 
 	params := map[string]interface{}{
@@ -804,16 +834,16 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	result, err := parseAsterOrder(body)
+	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("✓ 平空仓成功: %s 数量: %s", symbol, qtyStr)
+	asterLog.Infof("✓ 平空仓成功: %s 数量: %s", symbol, qtyStr)
 
 	// 平仓后取消该币种的所有挂单(止损止盈单)
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+		asterLog.Warnf("  ⚠ 取消挂单失败: %v", err)
 	}
 
 	return result, nil
@@ -830,6 +860,27 @@ func (t *AsterTrader) SetLeverage(symbol string, leverage int) error {
 	return err
 }
 
+// parseAsterOrder parses an order response (same fields as Binance)
+func parseAsterOrder(body []byte) (*Order, error) {
+	var resp struct {
+		OrderID       int64  `json:"orderId"`
+		ClientOrderID string `json:"clientOrderId"`
+		Symbol        string `json:"symbol"`
+		Status        string `json:"status"`
+		AvgPrice      string `json:"avgPrice"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return &Order{
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        resp.Symbol,
+		Status:        resp.Status,
+		Price:         parseFloatString(resp.AvgPrice),
+	}, nil
+}
+
 // GetMarketPrice 获取市场价格
 func (t *AsterTrader) GetMarketPrice(symbol string) (float64, error) {
 	// 使用ticker接口获取当前价格
@@ -957,3 +1008,43 @@ func (t *AsterTrader) FormatQuantity(symbol string, quantity float64) (string, e
 	}
 	return fmt.Sprintf("%v", formatted), nil
 }
+
+// ConfigureLeverage sets the requested leverage for many symbols ahead of trading and reads the settings back
+// (Aster orders do not switch margin mode, so the account's margin mode is left as is)
+func (t *AsterTrader) ConfigureLeverage(requested map[string]int) []LeverageSetting {
+	settings := make([]LeverageSetting, 0, len(requested))
+	for symbol, leverage := range requested {
+		setting := LeverageSetting{Symbol: symbol, Requested: leverage}
+		if err := t.SetLeverage(symbol, leverage); err != nil {
+			setting.Error = fmt.Sprintf("failed to set leverage: %v", err)
+		}
+		settings = append(settings, setting)
+	}
+
+	// Verify: positionRisk reports every symbol's leverage, with or without a position
+	body, err := t.request("GET", "/fapi/v3/positionRisk", map[string]interface{}{})
+	if err != nil {
+		asterLog.Warnf("  ⚠ Failed to verify leverage settings: %v", err)
+		return settings
+	}
+	var risks []struct {
+		Symbol     string `json:"symbol"`
+		Leverage   string `json:"leverage"`
+		MarginType string `json:"marginType"`
+	}
+	if err := json.Unmarshal(body, &risks); err != nil {
+		asterLog.Warnf("  ⚠ Failed to parse leverage settings: %v", err)
+		return settings
+	}
+	reported := make(map[string]int, len(risks))
+	marginTypes := make(map[string]string, len(risks))
+	for _, risk := range risks {
+		reported[risk.Symbol], _ = strconv.Atoi(risk.Leverage)
+		marginTypes[risk.Symbol] = strings.ToLower(risk.MarginType)
+	}
+	for i := range settings {
+		settings[i].Applied = reported[settings[i].Symbol]
+		settings[i].MarginType = marginTypes[settings[i].Symbol]
+	}
+	return settings
+}
@@ -0,0 +1,368 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/market"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	whatIfGhostMaxHold = 24 * time.Hour // A variant still holding a position the monitor closed is marked to market after this
+	whatIfMaxPoints    = 5000           // Curve points kept per trader (one per decision cycle)
+)
+
+// whatIfLeg a tracked position's outcome under one alternative threshold
+type whatIfLeg struct {
+	Closed   bool      `json:"closed"`
+	PnL      float64   `json:"pnl"` // Final P&L once closed, otherwise the current P&L the variant would hold
+	ClosedAt time.Time `json:"closed_at,omitempty"`
+}
+
+// whatIfPosition a live position followed for the what-if curves. After the real position closes, variants
+// with a higher threshold than the one that closed it keep holding a "ghost" position marked to market
+type whatIfPosition struct {
+	Symbol       string                `json:"symbol"`
+	Side         string                `json:"side"`
+	EntryPrice   float64               `json:"entry_price"`
+	Quantity     float64               `json:"quantity"`
+	Leverage     float64               `json:"leverage"`
+	StopLoss     float64               `json:"stop_loss,omitempty"` // Last known stop (ends a ghost position)
+	OpenedAt     time.Time             `json:"opened_at"`
+	RealPnL      float64               `json:"real_pnl"` // Unrealized P&L, frozen at the last observation once closed (before fees)
+	RealClosed   bool                  `json:"real_closed"`
+	RealClosedAt time.Time             `json:"real_closed_at,omitempty"`
+	AutoClosed   bool                  `json:"auto_closed"` // Closed by the background auto-close monitor
+	Legs         map[string]*whatIfLeg `json:"legs"`
+}
+
+// WhatIfPoint cumulative P&L difference of each variant versus the real trader at a decision cycle
+type WhatIfPoint struct {
+	Time   time.Time          `json:"time"`
+	Cycle  int                `json:"cycle"`
+	Deltas map[string]float64 `json:"deltas"` // Variant key → USDT to add to the real equity
+}
+
+// whatIfState persisted what-if state
+type whatIfState struct {
+	Positions []*whatIfPosition  `json:"positions"`
+	Settled   map[string]float64 `json:"settled"` // Differences of positions whose every outcome is final
+	Points    []WhatIfPoint      `json:"points"`
+}
+
+// AutoCloseWhatIf follows live positions to compute hypothetical equity curves for alternative
+// auto-close thresholds next to the real one
+type AutoCloseWhatIf struct {
+	thresholds []float64
+	live       float64 // The trader's real threshold (negative = the monitor never closes positions)
+	path       string
+	mu         sync.Mutex
+	state      whatIfState
+}
+
+// NewAutoCloseWhatIf creates a what-if tracker for thresholds (leveraged P&L %) next to the live threshold,
+// persisted at path
+func NewAutoCloseWhatIf(thresholds []float64, live float64, path string) *AutoCloseWhatIf {
+	w := &AutoCloseWhatIf{thresholds: thresholds, live: live, path: path}
+	if err := w.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load auto-close what-if state (%s): %v", path, err)
+		w.state = whatIfState{}
+	}
+	if w.state.Settled == nil {
+		w.state.Settled = make(map[string]float64)
+	}
+	return w
+}
+
+// WhatIfVariantKey the key of a threshold in curves and the API (e.g. 3 → "3", 6.5 → "6.5")
+func WhatIfVariantKey(threshold float64) string {
+	return strconv.FormatFloat(threshold, 'f', -1, 64)
+}
+
+// Variants returns the keys of the tracked thresholds
+func (w *AutoCloseWhatIf) Variants() []string {
+	keys := make([]string, 0, len(w.thresholds))
+	for _, threshold := range w.thresholds {
+		keys = append(keys, WhatIfVariantKey(threshold))
+	}
+	return keys
+}
+
+// ResolveVariant the key of a tracked threshold given as "3", "3.0" or "3%" (ok=false if not tracked)
+func (w *AutoCloseWhatIf) ResolveVariant(variant string) (key string, ok bool) {
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(variant), "%"), 64)
+	if err != nil {
+		return "", false
+	}
+	key = WhatIfVariantKey(threshold)
+	for _, known := range w.Variants() {
+		if known == key {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// observe updates tracked positions from a live position snapshot (called by the background monitor)
+func (w *AutoCloseWhatIf) observe(positions []Position, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changed := false
+
+	seen := make(map[*whatIfPosition]bool)
+	for _, pos := range positions {
+		symbol, side := pos.Symbol, pos.Side
+		entryPrice, markPrice := pos.EntryPrice, pos.MarkPrice
+		unrealizedPnl := pos.UnrealizedProfit
+		leverage := float64(pos.Leverage)
+		if leverage == 0 {
+			leverage = 7 // Same default as the auto-close monitor
+		}
+		if entryPrice <= 0 || pos.Quantity == 0 {
+			continue
+		}
+
+		p := w.openPosition(symbol, side)
+		if p == nil {
+			p = &whatIfPosition{Symbol: symbol, Side: side, OpenedAt: now, Legs: make(map[string]*whatIfLeg)}
+			for _, key := range w.Variants() {
+				p.Legs[key] = &whatIfLeg{}
+			}
+			w.state.Positions = append(w.state.Positions, p)
+			changed = true
+		}
+		seen[p] = true
+		p.EntryPrice, p.Quantity, p.Leverage = entryPrice, pos.Quantity, leverage
+		p.RealPnL = unrealizedPnl
+
+		pnlPct := leveragedPnLPct(side, entryPrice, markPrice, leverage)
+		for _, threshold := range w.thresholds {
+			leg := p.Legs[WhatIfVariantKey(threshold)]
+			if leg == nil || leg.Closed {
+				continue
+			}
+			leg.PnL = unrealizedPnl
+			// Same rule as the real monitor, at this variant's threshold
+			if unrealizedPnl > 0 && pnlPct >= threshold {
+				leg.Closed, leg.ClosedAt = true, now
+				changed = true
+			}
+		}
+	}
+
+	for _, p := range w.state.Positions {
+		if p.RealClosed || seen[p] {
+			continue
+		}
+		// The real position closed since the last observation
+		p.RealClosed, p.RealClosedAt = true, now
+		changed = true
+		for _, threshold := range w.thresholds {
+			leg := p.Legs[WhatIfVariantKey(threshold)]
+			if leg == nil || leg.Closed {
+				continue
+			}
+			if p.AutoClosed && threshold > w.live {
+				continue // Keeps holding: this variant's target was not reached yet
+			}
+			// Closed by the AI or a stop: every variant would have closed with it
+			leg.Closed, leg.PnL, leg.ClosedAt = true, p.RealPnL, now
+		}
+	}
+
+	if w.markGhosts(now) {
+		changed = true
+	}
+	if w.settle() {
+		changed = true
+	}
+	if changed {
+		w.save()
+	}
+}
+
+// markGhosts marks variant positions the real trader already closed to market, closing them at their
+// threshold, their stop or after whatIfGhostMaxHold (caller holds mu)
+func (w *AutoCloseWhatIf) markGhosts(now time.Time) (changed bool) {
+	for _, p := range w.state.Positions {
+		if !p.RealClosed {
+			continue
+		}
+		price := 0.0
+		for _, threshold := range w.thresholds {
+			leg := p.Legs[WhatIfVariantKey(threshold)]
+			if leg == nil || leg.Closed {
+				continue
+			}
+			if price == 0 {
+				data, err := market.Get(p.Symbol)
+				if err != nil || data.CurrentPrice <= 0 {
+					break // Retry next observation
+				}
+				price = data.CurrentPrice
+			}
+			if p.Side == "long" {
+				leg.PnL = p.Quantity * (price - p.EntryPrice)
+			} else {
+				leg.PnL = p.Quantity * (p.EntryPrice - price)
+			}
+			stopped := p.StopLoss > 0 && ((p.Side == "long" && price <= p.StopLoss) || (p.Side == "short" && price >= p.StopLoss))
+			if leveragedPnLPct(p.Side, p.EntryPrice, price, p.Leverage) >= threshold || stopped ||
+				now.Sub(p.RealClosedAt) >= whatIfGhostMaxHold {
+				leg.Closed, leg.ClosedAt = true, now
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// settle moves positions whose real and variant outcomes are all final into the settled totals (caller holds mu)
+func (w *AutoCloseWhatIf) settle() (changed bool) {
+	kept := w.state.Positions[:0]
+	for _, p := range w.state.Positions {
+		final := p.RealClosed
+		for _, leg := range p.Legs {
+			final = final && leg.Closed
+		}
+		if !final {
+			kept = append(kept, p)
+			continue
+		}
+		for key, leg := range p.Legs {
+			w.state.Settled[key] += leg.PnL - p.RealPnL
+		}
+		changed = true
+	}
+	w.state.Positions = kept
+	return changed
+}
+
+// markAutoClosed flags the open position the monitor just closed (higher-threshold variants keep holding it)
+func (w *AutoCloseWhatIf) markAutoClosed(symbol, side string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if p := w.openPosition(symbol, strings.ToLower(side)); p != nil {
+		p.AutoClosed = true
+	}
+}
+
+// openPosition the tracked position for symbol/side whose real position is still open (caller holds mu)
+func (w *AutoCloseWhatIf) openPosition(symbol, side string) *whatIfPosition {
+	for _, p := range w.state.Positions {
+		if !p.RealClosed && p.Symbol == symbol && p.Side == side {
+			return p
+		}
+	}
+	return nil
+}
+
+// record appends a curve point for a decision cycle. stopOf returns a position's current stop (0 = none);
+// it is remembered so a ghost position can be stopped out
+func (w *AutoCloseWhatIf) record(cycle int, now time.Time, stopOf func(symbol, side string) float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, p := range w.state.Positions {
+		if !p.RealClosed {
+			if stop := stopOf(p.Symbol, p.Side); stop > 0 {
+				p.StopLoss = stop
+			}
+		}
+	}
+	w.state.Points = append(w.state.Points, WhatIfPoint{Time: now, Cycle: cycle, Deltas: w.deltas()})
+	if len(w.state.Points) > whatIfMaxPoints {
+		w.state.Points = w.state.Points[len(w.state.Points)-whatIfMaxPoints:]
+	}
+	w.save()
+}
+
+// deltas current difference of each variant versus the real trader (caller holds mu)
+func (w *AutoCloseWhatIf) deltas() map[string]float64 {
+	deltas := make(map[string]float64, len(w.thresholds))
+	for _, key := range w.Variants() {
+		deltas[key] = w.state.Settled[key]
+		for _, p := range w.state.Positions {
+			if leg := p.Legs[key]; leg != nil {
+				deltas[key] += leg.PnL - p.RealPnL
+			}
+		}
+	}
+	return deltas
+}
+
+// DeltaAt the variant's difference versus the real equity at t (the latest cycle point at or before t;
+// 0 before tracking started)
+func (w *AutoCloseWhatIf) DeltaAt(variant string, t time.Time) float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := sort.Search(len(w.state.Points), func(i int) bool { return w.state.Points[i].Time.After(t) })
+	if i == 0 {
+		return 0
+	}
+	return w.state.Points[i-1].Deltas[variant]
+}
+
+// Current returns each variant's difference versus the real equity right now
+func (w *AutoCloseWhatIf) Current() map[string]float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.deltas()
+}
+
+// load reads persisted state (a missing file is not an error)
+func (w *AutoCloseWhatIf) load() error {
+	data, err := os.ReadFile(w.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &w.state); err != nil {
+		return fmt.Errorf("failed to parse auto-close what-if state: %w", err)
+	}
+	return nil
+}
+
+// save persists the state (caller holds mu); failures are logged
+func (w *AutoCloseWhatIf) save() {
+	data, err := json.Marshal(w.state)
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize auto-close what-if state: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create auto-close what-if directory: %v", err)
+		return
+	}
+	tmpPath := w.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write auto-close what-if state: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace auto-close what-if file: %v", err)
+	}
+}
+
+// leveragedPnLPct position P&L % on margin (price move × leverage), as used by the auto-close monitor
+func leveragedPnLPct(side string, entryPrice, price, leverage float64) float64 {
+	if entryPrice <= 0 {
+		return 0
+	}
+	if strings.ToLower(side) == "long" {
+		return (price - entryPrice) / entryPrice * 100 * leverage
+	}
+	return (entryPrice - price) / entryPrice * 100 * leverage
+}
+
+// GetAutoCloseWhatIf returns the auto-close what-if tracker (nil = disabled)
+func (at *AutoTrader) GetAutoCloseWhatIf() *AutoCloseWhatIf {
+	return at.autoCloseWhatIf
+}
@@ -1,19 +1,24 @@
 package trader
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"lia/config"
 	decisionPkg "lia/decision"
 	"lia/logger"
+	"lia/logging"
 	"lia/market"
 	"lia/mcp"
 	multiagent "lia/multi-agent"
+	"lia/news"
 	"lia/pool"
-	"log"
+	"lia/sim"
+	"math"
 	"math/rand"
-	"strconv"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +30,9 @@ var (
 	positionLocksMutex   sync.Mutex                     // Protects the map itself
 )
 
+// traderLog logs the trader package's lines that belong to no one trader
+var traderLog = logging.Component("trader")
+
 var ErrMarginInsufficient = errors.New("margin insufficient for order")
 
 const (
@@ -55,10 +63,10 @@ type AutoTraderConfig struct {
 	// Trader identification
 	ID      string // Trader unique identifier (for log directories, etc.)
 	Name    string // Trader display name
-	AIModel string // AI model: "groq", "qwen", "deepseek", or "custom"
+	AIModel string // AI model: "groq", "qwen", "deepseek", "anthropic", "gemini" or "custom"
 
 	// Trading platform selection
-	Exchange string // "binance", "hyperliquid", "aster", "paper", "simulate", or "demo"
+	Exchange string // "binance", "hyperliquid", "aster", "okx", "bybit", "paper", "simulate", or "demo"
 
 	// Binance API configuration
 	BinanceAPIKey    string
@@ -74,6 +82,16 @@ type AutoTraderConfig struct {
 	AsterSigner     string // Aster API wallet address
 	AsterPrivateKey string // Aster API wallet private key
 
+	// OKX configuration
+	OKXAPIKey     string
+	OKXSecretKey  string
+	OKXPassphrase string
+
+	// Bybit configuration
+	BybitAPIKey    string
+	BybitSecretKey string
+	BybitTestnet   bool
+
 	CoinPoolAPIURL string
 
 	// AI configuration
@@ -83,11 +101,26 @@ type AutoTraderConfig struct {
 	GroqKey     string
 	GroqModel   string // Groq model name
 
+	// Anthropic Claude and Google Gemini
+	AnthropicKey   string
+	AnthropicModel string
+	GeminiKey      string
+	GeminiModel    string
+
 	// Custom AI API configuration
 	CustomAPIURL    string
 	CustomAPIKey    string
 	CustomModelName string
 
+	// Providers tried in order when AIModel still fails after its retries
+	AIFailover []string
+
+	// Bypass the shared AI response cache
+	DisableAICache bool
+
+	// Structured decision output: "text", "json", "json_schema" or "tool"
+	AIOutput string
+
 	// Scanning configuration
 	ScanInterval time.Duration // Scan interval (recommended 3 minutes)
 
@@ -107,32 +140,176 @@ type AutoTraderConfig struct {
 	AutoTakeProfitPct float64 // Auto close at this P&L % (0 = disabled, 1.0 = 1%)
 
 	// Copy trading: if set, this trader will copy decisions from another trader
-	CopyFromTraderID string // ID of trader to copy from
+	CopyFromTraderID string                    // ID of trader to copy from
+	CopyTrading      *config.CopyTradingConfig // Freshness, price band and sizing (nil = any age, equity-proportional)
+
+	// External signal feed (POST /api/signals/:trader_id; nil = off)
+	SignalWebhook *config.SignalWebhookConfig
+
+	// Memory footprint controls (see config.LowMemoryConfig)
+	AI500Limit          int  // AI500 top N coins merged into candidate pool (0 = default 20)
+	MaxCandidateCoins   int  // Hard cap on candidate coins per cycle (0 = unlimited)
+	PerformanceLookback int  // Cycles loaded for performance analysis (0 = default 100)
+	DropRawResponse     bool // Don't retain raw AI responses in decision records
+
+	// Open interest value (USD) below which candidates are skipped (0 = default 15M, < 0 = no filter)
+	MinOIUSD float64
+
+	// Candidate pool biasing by per-symbol track record
+	AdaptivePool config.AdaptivePoolConfig
+
+	// Retrieval of relevant past trades for the prompt
+	TradeMemory config.TradeMemoryConfig
+
+	// Order book driven fills for large paper orders
+	PaperFills config.PaperFillConfig
+
+	// Borrow limits, borrow interest and forced buy-ins for paper shorts
+	PaperShorts config.PaperShortConfig
+
+	// Fees, slippage and funding payments of paper fills
+	PaperCosts config.PaperCostConfig
+
+	// Price paths, clock and scripted AI of the simulate exchange mode (nil unless exchange is "simulate")
+	Simulation *config.SimulationConfig
+
+	// Self-termination conditions (nil = trade until stopped)
+	EndConditions *config.EndConditionsConfig
+
+	// AI request timeouts and compact-prompt retry
+	AIRequest config.AIRequestConfig
+
+	// What-if equity curves for alternative auto-close thresholds
+	AutoCloseWhatIf config.AutoCloseWhatIfConfig
+
+	// Minimum confidence for opens derived from calibration history
+	AdaptiveConfidence config.AdaptiveConfidenceConfig
+
+	// Decision log text retention (stripped to summaries after FullTextDays, 0 = keep full text)
+	DecisionRetention config.DecisionRetentionConfig
+
+	// Candle-close aligned cycle triggers (nil = free-running ticker every ScanInterval)
+	CycleAlignment *config.CycleAlignmentConfig
+
+	// Early cycles on market and position events (nil = schedule only)
+	CycleTriggers *config.CycleTriggersConfig
+
+	// Fixed-schedule equity snapshots (nil = off)
+	EquitySnapshots *config.EquitySnapshotsConfig
+
+	// Decision strategy ("" / "ai" = the LLM engine) and its settings
+	Strategy       string
+	StrategyParams json.RawMessage
+
+	// Stop loss handling: config.StopLossHonorStops places stop orders on opens ("" = never close losers)
+	StopLossMode string
+
+	// Reject opens against an opposite position on the same symbol
+	ProhibitHedging bool
+
+	// Log orders instead of sending them to the exchange (real balances, positions and prices)
+	DryRun bool
+
+	// Paper account repeating every order, for live vs paper execution comparisons (nil = off)
+	PaperShadow *config.PaperShadowConfig
+
+	// Background position monitor
+	BackgroundTakeProfitPct float64       // Close positions at this leveraged P&L % (0 = default 4.5, negative = off)
+	MonitorInterval         time.Duration // How often the monitor checks positions (0 = default 10s)
+
+	// Limit / post_only entries offered to the AI, cancelled when still unfilled after this (0 = market only)
+	LimitOrderTimeout time.Duration
+
+	// Extra candle timeframes in the prompt and its token budget (nil = 3m/4h only, no limit)
+	PromptData *config.PromptDataConfig
+
+	// Directory of the prompt templates ("" = built-in) and where each template version in use is stored
+	PromptTemplateDir string
+	PromptVersionsDir string
+
+	// Second-stage review of the opens before execution (nil = not configured)
+	RiskOfficer *config.RiskOfficerConfig
+
+	// Limits decisions are validated against (nil = decision.DefaultRiskPolicy)
+	RiskPolicy *config.RiskPolicyConfig
 }
 
 // SupabaseConfig configuration for Supabase database (aliased from logger package)
 type SupabaseConfig = logger.SupabaseConfig
 
+// WriteQueueOptions asynchronous Supabase writes (aliased from logger package)
+type WriteQueueOptions = logger.WriteQueueOptions
+
 // AutoTrader Auto trader
 type AutoTrader struct {
-	id                    string // Trader unique identifier
-	name                  string // Trader display name
-	aiModel               string // AI model name
-	exchange              string // Trading platform name
-	config                AutoTraderConfig
-	trader                Trader // Uses Trader interface (supports multiple platforms)
-	mcpClient             *mcp.Client
-	decisionLogger        *logger.DecisionLogger // Decision logger
-	initialBalance        float64
-	dailyPnL              float64
-	lastResetTime         time.Time
-	stopUntil             time.Time
-	isRunning             bool
-	startTime             time.Time        // System startup time
-	callCount             int              // AI call count
-	positionFirstSeenTime map[string]int64 // Position first seen time (symbol_side -> timestamp in milliseconds)
-	multiAgentConfig      interface{}      // Multi-agent config (avoid circular import - use interface{})
-	traderManager         interface{}      // Trader manager reference (for copy trading - avoid circular import)
+	id                 string // Trader unique identifier
+	name               string // Trader display name
+	aiModel            string // AI model name
+	exchange           string // Trading platform name
+	config             AutoTraderConfig
+	trader             Trader // Uses Trader interface (supports multiple platforms)
+	mcpClient          *mcp.Client
+	strategy           decisionPkg.Strategy        // Produces each cycle's decisions (AI engine or rules)
+	log                logging.Logger              // Logs with this trader's ID and name
+	promptTemplate     *decisionPkg.PromptTemplate // Templates the AI prompts are rendered from
+	riskPolicy         *decisionPkg.RiskPolicy     // Limits decisions are validated against
+	riskOfficer        *riskOfficer                // Vetoes or downsizes opens before execution (nil = off)
+	decisionLogger     *logger.DecisionLogger      // Decision logger
+	initialBalance     float64
+	risk               *riskControl // Daily loss / drawdown limits
+	isRunning          bool
+	startTime          time.Time               // System startup time
+	callCount          int                     // AI call count
+	positionOrigins    *positionOrigins        // Entry time and opening decision of each open position
+	positionProtection *protectionBook         // Stop/target levels per position and the one owner of their orders
+	multiAgentConfig   interface{}             // Multi-agent config (avoid circular import - use interface{})
+	traderManager      interface{}             // Trader manager reference (for copy trading - avoid circular import)
+	copiedCycles       map[string]int          // Copy trading: last copied cycle of each source trader
+	symbolThrottle     *SymbolThrottle         // Cross-trader per-symbol entry throttle (shared, owned by manager)
+	pnlLedger          *PnLLedger              // Realized P&L ledger (closes, fees, funding)
+	orderTracker       *OrderTracker           // Submitted orders and their reconciled fills
+	accountKey         string                  // Exchange account (traders with the same key share positions)
+	ownership          *OwnershipLedger        // Position ownership on shared accounts (shared, owned by manager)
+	tradeMemory        *TradeMemory            // Embeddings index of closed trades (nil = disabled)
+	rejectedTrades     *RejectedTradeSimulator // Open decisions rejected by validation and their simulated outcome
+	completion         *completionTracker      // End condition progress (nil = no end conditions)
+	openSagas          *openSagaLog            // In-flight multi-step opens (resumed or rolled back after a crash)
+	decisionQuality    *DecisionQuality        // Process scores of closed trades (independent of P&L)
+	runCtx             context.Context         // Cancelled by Stop (aborts in-flight AI requests)
+	cycles             cycleTracker            // The cycle in progress, waited for by Shutdown
+	cancelRun          context.CancelFunc
+	sim                *sim.Simulation // Price paths and clock of the simulate exchange mode (nil = live market)
+
+	// Hypothetical equity curves for alternative auto-close thresholds (nil = disabled)
+	autoCloseWhatIf *AutoCloseWhatIf
+
+	// When cycles fire (ticker or candle-close aligned)
+	schedule *cycleSchedule
+
+	// Operator pause/resume and manual cycle requests
+	control *cycleControl
+
+	// Webhook signals and manual trades waiting for their cycle
+	signals *signalFeed
+
+	// Event-driven early cycles (nil = schedule only)
+	triggers *cycleTriggers
+
+	// Paper twin of the live account (nil = no paper_shadow)
+	paperShadow *PaperShadow
+
+	// Crash history of the loop, kept by its supervisor
+	supervision supervision
+
+	// Guards the config fields that change at runtime (see RuntimeSettings)
+	settingsMu sync.RWMutex
+
+	// Leverage unavailable on the exchange, found by the startup leverage setup
+	leverageCaps leverageCaps
+
+	// Context of the latest AI decision (served by /api/context)
+	lastContext   *decisionPkg.Context
+	lastContextMu sync.RWMutex
 }
 
 // NewAutoTrader creates auto trader
@@ -149,6 +326,13 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 	if config.Name == "" {
 		config.Name = "Default Trader"
 	}
+	atLog := logging.ForTrader(config.ID, config.Name)
+	if config.BackgroundTakeProfitPct == 0 {
+		config.BackgroundTakeProfitPct = defaultBackgroundTakeProfitPct
+	}
+	if config.MonitorInterval <= 0 {
+		config.MonitorInterval = 10 * time.Second
+	}
 	if config.AIModel == "" {
 		if config.UseQwen {
 			config.AIModel = "qwen"
@@ -165,37 +349,88 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 	if config.AIModel == "custom" {
 		// Use custom API
 		mcpClient.SetCustomAPI(config.CustomAPIURL, config.CustomAPIKey, config.CustomModelName)
-		log.Printf("🤖 [%s] Using custom AI API: %s (Model: %s)", config.Name, config.CustomAPIURL, config.CustomModelName)
+		atLog.Infof("🤖 Using custom AI API: %s (Model: %s)", config.CustomAPIURL, config.CustomModelName)
+	} else if config.AIModel == "anthropic" {
+		mcpClient.SetAnthropicAPIKey(config.AnthropicKey, config.AnthropicModel)
+		atLog.Infof("🤖 Using Anthropic Claude (Model: %s)", mcpClient.Model)
+	} else if config.AIModel == "gemini" {
+		mcpClient.SetGeminiAPIKey(config.GeminiKey, config.GeminiModel)
+		atLog.Infof("🤖 Using Google Gemini (Model: %s)", mcpClient.Model)
 	} else if config.AIModel == "groq" {
 		// Use Groq (supports OpenAI and Qwen models)
 		mcpClient.SetGroqAPIKey(config.GroqKey, config.GroqModel)
 		if config.GroqModel != "" {
-			log.Printf("🤖 [%s] Using Groq AI (Model: %s)", config.Name, config.GroqModel)
+			atLog.Infof("🤖 Using Groq AI (Model: %s)", config.GroqModel)
 		} else {
-			log.Printf("🤖 [%s] Using Groq AI", config.Name)
+			atLog.Infof("🤖 Using Groq AI")
 		}
 	} else if config.UseQwen || config.AIModel == "qwen" {
 		// Use Qwen
 		mcpClient.SetQwenAPIKey(config.QwenKey, "")
-		log.Printf("🤖 [%s] Using Alibaba Cloud Qwen AI", config.Name)
+		atLog.Infof("🤖 Using Alibaba Cloud Qwen AI")
 	} else if config.AIModel == "deepseek" || config.DeepSeekKey != "" {
 		// Use DeepSeek
 		mcpClient.SetDeepSeekAPIKey(config.DeepSeekKey)
-		log.Printf("🤖 [%s] Using DeepSeek AI", config.Name)
+		atLog.Infof("🤖 Using DeepSeek AI")
 	} else {
 		// Default to Groq
 		if config.GroqKey != "" {
 			mcpClient.SetGroqAPIKey(config.GroqKey, config.GroqModel)
 			if config.GroqModel != "" {
-				log.Printf("🤖 [%s] Using Groq AI (Model: %s)", config.Name, config.GroqModel)
+				atLog.Infof("🤖 Using Groq AI (Model: %s)", config.GroqModel)
 			} else {
-				log.Printf("🤖 [%s] Using Groq AI", config.Name)
+				atLog.Infof("🤖 Using Groq AI")
 			}
 		} else {
-			log.Printf("⚠️  [%s] Warning: AI API key not configured, please set groq_key", config.Name)
+			atLog.Warnf("⚠️  Warning: AI API key not configured, please set groq_key")
 		}
 	}
 
+	// Per-provider timeout, prices and retries, then the failover providers
+	configureAIClient(mcpClient, config)
+	for _, provider := range config.AIFailover {
+		fallback := newFailoverClient(provider, config)
+		if fallback == nil {
+			atLog.Warnf("⚠️  Unknown AI failover provider '%s' - skipped", provider)
+			continue
+		}
+		configureAIClient(fallback, config)
+		mcpClient.Fallbacks = append(mcpClient.Fallbacks, fallback)
+	}
+	if len(mcpClient.Fallbacks) > 0 {
+		atLog.Infof("🔀 AI failover: %s → %s", mcpClient.Provider, strings.Join(config.AIFailover, " → "))
+	}
+
+	// Decision strategy (the AI engine unless a rule-based/hybrid strategy is configured)
+	strategy, err := decisionPkg.NewStrategy(config.Strategy, config.StrategyParams, mcpClient)
+	if err != nil {
+		return nil, err
+	}
+	if strategy.Name() != decisionPkg.StrategyAI {
+		atLog.Infof("📏 Decision strategy: %s", strategy.Name())
+	}
+
+	// Prompt templates, stored by version so each decision's prompt_version can be traced to its text
+	promptTemplate, err := decisionPkg.LoadPromptTemplate(config.PromptTemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	if config.PromptTemplateDir != "" {
+		atLog.Infof("📝 Prompt template: %s (version %s)", promptTemplate.Name, promptTemplate.Version)
+	}
+	if config.PromptVersionsDir != "" {
+		if err := decisionPkg.SavePromptVersion(config.PromptVersionsDir, promptTemplate); err != nil {
+			atLog.Warnf("⚠️  Failed to store prompt version %s: %v", promptTemplate.Version, err)
+		}
+	}
+
+	// Risk policy, recorded with each decision so traders with different policies can be compared
+	riskPolicy := NewRiskPolicy(config.RiskPolicy)
+	if config.RiskPolicy != nil {
+		atLog.Infof("⚖️  Risk policy: %s (R:R ≥ %g, risk %g%%/trade, max %d positions)",
+			riskPolicy.Label(), riskPolicy.MinRiskReward, riskPolicy.MaxRiskPerTradePct, riskPolicy.MaxPositions)
+	}
+
 	// Initialize coin pool API
 	if config.CoinPoolAPIURL != "" {
 		pool.SetCoinPoolAPI(config.CoinPoolAPIURL)
@@ -208,32 +443,43 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 
 	// Create corresponding trader based on configuration
 	var trader Trader
-	var err error
 	var tempLogger *logger.DecisionLogger                      // For paper trading state restoration
 	var restoredInitialBalance float64 = config.InitialBalance // Will be updated from database if records exist
+	var simulation *sim.Simulation
 
 	switch config.Exchange {
 	case "binance":
-		log.Printf("🏦 [%s] Using Binance Futures trading", config.Name)
+		atLog.Infof("🏦 Using Binance Futures trading")
 		trader = NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey)
 	case "hyperliquid":
-		log.Printf("🏦 [%s] Using Hyperliquid trading", config.Name)
+		atLog.Infof("🏦 Using Hyperliquid trading")
 		trader, err = NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize Hyperliquid trader: %w", err)
 		}
 	case "aster":
-		log.Printf("🏦 [%s] Using Aster trading", config.Name)
+		atLog.Infof("🏦 Using Aster trading")
 		trader, err = NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize Aster trader: %w", err)
 		}
-	case "paper", "simulate", "demo":
-		log.Printf("📊 [%s] Using paper trading mode (simulated)", config.Name)
+	case "okx":
+		atLog.Infof("🏦 Using OKX trading")
+		trader = NewOKXTrader(config.OKXAPIKey, config.OKXSecretKey, config.OKXPassphrase)
+	case "bybit":
+		atLog.Infof("🏦 Using Bybit trading")
+		trader = NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey, config.BybitTestnet)
+	case "simulate":
+		simulation, trader, tempLogger, err = newSimulatedTrader(config, mcpClient, strategy)
+		if err != nil {
+			return nil, err
+		}
+	case "paper", "demo":
+		atLog.Infof("📊 Using paper trading mode (simulated)")
 		// Initialize decision logger first to check for existing records
 		logDir := fmt.Sprintf("decision_logs/%s", config.ID)
 		if supabaseConfig != nil && supabaseConfig.UseSupabase {
-			log.Printf("🔗 [%s] Using Supabase for paper trading decision logging", config.Name)
+			atLog.Infof("🔗 Using Supabase for paper trading decision logging")
 			tempLogger = logger.NewDecisionLoggerWithConfig(logDir, config.ID, supabaseConfig)
 		} else {
 			tempLogger = logger.NewDecisionLogger(logDir)
@@ -244,16 +490,16 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 		if tempLogger != nil {
 			firstRecord, err := tempLogger.GetFirstRecord()
 			if err != nil {
-				log.Printf("⚠️  [%s] Could not get first record: %v", config.Name, err)
-				log.Printf("💡 [%s] Make sure you've run the seed migration in Supabase", config.Name)
+				atLog.Warnf("⚠️  Could not get first record: %v", err)
+				atLog.Infof("💡 Make sure you've run the seed migration in Supabase")
 			} else if firstRecord != nil {
 				restoredInitialBalance = firstRecord.AccountState.TotalBalance
 				if restoredInitialBalance > 0 {
-					log.Printf("✅ [%s] Found first record (cycle #%d), using initial balance: %.2f USDT for P&L calculation",
-						config.Name, firstRecord.CycleNumber, restoredInitialBalance)
+					atLog.Infof("✅ Found first record (cycle #%d), using initial balance: %.2f USDT for P&L calculation",
+						firstRecord.CycleNumber, restoredInitialBalance)
 				} else {
-					log.Printf("⚠️  [%s] First record has invalid balance (%.2f), using config: %.2f",
-						config.Name, restoredInitialBalance, config.InitialBalance)
+					atLog.Warnf("⚠️  First record has invalid balance (%.2f), using config: %.2f",
+						restoredInitialBalance, config.InitialBalance)
 					restoredInitialBalance = config.InitialBalance
 				}
 			}
@@ -261,21 +507,22 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 
 		// Restore paper trader state from latest decision record
 		// Database is seeded, so there should always be at least one record
-		log.Printf("🔄 [%s] Restoring balance from latest database record...", config.Name)
+		atLog.Infof("🔄 Restoring balance from latest database record...")
 		var paperTrader *PaperTrader
 		paperTrader, err := restorePaperTraderState(restoredInitialBalance, tempLogger)
 		if err != nil {
-			log.Printf("❌ [%s] Failed to restore from database: %v", config.Name, err)
-			log.Printf("💡 [%s] Make sure the database has been initialized for trader_id='%s'", config.Name, config.ID)
-			log.Printf("💡 [%s] Falling back to config initial balance: %.2f USDT", config.Name, config.InitialBalance)
+			atLog.Errorf("❌ Failed to restore from database: %v", err)
+			atLog.Infof("💡 Make sure the database has been initialized for trader_id='%s'", config.ID)
+			atLog.Infof("💡 Falling back to config initial balance: %.2f USDT", config.InitialBalance)
 			paperTrader = NewPaperTrader(config.InitialBalance)
 		} else {
-			log.Printf("✅ [%s] Successfully restored from database", config.Name)
-			log.Printf("💰 [%s] Current balance: Wallet=%.2f, Equity=%.2f, Available=%.2f, InitialBalance=%.2f (for P&L)",
-				config.Name, paperTrader.balance,
+			atLog.Infof("✅ Successfully restored from database")
+			atLog.Infof("💰 Current balance: Wallet=%.2f, Equity=%.2f, Available=%.2f, InitialBalance=%.2f (for P&L)",
+				paperTrader.balance,
 				paperTrader.balance+paperTrader.unrealizedProfit,
 				paperTrader.availableBalance, paperTrader.initialBalance)
 		}
+		applyPaperSettings(paperTrader, config)
 		trader = paperTrader
 	default:
 		return nil, fmt.Errorf("unsupported trading platform: %s", config.Exchange)
@@ -296,10 +543,10 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 		logDir := fmt.Sprintf("decision_logs/%s", config.ID)
 		// Use Supabase if configured, otherwise fall back to SQLite
 		if supabaseConfig != nil && supabaseConfig.UseSupabase {
-			log.Printf("🔗 [%s] Using Supabase for decision logging", config.Name)
+			atLog.Infof("🔗 Using Supabase for decision logging")
 			decisionLogger = logger.NewDecisionLoggerWithConfig(logDir, config.ID, supabaseConfig)
 		} else {
-			log.Printf("💾 [%s] Using SQLite for decision logging", config.Name)
+			atLog.Infof("💾 Using SQLite for decision logging")
 			decisionLogger = logger.NewDecisionLogger(logDir)
 		}
 	}
@@ -311,145 +558,361 @@ func NewAutoTraderWithMultiAgent(config AutoTraderConfig, supabaseConfig *Supaba
 		// For real exchanges, ALWAYS try to restore initial balance from first record
 		// This ensures P&L calculation continues from where it left off after restart
 		if decisionLogger != nil {
-			log.Printf("🔄 [%s] Attempting to restore initial balance from decision logs...", config.Name)
+			atLog.Infof("🔄 Attempting to restore initial balance from decision logs...")
 			firstRecord, err := decisionLogger.GetFirstRecord()
 			if err != nil {
-				log.Printf("⚠️  [%s] Could not get first record from logs: %v", config.Name, err)
-				log.Printf("⚠️  [%s] Will use config initial balance: %.2f USDT (P&L calculation will restart)", config.Name, config.InitialBalance)
+				atLog.Warnf("⚠️  Could not get first record from logs: %v", err)
+				atLog.Warnf("⚠️  Will use config initial balance: %.2f USDT (P&L calculation will restart)", config.InitialBalance)
 			} else if firstRecord != nil {
 				restoredFromDB := firstRecord.AccountState.TotalBalance
 				if restoredFromDB > 0 {
 					initialBalance = restoredFromDB
-					log.Printf("✅ [%s] Successfully restored initial balance from first record (cycle #%d): %.2f USDT",
-						config.Name, firstRecord.CycleNumber, initialBalance)
-					log.Printf("✅ [%s] Config had %.2f, but using %.2f from logs to maintain P&L continuity",
-						config.Name, config.InitialBalance, initialBalance)
+					atLog.Infof("✅ Successfully restored initial balance from first record (cycle #%d): %.2f USDT",
+						firstRecord.CycleNumber, initialBalance)
+					atLog.Infof("✅ Config had %.2f, but using %.2f from logs to maintain P&L continuity",
+						config.InitialBalance, initialBalance)
 				} else {
-					log.Printf("⚠️  [%s] First record has invalid/zero balance (%.2f), checking if this is expected...", config.Name, restoredFromDB)
+					atLog.Warnf("⚠️  First record has invalid/zero balance (%.2f), checking if this is expected...", restoredFromDB)
 					// If first record has 0 balance, it might mean account was liquidated
 					// But we should still use config value in this case
-					log.Printf("⚠️  [%s] Using config initial balance: %.2f USDT", config.Name, config.InitialBalance)
+					atLog.Warnf("⚠️  Using config initial balance: %.2f USDT", config.InitialBalance)
 					initialBalance = config.InitialBalance
 				}
 			} else {
-				log.Printf("ℹ️  [%s] No first record found in logs - this is the first run", config.Name)
-				log.Printf("ℹ️  [%s] Using config initial balance: %.2f USDT", config.Name, config.InitialBalance)
+				atLog.Infof("ℹ️  No first record found in logs - this is the first run")
+				atLog.Infof("ℹ️  Using config initial balance: %.2f USDT", config.InitialBalance)
 				initialBalance = config.InitialBalance
 			}
 		} else {
-			log.Printf("⚠️  [%s] Decision logger not available, using config initial balance: %.2f USDT", config.Name, config.InitialBalance)
+			atLog.Warnf("⚠️  Decision logger not available, using config initial balance: %.2f USDT", config.InitialBalance)
 		}
 	}
 
 	// Final safety check: ensure initialBalance is never 0 or negative
 	if initialBalance <= 0 {
-		log.Printf("⚠️  [%s] Initial balance is invalid (%.2f), forcing to config value: %.2f",
-			config.Name, initialBalance, config.InitialBalance)
+		atLog.Warnf("⚠️  Initial balance is invalid (%.2f), forcing to config value: %.2f",
+			initialBalance, config.InitialBalance)
 		initialBalance = config.InitialBalance
 	}
 
 	// Log final decision on initial balance
-	log.Printf("📊 [%s] Final initial balance for P&L calculation: %.2f USDT", config.Name, initialBalance)
+	atLog.Infof("📊 Final initial balance for P&L calculation: %.2f USDT", initialBalance)
 	if initialBalance != config.InitialBalance {
-		log.Printf("📊 [%s] Note: This differs from config (%.2f) - P&L will be calculated relative to restored value",
-			config.Name, config.InitialBalance)
+		atLog.Infof("📊 Note: This differs from config (%.2f) - P&L will be calculated relative to restored value",
+			config.InitialBalance)
+	}
+
+	// Tag client order IDs so execution audits can tell this trader's orders apart on shared accounts
+	if tagger, ok := trader.(ClientOrderTagger); ok {
+		tagger.SetClientOrderTag(ClientOrderTag(config.ID))
+	}
+
+	// Trader state files live next to the decision logs (the simulation's log directory in simulate mode)
+	stateDir := fmt.Sprintf("decision_logs/%s", config.ID)
+	if simulation != nil {
+		stateDir = simulation.LogDir
+	}
+
+	// Record realized P&L for every close, whichever code path triggers it
+	incomeCursor := time.Now()
+	if simulation != nil {
+		incomeCursor = simulation.Clock.Now() // Simulated income is dated on the simulated clock
+	}
+	pnlLedger := NewPnLLedger(filepath.Join(stateDir, "pnl_ledger.json"), incomeCursor)
+	// Record every order and reconcile its fill (logged actions carry fill prices, not pre-trade prices)
+	orderReader, _ := trader.(OrderReader)
+	clock := time.Now
+	if simulation != nil {
+		clock = simulation.Clock.Now
+	}
+	orderTracker := newOrderTracker(decisionLogger, orderReader, clock)
+	// Cancel the stop loss / take profit orders a flattened position leaves resting (never in dry run: the
+	// account's orders are real)
+	var openOrders OpenOrderLister
+	if !config.DryRun {
+		openOrders, _ = trader.(OpenOrderLister)
+	}
+	positionProtection := newProtectionBook(openOrders, ClientOrderTag(config.ID), clock)
+	// Clock resyncs, symbol rule refreshes and symbol filters go to the adapter itself, never the dry run executor
+	exchange := trader
+	if config.DryRun {
+		// Wrapped inside the order-path wrappers so baseTrader sees the shadow executor, never the order-placing adapter
+		trader = newDryRunTrader(trader, atLog)
+		atLog.Infof("🧪 DRY RUN: real %s balances, positions and prices; orders are logged, not sent", config.Exchange)
+	}
+	// Retry rate limits, clock drift and precision errors by error class
+	trader = newRetryTrader(trader, exchange)
+	var paperShadow *PaperShadow
+	if config.PaperShadow != nil {
+		paperShadow = newPaperShadow(config.PaperShadow.InitialBalance, func(pt *PaperTrader) { applyPaperSettings(pt, config) })
+		atLog.Infof("👥 Paper shadow: every order is repeated on a paper account (initial balance %s)", describeShadowBalance(config.PaperShadow.InitialBalance))
+	}
+	trader = newLedgerTrader(trader, pnlLedger, orderTracker, ledgerOptions{shadow: paperShadow, protection: positionProtection})
+	// Fit orders to the exchange's symbol filters before the ledger records them (real filters in dry run too, so
+	// its orders are the ones that would be sent)
+	trader = newFilterTrader(trader, exchange)
+
+	var tradeMemory *TradeMemory
+	if config.TradeMemory.Enabled {
+		tradeMemory = NewTradeMemory(config.TradeMemory, filepath.Join(stateDir, "trade_memory.json"))
+	}
+
+	var completion *completionTracker
+	if config.EndConditions.Active() {
+		completion = newCompletionTracker(*config.EndConditions, filepath.Join(stateDir, "completion.json"), pnlLedger.Summary().CloseCount)
+		atLog.Infof("🏁 End conditions: target %.2f%%, max loss %.2f%%, max %.1f days, max %d trades (0 = off), flatten: %s",
+			config.EndConditions.TargetPnLPct, config.EndConditions.MaxLossPct, config.EndConditions.MaxDays,
+			config.EndConditions.MaxTrades, config.EndConditions.FlattenPolicy)
+	}
+
+	var autoCloseWhatIf *AutoCloseWhatIf
+	if config.AutoCloseWhatIf.Enabled {
+		autoCloseWhatIf = NewAutoCloseWhatIf(config.AutoCloseWhatIf.Thresholds, config.BackgroundTakeProfitPct, filepath.Join(stateDir, "auto_close_what_if.json"))
+		atLog.Infof("🔀 Auto-close what-if curves: %v%% (live threshold %s)", config.AutoCloseWhatIf.Thresholds, describeTakeProfit(config.BackgroundTakeProfitPct))
+	}
+	if config.SignalWebhook != nil {
+		atLog.Infof("📡 Signal webhook: POST /api/signals/%s (max age %ds, exclusive: %v)",
+			config.ID, config.SignalWebhook.MaxAgeSeconds, config.SignalWebhook.Exclusive)
+	}
+	if config.AdaptiveConfidence.Enabled {
+		ac := config.AdaptiveConfidence
+		atLog.Infof("🎚️  Adaptive confidence threshold: %d-%d (default %d until %d calibrated trades)", ac.MinThreshold, ac.MaxThreshold, ac.DefaultThreshold, ac.MinTrades)
+	}
+
+	startTime := time.Now()
+	if simulation != nil {
+		startTime = simulation.Clock.Now()
 	}
 
 	return &AutoTrader{
-		id:                    config.ID,
-		name:                  config.Name,
-		aiModel:               config.AIModel,
-		exchange:              config.Exchange,
-		config:                config,
-		trader:                trader,
-		mcpClient:             mcpClient,
-		decisionLogger:        decisionLogger,
-		initialBalance:        initialBalance, // Use restored initial balance
-		lastResetTime:         time.Now(),
-		startTime:             time.Now(),
-		callCount:             0,
-		isRunning:             false,
-		positionFirstSeenTime: make(map[string]int64),
-		multiAgentConfig:      multiAgentConfig,
+		id:                 config.ID,
+		name:               config.Name,
+		log:                atLog,
+		aiModel:            config.AIModel,
+		exchange:           config.Exchange,
+		config:             config,
+		trader:             trader,
+		pnlLedger:          pnlLedger,
+		orderTracker:       orderTracker,
+		accountKey:         accountKeyOf(config),
+		tradeMemory:        tradeMemory,
+		rejectedTrades:     NewRejectedTradeSimulator(filepath.Join(stateDir, "rejected_trades.json")),
+		mcpClient:          mcpClient,
+		strategy:           strategy,
+		promptTemplate:     promptTemplate,
+		riskPolicy:         riskPolicy,
+		riskOfficer:        newRiskOfficer(config),
+		decisionLogger:     decisionLogger,
+		initialBalance:     initialBalance, // Use restored initial balance
+		risk:               newRiskControl(filepath.Join(stateDir, "risk_state.json")),
+		startTime:          startTime,
+		callCount:          0,
+		isRunning:          false,
+		positionOrigins:    newPositionOrigins(),
+		positionProtection: positionProtection,
+		multiAgentConfig:   multiAgentConfig,
+		completion:         completion,
+		openSagas:          newOpenSagaLog(filepath.Join(stateDir, "open_sagas.json")),
+		decisionQuality:    NewDecisionQuality(filepath.Join(stateDir, "decision_quality.json")),
+		autoCloseWhatIf:    autoCloseWhatIf,
+		schedule:           newCycleSchedule(config.ScanInterval, config.CycleAlignment),
+		control:            newCycleControl(),
+		signals:            newSignalFeed(),
+		triggers:           newCycleTriggers(config.CycleTriggers),
+		paperShadow:        paperShadow,
+		sim:                simulation,
 	}, nil
 }
 
+// applyPaperSettings applies the configured order book fills, short constraints and trading costs to a paper trader
+func applyPaperSettings(paperTrader *PaperTrader, config AutoTraderConfig) {
+	if config.PaperFills.Enabled {
+		paperTrader.SetOrderBookFills(config.PaperFills.NotionalThresholdUSD, config.PaperFills.DepthLimit)
+	}
+	if config.PaperShorts.Enabled {
+		paperTrader.SetShortConstraints(config.PaperShorts)
+	}
+	if config.PaperCosts.Enabled {
+		paperTrader.SetTradingCosts(config.PaperCosts)
+	}
+}
+
 // Run Runs the main auto trading loop
 func (at *AutoTrader) Run() error {
+	if at.IsCompleted() {
+		completion := at.GetCompletion()
+		at.log.Infof("🏁 Trader already completed (%s at %s) - not starting", completion.Reason, completion.CompletedAt.Format(time.RFC3339))
+		return nil
+	}
 	at.isRunning = true
-	log.Printf("[%s] 🚀 AI-driven auto trading system started", at.name)
-	log.Printf("[%s] 💰 Initial balance: %.2f USDT", at.name, at.initialBalance)
-	log.Printf("[%s] ⚙️  Scan interval: %v", at.name, at.config.ScanInterval)
-	log.Printf("[%s] 🤖 AI will autonomously decide leverage, position size, stop loss/take profit, etc.", at.name)
+	at.runCtx, at.cancelRun = context.WithCancel(context.Background())
+	defer at.cancelRun()
+	at.log.Infof("🚀 AI-driven auto trading system started")
+	at.log.Infof("💰 Initial balance: %.2f USDT", at.initialBalance)
+	at.log.Infof("⚙️  Scan interval: %v", at.RuntimeSettings().ScanInterval)
+	if at.schedule.aligned() {
+		at.log.Infof("🕯️  Cycles aligned to %s candle closes (+%ds)", at.config.CycleAlignment.Timeframe, at.config.CycleAlignment.OffsetSeconds)
+	}
+	at.log.Infof("🤖 AI will autonomously decide leverage, position size, stop loss/take profit, etc.")
 
 	// Log auto take profit status
-	if at.exchange == "paper" && at.config.AutoTakeProfitPct > 0 {
-		log.Printf("[%s] 🎯 Auto Take Profit: ENABLED (%.2f%% P&L target)", at.name, at.config.AutoTakeProfitPct)
-		log.Printf("[%s]    Positions will auto-close at %.2f%% profit (with leverage)", at.name, at.config.AutoTakeProfitPct)
+	if autoTakeProfitPct := at.autoTakeProfitPct(); at.exchange == "paper" && autoTakeProfitPct > 0 {
+		at.log.Infof("🎯 Auto Take Profit: ENABLED (%.2f%% P&L target)", autoTakeProfitPct)
+		at.log.Infof("   Positions will auto-close at %.2f%% profit (with leverage)", autoTakeProfitPct)
 	} else if at.exchange == "paper" {
-		log.Printf("[%s] ⚠️  Auto Take Profit: DISABLED (set auto_take_profit_pct in config to enable)", at.name)
+		at.log.Warnf("⚠️  Auto Take Profit: DISABLED (set auto_take_profit_pct in config to enable)")
+	} else {
+		at.log.Infof("ℹ️  Auto Take Profit: Paper trading only (current exchange: %s)", at.exchange)
+	}
+	if at.honorsStops() {
+		at.log.Infof("🛑 Stop losses: HONORED (every open places a stop order at its stop loss)")
 	} else {
-		log.Printf("[%s] ℹ️  Auto Take Profit: Paper trading only (current exchange: %s)", at.name, at.exchange)
+		at.log.Infof("ℹ️  Stop losses: risk planning only (losing positions are held until profitable)")
+	}
+	if at.sim != nil {
+		return at.runSimulation()
 	}
 
-	ticker := time.NewTicker(at.config.ScanInterval)
+	ticker := at.schedule.startTicker() // Unused when cycles are aligned to candle closes
 	defer ticker.Stop()
 
-	// Start background position monitor (checks every 10 seconds for profitable positions to close)
-	positionMonitorTicker := time.NewTicker(10 * time.Second)
-	defer positionMonitorTicker.Stop()
-
-	// Channel to stop background monitor
+	// Channel to stop background monitor (also on a panic, so a restarted loop does not run a second monitor)
 	stopMonitor := make(chan bool, 1)
+	defer func() { stopMonitor <- true }()
+
+	// Start background position monitor goroutine (skipped when it has nothing to do)
+	if at.positionMonitorNeeded() {
+		positionMonitorTicker := time.NewTicker(at.config.MonitorInterval)
+		defer positionMonitorTicker.Stop()
+		go at.startPositionMonitor(positionMonitorTicker, stopMonitor)
+	} else {
+		at.log.Infof("ℹ️  Background position monitor disabled (background_take_profit_pct < 0, the AI owns all exits)")
+	}
 
-	// Start background position monitor goroutine
-	go at.startPositionMonitor(positionMonitorTicker, stopMonitor)
+	// Early cycles on market and position events (stopped with the run context)
+	if at.triggers != nil {
+		go at.runCycleTriggers(at.runCtx)
+	}
+
+	// Equity snapshots on their own schedule (stopped with the run context)
+	if at.config.EquitySnapshots != nil && at.decisionLogger != nil {
+		go at.runEquitySnapshots(at.runCtx)
+	}
+
+	// Strip old decision records to summaries on their own schedule
+	if at.config.DecisionRetention.FullTextDays > 0 && at.decisionLogger != nil {
+		go at.runDecisionRetention(at.runCtx)
+	}
 
-	// Execute immediately on first run
-	log.Printf("[%s] ▶️  Starting first cycle immediately...", at.name)
-	if err := at.runCycle(); err != nil {
-		log.Printf("[%s] ❌ First cycle failed: %v", at.name, err)
-		log.Printf("[%s] ⚠️  Error logged, continuing with next scheduled cycle...", at.name)
+	// Finish or roll back opens interrupted by a previous crash before trading resumes
+	at.resumeOpenSagas()
+
+	// Entry times, opening decisions and owners of the positions left open by the last run
+	at.reconcilePositions()
+
+	// Cancel protection orders orphaned by positions closed while the trader was down
+	at.reconcileProtectionOrders()
+
+	// Execute immediately on first run (aligned: at the next candle close, so the first cycle sees closed candles too)
+	var lastStart time.Time
+	if paused, _ := at.IsPaused(); paused {
+		at.log.Infof("⏸ Paused by operator, skipping the first cycle")
+	} else if !at.schedule.aligned() {
+		at.log.Infof("▶️  Starting first cycle immediately...")
+		lastStart = time.Now()
+		if err := at.runCycle(at.runCtx); err != nil {
+			at.log.Errorf("❌ First cycle failed: %v", err)
+			at.log.Warnf("⚠️  Error logged, continuing with next scheduled cycle...")
+		}
 	}
 
-	log.Printf("[%s] ✅ Entering main trading loop (waiting for next interval: %v)...", at.name, at.config.ScanInterval)
+	at.log.Infof("✅ Entering main trading loop (waiting for next interval: %v)...", at.RuntimeSettings().ScanInterval)
 	for at.isRunning {
+		trigger := ticker.C
+		if at.schedule.aligned() {
+			trigger = at.schedule.wait(lastStart)
+			at.log.Infof("🕯️  Next cycle at %s (after the %s candle close)", at.schedule.nextCycle().Format("15:04:05"), at.config.CycleAlignment.Timeframe)
+		}
 		select {
-		case <-ticker.C:
-			log.Printf("[%s] ⏰ Ticker fired, starting cycle...", at.name)
-			if err := at.runCycle(); err != nil {
-				log.Printf("[%s] ❌ Cycle execution failed: %v", at.name, err)
-				log.Printf("[%s] ⚠️  Error logged, continuing with next scheduled cycle in %v...", at.name, at.config.ScanInterval)
+		case <-at.runCtx.Done():
+			// Stopped: leave without running the pending cycle
+		case <-trigger:
+			if paused, _ := at.IsPaused(); paused {
+				at.log.Infof("⏸ Paused by operator, skipping scheduled cycle")
+				continue
+			}
+			at.log.Infof("⏰ Ticker fired, starting cycle...")
+			lastStart = time.Now()
+			if err := at.runCycle(at.runCtx); err != nil {
+				at.log.Errorf("❌ Cycle execution failed: %v", err)
+				at.log.Warnf("⚠️  Error logged, continuing with next scheduled cycle...")
+			} else {
+				at.log.Infof("✅ Cycle completed successfully, waiting for next cycle")
+			}
+		case <-at.control.runNow:
+			// Manual cycles leave lastStart alone so the schedule is unchanged
+			at.log.Infof("⏩ Manual cycle starting...")
+			if err := at.runCycle(at.runCtx); err != nil {
+				at.log.Errorf("❌ Manual cycle failed: %v", err)
 			} else {
-				log.Printf("[%s] ✅ Cycle completed successfully, waiting for next interval: %v", at.name, at.config.ScanInterval)
+				at.log.Infof("✅ Manual cycle completed, waiting for next cycle")
 			}
+		case signal := <-at.signalQueue():
+			// Like manual cycles, signal cycles do not move the schedule
+			at.runSignalCycle(signal)
+		case reasons := <-at.triggerQueue():
+			// Triggered cycles do not move the schedule either
+			at.runTriggeredCycle(reasons)
 		}
 	}
 
-	// Stop background monitor when main loop exits
-	stopMonitor <- true
-
-	log.Printf("[%s] ⏹ Auto trading system stopped (isRunning=false)", at.name)
+	at.log.Infof("⏹ Auto trading system stopped (isRunning=false)")
 	return nil
 }
 
-// startPositionMonitor runs a background goroutine that checks positions every 10 seconds
-// and automatically closes positions with >=4.5% profit
+// defaultBackgroundTakeProfitPct the background monitor's take profit when none is configured
+const defaultBackgroundTakeProfitPct = config.DefaultBackgroundTakeProfitPct
+
+// describeTakeProfit a background take profit threshold for logs
+func describeTakeProfit(pct float64) string {
+	if pct < 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%.1f%%", pct)
+}
+
+// positionMonitorNeeded whether the background monitor has work: the take profit, paper stops and short
+// buy-ins, or following positions for the what-if curves
+func (at *AutoTrader) positionMonitorNeeded() bool {
+	if at.config.BackgroundTakeProfitPct > 0 || at.autoCloseWhatIf != nil {
+		return true
+	}
+	_, paper := asPaperTrader(at.trader)
+	return paper
+}
+
+// startPositionMonitor runs a background goroutine that checks positions every MonitorInterval
+// and automatically closes positions at the background take profit
 func (at *AutoTrader) startPositionMonitor(ticker *time.Ticker, stopChan chan bool) {
-	log.Printf("[%s] 🔄 Background position monitor started (checking every 10 seconds for positions >=4.5%% profit)", at.name)
+	at.log.Infof("🔄 Background position monitor started (checking every %v, take profit %s)",
+		at.config.MonitorInterval, describeTakeProfit(at.config.BackgroundTakeProfitPct))
 
 	for {
 		select {
 		case <-ticker.C:
+			at.enforcePaperStops()
+			at.enforcePaperBuyIns()
+			at.accruePaperFunding()
 			at.checkAndCloseProfitablePositions()
 		case <-stopChan:
-			log.Printf("[%s] 🛑 Background position monitor stopped", at.name)
+			at.log.Infof("🛑 Background position monitor stopped")
 			return
 		}
 	}
 }
 
-// checkAndCloseProfitablePositions checks all open positions and closes those with >4.5% profit
+// checkAndCloseProfitablePositions checks all open positions and closes those whose leveraged P&L reached
+// the background take profit
 func (at *AutoTrader) checkAndCloseProfitablePositions() {
 	// Skip if not running
 	if !at.isRunning {
@@ -462,18 +925,24 @@ func (at *AutoTrader) checkAndCloseProfitablePositions() {
 		return // Silently skip on error
 	}
 
-	if len(positions) == 0 {
-		return // No positions to check
+	// Follow positions for the alternative-threshold curves (also notices closes)
+	if at.autoCloseWhatIf != nil {
+		at.autoCloseWhatIf.observe(positions, at.now())
+	}
+
+	takeProfitPct := at.config.BackgroundTakeProfitPct
+	if len(positions) == 0 || takeProfitPct < 0 {
+		return // No positions to check, or the take profit is off
 	}
 
 	// Check each position silently, only log when closing
 	for _, pos := range positions {
-		symbol, _ := pos["symbol"].(string)
-		side, _ := pos["side"].(string)
-		unrealizedPnl, _ := pos["unRealizedProfit"].(float64)
-		entryPrice, _ := pos["entryPrice"].(float64)
-		markPrice, _ := pos["markPrice"].(float64)
-		leverage, _ := pos["leverage"].(float64)
+		symbol := pos.Symbol
+		side := pos.Side
+		unrealizedPnl := pos.UnrealizedProfit
+		entryPrice := pos.EntryPrice
+		markPrice := pos.MarkPrice
+		leverage := float64(pos.Leverage)
 
 		if leverage == 0 {
 			leverage = 7 // Default leverage if not found
@@ -489,8 +958,8 @@ func (at *AutoTrader) checkAndCloseProfitablePositions() {
 			pnlPct = priceChange * 100 * leverage
 		}
 
-		// Only close if profitable AND >=4.5%
-		if unrealizedPnl > 0 && pnlPct >= 4.5 {
+		// Only close if profitable AND at the take profit
+		if unrealizedPnl > 0 && pnlPct >= takeProfitPct {
 			// Get lock for this position to prevent race conditions
 			lock := getPositionLock(symbol, side)
 			lock.Lock()
@@ -504,17 +973,9 @@ func (at *AutoTrader) checkAndCloseProfitablePositions() {
 
 			positionStillExists := false
 			positionStillProfitable := false
-			for _, pos := range positions {
-				posSymbol, _ := pos["symbol"].(string)
-				posSide, _ := pos["side"].(string)
-				if posSymbol == symbol && strings.EqualFold(posSide, side) {
-					positionStillExists = true
-					posPnl, _ := pos["unRealizedProfit"].(float64)
-					if posPnl > 0 {
-						positionStillProfitable = true
-					}
-					break
-				}
+			if pos, ok := findPosition(positions, symbol, side); ok {
+				positionStillExists = true
+				positionStillProfitable = pos.UnrealizedProfit > 0
 			}
 
 			if !positionStillExists {
@@ -527,8 +988,8 @@ func (at *AutoTrader) checkAndCloseProfitablePositions() {
 				return
 			}
 
-			log.Printf("[%s] 🎯 [Background Monitor] %s %s: %.2f%% profit (%.2f USDT) - Auto-closing immediately!",
-				at.name, symbol, strings.ToUpper(side), pnlPct, unrealizedPnl)
+			at.log.Infof("🎯 [Background Monitor] %s %s: %.2f%% profit (%.2f USDT) - Auto-closing immediately!",
+				symbol, strings.ToUpper(side), pnlPct, unrealizedPnl)
 
 			// Close the position immediately
 			var closeErr error
@@ -539,19 +1000,19 @@ func (at *AutoTrader) checkAndCloseProfitablePositions() {
 			}
 
 			if closeErr != nil {
-				// Check if error is due to position already being closed or margin insufficient (position already closed)
-				errStr := strings.ToLower(closeErr.Error())
-				if strings.Contains(errStr, "no long position") ||
-					strings.Contains(errStr, "no short position") ||
-					strings.Contains(errStr, "margin is insufficient") && strings.Contains(errStr, "-2019") {
+				// Check if error is due to position already being closed (no position, reduce-only or -2019 rejection)
+				if isPositionGoneError(closeErr) {
 					// Position was already closed by another trader - this is expected, not an error
 					return
 				}
-				log.Printf("[%s] ❌ [Background Monitor] Failed to auto-close %s %s: %v",
-					at.name, symbol, strings.ToUpper(side), closeErr)
+				at.log.Errorf("❌ [Background Monitor] Failed to auto-close %s %s: %v",
+					symbol, strings.ToUpper(side), closeErr)
 			} else {
-				log.Printf("[%s] ✅ [Background Monitor] Successfully auto-closed %s %s at %.2f%% profit (%.2f USDT)",
-					at.name, symbol, strings.ToUpper(side), pnlPct, unrealizedPnl)
+				at.log.Infof("✅ [Background Monitor] Successfully auto-closed %s %s at %.2f%% profit (%.2f USDT)",
+					symbol, strings.ToUpper(side), pnlPct, unrealizedPnl)
+				if at.autoCloseWhatIf != nil {
+					at.autoCloseWhatIf.markAutoClosed(symbol, side)
+				}
 			}
 		}
 	}
@@ -560,48 +1021,64 @@ func (at *AutoTrader) checkAndCloseProfitablePositions() {
 // Stop Stops auto trading
 func (at *AutoTrader) Stop() {
 	at.isRunning = false
-	log.Println("⏹ Auto trading system stopped")
+	if at.cancelRun != nil {
+		at.cancelRun() // Abort an in-flight AI request instead of waiting for its timeout
+	}
+	at.log.Infof("⏹ Auto trading system stopped")
 }
 
-// runCycle Runs one trading cycle (using AI full decision mode)
-func (at *AutoTrader) runCycle() error {
+// runCycle Runs one trading cycle (using AI full decision mode). Cancelling cycleCtx (or aborting the cycle)
+// aborts the AI request and skips the decisions not yet executed; an order already being placed is finished
+func (at *AutoTrader) runCycle(cycleCtx context.Context) error {
 	at.callCount++
+	cycleCtx, cancel := context.WithCancel(cycleCtx)
+	defer cancel()
+	at.cycles.begin(at.callCount, cancel)
+	defer at.cycles.end()
 
-	log.Printf("\n[%s] "+strings.Repeat("=", 70), at.name)
-	log.Printf("[%s] ⏰ %s - AI Decision Cycle #%d", at.name, time.Now().Format("2006-01-02 15:04:05"), at.callCount)
-	log.Printf("[%s] "+strings.Repeat("=", 70), at.name)
+	at.log.Infof("%s", strings.Repeat("=", 70))
+	at.log.Infof("⏰ %s - AI Decision Cycle #%d", at.now().Format("2006-01-02 15:04:05"), at.callCount)
+	at.log.Infof("%s", strings.Repeat("=", 70))
 
 	// Create decision record
 	record := &logger.DecisionRecord{
 		ExecutionLog: []string{},
 		Success:      true,
 	}
+	defer func() {
+		if record.Success {
+			at.cycles.succeeded()
+		}
+	}()
+	if at.config.DryRun {
+		record.ExecutionLog = append(record.ExecutionLog, "🧪 Dry run: orders are logged, not sent to the exchange")
+	}
+	if signal := at.currentSignal(); signal != nil {
+		record.Source = signal.Source
+		signal.record = record
+	}
+	if reasons := at.currentTrigger(); len(reasons) > 0 {
+		record.ExecutionLog = append(record.ExecutionLog, "⚡ Triggered early: "+strings.Join(reasons, "; "))
+	}
+	at.syncPaperShadow()
 
 	// 1. Check if trading should be stopped
-	if time.Now().Before(at.stopUntil) {
-		remaining := at.stopUntil.Sub(time.Now())
-		log.Printf("⏸ Risk control: Trading paused, remaining %.0f minutes", remaining.Minutes())
+	if remaining := at.riskStopRemaining(); remaining > 0 {
+		at.log.Infof("⏸ Risk control: Trading paused, remaining %.0f minutes", remaining.Minutes())
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("Risk control pause active, remaining %.0f minutes", remaining.Minutes())
 		at.decisionLogger.LogDecision(record)
 		return nil
 	}
 
-	// 2. Reset daily P&L (resets daily)
-	if time.Since(at.lastResetTime) > 24*time.Hour {
-		at.dailyPnL = 0
-		at.lastResetTime = time.Now()
-		log.Println("📅 Daily P&L reset")
-	}
-
 	// 2.5. Check auto take profit and stop loss (paper trading only)
-	if at.exchange == "paper" && at.config.AutoTakeProfitPct > 0 {
-		if paperTrader, ok := at.trader.(*PaperTrader); ok {
-			toClose, err := paperTrader.CheckAutoTakeProfit(at.config.AutoTakeProfitPct)
+	if autoTakeProfitPct := at.autoTakeProfitPct(); at.exchange == "paper" && autoTakeProfitPct > 0 {
+		if paperTrader, ok := asPaperTrader(at.trader); ok {
+			toClose, err := paperTrader.CheckAutoTakeProfit(autoTakeProfitPct)
 			if err != nil {
-				log.Printf("⚠️  Failed to check auto take profit: %v", err)
+				at.log.Warnf("⚠️  Failed to check auto take profit: %v", err)
 			} else if len(toClose) > 0 {
-				log.Printf("🎯 Auto-closing %d position(s) due to take profit/stop loss", len(toClose))
+				at.log.Infof("🎯 Auto-closing %d position(s) due to take profit/stop loss", len(toClose))
 				for _, pos := range toClose {
 					var closeErr error
 					if pos.Side == "long" {
@@ -610,9 +1087,9 @@ func (at *AutoTrader) runCycle() error {
 						_, closeErr = at.trader.CloseShort(pos.Symbol, 0)
 					}
 					if closeErr != nil {
-						log.Printf("❌ Failed to auto-close %s %s: %v", pos.Symbol, pos.Side, closeErr)
+						at.log.Errorf("❌ Failed to auto-close %s %s: %v", pos.Symbol, pos.Side, closeErr)
 					} else {
-						log.Printf("✅ Auto-closed %s %s: %s", pos.Symbol, pos.Side, pos.Reason)
+						at.log.Infof("✅ Auto-closed %s %s: %s", pos.Symbol, pos.Side, pos.Reason)
 					}
 				}
 				// After auto-closing, rebuild context to reflect new positions
@@ -621,6 +1098,28 @@ func (at *AutoTrader) runCycle() error {
 		}
 	}
 
+	// 2.6. Sync exchange-reported fees and funding into the realized P&L ledger
+	at.accruePaperFunding()
+	if reporter, ok := baseTrader(at.trader).(IncomeReporter); ok {
+		if err := at.pnlLedger.SyncIncome(reporter, at.incomeShare()); err != nil {
+			at.log.Warnf("⚠️  Failed to sync fees/funding: %v", err)
+		}
+	}
+
+	// 2.7. Retry open rollbacks that failed earlier (naked positions without take profit), protect filled limit
+	// entries, cancel expired ones and reconcile orders whose fill is not known yet
+	at.resumeOpenSagas()
+	at.checkPendingEntries()
+	at.orderTracker.Reconcile()
+
+	// 2.8. Add this cycle's point to the auto-close what-if curves
+	if at.autoCloseWhatIf != nil {
+		at.autoCloseWhatIf.record(at.callCount, at.now(), func(symbol, side string) float64 {
+			levels, _ := at.positionProtection.levels(symbol, side)
+			return levels.StopLoss
+		})
+	}
+
 	// 3. Collect trading context
 	ctx, err := at.buildTradingContext()
 	if err != nil {
@@ -630,11 +1129,26 @@ func (at *AutoTrader) runCycle() error {
 		return fmt.Errorf("failed to build trading context: %w", err)
 	}
 
+	// 3.1. Check end conditions (target P&L, max loss, max days, max trades)
+	if reason := at.checkEndConditions(ctx.Account.TotalEquity); reason != "" {
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("🏁 Trader completed: %s", reason))
+		at.decisionLogger.LogDecision(record)
+		return nil
+	}
+
+	// 3.2. Daily P&L and the daily loss / drawdown limits (a hit pauses new decisions for stop_trading_minutes)
+	if reason := at.updateRiskControl(ctx.Account.TotalEquity); reason != "" {
+		record.Success = false
+		record.ErrorMessage = fmt.Sprintf("Risk control: %s", reason)
+		at.decisionLogger.LogDecision(record)
+		return nil
+	}
+
 	// Save account state snapshot
 	record.AccountState = logger.AccountSnapshot{
 		TotalBalance:          ctx.Account.TotalEquity,
 		AvailableBalance:      ctx.Account.AvailableBalance,
-		TotalUnrealizedProfit: ctx.Account.TotalPnL,
+		TotalUnrealizedProfit: ctx.Account.UnrealizedPnL,
 		PositionCount:         ctx.Account.PositionCount,
 		MarginUsedPct:         ctx.Account.MarginUsedPct,
 	}
@@ -662,20 +1176,20 @@ func (at *AutoTrader) runCycle() error {
 	// Note: For shared accounts, frontend will show proportional values per trader, but logs show actual account values
 	unrealizedPnL := ctx.Account.TotalEquity - ctx.Account.WalletBalance
 	marginUsed := ctx.Account.TotalEquity - ctx.Account.AvailableBalance
-	log.Printf("📊 Margin Balance (Equity): %.2f USDT | Wallet Balance: %.2f USDT | Available: %.2f USDT | Unrealized P&L: %.2f USDT | Positions: %d",
+	at.log.Infof("📊 Margin Balance (Equity): %.2f USDT | Wallet Balance: %.2f USDT | Available: %.2f USDT | Unrealized P&L: %.2f USDT | Positions: %d",
 		ctx.Account.TotalEquity, ctx.Account.WalletBalance, ctx.Account.AvailableBalance, unrealizedPnL, ctx.Account.PositionCount)
-	log.Printf("💡 Margin Used: %.2f USDT (%.1f%% of equity) - locked in %d open positions", marginUsed, (marginUsed/ctx.Account.TotalEquity)*100, ctx.Account.PositionCount)
+	at.log.Infof("💡 Margin Used: %.2f USDT (%.1f%% of equity) - locked in %d open positions", marginUsed, (marginUsed/ctx.Account.TotalEquity)*100, ctx.Account.PositionCount)
 
 	// Show breakdown of margin usage per position
 	if len(ctx.Positions) > 0 {
-		log.Printf("📋 Margin Breakdown by Position:")
+		at.log.Infof("📋 Margin Breakdown by Position:")
 		for _, pos := range ctx.Positions {
 			// Format P&L with color indicator
 			pnlSign := "+"
 			if pos.UnrealizedPnL < 0 {
 				pnlSign = ""
 			}
-			log.Printf("   • %s %s: %.2f USDT margin (%.1f%% of equity) | Notional: %.2f USDT | Leverage: %dx | P&L: %s%.2f USDT (%s%.2f%%)",
+			at.log.Infof("   • %s %s: %.2f USDT margin (%.1f%% of equity) | Notional: %.2f USDT | Leverage: %dx | P&L: %s%.2f USDT (%s%.2f%%)",
 				pos.Symbol, strings.ToUpper(pos.Side), pos.MarginUsed,
 				(pos.MarginUsed/ctx.Account.TotalEquity)*100,
 				pos.Quantity*pos.MarkPrice, pos.Leverage,
@@ -683,20 +1197,29 @@ func (at *AutoTrader) runCycle() error {
 		}
 	}
 
-	log.Printf("💡 Available = Equity (%.2f) - Margin Used (%.2f) = %.2f USDT", ctx.Account.TotalEquity, marginUsed, ctx.Account.AvailableBalance)
+	at.log.Infof("💡 Available = Equity (%.2f) - Margin Used (%.2f) = %.2f USDT", ctx.Account.TotalEquity, marginUsed, ctx.Account.AvailableBalance)
 	if ctx.Account.AvailableBalance < 0.01 {
-		log.Printf("⚠️  Available balance is $0 - all margin is used by open positions. This is normal when positions are open.")
+		at.log.Warnf("⚠️  Available balance is $0 - all margin is used by open positions. This is normal when positions are open.")
 	}
-	log.Printf("💡 Note: These are ACTUAL Binance account values (shared account). Frontend shows proportional values per trader.")
+	at.log.Infof("💡 Note: These are ACTUAL Binance account values (shared account). Frontend shows proportional values per trader.")
 
 	// 4. Call AI to get full decision (multi-agent or single-agent) OR copy from another trader
-	log.Println("🤖 Requesting AI analysis and decision...")
+	at.log.Infof("🤖 Requesting AI analysis and decision...")
 
 	var decision *decisionPkg.FullDecision
 	// err is already declared from buildTradingContext above
 
-	// Check if this trader should copy from another trader(s)
-	if at.config.CopyFromTraderID != "" && at.traderManager != nil {
+	// Signal cycles execute the webhook's decisions; otherwise check if this trader should copy from another trader(s)
+	if signal := at.currentSignal(); signal != nil {
+		at.log.Infof("📡 [Signal] Executing signal %s (%d decisions)", signal.ID, len(signal.Decisions))
+		decision, err = at.signalDecision(ctx, signal)
+		if err != nil {
+			record.Success = false
+			record.ErrorMessage = fmt.Sprintf("Signal %s: %v", signal.ID, err)
+			at.decisionLogger.LogDecision(record)
+			return err
+		}
+	} else if at.config.CopyFromTraderID != "" && at.traderManager != nil {
 		// Get trader manager (using type assertion)
 		type TraderManagerInterface interface {
 			GetTrader(id string) (*AutoTrader, error)
@@ -704,17 +1227,19 @@ func (at *AutoTrader) runCycle() error {
 		}
 		tm, ok := at.traderManager.(TraderManagerInterface)
 		if !ok {
-			log.Printf("⚠️  [Copy Trading] Failed to get trader manager, falling back to AI")
+			at.log.Warnf("⚠️  [Copy Trading] Failed to get trader manager, falling back to AI")
 		} else {
 			var allSourceDecisions []decisionPkg.Decision
 			var allCoTTraces []string
 			var sourceTraderNames []string
 			totalSourceEquity := 0.0
+			sourceFills := make(map[string]float64) // key: symbol_action - the sources' fill prices (price band)
+			nothingNew := false                     // Sources found, but their latest decisions were copied already or are too old
 
 			// Check if copying from all traders or specific trader
 			if at.config.CopyFromTraderID == "all" || at.config.CopyFromTraderID == "portfolio" {
 				// Copy from ALL traders (except itself)
-				log.Printf("📋 [Copy Trading] Copying decisions from ALL traders")
+				at.log.Infof("📋 [Copy Trading] Copying decisions from ALL traders")
 				allTraders := tm.GetAllTraders()
 				for traderID, sourceTrader := range allTraders {
 					// Skip self
@@ -730,6 +1255,11 @@ func (at *AutoTrader) runCycle() error {
 					if latestRecord.DecisionJSON == "" {
 						continue
 					}
+					if !at.copySourceFresh(traderID, latestRecord) {
+						nothingNew = true
+						continue
+					}
+					addCopyFills(sourceFills, latestRecord)
 					var traderDecisions []decisionPkg.Decision
 					if err := json.Unmarshal([]byte(latestRecord.DecisionJSON), &traderDecisions); err != nil {
 						continue
@@ -756,20 +1286,23 @@ func (at *AutoTrader) runCycle() error {
 				}
 			} else {
 				// Copy from specific trader
-				log.Printf("📋 [Copy Trading] Copying decisions from trader: %s", at.config.CopyFromTraderID)
+				at.log.Infof("📋 [Copy Trading] Copying decisions from trader: %s", at.config.CopyFromTraderID)
 				sourceTrader, err := tm.GetTrader(at.config.CopyFromTraderID)
 				if err != nil {
-					log.Printf("⚠️  [Copy Trading] Failed to get source trader '%s': %v, falling back to AI", at.config.CopyFromTraderID, err)
+					at.log.Warnf("⚠️  [Copy Trading] Failed to get source trader '%s': %v, falling back to AI", at.config.CopyFromTraderID, err)
 				} else {
 					// Get latest decision from source trader
 					sourceRecords, err := sourceTrader.GetDecisionLogger().GetLatestRecords(1)
 					if err != nil || len(sourceRecords) == 0 {
-						log.Printf("⚠️  [Copy Trading] No recent decisions from source trader, falling back to AI")
+						at.log.Warnf("⚠️  [Copy Trading] No recent decisions from source trader, falling back to AI")
 					} else {
 						latestRecord := sourceRecords[len(sourceRecords)-1]
-						if latestRecord.DecisionJSON != "" {
+						if !at.copySourceFresh(at.config.CopyFromTraderID, latestRecord) {
+							nothingNew = true
+						} else if latestRecord.DecisionJSON != "" {
+							addCopyFills(sourceFills, latestRecord)
 							if err := json.Unmarshal([]byte(latestRecord.DecisionJSON), &allSourceDecisions); err != nil {
-								log.Printf("⚠️  [Copy Trading] Failed to parse source decision JSON: %v, falling back to AI", err)
+								at.log.Warnf("⚠️  [Copy Trading] Failed to parse source decision JSON: %v, falling back to AI", err)
 							} else {
 								if latestRecord.CoTTrace != "" {
 									allCoTTraces = append(allCoTTraces, fmt.Sprintf("=== %s ===\n%s", sourceTrader.GetName(), latestRecord.CoTTrace))
@@ -795,21 +1328,16 @@ func (at *AutoTrader) runCycle() error {
 					currentEquity = at.initialBalance
 				}
 
-				equityRatio := 1.0
-				if totalSourceEquity > 0 {
-					equityRatio = currentEquity / totalSourceEquity
-				}
+				sizeRatio := at.copySizeRatio(currentEquity, totalSourceEquity)
 
-				log.Printf("📊 [Copy Trading] Source equity: %.2f, Current equity: %.2f, Ratio: %.2f",
-					totalSourceEquity, currentEquity, equityRatio)
+				at.log.Infof("📊 [Copy Trading] Source equity: %.2f, Current equity: %.2f, Size ratio: %.2f",
+					totalSourceEquity, currentEquity, sizeRatio)
 
 				// Get current positions to verify close decisions are valid
 				currentPositions, _ := at.trader.GetPositions()
 				positionMap := make(map[string]bool) // key: "SYMBOL_SIDE" (e.g., "ETHUSDT_LONG")
 				for _, pos := range currentPositions {
-					posSymbol, _ := pos["symbol"].(string)
-					posSide, _ := pos["side"].(string)
-					key := fmt.Sprintf("%s_%s", strings.ToUpper(posSymbol), strings.ToUpper(posSide))
+					key := fmt.Sprintf("%s_%s", strings.ToUpper(pos.Symbol), strings.ToUpper(pos.Side))
 					positionMap[key] = true
 				}
 
@@ -829,12 +1357,27 @@ func (at *AutoTrader) runCycle() error {
 						}
 						posKey := fmt.Sprintf("%s_%s", strings.ToUpper(d.Symbol), side)
 						if !positionMap[posKey] {
-							log.Printf("⚠️  [Copy Trading] Skipping %s %s - position does not exist in this account", d.Symbol, d.Action)
+							at.log.Warnf("⚠️  [Copy Trading] Skipping %s %s - position does not exist in this account", d.Symbol, d.Action)
+							continue
+						}
+					}
+
+					// Amend actions also need the position in this account
+					if decisionPkg.IsAmendAction(d.Action) && d.Side != "" {
+						posKey := fmt.Sprintf("%s_%s", strings.ToUpper(d.Symbol), strings.ToUpper(d.Side))
+						if !positionMap[posKey] {
+							at.log.Warnf("⚠️  [Copy Trading] Skipping %s %s - position does not exist in this account", d.Symbol, d.Action)
 							continue
 						}
 					}
 
-					key := fmt.Sprintf("%s_%s", d.Symbol, d.Action)
+					// Opens and adds only while the price is still near the source's fill
+					if reason := at.copyPriceMoved(d, sourceFills, ctx.MarketDataMap); reason != "" {
+						at.log.Warnf("⚠️  [Copy Trading] Skipping %s %s - %s", d.Symbol, d.Action, reason)
+						continue
+					}
+
+					key := fmt.Sprintf("%s_%s_%s", d.Symbol, d.Action, d.Side)
 					if _, exists := decisionMap[key]; !exists {
 						decisionMap[key] = d
 					}
@@ -844,9 +1387,11 @@ func (at *AutoTrader) runCycle() error {
 				scaledDecisions := make([]decisionPkg.Decision, 0, len(decisionMap))
 				for _, d := range decisionMap {
 					scaledDecision := d
-					// Scale position size proportionally
-					if d.PositionSizeUSD > 0 {
-						scaledDecision.PositionSizeUSD = d.PositionSizeUSD * equityRatio
+					// Scale position size proportionally (add_margin / add_* only scale - minimums apply to new positions)
+					if d.PositionSizeUSD > 0 && (d.Action == "add_margin" || decisionPkg.IsAddAction(d.Action)) {
+						scaledDecision.PositionSizeUSD = d.PositionSizeUSD * sizeRatio
+					} else if d.PositionSizeUSD > 0 {
+						scaledDecision.PositionSizeUSD = d.PositionSizeUSD * sizeRatio
 						// Ensure minimum position size (20% of equity for BTC/ETH, 15% for altcoins)
 						minSizeBTCETH := currentEquity * 0.20
 						minSizeAltcoin := currentEquity * 0.15
@@ -878,50 +1423,74 @@ func (at *AutoTrader) runCycle() error {
 					CoTTrace:    combinedCoT,
 					Decisions:   scaledDecisions,
 					RawResponse: fmt.Sprintf("Copied from %s", strings.Join(sourceTraderNames, ", ")),
-					Timestamp:   time.Now(),
+					Timestamp:   at.now(),
 				}
 
-				log.Printf("✅ [Copy Trading] Successfully copied %d decisions from: %s", len(scaledDecisions), strings.Join(sourceTraderNames, ", "))
+				at.log.Infof("✅ [Copy Trading] Successfully copied %d decisions from: %s", len(scaledDecisions), strings.Join(sourceTraderNames, ", "))
+				record.Source = logger.DecisionSourceCopy
 				err = nil // Clear any previous errors
+			} else if nothingNew {
+				// Wait for the sources' next decisions instead of trading on this trader's own strategy
+				decision = &decisionPkg.FullDecision{
+					UserPrompt:  "Copy trading: no new source decision",
+					CoTTrace:    "📋 [Copy Trading] The sources' latest decisions were already copied or are older than copy_trading.max_age_minutes",
+					Decisions:   []decisionPkg.Decision{},
+					RawResponse: "No new source decision",
+					Timestamp:   at.now(),
+				}
+				record.Source = logger.DecisionSourceCopy
+				err = nil
 			}
 		}
 	}
 
-	// If copy trading didn't produce a decision, use AI (normal flow)
+	// Signals-only traders wait between signals
+	if decision == nil && at.config.SignalWebhook != nil && at.config.SignalWebhook.Exclusive {
+		decision = at.signalWaitDecision()
+	}
+
+	// If copy trading didn't produce a decision, use the configured strategy (normal flow)
 	if decision == nil {
-		// Check if multi-agent is enabled
-		if at.multiAgentConfig != nil {
+		// Check if multi-agent is enabled (multi-agent consensus replaces the AI engine, not rule-based strategies)
+		if at.multiAgentConfig != nil && at.UsesAI() {
 			// Use multi-agent consensus
 			cfg, ok := at.multiAgentConfig.(*config.MultiAgentConfig)
 			if ok && cfg != nil && cfg.Enabled {
 				// Convert config.MultiAgentConfig to multiagent.MultiAgentConfig
 				maConfig := convertToMultiAgentConfig(cfg)
 				if maConfig != nil {
-					log.Printf("🤖 [Multi-Agent] Using multi-agent consensus (mode: %s)", maConfig.ConsensusMode)
-					decision, err = multiagent.GetMultiAgentDecision(ctx, maConfig)
+					at.log.Infof("🤖 [Multi-Agent] Using multi-agent consensus (mode: %s)", maConfig.ConsensusMode)
+					if maConfig.ConsensusMode == "sharpe" {
+						maConfig.AgentSharpe = at.agentSharpe(cfg.SharpeLookbackCycles, cfg.SharpeMinTrades)
+					}
+					var transcript *multiagent.Transcript
+					decision, transcript, err = multiagent.GetMultiAgentDecision(ctx, maConfig)
+					record.AgentDebate = agentDebateRecord(transcript)
 					if err != nil {
-						log.Printf("⚠️  Multi-agent decision failed, falling back to single-agent: %v", err)
+						at.log.Warnf("⚠️  Multi-agent decision failed, falling back to single-agent: %v", err)
 						// Fallback to single-agent
-						decision, err = decisionPkg.GetFullDecision(ctx, at.mcpClient)
+						decision, err = at.getAIDecision(cycleCtx, ctx)
+					} else {
+						record.ConsensusMode = maConfig.ConsensusMode
 					}
 				} else {
-					log.Printf("⚠️  Failed to convert multi-agent config, using single-agent")
-					decision, err = decisionPkg.GetFullDecision(ctx, at.mcpClient)
+					at.log.Warnf("⚠️  Failed to convert multi-agent config, using single-agent")
+					decision, err = at.getAIDecision(cycleCtx, ctx)
 				}
 			} else {
 				// Multi-agent config exists but not enabled, use single-agent
-				decision, err = decisionPkg.GetFullDecision(ctx, at.mcpClient)
+				decision, err = at.getAIDecision(cycleCtx, ctx)
 			}
 		} else {
 			// No multi-agent config, use single-agent
-			decision, err = decisionPkg.GetFullDecision(ctx, at.mcpClient)
+			decision, err = at.getAIDecision(cycleCtx, ctx)
 		}
 	}
 
 	// Save chain of thought, decision, and input prompt even if there's an error (for debugging)
 	// CRITICAL: GetFullDecision should always return a decision (with fallback), so decision should never be nil
 	if decision == nil {
-		log.Printf("⚠️  CRITICAL: GetFullDecision returned nil decision - this should never happen due to fallback")
+		at.log.Warnf("⚠️  CRITICAL: GetFullDecision returned nil decision - this should never happen due to fallback")
 		// Create emergency fallback
 		decision = &decisionPkg.FullDecision{
 			CoTTrace: "Emergency fallback - GetFullDecision returned nil",
@@ -936,9 +1505,44 @@ func (at *AutoTrader) runCycle() error {
 		err = nil // Clear error since we have fallback
 	}
 
+	if cycleCtx.Err() != nil {
+		// Stopped while waiting for the AI: nothing has been executed, leave the decisions unused
+		at.recordShutdownDuringCycle(record, "AI request aborted, no decisions executed")
+		at.decisionLogger.LogDecision(record)
+		return ErrShutdownDuringCycle
+	}
+
+	// Decisions rejected by validation are not executed; record them so their outcome can be simulated
+	for _, r := range decision.Rejected {
+		at.log.Infof("🚫 Rejected %s %s (%s): %s", r.Decision.Symbol, r.Decision.Action, r.Category, r.Reason)
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("🚫 Rejected %s %s by validation: %s", r.Decision.Symbol, r.Decision.Action, r.Reason))
+	}
+	if len(decision.Rejected) > 0 {
+		if added := at.rejectedTrades.Record(decision.Rejected, ctx.MarketDataMap); added > 0 {
+			at.log.Infof("🚫 Recorded %d rejected open decisions for outcome simulation", added)
+		}
+	}
+
 	record.InputPrompt = decision.UserPrompt
+	record.PromptTemplate = decision.PromptTemplate
+	record.PromptVersion = decision.PromptVersion
+	record.RiskPolicy = at.riskPolicy.Label()
 	record.CoTTrace = decision.CoTTrace
 	record.RawResponse = decision.RawResponse // Save raw response for debugging
+	if at.config.DropRawResponse {
+		record.RawResponse = ""
+	}
+	if u := decision.Usage; u != nil && u.Calls > 0 {
+		record.AIUsage = &logger.AIUsage{
+			Model:            u.Model,
+			Calls:            u.Calls,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			CostUSD:          u.CostUSD,
+		}
+		at.log.Infof("🪙 AI usage: %d call(s), %d prompt + %d completion tokens, ~$%.4f (%s)",
+			u.Calls, u.PromptTokens, u.CompletionTokens, u.CostUSD, u.Model)
+	}
 
 	// Log raw response preview if parsing failed
 	if decision.RawResponse != "" && err != nil {
@@ -946,7 +1550,7 @@ func (at *AutoTrader) runCycle() error {
 		if len(rawResponsePreview) > 500 {
 			rawResponsePreview = rawResponsePreview[:500] + "..."
 		}
-		log.Printf("🔍 Raw AI Response (first 500 chars): %s", rawResponsePreview)
+		at.log.Infof("🔍 Raw AI Response (first 500 chars): %s", rawResponsePreview)
 	}
 
 	if len(decision.Decisions) > 0 {
@@ -967,11 +1571,11 @@ func (at *AutoTrader) runCycle() error {
 
 			// Print AI chain of thought (even if there's an error)
 			if decision.CoTTrace != "" {
-				log.Printf("\n" + strings.Repeat("-", 70))
-				log.Println("💭 AI Chain of Thought Analysis (error case):")
-				log.Println(strings.Repeat("-", 70))
-				log.Println(decision.CoTTrace)
-				log.Printf(strings.Repeat("-", 70) + "\n")
+				at.log.Infof("%s", strings.Repeat("-", 70))
+				at.log.Infof("💭 AI Chain of Thought Analysis (error case):")
+				at.log.Infof("%s", strings.Repeat("-", 70))
+				at.log.Infof("%s", decision.CoTTrace)
+				at.log.Infof("%s", strings.Repeat("-", 70))
 			}
 
 			at.decisionLogger.LogDecision(record)
@@ -985,46 +1589,65 @@ func (at *AutoTrader) runCycle() error {
 		if strings.Contains(errStr, "extract decisions") || strings.Contains(errStr, "parse AI response") ||
 			strings.Contains(errStr, "JSON") || strings.Contains(errStr, "unable to find") {
 			record.ErrorMessage = fmt.Sprintf("JSON parsing failed, used fallback decision: %v", err)
-			log.Printf("⚠️  JSON parsing failed but fallback decision exists - continuing cycle (error should have been cleared by GetFullDecision)")
+			at.log.Warnf("⚠️  JSON parsing failed but fallback decision exists - continuing cycle (error should have been cleared by GetFullDecision)")
 		} else {
 			record.ErrorMessage = fmt.Sprintf("Warning: %v (but continuing with decisions)", err)
-			log.Printf("⚠️  Warning: %v (but continuing with available decisions - error should have been cleared by GetFullDecision)", err)
+			at.log.Warnf("⚠️  Warning: %v (but continuing with available decisions - error should have been cleared by GetFullDecision)", err)
 		}
 
-		log.Printf("💭 AI Chain of Thought Analysis (using fallback/safety decision):")
-		log.Println(strings.Repeat("-", 70))
-		log.Println(decision.CoTTrace)
-		log.Printf(strings.Repeat("-", 70) + "\n")
+		at.log.Infof("💭 AI Chain of Thought Analysis (using fallback/safety decision):")
+		at.log.Infof("%s", strings.Repeat("-", 70))
+		at.log.Infof("%s", decision.CoTTrace)
+		at.log.Infof("%s", strings.Repeat("-", 70))
 		// Clear the error so the cycle continues successfully
 		err = nil
 	}
 
 	// 5. Print AI chain of thought
-	log.Printf("\n" + strings.Repeat("-", 70))
-	log.Println("💭 AI Chain of Thought Analysis:")
-	log.Println(strings.Repeat("-", 70))
-	log.Println(decision.CoTTrace)
-	log.Printf(strings.Repeat("-", 70) + "\n")
+	at.log.Infof("%s", strings.Repeat("-", 70))
+	at.log.Infof("💭 AI Chain of Thought Analysis:")
+	at.log.Infof("%s", strings.Repeat("-", 70))
+	at.log.Infof("%s", decision.CoTTrace)
+	at.log.Infof("%s", strings.Repeat("-", 70))
 
 	// 6. Print AI decisions
-	log.Printf("📋 AI Decision List (%d items):\n", len(decision.Decisions))
+	at.log.Infof("📋 AI Decision List (%d items):", len(decision.Decisions))
 	for i, d := range decision.Decisions {
-		log.Printf("  [%d] %s: %s - %s", i+1, d.Symbol, d.Action, d.Reasoning)
-		if d.Action == "open_long" || d.Action == "open_short" {
-			log.Printf("      Leverage: %dx | Position: %.2f USDT | Stop Loss: %.4f | Take Profit: %.4f",
+		at.log.Infof("  [%d] %s: %s - %s", i+1, d.Symbol, d.Action, d.Reasoning)
+		if decisionPkg.IsEntryAction(d.Action) {
+			at.log.Infof("      Leverage: %dx | Position: %.2f USDT | Stop Loss: %.4f | Take Profit: %.4f",
 				d.Leverage, d.PositionSizeUSD, d.StopLoss, d.TakeProfit)
 		}
 	}
-	log.Println()
+
+	// Risk officer: veto or downsize opens against account state and market regime
+	if at.riskOfficer != nil {
+		var review *logger.RiskReview
+		decision.Decisions, review = at.riskOfficer.review(cycleCtx, ctx, decision.Decisions)
+		if review != nil {
+			record.RiskReview = review
+			if u := review.AIUsage; u != nil {
+				if record.AIUsage == nil {
+					record.AIUsage = &logger.AIUsage{}
+				}
+				record.AIUsage.Add(*u)
+			}
+			for _, v := range review.Verdicts {
+				if v.Verdict != logger.RiskApprove {
+					record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("🛡️ Risk officer: %s %s %s (%.2f → %.2f USDT): %s",
+						v.Verdict, v.Symbol, v.Action, v.SizeBefore, v.SizeAfter, v.Reason))
+				}
+			}
+		}
+	}
 
 	// 7. Sort decisions: ensure close positions before opening (prevent position stacking overflow)
 	sortedDecisions := sortDecisionsByPriority(decision.Decisions)
 
-	log.Println("🔄 Execution Order (optimized): Close positions first → Open positions later")
+	at.log.Infof("🔄 Execution Order (optimized): Close positions first → Open positions later")
 	for i, d := range sortedDecisions {
-		log.Printf("  [%d] %s %s", i+1, d.Symbol, d.Action)
+		at.log.Infof("  [%d] %s %s", i+1, d.Symbol, d.Action)
 	}
-	log.Println()
 
 	// 7.5. Validate: Limit new positions to prevent margin exhaustion
 	currentPositions, _ := at.trader.GetPositions()
@@ -1038,21 +1661,21 @@ func (at *AutoTrader) runCycle() error {
 		}
 	}
 
-	// Maximum 6 total positions (hard limit) – small position sizing keeps margin safe
-	maxPositions := 6
+	// Maximum total positions of the risk policy (hard limit) – small position sizing keeps margin safe
+	maxPositions := at.riskPolicy.MaxPositions
 	availableSlots := maxPositions - currentPositionCount
 
 	if newPositionCount > availableSlots {
-		log.Printf("⚠️  AI tried to open %d new positions, but only %d slots available (current: %d, max: %d)",
+		at.log.Warnf("⚠️  AI tried to open %d new positions, but only %d slots available (current: %d, max: %d)",
 			newPositionCount, availableSlots, currentPositionCount, maxPositions)
-		log.Printf("⚠️  Rejecting excess position openings. Only opening first %d positions.", availableSlots)
+		at.log.Warnf("⚠️  Rejecting excess position openings. Only opening first %d positions.", availableSlots)
 
 		// Filter out excess open positions
 		var filteredDecisions []decisionPkg.Decision
 		openedCount := 0
 		for _, d := range sortedDecisions {
 			if (d.Action == "open_long" || d.Action == "open_short") && openedCount >= availableSlots {
-				log.Printf("  ⏭ Skipping %s %s (would exceed position limit)", d.Symbol, d.Action)
+				at.log.Infof("  ⏭ Skipping %s %s (would exceed position limit)", d.Symbol, d.Action)
 				record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("⏭ Skipped %s %s (position limit reached)", d.Symbol, d.Action))
 				continue
 			}
@@ -1072,22 +1695,31 @@ func (at *AutoTrader) runCycle() error {
 			Quantity:  0,
 			Leverage:  d.Leverage,
 			Price:     0,
-			Timestamp: time.Now(),
+			Timestamp: at.now(),
 			Success:   false,
 		}
 
-		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
-			log.Printf("❌ Failed to execute decision (%s %s): %v", d.Symbol, d.Action, err)
+		if err := at.executeDecisionWithRecord(cycleCtx, &d, &actionRecord); err != nil {
+			if errors.Is(err, ErrShutdownDuringCycle) {
+				at.recordShutdownDuringCycle(record, fmt.Sprintf("%d decision(s) not executed", len(sortedDecisions)-len(record.Decisions)))
+				break
+			}
+			at.log.Errorf("❌ Failed to execute decision (%s %s): %v", d.Symbol, d.Action, err)
 			if errors.Is(err, ErrMarginInsufficient) {
-				log.Printf("   ↳ Margin alert: %s %s skipped due to insufficient free margin", d.Symbol, d.Action)
+				at.log.Infof("   ↳ Margin alert: %s %s skipped due to insufficient free margin", d.Symbol, d.Action)
 			}
 			actionRecord.Error = err.Error()
 			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s failed: %v", d.Symbol, d.Action, err))
 		} else {
 			actionRecord.Success = true
 			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s succeeded", d.Symbol, d.Action))
-			// Brief delay after successful execution
-			time.Sleep(1 * time.Second)
+			// Brief delay after successful execution (not needed against the simulated market); cut short by shutdown
+			if at.sim == nil {
+				select {
+				case <-cycleCtx.Done():
+				case <-time.After(1 * time.Second):
+				}
+			}
 		}
 
 		record.Decisions = append(record.Decisions, actionRecord)
@@ -1097,27 +1729,17 @@ func (at *AutoTrader) runCycle() error {
 	// This ensures newly opened positions and updated balances are saved to the database
 	currentBalance, err := at.trader.GetBalance()
 	if err == nil {
-		totalWalletBalance := 0.0
-		totalUnrealizedProfit := 0.0
-		availableBalance := 0.0
-
-		if wallet, ok := currentBalance["totalWalletBalance"].(float64); ok {
-			totalWalletBalance = wallet
-		}
-		if unrealized, ok := currentBalance["totalUnrealizedProfit"].(float64); ok {
-			totalUnrealizedProfit = unrealized
-		}
-		if avail, ok := currentBalance["availableBalance"].(float64); ok {
-			availableBalance = avail
-		}
+		totalWalletBalance := currentBalance.WalletBalance
+		totalUnrealizedProfit := currentBalance.UnrealizedProfit
+		availableBalance := currentBalance.AvailableBalance
 
-		totalEquity := totalWalletBalance + totalUnrealizedProfit
+		totalEquity := addUSDT(totalWalletBalance, totalUnrealizedProfit)
 
 		// Update account state snapshot with latest values
 		record.AccountState.TotalBalance = totalEquity
 		record.AccountState.AvailableBalance = availableBalance
 		record.AccountState.TotalUnrealizedProfit = totalUnrealizedProfit
-		log.Printf("💾 Updated account state: Equity=%.2f, Available=%.2f, Unrealized=%.2f",
+		at.log.Infof("💾 Updated account state: Equity=%.2f, Available=%.2f, Unrealized=%.2f",
 			totalEquity, availableBalance, totalUnrealizedProfit)
 	}
 
@@ -1126,79 +1748,107 @@ func (at *AutoTrader) runCycle() error {
 		// Clear old position snapshots and update with current positions
 		record.Positions = []logger.PositionSnapshot{}
 		for _, pos := range currentPositions {
-			symbol := pos["symbol"].(string)
-			side := pos["side"].(string)
-			entryPrice := pos["entryPrice"].(float64)
-			markPrice := pos["markPrice"].(float64)
-			quantity := pos["positionAmt"].(float64)
-			if quantity < 0 {
-				quantity = -quantity // Convert negative shorts to positive
-			}
-			unrealizedPnl := pos["unRealizedProfit"].(float64)
-			liquidationPrice := pos["liquidationPrice"].(float64)
-
 			leverage := 10.0
-			if lev, ok := pos["leverage"].(float64); ok {
-				leverage = lev
+			if pos.Leverage > 0 {
+				leverage = float64(pos.Leverage)
 			}
 
 			record.Positions = append(record.Positions, logger.PositionSnapshot{
-				Symbol:           symbol,
-				Side:             side,
-				PositionAmt:      quantity,
-				EntryPrice:       entryPrice,
-				MarkPrice:        markPrice,
-				UnrealizedProfit: unrealizedPnl,
+				Symbol:           pos.Symbol,
+				Side:             pos.Side,
+				PositionAmt:      pos.Quantity,
+				EntryPrice:       pos.EntryPrice,
+				MarkPrice:        pos.MarkPrice,
+				UnrealizedProfit: pos.UnrealizedProfit,
 				Leverage:         leverage,
-				LiquidationPrice: liquidationPrice,
+				LiquidationPrice: pos.LiquidationPrice,
 			})
 		}
 		// Update position count in account state
 		record.AccountState.PositionCount = len(record.Positions)
-		log.Printf("💾 Updated position snapshots: %d positions (including newly opened)", len(record.Positions))
+		at.log.Infof("💾 Updated position snapshots: %d positions (including newly opened)", len(record.Positions))
 	} else {
-		log.Printf("⚠️  Failed to refresh positions before logging: %v", err)
+		at.log.Warnf("⚠️  Failed to refresh positions before logging: %v", err)
 	}
 
-	// 9. Save decision record (now includes positions opened in this cycle)
+	// 9. Save decision record (now includes positions opened in this cycle), with fill prices where the exchange
+	// reports them
+	for i := range record.Decisions {
+		at.orderTracker.ApplyFill(&record.Decisions[i])
+	}
 	if err := at.decisionLogger.LogDecision(record); err != nil {
-		log.Printf("⚠ Failed to save decision record: %v", err)
+		at.log.Warnf("⚠ Failed to save decision record: %v", err)
 	}
 
 	return nil
 }
 
+// getAIDecision asks the configured strategy (the AI engine by default) for this cycle's decisions
+// (reqCtx is cancelled when the trader stops)
+func (at *AutoTrader) getAIDecision(reqCtx context.Context, ctx *decisionPkg.Context) (*decisionPkg.FullDecision, error) {
+	if at.config.AIRequest.CompactTimeoutSeconds > 0 {
+		ctx.CompactRetryTimeout = time.Duration(at.config.AIRequest.CompactTimeoutSeconds) * time.Second
+	}
+	decision, err := at.strategy.Decide(reqCtx, ctx)
+
+	at.lastContextMu.Lock()
+	at.lastContext = ctx
+	at.lastContextMu.Unlock()
+	return decision, err
+}
+
+// GetLastContext returns the context of the latest AI decision, including market breadth (nil before the first cycle)
+func (at *AutoTrader) GetLastContext() *decisionPkg.Context {
+	at.lastContextMu.RLock()
+	defer at.lastContextMu.RUnlock()
+	return at.lastContext
+}
+
+// UsesAI whether the trader's decisions come from the AI provider (false for rule-based strategies)
+func (at *AutoTrader) UsesAI() bool {
+	return at.strategy.Name() == decisionPkg.StrategyAI
+}
+
+// GetPromptTemplate the templates the trader's AI prompts are rendered from
+func (at *AutoTrader) GetPromptTemplate() *decisionPkg.PromptTemplate {
+	return at.promptTemplate
+}
+
+// performanceLookback cycles loaded for performance analysis
+func (at *AutoTrader) performanceLookback() int {
+	if at.config.PerformanceLookback > 0 {
+		return at.config.PerformanceLookback
+	}
+	return 100
+}
+
 // buildTradingContext Builds trading context
 func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 	// 1. Get account information
 	balance, err := at.trader.GetBalance()
 	if err != nil {
+		at.alertExchangeError("get its account balance", err)
 		return nil, fmt.Errorf("failed to get account balance: %w", err)
 	}
 
 	// Get account fields
-	totalWalletBalance := 0.0
-	totalUnrealizedProfit := 0.0
-	availableBalance := 0.0
-
-	if wallet, ok := balance["totalWalletBalance"].(float64); ok {
-		totalWalletBalance = wallet
-	}
-	if unrealized, ok := balance["totalUnrealizedProfit"].(float64); ok {
-		totalUnrealizedProfit = unrealized
-	}
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
-	}
+	totalWalletBalance := balance.WalletBalance
+	totalUnrealizedProfit := balance.UnrealizedProfit
+	availableBalance := balance.AvailableBalance
 
 	// Total Equity = Wallet Balance + Unrealized P&L
-	totalEquity := totalWalletBalance + totalUnrealizedProfit
+	totalEquity := addUSDT(totalWalletBalance, totalUnrealizedProfit)
+
+	// Realized P&L from before this session is whatever the wallet already banked
+	at.pnlLedger.Seed(totalWalletBalance, at.initialBalance)
 
 	// 2. Get position information
 	positions, err := at.trader.GetPositions()
 	if err != nil {
+		at.alertExchangeError("get its positions", err)
 		return nil, fmt.Errorf("failed to get positions: %w", err)
 	}
+	at.syncOwnership(positions)
 
 	var positionInfos []decisionPkg.PositionInfo
 	totalMarginUsed := 0.0
@@ -1207,24 +1857,21 @@ func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 	currentPositionKeys := make(map[string]bool)
 
 	for _, pos := range positions {
-		symbol := pos["symbol"].(string)
-		side := pos["side"].(string)
-		entryPrice := pos["entryPrice"].(float64)
-		markPrice := pos["markPrice"].(float64)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity // Short position quantity is negative, convert to positive
-		}
-		unrealizedPnl := pos["unRealizedProfit"].(float64)
-		liquidationPrice := pos["liquidationPrice"].(float64)
+		symbol := pos.Symbol
+		side := pos.Side
+		entryPrice := pos.EntryPrice
+		markPrice := pos.MarkPrice
+		quantity := pos.Quantity
+		unrealizedPnl := pos.UnrealizedProfit
+		liquidationPrice := pos.LiquidationPrice
 
 		// Calculate used margin (estimate)
-		leverage := 10 // Default value, should actually get from position info
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
+		leverage := 10 // Default when the exchange does not report it
+		if pos.Leverage > 0 {
+			leverage = pos.Leverage
 		}
-		marginUsed := (quantity * markPrice) / float64(leverage)
-		totalMarginUsed += marginUsed
+		marginUsed := marginForQuantity(quantity, markPrice, leverage)
+		totalMarginUsed = addUSDT(totalMarginUsed, marginUsed)
 
 		// Calculate P&L percentage
 		pnlPct := 0.0
@@ -1234,14 +1881,12 @@ func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 			pnlPct = ((entryPrice - markPrice) / entryPrice) * float64(leverage) * 100
 		}
 
-		// Track position first seen time
+		// Entry time (first seen time for positions of unknown origin)
 		posKey := symbol + "_" + side
 		currentPositionKeys[posKey] = true
-		if _, exists := at.positionFirstSeenTime[posKey]; !exists {
-			// New position, record current time
-			at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
-		}
-		updateTime := at.positionFirstSeenTime[posKey]
+		updateTime := at.positionOrigins.seen(symbol, side, at.now())
+
+		levels, _ := at.positionProtection.levels(symbol, side)
 
 		positionInfos = append(positionInfos, decisionPkg.PositionInfo{
 			Symbol:           symbol,
@@ -1255,24 +1900,25 @@ func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 			LiquidationPrice: liquidationPrice,
 			MarginUsed:       marginUsed,
 			UpdateTime:       updateTime,
+			StopLoss:         levels.StopLoss,
+			TakeProfit:       levels.TakeProfit,
 		})
 	}
 
-	// Clean up closed position records
-	for key := range at.positionFirstSeenTime {
-		if !currentPositionKeys[key] {
-			delete(at.positionFirstSeenTime, key)
-		}
-	}
+	// Clean up closed position records (and the protection orders a stop or take profit fill left resting)
+	at.positionOrigins.prune(currentPositionKeys)
+	at.positionProtection.prune(currentPositionKeys)
 
-	// 3. Get merged candidate coin pool (AI500 + OI Top, deduplicated)
+	// 3. Get merged candidate coin pool (AI500 + OI Top + custom sources, deduplicated, highest score first)
 	// Analyze the same number of coins regardless of positions (let AI see all good opportunities)
 	// AI will decide whether to switch positions based on margin usage rate and existing positions
-	const ai500Limit = 20 // AI500 takes top 20 highest-scored coins
+	ai500Limit := at.candidateLimit()
 
-	// Get merged coin pool (AI500 + OI Top)
-	mergedPool, err := pool.GetMergedCoinPool(ai500Limit)
-	if err != nil {
+	// Get merged coin pool (AI500 + OI Top; the simulated symbols in simulate mode)
+	var mergedPool *pool.MergedCoinPool
+	if at.sim != nil {
+		mergedPool = at.simulatedCoinPool()
+	} else if mergedPool, err = pool.GetMergedCoinPool(ai500Limit); err != nil {
 		return nil, fmt.Errorf("failed to get merged coin pool: %w", err)
 	}
 
@@ -1282,15 +1928,21 @@ func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 		sources := mergedPool.SymbolSources[symbol]
 		candidateCoins = append(candidateCoins, decisionPkg.CandidateCoin{
 			Symbol:  symbol,
-			Sources: sources, // "ai500" and/or "oi_top"
+			Sources: sources, // "ai500", "oi_top" and/or custom source names
 		})
 	}
 
-	log.Printf("📋 Merged coin pool: AI500 top %d + OI_Top20 = Total %d candidate coins",
-		ai500Limit, len(candidateCoins))
+	// Cap candidate pool size (low-memory mode) - prefer coins confirmed by both sources
+	// (truncated after the adaptive pool has had a chance to promote proven symbols)
+	capCandidates := at.config.MaxCandidateCoins > 0 && len(candidateCoins) > at.config.MaxCandidateCoins
+	if capCandidates {
+		sort.SliceStable(candidateCoins, func(i, j int) bool {
+			return len(candidateCoins[i].Sources) > len(candidateCoins[j].Sources)
+		})
+	}
 
 	// 4. Calculate total P&L
-	totalPnL := totalEquity - at.initialBalance
+	totalPnL := addUSDT(totalEquity, -at.initialBalance)
 	totalPnLPct := 0.0
 	if at.initialBalance > 0 {
 		totalPnLPct = (totalPnL / at.initialBalance) * 100
@@ -1300,23 +1952,44 @@ func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 	if totalEquity > 0 {
 		marginUsedPct = (totalMarginUsed / totalEquity) * 100
 	}
+	at.alertLiquidationRisk(positionInfos, totalEquity, marginUsedPct)
 
 	// 5. Analyze historical performance (recent 100 cycles, avoid losing trading records for long-term positions)
 	// Assume 3 minutes per cycle, 100 cycles = 5 hours, sufficient to cover most trades
-	performance, err := at.decisionLogger.AnalyzePerformance(100)
+	performance, err := at.decisionLogger.AnalyzePerformance(at.performanceLookback())
 	if err != nil {
-		log.Printf("⚠️  Failed to analyze historical performance: %v", err)
+		at.log.Warnf("⚠️  Failed to analyze historical performance: %v", err)
 		// Doesn't affect main flow, continue execution (but set performance to nil to avoid passing error data)
 		performance = nil
 	}
 
+	// 5.1 Bias candidate pool by this trader's per-symbol track record
+	var poolAdjustments []decisionPkg.PoolAdjustment
+	if performance != nil {
+		openSymbols := make(map[string]bool)
+		for _, pos := range positionInfos {
+			openSymbols[pos.Symbol] = true
+		}
+		candidateCoins, poolAdjustments = applyAdaptivePool(candidateCoins, performance, at.config.AdaptivePool, openSymbols)
+		if len(poolAdjustments) > 0 {
+			at.log.Infof("🎯 Adaptive pool: %d symbols adjusted by trading history", len(poolAdjustments))
+		}
+	}
+	if capCandidates && len(candidateCoins) > at.config.MaxCandidateCoins {
+		candidateCoins = candidateCoins[:at.config.MaxCandidateCoins]
+	}
+
+	at.log.Infof("📋 Merged coin pool: AI500 top %d + OI_Top20 = Total %d candidate coins",
+		ai500Limit, len(candidateCoins))
+
 	// 6. Build context
+	btcEthLeverage, altcoinLeverage := at.leverageLimits()
 	ctx := &decisionPkg.Context{
-		CurrentTime:     time.Now().Format("2006-01-02 15:04:05"),
-		RuntimeMinutes:  int(time.Since(at.startTime).Minutes()),
+		CurrentTime:     at.now().Format("2006-01-02 15:04:05"),
+		RuntimeMinutes:  int(at.now().Sub(at.startTime).Minutes()),
 		CallCount:       at.callCount,
-		BTCETHLeverage:  at.config.BTCETHLeverage,  // Use configured leverage multiplier
-		AltcoinLeverage: at.config.AltcoinLeverage, // Use configured leverage multiplier
+		BTCETHLeverage:  btcEthLeverage,  // Use configured leverage multiplier
+		AltcoinLeverage: altcoinLeverage, // Use configured leverage multiplier
 		Account: decisionPkg.AccountInfo{
 			TotalEquity:      totalEquity,
 			WalletBalance:    totalWalletBalance, // Actual wallet balance from API
@@ -1326,17 +1999,91 @@ func (at *AutoTrader) buildTradingContext() (*decisionPkg.Context, error) {
 			MarginUsed:       totalMarginUsed,
 			MarginUsedPct:    marginUsedPct,
 			PositionCount:    len(positionInfos),
+			RealizedPnL:      at.pnlLedger.Summary().RealizedPnL,
+			UnrealizedPnL:    totalUnrealizedProfit,
 		},
-		Positions:      positionInfos,
-		CandidateCoins: candidateCoins,
-		Performance:    performance, // Add historical performance analysis
+		Positions:       positionInfos,
+		NetExposure:     decisionPkg.NetExposures(positionInfos),
+		CandidateCoins:  candidateCoins,
+		Performance:     performance, // Add historical performance analysis
+		CoinPoolNotes:   mergedPool.StaleNotes,
+		PoolAdjustments: poolAdjustments,
+	}
+
+	// 6.5. Limit entries: offered when the exchange supports them, resting ones shown so the AI does not repeat them
+	if at.limitEntryTrader() != nil {
+		ctx.LimitEntryTimeoutMinutes = int(at.config.LimitOrderTimeout.Minutes())
+	}
+	ctx.PendingEntries = at.pendingEntries()
+
+	// 7. Cross-trader symbol throttle state (so AI knows which symbols are saturated)
+	if at.symbolThrottle != nil {
+		ctx.SymbolThrottles = at.symbolThrottle.Snapshot()
+	}
+
+	// 8. Trade memory: index newly closed trades, retrieval runs once market data is loaded
+	if at.tradeMemory != nil {
+		if performance != nil {
+			if added := at.tradeMemory.Index(performance.RecentTrades); added > 0 {
+				at.log.Infof("🧠 Trade memory: indexed %d new closed trades", added)
+			}
+		}
+		ctx.Memory = at.tradeMemory
+	}
+
+	// 8.5. Score the process of newly closed trades (regime, reward:risk, price path, slippage)
+	if performance != nil {
+		if scored := at.decisionQuality.Score(performance.RecentTrades); scored > 0 {
+			at.log.Infof("📐 Decision quality: scored %d closed trades", scored)
+		}
+	}
+
+	// 8.6. Confidence threshold for opens, calibrated on the scored trades
+	ctx.ConfidenceThreshold = at.confidenceThreshold()
+	ctx.HonorStops = at.honorsStops()
+	ctx.ProhibitHedging = at.config.ProhibitHedging
+
+	// 8.7. Extra timeframes (loaded with the market data) and the prompt budget
+	if pd := at.config.PromptData; pd != nil {
+		ctx.PromptTimeframes = pd.Timeframes
+		ctx.TimeframeSeries = pd.SeriesLength
+		ctx.MaxPromptTokens = pd.MaxPromptTokens
 	}
+	ctx.MinOIUSD = at.config.MinOIUSD
+	ctx.PromptTemplate = at.promptTemplate
+	ctx.RiskPolicy = at.riskPolicy
+
+	// 8.8. Recent headlines of the positions and candidates (wall-clock news, so not in simulate mode)
+	if news.Enabled() && at.sim == nil {
+		at.attachNews(ctx)
+	}
+
+	// 8.9. Recent liquidations (live stream, so not in simulate mode)
+	if market.LiquidationsEnabled() && at.sim == nil {
+		at.attachLiquidations(ctx)
+	}
+
+	// 9. Rejected decisions: advance outcome simulations and feed the aggregate back to the AI
+	at.rejectedTrades.Update()
+	ctx.RejectedTrades = at.rejectedTrades.Summary(rejectedSummaryWindow)
 
 	return ctx, nil
 }
 
-// executeDecisionWithRecord executes AI decision and records detailed information
-func (at *AutoTrader) executeDecisionWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
+// executeDecisionWithRecord executes AI decision and records detailed information (ErrShutdownDuringCycle when
+// the trader was stopped before it started)
+func (at *AutoTrader) executeDecisionWithRecord(cycleCtx context.Context, decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
+	if cycleCtx.Err() != nil {
+		// Stopped between orders: nothing of this decision has been placed yet
+		return ErrShutdownDuringCycle
+	}
+	if decisionPkg.IsEntryAction(decision.Action) && at.IsCompleted() {
+		return fmt.Errorf("trader completed its run (%s), not opening new positions", at.GetCompletion().Reason)
+	}
+	if paused, _ := at.IsPaused(); paused && decisionPkg.IsEntryAction(decision.Action) {
+		// Manual cycles and manual trades run while paused, but only to close or amend
+		return fmt.Errorf("trader is paused, not opening or adding to positions")
+	}
 	switch decision.Action {
 	case "open_long":
 		return at.executeOpenLongWithRecord(decision, actionRecord)
@@ -1346,6 +2093,10 @@ func (at *AutoTrader) executeDecisionWithRecord(decision *decisionPkg.Decision,
 		return at.executeCloseLongWithRecord(decision, actionRecord)
 	case "close_short":
 		return at.executeCloseShortWithRecord(decision, actionRecord)
+	case "add_long", "add_short":
+		return at.executeAddWithRecord(decision, actionRecord)
+	case "adjust_stop", "adjust_target", "add_margin", "reduce_size":
+		return at.executeAmendWithRecord(decision, actionRecord)
 	case "hold", "wait":
 		// No execution needed, just record
 		return nil
@@ -1360,17 +2111,8 @@ func (at *AutoTrader) determineExecutableMargin(symbol, action string, desiredMa
 		return 0, 0, fmt.Errorf("failed to fetch balance before %s %s: %w", action, symbol, err)
 	}
 
-	rawAvailable, exists := balance["availableBalance"]
-	if !exists {
-		return 0, 0, fmt.Errorf("failed to determine available balance before %s %s: field missing", action, symbol)
-	}
-
-	available, err := toFloat64(rawAvailable)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid available balance format before %s %s: %w", action, symbol, err)
-	}
-
-	maxUsable := available - marginSafetyBuffer
+	available := balance.AvailableBalance
+	maxUsable := addUSDT(available, -marginSafetyBuffer)
 	if maxUsable < 0 {
 		maxUsable = 0
 	}
@@ -1386,45 +2128,18 @@ func (at *AutoTrader) determineExecutableMargin(symbol, action string, desiredMa
 	}
 
 	if effectiveMargin < desiredMargin {
-		log.Printf("  ⚠️  Reducing %s %s margin from %.2f to %.2f USDT (available: %.2f USDT, buffer: %.2f USDT)",
+		at.log.Warnf("  ⚠️  Reducing %s %s margin from %.2f to %.2f USDT (available: %.2f USDT, buffer: %.2f USDT)",
 			symbol, action, desiredMargin, effectiveMargin, available, marginSafetyBuffer)
 	}
 
 	return effectiveMargin, available, nil
 }
 
-func isMarginInsufficientAPIError(err error) bool {
-	if err == nil {
-		return false
-	}
-	lower := strings.ToLower(err.Error())
-	return strings.Contains(lower, "margin is insufficient") || strings.Contains(lower, "-2019")
-}
-
-func toFloat64(value interface{}) (float64, error) {
-	switch v := value.(type) {
-	case float64:
-		return v, nil
-	case float32:
-		return float64(v), nil
-	case int:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case json.Number:
-		return v.Float64()
-	case string:
-		return strconv.ParseFloat(v, 64)
-	default:
-		return 0, fmt.Errorf("unsupported numeric type %T", value)
-	}
-}
-
 // executeOpenLongWithRecord Execute opening long position and record detailed information
 func (at *AutoTrader) executeOpenLongWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📈 Opening long position: %s", decision.Symbol)
+	at.log.Infof("  📈 Opening long position: %s", decision.Symbol)
 
-	// Note: Multiple positions in the same coin are allowed (user preference)
+	// Note: One position per coin and side - validation sends more size through add_long / add_short
 
 	// Get current price
 	marketData, err := market.Get(decision.Symbol)
@@ -1436,19 +2151,38 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decisionPkg.Decision,
 	if err != nil {
 		return err
 	}
+	decision.Leverage = at.capLeverage(decision.Symbol, decision.Leverage)
 
 	// Calculate quantity from MARGIN
 	// position_size_usd is now MARGIN, not notional
 	// notional = margin * leverage
 	// quantity = notional / price
-	notionalValue := effectiveMargin * float64(decision.Leverage)
-	quantity := notionalValue / marketData.CurrentPrice
+	quantity := quantityForMargin(effectiveMargin, decision.Leverage, marketData.CurrentPrice)
 	actionRecord.Quantity = quantity
 	actionRecord.Price = marketData.CurrentPrice
 
-	// Open position
-	order, err := at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
+	// Reserve a slot in the cross-trader symbol throttle (shared account protection)
+	if at.symbolThrottle != nil {
+		if err := at.symbolThrottle.TryAcquire(decision.Symbol, at.id); err != nil {
+			return err
+		}
+	}
+
+	// Open position (leverage → entry → take profit / stop loss, rolled back if a step fails); limit entries
+	// rest on the book and are protected once they fill
+	var order *Order
+	if lt := at.limitEntryTrader(); lt != nil && decision.IsLimitEntry() {
+		order, err = at.placeLimitEntry(lt, decision, "long", effectiveMargin, actionRecord)
+	} else {
+		if decision.IsLimitEntry() {
+			at.log.Warnf("  ⚠ %s entries are not enabled on this trader, opening at market", decision.OrderType)
+		}
+		order, err = at.openWithSaga(decision.Symbol, "long", quantity, decision.Leverage, decision.TakeProfit, at.stopOrderPrice(decision))
+	}
 	if err != nil {
+		if at.symbolThrottle != nil {
+			at.symbolThrottle.Release(decision.Symbol, at.id)
+		}
 		if isMarginInsufficientAPIError(err) {
 			return fmt.Errorf("%w: Binance rejected %s open_long (need %.2f USDT margin, err: %v)",
 				ErrMarginInsufficient, decision.Symbol, effectiveMargin, err)
@@ -1457,33 +2191,35 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decisionPkg.Decision,
 	}
 
 	// Record order ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	actionRecord.OrderID = order.OrderID
+	actionRecord.ClientOrderID = order.ClientOrderID
+	actionRecord.Fee = order.Fee
+
+	if orderResting(order.Status) {
+		// Resting limit entry: the position (and its first-seen time) appears once it fills
+		return nil
 	}
+	at.log.Infof("  ✓ Position opened successfully, Order ID: %v, Quantity: %.4f", order.OrderID, quantity)
 
-	log.Printf("  ✓ Position opened successfully, Order ID: %v, Quantity: %.4f", order["orderId"], quantity)
+	// Remember the regime this position was opened in
+	if at.tradeMemory != nil {
+		at.tradeMemory.RecordEntry(decision.Symbol, "long", marketData)
+	}
+	at.recordDecisionPlan(decision, "long", marketData)
 
 	// Record position opening time
-	posKey := decision.Symbol + "_long"
-	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
+	at.positionOrigins.opened(decision.Symbol, "long", at.now())
 
-	// DISABLED: Stop loss orders - we don't want to automatically close losing positions
-	// Only profitable positions can be closed (by AI decision or manual close)
-	// if err := at.trader.SetStopLoss(decision.Symbol, "LONG", quantity, decision.StopLoss); err != nil {
-	// 	log.Printf("  ⚠ Failed to set stop loss: %v", err)
-	// }
-	if err := at.trader.SetTakeProfit(decision.Symbol, "LONG", quantity, decision.TakeProfit); err != nil {
-		log.Printf("  ⚠ Failed to set take profit: %v", err)
-	}
+	// Take profit (and the stop loss when stops are honored) was placed by the open saga
 
 	return nil
 }
 
 // executeOpenShortWithRecord Execute opening short position and record detailed information
 func (at *AutoTrader) executeOpenShortWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📉 Opening short position: %s", decision.Symbol)
+	at.log.Infof("  📉 Opening short position: %s", decision.Symbol)
 
-	// Note: Multiple positions in the same coin are allowed (user preference)
+	// Note: One position per coin and side - validation sends more size through add_long / add_short
 
 	// Get current price
 	marketData, err := market.Get(decision.Symbol)
@@ -1495,19 +2231,38 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decisionPkg.Decision,
 	if err != nil {
 		return err
 	}
+	decision.Leverage = at.capLeverage(decision.Symbol, decision.Leverage)
 
 	// Calculate quantity from MARGIN
 	// position_size_usd is now MARGIN, not notional
 	// notional = margin * leverage
 	// quantity = notional / price
-	notionalValue := effectiveMargin * float64(decision.Leverage)
-	quantity := notionalValue / marketData.CurrentPrice
+	quantity := quantityForMargin(effectiveMargin, decision.Leverage, marketData.CurrentPrice)
 	actionRecord.Quantity = quantity
 	actionRecord.Price = marketData.CurrentPrice
 
-	// Open position
-	order, err := at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+	// Reserve a slot in the cross-trader symbol throttle (shared account protection)
+	if at.symbolThrottle != nil {
+		if err := at.symbolThrottle.TryAcquire(decision.Symbol, at.id); err != nil {
+			return err
+		}
+	}
+
+	// Open position (leverage → entry → take profit / stop loss, rolled back if a step fails); limit entries
+	// rest on the book and are protected once they fill
+	var order *Order
+	if lt := at.limitEntryTrader(); lt != nil && decision.IsLimitEntry() {
+		order, err = at.placeLimitEntry(lt, decision, "short", effectiveMargin, actionRecord)
+	} else {
+		if decision.IsLimitEntry() {
+			at.log.Warnf("  ⚠ %s entries are not enabled on this trader, opening at market", decision.OrderType)
+		}
+		order, err = at.openWithSaga(decision.Symbol, "short", quantity, decision.Leverage, decision.TakeProfit, at.stopOrderPrice(decision))
+	}
 	if err != nil {
+		if at.symbolThrottle != nil {
+			at.symbolThrottle.Release(decision.Symbol, at.id)
+		}
 		if isMarginInsufficientAPIError(err) {
 			return fmt.Errorf("%w: Binance rejected %s open_short (need %.2f USDT margin, err: %v)",
 				ErrMarginInsufficient, decision.Symbol, effectiveMargin, err)
@@ -1516,31 +2271,33 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decisionPkg.Decision,
 	}
 
 	// Record order ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	actionRecord.OrderID = order.OrderID
+	actionRecord.ClientOrderID = order.ClientOrderID
+	actionRecord.Fee = order.Fee
+
+	if orderResting(order.Status) {
+		// Resting limit entry: the position (and its first-seen time) appears once it fills
+		return nil
 	}
+	at.log.Infof("  ✓ Position opened successfully, Order ID: %v, Quantity: %.4f", order.OrderID, quantity)
 
-	log.Printf("  ✓ Position opened successfully, Order ID: %v, Quantity: %.4f", order["orderId"], quantity)
+	// Remember the regime this position was opened in
+	if at.tradeMemory != nil {
+		at.tradeMemory.RecordEntry(decision.Symbol, "short", marketData)
+	}
+	at.recordDecisionPlan(decision, "short", marketData)
 
 	// Record position opening time
-	posKey := decision.Symbol + "_short"
-	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
+	at.positionOrigins.opened(decision.Symbol, "short", at.now())
 
-	// DISABLED: Stop loss orders - we don't want to automatically close losing positions
-	// Only profitable positions can be closed (by AI decision or manual close)
-	// if err := at.trader.SetStopLoss(decision.Symbol, "SHORT", quantity, decision.StopLoss); err != nil {
-	// 	log.Printf("  ⚠ Failed to set stop loss: %v", err)
-	// }
-	if err := at.trader.SetTakeProfit(decision.Symbol, "SHORT", quantity, decision.TakeProfit); err != nil {
-		log.Printf("  ⚠ Failed to set take profit: %v", err)
-	}
+	// Take profit (and the stop loss when stops are honored) was placed by the open saga
 
 	return nil
 }
 
 // executeCloseLongWithRecord executes closing long position and records detailed information
 func (at *AutoTrader) executeCloseLongWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🔄 Closing long position: %s", decision.Symbol)
+	at.log.Infof("  🔄 Closing long position: %s", decision.Symbol)
 
 	// Get lock for this position to prevent race conditions
 	lock := getPositionLock(decision.Symbol, "LONG")
@@ -1555,17 +2312,19 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *decisionPkg.Decision,
 
 	positionExists := false
 	for _, pos := range positions {
-		posSymbol, _ := pos["symbol"].(string)
-		posSide, _ := pos["side"].(string)
-		if posSymbol == decision.Symbol && strings.ToLower(posSide) == "long" {
+		if pos.Symbol == decision.Symbol && pos.Side == "long" {
 			positionExists = true
-			unrealizedPnl, _ := pos["unRealizedProfit"].(float64)
+			actionRecord.Quantity = pos.Quantity
+			if decision.IsPartialClose() {
+				actionRecord.Quantity = pos.Quantity * decision.ClosePct / 100
+			}
+			unrealizedPnl := pos.UnrealizedProfit
 			if unrealizedPnl < 0 {
 				// Position is losing money - reject close unless stop loss is hit
-				log.Printf("  ⚠️ Position %s LONG has negative P&L (%.2f USDT) - holding until profitable or stop loss hit", decision.Symbol, unrealizedPnl)
+				at.log.Warnf("  ⚠️ Position %s LONG has negative P&L (%.2f USDT) - holding until profitable or stop loss hit", decision.Symbol, unrealizedPnl)
 				return fmt.Errorf("position is losing money (P&L: %.2f USDT) - holding until profitable. Only close if stop loss is hit or position becomes profitable", unrealizedPnl)
 			}
-			log.Printf("  ✓ Position %s LONG is profitable (P&L: +%.2f USDT) - closing", decision.Symbol, unrealizedPnl)
+			at.log.Infof("  ✓ Position %s LONG is profitable (P&L: +%.2f USDT) - closing", decision.Symbol, unrealizedPnl)
 			break
 		}
 	}
@@ -1581,30 +2340,40 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *decisionPkg.Decision,
 	}
 	actionRecord.Price = marketData.CurrentPrice
 
-	// Close position
-	order, err := at.trader.CloseLong(decision.Symbol, 0) // 0 = close all
+	// Close position (0 = close all)
+	quantity := 0.0
+	if decision.IsPartialClose() {
+		quantity = actionRecord.Quantity
+		actionRecord.Partial = true
+	}
+	order, err := at.trader.CloseLong(decision.Symbol, quantity)
 	if err != nil {
 		// Check if position was already closed
-		errStr := strings.ToLower(err.Error())
-		if strings.Contains(errStr, "no long position") ||
-			(strings.Contains(errStr, "margin is insufficient") && strings.Contains(errStr, "-2019")) {
+		if isPositionGoneError(err) {
 			return fmt.Errorf("position %s LONG was already closed (likely by another trader)", decision.Symbol)
 		}
 		return err
 	}
 
 	// Record order ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	actionRecord.OrderID = order.OrderID
+	actionRecord.ClientOrderID = order.ClientOrderID
+	actionRecord.Fee = order.Fee
+	actionRecord.RealizedPnL = order.RealizedPnL
+
+	if quantity > 0 {
+		// Closing cancels the symbol's open orders on the exchange - restore stop/target for the remainder
+		at.refreshProtectionOrders(decision.Symbol)
+		at.log.Infof("  ✓ Closed %.0f%% of the position (%.4f)", decision.ClosePct, quantity)
+		return nil
 	}
-
-	log.Printf("  ✓ Position closed successfully")
+	at.log.Infof("  ✓ Position closed successfully")
 	return nil
 }
 
 // executeCloseShortWithRecord executes closing short position and records detailed information
 func (at *AutoTrader) executeCloseShortWithRecord(decision *decisionPkg.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🔄 Closing short position: %s", decision.Symbol)
+	at.log.Infof("  🔄 Closing short position: %s", decision.Symbol)
 
 	// Get lock for this position to prevent race conditions
 	lock := getPositionLock(decision.Symbol, "SHORT")
@@ -1619,17 +2388,19 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *decisionPkg.Decision
 
 	positionExists := false
 	for _, pos := range positions {
-		posSymbol, _ := pos["symbol"].(string)
-		posSide, _ := pos["side"].(string)
-		if posSymbol == decision.Symbol && strings.ToLower(posSide) == "short" {
+		if pos.Symbol == decision.Symbol && pos.Side == "short" {
 			positionExists = true
-			unrealizedPnl, _ := pos["unRealizedProfit"].(float64)
+			actionRecord.Quantity = pos.Quantity
+			if decision.IsPartialClose() {
+				actionRecord.Quantity = pos.Quantity * decision.ClosePct / 100
+			}
+			unrealizedPnl := pos.UnrealizedProfit
 			if unrealizedPnl < 0 {
 				// Position is losing money - reject close unless stop loss is hit
-				log.Printf("  ⚠️ Position %s SHORT has negative P&L (%.2f USDT) - holding until profitable or stop loss hit", decision.Symbol, unrealizedPnl)
+				at.log.Warnf("  ⚠️ Position %s SHORT has negative P&L (%.2f USDT) - holding until profitable or stop loss hit", decision.Symbol, unrealizedPnl)
 				return fmt.Errorf("position is losing money (P&L: %.2f USDT) - holding until profitable. Only close if stop loss is hit or position becomes profitable", unrealizedPnl)
 			}
-			log.Printf("  ✓ Position %s SHORT is profitable (P&L: +%.2f USDT) - closing", decision.Symbol, unrealizedPnl)
+			at.log.Infof("  ✓ Position %s SHORT is profitable (P&L: +%.2f USDT) - closing", decision.Symbol, unrealizedPnl)
 			break
 		}
 	}
@@ -1645,24 +2416,34 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *decisionPkg.Decision
 	}
 	actionRecord.Price = marketData.CurrentPrice
 
-	// Close position
-	order, err := at.trader.CloseShort(decision.Symbol, 0) // 0 = close all
+	// Close position (0 = close all)
+	quantity := 0.0
+	if decision.IsPartialClose() {
+		quantity = actionRecord.Quantity
+		actionRecord.Partial = true
+	}
+	order, err := at.trader.CloseShort(decision.Symbol, quantity)
 	if err != nil {
 		// Check if position was already closed
-		errStr := strings.ToLower(err.Error())
-		if strings.Contains(errStr, "no short position") ||
-			(strings.Contains(errStr, "margin is insufficient") && strings.Contains(errStr, "-2019")) {
+		if isPositionGoneError(err) {
 			return fmt.Errorf("position %s SHORT was already closed (likely by another trader)", decision.Symbol)
 		}
 		return err
 	}
 
 	// Record order ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	actionRecord.OrderID = order.OrderID
+	actionRecord.ClientOrderID = order.ClientOrderID
+	actionRecord.Fee = order.Fee
+	actionRecord.RealizedPnL = order.RealizedPnL
+
+	if quantity > 0 {
+		// Closing cancels the symbol's open orders on the exchange - restore stop/target for the remainder
+		at.refreshProtectionOrders(decision.Symbol)
+		at.log.Infof("  ✓ Closed %.0f%% of the position (%.4f)", decision.ClosePct, quantity)
+		return nil
 	}
-
-	log.Printf("  ✓ Position closed successfully")
+	at.log.Infof("  ✓ Position closed successfully")
 	return nil
 }
 
@@ -1691,6 +2472,16 @@ func (at *AutoTrader) GetDecisionLogger() *logger.DecisionLogger {
 	return at.decisionLogger
 }
 
+// GetPnLLedger gets the realized P&L ledger
+func (at *AutoTrader) GetPnLLedger() *PnLLedger {
+	return at.pnlLedger
+}
+
+// GetRejectedTrades gets the simulator of decisions rejected by validation
+func (at *AutoTrader) GetRejectedTrades() *RejectedTradeSimulator {
+	return at.rejectedTrades
+}
+
 // SetTraderManager sets trader manager reference (for copy trading)
 func (at *AutoTrader) SetTraderManager(tm interface{}) {
 	at.traderManager = tm
@@ -1734,7 +2525,7 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 
 	// If only cycle #0 exists, that means no actual trading has happened yet
 	if latestRecord == nil {
-		log.Printf("⚠️  Only cycle #0 seed records found, no actual trading cycles yet. Using initial balance: %.2f", initialBalance)
+		traderLog.Warnf("⚠️  Only cycle #0 seed records found, no actual trading cycles yet. Using initial balance: %.2f", initialBalance)
 		return &PaperTrader{
 			initialBalance:   initialBalance,
 			balance:          initialBalance,
@@ -1751,13 +2542,13 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 	if latestRecordWithPositions != nil {
 		positionsSourceRecord = latestRecordWithPositions
 		if latestRecordWithPositions != latestRecord {
-			log.Printf("⚠️  Latest record (cycle #%d) has no positions, using cycle #%d for position restoration",
+			traderLog.Warnf("⚠️  Latest record (cycle #%d) has no positions, using cycle #%d for position restoration",
 				latestRecord.CycleNumber, latestRecordWithPositions.CycleNumber)
 		}
 	}
 
-	log.Printf("🔄 Restoring from cycle #%d (latest record)", latestRecord.CycleNumber)
-	log.Printf("📊 Latest record data: TotalBalance=%.2f, AvailableBalance=%.2f, UnrealizedProfit=%.2f, Positions=%d",
+	traderLog.Infof("🔄 Restoring from cycle #%d (latest record)", latestRecord.CycleNumber)
+	traderLog.Infof("📊 Latest record data: TotalBalance=%.2f, AvailableBalance=%.2f, UnrealizedProfit=%.2f, Positions=%d",
 		latestRecord.AccountState.TotalBalance, latestRecord.AccountState.AvailableBalance,
 		latestRecord.AccountState.TotalUnrealizedProfit, latestRecord.AccountState.PositionCount)
 
@@ -1768,7 +2559,7 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 	// This is because if account has been liquidated, latest record may show 0, but we should restore from initialBalance obtained from first record
 	effectiveEquity := accountState.TotalBalance
 	if effectiveEquity <= 0 {
-		log.Printf("⚠️  Latest record has invalid equity (%.2f), using restored initial balance: %.2f",
+		traderLog.Warnf("⚠️  Latest record has invalid equity (%.2f), using restored initial balance: %.2f",
 			effectiveEquity, initialBalance)
 		effectiveEquity = initialBalance
 		accountState.TotalUnrealizedProfit = 0 // Reset unrealized profit if using initial balance
@@ -1776,16 +2567,16 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 
 	// Create paper trader and restore balance
 	// balance = totalEquity - unrealizedProfit (wallet balance = total assets - unrealized P&L)
-	balance := effectiveEquity - accountState.TotalUnrealizedProfit
+	balance := addUSDT(effectiveEquity, -accountState.TotalUnrealizedProfit)
 	if balance < 0 {
-		log.Printf("⚠️  Calculated balance is negative (%.2f), setting to 0", balance)
+		traderLog.Warnf("⚠️  Calculated balance is negative (%.2f), setting to 0", balance)
 		balance = 0 // Safe handling: avoid negative balance
 	}
 
 	// Ensure available balance doesn't exceed total balance
 	availableBalance := accountState.AvailableBalance
 	if availableBalance > balance {
-		log.Printf("⚠️  Available balance (%.2f) exceeds wallet balance (%.2f), capping to wallet balance",
+		traderLog.Warnf("⚠️  Available balance (%.2f) exceeds wallet balance (%.2f), capping to wallet balance",
 			availableBalance, balance)
 		availableBalance = balance
 	}
@@ -1802,7 +2593,7 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 		rng:              rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 
-	log.Printf("💾 Restored paper trader values: balance=%.2f, availableBalance=%.2f, unrealizedProfit=%.2f, initialBalance=%.2f",
+	traderLog.Infof("💾 Restored paper trader values: balance=%.2f, availableBalance=%.2f, unrealizedProfit=%.2f, initialBalance=%.2f",
 		balance, availableBalance, accountState.TotalUnrealizedProfit, initialBalance)
 
 	// Restore positions from record that has positions (may be different from latest if latest has none)
@@ -1814,7 +2605,7 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 			EntryPrice: posSnapshot.EntryPrice,
 			Quantity:   posSnapshot.PositionAmt,
 			Leverage:   int(posSnapshot.Leverage),
-			MarginUsed: posSnapshot.EntryPrice * posSnapshot.PositionAmt / posSnapshot.Leverage, // Estimate margin
+			MarginUsed: marginForQuantity(math.Abs(posSnapshot.PositionAmt), posSnapshot.EntryPrice, int(posSnapshot.Leverage)), // Estimate margin
 		}
 
 		// If position amount is negative, it's a short (SHORT)
@@ -1834,7 +2625,7 @@ func restorePaperTraderState(initialBalance float64, decisionLogger *logger.Deci
 		positionCount++
 	}
 
-	log.Printf("✅ Restored paper trader state: Wallet=%.2f, Equity=%.2f, Available=%.2f, InitialBalance=%.2f (for P&L), Positions=%d",
+	traderLog.Infof("✅ Restored paper trader state: Wallet=%.2f, Equity=%.2f, Available=%.2f, InitialBalance=%.2f (for P&L), Positions=%d",
 		balance, effectiveEquity, availableBalance, initialBalance, positionCount)
 
 	return paperTrader, nil
@@ -1847,21 +2638,53 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 		aiProvider = "Qwen"
 	}
 
-	return map[string]interface{}{
+	paused, pausedAt := at.IsPaused()
+	risk := at.GetRiskStatus()
+	status := map[string]interface{}{
 		"trader_id":       at.id,
 		"trader_name":     at.name,
 		"ai_model":        at.aiModel,
 		"exchange":        at.exchange,
 		"is_running":      at.isRunning,
+		"is_paused":       paused,
 		"start_time":      at.startTime.Format(time.RFC3339),
-		"runtime_minutes": int(time.Since(at.startTime).Minutes()),
+		"runtime_minutes": int(at.now().Sub(at.startTime).Minutes()),
 		"call_count":      at.callCount,
 		"initial_balance": at.initialBalance,
-		"scan_interval":   at.config.ScanInterval.String(),
-		"stop_until":      at.stopUntil.Format(time.RFC3339),
-		"last_reset_time": at.lastResetTime.Format(time.RFC3339),
+		"scan_interval":   at.RuntimeSettings().ScanInterval.String(),
+		"stop_until":      risk.StopUntil,
+		"last_reset_time": risk.Day,
+		"risk":            risk,
 		"ai_provider":     aiProvider,
+		"strategy":        at.strategy.Name(),
+		"risk_policy":     at.riskPolicy.Label(),
+		"stop_loss_mode":  at.stopLossMode(),
+		"completed":       at.IsCompleted(),
+		"health":          at.Health(),
+	}
+	if dt, ok := asDryRunTrader(at.trader); ok {
+		status["dry_run"] = dt.status()
+	}
+	if summary := at.paperShadow.Summary(); summary != nil {
+		status["paper_shadow"] = summary
+	}
+	if protected := at.positionProtection.Positions(); len(protected) > 0 {
+		status["protected_positions"] = protected
+	}
+	if paused {
+		status["paused_at"] = pausedAt.Format(time.RFC3339)
+	}
+	if at.autoCloseWhatIf != nil {
+		status["auto_close_what_if"] = at.autoCloseWhatIf.Current()
+	}
+	if threshold := at.confidenceThreshold(); threshold != nil {
+		status["confidence_threshold"] = threshold
+	}
+	status["cycle_trigger"] = at.schedule.Status()
+	if report := at.GetLeverageSetup(); report != nil {
+		status["leverage_setup"] = report
 	}
+	return status
 }
 
 // GetInitialBalance gets initial balance
@@ -1877,22 +2700,12 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	}
 
 	// Get account fields
-	totalWalletBalance := 0.0
-	totalUnrealizedProfit := 0.0
-	availableBalance := 0.0
-
-	if wallet, ok := balance["totalWalletBalance"].(float64); ok {
-		totalWalletBalance = wallet
-	}
-	if unrealized, ok := balance["totalUnrealizedProfit"].(float64); ok {
-		totalUnrealizedProfit = unrealized
-	}
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
-	}
+	totalWalletBalance := balance.WalletBalance
+	totalUnrealizedProfit := balance.UnrealizedProfit
+	availableBalance := balance.AvailableBalance
 
 	// Total Equity = wallet balance + unrealized profit/loss
-	totalEquity := totalWalletBalance + totalUnrealizedProfit
+	totalEquity := addUSDT(totalWalletBalance, totalUnrealizedProfit)
 
 	// Get positions and calculate total margin
 	positions, err := at.trader.GetPositions()
@@ -1903,23 +2716,17 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	totalMarginUsed := 0.0
 	totalUnrealizedPnL := 0.0
 	for _, pos := range positions {
-		markPrice := pos["markPrice"].(float64)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity
-		}
-		unrealizedPnl := pos["unRealizedProfit"].(float64)
-		totalUnrealizedPnL += unrealizedPnl
+		totalUnrealizedPnL = addUSDT(totalUnrealizedPnL, pos.UnrealizedProfit)
 
 		leverage := 10
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
+		if pos.Leverage > 0 {
+			leverage = pos.Leverage
 		}
-		marginUsed := (quantity * markPrice) / float64(leverage)
-		totalMarginUsed += marginUsed
+		marginUsed := marginForQuantity(pos.Quantity, pos.MarkPrice, leverage)
+		totalMarginUsed = addUSDT(totalMarginUsed, marginUsed)
 	}
 
-	totalPnL := totalEquity - at.initialBalance
+	totalPnL := addUSDT(totalEquity, -at.initialBalance)
 	totalPnLPct := 0.0
 	if at.initialBalance > 0 {
 		totalPnLPct = (totalPnL / at.initialBalance) * 100
@@ -1930,6 +2737,10 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		marginUsedPct = (totalMarginUsed / totalEquity) * 100
 	}
 
+	// Realized vs unrealized split
+	at.pnlLedger.Seed(totalWalletBalance, at.initialBalance)
+	realized := at.pnlLedger.Summary()
+
 	return map[string]interface{}{
 		// Core fields
 		"total_equity":      totalEquity,           // Account equity = wallet + unrealized
@@ -1938,11 +2749,14 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		"available_balance": availableBalance,      // Available balance
 
 		// Profit/loss statistics
-		"total_pnl":            totalPnL,           // Total profit/loss = equity - initial
-		"total_pnl_pct":        totalPnLPct,        // Total profit/loss percentage
-		"total_unrealized_pnl": totalUnrealizedPnL, // Unrealized profit/loss (calculated from positions)
-		"initial_balance":      at.initialBalance,  // Initial balance
-		"daily_pnl":            at.dailyPnL,        // Daily profit/loss
+		"total_pnl":            totalPnL,              // Total profit/loss = equity - initial
+		"total_pnl_pct":        totalPnLPct,           // Total profit/loss percentage
+		"total_unrealized_pnl": totalUnrealizedPnL,    // Unrealized profit/loss (calculated from positions)
+		"initial_balance":      at.initialBalance,     // Initial balance
+		"daily_pnl":            at.dailyPnL(),         // Realized + unrealized since the start of the day (UTC)
+		"realized_pnl":         realized.RealizedPnL,  // Banked P&L (closes + fees + funding, from ledger)
+		"unrealized_pnl":       totalUnrealizedProfit, // Open position P&L (not yet banked)
+		"realized_breakdown":   realized,              // Realized P&L by source
 
 		// Position information
 		"position_count":  len(positions),  // Position count
@@ -1959,38 +2773,16 @@ func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
 	}
 
 	var result []map[string]interface{}
+	var infos []decisionPkg.PositionInfo
 	for _, pos := range positions {
-		symbol := pos["symbol"].(string)
-
-		// Handle both "side" and "positionSide" fields (different exchanges use different names)
-		var side string
-		if s, ok := pos["side"].(string); ok && s != "" {
-			side = s
-		} else if ps, ok := pos["positionSide"].(string); ok && ps != "" {
-			// PaperTrader uses "positionSide" with "LONG"/"SHORT", convert to lowercase "long"/"short"
-			side = strings.ToLower(ps)
-		} else {
-			// Fallback: determine from positionAmt sign (negative = short)
-			quantity := pos["positionAmt"].(float64)
-			if quantity < 0 {
-				side = "short"
-			} else {
-				side = "long"
-			}
-		}
-
-		entryPrice := pos["entryPrice"].(float64)
-		markPrice := pos["markPrice"].(float64)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity
-		}
-		unrealizedPnl := pos["unRealizedProfit"].(float64)
-		liquidationPrice := pos["liquidationPrice"].(float64)
+		side := pos.Side
+		entryPrice := pos.EntryPrice
+		markPrice := pos.MarkPrice
+		quantity := pos.Quantity
 
 		leverage := 10
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
+		if pos.Leverage > 0 {
+			leverage = pos.Leverage
 		}
 
 		pnlPct := 0.0
@@ -2002,20 +2794,48 @@ func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
 
 		marginUsed := (quantity * markPrice) / float64(leverage)
 
-		result = append(result, map[string]interface{}{
-			"symbol":             symbol,
+		position := map[string]interface{}{
+			"symbol":             pos.Symbol,
 			"side":               side,
 			"entry_price":        entryPrice,
 			"mark_price":         markPrice,
 			"quantity":           quantity,
 			"leverage":           leverage,
-			"unrealized_pnl":     unrealizedPnl,
+			"unrealized_pnl":     pos.UnrealizedProfit,
 			"unrealized_pnl_pct": pnlPct,
-			"liquidation_price":  liquidationPrice,
+			"liquidation_price":  pos.LiquidationPrice,
 			"margin_used":        marginUsed,
+		}
+		if origin, ok := at.positionOrigins.get(pos.Symbol, side); ok {
+			position["entry_time"] = origin.EntryTime.UnixMilli()
+			position["entry_source"] = origin.Source
+			if origin.EntryCycle > 0 {
+				position["entry_cycle"] = origin.EntryCycle
+			}
+			if len(origin.Owners) > 0 {
+				position["owners"] = origin.Owners
+			}
+		}
+		result = append(result, position)
+		infos = append(infos, decisionPkg.PositionInfo{
+			Symbol:        pos.Symbol,
+			Side:          side,
+			MarkPrice:     markPrice,
+			Quantity:      quantity,
+			UnrealizedPnL: pos.UnrealizedProfit,
+			MarginUsed:    marginUsed,
 		})
 	}
 
+	// Each position carries its symbol's net exposure (a hedged long and short offset each other)
+	exposures := make(map[string]decisionPkg.NetExposure)
+	for _, e := range decisionPkg.NetExposures(infos) {
+		exposures[e.Symbol] = e
+	}
+	for _, position := range result {
+		position["net_exposure"] = exposures[position["symbol"].(string)]
+	}
+
 	return result, nil
 }
 
@@ -2029,12 +2849,14 @@ func sortDecisionsByPriority(decisions []decisionPkg.Decision) []decisionPkg.Dec
 	// Define priority
 	getActionPriority := func(action string) int {
 		switch action {
-		case "close_long", "close_short":
+		case "close_long", "close_short", "reduce_size":
 			return 1 // Highest priority: close positions first
-		case "open_long", "open_short":
-			return 2 // Second priority: open positions later
+		case "adjust_stop", "adjust_target", "add_margin":
+			return 2 // Then manage remaining positions
+		case "open_long", "open_short", "add_long", "add_short":
+			return 3 // Open and add to positions later
 		case "hold", "wait":
-			return 3 // Lowest priority: wait
+			return 4 // Lowest priority: wait
 		default:
 			return 999 // Unknown actions go last
 		}
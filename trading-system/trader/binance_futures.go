@@ -3,7 +3,9 @@ package trader
 import (
 	"context"
 	"fmt"
-	"log"
+	"lia/logging"
+	"lia/ratelimit"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,17 +14,24 @@ import (
 	"github.com/adshao/go-binance/v2/futures"
 )
 
+// binanceLog logs the Binance futures adapter
+var binanceLog = logging.Component("binance")
+
 // FuturesTrader Binance Futures trader
 type FuturesTrader struct {
 	client *futures.Client
 
+	// Account identity in the shared rate limiter: request budget and coalesced balance/position reads are
+	// shared by every trader using the same API key
+	accountKey string
+
 	// Balance cache
-	cachedBalance     map[string]interface{}
+	cachedBalance     *Balance
 	balanceCacheTime  time.Time
 	balanceCacheMutex sync.RWMutex
 
 	// Positions cache
-	cachedPositions     []map[string]interface{}
+	cachedPositions     []Position
 	positionsCacheTime  time.Time
 	positionsCacheMutex sync.RWMutex
 
@@ -36,17 +45,34 @@ type FuturesTrader struct {
 	// Time sync tracking
 	lastTimeSync  time.Time
 	timeSyncMutex sync.RWMutex
+
+	// Trader tag embedded in client order IDs (for execution audits)
+	clientOrderTag string
+
+	// Exchange metadata cache (exchangeInfo quantity precision, leverage brackets)
+	symbolPrecision map[string]int // LOT_SIZE quantity precision by symbol
+	pricePrecision  map[string]int // PRICE_FILTER price precision by symbol
+	symbolFilters   map[string]SymbolFilters
+	maxLeverage     map[string]int // Max initial leverage by symbol (first leverage bracket)
+	metadataTime    time.Time
+	metadataMutex   sync.RWMutex
 }
 
+// exchangeMetadataTTL how long exchangeInfo / leverage brackets are reused before refetching
+const exchangeMetadataTTL = 6 * time.Hour
+
 // NewFuturesTrader 创建合约交易器
 func NewFuturesTrader(apiKey, secretKey string) *FuturesTrader {
 	client := futures.NewClient(apiKey, secretKey)
+	accountKey := ratelimit.AccountKey("binance", apiKey)
+	client.HTTPClient = &http.Client{Transport: ratelimit.NewBinanceTransport(nil, accountKey)}
 
 	// Sync with Binance server time to avoid timestamp errors
 	syncServerTime(client)
 
 	return &FuturesTrader{
 		client:        client,
+		accountKey:    accountKey,
 		cacheDuration: 15 * time.Second, // 15秒缓存
 	}
 }
@@ -56,7 +82,7 @@ func syncServerTime(client *futures.Client) {
 	// Get Binance server time
 	serverTime, err := client.NewServerTimeService().Do(context.Background())
 	if err != nil {
-		log.Printf("⚠️  Failed to get Binance server time: %v (will continue without sync)", err)
+		binanceLog.Warnf("⚠️  Failed to get Binance server time: %v (will continue without sync)", err)
 		return
 	}
 
@@ -65,7 +91,7 @@ func syncServerTime(client *futures.Client) {
 	timeOffset := serverTime - localTime
 
 	if timeOffset > 1000 || timeOffset < -1000 {
-		log.Printf("⚠️  Time offset detected: %d ms (local time is %s ahead/behind server)",
+		binanceLog.Warnf("⚠️  Time offset detected: %d ms (local time is %s ahead/behind server)",
 			timeOffset,
 			func() string {
 				if timeOffset > 0 {
@@ -73,13 +99,14 @@ func syncServerTime(client *futures.Client) {
 				}
 				return fmt.Sprintf("%.1f seconds", float64(-timeOffset)/1000.0)
 			}())
-		log.Printf("💡 Tip: Sync your system clock: Windows Settings > Time & Language > Date & Time > Sync now")
+		binanceLog.Infof("💡 Tip: Sync your system clock: Windows Settings > Time & Language > Date & Time > Sync now")
 	} else {
-		log.Printf("✓ Time synchronized with Binance server (offset: %d ms)", timeOffset)
+		binanceLog.Infof("✓ Time synchronized with Binance server (offset: %d ms)", timeOffset)
 	}
 
-	// Note: go-binance library handles timestamps automatically
+	// Signed requests are stamped with local time minus client.TimeOffset, i.e. with server time
 	// If errors persist, sync system clock: Windows Settings > Time & Language > Sync now
+	client.TimeOffset = -timeOffset
 }
 
 // reSyncServerTime re-syncs server time (called on timestamp errors)
@@ -92,13 +119,23 @@ func (t *FuturesTrader) reSyncServerTime() {
 		return
 	}
 
-	log.Printf("🔄 Re-syncing with Binance server time due to timestamp error...")
+	binanceLog.Infof("🔄 Re-syncing with Binance server time due to timestamp error...")
 	syncServerTime(t.client)
 	t.lastTimeSync = time.Now()
 }
 
+// ResyncClock re-syncs the request timestamp offset with Binance server time (at most once per minute)
+func (t *FuturesTrader) ResyncClock() {
+	t.reSyncServerTime()
+}
+
+// RefreshSymbolRules reloads the quantity and price precision of every symbol (after a precision rejection)
+func (t *FuturesTrader) RefreshSymbolRules(symbol string) error {
+	return t.loadExchangeInfo()
+}
+
 // GetBalance 获取账户余额（带缓存）
-func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
+func (t *FuturesTrader) GetBalance() (*Balance, error) {
 	// 先检查缓存是否有效
 	t.balanceCacheMutex.RLock()
 	if t.cachedBalance != nil && time.Since(t.balanceCacheTime) < t.cacheDuration {
@@ -107,52 +144,63 @@ func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
 	}
 	t.balanceCacheMutex.RUnlock()
 
-	// 缓存过期或不存在，调用API
+	// 缓存过期或不存在，调用API（同一账户的trader共享一次请求）
+	shared, err := ratelimit.Coalesce(t.accountKey+"/balance", t.cacheDuration, func() (interface{}, error) {
+		return t.fetchBalance()
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := shared.(*Balance)
+
+	// 更新缓存
+	t.balanceCacheMutex.Lock()
+	t.cachedBalance = result
+	t.balanceCacheTime = time.Now()
+	t.balanceCacheMutex.Unlock()
+
+	return result, nil
+}
+
+// fetchBalance gets the account balance from Binance
+func (t *FuturesTrader) fetchBalance() (*Balance, error) {
 	account, err := t.client.NewGetAccountService().Do(context.Background())
 	if err != nil {
 		// If timestamp error, try re-syncing and retry once
 		if strings.Contains(err.Error(), "-1021") || strings.Contains(err.Error(), "recvWindow") || strings.Contains(err.Error(), "timestamp") {
-			log.Printf("⚠️  Timestamp error detected, re-syncing server time...")
+			binanceLog.Warnf("⚠️  Timestamp error detected, re-syncing server time...")
 			t.reSyncServerTime()
 			// Retry once after re-sync
 			account, err = t.client.NewGetAccountService().Do(context.Background())
 			if err != nil {
-				log.Printf("❌ Binance API call failed after re-sync: %v", err)
+				binanceLog.Errorf("❌ Binance API call failed after re-sync: %v", err)
 				return nil, fmt.Errorf("failed to get account info (timestamp error persists - please sync system clock): %w", err)
 			}
 		} else {
-			log.Printf("❌ Binance API call failed: %v", err)
+			binanceLog.Errorf("❌ Binance API call failed: %v", err)
 			return nil, fmt.Errorf("failed to get account info: %w", err)
 		}
 	}
 
-	result := make(map[string]interface{})
-	result["totalWalletBalance"], _ = strconv.ParseFloat(account.TotalWalletBalance, 64)
-	result["availableBalance"], _ = strconv.ParseFloat(account.AvailableBalance, 64)
-	result["totalUnrealizedProfit"], _ = strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
+	result := &Balance{}
+	result.WalletBalance, _ = strconv.ParseFloat(account.TotalWalletBalance, 64)
+	result.AvailableBalance, _ = strconv.ParseFloat(account.AvailableBalance, 64)
+	result.UnrealizedProfit, _ = strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
 
 	// Calculate margin balance (wallet + unrealized P&L) for clarity
-	walletBalance, _ := strconv.ParseFloat(account.TotalWalletBalance, 64)
-	unrealizedPnl, _ := strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
-	marginBalance := walletBalance + unrealizedPnl
+	marginBalance := result.Equity()
 
-	log.Printf("✓ Binance API returned: Wallet Balance=%s, Margin Balance=%.2f, Available=%s, Unrealized P&L=%s",
+	binanceLog.Infof("✓ Binance API returned: Wallet Balance=%s, Margin Balance=%.2f, Available=%s, Unrealized P&L=%s",
 		account.TotalWalletBalance,
 		marginBalance,
 		account.AvailableBalance,
 		account.TotalUnrealizedProfit)
 
-	// 更新缓存
-	t.balanceCacheMutex.Lock()
-	t.cachedBalance = result
-	t.balanceCacheTime = time.Now()
-	t.balanceCacheMutex.Unlock()
-
 	return result, nil
 }
 
 // GetPositions 获取所有持仓（带缓存）
-func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *FuturesTrader) GetPositions() ([]Position, error) {
 	// 先检查缓存是否有效
 	t.positionsCacheMutex.RLock()
 	if t.cachedPositions != nil && time.Since(t.positionsCacheTime) < t.cacheDuration {
@@ -161,12 +209,31 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 	}
 	t.positionsCacheMutex.RUnlock()
 
-	// 缓存过期或不存在，调用API
+	// 缓存过期或不存在，调用API（同一账户的trader共享一次请求）
+	shared, err := ratelimit.Coalesce(t.accountKey+"/positions", t.cacheDuration, func() (interface{}, error) {
+		return t.fetchPositions()
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := shared.([]Position)
+
+	// 更新缓存
+	t.positionsCacheMutex.Lock()
+	t.cachedPositions = result
+	t.positionsCacheTime = time.Now()
+	t.positionsCacheMutex.Unlock()
+
+	return result, nil
+}
+
+// fetchPositions gets the open positions from Binance
+func (t *FuturesTrader) fetchPositions() ([]Position, error) {
 	positions, err := t.client.NewGetPositionRiskService().Do(context.Background())
 	if err != nil {
 		// If timestamp error, try re-syncing and retry once
 		if strings.Contains(err.Error(), "-1021") || strings.Contains(err.Error(), "recvWindow") || strings.Contains(err.Error(), "timestamp") {
-			log.Printf("⚠️  Timestamp error detected, re-syncing server time...")
+			binanceLog.Warnf("⚠️  Timestamp error detected, re-syncing server time...")
 			t.reSyncServerTime()
 			// Retry once after re-sync
 			positions, err = t.client.NewGetPositionRiskService().Do(context.Background())
@@ -178,38 +245,28 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 		}
 	}
 
-	var result []map[string]interface{}
+	var result []Position
 	for _, pos := range positions {
 		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
 		if posAmt == 0 {
 			continue // 跳过无持仓的
 		}
 
-		posMap := make(map[string]interface{})
-		posMap["symbol"] = pos.Symbol
-		posMap["positionAmt"], _ = strconv.ParseFloat(pos.PositionAmt, 64)
-		posMap["entryPrice"], _ = strconv.ParseFloat(pos.EntryPrice, 64)
-		posMap["markPrice"], _ = strconv.ParseFloat(pos.MarkPrice, 64)
-		posMap["unRealizedProfit"], _ = strconv.ParseFloat(pos.UnRealizedProfit, 64)
-		posMap["leverage"], _ = strconv.ParseFloat(pos.Leverage, 64)
-		posMap["liquidationPrice"], _ = strconv.ParseFloat(pos.LiquidationPrice, 64)
-
-		// 判断方向
-		if posAmt > 0 {
-			posMap["side"] = "long"
-		} else {
-			posMap["side"] = "short"
+		position := Position{Symbol: pos.Symbol, Side: "long", Quantity: posAmt}
+		position.EntryPrice, _ = strconv.ParseFloat(pos.EntryPrice, 64)
+		position.MarkPrice, _ = strconv.ParseFloat(pos.MarkPrice, 64)
+		position.UnrealizedProfit, _ = strconv.ParseFloat(pos.UnRealizedProfit, 64)
+		position.Leverage, _ = strconv.Atoi(pos.Leverage)
+		position.LiquidationPrice, _ = strconv.ParseFloat(pos.LiquidationPrice, 64)
+
+		// 判断方向（空仓数量是负的，取绝对值）
+		if posAmt < 0 {
+			position.Side = "short"
+			position.Quantity = -posAmt
 		}
 
-		result = append(result, posMap)
+		result = append(result, position)
 	}
-
-	// 更新缓存
-	t.positionsCacheMutex.Lock()
-	t.cachedPositions = result
-	t.positionsCacheTime = time.Now()
-	t.positionsCacheMutex.Unlock()
-
 	return result, nil
 }
 
@@ -220,18 +277,22 @@ func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
 	positions, err := t.GetPositions()
 	if err == nil {
 		for _, pos := range positions {
-			if pos["symbol"] == symbol {
-				if lev, ok := pos["leverage"].(float64); ok {
-					currentLeverage = int(lev)
-					break
-				}
+			if pos.Symbol == symbol && pos.Leverage > 0 {
+				currentLeverage = pos.Leverage
+				break
 			}
 		}
 	}
 
+	// Clamp to the symbol's maximum leverage when brackets are known (the exchange rejects higher values)
+	if maxLeverage := t.maxLeverageFor(symbol); maxLeverage > 0 && leverage > maxLeverage {
+		binanceLog.Warnf("  ⚠ %s max leverage is %dx, using %dx instead of %dx", symbol, maxLeverage, maxLeverage, leverage)
+		leverage = maxLeverage
+	}
+
 	// 如果当前杠杆已经是目标杠杆，跳过
 	if currentLeverage == leverage && currentLeverage > 0 {
-		log.Printf("  ✓ %s leverage already %dx, no need to change", symbol, leverage)
+		binanceLog.Infof("  ✓ %s leverage already %dx, no need to change", symbol, leverage)
 		return nil
 	}
 
@@ -244,28 +305,117 @@ func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
 	if err != nil {
 		// 如果错误信息包含"No need to change"，说明杠杆已经是目标值
 		if contains(err.Error(), "No need to change") {
-			log.Printf("  ✓ %s leverage already %dx", symbol, leverage)
+			binanceLog.Infof("  ✓ %s leverage already %dx", symbol, leverage)
 			return nil
 		}
 		return fmt.Errorf("failed to set leverage: %w", err)
 	}
 
-	log.Printf("  ✓ %s leverage switched to %dx", symbol, leverage)
+	binanceLog.Infof("  ✓ %s leverage switched to %dx", symbol, leverage)
 
 	// Wait 5 seconds after switching leverage (avoid cooldown error)
-	log.Printf("  ⏱ Waiting 5 seconds cooldown...")
+	binanceLog.Infof("  ⏱ Waiting 5 seconds cooldown...")
 	time.Sleep(5 * time.Second)
 
 	return nil
 }
 
+// GetLeverage returns the symbol's current leverage setting (reported even without an open position)
+func (t *FuturesTrader) GetLeverage(symbol string) (int, error) {
+	risks, err := t.client.NewGetPositionRiskService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s leverage: %w", symbol, err)
+	}
+	for _, risk := range risks {
+		if leverage, err := strconv.Atoi(risk.Leverage); err == nil && leverage > 0 {
+			return leverage, nil
+		}
+	}
+	return 0, fmt.Errorf("%s leverage not reported", symbol)
+}
+
+// ConfigureLeverage sets isolated margin (as orders use) and the requested leverage, clamped to each symbol's
+// maximum, for many symbols without the per-order cooldowns, then reads every symbol's settings back
+func (t *FuturesTrader) ConfigureLeverage(requested map[string]int) []LeverageSetting {
+	if t.maxLeverageFor("BTCUSDT") == 0 {
+		if err := t.loadLeverageBrackets(); err != nil {
+			binanceLog.Warnf("  ⚠ Failed to load leverage brackets: %v", err)
+		}
+	}
+
+	before, err := t.leverageSettings()
+	if err != nil {
+		binanceLog.Warnf("  ⚠ Failed to read current leverage settings: %v", err)
+	}
+
+	results := make(map[string]*LeverageSetting, len(requested))
+	for symbol, leverage := range requested {
+		setting := &LeverageSetting{Symbol: symbol, Requested: leverage}
+		results[symbol] = setting
+
+		current, listed := before[symbol]
+		if before != nil && !listed {
+			setting.Error = "symbol not listed on Binance Futures"
+			continue
+		}
+		if current.MarginType != "isolated" {
+			if err := t.SetMarginType(symbol, futures.MarginTypeIsolated); err != nil {
+				setting.Error = err.Error()
+				continue
+			}
+		}
+		target := leverage
+		if maxLeverage := t.maxLeverageFor(symbol); maxLeverage > 0 && target > maxLeverage {
+			target = maxLeverage
+		}
+		if current.Applied == target {
+			continue
+		}
+		if _, err := t.client.NewChangeLeverageService().Symbol(symbol).Leverage(target).Do(context.Background()); err != nil && !contains(err.Error(), "No need to change") {
+			setting.Error = fmt.Sprintf("failed to set leverage: %v", err)
+		}
+	}
+
+	// Verify: what the exchange reports now
+	after, err := t.leverageSettings()
+	if err != nil {
+		binanceLog.Warnf("  ⚠ Failed to verify leverage settings: %v", err)
+	}
+	settings := make([]LeverageSetting, 0, len(results))
+	for symbol, setting := range results {
+		if reported, ok := after[symbol]; ok {
+			setting.Applied = reported.Applied
+			setting.MarginType = reported.MarginType
+		}
+		settings = append(settings, *setting)
+	}
+	return settings
+}
+
+// leverageSettings every symbol's current leverage and margin type (reported even without a position)
+func (t *FuturesTrader) leverageSettings() (map[string]LeverageSetting, error) {
+	risks, err := t.client.NewGetPositionRiskService().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]LeverageSetting, len(risks))
+	for _, risk := range risks {
+		if _, seen := settings[risk.Symbol]; seen {
+			continue // Hedge mode reports LONG and SHORT with the same settings
+		}
+		leverage, _ := strconv.Atoi(risk.Leverage)
+		settings[risk.Symbol] = LeverageSetting{Symbol: risk.Symbol, Applied: leverage, MarginType: strings.ToLower(risk.MarginType)}
+	}
+	return settings, nil
+}
+
 // SetMarginType 设置保证金模式
 func (t *FuturesTrader) SetMarginType(symbol string, marginType futures.MarginType) error {
 	// Check if already in Multi-Assets Mode - skip entirely if so
 	t.multiAssetsMutex.RLock()
 	if t.isMultiAssetsMode {
 		t.multiAssetsMutex.RUnlock()
-		log.Printf("  ⚠ %s account uses Multi-Assets Mode, skipping margin mode setting (not needed)", symbol)
+		binanceLog.Warnf("  ⚠ %s account uses Multi-Assets Mode, skipping margin mode setting (not needed)", symbol)
 		return nil
 	}
 	t.multiAssetsMutex.RUnlock()
@@ -278,14 +428,14 @@ func (t *FuturesTrader) SetMarginType(symbol string, marginType futures.MarginTy
 	if err != nil {
 		// If already in this mode, not an error
 		if contains(err.Error(), "No need to change") {
-			log.Printf("  ✓ %s margin mode already %s", symbol, marginType)
+			binanceLog.Infof("  ✓ %s margin mode already %s", symbol, marginType)
 			return nil
 		}
 		// Multi-Assets Mode (Unified Trading Account) doesn't support margin mode changes
 		// Error -4168: "Unable to adjust to isolated-margin mode under the Multi-Assets mode"
 		// Error -4050: "Cross balance insufficient" (also indicates Multi-Assets Mode)
 		if contains(err.Error(), "Multi-Assets mode") || contains(err.Error(), "-4168") || contains(err.Error(), "-4050") {
-			log.Printf("  ⚠ %s account uses Multi-Assets Mode, skipping margin mode setting (not needed)", symbol)
+			binanceLog.Warnf("  ⚠ %s account uses Multi-Assets Mode, skipping margin mode setting (not needed)", symbol)
 			// Mark as Multi-Assets Mode for future orders
 			t.multiAssetsMutex.Lock()
 			t.isMultiAssetsMode = true
@@ -295,20 +445,20 @@ func (t *FuturesTrader) SetMarginType(symbol string, marginType futures.MarginTy
 		return fmt.Errorf("failed to set margin mode: %w", err)
 	}
 
-	log.Printf("  ✓ %s margin mode switched to %s", symbol, marginType)
+	binanceLog.Infof("  ✓ %s margin mode switched to %s", symbol, marginType)
 
 	// Wait 3 seconds after switching margin mode (avoid cooldown error)
-	log.Printf("  ⏱ Waiting 3 seconds cooldown...")
+	binanceLog.Infof("  ⏱ Waiting 3 seconds cooldown...")
 	time.Sleep(3 * time.Second)
 
 	return nil
 }
 
 // OpenLong 开多仓
-func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
-	// 先取消该币种的所有委托单（清理旧的止损止盈单）
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	// 先取消该币种自己的委托单（清理旧的止损止盈单）
+	if err := t.cancelOwnOrders(symbol); err != nil {
+		binanceLog.Warnf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
 	}
 
 	// 设置杠杆
@@ -333,8 +483,10 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	t.multiAssetsMutex.RUnlock()
 
 	// Create market buy order
+	clientOrderID := newClientOrderID(t.clientOrderTag, ClientOrderKindOpen)
 	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
+		NewClientOrderID(clientOrderID).
 		Side(futures.SideTypeBuy).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr)
@@ -351,13 +503,14 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	if err != nil {
 		// If -4061 error (position side mismatch), try with BOTH and mark as Multi-Assets Mode
 		if contains(err.Error(), "-4061") || contains(err.Error(), "position side does not match") {
-			log.Printf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
+			binanceLog.Warnf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
 			t.multiAssetsMutex.Lock()
 			t.isMultiAssetsMode = true
 			t.multiAssetsMutex.Unlock()
 			// Retry with BOTH
 			order, err = t.client.NewCreateOrderService().
 				Symbol(symbol).
+				NewClientOrderID(clientOrderID).
 				Side(futures.SideTypeBuy).
 				PositionSide(futures.PositionSideTypeBoth).
 				Type(futures.OrderTypeMarket).
@@ -369,21 +522,17 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 		}
 	}
 
-	log.Printf("✓ Long position opened: %s quantity: %s", symbol, quantityStr)
-	log.Printf("  Order ID: %d", order.OrderID)
+	binanceLog.Infof("✓ Long position opened: %s quantity: %s", symbol, quantityStr)
+	binanceLog.Infof("  Order ID: %d", order.OrderID)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return binanceOrder(order), nil
 }
 
 // OpenShort 开空仓
-func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
-	// 先取消该币种的所有委托单（清理旧的止损止盈单）
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	// 先取消该币种自己的委托单（清理旧的止损止盈单）
+	if err := t.cancelOwnOrders(symbol); err != nil {
+		binanceLog.Warnf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
 	}
 
 	// 设置杠杆
@@ -408,8 +557,10 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	t.multiAssetsMutex.RUnlock()
 
 	// Create market sell order
+	clientOrderID := newClientOrderID(t.clientOrderTag, ClientOrderKindOpen)
 	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
+		NewClientOrderID(clientOrderID).
 		Side(futures.SideTypeSell).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr)
@@ -426,13 +577,14 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	if err != nil {
 		// If -4061 error (position side mismatch), try with BOTH and mark as Multi-Assets Mode
 		if contains(err.Error(), "-4061") || contains(err.Error(), "position side does not match") {
-			log.Printf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
+			binanceLog.Warnf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
 			t.multiAssetsMutex.Lock()
 			t.isMultiAssetsMode = true
 			t.multiAssetsMutex.Unlock()
 			// Retry with BOTH
 			order, err = t.client.NewCreateOrderService().
 				Symbol(symbol).
+				NewClientOrderID(clientOrderID).
 				Side(futures.SideTypeSell).
 				PositionSide(futures.PositionSideTypeBoth).
 				Type(futures.OrderTypeMarket).
@@ -444,18 +596,14 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 		}
 	}
 
-	log.Printf("✓ Short position opened: %s quantity: %s", symbol, quantityStr)
-	log.Printf("  Order ID: %d", order.OrderID)
+	binanceLog.Infof("✓ Short position opened: %s quantity: %s", symbol, quantityStr)
+	binanceLog.Infof("  Order ID: %d", order.OrderID)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return binanceOrder(order), nil
 }
 
 // CloseLong 平多仓
-func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -463,11 +611,8 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "long" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		if pos, ok := findPosition(positions, symbol, "long"); ok {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
@@ -487,8 +632,10 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 	t.multiAssetsMutex.RUnlock()
 
 	// Create market sell order (close long)
+	clientOrderID := newClientOrderID(t.clientOrderTag, ClientOrderKindClose)
 	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
+		NewClientOrderID(clientOrderID).
 		Side(futures.SideTypeSell).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr)
@@ -505,13 +652,14 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 	if err != nil {
 		// If -4061 error (position side mismatch), try with BOTH and mark as Multi-Assets Mode
 		if contains(err.Error(), "-4061") || contains(err.Error(), "position side does not match") {
-			log.Printf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
+			binanceLog.Warnf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
 			t.multiAssetsMutex.Lock()
 			t.isMultiAssetsMode = true
 			t.multiAssetsMutex.Unlock()
 			// Retry with BOTH
 			order, err = t.client.NewCreateOrderService().
 				Symbol(symbol).
+				NewClientOrderID(clientOrderID).
 				Side(futures.SideTypeSell).
 				PositionSide(futures.PositionSideTypeBoth).
 				Type(futures.OrderTypeMarket).
@@ -523,22 +671,13 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 		}
 	}
 
-	log.Printf("✓ Long position closed: %s quantity: %s", symbol, quantityStr)
+	binanceLog.Infof("✓ Long position closed: %s quantity: %s", symbol, quantityStr)
 
-	// Cancel all pending orders after closing position (stop loss/take profit orders)
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ Failed to cancel orders: %v", err)
-	}
-
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return binanceOrder(order), nil
 }
 
 // CloseShort 平空仓
-func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -546,11 +685,8 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "short" {
-				quantity = -pos["positionAmt"].(float64) // 空仓数量是负的，取绝对值
-				break
-			}
+		if pos, ok := findPosition(positions, symbol, "short"); ok {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
@@ -570,8 +706,10 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 	t.multiAssetsMutex.RUnlock()
 
 	// Create market buy order (close short)
+	clientOrderID := newClientOrderID(t.clientOrderTag, ClientOrderKindClose)
 	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
+		NewClientOrderID(clientOrderID).
 		Side(futures.SideTypeBuy).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr)
@@ -588,13 +726,14 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 	if err != nil {
 		// If -4061 error (position side mismatch), try with BOTH and mark as Multi-Assets Mode
 		if contains(err.Error(), "-4061") || contains(err.Error(), "position side does not match") {
-			log.Printf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
+			binanceLog.Warnf("  ⚠ Detected Multi-Assets Mode, retrying with PositionSide BOTH...")
 			t.multiAssetsMutex.Lock()
 			t.isMultiAssetsMode = true
 			t.multiAssetsMutex.Unlock()
 			// Retry with BOTH
 			order, err = t.client.NewCreateOrderService().
 				Symbol(symbol).
+				NewClientOrderID(clientOrderID).
 				Side(futures.SideTypeBuy).
 				PositionSide(futures.PositionSideTypeBoth).
 				Type(futures.OrderTypeMarket).
@@ -606,18 +745,9 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 		}
 	}
 
-	log.Printf("✓ Short position closed: %s quantity: %s", symbol, quantityStr)
-
-	// Cancel all pending orders after closing position (stop loss/take profit orders)
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ Failed to cancel orders: %v", err)
-	}
+	binanceLog.Infof("✓ Short position closed: %s quantity: %s", symbol, quantityStr)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return binanceOrder(order), nil
 }
 
 // CancelAllOrders 取消该币种的所有挂单
@@ -630,10 +760,252 @@ func (t *FuturesTrader) CancelAllOrders(symbol string) error {
 		return fmt.Errorf("failed to cancel orders: %w", err)
 	}
 
-	log.Printf("  ✓ Cancelled all orders for %s", symbol)
+	binanceLog.Infof("  ✓ Cancelled all orders for %s", symbol)
+	return nil
+}
+
+// cancelOwnOrders cancels the symbol's orders placed with this trader's client order tag, leaving the orders of
+// other traders sharing the account in place (every order of the symbol when no tag is set)
+func (t *FuturesTrader) cancelOwnOrders(symbol string) error {
+	if t.clientOrderTag == "" {
+		return t.CancelAllOrders(symbol)
+	}
+	orders, err := t.GetOpenOrders(symbol)
+	if err != nil {
+		return err
+	}
+	for _, o := range orders {
+		if tag, _, ok := parseClientOrderID(o.ClientOrderID); ok && tag == t.clientOrderTag {
+			if err := t.CancelOrder(symbol, o.OrderID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// OpenLimit places a GTC limit order opening positionSide at price (GTX when postOnly: Binance expires it
+// instead of letting it take liquidity). Unlike OpenLong/OpenShort it leaves the symbol's other orders in place
+func (t *FuturesTrader) OpenLimit(symbol, positionSide string, quantity float64, leverage int, price float64, postOnly bool) (*Order, error) {
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+	if err := t.SetMarginType(symbol, futures.MarginTypeIsolated); err != nil {
+		return nil, err
+	}
+
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	side := futures.SideTypeBuy
+	posSide := futures.PositionSideTypeLong
+	if positionSide == "SHORT" {
+		side = futures.SideTypeSell
+		posSide = futures.PositionSideTypeShort
+	}
+	t.multiAssetsMutex.RLock()
+	if t.isMultiAssetsMode {
+		posSide = futures.PositionSideTypeBoth
+	}
+	t.multiAssetsMutex.RUnlock()
+
+	timeInForce := futures.TimeInForceTypeGTC
+	if postOnly {
+		timeInForce = futures.TimeInForceTypeGTX
+	}
+
+	order, err := t.client.NewCreateOrderService().
+		Symbol(symbol).
+		NewClientOrderID(newClientOrderID(t.clientOrderTag, ClientOrderKindOpen)).
+		Side(side).
+		PositionSide(posSide).
+		Type(futures.OrderTypeLimit).
+		TimeInForce(timeInForce).
+		Price(t.formatPrice(symbol, price)).
+		Quantity(quantityStr).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to place %s limit order: %w", strings.ToLower(positionSide), err)
+	}
+
+	binanceLog.Infof("✓ %s limit order placed: %s quantity: %s @ %s (%s), Order ID: %d",
+		positionSide, symbol, quantityStr, order.Price, timeInForce, order.OrderID)
+	return binanceOrder(order), nil
+}
+
+// GetOrder returns an order's status, filled quantity and average fill price
+func (t *FuturesTrader) GetOrder(symbol string, orderID int64) (*Order, error) {
+	order, err := t.client.NewGetOrderService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order %d: %w", orderID, err)
+	}
+
+	price, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Price:         price,
+		ExecutedQty:   executedQty,
+	}, nil
+}
+
+// CancelOrder cancels one resting order
+func (t *FuturesTrader) CancelOrder(symbol string, orderID int64) error {
+	_, err := t.client.NewCancelOrderService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to cancel order %d: %w", orderID, err)
+	}
+
+	binanceLog.Infof("  ✓ Cancelled %s order %d", symbol, orderID)
 	return nil
 }
 
+// GetIncomeSince returns commission and funding fee income recorded after since. Commissions carry the client
+// order ID of the order whose trade they were charged for (so traders sharing the account can tell theirs apart)
+func (t *FuturesTrader) GetIncomeSince(since time.Time) ([]IncomeRecord, error) {
+	history, err := t.client.NewGetIncomeHistoryService().
+		StartTime(since.UnixMilli()).
+		Limit(1000).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get income history: %w", err)
+	}
+
+	var records []IncomeRecord
+	tradeIDs := make(map[string][]int) // Symbol -> indexes of commission records
+	for _, h := range history {
+		if h.IncomeType != "COMMISSION" && h.IncomeType != "FUNDING_FEE" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(h.Income, 64)
+		if err != nil {
+			continue
+		}
+		if h.IncomeType == "COMMISSION" && h.TradeID != "" {
+			tradeIDs[h.Symbol] = append(tradeIDs[h.Symbol], len(records))
+		}
+		records = append(records, IncomeRecord{
+			Symbol:  h.Symbol,
+			Type:    h.IncomeType,
+			Amount:  amount,
+			Time:    time.UnixMilli(h.Time),
+			TradeID: h.TradeID,
+		})
+	}
+
+	for symbol, indexes := range tradeIDs {
+		clientOrderIDs, err := t.tradeClientOrderIDs(symbol, since)
+		if err != nil {
+			binanceLog.Warnf("  ⚠ Failed to match %s commissions to their orders: %v", symbol, err)
+			continue
+		}
+		for _, i := range indexes {
+			records[i].ClientOrderID = clientOrderIDs[records[i].TradeID]
+		}
+	}
+	return records, nil
+}
+
+// tradeClientOrderIDs maps the IDs of the symbol's trades since since to the client order ID of their order
+func (t *FuturesTrader) tradeClientOrderIDs(symbol string, since time.Time) (map[string]string, error) {
+	trades, err := t.client.NewListAccountTradeService().
+		Symbol(symbol).
+		StartTime(since.UnixMilli()).
+		Limit(1000).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %w", err)
+	}
+	clientOrderIDs := make(map[int64]string)
+	byTrade := make(map[string]string, len(trades))
+	for _, trade := range trades {
+		clientOrderID, ok := clientOrderIDs[trade.OrderID]
+		if !ok {
+			order, err := t.GetOrder(symbol, trade.OrderID)
+			if err != nil {
+				return nil, err
+			}
+			clientOrderID = order.ClientOrderID
+			clientOrderIDs[trade.OrderID] = clientOrderID
+		}
+		byTrade[strconv.FormatInt(trade.ID, 10)] = clientOrderID
+	}
+	return byTrade, nil
+}
+
+// SetClientOrderTag sets the trader tag embedded in client order IDs
+func (t *FuturesTrader) SetClientOrderTag(tag string) {
+	t.clientOrderTag = tag
+}
+
+// GetOrderHistory returns the symbol's orders between start and end (Binance allows at most 7 days per query)
+func (t *FuturesTrader) GetOrderHistory(symbol string, start, end time.Time) ([]ExchangeOrder, error) {
+	history, err := t.client.NewListOrdersService().
+		Symbol(symbol).
+		StartTime(start.UnixMilli()).
+		EndTime(end.UnixMilli()).
+		Limit(1000).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order history for %s: %w", symbol, err)
+	}
+
+	orders := make([]ExchangeOrder, 0, len(history))
+	for _, o := range history {
+		orders = append(orders, exchangeOrderOf(o))
+	}
+	return orders, nil
+}
+
+// GetOpenOrders returns the resting orders of symbol ("" = every symbol)
+func (t *FuturesTrader) GetOpenOrders(symbol string) ([]ExchangeOrder, error) {
+	service := t.client.NewListOpenOrdersService()
+	if symbol != "" {
+		service = service.Symbol(symbol)
+	}
+	open, err := service.Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open orders: %w", err)
+	}
+
+	orders := make([]ExchangeOrder, 0, len(open))
+	for _, o := range open {
+		orders = append(orders, exchangeOrderOf(o))
+	}
+	return orders, nil
+}
+
+// exchangeOrderOf converts a Binance order
+func exchangeOrderOf(o *futures.Order) ExchangeOrder {
+	executedQty, _ := strconv.ParseFloat(o.ExecutedQuantity, 64)
+	avgPrice, _ := strconv.ParseFloat(o.AvgPrice, 64)
+	stopPrice, _ := strconv.ParseFloat(o.StopPrice, 64)
+	return ExchangeOrder{
+		Symbol:        o.Symbol,
+		OrderID:       o.OrderID,
+		ClientOrderID: o.ClientOrderID,
+		Side:          string(o.Side),
+		PositionSide:  string(o.PositionSide),
+		Type:          string(o.OrigType),
+		Status:        string(o.Status),
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		StopPrice:     stopPrice,
+		Time:          time.UnixMilli(o.Time),
+	}
+}
+
 // GetMarketPrice 获取市场价格
 func (t *FuturesTrader) GetMarketPrice(symbol string) (float64, error) {
 	prices, err := t.client.NewListPricesService().Symbol(symbol).Do(context.Background())
@@ -691,22 +1063,58 @@ func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity
 
 	_, err = t.client.NewCreateOrderService().
 		Symbol(symbol).
+		NewClientOrderID(newClientOrderID(t.clientOrderTag, ClientOrderKindStopLoss)).
 		Side(side).
 		PositionSide(posSide).
 		Type(futures.OrderTypeStopMarket).
-		StopPrice(fmt.Sprintf("%.8f", stopPrice)).
+		StopPrice(t.formatPrice(symbol, stopPrice)).
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
 		Do(context.Background())
 
 	if err != nil {
-		// Make it a warning, not a fatal error - position is still open
-		log.Printf("  ⚠ Failed to set stop loss: %v (position remains open)", err)
-		return nil // Don't fail the entire trade
+		// Reported so the open saga can retry (and close the position if the stop cannot be placed)
+		return fmt.Errorf("failed to set stop loss: %w", err)
 	}
 
-	log.Printf("  ✓ Stop loss set: %.4f", stopPrice)
+	binanceLog.Infof("  ✓ Stop loss set: %.4f", stopPrice)
+	return nil
+}
+
+// AddPositionMargin adds margin to an isolated position
+func (t *FuturesTrader) AddPositionMargin(symbol string, positionSide string, amount float64) error {
+	posSide := futures.PositionSideTypeLong
+	if positionSide == "SHORT" {
+		posSide = futures.PositionSideTypeShort
+	}
+
+	// Check if Multi-Assets Mode - use BOTH for position side
+	t.multiAssetsMutex.RLock()
+	useBothSide := t.isMultiAssetsMode
+	t.multiAssetsMutex.RUnlock()
+
+	if useBothSide {
+		posSide = futures.PositionSideTypeBoth
+	}
+
+	err := t.client.NewUpdatePositionMarginService().
+		Symbol(symbol).
+		PositionSide(posSide).
+		Amount(fmt.Sprintf("%.2f", amount)).
+		Type(1). // 1 = add margin, 2 = reduce margin
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to add position margin: %w", err)
+	}
+
+	// Balance changed - drop the cache
+	t.balanceCacheMutex.Lock()
+	t.cachedBalance = nil
+	t.balanceCacheMutex.Unlock()
+	ratelimit.Forget(t.accountKey + "/balance")
+
+	binanceLog.Infof("  ✓ Added %.2f USDT margin to %s %s", amount, symbol, positionSide)
 	return nil
 }
 
@@ -740,10 +1148,11 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 
 	_, err = t.client.NewCreateOrderService().
 		Symbol(symbol).
+		NewClientOrderID(newClientOrderID(t.clientOrderTag, ClientOrderKindTakeProfit)).
 		Side(side).
 		PositionSide(posSide).
 		Type(futures.OrderTypeTakeProfitMarket).
-		StopPrice(fmt.Sprintf("%.8f", takeProfitPrice)).
+		StopPrice(t.formatPrice(symbol, takeProfitPrice)).
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
@@ -751,37 +1160,155 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 
 	if err != nil {
 		// Make it a warning, not a fatal error - position is still open
-		log.Printf("  ⚠ Failed to set take profit: %v (position remains open)", err)
+		binanceLog.Warnf("  ⚠ Failed to set take profit: %v (position remains open)", err)
 		return nil // Don't fail the entire trade
 	}
 
-	log.Printf("  ✓ Take profit set: %.4f", takeProfitPrice)
+	binanceLog.Infof("  ✓ Take profit set: %.4f", takeProfitPrice)
 	return nil
 }
 
 // GetSymbolPrecision 获取交易对的数量精度
 func (t *FuturesTrader) GetSymbolPrecision(symbol string) (int, error) {
+	if precision, ok := t.cachedPrecision(symbol); ok {
+		return precision, nil
+	}
+	if err := t.loadExchangeInfo(); err != nil {
+		return 0, fmt.Errorf("获取交易规则失败: %w", err)
+	}
+	if precision, ok := t.cachedPrecision(symbol); ok {
+		return precision, nil
+	}
+
+	binanceLog.Warnf("  ⚠ %s 未找到精度信息，使用默认精度3", symbol)
+	return 3, nil // 默认精度为3
+}
+
+// cachedPrecision returns the cached quantity precision (false if missing or the cache expired)
+func (t *FuturesTrader) cachedPrecision(symbol string) (int, bool) {
+	t.metadataMutex.RLock()
+	defer t.metadataMutex.RUnlock()
+	if time.Since(t.metadataTime) >= exchangeMetadataTTL {
+		return 0, false
+	}
+	precision, ok := t.symbolPrecision[symbol]
+	return precision, ok
+}
+
+// loadExchangeInfo caches the quantity precision (LOT_SIZE) and price precision (PRICE_FILTER) of every symbol
+func (t *FuturesTrader) loadExchangeInfo() error {
 	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
 	if err != nil {
-		return 0, fmt.Errorf("获取交易规则失败: %w", err)
+		return err
 	}
 
+	precisions := make(map[string]int, len(exchangeInfo.Symbols))
+	pricePrecisions := make(map[string]int, len(exchangeInfo.Symbols))
+	filters := make(map[string]SymbolFilters, len(exchangeInfo.Symbols))
 	for _, s := range exchangeInfo.Symbols {
-		if s.Symbol == symbol {
-			// 从LOT_SIZE filter获取精度
-			for _, filter := range s.Filters {
-				if filter["filterType"] == "LOT_SIZE" {
-					stepSize := filter["stepSize"].(string)
-					precision := calculatePrecision(stepSize)
-					log.Printf("  %s 数量精度: %d (stepSize: %s)", symbol, precision, stepSize)
-					return precision, nil
+		var f SymbolFilters
+		// 从LOT_SIZE filter获取精度
+		for _, filter := range s.Filters {
+			switch filter["filterType"] {
+			case "LOT_SIZE":
+				if stepSize, ok := filter["stepSize"].(string); ok {
+					precisions[s.Symbol] = calculatePrecision(stepSize)
+				}
+				f.StepSize = parseFilterValue(filter["stepSize"])
+				f.MinQty = parseFilterValue(filter["minQty"])
+				if f.MaxQty == 0 {
+					f.MaxQty = parseFilterValue(filter["maxQty"])
+				}
+			case "MARKET_LOT_SIZE":
+				// Market orders are capped lower than limit orders
+				if maxQty := parseFilterValue(filter["maxQty"]); maxQty > 0 {
+					f.MaxQty = maxQty
 				}
+			case "PRICE_FILTER":
+				if tickSize, ok := filter["tickSize"].(string); ok {
+					pricePrecisions[s.Symbol] = calculatePrecision(tickSize)
+				}
+				f.TickSize = parseFilterValue(filter["tickSize"])
+			case "MIN_NOTIONAL":
+				f.MinNotional = parseFilterValue(filter["notional"])
 			}
 		}
+		filters[s.Symbol] = f
 	}
 
-	log.Printf("  ⚠ %s 未找到精度信息，使用默认精度3", symbol)
-	return 3, nil // 默认精度为3
+	t.metadataMutex.Lock()
+	t.symbolPrecision = precisions
+	t.pricePrecision = pricePrecisions
+	t.symbolFilters = filters
+	t.metadataTime = time.Now()
+	t.metadataMutex.Unlock()
+	binanceLog.Infof("  ✓ Exchange info cached: %d symbols", len(precisions))
+	return nil
+}
+
+// SymbolFilters returns the symbol's LOT_SIZE, MARKET_LOT_SIZE, PRICE_FILTER and MIN_NOTIONAL filters
+func (t *FuturesTrader) SymbolFilters(symbol string) (SymbolFilters, error) {
+	if _, err := t.GetSymbolPrecision(symbol); err != nil { // Loads (or reloads the expired) exchange info
+		return SymbolFilters{}, err
+	}
+	t.metadataMutex.RLock()
+	defer t.metadataMutex.RUnlock()
+	f, ok := t.symbolFilters[symbol]
+	if !ok {
+		return SymbolFilters{}, fmt.Errorf("%s is not a Binance futures symbol", symbol)
+	}
+	return f, nil
+}
+
+// loadLeverageBrackets caches the maximum initial leverage of every symbol
+func (t *FuturesTrader) loadLeverageBrackets() error {
+	brackets, err := t.client.NewGetLeverageBracketService().Do(context.Background())
+	if err != nil {
+		return err
+	}
+
+	maxLeverage := make(map[string]int, len(brackets))
+	for _, b := range brackets {
+		for _, bracket := range b.Brackets {
+			if bracket.InitialLeverage > maxLeverage[b.Symbol] {
+				maxLeverage[b.Symbol] = bracket.InitialLeverage
+			}
+		}
+	}
+
+	t.metadataMutex.Lock()
+	t.maxLeverage = maxLeverage
+	t.metadataMutex.Unlock()
+	binanceLog.Infof("  ✓ Leverage brackets cached: %d symbols", len(maxLeverage))
+	return nil
+}
+
+// maxLeverageFor returns the symbol's maximum leverage (0 if brackets were not loaded)
+func (t *FuturesTrader) maxLeverageFor(symbol string) int {
+	t.metadataMutex.RLock()
+	defer t.metadataMutex.RUnlock()
+	return t.maxLeverage[symbol]
+}
+
+// WarmUp loads exchange metadata and the balance/position caches before the first cycle
+func (t *FuturesTrader) WarmUp() error {
+	var errs []string
+	if err := t.loadExchangeInfo(); err != nil {
+		errs = append(errs, fmt.Sprintf("exchange info: %v", err))
+	}
+	if err := t.loadLeverageBrackets(); err != nil {
+		errs = append(errs, fmt.Sprintf("leverage brackets: %v", err))
+	}
+	if _, err := t.GetBalance(); err != nil {
+		errs = append(errs, fmt.Sprintf("balance: %v", err))
+	}
+	if _, err := t.GetPositions(); err != nil {
+		errs = append(errs, fmt.Sprintf("positions: %v", err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
 }
 
 // calculatePrecision 从stepSize计算精度
@@ -832,11 +1359,23 @@ func (t *FuturesTrader) FormatQuantity(symbol string, quantity float64) (string,
 	precision, err := t.GetSymbolPrecision(symbol)
 	if err != nil {
 		// 如果获取失败，使用默认格式
-		return fmt.Sprintf("%.3f", quantity), nil
+		return formatStepQuantity(quantity, 3), nil
 	}
 
-	format := fmt.Sprintf("%%.%df", precision)
-	return fmt.Sprintf(format, quantity), nil
+	return formatStepQuantity(quantity, precision), nil
+}
+
+// formatPrice formats a price to the symbol's tick precision (8 decimals if unknown)
+func (t *FuturesTrader) formatPrice(symbol string, price float64) string {
+	precision := 8
+	if _, err := t.GetSymbolPrecision(symbol); err == nil {
+		t.metadataMutex.RLock()
+		if p, ok := t.pricePrecision[symbol]; ok {
+			precision = p
+		}
+		t.metadataMutex.RUnlock()
+	}
+	return strconv.FormatFloat(price, 'f', precision, 64)
 }
 
 // 辅助函数
@@ -852,3 +1391,17 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+// binanceOrder converts a Binance order response
+func binanceOrder(order *futures.CreateOrderResponse) *Order {
+	price, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Price:         price,
+		ExecutedQty:   executedQty,
+	}
+}
@@ -0,0 +1,646 @@
+package trader
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"lia/logging"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bybitLog logs the Bybit adapter
+var bybitLog = logging.Component("bybit")
+
+const (
+	bybitBaseURL        = "https://api.bybit.com"
+	bybitTestnetBaseURL = "https://api-testnet.bybit.com"
+	bybitRecvWindow     = "5000"
+	bybitCategory       = "linear" // USDT perpetuals
+)
+
+// Bybit position indexes (hedge mode keeps one position per side)
+const (
+	bybitPositionOneWay    = 0
+	bybitPositionHedgeBuy  = 1
+	bybitPositionHedgeSell = 2
+)
+
+// Bybit return codes handled explicitly
+const (
+	bybitCodePositionIdx        = 10001  // Also returned when positionIdx does not match the position mode
+	bybitCodeLeverageNotChanged = 110043 // Leverage already set
+	bybitCodeNotModified        = 34040  // TP/SL already at this price
+)
+
+// BybitTrader Bybit USDT perpetual trader (REST API v5, unified trading account)
+type BybitTrader struct {
+	apiKey    string
+	secretKey string
+	baseURL   string
+	client    *http.Client
+
+	// Trader tag embedded in client order IDs (for execution audits)
+	clientOrderTag string
+
+	// Lot size filters by symbol
+	lotSizes map[string]bybitLotSize
+
+	// One-way mode detection (hedge mode is assumed until an order is rejected for its positionIdx)
+	isOneWayMode bool
+
+	mu sync.RWMutex
+}
+
+// bybitLotSize order quantity filter of a symbol (and its price tick and minimum order value)
+type bybitLotSize struct {
+	QtyStep        float64
+	MinOrderQty    float64
+	MaxMktOrderQty float64
+	MinNotional    float64
+	TickSize       float64
+	QtyPrecision   int
+}
+
+// bybitResponse envelope of every Bybit v5 response
+type bybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// bybitError a non-zero Bybit return code
+type bybitError struct {
+	Code int
+	Msg  string
+}
+
+func (e *bybitError) Error() string {
+	return fmt.Sprintf("Bybit error %d: %s", e.Code, e.Msg)
+}
+
+// isBybitCode reports whether err is a Bybit error with code
+func isBybitCode(err error, code int) bool {
+	var e *bybitError
+	return errors.As(err, &e) && e.Code == code
+}
+
+// NewBybitTrader creates a Bybit trader (testnet uses api-testnet.bybit.com)
+func NewBybitTrader(apiKey, secretKey string, testnet bool) *BybitTrader {
+	baseURL := bybitBaseURL
+	if testnet {
+		baseURL = bybitTestnetBaseURL
+	}
+	return &BybitTrader{
+		apiKey:    apiKey,
+		secretKey: secretKey,
+		baseURL:   baseURL,
+		lotSizes:  make(map[string]bybitLotSize),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 10 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+			},
+		},
+	}
+}
+
+// SetClientOrderTag sets the trader tag embedded in every client order ID
+func (t *BybitTrader) SetClientOrderTag(tag string) {
+	t.clientOrderTag = tag
+}
+
+// sign computes the X-BAPI-SIGN header: hex(HMAC-SHA256(timestamp + key + recvWindow + query or body))
+func (t *BybitTrader) sign(timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(t.secretKey))
+	mac.Write([]byte(timestamp + t.apiKey + bybitRecvWindow + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// request sends a signed request and returns the response result (GET requests are retried on network
+// errors; orders are not, so a timed-out order is never sent twice)
+func (t *BybitTrader) request(method, path string, query url.Values, payload interface{}) (json.RawMessage, error) {
+	const maxRetries = 3
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		result, err := t.doRequest(method, path, query, payload)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		retryable := strings.Contains(err.Error(), "timeout") ||
+			strings.Contains(err.Error(), "connection reset") ||
+			strings.Contains(err.Error(), "EOF")
+		if method != http.MethodGet || !retryable {
+			return nil, err
+		}
+		if attempt < maxRetries {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	return nil, fmt.Errorf("request failed after %d attempts: %w", maxRetries, lastErr)
+}
+
+// doRequest performs one signed request
+func (t *BybitTrader) doRequest(method, path string, query url.Values, payload interface{}) (json.RawMessage, error) {
+	fullURL := t.baseURL + path
+	signed := ""
+	if len(query) > 0 {
+		signed = query.Encode()
+		fullURL += "?" + signed
+	}
+
+	body := ""
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = string(raw)
+		signed = body
+	}
+
+	req, err := http.NewRequest(method, fullURL, bytes.NewBufferString(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-BAPI-API-KEY", t.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", bybitRecvWindow)
+	req.Header.Set("X-BAPI-SIGN", t.sign(timestamp, signed))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(raw))
+	}
+
+	var envelope bybitResponse
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if envelope.RetCode != 0 {
+		return nil, &bybitError{Code: envelope.RetCode, Msg: envelope.RetMsg}
+	}
+	return envelope.Result, nil
+}
+
+// lotSize returns (and caches) the symbol's quantity filter
+func (t *BybitTrader) lotSize(symbol string) (bybitLotSize, error) {
+	t.mu.RLock()
+	lot, ok := t.lotSizes[symbol]
+	t.mu.RUnlock()
+	if ok {
+		return lot, nil
+	}
+
+	query := url.Values{"category": {bybitCategory}, "symbol": {symbol}}
+	result, err := t.request(http.MethodGet, "/v5/market/instruments-info", query, nil)
+	if err != nil {
+		return bybitLotSize{}, fmt.Errorf("failed to get %s instrument info: %w", symbol, err)
+	}
+	var info struct {
+		List []struct {
+			LotSizeFilter struct {
+				QtyStep          string `json:"qtyStep"`
+				MinOrderQty      string `json:"minOrderQty"`
+				MaxMktOrderQty   string `json:"maxMktOrderQty"`
+				MinNotionalValue string `json:"minNotionalValue"`
+			} `json:"lotSizeFilter"`
+			PriceFilter struct {
+				TickSize string `json:"tickSize"`
+			} `json:"priceFilter"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(result, &info); err != nil {
+		return bybitLotSize{}, fmt.Errorf("failed to parse %s instrument info: %w", symbol, err)
+	}
+	if len(info.List) == 0 {
+		return bybitLotSize{}, fmt.Errorf("%s is not a Bybit USDT perpetual", symbol)
+	}
+
+	filter := info.List[0].LotSizeFilter
+	lot = bybitLotSize{
+		QtyStep:        parseFloatString(filter.QtyStep),
+		MinOrderQty:    parseFloatString(filter.MinOrderQty),
+		MaxMktOrderQty: parseFloatString(filter.MaxMktOrderQty),
+		MinNotional:    parseFloatString(filter.MinNotionalValue),
+		TickSize:       parseFloatString(info.List[0].PriceFilter.TickSize),
+		QtyPrecision:   calculatePrecision(filter.QtyStep),
+	}
+	t.mu.Lock()
+	t.lotSizes[symbol] = lot
+	t.mu.Unlock()
+	return lot, nil
+}
+
+// SymbolFilters returns the symbol's lot size, price and order value filters
+func (t *BybitTrader) SymbolFilters(symbol string) (SymbolFilters, error) {
+	lot, err := t.lotSize(symbol)
+	if err != nil {
+		return SymbolFilters{}, err
+	}
+	return SymbolFilters{
+		StepSize:    lot.QtyStep,
+		MinQty:      lot.MinOrderQty,
+		MaxQty:      lot.MaxMktOrderQty,
+		TickSize:    lot.TickSize,
+		MinNotional: lot.MinNotional,
+	}, nil
+}
+
+// RefreshSymbolRules reloads the symbol's quantity filter (after a precision rejection)
+func (t *BybitTrader) RefreshSymbolRules(symbol string) error {
+	t.mu.Lock()
+	delete(t.lotSizes, symbol)
+	t.mu.Unlock()
+	_, err := t.lotSize(symbol)
+	return err
+}
+
+// WarmUp loads the balance and positions before the first cycle
+func (t *BybitTrader) WarmUp() error {
+	if _, err := t.GetBalance(); err != nil {
+		return fmt.Errorf("balance: %w", err)
+	}
+	positions, err := t.GetPositions()
+	if err != nil {
+		return fmt.Errorf("positions: %w", err)
+	}
+	for _, pos := range positions {
+		if _, err := t.lotSize(pos.Symbol); err != nil {
+			return fmt.Errorf("instrument info: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetBalance gets the USDT balance of the unified trading account
+func (t *BybitTrader) GetBalance() (*Balance, error) {
+	query := url.Values{"accountType": {"UNIFIED"}, "coin": {"USDT"}}
+	result, err := t.request(http.MethodGet, "/v5/account/wallet-balance", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+	var wallet struct {
+		List []struct {
+			TotalAvailableBalance string `json:"totalAvailableBalance"`
+			Coin                  []struct {
+				Coin                string `json:"coin"`
+				WalletBalance       string `json:"walletBalance"`
+				UnrealisedPnl       string `json:"unrealisedPnl"`
+				AvailableToWithdraw string `json:"availableToWithdraw"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(result, &wallet); err != nil {
+		return nil, fmt.Errorf("failed to parse wallet balance: %w", err)
+	}
+
+	walletBalance, availableBalance, unrealizedProfit := 0.0, 0.0, 0.0
+	for _, account := range wallet.List {
+		for _, coin := range account.Coin {
+			if coin.Coin != "USDT" {
+				continue
+			}
+			walletBalance = parseFloatString(coin.WalletBalance)
+			unrealizedProfit = parseFloatString(coin.UnrealisedPnl)
+			availableBalance = parseFloatString(coin.AvailableToWithdraw)
+		}
+		// The account-wide available margin (USD) is what new orders can use
+		if account.TotalAvailableBalance != "" {
+			availableBalance = parseFloatString(account.TotalAvailableBalance)
+		}
+	}
+
+	return &Balance{
+		WalletBalance:    walletBalance,
+		AvailableBalance: availableBalance,
+		UnrealizedProfit: unrealizedProfit,
+	}, nil
+}
+
+// GetPositions gets all open USDT perpetual positions
+func (t *BybitTrader) GetPositions() ([]Position, error) {
+	query := url.Values{"category": {bybitCategory}, "settleCoin": {"USDT"}, "limit": {"200"}}
+	raw, err := t.request(http.MethodGet, "/v5/position/list", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	var list struct {
+		List []struct {
+			Symbol        string `json:"symbol"`
+			Side          string `json:"side"` // "Buy", "Sell" or "" (empty one-way position)
+			Size          string `json:"size"`
+			AvgPrice      string `json:"avgPrice"`
+			MarkPrice     string `json:"markPrice"`
+			UnrealisedPnl string `json:"unrealisedPnl"`
+			Leverage      string `json:"leverage"`
+			LiqPrice      string `json:"liqPrice"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse positions: %w", err)
+	}
+
+	result := []Position{}
+	for _, pos := range list.List {
+		size := parseFloatString(pos.Size)
+		if size == 0 {
+			continue
+		}
+		side := "long"
+		if pos.Side == "Sell" {
+			side = "short"
+		}
+
+		result = append(result, Position{
+			Symbol:           pos.Symbol,
+			Side:             side,
+			Quantity:         size,
+			EntryPrice:       parseFloatString(pos.AvgPrice),
+			MarkPrice:        parseFloatString(pos.MarkPrice),
+			UnrealizedProfit: parseFloatString(pos.UnrealisedPnl),
+			Leverage:         int(parseFloatString(pos.Leverage)),
+			LiquidationPrice: parseFloatString(pos.LiqPrice),
+		})
+	}
+	return result, nil
+}
+
+// positionIdx the positionIdx of orders on positionSide ("LONG"/"SHORT") in the current position mode
+func (t *BybitTrader) positionIdx(positionSide string) int {
+	t.mu.RLock()
+	oneWay := t.isOneWayMode
+	t.mu.RUnlock()
+	if oneWay {
+		return bybitPositionOneWay
+	}
+	if positionSide == "SHORT" {
+		return bybitPositionHedgeSell
+	}
+	return bybitPositionHedgeBuy
+}
+
+// withPositionIdx sends a request for positionSide, switching to one-way mode and retrying once when the
+// account rejects the hedge mode positionIdx
+func (t *BybitTrader) withPositionIdx(path, positionSide string, payload map[string]interface{}) (json.RawMessage, error) {
+	payload["positionIdx"] = t.positionIdx(positionSide)
+	result, err := t.request(http.MethodPost, path, nil, payload)
+	if err == nil || !isBybitCode(err, bybitCodePositionIdx) || payload["positionIdx"] == bybitPositionOneWay {
+		return result, err
+	}
+
+	bybitLog.Warnf("  ⚠ Detected Bybit one-way position mode, retrying with positionIdx 0...")
+	t.mu.Lock()
+	t.isOneWayMode = true
+	t.mu.Unlock()
+	payload["positionIdx"] = bybitPositionOneWay
+	return t.request(http.MethodPost, path, nil, payload)
+}
+
+// placeMarketOrder places a market order
+func (t *BybitTrader) placeMarketOrder(symbol, side, positionSide string, quantity float64, kind string, reduceOnly bool) (*Order, error) {
+	qty, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+	order := map[string]interface{}{
+		"category":    bybitCategory,
+		"symbol":      symbol,
+		"side":        side,
+		"orderType":   "Market",
+		"qty":         qty,
+		"orderLinkId": newClientOrderID(t.clientOrderTag, kind),
+	}
+	if reduceOnly {
+		order["reduceOnly"] = true
+	}
+
+	result, err := t.withPositionIdx("/v5/order/create", positionSide, order)
+	if err != nil {
+		return nil, err
+	}
+	var placed struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := json.Unmarshal(result, &placed); err != nil {
+		return nil, fmt.Errorf("failed to parse order response: %w", err)
+	}
+	// Bybit order IDs are UUIDs, so the order is identified by its client order ID
+	bybitLog.Infof("  ✓ Bybit order %s placed (%s)", placed.OrderID, placed.OrderLinkID)
+	return &Order{
+		ClientOrderID: placed.OrderLinkID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+	}, nil
+}
+
+// OpenLong opens a long position
+func (t *BybitTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	// Cancel leftover orders first so stale orders cannot act on the new position
+	if err := t.CancelAllOrders(symbol); err != nil {
+		bybitLog.Warnf("  ⚠ Failed to cancel old orders (continuing): %v", err)
+	}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+
+	result, err := t.placeMarketOrder(symbol, "Buy", "LONG", quantity, ClientOrderKindOpen, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open long position: %w", err)
+	}
+	bybitLog.Infof("✓ Long position opened: %s quantity: %.8f", symbol, quantity)
+	return result, nil
+}
+
+// OpenShort opens a short position
+func (t *BybitTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	// Cancel leftover orders first so stale orders cannot act on the new position
+	if err := t.CancelAllOrders(symbol); err != nil {
+		bybitLog.Warnf("  ⚠ Failed to cancel old orders (continuing): %v", err)
+	}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+
+	result, err := t.placeMarketOrder(symbol, "Sell", "SHORT", quantity, ClientOrderKindOpen, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open short position: %w", err)
+	}
+	bybitLog.Infof("✓ Short position opened: %s quantity: %.8f", symbol, quantity)
+	return result, nil
+}
+
+// positionQuantity current quantity of the symbol's position on side ("long"/"short")
+func (t *BybitTrader) positionQuantity(symbol, side string) (float64, error) {
+	positions, err := t.GetPositions()
+	if err != nil {
+		return 0, err
+	}
+	if pos, ok := findPosition(positions, symbol, side); ok {
+		return pos.Quantity, nil
+	}
+	return 0, fmt.Errorf("no %s position found for %s", side, symbol)
+}
+
+// CloseLong closes a long position (quantity=0 closes all of it)
+func (t *BybitTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
+	if quantity == 0 {
+		var err error
+		if quantity, err = t.positionQuantity(symbol, "long"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := t.placeMarketOrder(symbol, "Sell", "LONG", quantity, ClientOrderKindClose, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close long position: %w", err)
+	}
+	bybitLog.Infof("✓ Long position closed: %s quantity: %.8f", symbol, quantity)
+
+	if err := t.CancelAllOrders(symbol); err != nil {
+		bybitLog.Warnf("  ⚠ Failed to cancel orders: %v", err)
+	}
+	return result, nil
+}
+
+// CloseShort closes a short position (quantity=0 closes all of it)
+func (t *BybitTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
+	if quantity == 0 {
+		var err error
+		if quantity, err = t.positionQuantity(symbol, "short"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := t.placeMarketOrder(symbol, "Buy", "SHORT", quantity, ClientOrderKindClose, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close short position: %w", err)
+	}
+	bybitLog.Infof("✓ Short position closed: %s quantity: %.8f", symbol, quantity)
+
+	if err := t.CancelAllOrders(symbol); err != nil {
+		bybitLog.Warnf("  ⚠ Failed to cancel orders: %v", err)
+	}
+	return result, nil
+}
+
+// SetLeverage sets the symbol's leverage for both sides
+func (t *BybitTrader) SetLeverage(symbol string, leverage int) error {
+	lever := strconv.Itoa(leverage)
+	_, err := t.request(http.MethodPost, "/v5/position/set-leverage", nil, map[string]interface{}{
+		"category":     bybitCategory,
+		"symbol":       symbol,
+		"buyLeverage":  lever,
+		"sellLeverage": lever,
+	})
+	if err != nil && !isBybitCode(err, bybitCodeLeverageNotChanged) {
+		return fmt.Errorf("failed to set leverage: %w", err)
+	}
+	return nil
+}
+
+// GetMarketPrice gets the last traded price
+func (t *BybitTrader) GetMarketPrice(symbol string) (float64, error) {
+	query := url.Values{"category": {bybitCategory}, "symbol": {symbol}}
+	result, err := t.request(http.MethodGet, "/v5/market/tickers", query, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get price: %w", err)
+	}
+	var tickers struct {
+		List []struct {
+			LastPrice string `json:"lastPrice"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(result, &tickers); err != nil || len(tickers.List) == 0 {
+		return 0, fmt.Errorf("failed to parse %s ticker: %v", symbol, err)
+	}
+	return parseFloatString(tickers.List[0].LastPrice), nil
+}
+
+// setTradingStop sets the position's full-size stop loss or take profit (field: "stopLoss" or "takeProfit"),
+// filled at market when the last price reaches it
+func (t *BybitTrader) setTradingStop(symbol, positionSide, field string, price float64) error {
+	payload := map[string]interface{}{
+		"category":    bybitCategory,
+		"symbol":      symbol,
+		"tpslMode":    "Full",
+		field:         strconv.FormatFloat(price, 'f', -1, 64),
+		"slTriggerBy": "LastPrice",
+		"tpTriggerBy": "LastPrice",
+	}
+	_, err := t.withPositionIdx("/v5/position/trading-stop", positionSide, payload)
+	if err != nil && !isBybitCode(err, bybitCodeNotModified) {
+		return err
+	}
+	return nil
+}
+
+// SetStopLoss sets the position's stop loss (Bybit attaches it to the whole position, so quantity is unused)
+func (t *BybitTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	if err := t.setTradingStop(symbol, positionSide, "stopLoss", stopPrice); err != nil {
+		// Reported so the open saga can retry (and close the position if the stop cannot be placed)
+		return fmt.Errorf("failed to set stop loss: %w", err)
+	}
+	bybitLog.Infof("  ✓ Stop loss set: %.4f", stopPrice)
+	return nil
+}
+
+// SetTakeProfit sets the position's take profit (Bybit attaches it to the whole position, so quantity is unused)
+func (t *BybitTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	if err := t.setTradingStop(symbol, positionSide, "takeProfit", takeProfitPrice); err != nil {
+		return fmt.Errorf("failed to set take profit: %w", err)
+	}
+	bybitLog.Infof("  ✓ Take profit set: %.4f", takeProfitPrice)
+	return nil
+}
+
+// CancelAllOrders cancels the symbol's open and conditional orders
+func (t *BybitTrader) CancelAllOrders(symbol string) error {
+	_, err := t.request(http.MethodPost, "/v5/order/cancel-all", nil, map[string]interface{}{
+		"category": bybitCategory,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel orders: %w", err)
+	}
+	return nil
+}
+
+// FormatQuantity rounds a quantity down to the symbol's qtyStep
+func (t *BybitTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	lot, err := t.lotSize(symbol)
+	if err != nil {
+		return "", err
+	}
+	qty := dec(quantity)
+	if lot.QtyStep > 0 {
+		qty = qty.Div(dec(lot.QtyStep)).Floor().Mul(dec(lot.QtyStep))
+	}
+	if !qty.IsPositive() || qty.LessThan(dec(lot.MinOrderQty)) {
+		return "", fmt.Errorf("%s quantity %.8f is below the minimum order quantity %g", symbol, quantity, lot.MinOrderQty)
+	}
+	return qty.StringFixed(int32(lot.QtyPrecision)), nil
+}
@@ -0,0 +1,174 @@
+package trader
+
+import (
+	"fmt"
+	"lia/config"
+	decisionPkg "lia/decision"
+)
+
+// Confidence calibration: the prompt asks the AI for a confidence score on every open, but whether a given
+// score means anything differs per trader and model. Closed trades scored by DecisionQuality carry the
+// confidence they were opened with, so the threshold can be set to the lowest confidence at which this
+// trader's trades have actually made money after fees.
+
+const confidenceBucketWidth = 5 // Calibration table resolution (confidence points)
+
+// confidenceSample a closed trade's opening confidence and its P&L after estimated fees
+type confidenceSample struct {
+	confidence int
+	netPnL     float64
+}
+
+// ConfidenceThreshold computes the trader's confidence threshold from its most recent scored trades
+func (q *DecisionQuality) ConfidenceThreshold(cfg config.AdaptiveConfidenceConfig) *decisionPkg.ConfidenceThreshold {
+	q.mu.Lock()
+	var samples []confidenceSample
+	for i := len(q.scores) - 1; i >= 0 && len(samples) < cfg.LookbackTrades; i-- {
+		s := q.scores[i]
+		if s.Confidence <= 0 {
+			continue
+		}
+		samples = append(samples, confidenceSample{
+			confidence: s.Confidence,
+			netPnL:     s.PnL - s.Notional*cfg.FeePct/100,
+		})
+	}
+	q.mu.Unlock()
+
+	return calibrateConfidence(samples, cfg)
+}
+
+// calibrateConfidence picks the lowest opening confidence in [MinThreshold, MaxThreshold] whose trades at or
+// above it averaged a profit after fees over at least MinTrades trades, and whose own band (the next
+// confidenceBucketWidth points) did not lose money, so losing low-confidence trades are not carried by the
+// winners above them. Too little data = DefaultThreshold, no profitable level = MaxThreshold
+func calibrateConfidence(samples []confidenceSample, cfg config.AdaptiveConfidenceConfig) *decisionPkg.ConfidenceThreshold {
+	result := &decisionPkg.ConfidenceThreshold{
+		Threshold: cfg.DefaultThreshold,
+		Trades:    len(samples),
+		Buckets:   confidenceBuckets(samples),
+	}
+
+	if above, _, _ := confidenceAbove(samples, cfg.MinThreshold); above < cfg.MinTrades {
+		result.Reason = fmt.Sprintf("%d calibrated trades at confidence ≥%d, %d needed - using the default", above, cfg.MinThreshold, cfg.MinTrades)
+		return result
+	}
+
+	result.Adaptive = true
+	for threshold := cfg.MinThreshold; threshold <= cfg.MaxThreshold; threshold++ {
+		count, avgNet, winRate := confidenceAbove(samples, threshold)
+		if count < cfg.MinTrades {
+			break // Higher thresholds only have fewer trades
+		}
+		if !confidenceUsed(samples, threshold) {
+			continue // Same trades as the next used level: report the level actually traded
+		}
+		if avgNet > 0 && confidenceBandNet(samples, threshold) >= 0 {
+			result.Threshold = threshold
+			result.AboveTrades = count
+			result.AboveAvgNetPnL = avgNet
+			result.Reason = fmt.Sprintf("trades at confidence ≥%d averaged %+.2f USDT after fees (%d trades, %.0f%% win)", threshold, avgNet, count, winRate)
+			return result
+		}
+	}
+
+	result.Threshold = cfg.MaxThreshold
+	result.AboveTrades, result.AboveAvgNetPnL, _ = confidenceAbove(samples, cfg.MaxThreshold)
+	result.Reason = fmt.Sprintf("no confidence level with %d+ trades was profitable after fees - only the strongest setups", cfg.MinTrades)
+	return result
+}
+
+// confidenceAbove count, average net P&L and win rate (%) of samples opened at or above threshold
+func confidenceAbove(samples []confidenceSample, threshold int) (int, float64, float64) {
+	count, wins := 0, 0
+	total := 0.0
+	for _, s := range samples {
+		if s.confidence < threshold {
+			continue
+		}
+		count++
+		total += s.netPnL
+		if s.netPnL > 0 {
+			wins++
+		}
+	}
+	if count == 0 {
+		return 0, 0, 0
+	}
+	return count, total / float64(count), float64(wins) / float64(count) * 100
+}
+
+// confidenceUsed whether any sample was opened at exactly this confidence
+func confidenceUsed(samples []confidenceSample, confidence int) bool {
+	for _, s := range samples {
+		if s.confidence == confidence {
+			return true
+		}
+	}
+	return false
+}
+
+// confidenceBandNet average net P&L of samples opened within confidenceBucketWidth points from min
+func confidenceBandNet(samples []confidenceSample, min int) float64 {
+	count := 0
+	total := 0.0
+	for _, s := range samples {
+		if s.confidence >= min && s.confidence < min+confidenceBucketWidth {
+			count++
+			total += s.netPnL
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// confidenceBuckets groups samples into bands of confidenceBucketWidth points, lowest band first
+func confidenceBuckets(samples []confidenceSample) []decisionPkg.ConfidenceBucket {
+	byMin := make(map[int]*decisionPkg.ConfidenceBucket)
+	lowest, highest := 101, -1
+	for _, s := range samples {
+		min := s.confidence / confidenceBucketWidth * confidenceBucketWidth
+		b := byMin[min]
+		if b == nil {
+			max := min + confidenceBucketWidth - 1
+			if max > 100 {
+				max = 100
+			}
+			b = &decisionPkg.ConfidenceBucket{Min: min, Max: max}
+			byMin[min] = b
+		}
+		b.Trades++
+		b.AvgNetPnL += s.netPnL
+		if s.netPnL > 0 {
+			b.WinRate++
+		}
+		if min < lowest {
+			lowest = min
+		}
+		if min > highest {
+			highest = min
+		}
+	}
+
+	var buckets []decisionPkg.ConfidenceBucket
+	for min := lowest; min <= highest; min += confidenceBucketWidth {
+		b := byMin[min]
+		if b == nil {
+			continue
+		}
+		b.WinRate = b.WinRate / float64(b.Trades) * 100
+		b.AvgNetPnL /= float64(b.Trades)
+		buckets = append(buckets, *b)
+	}
+	return buckets
+}
+
+// confidenceThreshold the trader's current confidence threshold (nil = adaptive confidence disabled)
+func (at *AutoTrader) confidenceThreshold() *decisionPkg.ConfidenceThreshold {
+	if !at.config.AdaptiveConfidence.Enabled {
+		return nil
+	}
+	return at.decisionQuality.ConfidenceThreshold(at.config.AdaptiveConfidence)
+}
@@ -0,0 +1,87 @@
+package trader
+
+import (
+	"fmt"
+	"lia/config"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/market"
+	"math"
+)
+
+// copySourceFresh whether a source's latest record should be copied: not copied by this follower before, and
+// within copy_trading.max_age_minutes. Marks it copied
+func (at *AutoTrader) copySourceFresh(sourceID string, record *logger.DecisionRecord) bool {
+	if at.copiedCycles == nil {
+		at.copiedCycles = make(map[string]int)
+	}
+	if at.copiedCycles[sourceID] == record.CycleNumber {
+		at.log.Infof("📋 [Copy Trading] %s cycle #%d already copied, waiting for its next decision", sourceID, record.CycleNumber)
+		return false
+	}
+	if ct := at.config.CopyTrading; ct != nil && ct.MaxAgeMinutes > 0 {
+		age := at.now().Sub(record.Timestamp)
+		if age.Minutes() > ct.MaxAgeMinutes {
+			at.log.Infof("📋 [Copy Trading] %s cycle #%d is %.0f minutes old (max %.0f), not copied",
+				sourceID, record.CycleNumber, age.Minutes(), ct.MaxAgeMinutes)
+			return false
+		}
+	}
+	at.copiedCycles[sourceID] = record.CycleNumber
+	return true
+}
+
+// addCopyFills records the source's fill price of each executed action (key: symbol_action; the first source
+// wins, like the copied decisions)
+func addCopyFills(fills map[string]float64, record *logger.DecisionRecord) {
+	for _, action := range record.Decisions {
+		key := action.Symbol + "_" + action.Action
+		if _, exists := fills[key]; !exists && action.Success && action.Price > 0 {
+			fills[key] = action.Price
+		}
+	}
+}
+
+// copyPriceMoved the reason to skip a copied open/add whose price moved more than copy_trading.max_price_move_pct
+// from the source's fill ("" = within the band, or nothing to compare)
+func (at *AutoTrader) copyPriceMoved(d decisionPkg.Decision, fills map[string]float64, marketData map[string]*market.Data) string {
+	ct := at.config.CopyTrading
+	if ct == nil || ct.MaxPriceMovePct <= 0 || !(d.Action == "open_long" || d.Action == "open_short" || decisionPkg.IsAddAction(d.Action)) {
+		return ""
+	}
+	fill, ok := fills[d.Symbol+"_"+d.Action]
+	if !ok {
+		return "" // The source did not execute it (or the record predates fill prices)
+	}
+
+	price := 0.0
+	if data, ok := marketData[d.Symbol]; ok && data != nil {
+		price = data.CurrentPrice
+	} else if data, err := market.Get(d.Symbol); err == nil {
+		price = data.CurrentPrice
+	}
+	if price <= 0 {
+		return fmt.Sprintf("no current %s price to compare with the source's fill", d.Symbol)
+	}
+	movePct := math.Abs(price-fill) / fill * 100
+	if movePct > ct.MaxPriceMovePct {
+		return fmt.Sprintf("price moved %.2f%% since the source's fill at %.4f (max %.2f%%)", movePct, fill, ct.MaxPriceMovePct)
+	}
+	return ""
+}
+
+// copySizeRatio the multiplier from a source's position sizes to the follower's: follower/source equity in
+// "equity" mode (1 in "fixed" mode), times copy_trading.ratio
+func (at *AutoTrader) copySizeRatio(currentEquity, sourceEquity float64) float64 {
+	ratio := 1.0
+	if sourceEquity > 0 {
+		ratio = currentEquity / sourceEquity
+	}
+	if ct := at.config.CopyTrading; ct != nil {
+		if ct.SizeMode == config.CopySizeFixed {
+			ratio = 1.0
+		}
+		ratio *= ct.Ratio
+	}
+	return ratio
+}
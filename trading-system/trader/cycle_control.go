@@ -0,0 +1,101 @@
+package trader
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Errors returned by the operator cycle controls
+var (
+	ErrTraderNotRunning = errors.New("trader is not running")
+	ErrCycleQueued      = errors.New("a manual cycle is already queued")
+)
+
+// cycleControl operator controls of the decision loop: pausing scheduled cycles and requesting one now
+type cycleControl struct {
+	mu       sync.Mutex
+	paused   bool
+	pausedAt time.Time
+
+	runNow chan struct{} // Manual cycle requests (at most one queued)
+}
+
+// newCycleControl creates the controls (not paused)
+func newCycleControl() *cycleControl {
+	return &cycleControl{runNow: make(chan struct{}, 1)}
+}
+
+// Pause skips scheduled decision cycles until Resume (the position monitor keeps protecting open positions).
+// Returns false if the trader was already paused
+func (at *AutoTrader) Pause() bool {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+
+	if at.control.paused {
+		return false
+	}
+	at.control.paused = true
+	at.control.pausedAt = time.Now()
+	at.log.Infof("⏸ Paused by operator (scheduled cycles skipped until resumed)")
+	return true
+}
+
+// Resume re-enables scheduled decision cycles. Returns false if the trader was not paused
+func (at *AutoTrader) Resume() bool {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+
+	if !at.control.paused {
+		return false
+	}
+	at.control.paused = false
+	at.log.Infof("▶️  Resumed by operator (paused for %v)", time.Since(at.control.pausedAt).Round(time.Second))
+	at.control.pausedAt = time.Time{}
+	return true
+}
+
+// dropQueuedCycles drops the queued manual cycle and signals, so none runs after an emergency flatten (dropped
+// signals are reported to their callers as dropped)
+func (at *AutoTrader) dropQueuedCycles() {
+	select {
+	case <-at.control.runNow:
+		at.log.Infof("⏸ Queued manual cycle dropped")
+	default:
+	}
+	if at.signals == nil {
+		return
+	}
+	for {
+		select {
+		case signal := <-at.signals.queue:
+			at.log.Infof("⏸ Queued %s %s dropped", signal.Source, signal.ID)
+			close(signal.done)
+		default:
+			return
+		}
+	}
+}
+
+// IsPaused whether scheduled cycles are paused, and since when
+func (at *AutoTrader) IsPaused() (bool, time.Time) {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+	return at.control.paused, at.control.pausedAt
+}
+
+// RunCycleNow queues a decision cycle to run as soon as the current one (if any) finishes. It runs even while
+// the trader is paused (its opens and adds are rejected then), and does not move the schedule of the following
+// cycles
+func (at *AutoTrader) RunCycleNow() error {
+	if !at.isRunning {
+		return ErrTraderNotRunning
+	}
+	select {
+	case at.control.runNow <- struct{}{}:
+		at.log.Infof("⏩ Manual cycle requested by operator")
+		return nil
+	default:
+		return ErrCycleQueued
+	}
+}
@@ -0,0 +1,120 @@
+package trader
+
+import (
+	"lia/config"
+	"sync"
+	"time"
+)
+
+// Cycle modes reported in GetStatus
+const (
+	CycleModeInterval    = "interval"     // Free-running ticker every ScanInterval
+	CycleModeCandleClose = "candle_close" // Offset after candle closes of the configured timeframe
+)
+
+// cycleSchedule decides when decision cycles fire
+type cycleSchedule struct {
+	interval  time.Duration
+	alignment *config.CycleAlignmentConfig // nil = interval mode
+
+	mu     sync.Mutex
+	origin time.Time    // Interval mode: when the ticker started
+	next   time.Time    // Aligned mode: next planned cycle
+	ticker *time.Ticker // Interval mode ticker (nil = not started)
+}
+
+// newCycleSchedule creates the schedule (alignment nil or with an unsupported timeframe = interval mode)
+func newCycleSchedule(interval time.Duration, alignment *config.CycleAlignmentConfig) *cycleSchedule {
+	if alignment != nil && alignment.TimeframeDuration() == 0 {
+		alignment = nil
+	}
+	return &cycleSchedule{interval: interval, alignment: alignment}
+}
+
+// aligned whether cycles follow candle closes
+func (s *cycleSchedule) aligned() bool {
+	return s.alignment != nil
+}
+
+// nextAligned the first candle close (plus offset) at least one interval after lastStart. Half a candle of
+// slack keeps a cycle that itself started offset seconds after a close from skipping the next close
+func (s *cycleSchedule) nextAligned(lastStart, now time.Time) time.Time {
+	timeframe := s.alignment.TimeframeDuration()
+	offset := time.Duration(s.alignment.OffsetSeconds) * time.Second
+
+	earliest := lastStart.Add(s.getInterval() - timeframe/2)
+	if earliest.Before(now) {
+		earliest = now
+	}
+	// Binance candles close on multiples of the timeframe since the Unix epoch (UTC)
+	next := earliest.Add(-offset).Truncate(timeframe).Add(offset)
+	if next.Before(earliest) {
+		next = next.Add(timeframe)
+	}
+	return next
+}
+
+// wait returns a channel that fires at the next aligned trigger after lastStart and records it
+func (s *cycleSchedule) wait(lastStart time.Time) <-chan time.Time {
+	next := s.nextAligned(lastStart, time.Now())
+	s.mu.Lock()
+	s.next = next
+	s.mu.Unlock()
+	return time.After(time.Until(next))
+}
+
+// startTicker starts the interval-mode ticker
+func (s *cycleSchedule) startTicker() *time.Ticker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.origin = time.Now()
+	s.ticker = time.NewTicker(s.interval)
+	return s.ticker
+}
+
+// getInterval the minimum time between cycles
+func (s *cycleSchedule) getInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval
+}
+
+// setInterval changes the interval; a running interval-mode ticker restarts from now
+func (s *cycleSchedule) setInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = interval
+	if s.ticker != nil {
+		s.origin = time.Now()
+		s.ticker.Reset(interval)
+	}
+}
+
+// nextCycle the next planned cycle (zero = not started)
+func (s *cycleSchedule) nextCycle() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aligned() || s.origin.IsZero() {
+		return s.next
+	}
+	ticks := time.Since(s.origin)/s.interval + 1
+	return s.origin.Add(ticks * s.interval)
+}
+
+// Status trigger mode, alignment and next planned cycle, as reported in GetStatus
+func (s *cycleSchedule) Status() map[string]interface{} {
+	next := s.nextCycle()
+	status := map[string]interface{}{
+		"mode":     CycleModeInterval,
+		"interval": s.getInterval().String(),
+	}
+	if s.aligned() {
+		status["mode"] = CycleModeCandleClose
+		status["timeframe"] = s.alignment.Timeframe
+		status["offset_seconds"] = s.alignment.OffsetSeconds
+	}
+	if !next.IsZero() {
+		status["next_cycle_at"] = next.Format(time.RFC3339)
+	}
+	return status
+}
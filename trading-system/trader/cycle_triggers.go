@@ -0,0 +1,272 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"lia/config"
+	"lia/market"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// triggerBTCSymbol the market whose moves trigger cycles
+const triggerBTCSymbol = "BTCUSDT"
+
+// cycleTriggers checks market and position events between scheduled cycles and queues an early cycle when one
+// starts to hold. Conditions are edge-triggered: a BTC move or P&L level that stays past its threshold fires once
+type cycleTriggers struct {
+	cfg *config.CycleTriggersConfig
+
+	queue   chan []string // Reasons of the pending triggered cycle (at most one queued)
+	current []string      // Reasons of the triggered cycle in progress (touched by the run loop only)
+
+	mu        sync.Mutex
+	btcMoved  bool               // BTC was past btc_move_pct at the last check
+	pnlLevels map[string]int     // Position key → -1 / 0 / +1 (below -X, between, above +X) at the last check
+	funding   map[string]float64 // Held symbol → funding rate at the last check
+	lastFired time.Time
+}
+
+// newCycleTriggers creates the trigger engine (nil config = schedule only)
+func newCycleTriggers(cfg *config.CycleTriggersConfig) *cycleTriggers {
+	if cfg == nil {
+		return nil
+	}
+	return &cycleTriggers{
+		cfg:       cfg,
+		queue:     make(chan []string, 1),
+		pnlLevels: make(map[string]int),
+		funding:   make(map[string]float64),
+	}
+}
+
+// runCycleTriggers checks the triggers every check_interval_seconds until ctx ends
+func (at *AutoTrader) runCycleTriggers(ctx context.Context) {
+	cfg := at.triggers.cfg
+	at.log.Infof("⚡ Cycle triggers every %ds (BTC move %s in %dm, position P&L %s, funding flip: %v, min gap %ds)",
+		cfg.CheckIntervalSeconds, describeTriggerPct(cfg.BTCMovePct), cfg.BTCWindowMinutes,
+		describeTriggerPct(cfg.PositionPnLPct), cfg.FundingFlip, cfg.MinGapSeconds)
+
+	ticker := time.NewTicker(time.Duration(cfg.CheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			at.checkCycleTriggers()
+		case <-ctx.Done():
+			at.log.Infof("🛑 Cycle triggers stopped")
+			return
+		}
+	}
+}
+
+// checkCycleTriggers evaluates the triggers once and queues a cycle if any fired and the debounce allows it.
+// A trigger held back by the debounce is dropped, not postponed (the following cycle sees the new state anyway)
+func (at *AutoTrader) checkCycleTriggers() {
+	reasons := at.evaluateTriggers()
+	if len(reasons) == 0 {
+		return
+	}
+	if paused, _ := at.IsPaused(); paused {
+		return
+	}
+
+	gap := time.Duration(at.triggers.cfg.MinGapSeconds) * time.Second
+	if since := time.Since(at.cycles.lastStarted()); since < gap {
+		at.log.Infof("⚡ Trigger debounced (last cycle started %v ago): %s", since.Round(time.Second), strings.Join(reasons, "; "))
+		return
+	}
+	if done, _, _ := at.cycles.running(); done != nil {
+		at.log.Infof("⚡ Trigger dropped (a cycle is running): %s", strings.Join(reasons, "; "))
+		return
+	}
+
+	at.triggers.mu.Lock()
+	if since := time.Since(at.triggers.lastFired); since < gap {
+		at.triggers.mu.Unlock()
+		return
+	}
+	at.triggers.lastFired = time.Now()
+	at.triggers.mu.Unlock()
+
+	select {
+	case at.triggers.queue <- reasons:
+		at.log.Infof("⚡ Cycle triggered: %s", strings.Join(reasons, "; "))
+	default:
+		// A triggered cycle is already queued
+	}
+}
+
+// evaluateTriggers the conditions that started to hold since the last check
+func (at *AutoTrader) evaluateTriggers() []string {
+	cfg := at.triggers.cfg
+	var reasons []string
+
+	if cfg.BTCMovePct > 0 {
+		if reason, err := at.triggers.checkBTCMove(); err != nil {
+			at.log.Warnf("⚠️  Cycle trigger: BTC move check failed: %v", err)
+		} else if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	if cfg.PositionPnLPct > 0 || cfg.FundingFlip {
+		positions, err := at.trader.GetPositions()
+		if err != nil {
+			at.log.Warnf("⚠️  Cycle trigger: failed to get positions: %v", err)
+			return reasons
+		}
+		if cfg.PositionPnLPct > 0 {
+			reasons = append(reasons, at.triggers.checkPnLLevels(positions)...)
+		}
+		if cfg.FundingFlip {
+			reasons = append(reasons, at.triggers.checkFundingFlips(positions)...)
+		}
+	}
+	return reasons
+}
+
+// checkBTCMove fires when BTC's change over the window first reaches btc_move_pct
+func (ct *cycleTriggers) checkBTCMove() (string, error) {
+	window := ct.cfg.BTCWindowMinutes
+	klines, err := market.GetKlines(triggerBTCSymbol, "1m", window+1)
+	if err != nil {
+		return "", err
+	}
+	if len(klines) < window+1 || klines[0].Close <= 0 {
+		return "", fmt.Errorf("only %d 1m candles", len(klines))
+	}
+	last := klines[len(klines)-1].Close
+	move := (last - klines[0].Close) / klines[0].Close * 100
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	moved := math.Abs(move) >= ct.cfg.BTCMovePct
+	fired := moved && !ct.btcMoved
+	ct.btcMoved = moved
+	if !fired {
+		return "", nil
+	}
+	return fmt.Sprintf("BTC moved %+.2f%% in %dm (%.2f)", move, window, last), nil
+}
+
+// checkPnLLevels fires when a position's leveraged P&L crosses +position_pnl_pct or -position_pnl_pct. New
+// positions start at their current level, so opening one fires nothing
+func (ct *cycleTriggers) checkPnLLevels(positions []Position) []string {
+	threshold := ct.cfg.PositionPnLPct
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	var reasons []string
+	seen := make(map[string]bool, len(positions))
+	for _, pos := range positions {
+		key := pos.Symbol + "_" + pos.Side
+		seen[key] = true
+		leverage := float64(pos.Leverage)
+		if leverage <= 0 {
+			leverage = 1
+		}
+		pnlPct := leveragedPnLPct(pos.Side, pos.EntryPrice, pos.MarkPrice, leverage)
+		level := 0
+		if pnlPct >= threshold {
+			level = 1
+		} else if pnlPct <= -threshold {
+			level = -1
+		}
+
+		previous, known := ct.pnlLevels[key]
+		ct.pnlLevels[key] = level
+		if known && level != 0 && level != previous {
+			reasons = append(reasons, fmt.Sprintf("%s %s P&L crossed %+.1f%% (now %+.2f%%)", pos.Symbol, pos.Side, float64(level)*threshold, pnlPct))
+		}
+	}
+	for key := range ct.pnlLevels {
+		if !seen[key] {
+			delete(ct.pnlLevels, key)
+		}
+	}
+	return reasons
+}
+
+// checkFundingFlips fires when the funding rate of a held symbol changes sign
+func (ct *cycleTriggers) checkFundingFlips(positions []Position) []string {
+	symbols := make(map[string]bool, len(positions))
+	for _, pos := range positions {
+		symbols[pos.Symbol] = true
+	}
+
+	rates := make(map[string]float64, len(symbols))
+	for symbol := range symbols {
+		data, err := market.Get(symbol)
+		if err != nil {
+			continue
+		}
+		rates[symbol] = data.FundingRate
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	var reasons []string
+	for symbol, rate := range rates {
+		previous, known := ct.funding[symbol]
+		if known && (previous > 0 && rate < 0 || previous < 0 && rate > 0) {
+			reasons = append(reasons, fmt.Sprintf("%s funding flipped %+.4f%% → %+.4f%%", symbol, previous*100, rate*100))
+		}
+		if rate != 0 {
+			ct.funding[symbol] = rate
+		}
+	}
+	for symbol := range ct.funding {
+		if !symbols[symbol] {
+			delete(ct.funding, symbol)
+		}
+	}
+	return reasons
+}
+
+// triggerQueue the triggered cycles waiting for the run loop (nil = triggers disabled, never ready)
+func (at *AutoTrader) triggerQueue() <-chan []string {
+	if at.triggers == nil {
+		return nil
+	}
+	return at.triggers.queue
+}
+
+// currentTrigger the reasons of the triggered cycle in progress (nil = not a triggered cycle)
+func (at *AutoTrader) currentTrigger() []string {
+	if at.triggers == nil {
+		return nil
+	}
+	return at.triggers.current
+}
+
+// runTriggeredCycle runs an early cycle for the triggers' reasons (skipped while paused)
+func (at *AutoTrader) runTriggeredCycle(reasons []string) {
+	if paused, _ := at.IsPaused(); paused {
+		at.log.Infof("⏸ Paused by operator, skipping triggered cycle")
+		return
+	}
+	// A scheduled cycle may have run while this one was queued
+	if since := time.Since(at.cycles.lastStarted()); since < time.Duration(at.triggers.cfg.MinGapSeconds)*time.Second {
+		at.log.Infof("⚡ Triggered cycle skipped, a cycle started %v ago", since.Round(time.Second))
+		return
+	}
+	at.log.Infof("⚡ Triggered cycle starting (%s)...", strings.Join(reasons, "; "))
+	at.triggers.current = reasons
+	defer func() { at.triggers.current = nil }()
+	if err := at.runCycle(at.runCtx); err != nil {
+		at.log.Errorf("❌ Triggered cycle failed: %v", err)
+	} else {
+		at.log.Infof("✅ Triggered cycle completed, waiting for next cycle")
+	}
+}
+
+// describeTriggerPct a trigger threshold for logs
+func describeTriggerPct(pct float64) string {
+	if pct <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("±%.1f%%", pct)
+}
@@ -0,0 +1,480 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/market"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Decision quality scores each closed trade on how it was decided and executed rather than on its P&L:
+// did the entry follow the market regime, was the reward:risk actually obtained at the fill, did price
+// reach the target before the stop, and how far did the fill slip from the decision price. Comparing the
+// 0-100 process score with the outcome separates good decisions with bad luck from bad decisions that
+// got lucky.
+
+const (
+	qualityComponentWeight = 25.0               // Each of the four components is worth 25 points
+	qualityGoodProcess     = 60.0               // Scores at or above this count as a good decision
+	qualitySlippageZeroBps = 50.0               // Adverse slippage that scores 0 on execution
+	qualityMaxScores       = 1000               // Scored trades kept
+	qualityPlanMaxAge      = 7 * 24 * time.Hour // Plans whose trade never showed up as closed are dropped
+	qualityPathMaxKlines   = 1500               // Binance kline request limit
+	qualityFineMaxDuration = 20 * time.Hour     // Trades up to this long are replayed on 5m klines, longer on 1h
+)
+
+// Price path outcomes
+const (
+	FirstHitTarget  = "target"  // Take profit level reached before the stop
+	FirstHitStop    = "stop"    // Stop level reached before the target
+	FirstHitNeither = "neither" // Closed before either level was touched
+	FirstHitUnknown = "unknown" // Klines unavailable
+)
+
+// Decision quality verdicts (process vs outcome)
+const (
+	VerdictGoodDecision = "good_decision" // Good process, profitable
+	VerdictBadLuck      = "bad_luck"      // Good process, lost money
+	VerdictLucky        = "lucky"         // Poor process, profitable
+	VerdictBadDecision  = "bad_decision"  // Poor process, lost money
+)
+
+// decisionPlan what the AI planned when opening a position
+type decisionPlan struct {
+	DecisionPrice float64       `json:"decision_price"` // Market price the decision was made at
+	FillPrice     float64       `json:"fill_price"`     // Entry price reported after the open (0 = unknown)
+	StopLoss      float64       `json:"stop_loss"`
+	TakeProfit    float64       `json:"take_profit"`
+	Leverage      int           `json:"leverage"`
+	Confidence    int           `json:"confidence"`
+	Regime        *MemoryRegime `json:"regime,omitempty"`
+	At            time.Time     `json:"at"`
+}
+
+// DecisionScore process score of one closed trade
+type DecisionScore struct {
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"`
+	OpenTime    time.Time `json:"open_time"`
+	CloseTime   time.Time `json:"close_time"`
+	PnL         float64   `json:"pnl"`
+	Notional    float64   `json:"notional,omitempty"` // Position value at open (fee estimate)
+	Confidence  int       `json:"confidence"`
+	Regime      string    `json:"regime,omitempty"`
+	Alignment   int       `json:"regime_alignment"` // Trend signals (EMA20, 4h change, MACD) agreeing with the side, 0-3 (-1 = unknown)
+	PlannedRR   float64   `json:"planned_rr"`       // Reward:risk at the decision price
+	ExecutedRR  float64   `json:"executed_rr"`      // Reward:risk at the actual fill
+	SlippageBps float64   `json:"slippage_bps"`     // Adverse fill vs decision price (negative = price improvement)
+	FirstHit    string    `json:"first_hit"`
+
+	// Component scores (0-25, -1 = not available; the total is rescaled over available components)
+	RegimeScore    float64 `json:"regime_score"`
+	RRScore        float64 `json:"rr_score"`
+	PathScore      float64 `json:"path_score"`
+	ExecutionScore float64 `json:"execution_score"`
+
+	Score   float64 `json:"score"` // 0-100 process score
+	Verdict string  `json:"verdict"`
+}
+
+// DecisionQualitySummary aggregated process scores of a trader
+type DecisionQualitySummary struct {
+	Trades          int            `json:"trades"`
+	AvgScore        float64        `json:"avg_score"`
+	AvgScoreWinners float64        `json:"avg_score_winners"`
+	AvgScoreLosers  float64        `json:"avg_score_losers"`
+	Verdicts        map[string]int `json:"verdicts"`
+	AvgRegime       float64        `json:"avg_regime_score"`
+	AvgRR           float64        `json:"avg_rr_score"`
+	AvgPath         float64        `json:"avg_path_score"`
+	AvgExecution    float64        `json:"avg_execution_score"`
+}
+
+// DecisionQuality scores closed trades against the plan recorded when they were opened
+type DecisionQuality struct {
+	path   string
+	mu     sync.Mutex
+	plans  map[string]*decisionPlan // symbol_side -> plan of the open position
+	scored map[string]bool          // Trade keys already scored
+	scores []*DecisionScore
+}
+
+// decisionQualityState persisted form
+type decisionQualityState struct {
+	Plans  map[string]*decisionPlan `json:"plans"`
+	Scores []*DecisionScore         `json:"scores"`
+}
+
+// NewDecisionQuality creates the scorer persisted at path, loading existing plans and scores
+func NewDecisionQuality(path string) *DecisionQuality {
+	q := &DecisionQuality{
+		path:   path,
+		plans:  make(map[string]*decisionPlan),
+		scored: make(map[string]bool),
+	}
+	if err := q.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load decision quality scores (%s): %v - starting empty", path, err)
+	}
+	return q
+}
+
+// RecordPlan remembers the plan of a position that was just opened (fillPrice 0 = unknown)
+func (q *DecisionQuality) RecordPlan(decision *decisionPkg.Decision, side string, data *market.Data, fillPrice float64) {
+	if data == nil || data.CurrentPrice <= 0 {
+		return
+	}
+	plan := &decisionPlan{
+		DecisionPrice: data.CurrentPrice,
+		FillPrice:     fillPrice,
+		StopLoss:      decision.StopLoss,
+		TakeProfit:    decision.TakeProfit,
+		Leverage:      decision.Leverage,
+		Confidence:    decision.Confidence,
+		Regime:        regimeFromMarket(data),
+		At:            time.Now(),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.plans[decision.Symbol+"_"+side] = plan
+	q.save()
+}
+
+// Score scores closed trades that have a recorded plan; returns how many were scored
+func (q *DecisionQuality) Score(trades []logger.TradeOutcome) int {
+	q.mu.Lock()
+	var pending []logger.TradeOutcome
+	plans := make(map[string]*decisionPlan)
+	plansChanged := false
+	for _, trade := range trades {
+		key := scoreKey(trade)
+		if q.scored[key] {
+			continue
+		}
+		planKey := trade.Symbol + "_" + trade.Side
+		plan, ok := q.plans[planKey]
+		if !ok || absDuration(plan.At.Sub(trade.OpenTime)) > memoryEntryMatchSpan {
+			q.scored[key] = true // Opened without a plan (manual or before scoring existed): nothing to score
+			continue
+		}
+		pending = append(pending, trade)
+		plans[key] = plan
+		delete(q.plans, planKey)
+		plansChanged = true
+	}
+	for key, plan := range q.plans {
+		if time.Since(plan.At) > qualityPlanMaxAge {
+			delete(q.plans, key)
+			plansChanged = true
+		}
+	}
+	q.mu.Unlock()
+
+	// Price paths are fetched without holding the lock
+	var added []*DecisionScore
+	for _, trade := range pending {
+		added = append(added, scoreTrade(trade, plans[scoreKey(trade)]))
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, score := range added {
+		q.scored[scoreKey(logger.TradeOutcome{Symbol: score.Symbol, Side: score.Side, CloseTime: score.CloseTime})] = true
+		q.scores = append(q.scores, score)
+	}
+	if len(q.scores) > qualityMaxScores {
+		q.scores = q.scores[len(q.scores)-qualityMaxScores:]
+	}
+	if plansChanged {
+		q.save()
+	}
+	return len(added)
+}
+
+// scoreKey identifies a closed trade
+func scoreKey(trade logger.TradeOutcome) string {
+	return fmt.Sprintf("%s_%s_%d", trade.Symbol, trade.Side, trade.CloseTime.UnixNano())
+}
+
+// scoreTrade rates one trade against its plan
+func scoreTrade(trade logger.TradeOutcome, plan *decisionPlan) *DecisionScore {
+	score := &DecisionScore{
+		Symbol:         trade.Symbol,
+		Side:           trade.Side,
+		OpenTime:       trade.OpenTime,
+		CloseTime:      trade.CloseTime,
+		PnL:            trade.PnL,
+		Notional:       trade.PositionValue,
+		Confidence:     plan.Confidence,
+		Alignment:      -1,
+		RegimeScore:    -1,
+		RRScore:        -1,
+		PathScore:      -1,
+		ExecutionScore: -1,
+		FirstHit:       FirstHitUnknown,
+	}
+	long := trade.Side == "long"
+
+	// 1. Regime: trend signals agreeing with the side
+	if plan.Regime != nil {
+		score.Regime = plan.Regime.describe()
+		score.Alignment = regimeAlignment(plan.Regime, long)
+		score.RegimeScore = qualityComponentWeight * float64(score.Alignment) / 3
+	}
+
+	// 2. Reward:risk as planned vs as executed
+	fill := plan.FillPrice
+	if fill <= 0 {
+		fill = trade.OpenPrice
+	}
+	score.PlannedRR = rewardRisk(plan.DecisionPrice, plan.StopLoss, plan.TakeProfit, long)
+	score.ExecutedRR = rewardRisk(fill, plan.StopLoss, plan.TakeProfit, long)
+	if score.PlannedRR > 0 {
+		score.RRScore = qualityComponentWeight * math.Max(0, math.Min(1, score.ExecutedRR/score.PlannedRR))
+	}
+
+	// 3. Price path: target before stop
+	score.FirstHit = firstHit(trade, plan, long)
+	switch score.FirstHit {
+	case FirstHitTarget:
+		score.PathScore = qualityComponentWeight
+	case FirstHitStop:
+		score.PathScore = 0
+	case FirstHitNeither:
+		score.PathScore = qualityComponentWeight / 2
+	}
+
+	// 4. Execution: slippage of the fill against the decision price
+	if plan.FillPrice > 0 && plan.DecisionPrice > 0 {
+		slippage := (plan.FillPrice - plan.DecisionPrice) / plan.DecisionPrice * 10000
+		if !long {
+			slippage = -slippage
+		}
+		score.SlippageBps = slippage
+		score.ExecutionScore = qualityComponentWeight * math.Max(0, math.Min(1, 1-slippage/qualitySlippageZeroBps))
+	}
+
+	var total, weight float64
+	for _, component := range []float64{score.RegimeScore, score.RRScore, score.PathScore, score.ExecutionScore} {
+		if component >= 0 {
+			total += component
+			weight += qualityComponentWeight
+		}
+	}
+	if weight > 0 {
+		score.Score = total / weight * 100
+	}
+
+	good := score.Score >= qualityGoodProcess
+	switch {
+	case good && trade.PnL > 0:
+		score.Verdict = VerdictGoodDecision
+	case good:
+		score.Verdict = VerdictBadLuck
+	case trade.PnL > 0:
+		score.Verdict = VerdictLucky
+	default:
+		score.Verdict = VerdictBadDecision
+	}
+	return score
+}
+
+// regimeAlignment number of trend signals (price vs EMA20, 4h change, MACD) pointing the position's way
+func regimeAlignment(regime *MemoryRegime, long bool) int {
+	aligned := 0
+	for _, signal := range []float64{regime.PriceVsEMA20, regime.PriceChange4h, regime.MACD} {
+		if long && signal > 0 || !long && signal < 0 {
+			aligned++
+		}
+	}
+	return aligned
+}
+
+// rewardRisk reward:risk of an entry (0 if the levels are missing or on the wrong side)
+func rewardRisk(entry, stop, target float64, long bool) float64 {
+	if entry <= 0 || stop <= 0 || target <= 0 {
+		return 0
+	}
+	risk, reward := entry-stop, target-entry
+	if !long {
+		risk, reward = stop-entry, entry-target
+	}
+	if risk <= 0 || reward <= 0 {
+		return 0
+	}
+	return reward / risk
+}
+
+// firstHit replays klines between open and close to see whether target or stop was touched first
+// When a kline touches both, the stop is assumed first (conservative)
+func firstHit(trade logger.TradeOutcome, plan *decisionPlan, long bool) string {
+	if plan.StopLoss <= 0 || plan.TakeProfit <= 0 || trade.OpenTime.IsZero() || trade.CloseTime.IsZero() {
+		return FirstHitUnknown
+	}
+	interval, step := "5m", 5*time.Minute
+	if trade.CloseTime.Sub(trade.OpenTime) > qualityFineMaxDuration {
+		interval, step = "1h", time.Hour
+	}
+	limit := int(time.Since(trade.OpenTime)/step) + 2
+	if limit > qualityPathMaxKlines {
+		return FirstHitUnknown // Too old to cover with one request
+	}
+	klines, err := market.GetKlines(trade.Symbol, interval, limit)
+	if err != nil {
+		traderLog.Warnf("⚠️  Decision quality: failed to get %s klines: %v", trade.Symbol, err)
+		return FirstHitUnknown
+	}
+
+	start := trade.OpenTime.Truncate(step).UnixMilli()
+	end := trade.CloseTime.UnixMilli()
+	for _, k := range klines {
+		if k.OpenTime < start || k.OpenTime > end {
+			continue
+		}
+		stopHit := long && k.Low <= plan.StopLoss || !long && k.High >= plan.StopLoss
+		targetHit := long && k.High >= plan.TakeProfit || !long && k.Low <= plan.TakeProfit
+		switch {
+		case stopHit:
+			return FirstHitStop
+		case targetHit:
+			return FirstHitTarget
+		}
+	}
+	return FirstHitNeither
+}
+
+// Summary aggregates the scores of the last window (window <= 0 = all)
+func (q *DecisionQuality) Summary(window time.Duration) *DecisionQualitySummary {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	summary := &DecisionQualitySummary{Verdicts: make(map[string]int)}
+	var winners, losers int
+	var components [4]struct {
+		total float64
+		count int
+	}
+	for _, s := range q.scores {
+		if window > 0 && time.Since(s.CloseTime) > window {
+			continue
+		}
+		summary.Trades++
+		summary.AvgScore += s.Score
+		summary.Verdicts[s.Verdict]++
+		if s.PnL > 0 {
+			winners++
+			summary.AvgScoreWinners += s.Score
+		} else {
+			losers++
+			summary.AvgScoreLosers += s.Score
+		}
+		for i, component := range []float64{s.RegimeScore, s.RRScore, s.PathScore, s.ExecutionScore} {
+			if component >= 0 {
+				components[i].total += component
+				components[i].count++
+			}
+		}
+	}
+	if summary.Trades == 0 {
+		return summary
+	}
+
+	summary.AvgScore /= float64(summary.Trades)
+	if winners > 0 {
+		summary.AvgScoreWinners /= float64(winners)
+	}
+	if losers > 0 {
+		summary.AvgScoreLosers /= float64(losers)
+	}
+	averages := []*float64{&summary.AvgRegime, &summary.AvgRR, &summary.AvgPath, &summary.AvgExecution}
+	for i, c := range components {
+		if c.count > 0 {
+			*averages[i] = c.total / float64(c.count)
+		}
+	}
+	return summary
+}
+
+// Scores returns the most recent scored trades, newest first (limit <= 0 = all)
+func (q *DecisionQuality) Scores(limit int) []DecisionScore {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]DecisionScore, 0, len(q.scores))
+	for i := len(q.scores) - 1; i >= 0; i-- {
+		result = append(result, *q.scores[i])
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// load reads persisted plans and scores (a missing file is not an error)
+func (q *DecisionQuality) load() error {
+	data, err := os.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state decisionQualityState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse decision quality scores: %w", err)
+	}
+	if state.Plans != nil {
+		q.plans = state.Plans
+	}
+	q.scores = state.Scores
+	for _, s := range q.scores {
+		q.scored[scoreKey(logger.TradeOutcome{Symbol: s.Symbol, Side: s.Side, CloseTime: s.CloseTime})] = true
+	}
+	return nil
+}
+
+// save persists plans and scores (caller holds mu); failures are logged
+func (q *DecisionQuality) save() {
+	data, err := json.Marshal(decisionQualityState{Plans: q.plans, Scores: q.scores})
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize decision quality scores: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create decision quality directory: %v", err)
+		return
+	}
+	tmpPath := q.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write decision quality scores: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace decision quality file: %v", err)
+	}
+}
+
+// recordDecisionPlan records the plan of a position just opened, with the fill from the exchange's entry price
+func (at *AutoTrader) recordDecisionPlan(decision *decisionPkg.Decision, side string, data *market.Data) {
+	var fillPrice float64
+	if positions, err := at.trader.GetPositions(); err == nil {
+		for _, pos := range positions {
+			if pos.Symbol == decision.Symbol && pos.Side == side {
+				fillPrice = pos.EntryPrice
+				break
+			}
+		}
+	}
+	at.decisionQuality.RecordPlan(decision, side, data, fillPrice)
+}
+
+// GetDecisionQuality returns the trader's decision process scores
+func (at *AutoTrader) GetDecisionQuality() *DecisionQuality {
+	return at.decisionQuality
+}
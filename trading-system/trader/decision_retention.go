@@ -0,0 +1,29 @@
+package trader
+
+import (
+	"context"
+	"lia/logger"
+	"time"
+)
+
+// runDecisionRetention strips decision records older than decision_retention.full_text_days to summaries, at
+// start and every prune_interval_hours until ctx ends
+func (at *AutoTrader) runDecisionRetention(ctx context.Context) {
+	cfg := at.config.DecisionRetention
+	policy := logger.RetentionPolicy{FullTextDays: cfg.FullTextDays, SummaryChars: cfg.SummaryChars}
+	ticker := time.NewTicker(time.Duration(cfg.PruneIntervalHours) * time.Hour)
+	defer ticker.Stop()
+	for {
+		if n, err := at.decisionLogger.ApplyRetention(policy); err != nil {
+			at.log.Warnf("⚠️  Decision log retention failed: %v", err)
+		} else if n > 0 {
+			at.log.Infof("🧹 Stripped %d decision records older than %d days to summaries", n, cfg.FullTextDays)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
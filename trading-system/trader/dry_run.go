@@ -0,0 +1,183 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"lia/logging"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dryRunOrderStatus status of the simulated orders a dry run returns
+const dryRunOrderStatus = "DRY_RUN"
+
+// maxDryRunOrders shadow orders kept for GetStatus
+const maxDryRunOrders = 50
+
+// DryRunOrder an order a dry-run trader would have placed
+type DryRunOrder struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"` // open_long, close_short, stop_loss, take_profit, set_leverage, cancel_orders
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side,omitempty"`
+	Quantity float64   `json:"quantity,omitempty"`
+	Price    float64   `json:"price,omitempty"` // Market price at the time, or the stop / take profit trigger
+	Leverage int       `json:"leverage,omitempty"`
+}
+
+// dryRunTrader the shadow executor of dry_run traders: balances, positions and prices come from the real
+// exchange, orders are logged and answered with a simulated fill at the market price instead of being sent.
+// It only passes through the exchange's read-only optional interfaces (ping, cache warmup), so
+// limit entries, margin adjustments and the startup leverage setup are off
+type dryRunTrader struct {
+	Trader
+	log logging.Logger
+	seq int64
+
+	mu     sync.Mutex
+	orders []DryRunOrder // Latest shadow orders, oldest first
+	count  int
+}
+
+// newDryRunTrader wraps the exchange adapter t so no order reaches the exchange
+func newDryRunTrader(t Trader, log logging.Logger) *dryRunTrader {
+	return &dryRunTrader{Trader: t, log: log}
+}
+
+// shadow logs an order that was not placed and keeps it for GetStatus
+func (dt *dryRunTrader) shadow(order DryRunOrder) {
+	order.Time = time.Now()
+	dt.mu.Lock()
+	dt.orders = append(dt.orders, order)
+	if len(dt.orders) > maxDryRunOrders {
+		dt.orders = dt.orders[len(dt.orders)-maxDryRunOrders:]
+	}
+	dt.count++
+	dt.mu.Unlock()
+
+	switch {
+	case order.Quantity > 0 && order.Price > 0:
+		dt.log.Infof("🧪 DRY RUN: would have placed %s %s %s %.6f @ %.4f", order.Action, order.Symbol, order.Side, order.Quantity, order.Price)
+	case order.Leverage > 0:
+		dt.log.Infof("🧪 DRY RUN: would have set %s leverage to %dx", order.Symbol, order.Leverage)
+	default:
+		dt.log.Infof("🧪 DRY RUN: would have placed %s %s %s", order.Action, order.Symbol, order.Side)
+	}
+}
+
+// fill the simulated fill of a market order at the current price
+func (dt *dryRunTrader) fill(action, symbol, side string, quantity float64, leverage int) (*Order, error) {
+	price, err := dt.Trader.GetMarketPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("dry run: failed to get %s price: %w", symbol, err)
+	}
+	dt.shadow(DryRunOrder{Action: action, Symbol: symbol, Side: side, Quantity: quantity, Price: price, Leverage: leverage})
+	return &Order{
+		ClientOrderID: fmt.Sprintf("dryrun-%d-%d", time.Now().Unix(), atomic.AddInt64(&dt.seq, 1)),
+		Symbol:        symbol,
+		Status:        dryRunOrderStatus,
+		Price:         price,
+		ExecutedQty:   quantity,
+	}, nil
+}
+
+// closeQuantity the quantity a close of quantity (0 = all) would fill, read from the real position
+func (dt *dryRunTrader) closeQuantity(symbol, side string, quantity float64) (float64, error) {
+	if quantity > 0 {
+		return quantity, nil
+	}
+	positions, err := dt.Trader.GetPositions()
+	if err != nil {
+		return 0, fmt.Errorf("dry run: failed to get positions: %w", err)
+	}
+	pos, ok := findPosition(positions, symbol, side)
+	if !ok {
+		return 0, fmt.Errorf("dry run: no %s %s position to close", symbol, side)
+	}
+	return pos.Quantity, nil
+}
+
+// OpenLong logs the open instead of placing it
+func (dt *dryRunTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	return dt.fill("open_long", symbol, "long", quantity, leverage)
+}
+
+// OpenShort logs the open instead of placing it
+func (dt *dryRunTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	return dt.fill("open_short", symbol, "short", quantity, leverage)
+}
+
+// CloseLong logs the close instead of placing it
+func (dt *dryRunTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
+	quantity, err := dt.closeQuantity(symbol, "long", quantity)
+	if err != nil {
+		return nil, err
+	}
+	return dt.fill("close_long", symbol, "long", quantity, 0)
+}
+
+// CloseShort logs the close instead of placing it
+func (dt *dryRunTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
+	quantity, err := dt.closeQuantity(symbol, "short", quantity)
+	if err != nil {
+		return nil, err
+	}
+	return dt.fill("close_short", symbol, "short", quantity, 0)
+}
+
+// SetLeverage logs the change instead of applying it to the account
+func (dt *dryRunTrader) SetLeverage(symbol string, leverage int) error {
+	dt.shadow(DryRunOrder{Action: "set_leverage", Symbol: symbol, Leverage: leverage})
+	return nil
+}
+
+// SetStopLoss logs the stop order instead of placing it
+func (dt *dryRunTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	dt.shadow(DryRunOrder{Action: "stop_loss", Symbol: symbol, Side: positionSide, Quantity: quantity, Price: stopPrice})
+	return nil
+}
+
+// SetTakeProfit logs the take profit order instead of placing it
+func (dt *dryRunTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	dt.shadow(DryRunOrder{Action: "take_profit", Symbol: symbol, Side: positionSide, Quantity: quantity, Price: takeProfitPrice})
+	return nil
+}
+
+// CancelAllOrders logs the cancel instead of cancelling the account's orders
+func (dt *dryRunTrader) CancelAllOrders(symbol string) error {
+	dt.shadow(DryRunOrder{Action: "cancel_orders", Symbol: symbol})
+	return nil
+}
+
+// Ping checks the real exchange (readiness probe)
+func (dt *dryRunTrader) Ping(ctx context.Context) error {
+	if pinger, ok := dt.Trader.(ExchangePinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// WarmUp warms the real exchange adapter's caches
+func (dt *dryRunTrader) WarmUp() error {
+	if warmer, ok := dt.Trader.(CacheWarmer); ok {
+		return warmer.WarmUp()
+	}
+	return nil
+}
+
+// status the shadow orders logged so far
+func (dt *dryRunTrader) status() map[string]interface{} {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	return map[string]interface{}{
+		"orders":        dt.count,
+		"recent_orders": append([]DryRunOrder(nil), dt.orders...),
+	}
+}
+
+// asDryRunTrader the shadow executor of a dry_run trader (false = orders go to the exchange)
+func asDryRunTrader(t Trader) (*dryRunTrader, bool) {
+	dt, ok := baseTrader(t).(*dryRunTrader)
+	return dt, ok
+}
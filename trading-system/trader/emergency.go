@@ -0,0 +1,198 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"strings"
+	"time"
+)
+
+// FlattenReport what the emergency flatten did on one trader
+type FlattenReport struct {
+	TraderID        string                  `json:"trader_id"`
+	TraderName      string                  `json:"trader_name"`
+	CycleInProgress int                     `json:"cycle_in_progress,omitempty"` // Cycle that was running (aborted and waited for before the flatten)
+	CancelledOrders []string                `json:"cancelled_orders,omitempty"`  // Symbols whose open orders (take profit, stop, resting entries) were cancelled
+	Closed          []logger.DecisionAction `json:"closed"`
+	AlreadyClosed   []string                `json:"already_closed,omitempty"` // Gone once their lock was held (e.g. closed by a trader on the same account)
+	Failed          []string                `json:"failed,omitempty"`
+}
+
+// emergencyCycleWait how long the emergency flatten waits for an aborted cycle to return (it finishes the order
+// being placed) before flattening anyway
+const emergencyCycleWait = 30 * time.Second
+
+// EmergencyFlatten the kill switch: pauses the trader, drops its queued manual cycle and signals, aborts the
+// running cycle and waits for it, cancels the open orders of its position and resting entry symbols, and closes
+// every position at market under its position lock, losing or not. The outcome is logged as a decision record
+// with source "emergency"
+func (at *AutoTrader) EmergencyFlatten(reason, operator string) *FlattenReport {
+	report := &FlattenReport{TraderID: at.id, TraderName: at.name, Closed: []logger.DecisionAction{}}
+	at.Pause()
+	at.dropQueuedCycles()
+	at.log.Errorf("🚨 Emergency flatten by %s: %s", operator, reason)
+
+	if done, number := at.cycles.abort("Emergency flatten"); done != nil {
+		report.CycleInProgress = number
+		at.log.Infof("⏳ Aborting cycle #%d before flattening...", number)
+		select {
+		case <-done:
+		case <-time.After(emergencyCycleWait):
+			report.Failed = append(report.Failed, fmt.Sprintf("cycle #%d still running after %v", number, emergencyCycleWait))
+		}
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		report.Failed = append(report.Failed, fmt.Sprintf("get positions: %v", err))
+		at.logEmergency(report, reason, operator)
+		return report
+	}
+
+	// Take profit and stop orders first, so none fires against the closes; resting entries so none opens later
+	symbols := make(map[string]bool)
+	var ordered []string
+	for _, pos := range positions {
+		if !symbols[pos.Symbol] {
+			symbols[pos.Symbol] = true
+			ordered = append(ordered, pos.Symbol)
+		}
+	}
+	for _, entry := range at.pendingEntries() {
+		if !symbols[entry.Symbol] {
+			symbols[entry.Symbol] = true
+			ordered = append(ordered, entry.Symbol)
+		}
+	}
+	for _, symbol := range ordered {
+		if err := at.trader.CancelAllOrders(symbol); err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("cancel %s orders: %v", symbol, err))
+			continue
+		}
+		report.CancelledOrders = append(report.CancelledOrders, symbol)
+	}
+
+	for _, pos := range positions {
+		if pos.Side != "long" && pos.Side != "short" {
+			continue
+		}
+		at.flattenPosition(pos, report)
+	}
+
+	at.log.Errorf("🚨 Emergency flatten done: %d closed, %d already closed, %d failed",
+		len(report.Closed), len(report.AlreadyClosed), len(report.Failed))
+	at.logEmergency(report, reason, operator)
+	return report
+}
+
+// flattenPosition closes one position under its lock, unless another trader on the account closed it first
+func (at *AutoTrader) flattenPosition(pos Position, report *FlattenReport) {
+	key := pos.Symbol + "_" + pos.Side
+	lock := getPositionLock(pos.Symbol, strings.ToUpper(pos.Side))
+	lock.Lock()
+	defer lock.Unlock()
+
+	current, err := at.trader.GetPositions()
+	if err != nil {
+		report.Failed = append(report.Failed, fmt.Sprintf("%s: get positions: %v", key, err))
+		return
+	}
+	live, ok := findPosition(current, pos.Symbol, pos.Side)
+	if !ok {
+		report.AlreadyClosed = append(report.AlreadyClosed, key)
+		return
+	}
+
+	var order *Order
+	if pos.Side == "long" {
+		order, err = at.trader.CloseLong(pos.Symbol, 0)
+	} else {
+		order, err = at.trader.CloseShort(pos.Symbol, 0)
+	}
+	if err != nil {
+		at.log.Errorf("❌ Emergency close of %s failed: %v", key, err)
+		report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", key, err))
+		return
+	}
+
+	action := logger.DecisionAction{
+		Action:    "close_" + pos.Side,
+		Symbol:    pos.Symbol,
+		Quantity:  live.Quantity,
+		Leverage:  live.Leverage,
+		Price:     live.MarkPrice,
+		Timestamp: time.Now(),
+		Success:   true,
+	}
+	if order != nil {
+		action.OrderID = order.OrderID
+		action.ClientOrderID = order.ClientOrderID
+		action.Fee = order.Fee
+		action.RealizedPnL = order.RealizedPnL
+		if order.Price > 0 {
+			action.Price = order.Price
+		}
+	}
+	at.log.Infof("🧹 Emergency closed %s (%.4f @ %.4f, unrealized P&L was %+.2f USDT)",
+		key, live.Quantity, action.Price, live.UnrealizedProfit)
+	report.Closed = append(report.Closed, action)
+}
+
+// logEmergency records the flatten in the decision log
+func (at *AutoTrader) logEmergency(report *FlattenReport, reason, operator string) {
+	if at.decisionLogger == nil {
+		return
+	}
+	decisions := []decisionPkg.Decision{}
+	for _, action := range report.Closed {
+		decisions = append(decisions, decisionPkg.Decision{Symbol: action.Symbol, Action: action.Action, Reasoning: "Emergency flatten"})
+	}
+	decisionJSON, _ := json.Marshal(decisions)
+
+	executionLog := []string{
+		fmt.Sprintf("🚨 Emergency flatten by %s: %s", operator, reason),
+		"⏸ Trader paused (resume with POST /api/traders/:id/resume)",
+	}
+	if report.CycleInProgress > 0 {
+		executionLog = append(executionLog, fmt.Sprintf("⏹ Cycle #%d was running and was aborted before the flatten", report.CycleInProgress))
+	}
+	if len(report.CancelledOrders) > 0 {
+		executionLog = append(executionLog, fmt.Sprintf("Cancelled open orders: %s", strings.Join(report.CancelledOrders, ", ")))
+	}
+	for _, action := range report.Closed {
+		executionLog = append(executionLog, fmt.Sprintf("Closed %s %s (%.4f @ %.4f)", action.Symbol, strings.TrimPrefix(action.Action, "close_"), action.Quantity, action.Price))
+	}
+	for _, key := range report.AlreadyClosed {
+		executionLog = append(executionLog, fmt.Sprintf("%s already closed", key))
+	}
+	for _, failure := range report.Failed {
+		executionLog = append(executionLog, "❌ "+failure)
+	}
+
+	record := &logger.DecisionRecord{
+		InputPrompt:    fmt.Sprintf("Emergency flatten by %s", operator),
+		CoTTrace:       fmt.Sprintf("🚨 Emergency flatten: %s", reason),
+		DecisionJSON:   string(decisionJSON),
+		Positions:      []logger.PositionSnapshot{},
+		CandidateCoins: []string{},
+		Decisions:      report.Closed,
+		ExecutionLog:   executionLog,
+		Success:        len(report.Failed) == 0,
+		Source:         logger.DecisionSourceEmergency,
+	}
+	if len(report.Failed) > 0 {
+		record.ErrorMessage = fmt.Sprintf("%d step(s) failed: %s", len(report.Failed), strings.Join(report.Failed, "; "))
+	}
+	if account, err := at.GetAccountInfo(); err == nil {
+		record.AccountState.TotalBalance, _ = account["total_equity"].(float64)
+		record.AccountState.AvailableBalance, _ = account["available_balance"].(float64)
+		record.AccountState.TotalUnrealizedProfit, _ = account["unrealized_profit"].(float64)
+		record.AccountState.PositionCount, _ = account["position_count"].(int)
+		record.AccountState.MarginUsedPct, _ = account["margin_used_pct"].(float64)
+	}
+	if err := at.decisionLogger.LogDecision(record); err != nil {
+		at.log.Warnf("⚠️  Failed to log emergency flatten: %v", err)
+	}
+}
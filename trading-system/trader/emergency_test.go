@@ -0,0 +1,108 @@
+package trader
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// openBothResponse opens BTCUSDT and ETHUSDT longs
+const openBothResponse = `Both majors are breaking out.
+
+[{"symbol": "BTCUSDT", "action": "open_long", "leverage": 5, "position_size_usd": 2000, "stop_loss": 41200, "take_profit": 44500, "confidence": 85, "risk_usd": 40, "reasoning": "Breakout"},
+ {"symbol": "ETHUSDT", "action": "open_long", "leverage": 5, "position_size_usd": 2000, "stop_loss": 2255, "take_profit": 2420, "confidence": 85, "risk_usd": 40, "reasoning": "Breakout"}]
+`
+
+// blockingTrader holds the first open until released, so a test can act while a cycle is placing an order
+type blockingTrader struct {
+	Trader
+	opens   atomic.Int32
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTrader) unwrap() Trader {
+	return b.Trader
+}
+
+func (b *blockingTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	b.opens.Add(1)
+	b.once.Do(func() {
+		close(b.entered)
+		<-b.release
+	})
+	return b.Trader.OpenLong(symbol, quantity, leverage)
+}
+
+func TestEmergencyFlattenAbortsRunningCycle(t *testing.T) {
+	at := newSimulatedTestTrader(t, openBothResponse)
+	block := &blockingTrader{Trader: at.trader, entered: make(chan struct{}), release: make(chan struct{})}
+	at.trader = block
+
+	cycleDone := make(chan error, 1)
+	go func() { cycleDone <- at.Step() }()
+	<-block.entered
+
+	flattened := make(chan *FlattenReport, 1)
+	go func() { flattened <- at.EmergencyFlatten("test", "tester") }()
+	// Let the open being placed finish once the flatten has aborted the cycle
+	deadline := time.Now().Add(5 * time.Second)
+	for at.cycles.abortReason() == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("the flatten did not abort the running cycle")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(block.release)
+
+	report := <-flattened
+	<-cycleDone
+	if report.CycleInProgress != 1 {
+		t.Errorf("cycle in progress = %d, want 1", report.CycleInProgress)
+	}
+	if len(report.Failed) > 0 {
+		t.Errorf("flatten failures: %v", report.Failed)
+	}
+	if len(report.Closed) != 1 {
+		t.Errorf("closed %d positions, want the 1 opened before the abort", len(report.Closed))
+	}
+	if opens := block.opens.Load(); opens != 1 {
+		t.Errorf("%d opens placed, want 1 (the second decision must be skipped)", opens)
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 0 {
+		t.Errorf("positions left after the flatten: %+v", positions)
+	}
+
+	records, err := at.decisionLogger.GetLatestRecords(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aborted := false
+	for _, record := range records {
+		if strings.HasPrefix(record.ErrorMessage, "Emergency flatten during cycle") {
+			aborted = true
+		}
+	}
+	if !aborted {
+		t.Error("the aborted cycle's record does not say it was aborted by the flatten")
+	}
+
+	// Paused by the flatten: a later cycle (a manual one) may not reopen
+	if err := at.Step(); err != nil {
+		t.Fatal(err)
+	}
+	positions, err = at.trader.GetPositions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 0 {
+		t.Errorf("a cycle reopened positions while paused: %+v", positions)
+	}
+}
@@ -0,0 +1,259 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/config"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// End conditions that complete a trader
+const (
+	EndTargetPnL = "target_pnl"
+	EndMaxLoss   = "max_loss"
+	EndMaxDays   = "max_days"
+	EndMaxTrades = "max_trades"
+)
+
+// TraderCompletion progress towards a trader's end conditions and, once one is met, its final statistics
+type TraderCompletion struct {
+	StartedAt time.Time `json:"started_at"` // First run (persisted so max_days survives restarts)
+	Trades    int       `json:"trades"`     // Closes counted towards max_trades (partial reductions included)
+	Completed bool      `json:"completed"`
+
+	// Set on completion
+	CompletedAt     time.Time  `json:"completed_at,omitempty"`
+	Condition       string     `json:"condition,omitempty"` // target_pnl, max_loss, max_days, max_trades
+	Reason          string     `json:"reason,omitempty"`
+	FlattenPolicy   string     `json:"flatten_policy,omitempty"`
+	ClosedPositions []string   `json:"closed_positions,omitempty"` // symbol_side closed when flattening
+	FlattenErrors   []string   `json:"flatten_errors,omitempty"`
+	InitialBalance  float64    `json:"initial_balance,omitempty"`
+	FinalEquity     float64    `json:"final_equity,omitempty"` // Equity when the condition was met (before flattening)
+	PnL             float64    `json:"pnl,omitempty"`
+	PnLPct          float64    `json:"pnl_pct,omitempty"`
+	Days            float64    `json:"days,omitempty"`
+	Realized        PnLSummary `json:"realized"`
+	WinRate         float64    `json:"win_rate,omitempty"`
+	ProfitFactor    float64    `json:"profit_factor,omitempty"`
+}
+
+// completionTracker evaluates end conditions each cycle and persists progress/completion
+type completionTracker struct {
+	cfg            config.EndConditionsConfig
+	path           string
+	mu             sync.Mutex
+	state          TraderCompletion
+	lastCloseCount int // Ledger close count already added to state.Trades (the restored ledger's closes are counted)
+}
+
+// newCompletionTracker loads progress from path (a missing file starts a new run). closeCount is the close count of
+// the restored P&L ledger, whose closes state.Trades already includes
+func newCompletionTracker(cfg config.EndConditionsConfig, path string, closeCount int) *completionTracker {
+	ct := &completionTracker{cfg: cfg, path: path, lastCloseCount: closeCount}
+	if err := ct.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load completion state (%s): %v - starting a new run", path, err)
+		ct.state = TraderCompletion{}
+	}
+	if ct.state.StartedAt.IsZero() {
+		ct.state.StartedAt = time.Now()
+		ct.save()
+	}
+	return ct
+}
+
+// syncTrades adds closes recorded by the ledger since the last call
+func (ct *completionTracker) syncTrades(closeCount int) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if closeCount <= ct.lastCloseCount {
+		return
+	}
+	ct.state.Trades += closeCount - ct.lastCloseCount
+	ct.lastCloseCount = closeCount
+	ct.save()
+}
+
+// check returns the first end condition met ("" if none)
+func (ct *completionTracker) check(equity, initialBalance float64, now time.Time) (condition, reason string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.state.Completed {
+		return "", ""
+	}
+
+	pnlPct := 0.0
+	if initialBalance > 0 && equity > 0 {
+		pnlPct = (equity - initialBalance) / initialBalance * 100
+	}
+	days := now.Sub(ct.state.StartedAt).Hours() / 24
+	switch {
+	case ct.cfg.MaxLossPct > 0 && equity > 0 && pnlPct <= -ct.cfg.MaxLossPct:
+		return EndMaxLoss, fmt.Sprintf("max loss hit: P&L %.2f%% <= -%.2f%%", pnlPct, ct.cfg.MaxLossPct)
+	case ct.cfg.TargetPnLPct > 0 && equity > 0 && pnlPct >= ct.cfg.TargetPnLPct:
+		return EndTargetPnL, fmt.Sprintf("target P&L reached: %.2f%% >= %.2f%%", pnlPct, ct.cfg.TargetPnLPct)
+	case ct.cfg.MaxTrades > 0 && ct.state.Trades >= ct.cfg.MaxTrades:
+		return EndMaxTrades, fmt.Sprintf("%d trades completed (max %d)", ct.state.Trades, ct.cfg.MaxTrades)
+	case ct.cfg.MaxDays > 0 && days >= ct.cfg.MaxDays:
+		return EndMaxDays, fmt.Sprintf("%.1f days elapsed (max %.1f)", days, ct.cfg.MaxDays)
+	}
+	return "", ""
+}
+
+// finalize marks the run completed with its final statistics
+func (ct *completionTracker) finalize(final TraderCompletion) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	final.StartedAt = ct.state.StartedAt
+	final.Trades = ct.state.Trades
+	final.Completed = true
+	ct.state = final
+	ct.save()
+}
+
+// snapshot returns a copy of the current state
+func (ct *completionTracker) snapshot() TraderCompletion {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.state
+}
+
+// completed whether an end condition has been met
+func (ct *completionTracker) completed() bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.state.Completed
+}
+
+// load reads persisted state (a missing file is not an error)
+func (ct *completionTracker) load() error {
+	data, err := os.ReadFile(ct.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &ct.state); err != nil {
+		return fmt.Errorf("failed to parse completion state: %w", err)
+	}
+	return nil
+}
+
+// save persists the state (caller holds mu); failures are logged
+func (ct *completionTracker) save() {
+	data, err := json.MarshalIndent(ct.state, "", "  ")
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize completion state: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(ct.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create completion state directory: %v", err)
+		return
+	}
+	tmpPath := ct.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write completion state: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, ct.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace completion state file: %v", err)
+	}
+}
+
+// checkEndConditions completes the trader if one of its end conditions is met: stops opening positions,
+// flattens per policy, finalizes its statistics and stops the trading loop. Returns the reason ("" if still running)
+func (at *AutoTrader) checkEndConditions(equity float64) string {
+	if at.completion == nil {
+		return ""
+	}
+	at.completion.syncTrades(at.pnlLedger.Summary().CloseCount)
+
+	now := time.Now()
+	condition, reason := at.completion.check(equity, at.initialBalance, now)
+	if condition == "" {
+		return ""
+	}
+	at.log.Infof("🏁 End condition met - %s", reason)
+
+	final := TraderCompletion{
+		CompletedAt:    now,
+		Condition:      condition,
+		Reason:         reason,
+		FlattenPolicy:  at.completion.cfg.FlattenPolicy,
+		InitialBalance: at.initialBalance,
+		FinalEquity:    equity,
+		PnL:            equity - at.initialBalance,
+		Days:           now.Sub(at.completion.snapshot().StartedAt).Hours() / 24,
+	}
+	if at.initialBalance > 0 {
+		final.PnLPct = final.PnL / at.initialBalance * 100
+	}
+	if final.FlattenPolicy != config.FlattenKeep {
+		final.FlattenPolicy = config.FlattenCloseAll
+		final.ClosedPositions, final.FlattenErrors = at.flattenPositions()
+	} else {
+		at.log.Infof("📌 Flatten policy 'keep': open positions are left to their stop loss/take profit orders")
+	}
+
+	// Statistics are taken after flattening so the final closes are included
+	final.Realized = at.pnlLedger.Summary()
+	if performance, err := at.decisionLogger.AnalyzePerformance(at.performanceLookback()); err == nil && performance != nil {
+		final.WinRate = performance.WinRate
+		final.ProfitFactor = performance.ProfitFactor
+	}
+	at.completion.syncTrades(final.Realized.CloseCount)
+	at.completion.finalize(final)
+
+	at.isRunning = false
+	at.log.Infof("✅ Trader completed (%s): P&L %+.2f USDT (%+.2f%%) over %.1f days, %d trades",
+		condition, final.PnL, final.PnLPct, final.Days, at.completion.snapshot().Trades)
+	return reason
+}
+
+// flattenPositions closes all open positions at market
+func (at *AutoTrader) flattenPositions() (closed, failed []string) {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		at.log.Errorf("❌ Failed to get positions for flattening: %v", err)
+		return nil, []string{fmt.Sprintf("get positions: %v", err)}
+	}
+	for _, pos := range positions {
+		symbol := pos.Symbol
+		var closeErr error
+		switch pos.Side {
+		case "long":
+			_, closeErr = at.trader.CloseLong(symbol, 0)
+		case "short":
+			_, closeErr = at.trader.CloseShort(symbol, 0)
+		default:
+			continue
+		}
+		key := symbol + "_" + pos.Side
+		if closeErr != nil {
+			at.log.Errorf("❌ Failed to flatten %s: %v", key, closeErr)
+			failed = append(failed, fmt.Sprintf("%s: %v", key, closeErr))
+			continue
+		}
+		at.log.Infof("🧹 Flattened %s", key)
+		closed = append(closed, key)
+	}
+	return closed, failed
+}
+
+// IsCompleted whether the trader has met one of its end conditions
+func (at *AutoTrader) IsCompleted() bool {
+	return at.completion != nil && at.completion.completed()
+}
+
+// GetCompletion returns end condition progress / final statistics (nil if no end conditions are configured)
+func (at *AutoTrader) GetCompletion() *TraderCompletion {
+	if at.completion == nil {
+		return nil
+	}
+	state := at.completion.snapshot()
+	return &state
+}
@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"lia/logger"
+	"time"
+)
+
+// equitySnapshotPruneInterval how often snapshots older than the retention are deleted
+const equitySnapshotPruneInterval = time.Hour
+
+// runEquitySnapshots records the account's equity and positions every equity_snapshots.interval_seconds until ctx
+// ends, independently of the decision cycles (a slow or skipped cycle leaves no gap in the equity curve)
+func (at *AutoTrader) runEquitySnapshots(ctx context.Context) {
+	cfg := at.config.EquitySnapshots
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	at.log.Infof("📸 Equity snapshots every %v (retention: %s)", interval, describeRetention(cfg.RetentionDays))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastPrune time.Time
+	for {
+		at.takeEquitySnapshot()
+		if cfg.RetentionDays > 0 && time.Since(lastPrune) >= equitySnapshotPruneInterval {
+			lastPrune = time.Now()
+			cutoff := lastPrune.AddDate(0, 0, -cfg.RetentionDays)
+			if n, err := at.decisionLogger.PruneEquitySnapshots(cutoff); err != nil {
+				at.log.Warnf("⚠️  Failed to prune equity snapshots: %v", err)
+			} else if n > 0 {
+				at.log.Infof("🧹 Pruned %d equity snapshots older than %d days", n, cfg.RetentionDays)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			at.log.Infof("🛑 Equity snapshots stopped")
+			return
+		}
+	}
+}
+
+// takeEquitySnapshot stores one snapshot of the account (skipped with a warning when the exchange fails)
+func (at *AutoTrader) takeEquitySnapshot() {
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		at.log.Warnf("⚠️  Equity snapshot skipped: failed to get balance: %v", err)
+		return
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		at.log.Warnf("⚠️  Equity snapshot skipped: failed to get positions: %v", err)
+		return
+	}
+
+	snapshot := &logger.EquitySnapshot{
+		Timestamp:        time.Now(),
+		TotalEquity:      addUSDT(balance.WalletBalance, balance.UnrealizedProfit),
+		AvailableBalance: balance.AvailableBalance,
+		UnrealizedPnL:    balance.UnrealizedProfit,
+		PositionCount:    len(positions),
+		Positions:        make([]logger.PositionSnapshot, 0, len(positions)),
+	}
+	marginUsed := 0.0
+	for _, pos := range positions {
+		leverage := 10
+		if pos.Leverage > 0 {
+			leverage = pos.Leverage
+		}
+		marginUsed = addUSDT(marginUsed, marginForQuantity(pos.Quantity, pos.MarkPrice, leverage))
+		snapshot.Positions = append(snapshot.Positions, logger.PositionSnapshot{
+			Symbol:           pos.Symbol,
+			Side:             pos.Side,
+			PositionAmt:      pos.Quantity,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        pos.MarkPrice,
+			UnrealizedProfit: pos.UnrealizedProfit,
+			Leverage:         float64(leverage),
+			LiquidationPrice: pos.LiquidationPrice,
+		})
+	}
+	if snapshot.TotalEquity > 0 {
+		snapshot.MarginUsedPct = marginUsed / snapshot.TotalEquity * 100
+	}
+
+	if err := at.decisionLogger.SaveEquitySnapshot(snapshot); err != nil {
+		at.log.Warnf("⚠️  Failed to save equity snapshot: %v", err)
+	}
+}
+
+// describeRetention retention_days for logs
+func describeRetention(days int) string {
+	if days < 0 {
+		return "keep all"
+	}
+	return fmt.Sprintf("%d days", days)
+}
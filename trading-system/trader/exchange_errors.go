@@ -0,0 +1,233 @@
+package trader
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// ExchangeErrorClass what went wrong with an exchange request, independent of the exchange
+type ExchangeErrorClass string
+
+// Exchange error classes
+const (
+	ExchangeErrorUnknown            ExchangeErrorClass = ""
+	ExchangeErrorRateLimit          ExchangeErrorClass = "rate_limit"           // Request budget exceeded (retried after a backoff)
+	ExchangeErrorTimestamp          ExchangeErrorClass = "timestamp"            // Local clock drifted from the exchange's (retried after a resync)
+	ExchangeErrorPrecision          ExchangeErrorClass = "precision"            // Quantity or price off the step / tick size (retried once, re-rounded)
+	ExchangeErrorMinNotional        ExchangeErrorClass = "min_notional"         // Order value or size below the exchange minimum
+	ExchangeErrorMaxQuantity        ExchangeErrorClass = "max_quantity"         // Order size above the exchange maximum (re-rounding cannot fix it)
+	ExchangeErrorReduceOnly         ExchangeErrorClass = "reduce_only_rejected" // Reduce-only order with no position (left) to reduce
+	ExchangeErrorMarginInsufficient ExchangeErrorClass = "margin_insufficient"  // Not enough margin or balance
+	ExchangeErrorUnavailable        ExchangeErrorClass = "unavailable"          // Network failure or exchange overloaded / down
+)
+
+// ExchangeError an exchange error with its class. Error() is the original message, so existing message checks
+// keep working
+type ExchangeError struct {
+	Class ExchangeErrorClass
+	Code  string // Exchange error code ("" = none reported)
+	Err   error
+}
+
+func (e *ExchangeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExchangeError) Unwrap() error {
+	return e.Err
+}
+
+// binanceErrorClasses Binance futures error codes (Aster uses the same codes)
+var binanceErrorClasses = map[int]ExchangeErrorClass{
+	-1003: ExchangeErrorRateLimit,          // TOO_MANY_REQUESTS
+	-1015: ExchangeErrorRateLimit,          // TOO_MANY_ORDERS
+	-1021: ExchangeErrorTimestamp,          // INVALID_TIMESTAMP (outside recvWindow)
+	-1111: ExchangeErrorPrecision,          // BAD_PRECISION
+	-4014: ExchangeErrorPrecision,          // PRICE_NOT_INCREASED_BY_TICK_SIZE
+	-4023: ExchangeErrorPrecision,          // QTY_NOT_INCREASED_BY_STEP_SIZE
+	-4164: ExchangeErrorMinNotional,        // MIN_NOTIONAL
+	-4005: ExchangeErrorMaxQuantity,        // QTY_GREATER_THAN_MAX_QTY
+	-2022: ExchangeErrorReduceOnly,         // REDUCE_ONLY_REJECT
+	-4118: ExchangeErrorReduceOnly,         // REDUCE_ONLY_MARGIN_CHECK_FAILED
+	-2018: ExchangeErrorMarginInsufficient, // BALANCE_NOT_SUFFICIENT
+	-2019: ExchangeErrorMarginInsufficient, // MARGIN_NOT_SUFFICIEN
+	-1001: ExchangeErrorUnavailable,        // DISCONNECTED
+	-1007: ExchangeErrorUnavailable,        // TIMEOUT (execution status unknown)
+	-1008: ExchangeErrorUnavailable,        // SERVER_BUSY
+}
+
+// bybitErrorClasses Bybit v5 return codes
+var bybitErrorClasses = map[int]ExchangeErrorClass{
+	10006:  ExchangeErrorRateLimit,          // Too many visits
+	10018:  ExchangeErrorRateLimit,          // Exceeded the IP rate limit
+	10002:  ExchangeErrorTimestamp,          // Request time exceeds the time window
+	170136: ExchangeErrorMaxQuantity,        // Order quantity exceeded upper limit
+	170137: ExchangeErrorPrecision,          // Order quantity has too many decimals
+	170134: ExchangeErrorPrecision,          // Order price has too many decimals
+	170140: ExchangeErrorMinNotional,        // Order value exceeded lower limit
+	110017: ExchangeErrorReduceOnly,         // Reduce-only order with zero position
+	110004: ExchangeErrorMarginInsufficient, // Wallet balance is insufficient
+	110007: ExchangeErrorMarginInsufficient, // Available balance is insufficient
+	110012: ExchangeErrorMarginInsufficient, // Insufficient available balance
+	10016:  ExchangeErrorUnavailable,        // Server error
+}
+
+// okxErrorClasses OKX v5 error codes (top-level or per order)
+var okxErrorClasses = map[string]ExchangeErrorClass{
+	"50011": ExchangeErrorRateLimit,          // Rate limit reached
+	"50061": ExchangeErrorRateLimit,          // Sub-account rate limit reached
+	"50102": ExchangeErrorTimestamp,          // Timestamp request expired
+	"50112": ExchangeErrorTimestamp,          // Invalid OK-ACCESS-TIMESTAMP
+	"51121": ExchangeErrorPrecision,          // Order quantity must be a multiple of the lot size
+	"51020": ExchangeErrorMinNotional,        // Order amount should be greater than the min available amount
+	"51202": ExchangeErrorMaxQuantity,        // Market order amount exceeds the maximum amount
+	"51169": ExchangeErrorReduceOnly,         // No position in this direction to reduce
+	"51205": ExchangeErrorReduceOnly,         // Reduce-only is not available
+	"51008": ExchangeErrorMarginInsufficient, // Insufficient balance / margin
+	"50001": ExchangeErrorUnavailable,        // Service temporarily unavailable
+	"50013": ExchangeErrorUnavailable,        // System busy
+}
+
+// exchangeErrorMessages message fragments (lower case) of exchanges without codes (Hyperliquid) and of errors
+// whose code is not in the tables, checked in order
+var exchangeErrorMessages = []struct {
+	fragment string
+	class    ExchangeErrorClass
+}{
+	{"too many requests", ExchangeErrorRateLimit},
+	{"rate limit", ExchangeErrorRateLimit},
+	{"http 429", ExchangeErrorRateLimit},
+	{"http 418", ExchangeErrorRateLimit},
+	{"recvwindow", ExchangeErrorTimestamp},
+	{"timestamp for this request", ExchangeErrorTimestamp},
+	{"precision is over", ExchangeErrorPrecision},
+	{"step size", ExchangeErrorPrecision},
+	{"tick size", ExchangeErrorPrecision},
+	{"lot_size", ExchangeErrorPrecision},
+	{"lot size", ExchangeErrorPrecision},
+	{"invalid size", ExchangeErrorPrecision},
+	{"min_notional", ExchangeErrorMinNotional},
+	{"notional must be no smaller", ExchangeErrorMinNotional},
+	{"minimum value", ExchangeErrorMinNotional},
+	{"greater than max", ExchangeErrorMaxQuantity},
+	{"exceeded upper limit", ExchangeErrorMaxQuantity},
+	{"reduceonly", ExchangeErrorReduceOnly},
+	{"reduce only", ExchangeErrorReduceOnly},
+	{"reduce-only", ExchangeErrorReduceOnly},
+	{"margin is insufficient", ExchangeErrorMarginInsufficient},
+	{"insufficient margin", ExchangeErrorMarginInsufficient},
+	{"insufficient balance", ExchangeErrorMarginInsufficient},
+	{"http 502", ExchangeErrorUnavailable},
+	{"http 503", ExchangeErrorUnavailable},
+	{"http 504", ExchangeErrorUnavailable},
+	{"connection reset", ExchangeErrorUnavailable},
+	{"connection refused", ExchangeErrorUnavailable},
+	{"unexpected eof", ExchangeErrorUnavailable},
+}
+
+var (
+	jsonErrorCode = regexp.MustCompile(`"code"\s*:\s*(-\d+)`)          // Binance-style HTTP bodies (Aster)
+	okxErrorCode  = regexp.MustCompile(`(?:OKX error |\(|; )(5\d{4})`) // Top-level and per-order OKX codes
+)
+
+// ClassifyExchangeError the class of an error returned by an exchange adapter (ExchangeErrorUnknown for errors
+// that are not exchange rejections, e.g. "no long position found")
+func ClassifyExchangeError(err error) ExchangeErrorClass {
+	class, _ := classifyExchangeError(err)
+	return class
+}
+
+// classifyExchangeError the class and exchange code of err: codes first, then message fragments
+func classifyExchangeError(err error) (ExchangeErrorClass, string) {
+	if err == nil {
+		return ExchangeErrorUnknown, ""
+	}
+	var classified *ExchangeError
+	if errors.As(err, &classified) {
+		return classified.Class, classified.Code
+	}
+
+	var binanceErr *common.APIError
+	if errors.As(err, &binanceErr) {
+		if class, ok := binanceErrorClasses[int(binanceErr.Code)]; ok {
+			return class, strconv.FormatInt(binanceErr.Code, 10)
+		}
+	}
+	var bybitErr *bybitError
+	if errors.As(err, &bybitErr) {
+		if class, ok := bybitErrorClasses[bybitErr.Code]; ok {
+			return class, strconv.Itoa(bybitErr.Code)
+		}
+	}
+
+	msg := err.Error()
+	if m := jsonErrorCode.FindStringSubmatch(msg); m != nil {
+		if code, convErr := strconv.Atoi(m[1]); convErr == nil {
+			if class, ok := binanceErrorClasses[code]; ok {
+				return class, m[1]
+			}
+		}
+	}
+	// Per-order codes are more specific than the top-level one, so the last known code wins
+	class, code := ExchangeErrorUnknown, ""
+	for _, m := range okxErrorCode.FindAllStringSubmatch(msg, -1) {
+		if c, ok := okxErrorClasses[m[1]]; ok {
+			class, code = c, m[1]
+		}
+	}
+	if class != ExchangeErrorUnknown {
+		return class, code
+	}
+
+	lower := strings.ToLower(msg)
+	for _, m := range exchangeErrorMessages {
+		if strings.Contains(lower, m.fragment) {
+			return m.class, ""
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ExchangeErrorUnavailable, ""
+	}
+	return ExchangeErrorUnknown, ""
+}
+
+// classifiedError wraps an exchange error with its class (unchanged when unknown or already classified)
+func classifiedError(err error) error {
+	class, code := classifyExchangeError(err)
+	var classified *ExchangeError
+	if class == ExchangeErrorUnknown || errors.As(err, &classified) {
+		return err
+	}
+	return &ExchangeError{Class: class, Code: code, Err: err}
+}
+
+// isMarginInsufficientAPIError whether the exchange rejected an order for lack of margin or balance
+func isMarginInsufficientAPIError(err error) bool {
+	return ClassifyExchangeError(err) == ExchangeErrorMarginInsufficient
+}
+
+// isPositionGoneError whether a close failed because the position is already closed (by another trader on a
+// shared account, a stop fill or the background monitor): the adapter found no position, the exchange rejected
+// the reduce-only order, or Binance rejected the close for margin (-2019: nothing left to reduce)
+func isPositionGoneError(err error) bool {
+	if err == nil {
+		return false
+	}
+	lower := strings.ToLower(err.Error())
+	if strings.Contains(lower, "no long position") || strings.Contains(lower, "no short position") {
+		return true
+	}
+	switch ClassifyExchangeError(err) {
+	case ExchangeErrorReduceOnly:
+		return true
+	case ExchangeErrorMarginInsufficient:
+		return strings.Contains(lower, "-2019")
+	}
+	return false
+}
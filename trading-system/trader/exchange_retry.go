@@ -0,0 +1,271 @@
+package trader
+
+import (
+	"strconv"
+	"time"
+)
+
+// Exchange retry policy: a retryable error is retried up to exchangeRetryAttempts attempts in total, waiting
+// exchangeRetryBackoff before the second attempt (exchangeRateLimitBackoff after a rate limit) and doubling
+// the wait for each further attempt
+const (
+	exchangeRetryAttempts    = 3
+	exchangeRetryBackoff     = 500 * time.Millisecond
+	exchangeRateLimitBackoff = 2 * time.Second
+)
+
+// ClockResyncer optional interface for exchanges whose signed requests carry a timestamp the exchange checks
+// against its own clock
+type ClockResyncer interface {
+	// ResyncClock refreshes the offset between the local and the exchange clock
+	ResyncClock()
+}
+
+// SymbolRulesRefresher optional interface for exchanges that cache symbol step / tick sizes
+type SymbolRulesRefresher interface {
+	// RefreshSymbolRules reloads the symbol's quantity and price filters
+	RefreshSymbolRules(symbol string) error
+}
+
+// exchangeRetry retries exchange calls by error class (see ClassifyExchangeError)
+type exchangeRetry struct {
+	exchange Trader // The adapter, for clock resyncs and symbol rule refreshes
+	sleep    func(time.Duration)
+}
+
+// newExchangeRetry creates the retry policy of an exchange adapter
+func newExchangeRetry(exchange Trader) *exchangeRetry {
+	return &exchangeRetry{exchange: exchange, sleep: time.Sleep}
+}
+
+// read runs a call that changes nothing on the exchange (or can safely be repeated), retrying rate limits,
+// timestamp drift and outages
+func (r *exchangeRetry) read(op, symbol string, call func() error) error {
+	return r.run(op, symbol, false, call, nil)
+}
+
+// order runs a call that places an order, retrying rate limits and timestamp drift (the exchange rejected the
+// request, nothing was placed) and precision errors once after refreshing the symbol's rules and re-rounding with
+// reround (nil = nothing to re-round). Network failures and timeouts are not retried: the order may have reached
+// the exchange, and sending it again could open the position twice
+func (r *exchangeRetry) order(op, symbol string, call func() error, reround func()) error {
+	return r.run(op, symbol, true, call, reround)
+}
+
+// run calls call until it succeeds, fails with an error not worth retrying or runs out of attempts; the final
+// error carries its class
+func (r *exchangeRetry) run(op, symbol string, placesOrder bool, call func() error, reround func()) error {
+	delay := exchangeRetryBackoff
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || r == nil {
+			return classifiedError(err)
+		}
+		class, code := classifyExchangeError(err)
+		if attempt >= exchangeRetryAttempts {
+			return classifiedError(err)
+		}
+
+		wait := delay
+		switch class {
+		case ExchangeErrorRateLimit:
+			if wait < exchangeRateLimitBackoff {
+				wait = exchangeRateLimitBackoff
+			}
+		case ExchangeErrorTimestamp:
+			if resyncer, ok := r.exchange.(ClockResyncer); ok {
+				resyncer.ResyncClock()
+			}
+		case ExchangeErrorPrecision:
+			if refreshed || symbol == "" {
+				return classifiedError(err)
+			}
+			refreshed = true
+			if refresher, ok := r.exchange.(SymbolRulesRefresher); ok {
+				if refreshErr := refresher.RefreshSymbolRules(symbol); refreshErr != nil {
+					traderLog.Warnf("  ⚠ Failed to refresh %s symbol rules: %v", symbol, refreshErr)
+				}
+			}
+			if reround != nil {
+				reround()
+			}
+			wait = 0
+		case ExchangeErrorUnavailable:
+			if placesOrder {
+				return classifiedError(err)
+			}
+		default:
+			return classifiedError(err)
+		}
+
+		traderLog.Warnf("  🔁 %s %s failed (%s%s), attempt %d/%d in %v: %v", op, symbol, class, codeSuffix(code),
+			attempt+1, exchangeRetryAttempts, wait, err)
+		if wait > 0 {
+			r.sleep(wait)
+		}
+		delay *= 2
+	}
+}
+
+// retryTrader retries the calls of the trader it wraps by error class: balance and position reads, and the
+// orders that open, close and protect positions
+type retryTrader struct {
+	Trader
+	retry *exchangeRetry
+}
+
+// newRetryTrader wraps t with the retry policy of the exchange adapter (t is the adapter, or the dry run executor
+// in front of it)
+func newRetryTrader(t Trader, exchange Trader) *retryTrader {
+	return &retryTrader{Trader: t, retry: newExchangeRetry(exchange)}
+}
+
+func (rt *retryTrader) unwrap() Trader {
+	return rt.Trader
+}
+
+// GetBalance reads the account balance, retrying rate limits, clock drift and outages
+func (rt *retryTrader) GetBalance() (*Balance, error) {
+	var balance *Balance
+	err := rt.retry.read("balance", "", func() (err error) {
+		balance, err = rt.Trader.GetBalance()
+		return err
+	})
+	return balance, err
+}
+
+// GetPositions reads the open positions, retrying rate limits, clock drift and outages
+func (rt *retryTrader) GetPositions() ([]Position, error) {
+	var positions []Position
+	err := rt.retry.read("positions", "", func() (err error) {
+		positions, err = rt.Trader.GetPositions()
+		return err
+	})
+	return positions, err
+}
+
+// OpenLong opens a long position, re-rounding the quantity after a precision error
+func (rt *retryTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	var order *Order
+	err := rt.retry.order("open_long", symbol, func() (err error) {
+		order, err = rt.Trader.OpenLong(symbol, quantity, leverage)
+		return err
+	}, func() { quantity = rt.retry.roundedQuantity(symbol, quantity) })
+	return order, err
+}
+
+// OpenShort opens a short position, re-rounding the quantity after a precision error
+func (rt *retryTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	var order *Order
+	err := rt.retry.order("open_short", symbol, func() (err error) {
+		order, err = rt.Trader.OpenShort(symbol, quantity, leverage)
+		return err
+	}, func() { quantity = rt.retry.roundedQuantity(symbol, quantity) })
+	return order, err
+}
+
+// CloseLong closes (part of) a long position
+func (rt *retryTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
+	var order *Order
+	err := rt.retry.order("close_long", symbol, func() (err error) {
+		order, err = rt.Trader.CloseLong(symbol, quantity)
+		return err
+	}, rt.reroundClose(symbol, &quantity))
+	return order, err
+}
+
+// CloseShort closes (part of) a short position
+func (rt *retryTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
+	var order *Order
+	err := rt.retry.order("close_short", symbol, func() (err error) {
+		order, err = rt.Trader.CloseShort(symbol, quantity)
+		return err
+	}, rt.reroundClose(symbol, &quantity))
+	return order, err
+}
+
+// SetStopLoss places a stop loss order, re-rounding the quantity after a precision error
+func (rt *retryTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	return rt.retry.order("stop_loss", symbol, func() error {
+		return rt.Trader.SetStopLoss(symbol, positionSide, quantity, stopPrice)
+	}, func() { quantity = rt.retry.roundedQuantity(symbol, quantity) })
+}
+
+// SetTakeProfit places a take profit order, re-rounding the quantity after a precision error
+func (rt *retryTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return rt.retry.order("take_profit", symbol, func() error {
+		return rt.Trader.SetTakeProfit(symbol, positionSide, quantity, takeProfitPrice)
+	}, func() { quantity = rt.retry.roundedQuantity(symbol, quantity) })
+}
+
+// OpenLimit places a limit entry, re-rounding the quantity after a precision error
+func (rt *retryTrader) OpenLimit(symbol, positionSide string, quantity float64, leverage int, price float64, postOnly bool) (*Order, error) {
+	lt, err := limitOrdersOf(rt.Trader)
+	if err != nil {
+		return nil, err
+	}
+	var order *Order
+	err = rt.retry.order("open_limit", symbol, func() (err error) {
+		order, err = lt.OpenLimit(symbol, positionSide, quantity, leverage, price, postOnly)
+		return err
+	}, func() { quantity = rt.retry.roundedQuantity(symbol, quantity) })
+	return order, err
+}
+
+// GetOrder reads an order's status and fills, retrying rate limits, clock drift and outages
+func (rt *retryTrader) GetOrder(symbol string, orderID int64) (*Order, error) {
+	lt, err := limitOrdersOf(rt.Trader)
+	if err != nil {
+		return nil, err
+	}
+	var order *Order
+	err = rt.retry.read("order", symbol, func() (err error) {
+		order, err = lt.GetOrder(symbol, orderID)
+		return err
+	})
+	return order, err
+}
+
+// CancelOrder cancels one resting order, retrying rate limits, clock drift and outages (a repeated cancel is
+// harmless)
+func (rt *retryTrader) CancelOrder(symbol string, orderID int64) error {
+	lt, err := limitOrdersOf(rt.Trader)
+	if err != nil {
+		return err
+	}
+	return rt.retry.read("cancel_order", symbol, func() error {
+		return lt.CancelOrder(symbol, orderID)
+	})
+}
+
+// reroundClose re-rounds a partial close's quantity after a precision error (a full close, quantity 0, is sized
+// by the adapter)
+func (rt *retryTrader) reroundClose(symbol string, quantity *float64) func() {
+	return func() {
+		if *quantity > 0 {
+			*quantity = rt.retry.roundedQuantity(symbol, *quantity)
+		}
+	}
+}
+
+// roundedQuantity the quantity rounded to the symbol's step size by the adapter (unchanged if it cannot format it)
+func (r *exchangeRetry) roundedQuantity(symbol string, quantity float64) float64 {
+	formatted, err := r.exchange.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return quantity
+	}
+	rounded, err := strconv.ParseFloat(formatted, 64)
+	if err != nil || rounded <= 0 {
+		return quantity
+	}
+	return rounded
+}
+
+// codeSuffix ", code X" for log lines ("" without a code)
+func codeSuffix(code string) string {
+	if code == "" {
+		return ""
+	}
+	return ", code " + code
+}
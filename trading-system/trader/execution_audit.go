@@ -0,0 +1,355 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"lia/logger"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client order ID kinds (which code path placed the order)
+const (
+	ClientOrderKindOpen       = "o"
+	ClientOrderKindClose      = "c"
+	ClientOrderKindStopLoss   = "sl"
+	ClientOrderKindTakeProfit = "tp"
+)
+
+// clientOrderPrefix marks orders placed by this system (Binance allows 36 chars of [.A-Z:/a-z0-9_-])
+const clientOrderPrefix = "lia"
+
+// Execution audit mismatch types
+const (
+	AuditMissingOnExchange = "missing_on_exchange"     // Logged as executed but no such order on the exchange
+	AuditUnloggedOrder     = "unlogged_exchange_order" // Filled on the exchange with no corresponding decision
+	AuditQuantityMismatch  = "quantity_mismatch"       // Logged quantity differs from the executed quantity
+	AuditPriceMismatch     = "price_mismatch"          // Logged price differs from the average fill price
+	AuditUntrackedAction   = "untracked_action"        // Logged without an order reference (logged before order tagging)
+)
+
+// Audit tolerances (logged prices are decision-time market prices, so some slippage is expected)
+const (
+	auditQuantityTolerance = 0.01  // 1%
+	auditPriceTolerance    = 0.005 // 0.5%
+)
+
+// maxAuditRange Binance order history queries are limited to 7 days
+const maxAuditRange = 7 * 24 * time.Hour
+
+// ErrOrderHistoryUnsupported returned when the exchange cannot report order history
+var ErrOrderHistoryUnsupported = errors.New("order history is not supported on this exchange")
+
+// ExchangeOrder an order as reported by the exchange
+type ExchangeOrder struct {
+	Symbol        string    `json:"symbol"`
+	OrderID       int64     `json:"order_id"`
+	ClientOrderID string    `json:"client_order_id"`
+	Side          string    `json:"side"`          // BUY / SELL
+	PositionSide  string    `json:"position_side"` // LONG / SHORT / BOTH
+	Type          string    `json:"type"`          // MARKET / STOP_MARKET / TAKE_PROFIT_MARKET ...
+	Status        string    `json:"status"`
+	ExecutedQty   float64   `json:"executed_qty"`
+	StopPrice     float64   `json:"stop_price,omitempty"` // Trigger price of stop / take profit orders
+	AvgPrice      float64   `json:"avg_price"`
+	Time          time.Time `json:"time"`
+}
+
+// OrderHistoryProvider optional interface for exchanges that can list past orders
+type OrderHistoryProvider interface {
+	// GetOrderHistory returns the symbol's orders between start and end
+	GetOrderHistory(symbol string, start, end time.Time) ([]ExchangeOrder, error)
+}
+
+// ClientOrderTagger optional interface for exchanges that accept client order IDs
+type ClientOrderTagger interface {
+	// SetClientOrderTag sets the trader tag embedded in every client order ID
+	SetClientOrderTag(tag string)
+}
+
+// ClientOrderTag derives a short, stable client order ID tag from a trader ID
+func ClientOrderTag(traderID string) string {
+	h := fnv.New32a()
+	h.Write([]byte(traderID))
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// newClientOrderID builds a client order ID: lia_<tag>_<kind>_<base36 unix nanos> (at most 32 chars)
+func newClientOrderID(tag, kind string) string {
+	return newClientOrderIDAt(tag, kind, time.Now().UnixNano())
+}
+
+// newClientOrderIDAt builds a client order ID with the given unique nonce in place of the unix nanos (paper
+// orders use their order ID, so simulated runs produce the same IDs every time)
+func newClientOrderIDAt(tag, kind string, nonce int64) string {
+	if tag == "" {
+		tag = "0"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s", clientOrderPrefix, tag, kind, strconv.FormatInt(nonce, 36))
+}
+
+// parseClientOrderID extracts the tag and kind from a client order ID built by newClientOrderID
+func parseClientOrderID(id string) (tag, kind string, ok bool) {
+	parts := strings.Split(id, "_")
+	if len(parts) != 4 || parts[0] != clientOrderPrefix {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// AuditMismatch a discrepancy between the decision log and the exchange
+type AuditMismatch struct {
+	Type          string    `json:"type"`
+	Symbol        string    `json:"symbol"`
+	Action        string    `json:"action,omitempty"`       // Logged decision action
+	CycleNumber   int       `json:"cycle_number,omitempty"` // Cycle the action was logged in
+	OrderID       int64     `json:"order_id,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	Time          time.Time `json:"time"`
+	LoggedQty     float64   `json:"logged_qty,omitempty"`
+	ExchangeQty   float64   `json:"exchange_qty,omitempty"`
+	LoggedPrice   float64   `json:"logged_price,omitempty"`
+	ExchangePrice float64   `json:"exchange_price,omitempty"`
+	Detail        string    `json:"detail"`
+}
+
+// ExecutionAudit result of reconciling logged decision actions with exchange order history
+type ExecutionAudit struct {
+	TraderID        string            `json:"trader_id"`
+	Exchange        string            `json:"exchange"`
+	Start           time.Time         `json:"start"`
+	End             time.Time         `json:"end"`
+	Symbols         []string          `json:"symbols"`
+	LoggedActions   int               `json:"logged_actions"`   // Successful order-placing actions in range
+	ExchangeOrders  int               `json:"exchange_orders"`  // Filled orders attributed to this trader (or untagged)
+	Matched         int               `json:"matched"`          // Logged actions found on the exchange
+	ProtectionFills []ExchangeOrder   `json:"protection_fills"` // This trader's stop loss / take profit fills (no decision expected)
+	Mismatches      []AuditMismatch   `json:"mismatches"`
+	Summary         map[string]int    `json:"summary"`                 // Mismatch count by type
+	SymbolErrors    map[string]string `json:"symbol_errors,omitempty"` // Symbols whose order history could not be fetched
+}
+
+// isOrderAction whether a logged action places an order on the exchange
+func isOrderAction(action string) bool {
+	switch action {
+	case "open_long", "open_short", "add_long", "add_short", "close_long", "close_short", "reduce_size":
+		return true
+	}
+	return false
+}
+
+// AuditExecutions reconciles this trader's logged decision actions with the exchange's order history.
+// Symbols come from the logged actions, current positions and extraSymbols (the exchange is queried per symbol).
+func (at *AutoTrader) AuditExecutions(start, end time.Time, extraSymbols []string) (*ExecutionAudit, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	if end.Sub(start) > maxAuditRange {
+		return nil, fmt.Errorf("audit range is limited to %.0f days", maxAuditRange.Hours()/24)
+	}
+
+	provider, ok := baseTrader(at.trader).(OrderHistoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w (%s)", ErrOrderHistoryUnsupported, at.exchange)
+	}
+	if at.decisionLogger == nil {
+		return nil, fmt.Errorf("decision logger not initialized")
+	}
+
+	logged, err := at.decisionLogger.GetActionsInRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load decision actions: %w", err)
+	}
+
+	// Collect symbols to query
+	symbolSet := make(map[string]bool)
+	var actions []logger.LoggedAction
+	for _, a := range logged {
+		if !a.Success || !isOrderAction(a.Action) {
+			continue
+		}
+		actions = append(actions, a)
+		symbolSet[a.Symbol] = true
+	}
+	if positions, err := at.trader.GetPositions(); err == nil {
+		for _, pos := range positions {
+			symbolSet[pos.Symbol] = true
+		}
+	}
+	for _, symbol := range extraSymbols {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbolSet[symbol] = true
+		}
+	}
+
+	audit := &ExecutionAudit{
+		TraderID:        at.id,
+		Exchange:        at.exchange,
+		Start:           start,
+		End:             end,
+		LoggedActions:   len(actions),
+		ProtectionFills: []ExchangeOrder{},
+		Mismatches:      []AuditMismatch{},
+		Summary:         make(map[string]int),
+	}
+	for symbol := range symbolSet {
+		audit.Symbols = append(audit.Symbols, symbol)
+	}
+	sort.Strings(audit.Symbols)
+
+	// Fetch filled exchange orders, skipping orders tagged by other traders on a shared account
+	ownTag := ClientOrderTag(at.id)
+	byClientID := make(map[string]ExchangeOrder)
+	byOrderID := make(map[int64]ExchangeOrder)
+	var filled []ExchangeOrder
+	for _, symbol := range audit.Symbols {
+		orders, err := provider.GetOrderHistory(symbol, start, end)
+		if err != nil {
+			if audit.SymbolErrors == nil {
+				audit.SymbolErrors = make(map[string]string)
+			}
+			audit.SymbolErrors[symbol] = err.Error()
+			continue
+		}
+		for _, o := range orders {
+			if o.ExecutedQty <= 0 {
+				continue
+			}
+			if tag, _, ok := parseClientOrderID(o.ClientOrderID); ok && tag != ownTag {
+				continue
+			}
+			filled = append(filled, o)
+			byOrderID[o.OrderID] = o
+			if o.ClientOrderID != "" {
+				byClientID[o.ClientOrderID] = o
+			}
+		}
+	}
+	audit.ExchangeOrders = len(filled)
+
+	// Match logged actions to exchange orders (client order ID first, then exchange order ID)
+	matchedOrders := make(map[int64]bool)
+	for _, a := range actions {
+		if audit.SymbolErrors[a.Symbol] != "" {
+			continue // Can't tell either way
+		}
+		if a.ClientOrderID == "" && a.OrderID == 0 {
+			audit.addMismatch(AuditMismatch{
+				Type:   AuditUntrackedAction,
+				Detail: "action has no order ID or client order ID - cannot be matched",
+			}, &a, nil)
+			continue
+		}
+
+		var order ExchangeOrder
+		found := false
+		if a.ClientOrderID != "" {
+			order, found = byClientID[a.ClientOrderID]
+		}
+		if !found && a.OrderID != 0 {
+			order, found = byOrderID[a.OrderID]
+		}
+		if !found {
+			audit.addMismatch(AuditMismatch{
+				Type:   AuditMissingOnExchange,
+				Detail: "logged as executed but no filled order with this ID exists on the exchange",
+			}, &a, nil)
+			continue
+		}
+
+		audit.Matched++
+		matchedOrders[order.OrderID] = true
+		at.compareExecution(audit, &a, order)
+	}
+
+	// Exchange fills with no corresponding decision
+	for _, o := range filled {
+		if matchedOrders[o.OrderID] {
+			continue
+		}
+		_, kind, tagged := parseClientOrderID(o.ClientOrderID)
+		switch {
+		case tagged && (kind == ClientOrderKindStopLoss || kind == ClientOrderKindTakeProfit):
+			audit.ProtectionFills = append(audit.ProtectionFills, o)
+			continue
+		case tagged && kind == ClientOrderKindClose:
+			audit.addMismatch(AuditMismatch{Type: AuditUnloggedOrder,
+				Detail: "close placed by this trader outside an AI decision (profit monitor, auto take-profit or manual close API)"}, nil, &o)
+		case tagged:
+			audit.addMismatch(AuditMismatch{Type: AuditUnloggedOrder,
+				Detail: "order placed by this trader but missing from the decision log"}, nil, &o)
+		default:
+			audit.addMismatch(AuditMismatch{Type: AuditUnloggedOrder,
+				Detail: fmt.Sprintf("untagged %s %s order - placed manually, by other software, or before order tagging", o.Type, o.Side)}, nil, &o)
+		}
+	}
+
+	sort.Slice(audit.Mismatches, func(i, j int) bool {
+		return audit.Mismatches[i].Time.Before(audit.Mismatches[j].Time)
+	})
+	return audit, nil
+}
+
+// compareExecution reports quantity and price discrepancies between a logged action and its fill
+func (at *AutoTrader) compareExecution(audit *ExecutionAudit, a *logger.LoggedAction, order ExchangeOrder) {
+	// Logged open quantities are pre-rounding; normalize to the exchange's step size when possible
+	loggedQty := a.Quantity
+	if formatted, err := at.trader.FormatQuantity(a.Symbol, loggedQty); err == nil {
+		if q, err := strconv.ParseFloat(formatted, 64); err == nil {
+			loggedQty = q
+		}
+	}
+	if loggedQty > 0 && relativeDiff(loggedQty, order.ExecutedQty) > auditQuantityTolerance {
+		audit.addMismatch(AuditMismatch{
+			Type: AuditQuantityMismatch,
+			Detail: fmt.Sprintf("logged %.6f, executed %.6f (%.2f%% difference)",
+				loggedQty, order.ExecutedQty, relativeDiff(loggedQty, order.ExecutedQty)*100),
+		}, a, &order)
+	}
+
+	if a.Price > 0 && order.AvgPrice > 0 && relativeDiff(a.Price, order.AvgPrice) > auditPriceTolerance {
+		audit.addMismatch(AuditMismatch{
+			Type: AuditPriceMismatch,
+			Detail: fmt.Sprintf("logged %.6f, average fill %.6f (%.2f%% difference)",
+				a.Price, order.AvgPrice, relativeDiff(a.Price, order.AvgPrice)*100),
+		}, a, &order)
+	}
+}
+
+// addMismatch fills in the logged action and exchange order details and counts the mismatch
+func (audit *ExecutionAudit) addMismatch(m AuditMismatch, a *logger.LoggedAction, order *ExchangeOrder) {
+	if a != nil {
+		m.Symbol = a.Symbol
+		m.Action = a.Action
+		m.CycleNumber = a.CycleNumber
+		m.OrderID = a.OrderID
+		m.ClientOrderID = a.ClientOrderID
+		m.Time = a.Timestamp
+		m.LoggedQty = a.Quantity
+		m.LoggedPrice = a.Price
+	}
+	if order != nil {
+		m.Symbol = order.Symbol
+		m.OrderID = order.OrderID
+		m.ClientOrderID = order.ClientOrderID
+		m.ExchangeQty = order.ExecutedQty
+		m.ExchangePrice = order.AvgPrice
+		if m.Time.IsZero() {
+			m.Time = order.Time
+		}
+	}
+	audit.Mismatches = append(audit.Mismatches, m)
+	audit.Summary[m.Type]++
+}
+
+// relativeDiff |a-b| relative to b
+func relativeDiff(a, b float64) float64 {
+	if b == 0 {
+		return math.Abs(a)
+	}
+	return math.Abs(a-b) / math.Abs(b)
+}
@@ -0,0 +1,129 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ExchangePinger optional interface for exchanges that can check API reachability without
+// touching the account (used by the /health readiness probe)
+type ExchangePinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks Binance Futures API reachability
+func (t *FuturesTrader) Ping(ctx context.Context) error {
+	return t.client.NewPingService().Do(ctx)
+}
+
+// Ping checks Aster API reachability
+func (t *AsterTrader) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/fapi/v1/ping", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Ping checks OKX API reachability
+func (t *OKXTrader) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/api/v5/public/time", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Ping checks Bybit API reachability
+func (t *BybitTrader) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/v5/market/time", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Ping checks Hyperliquid API reachability
+func (t *HyperliquidTrader) Ping(ctx context.Context) error {
+	_, err := t.exchange.Info().AllMids(ctx)
+	return err
+}
+
+// GetExchange gets the trading platform name
+func (at *AutoTrader) GetExchange() string {
+	return at.exchange
+}
+
+// PingExchange checks the exchange API. Returns false if the exchange has no reachability check (paper trading)
+func (at *AutoTrader) PingExchange(ctx context.Context) (bool, error) {
+	pinger, ok := baseTrader(at.trader).(ExchangePinger)
+	if !ok {
+		return false, nil
+	}
+	return true, pinger.Ping(ctx)
+}
+
+// CheckExchangeAccount checks the API credentials can read the account (a signed balance request). Returns false
+// if the exchange has no remote account (paper trading)
+func (at *AutoTrader) CheckExchangeAccount(ctx context.Context) (bool, error) {
+	if _, ok := baseTrader(at.trader).(ExchangePinger); !ok {
+		return false, nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := at.trader.GetBalance()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return true, err
+	case <-ctx.Done():
+		return true, fmt.Errorf("balance request: %w", ctx.Err())
+	}
+}
+
+// AccountKey the exchange account the trader trades on (traders with the same key share one account)
+func (at *AutoTrader) AccountKey() string {
+	return at.accountKey
+}
+
+// LastSuccessfulCycle when the trader's latest cycle completed without error (zero = none yet)
+func (at *AutoTrader) LastSuccessfulCycle() time.Time {
+	return at.cycles.lastSucceeded()
+}
+
+// GetAIEndpoint gets the AI provider base URL (the readiness probe checks each endpoint once)
+func (at *AutoTrader) GetAIEndpoint() string {
+	return at.mcpClient.BaseURL
+}
+
+// PingAI checks the AI provider API
+func (at *AutoTrader) PingAI(ctx context.Context) error {
+	return at.mcpClient.Ping(ctx)
+}
@@ -4,13 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"lia/logging"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sonirico/go-hyperliquid"
 )
 
+// hyperliquidLog logs the Hyperliquid adapter
+var hyperliquidLog = logging.Component("hyperliquid")
+
 // HyperliquidTrader Hyperliquid交易器
 type HyperliquidTrader struct {
 	exchange   *hyperliquid.Exchange
@@ -54,7 +61,7 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 		nil,        // SpotMeta will be fetched automatically
 	)
 
-	log.Printf("✓ Hyperliquid交易器初始化成功 (testnet=%v, wallet=%s)", testnet, walletAddr)
+	hyperliquidLog.Infof("✓ Hyperliquid交易器初始化成功 (testnet=%v, wallet=%s)", testnet, walletAddr)
 
 	// 获取meta信息（包含精度等配置）
 	meta, err := exchange.Info().Meta(ctx)
@@ -71,23 +78,20 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 }
 
 // GetBalance 获取账户余额
-func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
-	log.Printf("🔄 正在调用Hyperliquid API获取账户余额...")
+func (t *HyperliquidTrader) GetBalance() (*Balance, error) {
+	hyperliquidLog.Infof("🔄 正在调用Hyperliquid API获取账户余额...")
 
 	// 获取账户状态
 	accountState, err := t.exchange.Info().UserState(t.ctx, t.walletAddr)
 	if err != nil {
-		log.Printf("❌ Hyperliquid API调用失败: %v", err)
+		hyperliquidLog.Errorf("❌ Hyperliquid API调用失败: %v", err)
 		return nil, fmt.Errorf("获取账户信息失败: %w", err)
 	}
 
-	// 解析余额信息（MarginSummary字段都是string）
-	result := make(map[string]interface{})
-
 	// 🔍 调试：打印API返回的完整CrossMarginSummary结构
 	summaryJSON, _ := json.MarshalIndent(accountState.MarginSummary, "  ", "  ")
-	log.Printf("🔍 [DEBUG] Hyperliquid API CrossMarginSummary完整数据:")
-	log.Printf("%s", string(summaryJSON))
+	hyperliquidLog.Infof("🔍 [DEBUG] Hyperliquid API CrossMarginSummary完整数据:")
+	hyperliquidLog.Infof("%s", string(summaryJSON))
 
 	accountValue, _ := strconv.ParseFloat(accountState.MarginSummary.AccountValue, 64)
 	totalMarginUsed, _ := strconv.ParseFloat(accountState.MarginSummary.TotalMarginUsed, 64)
@@ -107,29 +111,31 @@ func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
 	// 需要返回"不包含未实现盈亏的钱包余额"
 	walletBalanceWithoutUnrealized := accountValue - totalUnrealizedPnl
 
-	result["totalWalletBalance"] = walletBalanceWithoutUnrealized // 钱包余额（不含未实现盈亏）
-	result["availableBalance"] = accountValue - totalMarginUsed   // 可用余额（总净值 - 占用保证金）
-	result["totalUnrealizedProfit"] = totalUnrealizedPnl          // 未实现盈亏
+	result := &Balance{
+		WalletBalance:    walletBalanceWithoutUnrealized, // 钱包余额（不含未实现盈亏）
+		AvailableBalance: accountValue - totalMarginUsed, // 可用余额（总净值 - 占用保证金）
+		UnrealizedProfit: totalUnrealizedPnl,             // 未实现盈亏
+	}
 
-	log.Printf("✓ Hyperliquid 账户: 总净值=%.2f (钱包%.2f+未实现%.2f), 可用=%.2f, 保证金占用=%.2f",
+	hyperliquidLog.Infof("✓ Hyperliquid 账户: 总净值=%.2f (钱包%.2f+未实现%.2f), 可用=%.2f, 保证金占用=%.2f",
 		accountValue,
 		walletBalanceWithoutUnrealized,
 		totalUnrealizedPnl,
-		result["availableBalance"],
+		result.AvailableBalance,
 		totalMarginUsed)
 
 	return result, nil
 }
 
 // GetPositions 获取所有持仓
-func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *HyperliquidTrader) GetPositions() ([]Position, error) {
 	// 获取账户状态
 	accountState, err := t.exchange.Info().UserState(t.ctx, t.walletAddr)
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	var result []map[string]interface{}
+	var result []Position
 
 	// 遍历所有持仓
 	for _, assetPos := range accountState.AssetPositions {
@@ -142,19 +148,16 @@ func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
 			continue // 跳过无持仓的
 		}
 
-		posMap := make(map[string]interface{})
-
 		// 标准化symbol格式（Hyperliquid使用如"BTC"，我们转换为"BTCUSDT"）
-		symbol := position.Coin + "USDT"
-		posMap["symbol"] = symbol
+		pos := Position{Symbol: position.Coin + "USDT"}
 
 		// 持仓数量和方向
 		if posAmt > 0 {
-			posMap["side"] = "long"
-			posMap["positionAmt"] = posAmt
+			pos.Side = "long"
+			pos.Quantity = posAmt
 		} else {
-			posMap["side"] = "short"
-			posMap["positionAmt"] = -posAmt // 转为正数
+			pos.Side = "short"
+			pos.Quantity = -posAmt // 转为正数
 		}
 
 		// 价格信息（EntryPx和LiquidationPx是指针类型）
@@ -175,13 +178,13 @@ func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
 			markPrice = positionValue / absFloat(posAmt)
 		}
 
-		posMap["entryPrice"] = entryPrice
-		posMap["markPrice"] = markPrice
-		posMap["unRealizedProfit"] = unrealizedPnl
-		posMap["leverage"] = float64(position.Leverage.Value)
-		posMap["liquidationPrice"] = liquidationPx
+		pos.EntryPrice = entryPrice
+		pos.MarkPrice = markPrice
+		pos.UnrealizedProfit = unrealizedPnl
+		pos.Leverage = position.Leverage.Value
+		pos.LiquidationPrice = liquidationPx
 
-		result = append(result, posMap)
+		result = append(result, pos)
 	}
 
 	return result, nil
@@ -198,15 +201,15 @@ func (t *HyperliquidTrader) SetLeverage(symbol string, leverage int) error {
 		return fmt.Errorf("设置杠杆失败: %w", err)
 	}
 
-	log.Printf("  ✓ %s 杠杆已切换为 %dx", symbol, leverage)
+	hyperliquidLog.Infof("  ✓ %s 杠杆已切换为 %dx", symbol, leverage)
 	return nil
 }
 
 // OpenLong 开多仓
-func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
 	// 先取消该币种的所有委托单
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败: %v", err)
+		hyperliquidLog.Warnf("  ⚠ 取消旧委托单失败: %v", err)
 	}
 
 	// 设置杠杆
@@ -225,11 +228,11 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	hyperliquidLog.Infof("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 1.01)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*1.01, aggressivePrice)
+	hyperliquidLog.Infof("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*1.01, aggressivePrice)
 
 	// 创建市价买入订单（使用IOC limit order with aggressive price）
 	order := hyperliquid.CreateOrderRequest{
@@ -250,21 +253,18 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 		return nil, fmt.Errorf("开多仓失败: %w", err)
 	}
 
-	log.Printf("✓ 开多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	hyperliquidLog.Infof("✓ 开多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0 // Hyperliquid没有返回order ID
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &Order{Symbol: symbol, Status: "FILLED"} // Hyperliquid没有返回order ID
 
 	return result, nil
 }
 
 // OpenShort 开空仓
-func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
 	// 先取消该币种的所有委托单
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败: %v", err)
+		hyperliquidLog.Warnf("  ⚠ 取消旧委托单失败: %v", err)
 	}
 
 	// 设置杠杆
@@ -283,11 +283,11 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	hyperliquidLog.Infof("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 0.99)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*0.99, aggressivePrice)
+	hyperliquidLog.Infof("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*0.99, aggressivePrice)
 
 	// 创建市价卖出订单
 	order := hyperliquid.CreateOrderRequest{
@@ -308,18 +308,15 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 		return nil, fmt.Errorf("开空仓失败: %w", err)
 	}
 
-	log.Printf("✓ 开空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	hyperliquidLog.Infof("✓ 开空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &Order{Symbol: symbol, Status: "FILLED"}
 
 	return result, nil
 }
 
 // CloseLong 平多仓
-func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -327,11 +324,8 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "long" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		if pos, ok := findPosition(positions, symbol, "long"); ok {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
@@ -350,11 +344,11 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	hyperliquidLog.Infof("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 0.99)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*0.99, aggressivePrice)
+	hyperliquidLog.Infof("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*0.99, aggressivePrice)
 
 	// 创建平仓订单（卖出 + ReduceOnly）
 	order := hyperliquid.CreateOrderRequest{
@@ -375,23 +369,20 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 		return nil, fmt.Errorf("平多仓失败: %w", err)
 	}
 
-	log.Printf("✓ 平多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	hyperliquidLog.Infof("✓ 平多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
 
 	// 平仓后取消该币种的所有挂单
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+		hyperliquidLog.Warnf("  ⚠ 取消挂单失败: %v", err)
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &Order{Symbol: symbol, Status: "FILLED"}
 
 	return result, nil
 }
 
 // CloseShort 平空仓
-func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -399,11 +390,8 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "short" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		if pos, ok := findPosition(positions, symbol, "short"); ok {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
@@ -422,11 +410,11 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	hyperliquidLog.Infof("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 1.01)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*1.01, aggressivePrice)
+	hyperliquidLog.Infof("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*1.01, aggressivePrice)
 
 	// 创建平仓订单（买入 + ReduceOnly）
 	order := hyperliquid.CreateOrderRequest{
@@ -447,17 +435,14 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 		return nil, fmt.Errorf("平空仓失败: %w", err)
 	}
 
-	log.Printf("✓ 平空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	hyperliquidLog.Infof("✓ 平空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
 
 	// 平仓后取消该币种的所有挂单
 	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+		hyperliquidLog.Warnf("  ⚠ 取消挂单失败: %v", err)
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &Order{Symbol: symbol, Status: "FILLED"}
 
 	return result, nil
 }
@@ -477,15 +462,77 @@ func (t *HyperliquidTrader) CancelAllOrders(symbol string) error {
 		if order.Coin == coin {
 			_, err := t.exchange.Cancel(t.ctx, coin, order.Oid)
 			if err != nil {
-				log.Printf("  ⚠ 取消订单失败 (oid=%d): %v", order.Oid, err)
+				hyperliquidLog.Warnf("  ⚠ 取消订单失败 (oid=%d): %v", order.Oid, err)
 			}
 		}
 	}
 
-	log.Printf("  ✓ 已取消 %s 的所有挂单", symbol)
+	hyperliquidLog.Infof("  ✓ 已取消 %s 的所有挂单", symbol)
 	return nil
 }
 
+// GetOrderHistory returns the symbol's filled orders between start and end, built from the account's fills
+// (Hyperliquid reports fills, not orders, and this trader sets no client order IDs)
+func (t *HyperliquidTrader) GetOrderHistory(symbol string, start, end time.Time) ([]ExchangeOrder, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+	endMs := end.UnixMilli()
+	fills, err := t.exchange.Info().UserFillsByTime(t.ctx, t.walletAddr, start.UnixMilli(), &endMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fills for %s: %w", symbol, err)
+	}
+
+	byOrder := make(map[int64]*ExchangeOrder)
+	var orders []*ExchangeOrder
+	for _, fill := range fills {
+		if fill.Coin != coin {
+			continue
+		}
+		size, _ := strconv.ParseFloat(fill.Size, 64)
+		price, _ := strconv.ParseFloat(fill.Price, 64)
+		o, ok := byOrder[fill.Oid]
+		if !ok {
+			o = &ExchangeOrder{
+				Symbol:       symbol,
+				OrderID:      fill.Oid,
+				Side:         "BUY",
+				PositionSide: "BOTH",
+				Type:         "LIMIT",
+				Status:       OrderStatusFilled,
+			}
+			if fill.Side == "A" {
+				o.Side = "SELL"
+			}
+			// Dir is "Open Long", "Close Short", ... ("Long > Short" flips stay BOTH)
+			if !strings.Contains(fill.Dir, ">") {
+				if strings.HasSuffix(fill.Dir, "Long") {
+					o.PositionSide = "LONG"
+				} else if strings.HasSuffix(fill.Dir, "Short") {
+					o.PositionSide = "SHORT"
+				}
+			}
+			if fill.Crossed {
+				o.Type = "MARKET" // Taker fill
+			}
+			byOrder[fill.Oid] = o
+			orders = append(orders, o)
+		}
+		if total := o.ExecutedQty + size; total > 0 {
+			o.AvgPrice = (o.AvgPrice*o.ExecutedQty + price*size) / total
+		}
+		o.ExecutedQty += size
+		if fillTime := time.UnixMilli(fill.Time); fillTime.After(o.Time) {
+			o.Time = fillTime
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Time.Before(orders[j].Time) })
+	result := make([]ExchangeOrder, 0, len(orders))
+	for _, o := range orders {
+		result = append(result, *o)
+	}
+	return result, nil
+}
+
 // GetMarketPrice 获取市场价格
 func (t *HyperliquidTrader) GetMarketPrice(symbol string) (float64, error) {
 	coin := convertSymbolToHyperliquid(symbol)
@@ -541,7 +588,7 @@ func (t *HyperliquidTrader) SetStopLoss(symbol string, positionSide string, quan
 		return fmt.Errorf("设置止损失败: %w", err)
 	}
 
-	log.Printf("  止损价设置: %.4f", roundedStopPrice)
+	hyperliquidLog.Infof("  止损价设置: %.4f", roundedStopPrice)
 	return nil
 }
 
@@ -578,7 +625,7 @@ func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, qu
 		return fmt.Errorf("设置止盈失败: %w", err)
 	}
 
-	log.Printf("  止盈价设置: %.4f", roundedTakeProfitPrice)
+	hyperliquidLog.Infof("  止盈价设置: %.4f", roundedTakeProfitPrice)
 	return nil
 }
 
@@ -588,14 +635,30 @@ func (t *HyperliquidTrader) FormatQuantity(symbol string, quantity float64) (str
 	szDecimals := t.getSzDecimals(coin)
 
 	// 使用szDecimals格式化数量
-	formatStr := fmt.Sprintf("%%.%df", szDecimals)
-	return fmt.Sprintf(formatStr, quantity), nil
+	return formatStepQuantity(quantity, szDecimals), nil
+}
+
+// hyperliquidMinOrderValue Hyperliquid's minimum order value (USDC)
+const hyperliquidMinOrderValue = 10.0
+
+// SymbolFilters returns the coin's size step (szDecimals) and the minimum order value; prices have no tick size
+// (the adapter rounds them to 5 significant figures)
+func (t *HyperliquidTrader) SymbolFilters(symbol string) (SymbolFilters, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+	if t.meta != nil {
+		for _, asset := range t.meta.Universe {
+			if asset.Name == coin {
+				return SymbolFilters{StepSize: math.Pow10(-asset.SzDecimals), MinNotional: hyperliquidMinOrderValue}, nil
+			}
+		}
+	}
+	return SymbolFilters{}, fmt.Errorf("%s is not a Hyperliquid perpetual", coin)
 }
 
 // getSzDecimals 获取币种的数量精度
 func (t *HyperliquidTrader) getSzDecimals(coin string) int {
 	if t.meta == nil {
-		log.Printf("⚠️  meta信息为空，使用默认精度4")
+		hyperliquidLog.Warnf("⚠️  meta信息为空，使用默认精度4")
 		return 4 // 默认精度
 	}
 
@@ -606,7 +669,7 @@ func (t *HyperliquidTrader) getSzDecimals(coin string) int {
 		}
 	}
 
-	log.Printf("⚠️  未找到 %s 的精度信息，使用默认精度4", coin)
+	hyperliquidLog.Warnf("⚠️  未找到 %s 的精度信息，使用默认精度4", coin)
 	return 4 // 默认精度
 }
 
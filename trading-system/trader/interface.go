@@ -4,22 +4,22 @@ package trader
 // 支持多个交易平台（币安、Hyperliquid等）
 type Trader interface {
 	// GetBalance 获取账户余额
-	GetBalance() (map[string]interface{}, error)
+	GetBalance() (*Balance, error)
 
 	// GetPositions 获取所有持仓
-	GetPositions() ([]map[string]interface{}, error)
+	GetPositions() ([]Position, error)
 
 	// OpenLong 开多仓
-	OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	OpenLong(symbol string, quantity float64, leverage int) (*Order, error)
 
 	// OpenShort 开空仓
-	OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	OpenShort(symbol string, quantity float64, leverage int) (*Order, error)
 
 	// CloseLong 平多仓（quantity=0表示全部平仓）
-	CloseLong(symbol string, quantity float64) (map[string]interface{}, error)
+	CloseLong(symbol string, quantity float64) (*Order, error)
 
 	// CloseShort 平空仓（quantity=0表示全部平仓）
-	CloseShort(symbol string, quantity float64) (map[string]interface{}, error)
+	CloseShort(symbol string, quantity float64) (*Order, error)
 
 	// SetLeverage 设置杠杆
 	SetLeverage(symbol string, leverage int) error
@@ -39,3 +39,49 @@ type Trader interface {
 	// FormatQuantity 格式化数量到正确的精度
 	FormatQuantity(symbol string, quantity float64) (string, error)
 }
+
+// Balance USDT account balance, as every exchange adapter reports it
+type Balance struct {
+	WalletBalance    float64 `json:"totalWalletBalance"`    // Wallet balance excluding unrealized P&L
+	AvailableBalance float64 `json:"availableBalance"`      // Margin available for new orders
+	UnrealizedProfit float64 `json:"totalUnrealizedProfit"` // Unrealized P&L of open positions
+}
+
+// Equity wallet balance plus unrealized P&L
+func (b *Balance) Equity() float64 {
+	return addUSDT(b.WalletBalance, b.UnrealizedProfit)
+}
+
+// Position an open position (one per symbol and side)
+type Position struct {
+	Symbol           string  `json:"symbol"`      // e.g. "BTCUSDT"
+	Side             string  `json:"side"`        // "long" or "short"
+	Quantity         float64 `json:"positionAmt"` // Always positive (Side gives the direction)
+	EntryPrice       float64 `json:"entryPrice"`
+	MarkPrice        float64 `json:"markPrice"`
+	UnrealizedProfit float64 `json:"unRealizedProfit"`
+	Leverage         int     `json:"leverage"`         // 0 = not reported
+	LiquidationPrice float64 `json:"liquidationPrice"` // 0 = not reported
+}
+
+// Order result of an order
+type Order struct {
+	OrderID       int64    `json:"orderId"` // 0 = exchange returned no numeric ID
+	ClientOrderID string   `json:"clientOrderId,omitempty"`
+	Symbol        string   `json:"symbol"`
+	Status        string   `json:"status,omitempty"`
+	Price         float64  `json:"price,omitempty"`       // Fill price (0 = not reported)
+	ExecutedQty   float64  `json:"executedQty,omitempty"` // Filled quantity (0 = not reported)
+	Fee           float64  `json:"fee,omitempty"`         // Fee charged inline (0 = not reported)
+	RealizedPnL   *float64 `json:"realizedPnl,omitempty"` // Realized P&L of a close (nil = not reported)
+}
+
+// findPosition returns the symbol's position on side ("long"/"short"; "" = either side)
+func findPosition(positions []Position, symbol, side string) (Position, bool) {
+	for _, pos := range positions {
+		if pos.Symbol == symbol && (side == "" || pos.Side == side) {
+			return pos, true
+		}
+	}
+	return Position{}, false
+}
@@ -0,0 +1,160 @@
+package trader
+
+import (
+	"lia/pool"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LeverageSetting the outcome of configuring one symbol before trading
+type LeverageSetting struct {
+	Symbol     string `json:"symbol"`
+	Requested  int    `json:"requested"`
+	Applied    int    `json:"applied"`               // Leverage the exchange reports after setup (0 = unknown)
+	MarginType string `json:"margin_type,omitempty"` // As reported by the exchange ("" = not reported)
+	Error      string `json:"error,omitempty"`
+}
+
+// Clamped whether the exchange accepted less leverage than requested
+func (s LeverageSetting) Clamped() bool {
+	return s.Error == "" && s.Applied > 0 && s.Applied < s.Requested
+}
+
+// LeverageConfigurer optional interface for exchanges that can configure leverage and margin mode for many
+// symbols ahead of trading (instead of per order) and read the settings back
+type LeverageConfigurer interface {
+	// ConfigureLeverage sets the margin mode used by orders and the requested leverage (symbol → leverage),
+	// then reads the settings back; returns one setting per symbol
+	ConfigureLeverage(requested map[string]int) []LeverageSetting
+}
+
+// LeverageSetupReport result of the startup leverage setup of a trader
+type LeverageSetupReport struct {
+	At        time.Time         `json:"at"`
+	Supported bool              `json:"supported"` // false = the exchange configures leverage per order only
+	Symbols   int               `json:"symbols"`
+	Clamped   []LeverageSetting `json:"clamped,omitempty"` // Requested leverage unavailable, exchange maximum applied
+	Failed    []LeverageSetting `json:"failed,omitempty"`
+}
+
+// leverageCaps per-symbol leverage available on the exchange, learned by the startup setup
+type leverageCaps struct {
+	mu     sync.RWMutex
+	caps   map[string]int // symbol → maximum accepted leverage (only symbols clamped at setup)
+	report *LeverageSetupReport
+}
+
+// SetupLeverage configures leverage and margin mode on the exchange for the candidate universe (coin pool,
+// BTC/ETH and extraSymbols) before the first cycle: BTC/ETH at the BTC/ETH leverage, others at the altcoin
+// leverage. Verifies what the exchange accepted and remembers symbols whose requested leverage is unavailable
+// so opens are sized at the real leverage. Symbols with open positions are left untouched
+func (at *AutoTrader) SetupLeverage(extraSymbols []string) *LeverageSetupReport {
+	report := &LeverageSetupReport{At: time.Now()}
+	configurer, ok := baseTrader(at.trader).(LeverageConfigurer)
+	if !ok {
+		at.log.Infof("  ℹ️  Exchange %s sets leverage per order, skipping leverage setup", at.exchange)
+		at.leverageCaps.setReport(report, nil)
+		return report
+	}
+	report.Supported = true
+
+	symbols := at.leverageUniverse(extraSymbols)
+	report.Symbols = len(symbols)
+
+	requested := make(map[string]int, len(symbols))
+	for _, symbol := range symbols {
+		requested[symbol] = at.configuredLeverage(symbol)
+	}
+
+	caps := make(map[string]int)
+	for _, setting := range configurer.ConfigureLeverage(requested) {
+		switch {
+		case setting.Error != "":
+			report.Failed = append(report.Failed, setting)
+		case setting.Clamped():
+			report.Clamped = append(report.Clamped, setting)
+			caps[setting.Symbol] = setting.Applied
+		}
+	}
+	sort.Slice(report.Clamped, func(i, j int) bool { return report.Clamped[i].Symbol < report.Clamped[j].Symbol })
+	sort.Slice(report.Failed, func(i, j int) bool { return report.Failed[i].Symbol < report.Failed[j].Symbol })
+	at.leverageCaps.setReport(report, caps)
+
+	at.log.Infof("  ✓ Leverage configured for %d/%d symbols", len(symbols)-len(report.Failed), len(symbols))
+	for _, s := range report.Clamped {
+		at.log.Warnf("  ⚠️  %s: %dx unavailable, exchange allows %dx - opens will use %dx", s.Symbol, s.Requested, s.Applied, s.Applied)
+	}
+	for _, s := range report.Failed {
+		at.log.Warnf("  ⚠️  %s: leverage setup failed: %s", s.Symbol, s.Error)
+	}
+	return report
+}
+
+// leverageUniverse symbols the trader may open: coin pool candidates, BTC/ETH and extraSymbols, minus open positions
+func (at *AutoTrader) leverageUniverse(extraSymbols []string) []string {
+	candidates := []string{"BTCUSDT", "ETHUSDT"}
+	if at.sim != nil {
+		candidates = at.sim.Feed.Symbols()
+	} else if mergedPool, err := pool.GetMergedCoinPool(at.candidateLimit()); err != nil {
+		at.log.Warnf("  ⚠️  Leverage setup: failed to get coin pool: %v", err)
+	} else {
+		candidates = append(candidates, mergedPool.AllSymbols...)
+	}
+	candidates = append(candidates, extraSymbols...)
+
+	open := make(map[string]bool)
+	if positions, err := at.trader.GetPositions(); err == nil {
+		for _, pos := range positions {
+			open[pos.Symbol] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, symbol := range candidates {
+		if seen[symbol] || open[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// configuredLeverage the leverage the trader is configured to use for symbol
+func (at *AutoTrader) configuredLeverage(symbol string) int {
+	btcEth, altcoin := at.leverageLimits()
+	if symbol == "BTCUSDT" || symbol == "ETHUSDT" {
+		return btcEth
+	}
+	return altcoin
+}
+
+// GetLeverageSetup the startup leverage setup report (nil = setup not run)
+func (at *AutoTrader) GetLeverageSetup() *LeverageSetupReport {
+	at.leverageCaps.mu.RLock()
+	defer at.leverageCaps.mu.RUnlock()
+	return at.leverageCaps.report
+}
+
+// capLeverage lowers leverage to the symbol's maximum found at setup, so quantity is sized at the leverage
+// the exchange will actually apply
+func (at *AutoTrader) capLeverage(symbol string, leverage int) int {
+	at.leverageCaps.mu.RLock()
+	max := at.leverageCaps.caps[symbol]
+	at.leverageCaps.mu.RUnlock()
+	if max > 0 && leverage > max {
+		at.log.Warnf("  ⚠️  %s leverage %dx unavailable on the exchange, sizing at %dx", symbol, leverage, max)
+		return max
+	}
+	return leverage
+}
+
+// setReport stores the setup report and the leverage caps it found
+func (lc *leverageCaps) setReport(report *LeverageSetupReport, caps map[string]int) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.report = report
+	lc.caps = caps
+}
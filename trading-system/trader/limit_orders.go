@@ -0,0 +1,239 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"time"
+)
+
+// LimitOrderTrader optional interface for exchanges that can rest limit and post-only entries
+type LimitOrderTrader interface {
+	// OpenLimit places a limit order opening positionSide ("LONG"/"SHORT") at price (postOnly = maker only:
+	// the exchange expires it instead of filling it immediately)
+	OpenLimit(symbol, positionSide string, quantity float64, leverage int, price float64, postOnly bool) (*Order, error)
+
+	OrderReader
+
+	// CancelOrder cancels one resting order
+	CancelOrder(symbol string, orderID int64) error
+}
+
+// errLimitOrdersUnsupported returned by an order-path wrapper whose inner trader cannot rest limit orders
+var errLimitOrdersUnsupported = errors.New("limit orders are not supported by this exchange")
+
+// limitOrdersOf the inner trader of an order-path wrapper as a LimitOrderTrader
+func limitOrdersOf(t Trader) (LimitOrderTrader, error) {
+	lt, ok := t.(LimitOrderTrader)
+	if !ok {
+		return nil, errLimitOrdersUnsupported
+	}
+	return lt, nil
+}
+
+// limitOrderPath t as a LimitOrderTrader when every layer of its order path (symbol filters, ledger, retries and
+// the exchange adapter) can rest limit orders, so limit entries are normalized, retried and recorded like market
+// ones (nil otherwise, e.g. in dry run)
+func limitOrderPath(t Trader) LimitOrderTrader {
+	lt, ok := t.(LimitOrderTrader)
+	if !ok {
+		return nil
+	}
+	for inner := t; ; {
+		if _, ok := inner.(LimitOrderTrader); !ok {
+			return nil
+		}
+		w, ok := inner.(traderWrapper)
+		if !ok {
+			return lt
+		}
+		inner = w.unwrap()
+	}
+}
+
+// Order statuses reported while an order rests and once it is done (Binance names)
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCanceled        = "CANCELED"
+	OrderStatusExpired         = "EXPIRED"
+	OrderStatusRejected        = "REJECTED"
+)
+
+// orderResting whether an order with status can still fill
+func orderResting(status string) bool {
+	return status == OrderStatusNew || status == OrderStatusPartiallyFilled
+}
+
+// limitEntryTrader the exchange's limit order support when limit entries are enabled (nil = market entries only)
+func (at *AutoTrader) limitEntryTrader() LimitOrderTrader {
+	if at.config.LimitOrderTimeout <= 0 {
+		return nil
+	}
+	return limitOrderPath(at.trader)
+}
+
+// placeLimitEntry rests a limit / post_only open at the decision's limit price, sized from margin at that price.
+// The entry is tracked as an open saga until it fills (then its take profit and stop are placed) or times out
+func (at *AutoTrader) placeLimitEntry(lt LimitOrderTrader, decision *decisionPkg.Decision, side string, margin float64, actionRecord *logger.DecisionAction) (*Order, error) {
+	quantity := quantityForMargin(margin, decision.Leverage, decision.LimitPrice)
+	actionRecord.Quantity = quantity
+	actionRecord.Price = decision.LimitPrice
+
+	saga := &OpenSaga{
+		ID:           fmt.Sprintf("%s_%s_%d", decision.Symbol, side, time.Now().UnixNano()),
+		Symbol:       decision.Symbol,
+		Side:         side,
+		Quantity:     quantity,
+		Leverage:     decision.Leverage,
+		PrevLeverage: at.currentLeverage(decision.Symbol),
+		TakeProfit:   decision.TakeProfit,
+		StopLoss:     at.stopOrderPrice(decision),
+		Step:         SagaStepEntry,
+		StartedAt:    at.now(),
+		OrderType:    decision.OrderType,
+		LimitPrice:   decision.LimitPrice,
+	}
+	at.openSagas.begin(saga)
+
+	postOnly := decision.OrderType == decisionPkg.OrderTypePostOnly
+	order, err := lt.OpenLimit(decision.Symbol, positionSideOf(side), quantity, decision.Leverage, decision.LimitPrice, postOnly)
+	if err != nil {
+		at.openSagas.advance(saga, SagaStepEntry, err)
+		at.abandonLimitEntry(saga)
+		return nil, err
+	}
+	saga.OrderID = order.OrderID
+	at.orderTracker.Track("open_"+side, "LIMIT", order, quantity)
+
+	switch {
+	case order.Status == OrderStatusFilled:
+		// Marketable limit: filled on placement like a market entry
+		if order.ExecutedQty > 0 {
+			saga.Quantity = order.ExecutedQty
+		}
+		at.recordLimitFill(saga, order)
+		at.openSagas.advance(saga, SagaStepTakeProfit, nil)
+		if err := at.finishTakeProfit(saga); err != nil {
+			return nil, err
+		}
+	case !orderResting(order.Status):
+		at.abandonLimitEntry(saga)
+		if postOnly {
+			return nil, fmt.Errorf("post_only entry at %.4f would have filled immediately (status %s), not placed", decision.LimitPrice, order.Status)
+		}
+		return nil, fmt.Errorf("limit entry at %.4f was not accepted (status %s)", decision.LimitPrice, order.Status)
+	default:
+		saga.ExpiresAt = at.now().Add(at.config.LimitOrderTimeout)
+		at.openSagas.advance(saga, SagaStepPendingFill, nil)
+		at.openSagas.release(saga) // Checked by checkPendingEntries from now on
+		at.log.Infof("  ⏳ %s %s %s entry resting @ %.4f (quantity %.4f, cancelled at %s if unfilled)",
+			decision.Symbol, side, decision.OrderType, decision.LimitPrice, quantity, saga.ExpiresAt.Format("15:04:05"))
+	}
+	return order, nil
+}
+
+// checkPendingEntries follows the resting limit entries (every cycle): a filled entry gets its take profit and
+// stop, an entry past its timeout is cancelled (a partial fill is kept and protected), an entry the exchange
+// dropped is removed
+func (at *AutoTrader) checkPendingEntries() {
+	pending := at.openSagas.claimPendingFills()
+	if len(pending) == 0 {
+		return
+	}
+	lt := limitOrderPath(at.trader)
+	if lt == nil {
+		for _, saga := range pending {
+			at.openSagas.release(saga)
+		}
+		return
+	}
+
+	for _, saga := range pending {
+		order, err := lt.GetOrder(saga.Symbol, saga.OrderID)
+		if err != nil {
+			at.log.Warnf("  ⚠ Failed to check %s %s limit entry #%d: %v", saga.Symbol, saga.Side, saga.OrderID, err)
+			at.openSagas.release(saga)
+			continue
+		}
+
+		if orderResting(order.Status) {
+			if at.now().Before(saga.ExpiresAt) {
+				at.openSagas.release(saga)
+				continue
+			}
+			if err := lt.CancelOrder(saga.Symbol, saga.OrderID); err != nil {
+				at.log.Warnf("  ⚠ Failed to cancel expired %s %s limit entry #%d: %v", saga.Symbol, saga.Side, saga.OrderID, err)
+				at.openSagas.release(saga)
+				continue
+			}
+			// The final fill: the order may have filled (partly) before the cancel landed
+			if final, err := lt.GetOrder(saga.Symbol, saga.OrderID); err == nil {
+				order = final
+			}
+			at.log.Infof("  ⌛ %s %s limit entry #%d timed out: %.4f of %.4f filled", saga.Symbol, saga.Side, saga.OrderID, order.ExecutedQty, saga.Quantity)
+		}
+
+		if order.ExecutedQty <= 0 {
+			if order.Status != OrderStatusCanceled {
+				at.log.Infof("  ⌛ %s %s limit entry #%d ended unfilled (%s)", saga.Symbol, saga.Side, saga.OrderID, order.Status)
+			}
+			at.abandonLimitEntry(saga)
+			if at.symbolThrottle != nil {
+				at.symbolThrottle.Release(saga.Symbol, at.id)
+			}
+			continue
+		}
+
+		saga.Quantity = order.ExecutedQty
+		at.log.Infof("  ✅ %s %s limit entry #%d filled: %.4f @ %.4f", saga.Symbol, saga.Side, saga.OrderID, order.ExecutedQty, order.Price)
+		at.recordLimitFill(saga, order)
+		at.openSagas.advance(saga, SagaStepTakeProfit, nil)
+		if err := at.finishTakeProfit(saga); err != nil {
+			at.log.Errorf("  ❌ Filled %s %s limit entry: %v", saga.Symbol, saga.Side, err)
+		}
+	}
+}
+
+// abandonLimitEntry removes an entry that ended without a fill (nothing to cancel or close): restores leverage
+func (at *AutoTrader) abandonLimitEntry(saga *OpenSaga) {
+	at.restoreLeverage(saga)
+	at.openSagas.finish(saga)
+	at.log.Infof("  ↩️  %s %s limit entry removed", saga.Symbol, saga.Side)
+}
+
+// recordLimitFill marks the symbol as traded, records the fill's fee and attributes the fill to this trader, as
+// the ledger wrapper does for market opens
+func (at *AutoTrader) recordLimitFill(saga *OpenSaga, order *Order) {
+	at.pnlLedger.markSymbol(saga.Symbol)
+	if order.Fee > 0 {
+		at.pnlLedger.RecordFee(saga.Symbol, order.Fee)
+	}
+	if at.ownership != nil && order.ExecutedQty > 0 {
+		price := order.Price
+		if price <= 0 {
+			price = saga.LimitPrice
+		}
+		at.ownership.RecordOpen(at.accountKey, at.id, saga.Symbol, saga.Side, order.ExecutedQty, price, saga.Leverage, order.Fee)
+	}
+}
+
+// pendingEntries the resting limit entries for the AI context
+func (at *AutoTrader) pendingEntries() []decisionPkg.PendingEntry {
+	var entries []decisionPkg.PendingEntry
+	now := at.now()
+	for _, saga := range at.openSagas.pendingFills() {
+		entries = append(entries, decisionPkg.PendingEntry{
+			Symbol:           saga.Symbol,
+			Side:             saga.Side,
+			OrderType:        saga.OrderType,
+			LimitPrice:       saga.LimitPrice,
+			Quantity:         saga.Quantity,
+			AgeMinutes:       int(now.Sub(saga.StartedAt).Minutes()),
+			ExpiresInMinutes: max(0, int(saga.ExpiresAt.Sub(now).Minutes())),
+		})
+	}
+	return entries
+}
@@ -0,0 +1,24 @@
+package trader
+
+import (
+	decisionPkg "lia/decision"
+	"lia/market"
+)
+
+// attachLiquidations adds the recent liquidations of the cycle's positions and candidates, the market-wide
+// totals and the large liquidations to ctx
+func (at *AutoTrader) attachLiquidations(ctx *decisionPkg.Context) {
+	symbols := make([]string, 0, len(ctx.Positions)+len(ctx.CandidateCoins))
+	for _, pos := range ctx.Positions {
+		symbols = append(symbols, pos.Symbol)
+	}
+	for _, coin := range ctx.CandidateCoins {
+		symbols = append(symbols, coin.Symbol)
+	}
+
+	if summaries := market.SymbolLiquidations(symbols); len(summaries) > 0 {
+		ctx.Liquidations = summaries
+	}
+	ctx.MarketLiquidations = market.MarketLiquidations()
+	ctx.LargeLiquidations = market.LargeLiquidations(0)
+}
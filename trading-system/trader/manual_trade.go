@@ -0,0 +1,63 @@
+package trader
+
+import (
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"strings"
+	"time"
+)
+
+// manualConfidence confidence of manual opens that give none (the operator's call passes the confidence
+// threshold; the risk policy still applies)
+const manualConfidence = 100
+
+// SubmitManualTrade queues an operator's decisions for the trader's loop. They run in a cycle of their own, go
+// through the same validation as AI decisions and are recorded with source "manual". Manual trades run even
+// while the trader is paused
+func (at *AutoTrader) SubmitManualTrade(decisions []decisionPkg.Decision, comment, operator string) (*Signal, error) {
+	if len(decisions) == 0 {
+		return nil, fmt.Errorf("%w: no decisions", ErrInvalidSignal)
+	}
+	decisions = append([]decisionPkg.Decision(nil), decisions...)
+	for i := range decisions {
+		d := &decisions[i]
+		d.Symbol = strings.ToUpper(strings.TrimSpace(d.Symbol))
+		d.Action = strings.ToLower(strings.TrimSpace(d.Action))
+		if d.Symbol == "" {
+			return nil, fmt.Errorf("%w: decision #%d needs a symbol", ErrInvalidSignal, i+1)
+		}
+		if !isManualAction(d.Action) {
+			return nil, fmt.Errorf("%w: decision #%d: action '%s' is not an open, add, close or amend action", ErrInvalidSignal, i+1, d.Action)
+		}
+		if d.Confidence == 0 && decisionPkg.IsEntryAction(d.Action) {
+			d.Confidence = manualConfidence
+		}
+		if d.Reasoning == "" {
+			d.Reasoning = "Manual trade"
+			if comment != "" {
+				d.Reasoning = "Manual trade: " + comment
+			}
+		}
+	}
+	if operator == "" {
+		operator = "operator"
+	}
+
+	signal := newSignal(fmt.Sprintf("manual-%d", time.Now().UnixNano()), logger.DecisionSourceManual, decisions)
+	signal.Comment = comment
+	signal.Operator = operator
+	if err := at.queueSignal(signal); err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// isManualAction whether an operator may submit the action (anything that trades or amends a position)
+func isManualAction(action string) bool {
+	switch action {
+	case "open_long", "open_short", "close_long", "close_short":
+		return true
+	}
+	return decisionPkg.IsAddAction(action) || decisionPkg.IsAmendAction(action)
+}
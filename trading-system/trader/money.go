@@ -0,0 +1,93 @@
+package trader
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Money math at the execution and accounting boundaries (sizing, quantities, fees, P&L, balances)
+// is done in decimal and rounded to fixed precision before converting back, so repeated
+// accumulation (wallet balance, ledger totals) cannot drift. float64 stays the transport type in
+// Trader maps and is still used for indicator math.
+
+const (
+	usdtPlaces     = 8  // USDT amounts (exchanges report 8 decimals)
+	quantityPlaces = 12 // Unrounded order quantities (before exchange step size formatting)
+)
+
+// dec converts a float64 to decimal using its shortest representation (0.1 → exactly 0.1)
+func dec(v float64) decimal.Decimal {
+	return decimal.NewFromFloat(v)
+}
+
+// usdtFloat rounds a USDT amount to 8 decimals and converts it back to float64
+func usdtFloat(v decimal.Decimal) float64 {
+	f, _ := v.Round(usdtPlaces).Float64()
+	return f
+}
+
+// addUSDT returns a + b without float rounding drift
+func addUSDT(a, b float64) float64 {
+	return usdtFloat(dec(a).Add(dec(b)))
+}
+
+// sumUSDT returns the drift-free sum of USDT amounts
+func sumUSDT(values ...float64) float64 {
+	total := decimal.Zero
+	for _, v := range values {
+		total = total.Add(dec(v))
+	}
+	return usdtFloat(total)
+}
+
+// quantityForMargin converts margin to an order quantity: margin × leverage / price
+func quantityForMargin(margin float64, leverage int, price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	notional := dec(margin).Mul(decimal.NewFromInt(int64(leverage)))
+	q, _ := notional.DivRound(dec(price), quantityPlaces).Float64()
+	return q
+}
+
+// marginForQuantity margin required for a position: quantity × price / leverage
+func marginForQuantity(quantity, price float64, leverage int) float64 {
+	if leverage <= 0 {
+		leverage = 1
+	}
+	return usdtFloat(dec(quantity).Mul(dec(price)).Div(decimal.NewFromInt(int64(leverage))))
+}
+
+// formatStepQuantity formats a quantity to the given number of decimals (half rounded away from zero)
+func formatStepQuantity(quantity float64, places int) string {
+	return dec(quantity).StringFixed(int32(places))
+}
+
+// roundToStep rounds value to the nearest multiple of step
+func roundToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	s := dec(step)
+	f, _ := dec(value).Div(s).Round(0).Mul(s).Float64()
+	return f
+}
+
+// floorToStep rounds value down to a multiple of step
+func floorToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	s := dec(step)
+	f, _ := dec(value).Div(s).Floor().Mul(s).Float64()
+	return f
+}
+
+// ceilToStep rounds value up to a multiple of step
+func ceilToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	s := dec(step)
+	f, _ := dec(value).Div(s).Ceil().Mul(s).Float64()
+	return f
+}
@@ -1,7 +1,9 @@
 package trader
 
 import (
+	"encoding/json"
 	"lia/config"
+	"lia/logger"
 	multiagent "lia/multi-agent"
 )
 
@@ -13,24 +15,94 @@ func convertToMultiAgentConfig(cfg *config.MultiAgentConfig) *multiagent.MultiAg
 	
 	agents := make([]multiagent.AgentConfig, len(cfg.Agents))
 	for i, agent := range cfg.Agents {
-		agents[i] = multiagent.AgentConfig{
-			ID:        agent.ID,
-			Name:      agent.Name,
-			Model:     agent.Model,
-			APIKey:    agent.APIKey,
-			GroqModel: agent.GroqModel,
-			Role:      agent.Role,
-			Weight:    agent.Weight,
-		}
+		agents[i] = convertAgentConfig(agent)
 	}
-	
-	return &multiagent.MultiAgentConfig{
+
+	maConfig := &multiagent.MultiAgentConfig{
 		Enabled:       cfg.Enabled,
 		ConsensusMode: cfg.ConsensusMode,
 		FastFirst:     cfg.FastFirst,
 		MinAgents:     cfg.MinAgents,
 		MaxWaitTime:   cfg.MaxWaitTime,
 		Agents:        agents,
+		VetoAgent:     cfg.VetoAgent,
+	}
+	if cfg.Chair != nil {
+		chair := convertAgentConfig(*cfg.Chair)
+		maConfig.Chair = &chair
+	}
+	return maConfig
+}
+
+// convertAgentConfig converts one agent entry (already normalized by config validation)
+func convertAgentConfig(agent config.AgentConfig) multiagent.AgentConfig {
+	return multiagent.AgentConfig{
+		ID:       agent.ID,
+		Name:     agent.Name,
+		Provider: agent.Provider,
+		Model:    agent.Model,
+		APIKey:   agent.APIKey,
+		APIURL:   agent.APIURL,
+		Role:     agent.Role,
+		Weight:   agent.Weight,
+	}
+}
+
+// agentSharpe per-agent Sharpe ratio of the trades opened on the agents' proposals, for the agents with at
+// least minTrades closed trades in the last lookbackCycles cycles
+func (at *AutoTrader) agentSharpe(lookbackCycles, minTrades int) map[string]float64 {
+	records, err := at.decisionLogger.GetAgentTrackRecords(lookbackCycles)
+	if err != nil {
+		at.log.Warnf("⚠️  Agent track records unavailable: %v - using configured weights", err)
+		return nil
 	}
+	sharpe := make(map[string]float64)
+	for id, r := range records {
+		if r.Trades >= minTrades {
+			sharpe[id] = r.Sharpe
+			at.log.Infof("📈 Agent %s: %d trades, Sharpe %.2f", id, r.Trades, r.Sharpe)
+		}
+	}
+	return sharpe
 }
 
+// agentDebateRecord converts a multi-agent transcript for the decision logger (nil when there is none)
+func agentDebateRecord(t *multiagent.Transcript) *logger.AgentDebate {
+	if t == nil {
+		return nil
+	}
+	debate := &logger.AgentDebate{
+		ConsensusMode: t.ConsensusMode,
+		Resolution:    t.Resolution,
+		Proposals:     make([]logger.AgentProposal, len(t.Proposals)),
+		Votes:         make([]logger.AgentVote, len(t.Votes)),
+	}
+	for i, p := range t.Proposals {
+		debate.Proposals[i] = logger.AgentProposal{
+			AgentID:    p.AgentID,
+			Name:       p.Name,
+			Provider:   p.Provider,
+			Model:      p.Model,
+			Role:       p.Role,
+			Weight:     p.Weight,
+			Status:     p.Status,
+			Error:      p.Error,
+			DurationMs: p.Duration.Milliseconds(),
+			CoTTrace:   p.CoTTrace,
+		}
+		if p.Decisions != nil {
+			debate.Proposals[i].Decisions, _ = json.Marshal(p.Decisions)
+		}
+	}
+	for i, v := range t.Votes {
+		debate.Votes[i] = logger.AgentVote{
+			Symbol:   v.Symbol,
+			Action:   v.Action,
+			Agents:   v.Agents,
+			Votes:    v.Votes,
+			Share:    v.Share,
+			Accepted: v.Accepted,
+		}
+	}
+	return debate
+}
@@ -0,0 +1,42 @@
+package trader
+
+import (
+	decisionPkg "lia/decision"
+	"lia/news"
+	"time"
+)
+
+// attachNews adds the feed's recent headlines for the cycle's positions and candidates to ctx
+func (at *AutoTrader) attachNews(ctx *decisionPkg.Context) {
+	symbols := make([]string, 0, len(ctx.Positions)+len(ctx.CandidateCoins))
+	for _, pos := range ctx.Positions {
+		symbols = append(symbols, pos.Symbol)
+	}
+	for _, coin := range ctx.CandidateCoins {
+		symbols = append(symbols, coin.Symbol)
+	}
+
+	now := time.Now()
+	for symbol, headlines := range news.ForSymbols(symbols) {
+		if ctx.News == nil {
+			ctx.News = make(map[string][]decisionPkg.NewsHeadline)
+		}
+		ctx.News[symbol] = newsHeadlines(headlines, now)
+	}
+	ctx.MarketNews = newsHeadlines(news.Market(), now)
+}
+
+// newsHeadlines the headlines as shown to the AI
+func newsHeadlines(headlines []news.Headline, now time.Time) []decisionPkg.NewsHeadline {
+	var result []decisionPkg.NewsHeadline
+	for _, h := range headlines {
+		result = append(result, decisionPkg.NewsHeadline{
+			Title:      h.Title,
+			Source:     h.Source,
+			AgeMinutes: int(now.Sub(h.PublishedAt).Minutes()),
+			Sentiment:  h.Sentiment,
+			Important:  h.Important,
+		})
+	}
+	return result
+}
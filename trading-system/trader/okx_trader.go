@@ -0,0 +1,734 @@
+package trader
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"lia/logging"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// okxLog logs the OKX adapter
+var okxLog = logging.Component("okx")
+
+const (
+	okxBaseURL         = "https://www.okx.com"
+	okxMarginMode      = "cross" // tdMode / mgnMode of every order and leverage setting
+	okxInstrumentTTL   = time.Hour
+	okxPositionModeNet = "net_mode"
+)
+
+// OKXTrader OKX USDT-margined perpetual swap trader (REST API v5)
+type OKXTrader struct {
+	apiKey     string
+	secretKey  string
+	passphrase string
+	baseURL    string
+	client     *http.Client
+
+	// Instrument metadata (contract value, lot size) by instrument ID
+	instruments     map[string]okxInstrument
+	instrumentsTime time.Time
+
+	// Account position mode: "long_short_mode" (posSide long/short) or "net_mode"
+	positionMode string
+
+	mu sync.RWMutex
+}
+
+// okxInstrument contract specification of a swap instrument
+type okxInstrument struct {
+	ContractValue float64 // Base coin per contract (ctVal)
+	LotSize       float64 // Contract size step (lotSz)
+	MinSize       float64 // Minimum order size in contracts (minSz)
+	MaxMktSize    float64 // Maximum market order size in contracts (maxMktSz)
+	TickSize      float64 // Price step (tickSz)
+	LotPrecision  int     // Decimals of lotSz
+}
+
+// okxResponse envelope of every OKX v5 response
+type okxResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewOKXTrader creates an OKX trader from an API key, secret and passphrase
+func NewOKXTrader(apiKey, secretKey, passphrase string) *OKXTrader {
+	return &OKXTrader{
+		apiKey:      apiKey,
+		secretKey:   secretKey,
+		passphrase:  passphrase,
+		baseURL:     okxBaseURL,
+		instruments: make(map[string]okxInstrument),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 10 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+			},
+		},
+	}
+}
+
+// okxInstID converts a symbol (BTCUSDT) to an OKX swap instrument ID (BTC-USDT-SWAP)
+func okxInstID(symbol string) string {
+	return strings.TrimSuffix(symbol, "USDT") + "-USDT-SWAP"
+}
+
+// okxSymbol converts an OKX swap instrument ID (BTC-USDT-SWAP) back to a symbol (BTCUSDT)
+func okxSymbol(instID string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(instID, "-SWAP"), "-", "")
+}
+
+// sign computes the OK-ACCESS-SIGN header: base64(HMAC-SHA256(timestamp + method + path + body))
+func (t *OKXTrader) sign(timestamp, method, requestPath, body string) string {
+	mac := hmac.New(sha256.New, []byte(t.secretKey))
+	mac.Write([]byte(timestamp + method + requestPath + body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// request sends a signed request and returns the response data (GET requests are retried on network errors;
+// orders are not, so a timed-out order is never sent twice)
+func (t *OKXTrader) request(method, path string, query url.Values, payload interface{}) (json.RawMessage, error) {
+	const maxRetries = 3
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		data, err := t.doRequest(method, path, query, payload)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+
+		retryable := strings.Contains(err.Error(), "timeout") ||
+			strings.Contains(err.Error(), "connection reset") ||
+			strings.Contains(err.Error(), "EOF")
+		if method != http.MethodGet || !retryable {
+			return nil, err
+		}
+		if attempt < maxRetries {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	return nil, fmt.Errorf("request failed after %d attempts: %w", maxRetries, lastErr)
+}
+
+// doRequest performs one signed request
+func (t *OKXTrader) doRequest(method, path string, query url.Values, payload interface{}) (json.RawMessage, error) {
+	requestPath := path
+	if len(query) > 0 {
+		requestPath += "?" + query.Encode()
+	}
+
+	body := ""
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = string(raw)
+	}
+
+	req, err := http.NewRequest(method, t.baseURL+requestPath, bytes.NewBufferString(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OK-ACCESS-KEY", t.apiKey)
+	req.Header.Set("OK-ACCESS-SIGN", t.sign(timestamp, method, requestPath, body))
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", t.passphrase)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(raw))
+	}
+
+	var envelope okxResponse
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if envelope.Code != "0" {
+		return nil, fmt.Errorf("OKX error %s: %s%s", envelope.Code, envelope.Msg, okxItemErrors(envelope.Data))
+	}
+	return envelope.Data, nil
+}
+
+// okxItemErrors per-item error messages of a failed batch/order response (sCode/sMsg)
+func okxItemErrors(data json.RawMessage) string {
+	var items []struct {
+		SCode string `json:"sCode"`
+		SMsg  string `json:"sMsg"`
+	}
+	if json.Unmarshal(data, &items) != nil {
+		return ""
+	}
+	var msgs []string
+	for _, item := range items {
+		if item.SCode != "" && item.SCode != "0" {
+			msgs = append(msgs, fmt.Sprintf("%s %s", item.SCode, item.SMsg))
+		}
+	}
+	if len(msgs) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(msgs, "; ") + ")"
+}
+
+// parseFloatString parses a decimal string field of an exchange response ("" = 0)
+func parseFloatString(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// loadInstruments caches the USDT swap contract specifications
+func (t *OKXTrader) loadInstruments() error {
+	t.mu.RLock()
+	fresh := len(t.instruments) > 0 && time.Since(t.instrumentsTime) < okxInstrumentTTL
+	t.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	data, err := t.request(http.MethodGet, "/api/v5/public/instruments", url.Values{"instType": {"SWAP"}}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get instruments: %w", err)
+	}
+	var items []struct {
+		InstID    string `json:"instId"`
+		SettleCcy string `json:"settleCcy"`
+		CtVal     string `json:"ctVal"`
+		LotSz     string `json:"lotSz"`
+		MinSz     string `json:"minSz"`
+		MaxMktSz  string `json:"maxMktSz"`
+		TickSz    string `json:"tickSz"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("failed to parse instruments: %w", err)
+	}
+
+	instruments := make(map[string]okxInstrument, len(items))
+	for _, item := range items {
+		if item.SettleCcy != "USDT" {
+			continue
+		}
+		instruments[item.InstID] = okxInstrument{
+			ContractValue: parseFloatString(item.CtVal),
+			LotSize:       parseFloatString(item.LotSz),
+			MinSize:       parseFloatString(item.MinSz),
+			MaxMktSize:    parseFloatString(item.MaxMktSz),
+			TickSize:      parseFloatString(item.TickSz),
+			LotPrecision:  calculatePrecision(item.LotSz),
+		}
+	}
+
+	t.mu.Lock()
+	t.instruments = instruments
+	t.instrumentsTime = time.Now()
+	t.mu.Unlock()
+	return nil
+}
+
+// SymbolFilters returns the instrument's lot, price and size filters in base coin (contracts × ctVal)
+func (t *OKXTrader) SymbolFilters(symbol string) (SymbolFilters, error) {
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return SymbolFilters{}, err
+	}
+	inBase := func(contracts float64) float64 {
+		f, _ := dec(contracts).Mul(dec(inst.ContractValue)).Float64()
+		return f
+	}
+	return SymbolFilters{
+		StepSize: inBase(inst.LotSize),
+		MinQty:   inBase(inst.MinSize),
+		MaxQty:   inBase(inst.MaxMktSize),
+		TickSize: inst.TickSize,
+	}, nil
+}
+
+// RefreshSymbolRules reloads the contract specifications (after a lot size rejection)
+func (t *OKXTrader) RefreshSymbolRules(symbol string) error {
+	t.mu.Lock()
+	t.instrumentsTime = time.Time{}
+	t.mu.Unlock()
+	return t.loadInstruments()
+}
+
+// instrument returns the contract specification of symbol
+func (t *OKXTrader) instrument(symbol string) (okxInstrument, error) {
+	if err := t.loadInstruments(); err != nil {
+		return okxInstrument{}, err
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	inst, ok := t.instruments[okxInstID(symbol)]
+	if !ok || inst.ContractValue <= 0 {
+		return okxInstrument{}, fmt.Errorf("%s is not an OKX USDT swap", symbol)
+	}
+	return inst, nil
+}
+
+// contracts converts a base coin quantity to a contract count rounded down to the lot size
+func (t *OKXTrader) contracts(symbol string, quantity float64) (string, error) {
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return "", err
+	}
+	count := dec(quantity).Div(dec(inst.ContractValue))
+	if inst.LotSize > 0 {
+		count = count.Div(dec(inst.LotSize)).Floor().Mul(dec(inst.LotSize))
+	}
+	if count.LessThan(dec(inst.MinSize)) || !count.IsPositive() {
+		return "", fmt.Errorf("%s quantity %.8f is below the minimum order size (%g contracts × %g per contract)",
+			symbol, quantity, inst.MinSize, inst.ContractValue)
+	}
+	return count.StringFixed(int32(inst.LotPrecision)), nil
+}
+
+// getPositionMode reads (and caches) the account position mode
+func (t *OKXTrader) getPositionMode() (string, error) {
+	t.mu.RLock()
+	mode := t.positionMode
+	t.mu.RUnlock()
+	if mode != "" {
+		return mode, nil
+	}
+
+	data, err := t.request(http.MethodGet, "/api/v5/account/config", nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get account config: %w", err)
+	}
+	var configs []struct {
+		PosMode string `json:"posMode"`
+	}
+	if err := json.Unmarshal(data, &configs); err != nil || len(configs) == 0 {
+		return "", fmt.Errorf("failed to parse account config: %v", err)
+	}
+
+	t.mu.Lock()
+	t.positionMode = configs[0].PosMode
+	t.mu.Unlock()
+	return configs[0].PosMode, nil
+}
+
+// WarmUp loads the instrument table and the account position mode before the first cycle
+func (t *OKXTrader) WarmUp() error {
+	if err := t.loadInstruments(); err != nil {
+		return fmt.Errorf("instruments: %w", err)
+	}
+	if _, err := t.getPositionMode(); err != nil {
+		return fmt.Errorf("position mode: %w", err)
+	}
+	if _, err := t.GetBalance(); err != nil {
+		return fmt.Errorf("balance: %w", err)
+	}
+	return nil
+}
+
+// GetBalance gets the USDT balance of the trading account
+func (t *OKXTrader) GetBalance() (*Balance, error) {
+	data, err := t.request(http.MethodGet, "/api/v5/account/balance", url.Values{"ccy": {"USDT"}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	var accounts []struct {
+		Details []struct {
+			Ccy      string `json:"ccy"`
+			CashBal  string `json:"cashBal"`
+			AvailEq  string `json:"availEq"`
+			AvailBal string `json:"availBal"`
+			Upl      string `json:"upl"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse account balance: %w", err)
+	}
+
+	walletBalance, availableBalance, unrealizedProfit := 0.0, 0.0, 0.0
+	for _, account := range accounts {
+		for _, detail := range account.Details {
+			if detail.Ccy != "USDT" {
+				continue
+			}
+			walletBalance = parseFloatString(detail.CashBal)
+			// availEq is only reported in multi-currency/portfolio margin accounts
+			availableBalance = parseFloatString(detail.AvailEq)
+			if detail.AvailEq == "" {
+				availableBalance = parseFloatString(detail.AvailBal)
+			}
+			unrealizedProfit = parseFloatString(detail.Upl)
+		}
+	}
+
+	return &Balance{
+		WalletBalance:    walletBalance,
+		AvailableBalance: availableBalance,
+		UnrealizedProfit: unrealizedProfit,
+	}, nil
+}
+
+// GetPositions gets all open USDT swap positions (quantities in base coin)
+func (t *OKXTrader) GetPositions() ([]Position, error) {
+	data, err := t.request(http.MethodGet, "/api/v5/account/positions", url.Values{"instType": {"SWAP"}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	var positions []struct {
+		InstID  string `json:"instId"`
+		PosSide string `json:"posSide"`
+		Pos     string `json:"pos"`
+		AvgPx   string `json:"avgPx"`
+		MarkPx  string `json:"markPx"`
+		Upl     string `json:"upl"`
+		Lever   string `json:"lever"`
+		LiqPx   string `json:"liqPx"`
+	}
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("failed to parse positions: %w", err)
+	}
+
+	result := []Position{}
+	for _, pos := range positions {
+		size := parseFloatString(pos.Pos)
+		if size == 0 || !strings.HasSuffix(pos.InstID, "-USDT-SWAP") {
+			continue
+		}
+		symbol := okxSymbol(pos.InstID)
+		inst, err := t.instrument(symbol)
+		if err != nil {
+			okxLog.Warnf("  ⚠ Skipping OKX position %s: %v", pos.InstID, err)
+			continue
+		}
+
+		// long_short_mode reports the side in posSide; net_mode signs the size
+		side := pos.PosSide
+		if side == "net" || side == "" {
+			side = "long"
+			if size < 0 {
+				side = "short"
+			}
+		}
+		if size < 0 {
+			size = -size
+		}
+		quantity, _ := dec(size).Mul(dec(inst.ContractValue)).Float64()
+
+		result = append(result, Position{
+			Symbol:           symbol,
+			Side:             side,
+			Quantity:         quantity,
+			EntryPrice:       parseFloatString(pos.AvgPx),
+			MarkPrice:        parseFloatString(pos.MarkPx),
+			UnrealizedProfit: parseFloatString(pos.Upl),
+			Leverage:         int(parseFloatString(pos.Lever)),
+			LiquidationPrice: parseFloatString(pos.LiqPx),
+		})
+	}
+	return result, nil
+}
+
+// orderPosSide fills in posSide ("long"/"short") in long_short_mode, or reduceOnly for closes in net_mode
+func (t *OKXTrader) orderPosSide(order map[string]interface{}, positionSide string, closing bool) error {
+	mode, err := t.getPositionMode()
+	if err != nil {
+		return err
+	}
+	if mode == okxPositionModeNet {
+		if closing {
+			order["reduceOnly"] = true
+		}
+		return nil
+	}
+	order["posSide"] = strings.ToLower(positionSide)
+	return nil
+}
+
+// placeMarketOrder places a market order of quantity (base coin)
+func (t *OKXTrader) placeMarketOrder(symbol, side, positionSide string, quantity float64, closing bool) (*Order, error) {
+	sz, err := t.contracts(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+	order := map[string]interface{}{
+		"instId":  okxInstID(symbol),
+		"tdMode":  okxMarginMode,
+		"side":    side,
+		"ordType": "market",
+		"sz":      sz,
+	}
+	if err := t.orderPosSide(order, positionSide, closing); err != nil {
+		return nil, err
+	}
+
+	data, err := t.request(http.MethodPost, "/api/v5/trade/order", nil, order)
+	if err != nil {
+		return nil, err
+	}
+	var placed []struct {
+		OrdID   string `json:"ordId"`
+		ClOrdID string `json:"clOrdId"`
+	}
+	if err := json.Unmarshal(data, &placed); err != nil || len(placed) == 0 {
+		return nil, fmt.Errorf("failed to parse order response: %v", err)
+	}
+
+	orderID, _ := strconv.ParseInt(placed[0].OrdID, 10, 64)
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: placed[0].ClOrdID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+	}, nil
+}
+
+// OpenLong opens a long position
+func (t *OKXTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	// Cancel leftover orders first so stale stops cannot act on the new position
+	if err := t.CancelAllOrders(symbol); err != nil {
+		okxLog.Warnf("  ⚠ Failed to cancel old orders (continuing): %v", err)
+	}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+
+	result, err := t.placeMarketOrder(symbol, "buy", "LONG", quantity, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open long position: %w", err)
+	}
+	okxLog.Infof("✓ Long position opened: %s quantity: %.8f", symbol, quantity)
+	return result, nil
+}
+
+// OpenShort opens a short position
+func (t *OKXTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	// Cancel leftover orders first so stale stops cannot act on the new position
+	if err := t.CancelAllOrders(symbol); err != nil {
+		okxLog.Warnf("  ⚠ Failed to cancel old orders (continuing): %v", err)
+	}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+
+	result, err := t.placeMarketOrder(symbol, "sell", "SHORT", quantity, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open short position: %w", err)
+	}
+	okxLog.Infof("✓ Short position opened: %s quantity: %.8f", symbol, quantity)
+	return result, nil
+}
+
+// positionQuantity current base coin quantity of the symbol's position on side ("long"/"short")
+func (t *OKXTrader) positionQuantity(symbol, side string) (float64, error) {
+	positions, err := t.GetPositions()
+	if err != nil {
+		return 0, err
+	}
+	if pos, ok := findPosition(positions, symbol, side); ok {
+		return pos.Quantity, nil
+	}
+	return 0, fmt.Errorf("no %s position found for %s", side, symbol)
+}
+
+// CloseLong closes a long position (quantity=0 closes all of it)
+func (t *OKXTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
+	if quantity == 0 {
+		var err error
+		if quantity, err = t.positionQuantity(symbol, "long"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := t.placeMarketOrder(symbol, "sell", "LONG", quantity, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close long position: %w", err)
+	}
+	okxLog.Infof("✓ Long position closed: %s quantity: %.8f", symbol, quantity)
+
+	// Cancel the position's remaining stop loss/take profit orders
+	if err := t.CancelAllOrders(symbol); err != nil {
+		okxLog.Warnf("  ⚠ Failed to cancel orders: %v", err)
+	}
+	return result, nil
+}
+
+// CloseShort closes a short position (quantity=0 closes all of it)
+func (t *OKXTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
+	if quantity == 0 {
+		var err error
+		if quantity, err = t.positionQuantity(symbol, "short"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := t.placeMarketOrder(symbol, "buy", "SHORT", quantity, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close short position: %w", err)
+	}
+	okxLog.Infof("✓ Short position closed: %s quantity: %.8f", symbol, quantity)
+
+	// Cancel the position's remaining stop loss/take profit orders
+	if err := t.CancelAllOrders(symbol); err != nil {
+		okxLog.Warnf("  ⚠ Failed to cancel orders: %v", err)
+	}
+	return result, nil
+}
+
+// SetLeverage sets the symbol's cross margin leverage
+func (t *OKXTrader) SetLeverage(symbol string, leverage int) error {
+	_, err := t.request(http.MethodPost, "/api/v5/account/set-leverage", nil, map[string]interface{}{
+		"instId":  okxInstID(symbol),
+		"lever":   strconv.Itoa(leverage),
+		"mgnMode": okxMarginMode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set leverage: %w", err)
+	}
+	return nil
+}
+
+// GetMarketPrice gets the last traded price
+func (t *OKXTrader) GetMarketPrice(symbol string) (float64, error) {
+	data, err := t.request(http.MethodGet, "/api/v5/market/ticker", url.Values{"instId": {okxInstID(symbol)}}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get price: %w", err)
+	}
+	var tickers []struct {
+		Last string `json:"last"`
+	}
+	if err := json.Unmarshal(data, &tickers); err != nil || len(tickers) == 0 {
+		return 0, fmt.Errorf("failed to parse %s ticker: %v", symbol, err)
+	}
+	return parseFloatString(tickers[0].Last), nil
+}
+
+// placeTriggerOrder places a conditional market order that closes the position side when triggerPrice is hit
+// (kind: "sl" or "tp")
+func (t *OKXTrader) placeTriggerOrder(symbol, positionSide string, quantity, triggerPrice float64, kind string) error {
+	sz, err := t.contracts(symbol, quantity)
+	if err != nil {
+		return err
+	}
+	side := "sell"
+	if positionSide == "SHORT" {
+		side = "buy"
+	}
+	trigger := strconv.FormatFloat(triggerPrice, 'f', -1, 64)
+	order := map[string]interface{}{
+		"instId":               okxInstID(symbol),
+		"tdMode":               okxMarginMode,
+		"side":                 side,
+		"ordType":              "conditional",
+		"sz":                   sz,
+		kind + "TriggerPx":     trigger,
+		kind + "OrdPx":         "-1", // -1 = market when triggered
+		kind + "TriggerPxType": "last",
+	}
+	if err := t.orderPosSide(order, positionSide, true); err != nil {
+		return err
+	}
+	_, err = t.request(http.MethodPost, "/api/v5/trade/order-algo", nil, order)
+	return err
+}
+
+// SetStopLoss places a stop market order for the position
+func (t *OKXTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	if err := t.placeTriggerOrder(symbol, positionSide, quantity, stopPrice, "sl"); err != nil {
+		// Reported so the open saga can retry (and close the position if the stop cannot be placed)
+		return fmt.Errorf("failed to set stop loss: %w", err)
+	}
+	okxLog.Infof("  ✓ Stop loss set: %.4f", stopPrice)
+	return nil
+}
+
+// SetTakeProfit places a take profit market order for the position
+func (t *OKXTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	if err := t.placeTriggerOrder(symbol, positionSide, quantity, takeProfitPrice, "tp"); err != nil {
+		return fmt.Errorf("failed to set take profit: %w", err)
+	}
+	okxLog.Infof("  ✓ Take profit set: %.4f", takeProfitPrice)
+	return nil
+}
+
+// CancelAllOrders cancels the symbol's pending orders and trigger (stop loss/take profit) orders
+func (t *OKXTrader) CancelAllOrders(symbol string) error {
+	instID := okxInstID(symbol)
+
+	data, err := t.request(http.MethodGet, "/api/v5/trade/orders-pending", url.Values{"instId": {instID}}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get pending orders: %w", err)
+	}
+	var pending []struct {
+		OrdID string `json:"ordId"`
+	}
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return fmt.Errorf("failed to parse pending orders: %w", err)
+	}
+	if len(pending) > 0 {
+		cancels := make([]map[string]string, 0, len(pending))
+		for _, order := range pending {
+			cancels = append(cancels, map[string]string{"instId": instID, "ordId": order.OrdID})
+		}
+		if _, err := t.request(http.MethodPost, "/api/v5/trade/cancel-batch-orders", nil, cancels); err != nil {
+			return fmt.Errorf("failed to cancel orders: %w", err)
+		}
+	}
+
+	query := url.Values{"instId": {instID}, "ordType": {"conditional"}}
+	data, err = t.request(http.MethodGet, "/api/v5/trade/orders-algo-pending", query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get pending trigger orders: %w", err)
+	}
+	var algos []struct {
+		AlgoID string `json:"algoId"`
+	}
+	if err := json.Unmarshal(data, &algos); err != nil {
+		return fmt.Errorf("failed to parse pending trigger orders: %w", err)
+	}
+	if len(algos) > 0 {
+		cancels := make([]map[string]string, 0, len(algos))
+		for _, algo := range algos {
+			cancels = append(cancels, map[string]string{"instId": instID, "algoId": algo.AlgoID})
+		}
+		if _, err := t.request(http.MethodPost, "/api/v5/trade/cancel-algos", nil, cancels); err != nil {
+			return fmt.Errorf("failed to cancel trigger orders: %w", err)
+		}
+	}
+
+	if len(pending)+len(algos) > 0 {
+		okxLog.Infof("  ✓ Cancelled %d pending orders for %s", len(pending)+len(algos), symbol)
+	}
+	return nil
+}
+
+// FormatQuantity formats a base coin quantity to the instrument's contract step
+func (t *OKXTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return "", err
+	}
+	step := dec(inst.ContractValue).Mul(dec(inst.LotSize))
+	if !step.IsPositive() {
+		return formatStepQuantity(quantity, 3), nil
+	}
+	rounded := dec(quantity).Div(step).Floor().Mul(step)
+	return rounded.StringFixed(int32(calculatePrecision(step.String()))), nil
+}
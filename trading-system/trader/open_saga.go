@@ -0,0 +1,388 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Open saga steps (the step that has not been confirmed yet)
+const (
+	SagaStepEntry       = "entry"        // Leverage may have changed, entry order sent but not confirmed
+	SagaStepPendingFill = "pending_fill" // Limit entry resting on the exchange (protected once it fills)
+	SagaStepTakeProfit  = "take_profit"  // Position open, take profit (and stop loss) orders not placed yet
+	SagaStepCompensate  = "compensate"   // Rolling back: close the position and restore leverage
+)
+
+const (
+	sagaTakeProfitAttempts = 3           // Take profit / stop loss placement attempts before the position is closed
+	sagaTakeProfitBackoff  = time.Second // Delay before the second attempt (doubles each retry)
+)
+
+// LeverageReader optional interface for exchanges that report a symbol's current leverage setting
+type LeverageReader interface {
+	// GetLeverage returns the leverage configured for symbol
+	GetLeverage(symbol string) (int, error)
+}
+
+// OpenSaga persisted state of a multi-step open: set leverage → market entry → take profit (and stop loss) orders.
+// Each step is recorded before it runs so a crash mid-way can be finished or rolled back on restart
+type OpenSaga struct {
+	ID           string    `json:"id"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"` // "long" or "short"
+	Quantity     float64   `json:"quantity"`
+	Leverage     int       `json:"leverage"`
+	PrevLeverage int       `json:"prev_leverage,omitempty"` // Leverage before the open (0 = unknown, not restored)
+	TakeProfit   float64   `json:"take_profit"`
+	StopLoss     float64   `json:"stop_loss,omitempty"` // 0 = no stop order (stops not honored)
+	Step         string    `json:"step"`
+	StartedAt    time.Time `json:"started_at"`
+	LastError    string    `json:"last_error,omitempty"`
+
+	// Limit / post_only entries (market entries leave these empty)
+	OrderType  string    `json:"order_type,omitempty"`
+	LimitPrice float64   `json:"limit_price,omitempty"`
+	OrderID    int64     `json:"order_id,omitempty"`   // Resting entry order
+	ExpiresAt  time.Time `json:"expires_at,omitempty"` // Unfilled remainder cancelled after this
+}
+
+// openSagaLog in-flight open sagas (finished sagas are removed)
+type openSagaLog struct {
+	path   string
+	mu     sync.Mutex
+	sagas  []*OpenSaga
+	active map[string]bool // Sagas being driven by this process right now (not resumable)
+}
+
+// newOpenSagaLog creates a saga log persisted at path, loading sagas left over from a previous run
+func newOpenSagaLog(path string) *openSagaLog {
+	l := &openSagaLog{path: path, active: make(map[string]bool)}
+	if err := l.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load open sagas (%s): %v", path, err)
+	}
+	return l
+}
+
+// begin records a new saga before its first step runs
+func (l *openSagaLog) begin(s *OpenSaga) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sagas = append(l.sagas, s)
+	l.active[s.ID] = true
+	l.save()
+}
+
+// advance persists a step transition
+func (l *openSagaLog) advance(s *OpenSaga, step string, stepErr error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s.Step = step
+	if stepErr != nil {
+		s.LastError = stepErr.Error()
+	}
+	l.save()
+}
+
+// finish removes a completed or rolled back saga
+func (l *openSagaLog) finish(s *OpenSaga) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kept := l.sagas[:0]
+	for _, saga := range l.sagas {
+		if saga.ID != s.ID {
+			kept = append(kept, saga)
+		}
+	}
+	l.sagas = kept
+	delete(l.active, s.ID)
+	l.save()
+}
+
+// release marks a saga as no longer driven by this process (it stays in the log to be resumed)
+func (l *openSagaLog) release(s *OpenSaga) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.active, s.ID)
+}
+
+// claimPending returns unfinished sagas no one is driving, marking them active (resting limit entries are
+// left to claimPendingFills)
+func (l *openSagaLog) claimPending() []*OpenSaga {
+	return l.claim(func(s *OpenSaga) bool { return s.Step != SagaStepPendingFill })
+}
+
+// claimPendingFills returns the resting limit entries no one is checking, marking them active
+func (l *openSagaLog) claimPendingFills() []*OpenSaga {
+	return l.claim(func(s *OpenSaga) bool { return s.Step == SagaStepPendingFill })
+}
+
+// claim marks the idle sagas matching match active and returns them
+func (l *openSagaLog) claim(match func(*OpenSaga) bool) []*OpenSaga {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var pending []*OpenSaga
+	for _, s := range l.sagas {
+		if !l.active[s.ID] && match(s) {
+			l.active[s.ID] = true
+			pending = append(pending, s)
+		}
+	}
+	return pending
+}
+
+// pendingFills snapshot of the resting limit entries
+func (l *openSagaLog) pendingFills() []OpenSaga {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var pending []OpenSaga
+	for _, s := range l.sagas {
+		if s.Step == SagaStepPendingFill {
+			pending = append(pending, *s)
+		}
+	}
+	return pending
+}
+
+// load reads persisted sagas (a missing file is not an error)
+func (l *openSagaLog) load() error {
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &l.sagas); err != nil {
+		return fmt.Errorf("failed to parse open sagas: %w", err)
+	}
+	return nil
+}
+
+// save persists the sagas (caller holds mu); failures are logged
+func (l *openSagaLog) save() {
+	data, err := json.MarshalIndent(l.sagas, "", "  ")
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize open sagas: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create open saga directory: %v", err)
+		return
+	}
+	tmpPath := l.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write open sagas: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace open sagas file: %v", err)
+	}
+}
+
+// openWithSaga opens a position and places its take profit (and stop loss, if > 0) as a saga. A failed entry
+// restores the previous leverage and cancels the entry; a protection order that still fails after retries
+// closes the naked position
+func (at *AutoTrader) openWithSaga(symbol, side string, quantity float64, leverage int, takeProfit, stopLoss float64) (*Order, error) {
+	saga := &OpenSaga{
+		ID:           fmt.Sprintf("%s_%s_%d", symbol, side, time.Now().UnixNano()),
+		Symbol:       symbol,
+		Side:         side,
+		Quantity:     quantity,
+		Leverage:     leverage,
+		PrevLeverage: at.currentLeverage(symbol),
+		TakeProfit:   takeProfit,
+		StopLoss:     stopLoss,
+		Step:         SagaStepEntry,
+		StartedAt:    time.Now(),
+	}
+	at.openSagas.begin(saga)
+
+	var order *Order
+	var err error
+	if side == "long" {
+		order, err = at.trader.OpenLong(symbol, quantity, leverage)
+	} else {
+		order, err = at.trader.OpenShort(symbol, quantity, leverage)
+	}
+	if err != nil {
+		at.openSagas.advance(saga, SagaStepEntry, err)
+		at.rollbackEntry(saga)
+		return nil, err
+	}
+
+	at.openSagas.advance(saga, SagaStepTakeProfit, nil)
+	if err := at.finishTakeProfit(saga); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// finishTakeProfit places the take profit and the stop loss (each retried with backoff) and completes the
+// saga, or compensates by closing the position
+func (at *AutoTrader) finishTakeProfit(saga *OpenSaga) error {
+	positionSide := positionSideOf(saga.Side)
+	if saga.TakeProfit > 0 {
+		err := placeProtectionOrder(saga, "take profit", func() error {
+			return at.positionProtection.place(at.trader, ClientOrderKindTakeProfit, saga.Symbol, positionSide, saga.Quantity, saga.TakeProfit)
+		})
+		if err != nil {
+			return at.failProtection(saga, "take profit", err)
+		}
+	}
+	if saga.StopLoss > 0 {
+		err := placeProtectionOrder(saga, "stop loss", func() error {
+			return at.positionProtection.place(at.trader, ClientOrderKindStopLoss, saga.Symbol, positionSide, saga.Quantity, saga.StopLoss)
+		})
+		if err != nil {
+			return at.failProtection(saga, "stop loss", err)
+		}
+	}
+	at.openSagas.finish(saga)
+	return nil
+}
+
+// placeProtectionOrder runs place up to sagaTakeProfitAttempts times with backoff
+func placeProtectionOrder(saga *OpenSaga, kind string, place func() error) error {
+	var err error
+	delay := sagaTakeProfitBackoff
+	for attempt := 1; attempt <= sagaTakeProfitAttempts; attempt++ {
+		if err = place(); err == nil {
+			return nil
+		}
+		traderLog.Warnf("  ⚠ Failed to set %s for %s %s (attempt %d/%d): %v",
+			kind, saga.Symbol, saga.Side, attempt, sagaTakeProfitAttempts, err)
+		if attempt < sagaTakeProfitAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// failProtection compensates an open whose protection order could not be placed by closing the position
+func (at *AutoTrader) failProtection(saga *OpenSaga, kind string, err error) error {
+	at.openSagas.advance(saga, SagaStepCompensate, err)
+	if compErr := at.compensateOpen(saga); compErr != nil {
+		return fmt.Errorf("%s could not be placed (%v) and rollback failed: %w", kind, err, compErr)
+	}
+	return fmt.Errorf("%s could not be placed after %d attempts, position closed: %w", kind, sagaTakeProfitAttempts, err)
+}
+
+// rollbackEntry compensates a failed or unconfirmed entry: cancel resting orders and restore leverage
+func (at *AutoTrader) rollbackEntry(saga *OpenSaga) {
+	if err := at.trader.CancelAllOrders(saga.Symbol); err != nil {
+		at.log.Warnf("  ⚠ Saga rollback: failed to cancel %s orders: %v", saga.Symbol, err)
+	}
+	at.restoreLeverage(saga)
+	at.openSagas.finish(saga)
+	at.log.Infof("  ↩️  Open %s %s rolled back", saga.Symbol, saga.Side)
+}
+
+// compensateOpen closes the position opened by the saga and restores leverage. On failure the saga
+// stays in the log and is retried next cycle
+func (at *AutoTrader) compensateOpen(saga *OpenSaga) error {
+	var err error
+	if saga.Side == "long" {
+		_, err = at.trader.CloseLong(saga.Symbol, saga.Quantity)
+	} else {
+		_, err = at.trader.CloseShort(saga.Symbol, saga.Quantity)
+	}
+	if err != nil {
+		at.openSagas.advance(saga, SagaStepCompensate, err)
+		at.openSagas.release(saga)
+		at.log.Errorf("  ❌ Saga rollback: failed to close unprotected %s %s (will retry): %v", saga.Symbol, saga.Side, err)
+		return err
+	}
+	at.restoreLeverage(saga)
+	at.openSagas.finish(saga)
+	at.log.Infof("  ↩️  Unprotected %s %s closed (open rolled back)", saga.Symbol, saga.Side)
+	return nil
+}
+
+// restoreLeverage sets the symbol back to the leverage it had before the saga (when known)
+func (at *AutoTrader) restoreLeverage(saga *OpenSaga) {
+	if saga.PrevLeverage <= 0 || saga.PrevLeverage == saga.Leverage {
+		return
+	}
+	if err := at.trader.SetLeverage(saga.Symbol, saga.PrevLeverage); err != nil {
+		at.log.Warnf("  ⚠ Saga rollback: failed to restore %s leverage to %dx: %v", saga.Symbol, saga.PrevLeverage, err)
+	}
+}
+
+// currentLeverage the symbol's leverage before an open (0 = unknown)
+func (at *AutoTrader) currentLeverage(symbol string) int {
+	if reader, ok := baseTrader(at.trader).(LeverageReader); ok {
+		if leverage, err := reader.GetLeverage(symbol); err == nil {
+			return leverage
+		}
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return 0
+	}
+	if pos, ok := findPosition(positions, symbol, ""); ok {
+		return pos.Leverage
+	}
+	return 0
+}
+
+// resumeOpenSagas finishes or rolls back opens interrupted by a crash (or whose rollback failed earlier)
+func (at *AutoTrader) resumeOpenSagas() {
+	pending := at.openSagas.claimPending()
+	if len(pending) == 0 {
+		return
+	}
+	at.log.Infof("🔁 Resuming %d interrupted open(s)", len(pending))
+
+	for _, saga := range pending {
+		switch saga.Step {
+		case SagaStepEntry:
+			// The entry may or may not have filled before the interruption
+			open, err := at.hasPosition(saga.Symbol, saga.Side)
+			if err != nil {
+				at.log.Warnf("  ⚠ Cannot resume open %s %s: %v", saga.Symbol, saga.Side, err)
+				at.openSagas.release(saga)
+				continue
+			}
+			if !open {
+				at.rollbackEntry(saga)
+				continue
+			}
+			at.openSagas.advance(saga, SagaStepTakeProfit, nil)
+			if err := at.finishTakeProfit(saga); err != nil {
+				at.log.Errorf("  ❌ Resumed open %s %s: %v", saga.Symbol, saga.Side, err)
+			}
+		case SagaStepTakeProfit:
+			if err := at.finishTakeProfit(saga); err != nil {
+				at.log.Errorf("  ❌ Resumed open %s %s: %v", saga.Symbol, saga.Side, err)
+			}
+		case SagaStepCompensate:
+			at.compensateOpen(saga)
+		default:
+			at.log.Warnf("  ⚠ Dropping open saga %s with unknown step %q", saga.ID, saga.Step)
+			at.openSagas.finish(saga)
+		}
+	}
+}
+
+// hasPosition whether a position exists for symbol/side
+func (at *AutoTrader) hasPosition(symbol, side string) (bool, error) {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return false, err
+	}
+	pos, ok := findPosition(positions, symbol, side)
+	return ok && pos.Quantity > 0, nil
+}
+
+// positionSideOf the exchange position side for "long"/"short"
+func positionSideOf(side string) string {
+	if side == "long" {
+		return "LONG"
+	}
+	return "SHORT"
+}
@@ -0,0 +1,182 @@
+package trader
+
+import (
+	"lia/logger"
+	"sync"
+	"time"
+)
+
+// orderReconcileWindow how long an order's fill is looked for before it is left as last reported
+const orderReconcileWindow = 24 * time.Hour
+
+// settledOrderRetention how long settled orders are kept in memory for ApplyFill
+const settledOrderRetention = time.Hour
+
+// OrderReader optional interface for exchanges that report a single order's status and fills
+type OrderReader interface {
+	// GetOrder returns the order's status, filled quantity and average fill price
+	GetOrder(symbol string, orderID int64) (*Order, error)
+}
+
+// OrderTracker records every order the trader submits (in the decision logger's orders table) and reconciles the
+// ones whose fill is not known yet against the exchange, so logged actions carry fill prices instead of the
+// pre-trade mark price
+type OrderTracker struct {
+	mu     sync.Mutex
+	store  *logger.DecisionLogger
+	reader OrderReader                   // nil = the exchange cannot report orders (records stay as submitted)
+	orders map[int64]*logger.OrderRecord // Recent and unsettled orders by order ID
+	now    func() time.Time
+}
+
+// newOrderTracker creates the tracker and resumes reconciling the orders left unsettled by the last run
+func newOrderTracker(store *logger.DecisionLogger, reader OrderReader, now func() time.Time) *OrderTracker {
+	ot := &OrderTracker{
+		store:  store,
+		reader: reader,
+		orders: make(map[int64]*logger.OrderRecord),
+		now:    now,
+	}
+	if reader == nil {
+		return ot
+	}
+	unsettled, err := store.GetOrders(true, 0)
+	if err != nil {
+		traderLog.Warnf("⚠ Failed to load unsettled orders: %v", err)
+		return ot
+	}
+	cutoff := now().Add(-orderReconcileWindow)
+	for i := range unsettled {
+		if unsettled[i].SubmittedAt.After(cutoff) {
+			ot.orders[unsettled[i].OrderID] = &unsettled[i]
+		}
+	}
+	if len(ot.orders) > 0 {
+		traderLog.Infof("📑 Reconciling %d orders left unsettled by the last run", len(ot.orders))
+	}
+	return ot
+}
+
+// Track records a submitted order (orders without an exchange ID are not tracked)
+func (ot *OrderTracker) Track(action, orderType string, order *Order, quantity float64) {
+	if ot == nil || order == nil || order.OrderID == 0 {
+		return
+	}
+	now := ot.now()
+	record := &logger.OrderRecord{
+		OrderID:        order.OrderID,
+		ClientOrderID:  order.ClientOrderID,
+		Symbol:         order.Symbol,
+		Action:         action,
+		OrderType:      orderType,
+		Status:         order.Status,
+		Quantity:       quantity,
+		FilledQuantity: order.ExecutedQty,
+		AvgPrice:       order.Price,
+		Fee:            order.Fee,
+		SubmittedAt:    now,
+		UpdatedAt:      now,
+	}
+
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	if err := ot.store.SaveOrder(record); err != nil {
+		traderLog.Warnf("⚠ Failed to record order %d: %v", order.OrderID, err)
+	}
+	ot.orders[record.OrderID] = record
+}
+
+// ApplyFill replaces the pre-trade price and quantity of a logged action with its order's fill, querying the
+// exchange when the fill is not known yet. The action's price is kept on the order record as its mark price
+func (ot *OrderTracker) ApplyFill(action *logger.DecisionAction) {
+	if ot == nil || action.OrderID == 0 || !action.Success {
+		return
+	}
+	ot.mu.Lock()
+	record, ok := ot.orders[action.OrderID]
+	ot.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	record.MarkPrice = action.Price
+	if ot.reconcile(record) {
+		action.Quantity = record.FilledQuantity
+	}
+	if record.AvgPrice > 0 {
+		action.Price = record.AvgPrice
+	}
+}
+
+// Reconcile queries the exchange for every order whose fill is not known yet and writes settled fills back to
+// the logged actions. Orders older than orderReconcileWindow are left as last reported
+func (ot *OrderTracker) Reconcile() {
+	if ot == nil {
+		return
+	}
+	now := ot.now()
+	ot.mu.Lock()
+	var records []*logger.OrderRecord
+	for id, record := range ot.orders {
+		if !record.Settled() && ot.reader != nil {
+			records = append(records, record)
+		} else if record.UpdatedAt.Before(now.Add(-settledOrderRetention)) {
+			delete(ot.orders, id)
+		}
+	}
+	ot.mu.Unlock()
+
+	cutoff := now.Add(-orderReconcileWindow)
+	for _, record := range records {
+		if record.SubmittedAt.Before(cutoff) {
+			traderLog.Warnf("⚠ Order %d (%s %s) still %s after %v, no longer reconciled", record.OrderID, record.Action,
+				record.Symbol, record.Status, orderReconcileWindow)
+			ot.forget(record)
+			continue
+		}
+		if ot.reconcile(record) {
+			if updated, err := ot.store.ApplyOrderFill(record); err != nil {
+				traderLog.Warnf("⚠ Failed to apply order %d fill to its decision action: %v", record.OrderID, err)
+			} else if updated > 0 {
+				traderLog.Infof("📑 Order %d (%s %s) reconciled: %.4f filled @ %.4f", record.OrderID, record.Action,
+					record.Symbol, record.FilledQuantity, record.AvgPrice)
+			}
+		}
+	}
+}
+
+// reconcile refreshes an unsettled record from the exchange and saves it. Returns whether the order is settled
+func (ot *OrderTracker) reconcile(record *logger.OrderRecord) bool {
+	if !record.Settled() && ot.reader != nil {
+		order, err := ot.reader.GetOrder(record.Symbol, record.OrderID)
+		if err != nil {
+			traderLog.Warnf("⚠ Failed to query order %d (%s): %v", record.OrderID, record.Symbol, err)
+			return false
+		}
+		record.Status = order.Status
+		if order.ExecutedQty > 0 {
+			record.FilledQuantity = order.ExecutedQty
+		}
+		if order.Price > 0 {
+			record.AvgPrice = order.Price
+		}
+		if order.Fee > 0 {
+			record.Fee = order.Fee
+		}
+	}
+	record.UpdatedAt = ot.now()
+
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	if err := ot.store.SaveOrder(record); err != nil {
+		traderLog.Warnf("⚠ Failed to update order %d: %v", record.OrderID, err)
+	}
+	return record.Settled()
+}
+
+// forget stops tracking an order
+func (ot *OrderTracker) forget(record *logger.OrderRecord) {
+	ot.mu.Lock()
+	delete(ot.orders, record.OrderID)
+	ot.mu.Unlock()
+}
@@ -0,0 +1,585 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"lia/ratelimit"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ownershipFillRetention how long attributed exchange fills are remembered (so a fill is attributed only once)
+const ownershipFillRetention = 24 * time.Hour
+
+// OwnedPosition the part of an exchange position one trader owns
+type OwnedPosition struct {
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"` // long/short
+	Quantity   float64 `json:"quantity"`
+	EntryPrice float64 `json:"entry_price"` // Average price of this trader's fills
+	Leverage   int     `json:"leverage"`
+}
+
+// accountOwnership the owned positions and realized P&L of the traders sharing one exchange account
+type accountOwnership struct {
+	Positions map[string]map[string]*OwnedPosition `json:"positions"` // trader ID -> symbol_side -> owned part
+	Realized  map[string]float64                   `json:"realized"`  // trader ID -> realized P&L of owned quantity (before fees)
+	Fees      map[string]float64                   `json:"fees"`      // trader ID -> fees of the trader's orders
+	LastMarks map[string]float64                   `json:"last_marks"`
+	LastSync  time.Time                            `json:"last_sync"`
+
+	members  map[string]bool     // Traders of this process on the account
+	inFlight map[string]int      // symbol_side -> orders being placed (not reconciled until recorded)
+	fills    map[int64]time.Time // Exchange fills already attributed by Sync
+}
+
+// OwnershipLedger attributes positions on exchange accounts shared by several traders to the trader that opened
+// them: every open and close placed through a trader is recorded under its ID, fills the traders did not record
+// (exchange stop loss / take profit, liquidations, manual closes) are attributed by their client order tag, or
+// pro rata when untagged. Owned positions give each trader its own margin, unrealized and realized P&L
+type OwnershipLedger struct {
+	mu       sync.Mutex
+	path     string
+	accounts map[string]*accountOwnership // By account key
+}
+
+// OwnershipView a trader's share of its exchange account
+type OwnershipView struct {
+	TraderID      string              `json:"trader_id"`
+	Account       string              `json:"account"`
+	SharedWith    []string            `json:"shared_with"` // Other traders on the same account
+	Positions     []OwnedPositionView `json:"positions"`
+	Unattributed  []OwnedPositionView `json:"unattributed"` // Exchange quantity no trader of this process owns
+	MarginUsed    float64             `json:"margin_used"`
+	UnrealizedPnL float64             `json:"unrealized_pnl"`
+	RealizedPnL   float64             `json:"realized_pnl"`
+	Fees          float64             `json:"fees"`
+}
+
+// OwnedPositionView an owned position valued at the exchange's mark price
+type OwnedPositionView struct {
+	OwnedPosition
+	MarkPrice     float64 `json:"mark_price"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	MarginUsed    float64 `json:"margin_used"`
+	ShareOfPct    float64 `json:"share_of_position_pct"` // Share of the exchange position
+}
+
+// NewOwnershipLedger creates the ledger, restoring the state saved at path
+func NewOwnershipLedger(path string) *OwnershipLedger {
+	l := &OwnershipLedger{path: path, accounts: make(map[string]*accountOwnership)}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &l.accounts); err != nil {
+			traderLog.Warnf("⚠️  Failed to load position ownership (%s): %v", path, err)
+			l.accounts = make(map[string]*accountOwnership)
+		}
+	} else if !os.IsNotExist(err) {
+		traderLog.Warnf("⚠️  Failed to read position ownership (%s): %v", path, err)
+	}
+	return l
+}
+
+// accountKeyOf identifies the exchange account a trader trades on (traders with the same key share positions)
+func accountKeyOf(config AutoTraderConfig) string {
+	switch config.Exchange {
+	case "binance":
+		return ratelimit.AccountKey("binance", config.BinanceAPIKey)
+	case "okx":
+		return ratelimit.AccountKey("okx", config.OKXAPIKey)
+	case "bybit":
+		return ratelimit.AccountKey("bybit", config.BybitAPIKey)
+	case "hyperliquid":
+		return "hyperliquid:" + strings.ToLower(config.HyperliquidWalletAddr)
+	case "aster":
+		return "aster:" + strings.ToLower(config.AsterUser)
+	}
+	return config.Exchange + ":" + config.ID // Simulated accounts belong to one trader
+}
+
+// account returns (creating if needed) an account's ownership (caller holds l.mu)
+func (l *OwnershipLedger) account(key string) *accountOwnership {
+	a, ok := l.accounts[key]
+	if !ok {
+		a = &accountOwnership{}
+		l.accounts[key] = a
+	}
+	if a.Positions == nil {
+		a.Positions = make(map[string]map[string]*OwnedPosition)
+	}
+	if a.Realized == nil {
+		a.Realized = make(map[string]float64)
+	}
+	if a.Fees == nil {
+		a.Fees = make(map[string]float64)
+	}
+	if a.LastMarks == nil {
+		a.LastMarks = make(map[string]float64)
+	}
+	if a.members == nil {
+		a.members = make(map[string]bool)
+		a.inFlight = make(map[string]int)
+		a.fills = make(map[int64]time.Time)
+	}
+	return a
+}
+
+// register adds a trader to an account
+func (l *OwnershipLedger) register(accountKey, traderID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.account(accountKey).members[traderID] = true
+}
+
+// shared whether other traders are registered on the account (false without a ledger)
+func (l *OwnershipLedger) shared(accountKey string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.account(accountKey).members) > 1
+}
+
+// symbolShare the trader's share (0-1) of the owned quantity of a symbol's positions on the account, both sides
+func (l *OwnershipLedger) symbolShare(accountKey, traderID, symbol string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+	var own, total float64
+	for key, quantity := range a.ownedTotals() {
+		if s, _ := splitPositionKey(key); s == symbol {
+			total += quantity
+		}
+	}
+	for key, p := range a.Positions[traderID] {
+		if s, _ := splitPositionKey(key); s == symbol {
+			own += p.Quantity
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	return own / total
+}
+
+// begin marks an order on a position as being placed: Sync leaves the position alone until it is recorded
+func (l *OwnershipLedger) begin(accountKey, symbol, side string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.account(accountKey).inFlight[symbol+"_"+side]++
+}
+
+// end finishes an order begun with begin (call after recording it, or when it failed)
+func (l *OwnershipLedger) end(accountKey, symbol, side string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+	key := symbol + "_" + side
+	if a.inFlight[key] > 1 {
+		a.inFlight[key]--
+	} else {
+		delete(a.inFlight, key)
+	}
+}
+
+// RecordOpen adds a trader's fill to its owned position at the averaged entry price
+func (l *OwnershipLedger) RecordOpen(accountKey, traderID, symbol, side string, quantity, price float64, leverage int, fee float64) {
+	if l == nil || quantity <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+	a.add(traderID, symbol, side, quantity, price, leverage)
+	if fee > 0 {
+		a.Fees[traderID] = addUSDT(a.Fees[traderID], fee)
+	}
+	l.save()
+}
+
+// RecordClose removes a closed quantity: first from the closing trader's own part, the rest (a close of more than
+// it owns) from the other owners pro rata. Each owner realizes the P&L of its part at price
+func (l *OwnershipLedger) RecordClose(accountKey, traderID, symbol, side string, quantity, price, fee float64) {
+	if l == nil || quantity <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+	key := symbol + "_" + side
+	remaining := quantity - a.reduce(traderID, key, quantity, price)
+	if remaining > 0 {
+		a.reduceProRata(key, remaining, price)
+	}
+	if fee > 0 {
+		a.Fees[traderID] = addUSDT(a.Fees[traderID], fee)
+	}
+	l.save()
+}
+
+// Sync attributes position reductions no trader recorded: owned quantity above the exchange's goes first to the
+// owners named by the client order tags of new stop loss / take profit fills (history; nil = not available),
+// the rest pro rata. Quantity above the owned total stays unattributed
+func (l *OwnershipLedger) Sync(accountKey string, positions []Position, history func(symbol string, since time.Time) []ExchangeOrder, now time.Time) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+
+	exchangeQty := make(map[string]float64)
+	for _, pos := range positions {
+		key := pos.Symbol + "_" + pos.Side
+		exchangeQty[key] = pos.Quantity
+		if pos.MarkPrice > 0 {
+			a.LastMarks[key] = pos.MarkPrice
+		}
+	}
+	for id, seenAt := range a.fills {
+		if now.Sub(seenAt) > ownershipFillRetention {
+			delete(a.fills, id)
+		}
+	}
+
+	changed := false
+	for key, total := range a.ownedTotals() {
+		excess := total - exchangeQty[key]
+		if excess <= total*1e-6 || a.inFlight[key] > 0 {
+			continue
+		}
+		symbol, side := splitPositionKey(key)
+		price := a.LastMarks[key]
+
+		// Stop loss / take profit fills name their owner
+		if history != nil {
+			tags := a.memberTags()
+			closeSide := "SELL"
+			if side == "short" {
+				closeSide = "BUY"
+			}
+			since := a.LastSync.Add(-time.Minute)
+			if oldest := now.Add(-positionHistoryWindow); since.Before(oldest) {
+				since = oldest
+			}
+			for _, fill := range history(symbol, since) {
+				if excess <= 0 {
+					break
+				}
+				if _, done := a.fills[fill.OrderID]; done || fill.ExecutedQty <= 0 || fill.Side != closeSide {
+					continue
+				}
+				if fill.PositionSide != positionSideOf(side) && fill.PositionSide != "BOTH" && fill.PositionSide != "" {
+					continue
+				}
+				tag, kind, tagged := parseClientOrderID(fill.ClientOrderID)
+				owner, known := tags[tag]
+				if !tagged || !known || (kind != ClientOrderKindStopLoss && kind != ClientOrderKindTakeProfit) {
+					continue
+				}
+				a.fills[fill.OrderID] = fill.Time
+				fillPrice := fill.AvgPrice
+				if fillPrice <= 0 {
+					fillPrice = price
+				}
+				reduced := a.reduce(owner, key, math.Min(fill.ExecutedQty, excess), fillPrice)
+				excess -= reduced
+				if reduced > 0 {
+					traderLog.Infof("  🏷️  %s %s: %.4f closed by %s's %s order attributed to it", symbol, strings.ToUpper(side), reduced, owner, kind)
+				}
+			}
+		}
+		if excess > 0 {
+			a.reduceProRata(key, excess, price)
+			traderLog.Infof("  🏷️  %s %s: %.4f closed outside the traders (manual, liquidation or untagged order) taken from its owners pro rata", symbol, strings.ToUpper(side), excess)
+		}
+		changed = true
+	}
+	a.LastSync = now
+	if changed {
+		l.save()
+	}
+}
+
+// Seed attributes a position no trader owns yet to the traders whose tagged opening fills built it (newest
+// first, up to the position's quantity). Untagged fills stay unattributed
+func (l *OwnershipLedger) Seed(accountKey string, pos Position, contributors []ExchangeOrder) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+	key := pos.Symbol + "_" + pos.Side
+	if a.ownedTotals()[key] > 0 {
+		return
+	}
+
+	tags := a.memberTags()
+	remaining := pos.Quantity
+	seeded := false
+	for _, fill := range contributors {
+		if remaining <= 0 {
+			break
+		}
+		quantity := math.Min(fill.ExecutedQty, remaining)
+		remaining -= quantity
+		tag, _, tagged := parseClientOrderID(fill.ClientOrderID)
+		owner, known := tags[tag]
+		if !tagged || !known {
+			continue
+		}
+		price := fill.AvgPrice
+		if price <= 0 {
+			price = pos.EntryPrice
+		}
+		a.add(owner, pos.Symbol, pos.Side, quantity, price, pos.Leverage)
+		seeded = true
+	}
+	if seeded {
+		l.save()
+	}
+}
+
+// View values a trader's owned positions at the exchange positions' mark prices
+func (l *OwnershipLedger) View(accountKey, traderID string, positions []Position) *OwnershipView {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a := l.account(accountKey)
+
+	view := &OwnershipView{
+		TraderID:     traderID,
+		Account:      accountKey,
+		SharedWith:   []string{},
+		Positions:    []OwnedPositionView{},
+		Unattributed: []OwnedPositionView{},
+		RealizedPnL:  a.Realized[traderID],
+		Fees:         a.Fees[traderID],
+	}
+	for member := range a.members {
+		if member != traderID {
+			view.SharedWith = append(view.SharedWith, member)
+		}
+	}
+	sort.Strings(view.SharedWith)
+
+	totals := a.ownedTotals()
+	for _, pos := range positions {
+		key := pos.Symbol + "_" + pos.Side
+		if owned, ok := a.Positions[traderID][key]; ok {
+			v := valueOwned(*owned, pos)
+			view.Positions = append(view.Positions, v)
+			view.MarginUsed = addUSDT(view.MarginUsed, v.MarginUsed)
+			view.UnrealizedPnL = addUSDT(view.UnrealizedPnL, v.UnrealizedPnL)
+		}
+		if rest := pos.Quantity - totals[key]; rest > pos.Quantity*1e-6 {
+			view.Unattributed = append(view.Unattributed, valueOwned(OwnedPosition{
+				Symbol: pos.Symbol, Side: pos.Side, Quantity: rest, EntryPrice: pos.EntryPrice, Leverage: pos.Leverage,
+			}, pos))
+		}
+	}
+	return view
+}
+
+// valueOwned values an owned part of pos at its mark price
+func valueOwned(owned OwnedPosition, pos Position) OwnedPositionView {
+	v := OwnedPositionView{OwnedPosition: owned, MarkPrice: pos.MarkPrice}
+	if pos.Quantity > 0 {
+		v.ShareOfPct = owned.Quantity / pos.Quantity * 100
+	}
+	v.UnrealizedPnL = usdtFloat(dec(owned.Quantity).Mul(dec(pos.MarkPrice).Sub(dec(owned.EntryPrice))))
+	if owned.Side == "short" {
+		v.UnrealizedPnL = -v.UnrealizedPnL
+	}
+	leverage := owned.Leverage
+	if leverage <= 0 {
+		leverage = max(pos.Leverage, 1)
+	}
+	v.MarginUsed = marginForQuantity(owned.Quantity, pos.MarkPrice, leverage)
+	return v
+}
+
+// add adds quantity at price to a trader's owned position
+func (a *accountOwnership) add(traderID, symbol, side string, quantity, price float64, leverage int) {
+	owned, ok := a.Positions[traderID]
+	if !ok {
+		owned = make(map[string]*OwnedPosition)
+		a.Positions[traderID] = owned
+	}
+	key := symbol + "_" + side
+	p, ok := owned[key]
+	if !ok {
+		owned[key] = &OwnedPosition{Symbol: symbol, Side: side, Quantity: quantity, EntryPrice: price, Leverage: leverage}
+		return
+	}
+	total := p.Quantity + quantity
+	p.EntryPrice = (p.Quantity*p.EntryPrice + quantity*price) / total
+	p.Quantity = total
+	if leverage > 0 {
+		p.Leverage = leverage
+	}
+}
+
+// reduce removes up to quantity from a trader's owned position, realizing its P&L at price. Returns the
+// quantity removed
+func (a *accountOwnership) reduce(traderID, key string, quantity, price float64) float64 {
+	p, ok := a.Positions[traderID][key]
+	if !ok || quantity <= 0 {
+		return 0
+	}
+	reduced := math.Min(quantity, p.Quantity)
+	if price > 0 {
+		pnl := usdtFloat(dec(reduced).Mul(dec(price).Sub(dec(p.EntryPrice))))
+		if p.Side == "short" {
+			pnl = -pnl
+		}
+		a.Realized[traderID] = addUSDT(a.Realized[traderID], pnl)
+	}
+	p.Quantity -= reduced
+	if p.Quantity <= reduced*1e-9 || p.Quantity <= 0 {
+		delete(a.Positions[traderID], key)
+		if len(a.Positions[traderID]) == 0 {
+			delete(a.Positions, traderID)
+		}
+	}
+	return reduced
+}
+
+// reduceProRata removes quantity from every owner of a position in proportion to its part
+func (a *accountOwnership) reduceProRata(key string, quantity, price float64) {
+	total := a.ownedTotals()[key]
+	if total <= 0 {
+		return
+	}
+	share := math.Min(quantity/total, 1)
+	for traderID, owned := range a.Positions {
+		if p, ok := owned[key]; ok {
+			a.reduce(traderID, key, p.Quantity*share, price)
+		}
+	}
+}
+
+// ownedTotals the owned quantity of every position across traders
+func (a *accountOwnership) ownedTotals() map[string]float64 {
+	totals := make(map[string]float64)
+	for _, owned := range a.Positions {
+		for key, p := range owned {
+			totals[key] += p.Quantity
+		}
+	}
+	return totals
+}
+
+// memberTags maps the client order tags of the account's traders (and of owners restored from disk) to their IDs
+func (a *accountOwnership) memberTags() map[string]string {
+	tags := make(map[string]string)
+	for id := range a.members {
+		tags[ClientOrderTag(id)] = id
+	}
+	for id := range a.Positions {
+		tags[ClientOrderTag(id)] = id
+	}
+	return tags
+}
+
+// save writes the ledger to disk (caller holds l.mu)
+func (l *OwnershipLedger) save() {
+	data, err := json.MarshalIndent(l.accounts, "", "  ")
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize position ownership: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create position ownership directory: %v", err)
+		return
+	}
+	tmpPath := l.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write position ownership: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace position ownership file: %v", err)
+	}
+}
+
+// splitPositionKey splits a symbol_side key
+func splitPositionKey(key string) (symbol, side string) {
+	i := strings.LastIndex(key, "_")
+	if i < 0 {
+		return key, ""
+	}
+	return key[:i], key[i+1:]
+}
+
+// SetOwnershipLedger attributes this trader's orders on its exchange account in the shared ownership ledger
+// (set before the trader starts)
+func (at *AutoTrader) SetOwnershipLedger(ledger *OwnershipLedger) {
+	if at.config.DryRun {
+		return // Shadow fills never reach the account, they must not shift the live traders' shares
+	}
+	at.ownership = ledger
+	ledger.register(at.accountKey, at.id)
+	if lt, ok := asLedgerTrader(at.trader); ok {
+		lt.ownership = ledger
+		lt.accountKey = at.accountKey
+		lt.traderID = at.id
+	}
+}
+
+// GetOwnership returns this trader's owned share of its exchange account (nil when there is no ownership ledger)
+func (at *AutoTrader) GetOwnership() (*OwnershipView, error) {
+	if at.ownership == nil {
+		return nil, nil
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	return at.ownership.View(at.accountKey, at.id, positions), nil
+}
+
+// incomeShare how this trader's part of the account's exchange income is found (nil = the account is not shared,
+// all income of its traded symbols is its own)
+func (at *AutoTrader) incomeShare() *incomeShare {
+	if !at.ownership.shared(at.accountKey) {
+		return nil
+	}
+	return &incomeShare{
+		tag: ClientOrderTag(at.id),
+		owned: func(symbol string) float64 {
+			return at.ownership.symbolShare(at.accountKey, at.id, symbol)
+		},
+	}
+}
+
+// syncOwnership attributes the position changes of the cycle's snapshot that no trader recorded
+func (at *AutoTrader) syncOwnership(positions []Position) {
+	if at.ownership == nil {
+		return
+	}
+	var history func(symbol string, since time.Time) []ExchangeOrder
+	if provider, ok := baseTrader(at.trader).(OrderHistoryProvider); ok {
+		history = func(symbol string, since time.Time) []ExchangeOrder {
+			orders, err := provider.GetOrderHistory(symbol, since, at.now())
+			if err != nil {
+				at.log.Warnf("  ⚠ Failed to get %s order history for ownership: %v", symbol, err)
+				return nil
+			}
+			return orders
+		}
+	}
+	at.ownership.Sync(at.accountKey, positions, history, at.now())
+}
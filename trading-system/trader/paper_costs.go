@@ -0,0 +1,143 @@
+package trader
+
+import (
+	"lia/config"
+	"lia/market"
+	"math"
+	"time"
+)
+
+// IncomeFundingFee income type of funding payments (same as Binance reports them)
+const IncomeFundingFee = "FUNDING_FEE"
+
+// SetTradingCosts makes paper fills pay taker/maker fees, slip from mark price and open positions pay or
+// receive funding, so paper results approximate live trading
+func (t *PaperTrader) SetTradingCosts(cfg config.PaperCostConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.costs = &cfg
+
+	funding := "off"
+	if cfg.FundingIntervalHours > 0 {
+		funding = "every " + (time.Duration(cfg.FundingIntervalHours) * time.Hour).String()
+	}
+	paperLog.Infof("💸 [Simulated] Trading costs enabled: taker %.2f bps, maker %.2f bps, %s slippage (%.2f bps), funding %s",
+		cfg.TakerFeeBps, cfg.MakerFeeBps, cfg.SlippageModel, cfg.SlippageBps, funding)
+}
+
+// slippedPrice moves a mark price fill against the order by the configured slippage (caller holds t.mu)
+// The volume model adds volume_impact_bps per 1% of the average 4h candle volume the order takes, capped at
+// max_slippage_bps; without volume data it falls back to slippage_bps
+func (t *PaperTrader) slippedPrice(symbol, side string, quantity, markPrice float64) float64 {
+	if t.costs == nil || t.costs.SlippageModel == "none" {
+		return markPrice
+	}
+
+	bps := t.costs.SlippageBps
+	if t.costs.SlippageModel == "volume" {
+		if data, err := market.Get(symbol); err == nil && data.LongerTermContext != nil && data.LongerTermContext.AverageVolume > 0 {
+			participationPct := quantity / data.LongerTermContext.AverageVolume * 100
+			bps = math.Min(bps+t.costs.VolumeImpactBps*participationPct, t.costs.MaxSlippageBps)
+		}
+	}
+
+	if side == "BUY" {
+		return markPrice * (1 + bps/10000)
+	}
+	return markPrice * (1 - bps/10000)
+}
+
+// atTakeProfit reports whether the position's take profit is reached at price: the close fills as the resting
+// take profit order would, as a maker without slippage (caller holds t.mu)
+func (t *PaperTrader) atTakeProfit(pos *PaperPosition, price float64) bool {
+	if t.costs == nil || pos.TakeProfit <= 0 {
+		return false
+	}
+	if pos.Side == "LONG" {
+		return price >= pos.TakeProfit
+	}
+	return price <= pos.TakeProfit
+}
+
+// chargeFee deducts the fee of a fill from the wallet and returns it (caller holds t.mu)
+func (t *PaperTrader) chargeFee(notional float64, maker bool) float64 {
+	if t.costs == nil {
+		return 0
+	}
+	bps := t.costs.TakerFeeBps
+	if maker {
+		bps = t.costs.MakerFeeBps
+	}
+	fee := usdtFloat(dec(notional).Mul(dec(bps)).Div(dec(10000)))
+	t.balance = addUSDT(t.balance, -fee)
+	return fee
+}
+
+// accrueFunding settles every funding time the position was held over, up to now, at the symbol's current
+// funding rate and mark price: longs pay positive rates and shorts receive them (caller holds t.mu)
+func (t *PaperTrader) accrueFunding(pos *PaperPosition, now time.Time) {
+	if t.costs == nil || t.costs.FundingIntervalHours <= 0 {
+		return
+	}
+	if pos.FundingPaidUntil.IsZero() {
+		// Opened (or restored) now: earlier funding times are not owed
+		pos.FundingPaidUntil = now
+		return
+	}
+
+	interval := time.Duration(t.costs.FundingIntervalHours) * time.Hour
+	next := pos.FundingPaidUntil.Truncate(interval).Add(interval) // Funding times are aligned to 00:00 UTC
+	if next.After(now) {
+		return
+	}
+	data, err := market.Get(pos.Symbol)
+	if err != nil {
+		return // Settled on a later call
+	}
+
+	for ; !next.After(now); next = next.Add(interval) {
+		payment := dec(pos.Quantity).Mul(dec(data.CurrentPrice)).Mul(dec(data.FundingRate))
+		if pos.Side == "LONG" {
+			payment = payment.Neg()
+		}
+		amount := usdtFloat(payment)
+		if amount == 0 {
+			continue
+		}
+		t.balance = addUSDT(t.balance, amount)
+		pos.Funding = addUSDT(pos.Funding, amount)
+		t.income = append(t.income, IncomeRecord{
+			Symbol: pos.Symbol,
+			Type:   IncomeFundingFee,
+			Amount: amount,
+			Time:   now,
+		})
+		paperLog.Infof("💸 [Simulated] Funding %s %s at %s: %+.4f USDT (rate %.4f%%)",
+			pos.Symbol, pos.Side, next.UTC().Format("15:04"), amount, data.FundingRate*100)
+	}
+	pos.FundingPaidUntil = now
+	if len(t.income) > maxPaperIncome {
+		t.income = t.income[len(t.income)-maxPaperIncome:]
+	}
+}
+
+// AccrueFunding settles the funding due on all open positions
+func (t *PaperTrader) AccrueFunding() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.costs == nil {
+		return
+	}
+	now := t.now()
+	for _, pos := range t.positions {
+		t.accrueFunding(pos, now)
+	}
+}
+
+// accruePaperFunding settles funding on paper positions (recorded in the P&L ledger on the next income sync)
+func (at *AutoTrader) accruePaperFunding() {
+	if paperTrader, ok := asPaperTrader(at.trader); ok {
+		paperTrader.AccrueFunding()
+	}
+}
@@ -0,0 +1,44 @@
+package trader
+
+import (
+	"lia/market"
+	"math"
+)
+
+// SetOrderBookFills makes orders with notional >= thresholdUSD fill at the average price of walking
+// the live order book (depthLimit levels per side) instead of at mark price
+func (t *PaperTrader) SetOrderBookFills(thresholdUSD float64, depthLimit int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bookFillThreshold = thresholdUSD
+	t.bookDepthLimit = depthLimit
+	paperLog.Infof("📚 [Simulated] Order book fills enabled for orders ≥ %.0f USDT notional (depth %d)", thresholdUSD, depthLimit)
+}
+
+// fillPrice returns the simulated fill price for a market order (caller holds t.mu)
+// Small orders, or any failure to get the book, fill at mark price plus the configured slippage
+func (t *PaperTrader) fillPrice(symbol, side string, quantity, markPrice float64) float64 {
+	if t.bookFillThreshold <= 0 || quantity*markPrice < t.bookFillThreshold {
+		return t.slippedPrice(symbol, side, quantity, markPrice)
+	}
+
+	book, err := market.GetOrderBook(symbol, t.bookDepthLimit)
+	if err != nil {
+		paperLog.Warnf("⚠️  [Simulated] Failed to get %s order book, filling at mark %.4f: %v", symbol, markPrice, err)
+		return t.slippedPrice(symbol, side, quantity, markPrice)
+	}
+	fill, err := book.WalkFill(side, quantity)
+	if err != nil {
+		paperLog.Warnf("⚠️  [Simulated] Failed to walk %s order book, filling at mark %.4f: %v", symbol, markPrice, err)
+		return t.slippedPrice(symbol, side, quantity, markPrice)
+	}
+
+	slippageBps := math.Abs(fill.AvgPrice-markPrice) / markPrice * 10000
+	paperLog.Infof("📚 [Simulated] %s %s %.4f (%.0f USDT) walked %d levels: avg %.4f vs mark %.4f (%.1f bps)",
+		symbol, side, quantity, quantity*markPrice, fill.Levels, fill.AvgPrice, markPrice, slippageBps)
+	if fill.Exhausted {
+		paperLog.Warnf("⚠️  [Simulated] %s order exceeds the %d-level book snapshot, remainder filled at %.4f",
+			symbol, t.bookDepthLimit, fill.WorstPrice)
+	}
+	return fill.AvgPrice
+}
@@ -0,0 +1,250 @@
+package trader
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// paperLimitOrderRetention how long finished limit orders stay queryable
+const paperLimitOrderRetention = 24 * time.Hour
+
+// paperLimitOrder a simulated limit entry: rests until the mark price reaches its price
+type paperLimitOrder struct {
+	orderID       int64
+	clientOrderID string
+	symbol        string
+	positionSide  string // "LONG" or "SHORT"
+	quantity      float64
+	leverage      int
+	price         float64
+	margin        float64 // Margin reserved while the order rests
+	status        string
+	executedQty   float64
+	avgPrice      float64
+	fee           float64
+	placedAt      time.Time
+}
+
+// order the order as the exchange reports it
+func (o *paperLimitOrder) order() *Order {
+	return &Order{
+		OrderID:       o.orderID,
+		ClientOrderID: o.clientOrderID,
+		Symbol:        o.symbol,
+		Status:        o.status,
+		Price:         o.avgPrice,
+		ExecutedQty:   o.executedQty,
+		Fee:           o.fee,
+	}
+}
+
+// crossed whether a mark price reaches the order's price (at or below it for buys, at or above it for sells)
+func (o *paperLimitOrder) crossed(markPrice float64) bool {
+	if o.positionSide == "LONG" {
+		return markPrice <= o.price
+	}
+	return markPrice >= o.price
+}
+
+// OpenLimit places a simulated limit entry. A price the mark has already reached fills immediately at the mark
+// as a taker, or expires when postOnly; otherwise the order rests with its margin reserved until GetOrder sees
+// the mark reach it
+func (t *PaperTrader) OpenLimit(symbol, positionSide string, quantity float64, leverage int, price float64, postOnly bool) (*Order, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	markPrice, err := t.getMarketPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market price: %w", err)
+	}
+	t.pruneLimitOrders()
+
+	orderID := t.nextOrderID()
+	o := &paperLimitOrder{
+		orderID:       orderID,
+		clientOrderID: newClientOrderIDAt(t.clientOrderTag, ClientOrderKindOpen, orderID),
+		symbol:        symbol,
+		positionSide:  positionSide,
+		quantity:      quantity,
+		leverage:      leverage,
+		price:         price,
+		status:        OrderStatusNew,
+		placedAt:      t.now(),
+	}
+
+	if o.crossed(markPrice) {
+		if postOnly {
+			o.status = OrderStatusExpired
+			t.limitOrders[orderID] = o
+			paperLog.Infof("⌛ [Simulated] Post-only %s %s @ %.4f expired: mark %.4f would fill it as a taker", symbol, positionSide, price, markPrice)
+			return o.order(), nil
+		}
+		fillAt := t.fillPrice(symbol, orderSide(positionSide), quantity, markPrice)
+		if err := t.checkLimitMargin(o, fillAt); err != nil {
+			return nil, err
+		}
+		t.limitOrders[orderID] = o
+		t.fillLimitOrder(o, fillAt, false)
+		return o.order(), nil
+	}
+
+	if err := t.checkLimitMargin(o, price); err != nil {
+		return nil, err
+	}
+	o.margin = limitMargin(quantity, price, leverage)
+	t.availableBalance = addUSDT(t.availableBalance, -o.margin)
+	t.limitOrders[orderID] = o
+
+	paperLog.Infof("⏳ [Simulated] Limit %s resting: %s %f @ %.4f (mark %.4f, Leverage %dx, Margin %.2f reserved)",
+		positionSide, symbol, quantity, price, markPrice, leverage, o.margin)
+	return o.order(), nil
+}
+
+// GetOrder returns a simulated order. A resting limit order fills at its price (as a maker) once the mark has
+// reached it. Orders this process does not know (placed before a restart) are reported as expired
+func (t *PaperTrader) GetOrder(symbol string, orderID int64) (*Order, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.limitOrders[orderID]
+	if !ok {
+		for i := len(t.orders) - 1; i >= 0; i-- {
+			if filled := t.orders[i]; filled.OrderID == orderID {
+				return &Order{
+					OrderID:       filled.OrderID,
+					ClientOrderID: filled.ClientOrderID,
+					Symbol:        filled.Symbol,
+					Status:        filled.Status,
+					Price:         filled.AvgPrice,
+					ExecutedQty:   filled.ExecutedQty,
+				}, nil
+			}
+		}
+		return &Order{OrderID: orderID, Symbol: symbol, Status: OrderStatusExpired}, nil
+	}
+	if o.status == OrderStatusNew {
+		if markPrice, err := t.getMarketPrice(o.symbol); err == nil && o.crossed(markPrice) {
+			t.fillLimitOrder(o, o.price, true)
+		}
+	}
+	return o.order(), nil
+}
+
+// CancelOrder cancels a resting simulated limit order and releases its margin
+func (t *PaperTrader) CancelOrder(symbol string, orderID int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.limitOrders[orderID]
+	if !ok {
+		return fmt.Errorf("order %d not found", orderID)
+	}
+	if o.status != OrderStatusNew {
+		return fmt.Errorf("order %d is already %s", orderID, o.status)
+	}
+	o.status = OrderStatusCanceled
+	t.availableBalance = addUSDT(t.availableBalance, o.margin)
+	paperLog.Infof("  ✓ [Simulated] Cancelled %s limit %s @ %.4f", symbol, o.positionSide, o.price)
+	return nil
+}
+
+// checkLimitMargin checks the balance (and for shorts the borrow limit) for filling o at price (caller holds t.mu)
+func (t *PaperTrader) checkLimitMargin(o *paperLimitOrder, price float64) error {
+	const tolerance = 0.1 // Same tolerance as market opens
+	margin := limitMargin(o.quantity, price, o.leverage)
+	if margin > t.availableBalance+tolerance {
+		return fmt.Errorf("insufficient available balance: need %.2f, available %.2f", margin, t.availableBalance)
+	}
+	if o.positionSide == "SHORT" {
+		return t.checkBorrowLimit(o.symbol, o.quantity*price)
+	}
+	return nil
+}
+
+// fillLimitOrder fills o at price: opens the position or adds to the open one at the averaged entry price,
+// charges the fee and records the fill (caller holds t.mu)
+func (t *PaperTrader) fillLimitOrder(o *paperLimitOrder, price float64, maker bool) {
+	now := t.now()
+	margin := limitMargin(o.quantity, price, o.leverage)
+	t.availableBalance = addUSDT(t.availableBalance, o.margin-margin)
+	o.margin = 0
+
+	key := o.symbol + "_" + o.positionSide
+	if pos, exists := t.positions[key]; exists {
+		totalQty := dec(pos.Quantity).Add(dec(o.quantity))
+		pos.EntryPrice, _ = dec(pos.Quantity).Mul(dec(pos.EntryPrice)).
+			Add(dec(o.quantity).Mul(dec(price))).Div(totalQty).Float64()
+		pos.Quantity, _ = totalQty.Float64()
+		pos.Leverage = o.leverage
+		pos.MarginUsed = addUSDT(pos.MarginUsed, margin)
+	} else {
+		pos = &PaperPosition{
+			Symbol:     o.symbol,
+			Side:       o.positionSide,
+			EntryPrice: price,
+			Quantity:   o.quantity,
+			Leverage:   o.leverage,
+			EntryTime:  now,
+			MarginUsed: margin,
+		}
+		t.positions[key] = pos
+		t.accrueBorrowInterest(pos, price, now)
+	}
+
+	o.status = OrderStatusFilled
+	o.executedQty = o.quantity
+	o.avgPrice = price
+	o.fee = t.chargeFee(o.quantity*price, maker)
+
+	t.appendOrder(ExchangeOrder{
+		Symbol:        o.symbol,
+		OrderID:       o.orderID,
+		ClientOrderID: o.clientOrderID,
+		Side:          orderSide(o.positionSide),
+		PositionSide:  o.positionSide,
+		Type:          "LIMIT",
+		Status:        OrderStatusFilled,
+		ExecutedQty:   o.quantity,
+		AvgPrice:      price,
+		Time:          now,
+	})
+	paperLog.Infof("✅ [Simulated] Limit %s filled: %s %f @ %.4f (Leverage %dx, Margin %.2f, Fee %.4f)",
+		o.positionSide, o.symbol, o.quantity, price, o.leverage, margin, o.fee)
+}
+
+// reservedMargin margin held by resting limit orders (caller holds t.mu)
+func (t *PaperTrader) reservedMargin() float64 {
+	total := 0.0
+	for _, o := range t.limitOrders {
+		if o.status == OrderStatusNew {
+			total = addUSDT(total, o.margin)
+		}
+	}
+	return total
+}
+
+// pruneLimitOrders forgets finished limit orders older than paperLimitOrderRetention (caller holds t.mu)
+func (t *PaperTrader) pruneLimitOrders() {
+	cutoff := t.now().Add(-paperLimitOrderRetention)
+	for id, o := range t.limitOrders {
+		if o.status != OrderStatusNew && o.placedAt.Before(cutoff) {
+			delete(t.limitOrders, id)
+		}
+	}
+}
+
+// limitMargin margin of quantity at price, rounded down to cents like market opens
+func limitMargin(quantity, price float64, leverage int) float64 {
+	margin, _ := dec(quantity).Mul(dec(price)).Div(decimal.NewFromInt(int64(leverage))).RoundFloor(2).Float64()
+	return margin
+}
+
+// orderSide the order side opening positionSide
+func orderSide(positionSide string) string {
+	if positionSide == "SHORT" {
+		return "SELL"
+	}
+	return "BUY"
+}
@@ -0,0 +1,318 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Paper shadow history kept in memory (oldest dropped first)
+const (
+	maxShadowFills  = 500
+	maxShadowPoints = 2000
+)
+
+// ShadowFill one order of the live trader and its paper twin
+type ShadowFill struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"` // open_long, close_short, ...
+	Symbol      string    `json:"symbol"`
+	Quantity    float64   `json:"quantity"`
+	LivePrice   float64   `json:"live_price"`   // 0 = not reported by the exchange
+	PaperPrice  float64   `json:"paper_price"`  // 0 = the paper order failed
+	LiveFee     float64   `json:"live_fee"`     // Fee the exchange reported inline (0 = not reported)
+	PaperFee    float64   `json:"paper_fee"`    // Simulated fee (paper_costs)
+	SlippageBps float64   `json:"slippage_bps"` // Live fill vs paper fill, positive = live filled worse
+	Reason      string    `json:"reason,omitempty"`
+	PaperError  string    `json:"paper_error,omitempty"`
+}
+
+// ShadowPoint live and paper equity at a cycle
+type ShadowPoint struct {
+	Time        time.Time `json:"time"`
+	Cycle       int       `json:"cycle"`
+	LiveEquity  float64   `json:"live_equity"`
+	PaperEquity float64   `json:"paper_equity"`
+}
+
+// ShadowSummary how far the live account drifted from its paper twin since the shadow started
+type ShadowSummary struct {
+	StartedAt          time.Time `json:"started_at"`
+	LiveStartEquity    float64   `json:"live_start_equity"`
+	PaperStartEquity   float64   `json:"paper_start_equity"`
+	LivePnL            float64   `json:"live_pnl"`
+	PaperPnL           float64   `json:"paper_pnl"`
+	TrackingDifference float64   `json:"tracking_difference"` // Live P&L - paper P&L (negative = execution cost)
+	Fills              int       `json:"fills"`
+	PaperFailures      int       `json:"paper_failures"`
+	AvgSlippageBps     float64   `json:"avg_slippage_bps"` // Over fills with both prices
+	LiveFees           float64   `json:"live_fees"`
+	PaperFees          float64   `json:"paper_fees"`
+}
+
+// PaperShadow a paper account that executes every order of a live trader with the same symbol, quantity and
+// leverage, so live fills, fees and the equity curve can be compared with an idealized execution. Paper
+// positions follow the live ones at every sync: a position closed on the exchange (take profit, stop,
+// liquidation) is closed on paper, one opened outside the order path (limit fill, held at startup) is opened
+type PaperShadow struct {
+	mu             sync.Mutex
+	paper          *PaperTrader // nil until the first sync (created with the live equity)
+	initialBalance float64      // 0 = the live equity at the first sync
+	configure      func(*PaperTrader)
+	startedAt      time.Time
+	liveStart      float64
+	lastLive       float64
+	fills          []ShadowFill
+	points         []ShadowPoint
+	fillCount      int
+	failures       int
+	slippageSum    float64
+	slippageCount  int
+	liveFees       float64
+	paperFees      float64
+}
+
+// newPaperShadow creates the shadow (initialBalance 0 = start at the live equity); configure applies the
+// trader's paper fill, short and cost settings to the paper account
+func newPaperShadow(initialBalance float64, configure func(*PaperTrader)) *PaperShadow {
+	return &PaperShadow{initialBalance: initialBalance, configure: configure}
+}
+
+// sync starts the paper account on first use, aligns the paper positions with the live ones and records a
+// curve point. Called at the start of every cycle
+func (ps *PaperShadow) sync(cycle int, liveEquity float64, livePositions []Position) {
+	if ps == nil {
+		return
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.paper == nil {
+		balance := ps.initialBalance
+		if balance <= 0 {
+			balance = liveEquity
+		}
+		if balance <= 0 {
+			return
+		}
+		ps.paper = NewPaperTrader(balance)
+		if ps.configure != nil {
+			ps.configure(ps.paper)
+		}
+		ps.startedAt = time.Now()
+		ps.liveStart = liveEquity
+		paperLog.Infof("👥 Paper shadow started with %.2f USDT (live equity %.2f)", balance, liveEquity)
+	}
+
+	paperPositions, err := ps.paper.GetPositions()
+	if err == nil {
+		for _, pos := range paperPositions {
+			if _, ok := findPosition(livePositions, pos.Symbol, pos.Side); ok {
+				continue
+			}
+			ps.mirrorLocked("close_"+pos.Side, pos.Symbol, 0, 0, nil, "closed on the live exchange")
+		}
+		for _, pos := range livePositions {
+			if pos.Side != "long" && pos.Side != "short" {
+				continue
+			}
+			if _, ok := findPosition(paperPositions, pos.Symbol, pos.Side); ok {
+				continue
+			}
+			leverage := pos.Leverage
+			if leverage <= 0 {
+				leverage = 1 // Not reported by the exchange
+			}
+			ps.mirrorLocked("open_"+pos.Side, pos.Symbol, pos.Quantity, leverage, nil, "opened on the live exchange")
+		}
+	}
+
+	ps.lastLive = liveEquity
+	paperEquity := 0.0
+	if balance, err := ps.paper.GetBalance(); err == nil {
+		paperEquity = balance.Equity()
+	}
+	ps.points = append(ps.points, ShadowPoint{Time: time.Now(), Cycle: cycle, LiveEquity: liveEquity, PaperEquity: paperEquity})
+	if len(ps.points) > maxShadowPoints {
+		ps.points = ps.points[len(ps.points)-maxShadowPoints:]
+	}
+}
+
+// mirror executes a live order on the paper account
+func (ps *PaperShadow) mirror(action, symbol string, quantity float64, leverage int, live *Order) {
+	if ps == nil {
+		return
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.mirrorLocked(action, symbol, quantity, leverage, live, "")
+}
+
+// mirrorLocked places the paper order and records both fills (ps.mu held)
+func (ps *PaperShadow) mirrorLocked(action, symbol string, quantity float64, leverage int, live *Order, reason string) {
+	if ps.paper == nil {
+		return
+	}
+
+	var paper *Order
+	var err error
+	switch action {
+	case "open_long":
+		paper, err = ps.paper.OpenLong(symbol, quantity, leverage)
+	case "open_short":
+		paper, err = ps.paper.OpenShort(symbol, quantity, leverage)
+	case "close_long":
+		paper, err = ps.paper.CloseLong(symbol, quantity)
+	case "close_short":
+		paper, err = ps.paper.CloseShort(symbol, quantity)
+	default:
+		return
+	}
+
+	fill := ShadowFill{Time: time.Now(), Action: action, Symbol: symbol, Quantity: quantity, Reason: reason}
+	if live != nil {
+		fill.LivePrice = live.Price
+		fill.LiveFee = live.Fee
+		if live.ExecutedQty > 0 {
+			fill.Quantity = live.ExecutedQty
+		}
+	}
+	if err != nil {
+		fill.PaperError = err.Error()
+		ps.failures++
+		paperLog.Warnf("  ⚠️  Paper shadow: %s %s failed: %v", action, symbol, err)
+	} else if paper != nil {
+		fill.PaperPrice = paper.Price
+		fill.PaperFee = paper.Fee
+		if fill.Quantity == 0 {
+			fill.Quantity = paper.ExecutedQty
+		}
+	}
+	if fill.LivePrice > 0 && fill.PaperPrice > 0 {
+		fill.SlippageBps = (fill.LivePrice - fill.PaperPrice) / fill.PaperPrice * 10000
+		if action == "open_short" || action == "close_long" {
+			fill.SlippageBps = -fill.SlippageBps // Selling lower than paper is worse
+		}
+		ps.slippageSum += fill.SlippageBps
+		ps.slippageCount++
+	}
+	ps.liveFees += fill.LiveFee
+	ps.paperFees += fill.PaperFee
+	ps.fillCount++
+
+	ps.fills = append(ps.fills, fill)
+	if len(ps.fills) > maxShadowFills {
+		ps.fills = ps.fills[len(ps.fills)-maxShadowFills:]
+	}
+}
+
+// protect mirrors a stop loss or take profit order on the paper position
+func (ps *PaperShadow) protect(kind, symbol, positionSide string, quantity, price float64) {
+	if ps == nil {
+		return
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.paper == nil {
+		return
+	}
+	if kind == "stop_loss" {
+		ps.paper.SetStopLoss(symbol, strings.ToUpper(positionSide), quantity, price)
+	} else {
+		ps.paper.SetTakeProfit(symbol, strings.ToUpper(positionSide), quantity, price)
+	}
+}
+
+// Summary the live vs paper comparison so far (nil before the first sync)
+func (ps *PaperShadow) Summary() *ShadowSummary {
+	if ps == nil {
+		return nil
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.paper == nil {
+		return nil
+	}
+
+	summary := &ShadowSummary{
+		StartedAt:        ps.startedAt,
+		LiveStartEquity:  ps.liveStart,
+		PaperStartEquity: ps.paper.initialBalance,
+		LivePnL:          ps.lastLive - ps.liveStart,
+		Fills:            ps.fillCount,
+		PaperFailures:    ps.failures,
+		LiveFees:         ps.liveFees,
+		PaperFees:        ps.paperFees,
+	}
+	if balance, err := ps.paper.GetBalance(); err == nil {
+		summary.PaperPnL = balance.Equity() - ps.paper.initialBalance
+	}
+	summary.TrackingDifference = summary.LivePnL - summary.PaperPnL
+	if ps.slippageCount > 0 {
+		summary.AvgSlippageBps = ps.slippageSum / float64(ps.slippageCount)
+	}
+	return summary
+}
+
+// History the recorded curve points and the latest fills (limit > 0 keeps the newest limit fills)
+func (ps *PaperShadow) History(limit int) ([]ShadowPoint, []ShadowFill) {
+	if ps == nil {
+		return nil, nil
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	fills := ps.fills
+	if limit > 0 && len(fills) > limit {
+		fills = fills[len(fills)-limit:]
+	}
+	return append([]ShadowPoint(nil), ps.points...), append([]ShadowFill(nil), fills...)
+}
+
+// PaperPositions the paper account's positions (nil before the first sync)
+func (ps *PaperShadow) PaperPositions() ([]Position, error) {
+	if ps == nil {
+		return nil, nil
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.paper == nil {
+		return nil, nil
+	}
+	positions, err := ps.paper.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("paper shadow positions: %w", err)
+	}
+	return positions, nil
+}
+
+// GetPaperShadow the trader's paper shadow (nil = none configured)
+func (at *AutoTrader) GetPaperShadow() *PaperShadow {
+	return at.paperShadow
+}
+
+// syncPaperShadow brings the paper shadow in line with the live account at the start of a cycle
+func (at *AutoTrader) syncPaperShadow() {
+	if at.paperShadow == nil {
+		return
+	}
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		at.log.Warnf("⚠️  Paper shadow sync skipped: failed to get balance: %v", err)
+		return
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		at.log.Warnf("⚠️  Paper shadow sync skipped: failed to get positions: %v", err)
+		return
+	}
+	at.paperShadow.sync(at.callCount, balance.Equity(), positions)
+}
+
+// describeShadowBalance the paper shadow's starting balance for logs
+func describeShadowBalance(initialBalance float64) string {
+	if initialBalance <= 0 {
+		return "= live equity"
+	}
+	return fmt.Sprintf("%.2f USDT", initialBalance)
+}
@@ -0,0 +1,218 @@
+package trader
+
+import (
+	"fmt"
+	"lia/config"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// IncomeBorrowInterest income type of simulated short borrow interest
+	IncomeBorrowInterest = "BORROW_INTEREST"
+
+	borrowInterestPeriod = time.Hour // Interest is charged per started hour, like margin venues
+	maxPaperIncome       = 1000      // Interest records kept for the P&L ledger sync
+)
+
+// Forced buy-in reasons
+const (
+	BuyInLimitExceeded = "borrow_limit_exceeded" // The short grew past its borrow limit
+	BuyInRecalled      = "borrow_recalled"       // The lender recalled the borrowed asset
+)
+
+// paperShortRules borrowing constraints for simulated shorts
+type paperShortRules struct {
+	cfg      config.PaperShortConfig
+	limits   map[string]float64 // Per-symbol limit overrides
+	noBorrow map[string]bool
+}
+
+// SetShortConstraints limits paper shorts per symbol, charges hourly borrow interest on their notional and
+// force-closes shorts whose borrow is exceeded or recalled
+func (t *PaperTrader) SetShortConstraints(cfg config.PaperShortConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rules := &paperShortRules{cfg: cfg, limits: make(map[string]float64), noBorrow: make(map[string]bool)}
+	for symbol, limit := range cfg.SymbolMaxNotionalUSD {
+		rules.limits[strings.ToUpper(symbol)] = limit
+	}
+	for _, symbol := range cfg.NoBorrowSymbols {
+		rules.noBorrow[strings.ToUpper(symbol)] = true
+	}
+	t.shortRules = rules
+	paperLog.Infof("🏦 [Simulated] Short constraints enabled: limit %s per symbol, %.2f%% APR borrow interest, %.2f%%/day recall chance",
+		formatShortLimit(cfg.MaxShortNotionalUSD), cfg.BorrowRateAPR, cfg.RecallProbabilityPerDay)
+}
+
+// formatShortLimit a borrow limit for logs
+func formatShortLimit(limit float64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.0f USDT", limit)
+}
+
+// shortLimit the symbol's maximum short notional (ok=false = unlimited)
+func (r *paperShortRules) shortLimit(symbol string) (float64, bool) {
+	if r.noBorrow[symbol] {
+		return 0, true
+	}
+	if limit, ok := r.limits[symbol]; ok {
+		return limit, limit > 0
+	}
+	return r.cfg.MaxShortNotionalUSD, r.cfg.MaxShortNotionalUSD > 0
+}
+
+// checkBorrowLimit rejects a short that would take the symbol's short notional past its limit (caller holds t.mu)
+func (t *PaperTrader) checkBorrowLimit(symbol string, notional float64) error {
+	if t.shortRules == nil {
+		return nil
+	}
+	limit, limited := t.shortRules.shortLimit(symbol)
+	if !limited {
+		return nil
+	}
+	if t.shortRules.noBorrow[symbol] {
+		return fmt.Errorf("%s cannot be borrowed for shorting (no_borrow_symbols)", symbol)
+	}
+
+	existing := 0.0
+	if pos, ok := t.positions[symbol+"_SHORT"]; ok {
+		existing = pos.Quantity * pos.EntryPrice
+	}
+	if existing+notional > limit {
+		return fmt.Errorf("short borrow limit for %s is %.2f USDT notional (held %.2f, requested %.2f)",
+			symbol, limit, existing, notional)
+	}
+	return nil
+}
+
+// accrueBorrowInterest charges interest for every started hour of the short's borrow up to now at the current
+// notional, rolling the recall chance once per hour (caller holds t.mu)
+func (t *PaperTrader) accrueBorrowInterest(pos *PaperPosition, price float64, now time.Time) {
+	rules := t.shortRules
+	if rules == nil || pos.Side != "SHORT" {
+		return
+	}
+	if pos.InterestPaidUntil.IsZero() {
+		pos.InterestPaidUntil = now
+	}
+
+	hourlyRate := rules.cfg.BorrowRateAPR / 100 / (365 * 24)
+	recallPerHour := 1 - math.Pow(1-rules.cfg.RecallProbabilityPerDay/100, 1.0/24)
+	for !now.Before(pos.InterestPaidUntil) {
+		interest := usdtFloat(dec(pos.Quantity).Mul(dec(price)).Mul(dec(hourlyRate)))
+		if interest > 0 {
+			t.balance = addUSDT(t.balance, -interest)
+			pos.BorrowInterest = addUSDT(pos.BorrowInterest, interest)
+			t.income = append(t.income, IncomeRecord{
+				Symbol: pos.Symbol,
+				Type:   IncomeBorrowInterest,
+				Amount: -interest,
+				Time:   now,
+			})
+		}
+		// The first hour is charged when the borrow starts; recalls can only happen on later hours
+		laterHour := pos.InterestPaidUntil.After(pos.EntryTime)
+		if laterHour && pos.BuyInReason == "" && recallPerHour > 0 && t.rng.Float64() < recallPerHour {
+			pos.BuyInReason = BuyInRecalled
+		}
+		pos.InterestPaidUntil = pos.InterestPaidUntil.Add(borrowInterestPeriod)
+	}
+	if len(t.income) > maxPaperIncome {
+		t.income = t.income[len(t.income)-maxPaperIncome:]
+	}
+}
+
+// buyInPrice adds the buy-in premium to the fill of a short that is being force-closed (caller holds t.mu)
+func (t *PaperTrader) buyInPrice(pos *PaperPosition, fill float64) float64 {
+	if t.shortRules == nil || pos.BuyInReason == "" {
+		return fill
+	}
+	premium := fill * t.shortRules.cfg.BuyInPremiumBps / 10000
+	paperLog.Infof("🏦 [Simulated] Forced buy-in of %s short (%s): fill %.4f + %.4f premium",
+		pos.Symbol, pos.BuyInReason, fill, premium)
+	return fill + premium
+}
+
+// PaperBuyIn a simulated short that must be force-closed
+type PaperBuyIn struct {
+	Symbol   string
+	Reason   string  // BuyInLimitExceeded or BuyInRecalled
+	Notional float64 // Short notional at the current price
+	Limit    float64 // Borrow limit (0 = unlimited)
+}
+
+// ShortBuyIns charges the borrow interest due on open shorts and returns the shorts to force-close: shorts whose
+// notional grew more than buy_in_excess_pct past their borrow limit, and shorts whose borrow was recalled.
+// The caller closes them at market; the close fills with the buy-in premium
+func (t *PaperTrader) ShortBuyIns() []PaperBuyIn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.shortRules == nil {
+		return nil
+	}
+	now := t.now()
+	var buyIns []PaperBuyIn
+	for _, pos := range t.positions {
+		if pos.Side != "SHORT" {
+			continue
+		}
+		price, err := t.getMarketPrice(pos.Symbol)
+		if err != nil {
+			continue
+		}
+		t.accrueBorrowInterest(pos, price, now)
+
+		notional := pos.Quantity * price
+		limit, limited := t.shortRules.shortLimit(pos.Symbol)
+		if limited && pos.BuyInReason == "" && notional > limit*(1+t.shortRules.cfg.BuyInExcessPct/100) {
+			pos.BuyInReason = BuyInLimitExceeded
+		}
+		if pos.BuyInReason != "" {
+			buyIns = append(buyIns, PaperBuyIn{Symbol: pos.Symbol, Reason: pos.BuyInReason, Notional: notional, Limit: limit})
+		}
+	}
+	return buyIns
+}
+
+// GetIncomeSince implements IncomeReporter with the simulated borrow interest charges and funding payments
+func (t *PaperTrader) GetIncomeSince(since time.Time) ([]IncomeRecord, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var records []IncomeRecord
+	for _, r := range t.income {
+		if !r.Time.Before(since) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// enforcePaperBuyIns charges paper short borrow interest and force-closes the shorts the lender buys in
+// (routed through the trader so the close is recorded in the P&L ledger)
+func (at *AutoTrader) enforcePaperBuyIns() {
+	paperTrader, ok := asPaperTrader(at.trader)
+	if !ok {
+		return
+	}
+
+	for _, buyIn := range paperTrader.ShortBuyIns() {
+		lock := getPositionLock(buyIn.Symbol, "SHORT")
+		lock.Lock()
+		_, err := at.trader.CloseShort(buyIn.Symbol, 0)
+		lock.Unlock()
+
+		if err != nil {
+			at.log.Errorf("❌ [Buy-in] Failed to force-close %s SHORT: %v", buyIn.Symbol, err)
+			continue
+		}
+		at.log.Infof("🏦 [Buy-in] %s SHORT force-closed: %s (notional %.2f USDT, limit %s)",
+			buyIn.Symbol, buyIn.Reason, buyIn.Notional, formatShortLimit(buyIn.Limit))
+	}
+}
@@ -2,15 +2,20 @@ package trader
 
 import (
 	"fmt"
-	"log"
-	"math"
-	"math/rand"
+	"lia/config"
+	"lia/logging"
 	"lia/market"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// paperLog logs the paper and simulated exchange
+var paperLog = logging.Component("paper")
+
 // PaperTrader Paper trading simulator
 // Does not connect to real exchanges, completely simulates trading for testing and demonstration
 type PaperTrader struct {
@@ -27,6 +32,28 @@ type PaperTrader struct {
 
 	// Random number generator (for simulating price fluctuations)
 	rng *rand.Rand
+
+	// Time source (nil = wall clock; the simulate exchange mode uses its simulated clock)
+	clock func() time.Time
+
+	// Simulated order history (for execution audits)
+	orders         []ExchangeOrder
+	lastOrderID    int64
+	clientOrderTag string
+
+	// Order book driven fills (0 = always fill at mark price)
+	bookFillThreshold float64 // Notional (USDT) at which orders walk the live order book
+	bookDepthLimit    int     // Levels fetched per side
+
+	// Short borrowing constraints (nil = shorts are unlimited and free)
+	shortRules *paperShortRules
+	income     []IncomeRecord // Simulated borrow interest and funding payments (reported like exchange income)
+
+	// Fees, slippage and funding (nil = free fills at mark price)
+	costs *config.PaperCostConfig
+
+	// Resting and finished limit entries by order ID (see paper_orders.go)
+	limitOrders map[int64]*paperLimitOrder
 }
 
 // PaperPosition Simulated position
@@ -40,6 +67,23 @@ type PaperPosition struct {
 	MarginUsed float64
 	StopLoss   float64 // Stop loss price level (0 if not set)
 	TakeProfit float64 // Take profit price level (0 if not set)
+
+	// Shorts under borrowing constraints
+	BorrowInterest    float64   // Interest paid on the borrowed value so far
+	InterestPaidUntil time.Time // End of the last charged interest hour
+	BuyInReason       string    // Set when the short must be force-closed (the close fills with a premium)
+
+	// Funding payments (trading costs enabled)
+	Funding          float64   // Funding received (negative = paid) so far
+	FundingPaidUntil time.Time // Time funding was last settled
+}
+
+// addFill adds a fill to the position: the entry becomes the quantity-weighted average, margin adds up and
+// the leverage, entry time and protection levels are kept
+func (p *PaperPosition) addFill(quantity, price, marginUsed float64) {
+	p.EntryPrice = (p.Quantity*p.EntryPrice + quantity*price) / (p.Quantity + quantity)
+	p.Quantity += quantity
+	p.MarginUsed = addUSDT(p.MarginUsed, marginUsed)
 }
 
 // NewPaperTrader Creates a paper trading simulator
@@ -49,65 +93,54 @@ func NewPaperTrader(initialBalance float64) *PaperTrader {
 		balance:          initialBalance,
 		availableBalance: initialBalance,
 		positions:        make(map[string]*PaperPosition),
+		limitOrders:      make(map[int64]*paperLimitOrder),
 		rng:              rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
 // GetBalance Get account balance (simulated)
-func (t *PaperTrader) GetBalance() (map[string]interface{}, error) {
+func (t *PaperTrader) GetBalance() (*Balance, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
 	// Update unrealized profit/loss (based on current market price)
-	totalUnrealized := 0.0
+	totalUnrealized := decimal.Zero
 	for _, pos := range t.positions {
 		currentPrice, err := t.getMarketPrice(pos.Symbol)
 		if err != nil {
 			continue
 		}
-
-		var unrealized float64
-		if pos.Side == "LONG" {
-			// Long position: (current price - entry price) / entry price * position value * leverage
-			priceChange := (currentPrice - pos.EntryPrice) / pos.EntryPrice
-			positionValue := pos.Quantity * pos.EntryPrice
-			unrealized = priceChange * positionValue * float64(pos.Leverage)
-		} else {
-			// Short position: (entry price - current price) / entry price * position value * leverage
-			priceChange := (pos.EntryPrice - currentPrice) / pos.EntryPrice
-			positionValue := pos.Quantity * pos.EntryPrice
-			unrealized = priceChange * positionValue * float64(pos.Leverage)
-		}
-		totalUnrealized += unrealized
+		totalUnrealized = totalUnrealized.Add(simulatedPnL(pos.Side, pos.EntryPrice, currentPrice, pos.Quantity))
 	}
 
-	t.unrealizedProfit = totalUnrealized
+	t.unrealizedProfit = usdtFloat(totalUnrealized)
 
 	// Calculate available balance (total equity - margin used by positions)
-	totalMarginUsed := 0.0
+	totalMarginUsed := decimal.Zero
 	for _, pos := range t.positions {
-		totalMarginUsed += pos.MarginUsed
+		totalMarginUsed = totalMarginUsed.Add(dec(pos.MarginUsed))
 	}
+	totalMarginUsed = totalMarginUsed.Add(dec(t.reservedMargin()))
 
-	totalEquity := t.balance + t.unrealizedProfit
-	t.availableBalance = totalEquity - totalMarginUsed
+	totalEquity := dec(t.balance).Add(totalUnrealized)
+	t.availableBalance = usdtFloat(totalEquity.Sub(totalMarginUsed))
 	if t.availableBalance < 0 {
 		t.availableBalance = 0
 	}
 
-	return map[string]interface{}{
-		"totalWalletBalance":    t.balance,
-		"availableBalance":      t.availableBalance,
-		"totalUnrealizedProfit": t.unrealizedProfit,
+	return &Balance{
+		WalletBalance:    t.balance,
+		AvailableBalance: t.availableBalance,
+		UnrealizedProfit: t.unrealizedProfit,
 	}, nil
 }
 
 // GetPositions 获取所有持仓（模拟）
-func (t *PaperTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *PaperTrader) GetPositions() ([]Position, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	var result []map[string]interface{}
+	var result []Position
 	for _, pos := range t.positions {
 		currentPrice, err := t.getMarketPrice(pos.Symbol)
 		if err != nil {
@@ -115,19 +148,7 @@ func (t *PaperTrader) GetPositions() ([]map[string]interface{}, error) {
 		}
 
 		// 计算未实现盈亏
-		var unrealizedPnl float64
-		var unrealizedPnlPct float64
-		if pos.Side == "LONG" {
-			priceChange := (currentPrice - pos.EntryPrice) / pos.EntryPrice
-			positionValue := pos.Quantity * pos.EntryPrice
-			unrealizedPnl = priceChange * positionValue * float64(pos.Leverage)
-			unrealizedPnlPct = priceChange * 100
-		} else {
-			priceChange := (pos.EntryPrice - currentPrice) / pos.EntryPrice
-			positionValue := pos.Quantity * pos.EntryPrice
-			unrealizedPnl = priceChange * positionValue * float64(pos.Leverage)
-			unrealizedPnlPct = priceChange * 100
-		}
+		unrealizedPnl := usdtFloat(simulatedPnL(pos.Side, pos.EntryPrice, currentPrice, pos.Quantity))
 
 		// 计算强平价（简化：假设强平在入场价 ±20%）
 		var liquidationPrice float64
@@ -137,26 +158,15 @@ func (t *PaperTrader) GetPositions() ([]map[string]interface{}, error) {
 			liquidationPrice = pos.EntryPrice * 1.2 // 做空：价格上涨20%强平
 		}
 
-		positionAmt := pos.Quantity
-		if pos.Side == "SHORT" {
-			positionAmt = -positionAmt // 做空用负数表示
-		}
-
-		// Convert side to lowercase to match expected format
-		side := strings.ToLower(pos.Side)
-
-		result = append(result, map[string]interface{}{
-			"symbol":              pos.Symbol,
-			"side":                side,     // Use "side" for consistency with other traders
-			"positionSide":        pos.Side, // Keep for backward compatibility
-			"positionAmt":         positionAmt,
-			"entryPrice":          pos.EntryPrice,
-			"markPrice":           currentPrice,
-			"leverage":            float64(pos.Leverage),
-			"unRealizedProfit":    unrealizedPnl,
-			"unRealizedProfitPct": unrealizedPnlPct,
-			"liquidationPrice":    liquidationPrice,
-			"marginUsed":          pos.MarginUsed,
+		result = append(result, Position{
+			Symbol:           pos.Symbol,
+			Side:             strings.ToLower(pos.Side),
+			Quantity:         pos.Quantity,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        currentPrice,
+			UnrealizedProfit: unrealizedPnl,
+			Leverage:         pos.Leverage,
+			LiquidationPrice: liquidationPrice,
 		})
 	}
 
@@ -164,7 +174,7 @@ func (t *PaperTrader) GetPositions() ([]map[string]interface{}, error) {
 }
 
 // OpenLong 开多仓（模拟）
-func (t *PaperTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *PaperTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -172,14 +182,11 @@ func (t *PaperTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 	if err != nil {
 		return nil, fmt.Errorf("failed to get market price: %w", err)
 	}
+	currentPrice = t.fillPrice(symbol, "BUY", quantity, currentPrice)
 
-	// Calculate required margin
-	positionValue := quantity * currentPrice
-	marginUsed := positionValue / float64(leverage)
-
-	// Round down margin to 2 decimal places to be more conservative
-	// This prevents failures due to floating point precision issues
-	marginUsed = math.Floor(marginUsed*100) / 100 // Round down to 2 decimals
+	// Calculate required margin, rounded down to 2 decimal places to be more conservative
+	marginUsed, _ := dec(quantity).Mul(dec(currentPrice)).
+		Div(decimal.NewFromInt(int64(leverage))).RoundFloor(2).Float64()
 
 	// Add small tolerance (0.1 USDT) to available balance check to account for:
 	// - Floating point precision differences
@@ -196,30 +203,40 @@ func (t *PaperTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 	// Use the rounded-down margin value for actual margin used
 	// This ensures we're slightly conservative with margin calculations
 
-	// Create position
-	t.positions[symbol+"_LONG"] = &PaperPosition{
-		Symbol:     symbol,
-		Side:       "LONG",
-		EntryPrice: currentPrice,
-		Quantity:   quantity,
-		Leverage:   leverage,
-		EntryTime:  time.Now(),
-		MarginUsed: marginUsed,
-	}
-
-	log.Printf("📈 [Simulated] Open long: %s %f @ %.4f (Leverage %dx, Margin %.2f)", symbol, quantity, currentPrice, leverage, marginUsed)
-
-	return map[string]interface{}{
-		"orderId":     time.Now().Unix(),
-		"symbol":      symbol,
-		"side":        "BUY",
-		"price":       currentPrice,
-		"executedQty": quantity,
+	// Add to the existing long (averaging the entry) or create the position
+	if pos, exists := t.positions[symbol+"_LONG"]; exists {
+		pos.addFill(quantity, currentPrice, marginUsed)
+	} else {
+		t.positions[symbol+"_LONG"] = &PaperPosition{
+			Symbol:     symbol,
+			Side:       "LONG",
+			EntryPrice: currentPrice,
+			Quantity:   quantity,
+			Leverage:   leverage,
+			EntryTime:  t.now(),
+			MarginUsed: marginUsed,
+		}
+	}
+
+	fee := t.chargeFee(quantity*currentPrice, false)
+
+	paperLog.Infof("📈 [Simulated] Open long: %s %f @ %.4f (Leverage %dx, Margin %.2f, Fee %.4f)", symbol, quantity, currentPrice, leverage, marginUsed, fee)
+
+	orderID, clientOrderID := t.recordOrder(ClientOrderKindOpen, symbol, "BUY", "LONG", quantity, currentPrice)
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+		Price:         currentPrice,
+		ExecutedQty:   quantity,
+		Fee:           fee,
 	}, nil
 }
 
 // OpenShort 开空仓（模拟）
-func (t *PaperTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *PaperTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -227,14 +244,11 @@ func (t *PaperTrader) OpenShort(symbol string, quantity float64, leverage int) (
 	if err != nil {
 		return nil, fmt.Errorf("failed to get market price: %w", err)
 	}
+	currentPrice = t.fillPrice(symbol, "SELL", quantity, currentPrice)
 
-	// Calculate required margin
-	positionValue := quantity * currentPrice
-	marginUsed := positionValue / float64(leverage)
-
-	// Round down margin to 2 decimal places to be more conservative
-	// This prevents failures due to floating point precision issues
-	marginUsed = math.Floor(marginUsed*100) / 100 // Round down to 2 decimals
+	// Calculate required margin, rounded down to 2 decimal places to be more conservative
+	marginUsed, _ := dec(quantity).Mul(dec(currentPrice)).
+		Div(decimal.NewFromInt(int64(leverage))).RoundFloor(2).Float64()
 
 	// Add small tolerance (0.1 USDT) to available balance check to account for:
 	// - Floating point precision differences
@@ -248,33 +262,53 @@ func (t *PaperTrader) OpenShort(symbol string, quantity float64, leverage int) (
 		return nil, fmt.Errorf("insufficient available balance: need %.2f, available %.2f", marginUsed, t.availableBalance)
 	}
 
+	// Borrow limit for the short notional
+	if err := t.checkBorrowLimit(symbol, quantity*currentPrice); err != nil {
+		return nil, err
+	}
+
 	// Use the rounded-down margin value for actual margin used
 	// This ensures we're slightly conservative with margin calculations
 
-	// Create position
-	t.positions[symbol+"_SHORT"] = &PaperPosition{
-		Symbol:     symbol,
-		Side:       "SHORT",
-		EntryPrice: currentPrice,
-		Quantity:   quantity,
-		Leverage:   leverage,
-		EntryTime:  time.Now(),
-		MarginUsed: marginUsed,
-	}
-
-	log.Printf("📉 [Simulated] Open short: %s %f @ %.4f (Leverage %dx, Margin %.2f)", symbol, quantity, currentPrice, leverage, marginUsed)
-
-	return map[string]interface{}{
-		"orderId":     time.Now().Unix(),
-		"symbol":      symbol,
-		"side":        "SELL",
-		"price":       currentPrice,
-		"executedQty": quantity,
+	// Add to the existing short (averaging the entry) or create the position
+	pos, exists := t.positions[symbol+"_SHORT"]
+	if exists {
+		pos.addFill(quantity, currentPrice, marginUsed)
+	} else {
+		pos = &PaperPosition{
+			Symbol:     symbol,
+			Side:       "SHORT",
+			EntryPrice: currentPrice,
+			Quantity:   quantity,
+			Leverage:   leverage,
+			EntryTime:  t.now(),
+			MarginUsed: marginUsed,
+		}
+		t.positions[symbol+"_SHORT"] = pos
+	}
+
+	// Borrow interest is charged when the borrow starts, then every hour (an add is charged from the next hour)
+	t.accrueBorrowInterest(pos, currentPrice, pos.EntryTime)
+
+	fee := t.chargeFee(quantity*currentPrice, false)
+
+	paperLog.Infof("📉 [Simulated] Open short: %s %f @ %.4f (Leverage %dx, Margin %.2f, Fee %.4f)", symbol, quantity, currentPrice, leverage, marginUsed, fee)
+
+	orderID, clientOrderID := t.recordOrder(ClientOrderKindOpen, symbol, "SELL", "SHORT", quantity, currentPrice)
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+		Price:         currentPrice,
+		ExecutedQty:   quantity,
+		Fee:           fee,
 	}, nil
 }
 
 // CloseLong 平多仓（模拟）
-func (t *PaperTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *PaperTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -289,38 +323,51 @@ func (t *PaperTrader) CloseLong(symbol string, quantity float64) (map[string]int
 		currentPrice = pos.EntryPrice
 	}
 
-	// Calculate profit/loss
-	priceChange := (currentPrice - pos.EntryPrice) / pos.EntryPrice
-	positionValue := pos.Quantity * pos.EntryPrice
-	realizedPnl := priceChange * positionValue * float64(pos.Leverage)
+	// Calculate profit/loss (partial close only realizes the closed portion)
+	closedQty := pos.Quantity
+	if quantity > 0 && quantity < pos.Quantity {
+		closedQty = quantity
+	}
+	t.accrueFunding(pos, t.now())
+	maker := t.atTakeProfit(pos, currentPrice)
+	if !maker {
+		currentPrice = t.fillPrice(symbol, "SELL", closedQty, currentPrice)
+	}
+	realizedPnl := usdtFloat(simulatedPnL(pos.Side, pos.EntryPrice, currentPrice, closedQty))
 
-	// Update balance (add P&L to wallet)
-	t.balance += realizedPnl
+	// Update balance (add P&L to wallet, fee paid separately)
+	t.balance = addUSDT(t.balance, realizedPnl)
+	fee := t.chargeFee(closedQty*currentPrice, maker)
 
 	// If quantity=0, close all; otherwise close partial
 	if quantity == 0 || quantity >= pos.Quantity {
 		// Close all
 		delete(t.positions, key)
-		log.Printf("📤 [Simulated] Close long: %s (all) @ %.4f, P&L=%.2f", symbol, currentPrice, realizedPnl)
+		paperLog.Infof("📤 [Simulated] Close long: %s (all) @ %.4f, P&L=%.2f", symbol, currentPrice, realizedPnl)
 	} else {
 		// Close partial (simplified: reduce proportionally)
-		ratio := quantity / pos.Quantity
-		pos.Quantity -= quantity
-		pos.MarginUsed *= (1 - ratio)
-		log.Printf("📤 [Simulated] Close long: %s (partial %f) @ %.4f, P&L=%.2f", symbol, quantity, currentPrice, realizedPnl*ratio)
-	}
-
-	return map[string]interface{}{
-		"orderId":     time.Now().Unix(),
-		"symbol":      symbol,
-		"side":        "SELL",
-		"price":       currentPrice,
-		"executedQty": quantity,
+		remaining := dec(pos.Quantity).Sub(dec(quantity))
+		pos.MarginUsed = usdtFloat(dec(pos.MarginUsed).Mul(remaining).Div(dec(pos.Quantity)))
+		pos.Quantity, _ = remaining.Float64()
+		paperLog.Infof("📤 [Simulated] Close long: %s (partial %f) @ %.4f, P&L=%.2f", symbol, quantity, currentPrice, realizedPnl)
+	}
+
+	orderID, clientOrderID := t.recordOrder(ClientOrderKindClose, symbol, "SELL", "LONG", closedQty, currentPrice)
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+		Price:         currentPrice,
+		ExecutedQty:   closedQty,
+		RealizedPnL:   &realizedPnl,
+		Fee:           fee,
 	}, nil
 }
 
 // CloseShort 平空仓（模拟）
-func (t *PaperTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *PaperTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -335,33 +382,47 @@ func (t *PaperTrader) CloseShort(symbol string, quantity float64) (map[string]in
 		currentPrice = pos.EntryPrice
 	}
 
-	// Calculate profit/loss
-	priceChange := (pos.EntryPrice - currentPrice) / pos.EntryPrice
-	positionValue := pos.Quantity * pos.EntryPrice
-	realizedPnl := priceChange * positionValue * float64(pos.Leverage)
+	// Calculate profit/loss (partial close only realizes the closed portion)
+	closedQty := pos.Quantity
+	if quantity > 0 && quantity < pos.Quantity {
+		closedQty = quantity
+	}
+	t.accrueFunding(pos, t.now())
+	maker := pos.BuyInReason == "" && t.atTakeProfit(pos, currentPrice)
+	if !maker {
+		currentPrice = t.fillPrice(symbol, "BUY", closedQty, currentPrice)
+		currentPrice = t.buyInPrice(pos, currentPrice)
+	}
+	realizedPnl := usdtFloat(simulatedPnL(pos.Side, pos.EntryPrice, currentPrice, closedQty))
 
-	// Update balance (add P&L to wallet)
-	t.balance += realizedPnl
+	// Update balance (add P&L to wallet, fee paid separately)
+	t.balance = addUSDT(t.balance, realizedPnl)
+	fee := t.chargeFee(closedQty*currentPrice, maker)
 
 	// If quantity=0, close all; otherwise close partial
 	if quantity == 0 || quantity >= pos.Quantity {
 		// Close all
 		delete(t.positions, key)
-		log.Printf("📤 [Simulated] Close short: %s (all) @ %.4f, P&L=%.2f", symbol, currentPrice, realizedPnl)
+		paperLog.Infof("📤 [Simulated] Close short: %s (all) @ %.4f, P&L=%.2f", symbol, currentPrice, realizedPnl)
 	} else {
 		// Close partial
-		ratio := quantity / pos.Quantity
-		pos.Quantity -= quantity
-		pos.MarginUsed *= (1 - ratio)
-		log.Printf("📤 [Simulated] Close short: %s (partial %f) @ %.4f, P&L=%.2f", symbol, quantity, currentPrice, realizedPnl*ratio)
-	}
-
-	return map[string]interface{}{
-		"orderId":     time.Now().Unix(),
-		"symbol":      symbol,
-		"side":        "BUY",
-		"price":       currentPrice,
-		"executedQty": quantity,
+		remaining := dec(pos.Quantity).Sub(dec(quantity))
+		pos.MarginUsed = usdtFloat(dec(pos.MarginUsed).Mul(remaining).Div(dec(pos.Quantity)))
+		pos.Quantity, _ = remaining.Float64()
+		paperLog.Infof("📤 [Simulated] Close short: %s (partial %f) @ %.4f, P&L=%.2f", symbol, quantity, currentPrice, realizedPnl)
+	}
+
+	orderID, clientOrderID := t.recordOrder(ClientOrderKindClose, symbol, "BUY", "SHORT", closedQty, currentPrice)
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+		Price:         currentPrice,
+		ExecutedQty:   closedQty,
+		RealizedPnL:   &realizedPnl,
+		Fee:           fee,
 	}, nil
 }
 
@@ -393,7 +454,7 @@ func (t *PaperTrader) SetStopLoss(symbol string, positionSide string, quantity,
 	key := symbol + "_" + positionSide
 	if pos, exists := t.positions[key]; exists {
 		pos.StopLoss = stopPrice
-		log.Printf("  📌 [Simulated] Stop loss set for %s %s: %.4f", symbol, positionSide, stopPrice)
+		paperLog.Infof("  📌 [Simulated] Stop loss set for %s %s: %.4f", symbol, positionSide, stopPrice)
 	}
 	return nil
 }
@@ -406,14 +467,108 @@ func (t *PaperTrader) SetTakeProfit(symbol string, positionSide string, quantity
 	key := symbol + "_" + positionSide
 	if pos, exists := t.positions[key]; exists {
 		pos.TakeProfit = takeProfitPrice
-		log.Printf("  📌 [Simulated] Take profit set for %s %s: %.4f", symbol, positionSide, takeProfitPrice)
+		paperLog.Infof("  📌 [Simulated] Take profit set for %s %s: %.4f", symbol, positionSide, takeProfitPrice)
 	}
 	return nil
 }
 
+// AddPositionMargin adds margin to a simulated position
+func (t *PaperTrader) AddPositionMargin(symbol string, positionSide string, amount float64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := symbol + "_" + positionSide
+	pos, exists := t.positions[key]
+	if !exists {
+		return fmt.Errorf("no %s position found for %s", strings.ToLower(positionSide), symbol)
+	}
+	pos.MarginUsed = addUSDT(pos.MarginUsed, amount)
+	paperLog.Infof("  💰 [Simulated] Added %.2f USDT margin to %s %s (margin now %.2f)", amount, symbol, positionSide, pos.MarginUsed)
+	return nil
+}
+
+// simulatedPnL simulated position P&L: price change × quantity. Leverage is already in the quantity (sized
+// from margin × leverage), so it only scales the P&L relative to the margin, not in USDT
+func simulatedPnL(side string, entryPrice, currentPrice, quantity float64) decimal.Decimal {
+	move := dec(currentPrice).Sub(dec(entryPrice))
+	if side == "SHORT" {
+		move = move.Neg()
+	}
+	return move.Mul(dec(quantity))
+}
+
+// maxPaperOrders number of simulated orders kept for execution audits
+const maxPaperOrders = 2000
+
+// nextOrderID a new simulated order ID (caller holds t.mu)
+func (t *PaperTrader) nextOrderID() int64 {
+	// Millisecond IDs, kept strictly increasing when several orders fill in the same millisecond
+	orderID := t.now().UnixMilli()
+	if orderID <= t.lastOrderID {
+		orderID = t.lastOrderID + 1
+	}
+	t.lastOrderID = orderID
+	return orderID
+}
+
+// recordOrder appends a filled market order to the simulated order history (caller holds t.mu)
+func (t *PaperTrader) recordOrder(kind, symbol, side, positionSide string, quantity, price float64) (int64, string) {
+	orderID := t.nextOrderID()
+	clientOrderID := newClientOrderIDAt(t.clientOrderTag, kind, orderID)
+	t.appendOrder(ExchangeOrder{
+		Symbol:        symbol,
+		OrderID:       orderID,
+		ClientOrderID: clientOrderID,
+		Side:          side,
+		PositionSide:  positionSide,
+		Type:          "MARKET",
+		Status:        "FILLED",
+		ExecutedQty:   quantity,
+		AvgPrice:      price,
+		Time:          t.now(),
+	})
+	return orderID, clientOrderID
+}
+
+// appendOrder adds an order to the simulated order history, keeping the last maxPaperOrders (caller holds t.mu)
+func (t *PaperTrader) appendOrder(order ExchangeOrder) {
+	t.orders = append(t.orders, order)
+	if len(t.orders) > maxPaperOrders {
+		t.orders = t.orders[len(t.orders)-maxPaperOrders:]
+	}
+}
+
+// SetClientOrderTag sets the trader tag embedded in client order IDs
+func (t *PaperTrader) SetClientOrderTag(tag string) {
+	t.mu.Lock()
+	t.clientOrderTag = tag
+	t.mu.Unlock()
+}
+
+// GetOrderHistory returns simulated orders for symbol between start and end
+func (t *PaperTrader) GetOrderHistory(symbol string, start, end time.Time) ([]ExchangeOrder, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var orders []ExchangeOrder
+	for _, o := range t.orders {
+		if o.Symbol == symbol && !o.Time.Before(start) && !o.Time.After(end) {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
 // CancelAllOrders 取消所有挂单（模拟）
 func (t *PaperTrader) CancelAllOrders(symbol string) error {
-	// 模拟：不需要实际操作
+	// 模拟：止盈止损不是挂单，只需取消限价开仓单（与交易所一致）
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, o := range t.limitOrders {
+		if o.symbol == symbol && o.status == OrderStatusNew {
+			o.status = OrderStatusCanceled
+		}
+	}
 	return nil
 }
 
@@ -461,7 +616,7 @@ func (t *PaperTrader) CheckAutoTakeProfit(autoTakeProfitPct float64) ([]struct {
 				Side:   side,
 				Reason: fmt.Sprintf("Auto take profit: %.2f%% P&L (target: %.2f%%)", pnlPct, autoTakeProfitPct),
 			})
-			log.Printf("  🎯 [Auto-Close] %s %s: %.2f%% P&L reached (target: %.2f%%)", pos.Symbol, side, pnlPct, autoTakeProfitPct)
+			paperLog.Infof("  🎯 [Auto-Close] %s %s: %.2f%% P&L reached (target: %.2f%%)", pos.Symbol, side, pnlPct, autoTakeProfitPct)
 			continue
 		}
 
@@ -485,7 +640,7 @@ func (t *PaperTrader) CheckAutoTakeProfit(autoTakeProfitPct float64) ([]struct {
 					Side:   side,
 					Reason: fmt.Sprintf("Stop loss triggered: price %.4f hit stop loss %.4f", currentPrice, pos.StopLoss),
 				})
-				log.Printf("  🛑 [Auto-Close] %s %s: Stop loss triggered (price: %.4f, stop: %.4f)", pos.Symbol, side, currentPrice, pos.StopLoss)
+				paperLog.Infof("  🛑 [Auto-Close] %s %s: Stop loss triggered (price: %.4f, stop: %.4f)", pos.Symbol, side, currentPrice, pos.StopLoss)
 			}
 		}
 
@@ -509,7 +664,7 @@ func (t *PaperTrader) CheckAutoTakeProfit(autoTakeProfitPct float64) ([]struct {
 					Side:   side,
 					Reason: fmt.Sprintf("Take profit triggered: price %.4f hit take profit %.4f", currentPrice, pos.TakeProfit),
 				})
-				log.Printf("  ✅ [Auto-Close] %s %s: Take profit triggered (price: %.4f, target: %.4f)", pos.Symbol, side, currentPrice, pos.TakeProfit)
+				paperLog.Infof("  ✅ [Auto-Close] %s %s: Take profit triggered (price: %.4f, target: %.4f)", pos.Symbol, side, currentPrice, pos.TakeProfit)
 			}
 		}
 	}
@@ -520,5 +675,40 @@ func (t *PaperTrader) CheckAutoTakeProfit(autoTakeProfitPct float64) ([]struct {
 // FormatQuantity 格式化数量（模拟）
 func (t *PaperTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
 	// 模拟：简单格式化（实际应该根据交易所精度）
-	return fmt.Sprintf("%.4f", quantity), nil
+	return formatStepQuantity(quantity, 4), nil
+}
+
+// PaperStopTrigger a simulated stop loss whose trigger price the market has crossed
+type PaperStopTrigger struct {
+	Symbol    string
+	Side      string  // "long" or "short"
+	StopPrice float64 // Stop loss level
+	Price     float64 // Market price that crossed it
+}
+
+// TriggeredStops positions whose simulated stop loss has been crossed. The caller closes them at market like a
+// stop-market order, so a price that gapped through the stop fills worse than the stop
+func (t *PaperTrader) TriggeredStops() []PaperStopTrigger {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var triggered []PaperStopTrigger
+	for _, pos := range t.positions {
+		if pos.StopLoss <= 0 {
+			continue
+		}
+		price, err := t.getMarketPrice(pos.Symbol)
+		if err != nil {
+			continue
+		}
+		if (pos.Side == "LONG" && price <= pos.StopLoss) || (pos.Side == "SHORT" && price >= pos.StopLoss) {
+			triggered = append(triggered, PaperStopTrigger{
+				Symbol:    pos.Symbol,
+				Side:      strings.ToLower(pos.Side),
+				StopPrice: pos.StopLoss,
+				Price:     price,
+			})
+		}
+	}
+	return triggered
 }
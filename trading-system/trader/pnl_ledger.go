@@ -0,0 +1,610 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PnL ledger event types
+const (
+	PnLEventClose    = "close"    // Realized P&L from closing (part of) a position
+	PnLEventFee      = "fee"      // Trading fee (negative amount)
+	PnLEventFunding  = "funding"  // Funding payment (positive = received)
+	PnLEventInterest = "interest" // Borrow interest on paper shorts (negative amount)
+	PnLEventCarried  = "carried"  // Realized P&L carried over from before this session
+)
+
+// maxLedgerEvents number of recent events kept in memory (totals are kept for all events)
+const maxLedgerEvents = 500
+
+// PnLEvent a single realized P&L ledger entry
+type PnLEvent struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Symbol string    `json:"symbol,omitempty"`
+	Side   string    `json:"side,omitempty"`
+	Amount float64   `json:"amount"` // USDT, positive = gain
+	Note   string    `json:"note,omitempty"`
+}
+
+// PnLSummary realized P&L totals by event type
+type PnLSummary struct {
+	RealizedPnL float64 `json:"realized_pnl"` // Sum of all ledger events
+	TradingPnL  float64 `json:"trading_pnl"`  // Closed position P&L
+	Fees        float64 `json:"fees"`         // Trading fees (negative)
+	Funding     float64 `json:"funding"`      // Funding payments
+	Interest    float64 `json:"interest"`     // Borrow interest (paper shorts, negative)
+	Carried     float64 `json:"carried"`      // Realized before this session (from wallet balance)
+	CloseCount  int     `json:"close_count"`
+}
+
+// IncomeRecord a fee or funding payment reported by the exchange
+type IncomeRecord struct {
+	Symbol        string
+	Type          string // "COMMISSION", "FUNDING_FEE" or "BORROW_INTEREST"
+	Amount        float64
+	Time          time.Time
+	TradeID       string // Trade a commission was charged for ("" = not reported)
+	ClientOrderID string // Client order ID of that trade's order ("" = not reported)
+}
+
+// IncomeReporter optional interface for exchanges that report fee, funding and interest income
+type IncomeReporter interface {
+	// GetIncomeSince returns commission and funding records after since
+	GetIncomeSince(since time.Time) ([]IncomeRecord, error)
+}
+
+// PnLLedger per-trader realized P&L ledger (updated on every close, fee and funding event). Persisted next to the
+// decision logs, so totals, the carried balance and the income cursor survive restarts
+type PnLLedger struct {
+	events       []PnLEvent
+	summary      PnLSummary
+	seeded       bool
+	symbols      map[string]bool // Symbols this trader has traded (income for other symbols belongs to other traders)
+	incomeCursor time.Time       // Time of the last synced exchange income record
+	path         string          // State file ("" = not persisted)
+	mu           sync.RWMutex
+}
+
+// pnlLedgerState the persisted ledger
+type pnlLedgerState struct {
+	Events       []PnLEvent      `json:"events"`
+	Summary      PnLSummary      `json:"summary"`
+	Seeded       bool            `json:"seeded"`
+	Symbols      map[string]bool `json:"symbols"`
+	IncomeCursor time.Time       `json:"income_cursor"`
+}
+
+// incomeShare how the income of an exchange account shared with other traders is attributed to this trader
+type incomeShare struct {
+	tag   string                      // This trader's client order tag: commissions of its orders are its own
+	owned func(symbol string) float64 // This trader's share (0-1) of the symbol's positions: splits funding, interest and commissions of untagged orders
+}
+
+// of the part of an income record that belongs to this trader (0-1, everything without a share)
+func (s *incomeShare) of(r IncomeRecord) float64 {
+	if s == nil {
+		return 1
+	}
+	if r.Type == "COMMISSION" {
+		if tag, _, ok := parseClientOrderID(r.ClientOrderID); ok {
+			if tag == s.tag {
+				return 1
+			}
+			return 0
+		}
+	}
+	return s.owned(r.Symbol)
+}
+
+// NewPnLLedger creates the realized P&L ledger, restored from path ("" = not persisted). A new ledger syncs
+// exchange income from cursor on
+func NewPnLLedger(path string, cursor time.Time) *PnLLedger {
+	l := &PnLLedger{
+		symbols:      make(map[string]bool),
+		incomeCursor: cursor,
+		path:         path,
+	}
+	if path == "" {
+		return l
+	}
+	if err := l.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load P&L ledger (%s): %v - starting a new ledger", path, err)
+		*l = PnLLedger{symbols: make(map[string]bool), incomeCursor: cursor, path: path}
+	}
+	return l
+}
+
+// load reads persisted state (a missing file is not an error)
+func (l *PnLLedger) load() error {
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state pnlLedgerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse P&L ledger: %w", err)
+	}
+	l.events = state.Events
+	l.summary = state.Summary
+	l.seeded = state.Seeded
+	if state.Symbols != nil {
+		l.symbols = state.Symbols
+	}
+	if !state.IncomeCursor.IsZero() {
+		l.incomeCursor = state.IncomeCursor
+	}
+	return nil
+}
+
+// save persists the ledger (caller holds mu); failures are logged
+func (l *PnLLedger) save() {
+	if l.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(pnlLedgerState{
+		Events:       l.events,
+		Summary:      l.summary,
+		Seeded:       l.seeded,
+		Symbols:      l.symbols,
+		IncomeCursor: l.incomeCursor,
+	}, "", "  ")
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize P&L ledger: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create P&L ledger directory: %v", err)
+		return
+	}
+	tmpPath := l.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write P&L ledger: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace P&L ledger file: %v", err)
+	}
+}
+
+// markSymbol records that this trader has traded symbol
+func (l *PnLLedger) markSymbol(symbol string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.symbols[symbol] {
+		l.symbols[symbol] = true
+		l.save()
+	}
+}
+
+// SyncIncome records new exchange fees and funding for symbols this trader has traded. On an account shared with
+// other traders (share set) only this trader's part is recorded: the commissions of its own orders, and its
+// owned share of funding, interest and commissions whose order is unknown
+func (l *PnLLedger) SyncIncome(reporter IncomeReporter, share *incomeShare) error {
+	l.mu.RLock()
+	since := l.incomeCursor
+	l.mu.RUnlock()
+
+	records, err := reporter.GetIncomeSince(since.Add(time.Millisecond))
+	if err != nil {
+		return err
+	}
+
+	latest := since
+	for _, r := range records {
+		if !r.Time.After(since) {
+			continue
+		}
+		if r.Time.After(latest) {
+			latest = r.Time
+		}
+
+		l.mu.RLock()
+		traded := l.symbols[r.Symbol]
+		l.mu.RUnlock()
+		if !traded {
+			continue
+		}
+		amount := r.Amount * share.of(r)
+
+		switch r.Type {
+		case "COMMISSION":
+			l.RecordFee(r.Symbol, amount)
+		case "FUNDING_FEE":
+			l.RecordFunding(r.Symbol, amount)
+		case IncomeBorrowInterest:
+			l.RecordInterest(r.Symbol, amount)
+		}
+	}
+
+	l.mu.Lock()
+	if latest.After(l.incomeCursor) {
+		l.incomeCursor = latest
+		l.save()
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+// record appends an event and updates totals
+func (l *PnLLedger) record(event PnLEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Totals are accumulated in decimal so thousands of small fee/funding events don't drift
+	switch event.Type {
+	case PnLEventClose:
+		l.summary.TradingPnL = addUSDT(l.summary.TradingPnL, event.Amount)
+		l.summary.CloseCount++
+	case PnLEventFee:
+		l.summary.Fees = addUSDT(l.summary.Fees, event.Amount)
+	case PnLEventFunding:
+		l.summary.Funding = addUSDT(l.summary.Funding, event.Amount)
+	case PnLEventInterest:
+		l.summary.Interest = addUSDT(l.summary.Interest, event.Amount)
+	case PnLEventCarried:
+		l.summary.Carried = addUSDT(l.summary.Carried, event.Amount)
+	}
+	l.summary.RealizedPnL = addUSDT(l.summary.RealizedPnL, event.Amount)
+
+	l.events = append(l.events, event)
+	if len(l.events) > maxLedgerEvents {
+		l.events = l.events[len(l.events)-maxLedgerEvents:]
+	}
+	l.save()
+}
+
+// RecordClose records realized P&L from closing a position
+func (l *PnLLedger) RecordClose(symbol, side string, pnl float64) {
+	l.record(PnLEvent{Type: PnLEventClose, Symbol: symbol, Side: strings.ToLower(side), Amount: pnl})
+}
+
+// RecordFee records a trading fee (pass the fee as a positive number)
+func (l *PnLLedger) RecordFee(symbol string, fee float64) {
+	if fee == 0 {
+		return
+	}
+	l.record(PnLEvent{Type: PnLEventFee, Symbol: symbol, Amount: -math.Abs(fee)})
+}
+
+// RecordFunding records a funding payment (positive = received, negative = paid)
+func (l *PnLLedger) RecordFunding(symbol string, amount float64) {
+	if amount == 0 {
+		return
+	}
+	l.record(PnLEvent{Type: PnLEventFunding, Symbol: symbol, Amount: amount})
+}
+
+// RecordInterest records borrow interest (pass the interest as a positive or negative number)
+func (l *PnLLedger) RecordInterest(symbol string, interest float64) {
+	if interest == 0 {
+		return
+	}
+	l.record(PnLEvent{Type: PnLEventInterest, Symbol: symbol, Amount: -math.Abs(interest)})
+}
+
+// Seed records realized P&L accumulated before the ledger was started (wallet balance - initial balance)
+// Only the first call on a new ledger has an effect (a restored ledger is already seeded)
+func (l *PnLLedger) Seed(walletBalance, initialBalance float64) {
+	l.mu.Lock()
+	if l.seeded {
+		l.mu.Unlock()
+		return
+	}
+	l.seeded = true
+	l.save()
+	l.mu.Unlock()
+
+	carried := addUSDT(walletBalance, -initialBalance)
+	if math.Abs(carried) < 0.01 {
+		return
+	}
+	l.record(PnLEvent{
+		Type:   PnLEventCarried,
+		Amount: carried,
+		Note:   fmt.Sprintf("wallet %.2f vs initial %.2f at startup", walletBalance, initialBalance),
+	})
+}
+
+// Summary returns realized P&L totals
+func (l *PnLLedger) Summary() PnLSummary {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.summary
+}
+
+// RecentEvents returns the most recent n events (oldest first, n <= 0 returns all kept events)
+func (l *PnLLedger) RecentEvents(n int) []PnLEvent {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	start := 0
+	if n > 0 && len(l.events) > n {
+		start = len(l.events) - n
+	}
+	events := make([]PnLEvent, len(l.events)-start)
+	copy(events, l.events[start:])
+	return events
+}
+
+// ledgerTrader wraps a Trader and records realized P&L for every close, whichever code path closes it
+// (AI decisions, background profit monitor, paper auto take-profit, manual close API), every order in the
+// order tracker and every fill in the position ownership ledger
+type ledgerTrader struct {
+	Trader
+	ledger *PnLLedger
+	orders *OrderTracker
+
+	// Position ownership on shared accounts (nil = not attributed, see SetOwnershipLedger)
+	ownership  *OwnershipLedger
+	accountKey string
+	traderID   string
+
+	ledgerOptions
+}
+
+// ledgerOptions what a ledgerTrader records besides realized P&L and orders (nil = not recorded)
+type ledgerOptions struct {
+	// Paper twin that repeats every order (paper_shadow)
+	shadow *PaperShadow
+
+	// Stop loss / take profit levels per position, whose orders are cancelled once the position is flattened
+	protection *protectionBook
+}
+
+// newLedgerTrader wraps t so closes are recorded in ledger, orders in orders and the rest as set in opts
+func newLedgerTrader(t Trader, ledger *PnLLedger, orders *OrderTracker, opts ledgerOptions) Trader {
+	return &ledgerTrader{Trader: t, ledger: ledger, orders: orders, ledgerOptions: opts}
+}
+
+// OpenLong opens a long position and marks the symbol as traded
+func (lt *ledgerTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	lt.ownership.begin(lt.accountKey, symbol, "long")
+	defer lt.ownership.end(lt.accountKey, symbol, "long")
+	order, err := lt.Trader.OpenLong(symbol, quantity, leverage)
+	if err == nil {
+		lt.ledger.markSymbol(symbol)
+		lt.recordFee(symbol, order)
+		lt.orders.Track("open_long", "MARKET", order, quantity)
+		lt.recordOwnedOpen(symbol, "long", order, quantity, leverage)
+		lt.shadow.mirror("open_long", symbol, quantity, leverage, order)
+	}
+	return order, err
+}
+
+// OpenShort opens a short position and marks the symbol as traded
+func (lt *ledgerTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	lt.ownership.begin(lt.accountKey, symbol, "short")
+	defer lt.ownership.end(lt.accountKey, symbol, "short")
+	order, err := lt.Trader.OpenShort(symbol, quantity, leverage)
+	if err == nil {
+		lt.ledger.markSymbol(symbol)
+		lt.recordFee(symbol, order)
+		lt.orders.Track("open_short", "MARKET", order, quantity)
+		lt.recordOwnedOpen(symbol, "short", order, quantity, leverage)
+		lt.shadow.mirror("open_short", symbol, quantity, leverage, order)
+	}
+	return order, err
+}
+
+// CloseLong closes a long position and records its realized P&L
+func (lt *ledgerTrader) CloseLong(symbol string, quantity float64) (*Order, error) {
+	lt.ownership.begin(lt.accountKey, symbol, "long")
+	defer lt.ownership.end(lt.accountKey, symbol, "long")
+	estimate := lt.estimateClosePnL(symbol, "long", quantity)
+	closing := lt.closingQuantity(symbol, "long", quantity)
+	order, err := lt.Trader.CloseLong(symbol, quantity)
+	if err == nil {
+		lt.recordClose(symbol, "long", order, estimate)
+		lt.orders.Track("close_long", "MARKET", order, quantity)
+		lt.recordOwnedClose(symbol, "long", order, closing)
+		lt.shadow.mirror("close_long", symbol, quantity, 0, order)
+		if quantity == 0 {
+			lt.protection.flattened(symbol, "long")
+		}
+	}
+	return order, err
+}
+
+// CloseShort closes a short position and records its realized P&L
+func (lt *ledgerTrader) CloseShort(symbol string, quantity float64) (*Order, error) {
+	lt.ownership.begin(lt.accountKey, symbol, "short")
+	defer lt.ownership.end(lt.accountKey, symbol, "short")
+	estimate := lt.estimateClosePnL(symbol, "short", quantity)
+	closing := lt.closingQuantity(symbol, "short", quantity)
+	order, err := lt.Trader.CloseShort(symbol, quantity)
+	if err == nil {
+		lt.recordClose(symbol, "short", order, estimate)
+		lt.orders.Track("close_short", "MARKET", order, quantity)
+		lt.recordOwnedClose(symbol, "short", order, closing)
+		lt.shadow.mirror("close_short", symbol, quantity, 0, order)
+		if quantity == 0 {
+			lt.protection.flattened(symbol, "short")
+		}
+	}
+	return order, err
+}
+
+// SetStopLoss places the stop order and repeats it on the paper shadow
+func (lt *ledgerTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	if err := lt.Trader.SetStopLoss(symbol, positionSide, quantity, stopPrice); err != nil {
+		return err
+	}
+	lt.shadow.protect("stop_loss", symbol, positionSide, quantity, stopPrice)
+	return nil
+}
+
+// SetTakeProfit places the take profit order and repeats it on the paper shadow
+func (lt *ledgerTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	if err := lt.Trader.SetTakeProfit(symbol, positionSide, quantity, takeProfitPrice); err != nil {
+		return err
+	}
+	lt.shadow.protect("take_profit", symbol, positionSide, quantity, takeProfitPrice)
+	return nil
+}
+
+// OpenLimit places a limit entry (its fill is recorded once it fills, see recordLimitFill)
+func (lt *ledgerTrader) OpenLimit(symbol, positionSide string, quantity float64, leverage int, price float64, postOnly bool) (*Order, error) {
+	limit, err := limitOrdersOf(lt.Trader)
+	if err != nil {
+		return nil, err
+	}
+	return limit.OpenLimit(symbol, positionSide, quantity, leverage, price, postOnly)
+}
+
+// GetOrder returns the order's status and fills
+func (lt *ledgerTrader) GetOrder(symbol string, orderID int64) (*Order, error) {
+	limit, err := limitOrdersOf(lt.Trader)
+	if err != nil {
+		return nil, err
+	}
+	return limit.GetOrder(symbol, orderID)
+}
+
+// CancelOrder cancels one resting order
+func (lt *ledgerTrader) CancelOrder(symbol string, orderID int64) error {
+	limit, err := limitOrdersOf(lt.Trader)
+	if err != nil {
+		return err
+	}
+	return limit.CancelOrder(symbol, orderID)
+}
+
+// estimateClosePnL estimates realized P&L from the position's unrealized P&L just before closing
+func (lt *ledgerTrader) estimateClosePnL(symbol, side string, quantity float64) float64 {
+	positions, err := lt.Trader.GetPositions()
+	if err != nil {
+		return 0
+	}
+	for _, pos := range positions {
+		if pos.Symbol != symbol || pos.Side != side {
+			continue
+		}
+		if quantity > 0 && pos.Quantity > 0 && quantity < pos.Quantity {
+			return usdtFloat(dec(pos.UnrealizedProfit).Mul(dec(quantity)).Div(dec(pos.Quantity)))
+		}
+		return pos.UnrealizedProfit
+	}
+	return 0
+}
+
+// recordClose records the close using the exchange-reported P&L when available, otherwise the estimate
+func (lt *ledgerTrader) recordClose(symbol, side string, order *Order, estimate float64) {
+	pnl := estimate
+	if order.RealizedPnL != nil {
+		pnl = *order.RealizedPnL
+	}
+	lt.ledger.markSymbol(symbol)
+	lt.ledger.RecordClose(symbol, side, pnl)
+	lt.recordFee(symbol, order)
+	traderLog.Infof("  📒 Realized P&L recorded: %s %s %+.2f USDT", symbol, strings.ToUpper(side), pnl)
+}
+
+// recordFee records the order fee when the exchange reports one inline
+func (lt *ledgerTrader) recordFee(symbol string, order *Order) {
+	if order.Fee > 0 {
+		lt.ledger.RecordFee(symbol, order.Fee)
+	}
+}
+
+// recordOwnedOpen attributes an open's fill to this trader
+func (lt *ledgerTrader) recordOwnedOpen(symbol, side string, order *Order, quantity float64, leverage int) {
+	if lt.ownership == nil {
+		return
+	}
+	filled, price := lt.fillOf(symbol, order, quantity)
+	lt.ownership.RecordOpen(lt.accountKey, lt.traderID, symbol, side, filled, price, leverage, order.Fee)
+}
+
+// closingQuantity the quantity a close will execute: quantity, or the whole position for a close all (0), read
+// before the close (only needed to attribute ownership)
+func (lt *ledgerTrader) closingQuantity(symbol, side string, quantity float64) float64 {
+	if quantity > 0 || lt.ownership == nil {
+		return quantity
+	}
+	positions, err := lt.Trader.GetPositions()
+	if err != nil {
+		return 0
+	}
+	for _, pos := range positions {
+		if pos.Symbol == symbol && pos.Side == side {
+			return pos.Quantity
+		}
+	}
+	return 0
+}
+
+// recordOwnedClose removes a close's fill from the owners of the position
+func (lt *ledgerTrader) recordOwnedClose(symbol, side string, order *Order, quantity float64) {
+	if lt.ownership == nil {
+		return
+	}
+	filled, price := lt.fillOf(symbol, order, quantity)
+	lt.ownership.RecordClose(lt.accountKey, lt.traderID, symbol, side, filled, price, order.Fee)
+}
+
+// fillOf the executed quantity and price of a market order (requested quantity and current price when the
+// exchange acknowledges without fills)
+func (lt *ledgerTrader) fillOf(symbol string, order *Order, quantity float64) (float64, float64) {
+	filled, price := quantity, order.Price
+	if order.ExecutedQty > 0 {
+		filled = order.ExecutedQty
+	}
+	if price <= 0 {
+		price, _ = lt.Trader.GetMarketPrice(symbol)
+	}
+	return filled, price
+}
+
+// traderWrapper a wrapper around the exchange trader that adds bookkeeping or policy to the order path
+type traderWrapper interface {
+	unwrap() Trader
+}
+
+func (lt *ledgerTrader) unwrap() Trader {
+	return lt.Trader
+}
+
+// asLedgerTrader the ledger wrapper of t's order path
+func asLedgerTrader(t Trader) (*ledgerTrader, bool) {
+	for {
+		if lt, ok := t.(*ledgerTrader); ok {
+			return lt, true
+		}
+		w, ok := t.(traderWrapper)
+		if !ok {
+			return nil, false
+		}
+		t = w.unwrap()
+	}
+}
+
+// baseTrader returns the exchange trader behind the order-path wrappers (symbol filters, ledger, retries)
+func baseTrader(t Trader) Trader {
+	for {
+		w, ok := t.(traderWrapper)
+		if !ok {
+			return t
+		}
+		t = w.unwrap()
+	}
+}
+
+// asPaperTrader returns the underlying PaperTrader (if any), unwrapping the ledger wrapper
+func asPaperTrader(t Trader) (*PaperTrader, bool) {
+	pt, ok := baseTrader(t).(*PaperTrader)
+	return pt, ok
+}
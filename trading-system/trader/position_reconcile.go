@@ -0,0 +1,329 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Position origin sources (how the entry of an open position is known)
+const (
+	OriginOpened    = "opened"           // Opened by this trader while running
+	OriginJournal   = "trade_journal"    // Restored at startup from the open trade in the trade journal
+	OriginExchange  = "exchange_history" // Reconstructed at startup from the exchange's order history
+	OriginFirstSeen = "first_seen"       // Unknown: the time this trader first saw the position
+)
+
+// originOwnerUntagged owner of orders without a client order tag (placed manually or by other software)
+const originOwnerUntagged = "untagged"
+
+// positionHistoryWindow how far back the exchange's order history is searched for a position's entry (Binance
+// order history queries are limited to 7 days)
+const positionHistoryWindow = maxAuditRange
+
+// PositionOrigin when an open position was entered, by which decision and, on shared accounts, by which traders
+type PositionOrigin struct {
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"` // long/short
+	EntryTime    time.Time `json:"entry_time"`
+	EntryCycle   int       `json:"entry_cycle,omitempty"`    // Cycle of the opening decision (0 = unknown)
+	EntryOrderID int64     `json:"entry_order_id,omitempty"` // Exchange order that started the position (exchange_history)
+	Source       string    `json:"source"`
+	Owners       []string  `json:"owners,omitempty"` // Traders whose orders built the position (from client order tags)
+}
+
+// positionOrigins the origin of every open position (symbol_side), read by the API while cycles update it
+type positionOrigins struct {
+	mu      sync.RWMutex
+	origins map[string]*PositionOrigin
+}
+
+// newPositionOrigins creates an empty origin set
+func newPositionOrigins() *positionOrigins {
+	return &positionOrigins{origins: make(map[string]*PositionOrigin)}
+}
+
+// seen returns the entry time (unix milliseconds) of a position, recording now as its first-seen time when it
+// has no origin yet
+func (p *positionOrigins) seen(symbol, side string, now time.Time) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := symbol + "_" + side
+	origin, ok := p.origins[key]
+	if !ok {
+		origin = &PositionOrigin{Symbol: symbol, Side: side, EntryTime: now, Source: OriginFirstSeen}
+		p.origins[key] = origin
+	}
+	return origin.EntryTime.UnixMilli()
+}
+
+// opened records a position opened by this trader now
+func (p *positionOrigins) opened(symbol, side string, now time.Time) {
+	p.set(&PositionOrigin{Symbol: symbol, Side: side, EntryTime: now, Source: OriginOpened})
+}
+
+// set records a position's origin
+func (p *positionOrigins) set(origin *PositionOrigin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.origins[origin.Symbol+"_"+origin.Side] = origin
+}
+
+// get returns a copy of a position's origin
+func (p *positionOrigins) get(symbol, side string) (PositionOrigin, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	origin, ok := p.origins[symbol+"_"+side]
+	if !ok {
+		return PositionOrigin{}, false
+	}
+	return *origin, true
+}
+
+// prune forgets the positions that are no longer open (current: symbol_side keys of the open positions)
+func (p *positionOrigins) prune(current map[string]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.origins {
+		if !current[key] {
+			delete(p.origins, key)
+		}
+	}
+}
+
+// reconcilePositions rebuilds the bookkeeping of the positions already open at startup: their entry time and
+// opening decision (from the trade journal, or the exchange's order history when the journal has none), the
+// stop/target levels of that decision and its later adjustments, and on shared accounts the traders whose
+// orders built them
+func (at *AutoTrader) reconcilePositions() {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		at.log.Warnf("⚠ Position reconciliation skipped: %v", err)
+		return
+	}
+	if len(positions) == 0 {
+		return
+	}
+
+	openTrades := make(map[string]logger.Trade)
+	if trades, err := at.decisionLogger.GetTrades(logger.TradeOpen, 0); err != nil {
+		at.log.Warnf("⚠ Failed to load open trades: %v", err)
+	} else {
+		for _, t := range trades {
+			openTrades[t.Symbol+"_"+t.Side] = t
+		}
+	}
+	provider, _ := baseTrader(at.trader).(OrderHistoryProvider)
+	owners := at.ownerTags()
+	now := at.now()
+
+	at.log.Infof("🧭 Reconciling %d open position(s) with the trade journal and exchange history", len(positions))
+	history := make(map[string][]ExchangeOrder)
+	for _, pos := range positions {
+		origin := &PositionOrigin{Symbol: pos.Symbol, Side: pos.Side, EntryTime: now, Source: OriginFirstSeen}
+
+		var entry *ExchangeOrder
+		if provider != nil {
+			orders, ok := history[pos.Symbol]
+			if !ok {
+				if orders, err = provider.GetOrderHistory(pos.Symbol, now.Add(-positionHistoryWindow), now); err != nil {
+					at.log.Warnf("  ⚠ Failed to get %s order history: %v", pos.Symbol, err)
+				}
+				history[pos.Symbol] = orders
+			}
+			var contributors []ExchangeOrder
+			entry, contributors = positionEntry(orders, pos.Side, pos.Quantity)
+			origin.Owners = contributorOwners(contributors, owners)
+			if at.ownership != nil {
+				at.ownership.Seed(at.accountKey, pos, contributors)
+			}
+		}
+
+		if trade, ok := openTrades[pos.Symbol+"_"+pos.Side]; ok {
+			origin.EntryTime = trade.OpenTime
+			origin.EntryCycle = trade.OpenCycle
+			origin.Source = OriginJournal
+		} else if entry != nil {
+			origin.EntryTime = entry.Time
+			origin.EntryOrderID = entry.OrderID
+			origin.EntryCycle = at.entryCycle(entry)
+			origin.Source = OriginExchange
+		}
+		at.positionOrigins.set(origin)
+
+		restored := ""
+		if origin.EntryCycle > 0 {
+			if levels := at.restoreProtectionLevels(origin); levels != nil {
+				restored = fmt.Sprintf(", stop %.4f / target %.4f", levels.StopLoss, levels.TakeProfit)
+			}
+		}
+		ownersNote := ""
+		if len(origin.Owners) > 0 {
+			ownersNote = ", owners " + strings.Join(origin.Owners, ",")
+		}
+		at.log.Infof("  📍 %s %s: entered %s (%s, cycle #%d%s%s)", pos.Symbol, strings.ToUpper(pos.Side),
+			origin.EntryTime.Format("2006-01-02 15:04:05"), origin.Source, origin.EntryCycle, restored, ownersNote)
+	}
+}
+
+// positionEntry walks the symbol's fills back from the newest until they add up to the open quantity: the
+// oldest opening fill needed is the order that started the position. contributors are the opening fills
+// since then (nil entry = the position is older than the history)
+func positionEntry(orders []ExchangeOrder, side string, quantity float64) (entry *ExchangeOrder, contributors []ExchangeOrder) {
+	positionSide := positionSideOf(side)
+	sorted := make([]ExchangeOrder, 0, len(orders))
+	for _, o := range orders {
+		if o.ExecutedQty > 0 && (o.PositionSide == positionSide || o.PositionSide == "BOTH" || o.PositionSide == "") {
+			sorted = append(sorted, o)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.After(sorted[j].Time) })
+
+	remaining := quantity
+	for i := range sorted {
+		o := sorted[i]
+		if o.Side != orderSide(positionSide) {
+			remaining += o.ExecutedQty // A close: the position was larger before it
+			continue
+		}
+		contributors = append(contributors, o)
+		remaining -= o.ExecutedQty
+		if remaining <= quantity*1e-6 {
+			return &o, contributors
+		}
+	}
+	return nil, contributors
+}
+
+// ownerTags maps client order tags to the IDs of the traders running in this process
+func (at *AutoTrader) ownerTags() map[string]string {
+	tags := map[string]string{ClientOrderTag(at.id): at.id}
+	type traderLister interface {
+		GetAllTraders() map[string]*AutoTrader
+	}
+	if tm, ok := at.traderManager.(traderLister); ok {
+		for id := range tm.GetAllTraders() {
+			tags[ClientOrderTag(id)] = id
+		}
+	}
+	return tags
+}
+
+// contributorOwners the traders whose tagged orders built a position (unknown tags are kept as "tag:<tag>",
+// untagged orders as "untagged")
+func contributorOwners(contributors []ExchangeOrder, tags map[string]string) []string {
+	seen := make(map[string]bool)
+	var owners []string
+	for _, o := range contributors {
+		owner := originOwnerUntagged
+		if tag, _, ok := parseClientOrderID(o.ClientOrderID); ok {
+			if id, known := tags[tag]; known {
+				owner = id
+			} else {
+				owner = "tag:" + tag
+			}
+		}
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// entryCycle the cycle whose logged action placed an exchange order (0 = not placed by a logged decision)
+func (at *AutoTrader) entryCycle(order *ExchangeOrder) int {
+	actions, err := at.decisionLogger.GetActionsInRange(order.Time.Add(-10*time.Minute), order.Time.Add(10*time.Minute))
+	if err != nil {
+		return 0
+	}
+	for _, a := range actions {
+		if !a.Success || a.Symbol != order.Symbol {
+			continue
+		}
+		if (a.ClientOrderID != "" && a.ClientOrderID == order.ClientOrderID) || (a.OrderID != 0 && a.OrderID == order.OrderID) {
+			return a.CycleNumber
+		}
+	}
+	return 0
+}
+
+// restoreProtectionLevels replays the stop/target of a position's opening decision and the adjust_stop /
+// adjust_target decisions logged for it since (levels already tracked, e.g. by a resumed open, are kept)
+func (at *AutoTrader) restoreProtectionLevels(origin *PositionOrigin) *ProtectedPosition {
+	if levels, ok := at.positionProtection.levels(origin.Symbol, origin.Side); ok {
+		return &levels
+	}
+
+	actions, err := at.decisionLogger.GetActionsInRange(origin.EntryTime.Add(-time.Minute), at.now())
+	if err != nil {
+		at.log.Warnf("  ⚠ Failed to load %s %s actions: %v", origin.Symbol, origin.Side, err)
+		return nil
+	}
+	// Cycles with a successful open or adjustment of this symbol
+	actionsByCycle := make(map[int][]string)
+	var cycles []int
+	for _, a := range actions {
+		if !a.Success || a.Symbol != origin.Symbol || a.CycleNumber < origin.EntryCycle {
+			continue
+		}
+		switch a.Action {
+		case "open_" + origin.Side, "adjust_stop", "adjust_target":
+			if len(actionsByCycle[a.CycleNumber]) == 0 {
+				cycles = append(cycles, a.CycleNumber)
+			}
+			actionsByCycle[a.CycleNumber] = append(actionsByCycle[a.CycleNumber], a.Action)
+		}
+	}
+	if len(cycles) == 0 {
+		return nil
+	}
+	decisionJSONs, err := at.decisionLogger.GetDecisionJSONs(cycles)
+	if err != nil {
+		at.log.Warnf("  ⚠ Failed to load %s %s decisions: %v", origin.Symbol, origin.Side, err)
+		return nil
+	}
+
+	sort.Ints(cycles)
+	levels := &ProtectedPosition{}
+	for _, cycle := range cycles {
+		var decisions []decisionPkg.Decision
+		if err := json.Unmarshal([]byte(decisionJSONs[cycle]), &decisions); err != nil {
+			continue
+		}
+		for _, action := range actionsByCycle[cycle] {
+			for i := range decisions {
+				d := &decisions[i]
+				if d.Symbol != origin.Symbol || d.Action != action || (d.Side != "" && strings.ToLower(d.Side) != origin.Side) {
+					continue
+				}
+				switch action {
+				case "adjust_stop":
+					levels.StopLoss = d.StopLoss
+				case "adjust_target":
+					levels.TakeProfit = d.TakeProfit
+				default:
+					// A new open (or an add) sets the levels its take profit / stop orders were placed at
+					levels.TakeProfit = d.TakeProfit
+					levels.StopLoss = at.stopOrderPrice(d)
+				}
+				break
+			}
+		}
+	}
+	if levels.StopLoss <= 0 && levels.TakeProfit <= 0 {
+		return nil
+	}
+	at.positionProtection.update(origin.Symbol, origin.Side, func(tracked *ProtectedPosition) {
+		if tracked.StopLoss <= 0 && tracked.TakeProfit <= 0 {
+			tracked.StopLoss, tracked.TakeProfit = levels.StopLoss, levels.TakeProfit
+		}
+	})
+	return levels
+}
@@ -0,0 +1,301 @@
+package trader
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// protectionPruneGrace positions protected more recently than this are not pruned (the position snapshot of an
+// early cycle may predate the open)
+const protectionPruneGrace = time.Minute
+
+// OpenOrderLister optional interface for exchanges that list resting orders and cancel them one by one
+type OpenOrderLister interface {
+	// GetOpenOrders returns the resting orders of symbol ("" = every symbol)
+	GetOpenOrders(symbol string) ([]ExchangeOrder, error)
+
+	// CancelOrder cancels one resting order
+	CancelOrder(symbol string, orderID int64) error
+}
+
+// ProtectedPosition the stop loss / take profit levels this trader maintains for a position
+type ProtectedPosition struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`                  // long/short
+	StopLoss   float64   `json:"stop_loss,omitempty"`   // Stop price (0 = none)
+	TakeProfit float64   `json:"take_profit,omitempty"` // Take profit price (0 = none)
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// protectionBook the stop loss / take profit levels of this trader's positions and the one owner of their orders:
+// it places them, re-places them when a level is amended or the position resized, and cancels them once the
+// position is flattened (closed in full by the AI, the background monitor or an operator, or closed by the
+// exchange filling the other protection order). Only orders tagged with this trader's client order tag are
+// cancelled, so other traders' orders on a shared account are left alone
+type protectionBook struct {
+	mu        sync.Mutex
+	exchange  OpenOrderLister // nil = orders cannot be listed (levels are kept, orders are not cancelled one by one)
+	tag       string          // This trader's client order tag
+	positions map[string]*ProtectedPosition
+	now       func() time.Time
+}
+
+// newProtectionBook creates an empty book
+func newProtectionBook(exchange OpenOrderLister, tag string, now func() time.Time) *protectionBook {
+	return &protectionBook{
+		exchange:  exchange,
+		tag:       tag,
+		positions: make(map[string]*ProtectedPosition),
+		now:       now,
+	}
+}
+
+// levels the levels maintained for a position (false = none)
+func (pb *protectionBook) levels(symbol, side string) (ProtectedPosition, bool) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pos, ok := pb.positions[symbol+"_"+strings.ToLower(side)]
+	if !ok {
+		return ProtectedPosition{}, false
+	}
+	return *pos, true
+}
+
+// update changes the levels maintained for a position (created empty if needed). It does not touch the orders:
+// refresh re-places them
+func (pb *protectionBook) update(symbol, side string, change func(levels *ProtectedPosition)) {
+	side = strings.ToLower(side)
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pos, ok := pb.positions[symbol+"_"+side]
+	if !ok {
+		pos = &ProtectedPosition{Symbol: symbol, Side: side}
+		pb.positions[symbol+"_"+side] = pos
+	}
+	change(pos)
+	pos.UpdatedAt = pb.now()
+}
+
+// place places a stop loss or take profit order for a position (positionSide LONG/SHORT) and records its level
+func (pb *protectionBook) place(t Trader, kind, symbol, positionSide string, quantity, price float64) error {
+	var err error
+	if kind == ClientOrderKindStopLoss {
+		err = t.SetStopLoss(symbol, positionSide, quantity, price)
+	} else {
+		err = t.SetTakeProfit(symbol, positionSide, quantity, price)
+	}
+	if err != nil {
+		return err
+	}
+	pb.record(kind, symbol, positionSide, price)
+	return nil
+}
+
+// record records the level of a protection order placed for a position
+func (pb *protectionBook) record(kind, symbol, positionSide string, price float64) {
+	pb.update(symbol, positionSide, func(levels *ProtectedPosition) {
+		if kind == ClientOrderKindStopLoss {
+			levels.StopLoss = price
+		} else {
+			levels.TakeProfit = price
+		}
+	})
+}
+
+// refresh cancels this trader's protection orders of the symbol and re-places every level maintained for its
+// open positions (both sides together, some exchanges cancel per symbol). An exchange that cannot list orders
+// only gets the symbol's orders cancelled when the account is not shared with other traders
+func (pb *protectionBook) refresh(t Trader, symbol string, sharedAccount bool) {
+	if !pb.cancelOwn(symbol, "", "protection amended") {
+		if sharedAccount {
+			traderLog.Warnf("  ⚠ %s orders cannot be listed on this exchange and the account is shared: previous stop/target orders left in place", symbol)
+		} else if err := t.CancelAllOrders(symbol); err != nil {
+			traderLog.Warnf("  ⚠ Failed to cancel orders for %s: %v", symbol, err)
+		}
+	}
+
+	positions, err := t.GetPositions()
+	if err != nil {
+		traderLog.Warnf("  ⚠ Failed to get positions to restore orders for %s: %v", symbol, err)
+		return
+	}
+	for _, pos := range positions {
+		if pos.Symbol != symbol {
+			continue
+		}
+		levels, ok := pb.levels(symbol, pos.Side)
+		if !ok {
+			continue
+		}
+		positionSide := strings.ToUpper(pos.Side)
+		if levels.StopLoss > 0 {
+			if err := pb.place(t, ClientOrderKindStopLoss, symbol, positionSide, pos.Quantity, levels.StopLoss); err != nil {
+				traderLog.Warnf("  ⚠ Failed to set stop loss: %v", err)
+			}
+		}
+		if levels.TakeProfit > 0 {
+			if err := pb.place(t, ClientOrderKindTakeProfit, symbol, positionSide, pos.Quantity, levels.TakeProfit); err != nil {
+				traderLog.Warnf("  ⚠ Failed to set take profit: %v", err)
+			}
+		}
+	}
+}
+
+// flattened forgets the levels of a position that is no longer open and cancels this trader's protection orders
+func (pb *protectionBook) flattened(symbol, side string) {
+	if pb == nil {
+		return
+	}
+	pb.mu.Lock()
+	delete(pb.positions, symbol+"_"+side)
+	pb.mu.Unlock()
+	pb.cancelOwn(symbol, side, "position flattened")
+}
+
+// cancelOwn cancels this trader's protection orders of a symbol's position (side "" = both sides). Returns
+// false when the orders could not be listed, so nothing was cancelled
+func (pb *protectionBook) cancelOwn(symbol, side, reason string) bool {
+	if pb.exchange == nil {
+		return false
+	}
+	orders, err := pb.exchange.GetOpenOrders(symbol)
+	if err != nil {
+		traderLog.Warnf("  ⚠ Failed to list %s open orders, protection orders left in place: %v", symbol, err)
+		return false
+	}
+	for _, o := range pb.own(orders) {
+		if side == "" || protectedSide(o) == side {
+			pb.cancel(o, reason)
+		}
+	}
+	return true
+}
+
+// prune flattens the positions that are no longer open (current: symbol_side keys of the open positions), which
+// catches positions the exchange closed by filling a stop or take profit
+func (pb *protectionBook) prune(current map[string]bool) {
+	cutoff := pb.now().Add(-protectionPruneGrace)
+	pb.mu.Lock()
+	var closed []ProtectedPosition
+	for key, pos := range pb.positions {
+		if !current[key] && pos.UpdatedAt.Before(cutoff) {
+			closed = append(closed, *pos)
+		}
+	}
+	pb.mu.Unlock()
+
+	for _, pos := range closed {
+		if pb.exchange != nil {
+			traderLog.Infof("🧹 %s %s is closed, cancelling its remaining protection orders", pos.Symbol, pos.Side)
+		}
+		pb.flattened(pos.Symbol, pos.Side)
+	}
+}
+
+// reconcile runs at startup: cancels this trader's protection orders whose position is no longer open (orphans
+// left by a position closed while the trader was down) and records the levels of the positions still protected
+func (pb *protectionBook) reconcile(positions []Position) {
+	if pb.exchange == nil {
+		return
+	}
+	orders, err := pb.exchange.GetOpenOrders("")
+	if err != nil {
+		traderLog.Warnf("⚠ Protection order reconciliation skipped: %v", err)
+		return
+	}
+
+	open := make(map[string]bool)
+	for _, pos := range positions {
+		open[pos.Symbol+"_"+pos.Side] = true
+	}
+	orphans := 0
+	for _, o := range pb.own(orders) {
+		side := protectedSide(o)
+		if open[o.Symbol+"_"+side] {
+			_, kind, _ := parseClientOrderID(o.ClientOrderID)
+			pb.record(kind, o.Symbol, side, o.StopPrice)
+			continue
+		}
+		if pb.cancel(o, "orphan, no open position") {
+			orphans++
+		}
+	}
+	if orphans > 0 {
+		traderLog.Infof("🧹 Cancelled %d orphan protection order(s) left by positions closed since the last run", orphans)
+	}
+}
+
+// Positions the protected positions, by symbol and side
+func (pb *protectionBook) Positions() []ProtectedPosition {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	positions := make([]ProtectedPosition, 0, len(pb.positions))
+	for _, pos := range pb.positions {
+		positions = append(positions, *pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Symbol != positions[j].Symbol {
+			return positions[i].Symbol < positions[j].Symbol
+		}
+		return positions[i].Side < positions[j].Side
+	})
+	return positions
+}
+
+// own the stop loss and take profit orders this trader placed
+func (pb *protectionBook) own(orders []ExchangeOrder) []ExchangeOrder {
+	var own []ExchangeOrder
+	for _, o := range orders {
+		tag, kind, ok := parseClientOrderID(o.ClientOrderID)
+		if ok && tag == pb.tag && (kind == ClientOrderKindStopLoss || kind == ClientOrderKindTakeProfit) {
+			own = append(own, o)
+		}
+	}
+	return own
+}
+
+// cancel cancels one protection order, reporting whether it was cancelled
+func (pb *protectionBook) cancel(o ExchangeOrder, reason string) bool {
+	if err := pb.exchange.CancelOrder(o.Symbol, o.OrderID); err != nil {
+		traderLog.Warnf("  ⚠ Failed to cancel %s %s order %d (%s): %v", o.Symbol, o.Type, o.OrderID, reason, err)
+		return false
+	}
+	traderLog.Infof("  🧹 Cancelled %s %s order %d at %.4f (%s)", o.Symbol, o.Type, o.OrderID, o.StopPrice, reason)
+	return true
+}
+
+// protectedSide the side (long/short) of the position a protection order closes (one-way mode reports BOTH:
+// a sell closes a long, a buy closes a short)
+func protectedSide(o ExchangeOrder) string {
+	switch o.PositionSide {
+	case "LONG":
+		return "long"
+	case "SHORT":
+		return "short"
+	}
+	if o.Side == "SELL" {
+		return "long"
+	}
+	return "short"
+}
+
+// refreshProtectionOrders re-places the stop / target orders of the symbol's positions after an amendment, an
+// add or a partial close
+func (at *AutoTrader) refreshProtectionOrders(symbol string) {
+	at.positionProtection.refresh(at.trader, symbol, at.ownership.shared(at.accountKey))
+}
+
+// reconcileProtectionOrders reconciles this trader's protection orders with the open positions at startup
+func (at *AutoTrader) reconcileProtectionOrders() {
+	if at.positionProtection.exchange == nil {
+		return
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		at.log.Warnf("⚠ Protection order reconciliation skipped: %v", err)
+		return
+	}
+	at.positionProtection.reconcile(positions)
+}
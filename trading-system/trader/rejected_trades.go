@@ -0,0 +1,335 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/market"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	rejectedSimHorizon    = 24 * time.Hour      // Simulations neither stopped out nor at target by then close at market
+	rejectedSimInterval   = "5m"                // Kline interval used to replay the price path
+	rejectedSimMaxKlines  = 300                 // 25h of 5m klines (covers the horizon)
+	rejectedSummaryWindow = 7 * 24 * time.Hour  // Window of the feedback given to the AI
+	rejectedRetention     = 30 * 24 * time.Hour // Rejected trades kept for the API
+	rejectedMaxRecords    = 1000
+)
+
+// Rejected trade simulation status
+const (
+	RejectedSimPending    = "pending"
+	RejectedSimTakeProfit = "take_profit"
+	RejectedSimStopLoss   = "stop_loss"
+	RejectedSimExpired    = "expired" // Neither hit within the horizon, closed at market
+)
+
+// RejectedTrade an open decision rejected by validation and its simulated outcome
+type RejectedTrade struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Category   string    `json:"category"`
+	Reason     string    `json:"reason"`
+	RejectedAt time.Time `json:"rejected_at"`
+	EntryPrice float64   `json:"entry_price"` // Market price when the decision was rejected
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	Leverage   int       `json:"leverage"`
+	MarginUSD  float64   `json:"margin_usd"`
+	Status     string    `json:"status"`
+	ExitPrice  float64   `json:"exit_price,omitempty"`
+	ExitTime   time.Time `json:"exit_time,omitempty"`
+	PnL        float64   `json:"pnl"`     // Hypothetical P&L (USDT, before fees)
+	PnLPct     float64   `json:"pnl_pct"` // Hypothetical P&L as % of margin
+}
+
+// RejectedTradeSimulator records open decisions rejected by validation and simulates what they would
+// have made from subsequent market data, so the AI (and operators) can judge whether the rules are too strict
+type RejectedTradeSimulator struct {
+	path   string
+	mu     sync.Mutex
+	trades []*RejectedTrade
+}
+
+// NewRejectedTradeSimulator creates a simulator persisted at path, loading existing records
+func NewRejectedTradeSimulator(path string) *RejectedTradeSimulator {
+	s := &RejectedTradeSimulator{path: path}
+	if err := s.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load rejected trades (%s): %v - starting empty", path, err)
+	}
+	return s
+}
+
+// Record stores rejected open decisions with the current price as hypothetical entry
+// Other rejected actions (closes, amends) have no outcome to simulate and are skipped
+func (s *RejectedTradeSimulator) Record(rejected []decisionPkg.RejectedDecision, marketData map[string]*market.Data) int {
+	now := time.Now()
+	var added []*RejectedTrade
+	for _, r := range rejected {
+		d := r.Decision
+		side := ""
+		switch d.Action {
+		case "open_long":
+			side = "long"
+		case "open_short":
+			side = "short"
+		default:
+			continue
+		}
+
+		var price float64
+		if data, ok := marketData[d.Symbol]; ok && data != nil {
+			price = data.CurrentPrice
+		} else if data, err := market.Get(d.Symbol); err == nil {
+			price = data.CurrentPrice
+		}
+		// Without a sane entry/stop/target the path can't be simulated
+		if price <= 0 || d.StopLoss <= 0 || d.TakeProfit <= 0 || d.Leverage <= 0 || d.PositionSizeUSD <= 0 {
+			continue
+		}
+		if side == "long" && !(d.StopLoss < price && price < d.TakeProfit) ||
+			side == "short" && !(d.TakeProfit < price && price < d.StopLoss) {
+			continue
+		}
+
+		added = append(added, &RejectedTrade{
+			Symbol:     d.Symbol,
+			Side:       side,
+			Category:   r.Category,
+			Reason:     r.Reason,
+			RejectedAt: now,
+			EntryPrice: price,
+			StopLoss:   d.StopLoss,
+			TakeProfit: d.TakeProfit,
+			Leverage:   d.Leverage,
+			MarginUSD:  d.PositionSizeUSD,
+			Status:     RejectedSimPending,
+		})
+	}
+	if len(added) == 0 {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trades = append(s.trades, added...)
+	s.prune(now)
+	s.save()
+	return len(added)
+}
+
+// Update advances pending simulations using klines since each rejection (one fetch per symbol)
+func (s *RejectedTradeSimulator) Update() {
+	s.mu.Lock()
+	oldest := make(map[string]time.Time)
+	for _, t := range s.trades {
+		if t.Status != RejectedSimPending {
+			continue
+		}
+		if at, ok := oldest[t.Symbol]; !ok || t.RejectedAt.Before(at) {
+			oldest[t.Symbol] = t.RejectedAt
+		}
+	}
+	s.mu.Unlock()
+	if len(oldest) == 0 {
+		return
+	}
+
+	klines := make(map[string][]market.Kline)
+	for symbol, since := range oldest {
+		limit := int(time.Since(since)/(5*time.Minute)) + 2
+		if limit > rejectedSimMaxKlines {
+			limit = rejectedSimMaxKlines
+		}
+		k, err := market.GetKlines(symbol, rejectedSimInterval, limit)
+		if err != nil {
+			traderLog.Warnf("⚠️  Rejected trade simulation: failed to get %s klines: %v", symbol, err)
+			continue
+		}
+		klines[symbol] = k
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resolved := 0
+	for _, t := range s.trades {
+		if t.Status != RejectedSimPending {
+			continue
+		}
+		if k, ok := klines[t.Symbol]; ok && t.simulate(k, now) {
+			resolved++
+		}
+	}
+	if resolved > 0 {
+		traderLog.Infof("🚫 Rejected trade simulation: %d rejected trades resolved", resolved)
+		s.save()
+	}
+}
+
+// simulate walks the price path after the rejection. Returns true once the outcome is known
+// When a kline touches both stop and target, the stop is assumed to fill first (conservative)
+func (t *RejectedTrade) simulate(klines []market.Kline, now time.Time) bool {
+	start := t.RejectedAt.UnixMilli()
+	deadline := t.RejectedAt.Add(rejectedSimHorizon).UnixMilli()
+	var last *market.Kline
+	for i := range klines {
+		k := &klines[i]
+		if k.OpenTime < start || k.OpenTime >= deadline {
+			continue
+		}
+		last = k
+		stopHit := t.Side == "long" && k.Low <= t.StopLoss || t.Side == "short" && k.High >= t.StopLoss
+		targetHit := t.Side == "long" && k.High >= t.TakeProfit || t.Side == "short" && k.Low <= t.TakeProfit
+		switch {
+		case stopHit:
+			t.close(RejectedSimStopLoss, t.StopLoss, time.UnixMilli(k.CloseTime))
+			return true
+		case targetHit:
+			t.close(RejectedSimTakeProfit, t.TakeProfit, time.UnixMilli(k.CloseTime))
+			return true
+		}
+	}
+
+	if now.UnixMilli() >= deadline && last != nil {
+		t.close(RejectedSimExpired, last.Close, time.UnixMilli(last.CloseTime))
+		return true
+	}
+	return false
+}
+
+// close sets the simulated exit
+func (t *RejectedTrade) close(status string, exitPrice float64, exitTime time.Time) {
+	move := (exitPrice - t.EntryPrice) / t.EntryPrice
+	if t.Side == "short" {
+		move = -move
+	}
+	t.Status = status
+	t.ExitPrice = exitPrice
+	t.ExitTime = exitTime
+	t.PnL = t.MarginUSD * float64(t.Leverage) * move
+	t.PnLPct = move * float64(t.Leverage) * 100
+}
+
+// Summary aggregates rejected trades of the last window (nil if none were rejected)
+func (s *RejectedTradeSimulator) Summary(window time.Duration) *decisionPkg.RejectedTradeSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := time.Now().Add(-window)
+	summary := &decisionPkg.RejectedTradeSummary{WindowDays: int(window.Hours() / 24)}
+	categories := make(map[string]*decisionPkg.RejectedCategoryStats)
+	for _, t := range s.trades {
+		if t.RejectedAt.Before(since) {
+			continue
+		}
+		summary.Rejected++
+		c, ok := categories[t.Category]
+		if !ok {
+			c = &decisionPkg.RejectedCategoryStats{Category: t.Category}
+			categories[t.Category] = c
+		}
+		c.Rejected++
+
+		switch t.Status {
+		case RejectedSimPending:
+			summary.Pending++
+			continue
+		case RejectedSimTakeProfit:
+			summary.TakeProfit++
+		case RejectedSimStopLoss:
+			summary.StopLoss++
+		case RejectedSimExpired:
+			summary.Expired++
+		}
+		summary.Resolved++
+		summary.TotalPnL += t.PnL
+		c.Resolved++
+		c.PnL += t.PnL
+		if t.PnL > 0 {
+			c.Wins++
+		}
+	}
+	if summary.Rejected == 0 {
+		return nil
+	}
+
+	for _, c := range categories {
+		summary.ByCategory = append(summary.ByCategory, *c)
+	}
+	sort.Slice(summary.ByCategory, func(i, j int) bool {
+		return summary.ByCategory[i].Rejected > summary.ByCategory[j].Rejected
+	})
+	return summary
+}
+
+// Trades returns the most recent rejected trades, newest first (limit <= 0 = all)
+func (s *RejectedTradeSimulator) Trades(limit int) []RejectedTrade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]RejectedTrade, 0, len(s.trades))
+	for i := len(s.trades) - 1; i >= 0; i-- {
+		result = append(result, *s.trades[i])
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// prune drops records past retention and caps the total (caller holds mu)
+func (s *RejectedTradeSimulator) prune(now time.Time) {
+	cutoff := now.Add(-rejectedRetention)
+	kept := s.trades[:0]
+	for _, t := range s.trades {
+		if t.RejectedAt.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) > rejectedMaxRecords {
+		kept = kept[len(kept)-rejectedMaxRecords:]
+	}
+	s.trades = kept
+}
+
+// load reads persisted records (a missing file is not an error)
+func (s *RejectedTradeSimulator) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.trades); err != nil {
+		return fmt.Errorf("failed to parse rejected trades: %w", err)
+	}
+	return nil
+}
+
+// save persists the records (caller holds mu); failures are logged
+func (s *RejectedTradeSimulator) save() {
+	data, err := json.Marshal(s.trades)
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize rejected trades: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create rejected trades directory: %v", err)
+		return
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write rejected trades: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace rejected trades file: %v", err)
+	}
+}
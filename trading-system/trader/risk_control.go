@@ -0,0 +1,272 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// riskControl daily loss and drawdown limits. Updated from the account equity every cycle; hitting a limit pauses
+// new decisions for StopTradingTime (the position monitor keeps protecting open positions). The baselines, peak
+// and stop are persisted so a restart neither starts a new day nor forgets the high-water mark
+type riskControl struct {
+	mu               sync.Mutex
+	path             string    // Persisted state file
+	day              time.Time // Start of the current day (UTC)
+	dayStartEquity   float64   // Equity at the first cycle of the day
+	dayStartRealized float64   // Ledger realized P&L at the first cycle of the day
+	equity           float64   // Equity at the last cycle
+	realizedToday    float64   // Closes, fees and funding banked today
+	peakEquity       float64   // Highest equity seen (drawdown reference)
+	stopUntil        time.Time // New decisions paused until this time
+	stopReason       string
+	stopDaily        bool // The active stop is the daily loss limit
+	stoppedAt        time.Time
+	dailyCleared     bool // Operator cleared today's daily loss stop: not re-triggered until the next day
+}
+
+// RiskStatus daily P&L and the state of the risk limits (for API)
+type RiskStatus struct {
+	Day              string  `json:"day"` // UTC date the daily P&L covers
+	DayStartEquity   float64 `json:"day_start_equity"`
+	Equity           float64 `json:"equity"`
+	DailyPnL         float64 `json:"daily_pnl"`     // Realized + unrealized since the start of the day
+	DailyPnLPct      float64 `json:"daily_pnl_pct"` // Of day_start_equity
+	RealizedToday    float64 `json:"realized_today"`
+	UnrealizedChange float64 `json:"unrealized_change"` // daily_pnl - realized_today
+	PeakEquity       float64 `json:"peak_equity"`
+	DrawdownPct      float64 `json:"drawdown_pct"` // Below peak_equity
+	MaxDailyLossPct  float64 `json:"max_daily_loss_pct"`
+	MaxDrawdownPct   float64 `json:"max_drawdown_pct"`
+	Stopped          bool    `json:"stopped"`
+	StopUntil        string  `json:"stop_until,omitempty"`
+	StopReason       string  `json:"stop_reason,omitempty"`
+	StoppedAt        string  `json:"stopped_at,omitempty"`
+	DailyLossCleared bool    `json:"daily_loss_cleared"` // Daily loss stop cleared by the operator for today
+}
+
+// riskState the persisted part of riskControl
+type riskState struct {
+	Day              time.Time `json:"day"`
+	DayStartEquity   float64   `json:"day_start_equity"`
+	DayStartRealized float64   `json:"day_start_realized"`
+	PeakEquity       float64   `json:"peak_equity"`
+	StopUntil        time.Time `json:"stop_until"`
+	StopReason       string    `json:"stop_reason,omitempty"`
+	StopDaily        bool      `json:"stop_daily,omitempty"`
+	StoppedAt        time.Time `json:"stopped_at"`
+	DailyCleared     bool      `json:"daily_cleared,omitempty"`
+}
+
+// newRiskControl creates the risk state, restored from path (the baselines of a new trader are set by the first
+// update)
+func newRiskControl(path string) *riskControl {
+	r := &riskControl{path: path}
+	if err := r.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load risk state (%s): %v - starting from the next cycle's equity", path, err)
+		*r = riskControl{path: path}
+	}
+	return r
+}
+
+// load reads persisted state (a missing file is not an error)
+func (r *riskControl) load() error {
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state riskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse risk state: %w", err)
+	}
+	r.day = state.Day
+	r.dayStartEquity = state.DayStartEquity
+	r.dayStartRealized = state.DayStartRealized
+	r.peakEquity = state.PeakEquity
+	r.stopUntil = state.StopUntil
+	r.stopReason = state.StopReason
+	r.stopDaily = state.StopDaily
+	r.stoppedAt = state.StoppedAt
+	r.dailyCleared = state.DailyCleared
+	return nil
+}
+
+// save persists the state (caller holds mu); failures are logged
+func (r *riskControl) save() {
+	data, err := json.MarshalIndent(riskState{
+		Day:              r.day,
+		DayStartEquity:   r.dayStartEquity,
+		DayStartRealized: r.dayStartRealized,
+		PeakEquity:       r.peakEquity,
+		StopUntil:        r.stopUntil,
+		StopReason:       r.stopReason,
+		StopDaily:        r.stopDaily,
+		StoppedAt:        r.stoppedAt,
+		DailyCleared:     r.dailyCleared,
+	}, "", "  ")
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize risk state: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create risk state directory: %v", err)
+		return
+	}
+	tmpPath := r.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write risk state: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, r.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace risk state file: %v", err)
+	}
+}
+
+// riskStopRemaining time left of an active risk stop (0 = not stopped)
+func (at *AutoTrader) riskStopRemaining() time.Duration {
+	at.risk.mu.Lock()
+	defer at.risk.mu.Unlock()
+	if remaining := at.risk.stopUntil.Sub(at.now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// updateRiskControl records this cycle's equity and realized P&L and checks the daily loss and drawdown limits.
+// Returns the reason when a limit was hit and trading is paused. The peak is kept across pauses: a drawdown stop
+// comes back after StopTradingTime while equity is still below the limit, until equity recovers or an operator
+// resets the peak
+func (at *AutoTrader) updateRiskControl(equity float64) string {
+	if equity <= 0 {
+		return ""
+	}
+	realized := at.pnlLedger.Summary().RealizedPnL
+
+	r := at.risk
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer r.save()
+
+	now := at.now()
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(r.day) {
+		if !r.day.IsZero() {
+			at.log.Infof("📅 Daily P&L reset (previous day: %+.2f USDT)", r.equity-r.dayStartEquity)
+		}
+		r.day = day
+		r.dayStartEquity = equity
+		r.dayStartRealized = realized
+		r.dailyCleared = false
+	}
+	r.equity = equity
+	r.realizedToday = realized - r.dayStartRealized
+	if equity > r.peakEquity {
+		r.peakEquity = equity
+	}
+
+	if now.Before(r.stopUntil) {
+		return ""
+	}
+
+	var reason string
+	stopDaily := false
+	dailyPnL := equity - r.dayStartEquity
+	drawdownPct := (r.peakEquity - equity) / r.peakEquity * 100
+	switch {
+	case at.config.MaxDailyLoss > 0 && !r.dailyCleared && dailyPnL < 0 && -dailyPnL/r.dayStartEquity*100 >= at.config.MaxDailyLoss:
+		reason = fmt.Sprintf("daily loss %.2f USDT (%.2f%%) reached max_daily_loss %.2f%%",
+			dailyPnL, dailyPnL/r.dayStartEquity*100, at.config.MaxDailyLoss)
+		stopDaily = true
+	case at.config.MaxDrawdown > 0 && drawdownPct >= at.config.MaxDrawdown:
+		reason = fmt.Sprintf("drawdown %.2f%% from peak equity %.2f reached max_drawdown %.2f%%",
+			drawdownPct, r.peakEquity, at.config.MaxDrawdown)
+	default:
+		return ""
+	}
+
+	r.stopUntil = now.Add(at.config.StopTradingTime)
+	r.stopReason = reason
+	r.stopDaily = stopDaily
+	r.stoppedAt = now
+	at.log.Warnf("🛑 Risk control: %s - trading paused for %v", reason, at.config.StopTradingTime)
+	return reason
+}
+
+// GetRiskStatus daily P&L and the risk stop state
+func (at *AutoTrader) GetRiskStatus() RiskStatus {
+	r := at.risk
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := RiskStatus{
+		DayStartEquity:   r.dayStartEquity,
+		Equity:           r.equity,
+		RealizedToday:    r.realizedToday,
+		PeakEquity:       r.peakEquity,
+		MaxDailyLossPct:  at.config.MaxDailyLoss,
+		MaxDrawdownPct:   at.config.MaxDrawdown,
+		Stopped:          at.now().Before(r.stopUntil),
+		StopReason:       r.stopReason,
+		DailyLossCleared: r.dailyCleared,
+	}
+	if !r.day.IsZero() {
+		status.Day = r.day.Format("2006-01-02")
+		status.DailyPnL = r.equity - r.dayStartEquity
+		status.UnrealizedChange = status.DailyPnL - r.realizedToday
+		if r.dayStartEquity > 0 {
+			status.DailyPnLPct = status.DailyPnL / r.dayStartEquity * 100
+		}
+		if r.peakEquity > 0 {
+			status.DrawdownPct = (r.peakEquity - r.equity) / r.peakEquity * 100
+		}
+	}
+	if !r.stopUntil.IsZero() {
+		status.StopUntil = r.stopUntil.Format(time.RFC3339)
+	}
+	if !r.stoppedAt.IsZero() {
+		status.StoppedAt = r.stoppedAt.Format(time.RFC3339)
+	}
+	return status
+}
+
+// dailyPnL realized + unrealized P&L since the start of the day (0 before the first cycle)
+func (at *AutoTrader) dailyPnL() float64 {
+	at.risk.mu.Lock()
+	defer at.risk.mu.Unlock()
+	if at.risk.day.IsZero() {
+		return 0
+	}
+	return at.risk.equity - at.risk.dayStartEquity
+}
+
+// ClearRiskStop ends an active risk stop. A cleared daily loss stop is not re-triggered until the next day; a
+// cleared drawdown stop comes back at the next cycle while equity is still below the limit, unless resetPeak
+// rebases the peak on the current equity (the peak is reset even when no stop is active). Returns false if no
+// stop was active
+func (at *AutoTrader) ClearRiskStop(resetPeak bool) bool {
+	r := at.risk
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if resetPeak && r.equity > 0 {
+		at.log.Infof("📉 Drawdown peak reset by operator: %.2f -> %.2f", r.peakEquity, r.equity)
+		r.peakEquity = r.equity
+		r.save()
+	}
+	if !at.now().Before(r.stopUntil) {
+		return false
+	}
+	at.log.Infof("▶️  Risk stop cleared by operator (%s)", r.stopReason)
+	r.stopUntil = time.Time{}
+	if r.stopDaily {
+		r.dailyCleared = true
+	}
+	r.save()
+	return true
+}
@@ -0,0 +1,334 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lia/config"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/logging"
+	"lia/mcp"
+	"strings"
+)
+
+// riskOfficerSystemPrompt instructions of the AI reviewer
+const riskOfficerSystemPrompt = `You are the risk officer of a crypto perpetual futures trading desk. A trading model has proposed the position opens below; they already passed format and limit validation.
+Review each open against the account state and the market regime. You may approve it, veto it, or downsize it (keep a share of its margin between 0 and 1).
+Veto or downsize opens that concentrate risk, fight the market regime, overload the account or are weakly justified. Approve opens that are sound; do not propose new trades.
+
+Answer with a single JSON object and nothing else:
+{"verdicts": [{"index": 1, "verdict": "approve" | "veto" | "downsize", "size_factor": 0.5, "reason": "..."}], "summary": "..."}
+size_factor is only read for "downsize". Give one verdict per open, by its index.`
+
+// riskOfficer reviews each cycle's opens after validation and before execution: rule checks against the account
+// and the BTC regime, then optionally an AI reviewer. It can veto or downsize opens; everything else passes
+type riskOfficer struct {
+	config config.RiskOfficerConfig
+	client *mcp.Client // AI reviewer (nil = rules only)
+	log    logging.Logger
+}
+
+// riskItem an open under review
+type riskItem struct {
+	index    int // Position in the cycle's decision list
+	decision decisionPkg.Decision
+	verdict  logger.RiskVerdict
+	reasons  []string
+}
+
+// newRiskOfficer the trader's risk officer (nil = not configured)
+func newRiskOfficer(traderConfig AutoTraderConfig) *riskOfficer {
+	cfg := traderConfig.RiskOfficer
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	officer := &riskOfficer{config: *cfg, log: logging.ForTrader(traderConfig.ID, traderConfig.Name)}
+	if cfg.Mode == config.RiskOfficerAI {
+		provider := cfg.Provider
+		if provider == "" {
+			provider = traderConfig.AIModel
+		}
+		client := newFailoverClient(provider, traderConfig)
+		if client == nil {
+			officer.log.Warnf("⚠️  Risk officer: unknown provider '%s' - reviewing with rules only", provider)
+			return officer
+		}
+		configureAIClient(client, traderConfig)
+		if cfg.Model != "" {
+			client.Model = cfg.Model
+		}
+		officer.client = client
+		officer.log.Infof("🛡️  Risk officer: rules + AI review (%s %s)", client.Provider, client.Model)
+	} else {
+		officer.log.Infof("🛡️  Risk officer: rules (max margin %.0f%%, max position %.0f%%)", cfg.MaxMarginUsedPct, cfg.MaxPositionPct)
+	}
+	return officer
+}
+
+// review rules on the opens in decisions and returns the list to execute: vetoed opens removed, downsized opens
+// with their reduced margin (nil review = nothing to review)
+func (ro *riskOfficer) review(reqCtx context.Context, ctx *decisionPkg.Context, decisions []decisionPkg.Decision) ([]decisionPkg.Decision, *logger.RiskReview) {
+	var items []*riskItem
+	for i, d := range decisions {
+		if decisionPkg.IsEntryAction(d.Action) {
+			items = append(items, &riskItem{
+				index:    i,
+				decision: d,
+				verdict:  logger.RiskVerdict{Symbol: d.Symbol, Action: d.Action, SizeBefore: d.PositionSizeUSD, SizeAfter: d.PositionSizeUSD},
+			})
+		}
+	}
+	if len(items) == 0 {
+		return decisions, nil
+	}
+
+	review := &logger.RiskReview{Reviewer: config.RiskOfficerRules}
+	ro.applyRules(ctx, items)
+	if ro.client != nil {
+		review.Reviewer = "ai:" + ro.client.Model
+		ro.applyAIReview(reqCtx, ctx, items, review)
+	}
+
+	vetoed := make(map[int]bool)
+	result := make([]decisionPkg.Decision, len(decisions))
+	copy(result, decisions)
+	for _, item := range items {
+		v := &item.verdict
+		v.Reason = strings.Join(item.reasons, "; ")
+		switch {
+		case v.SizeAfter <= 0:
+			v.Verdict = logger.RiskVeto
+			v.SizeAfter = 0
+			vetoed[item.index] = true
+			ro.log.Infof("🛡️  Risk officer vetoed %s %s (%.2f USDT): %s", v.Symbol, v.Action, v.SizeBefore, v.Reason)
+		case v.SizeAfter < v.SizeBefore:
+			v.Verdict = logger.RiskDownsize
+			d := &result[item.index]
+			if d.RiskUSD > 0 {
+				d.RiskUSD *= v.SizeAfter / v.SizeBefore
+			}
+			d.PositionSizeUSD = v.SizeAfter
+			ro.log.Infof("🛡️  Risk officer downsized %s %s %.2f → %.2f USDT: %s", v.Symbol, v.Action, v.SizeBefore, v.SizeAfter, v.Reason)
+		default:
+			v.Verdict = logger.RiskApprove
+		}
+		review.Verdicts = append(review.Verdicts, *v)
+	}
+
+	kept := result[:0]
+	for i, d := range result {
+		if !vetoed[i] {
+			kept = append(kept, d)
+		}
+	}
+	return kept, review
+}
+
+// applyRules the rule checks, in decision order: crash vetoes, regime and drawdown downsizing, then the per-position
+// and total margin caps. An open downsized below the minimum margin is vetoed
+func (ro *riskOfficer) applyRules(ctx *decisionPkg.Context, items []*riskItem) {
+	equity := ctx.Account.TotalEquity
+	regime, downtrend := decisionPkg.BTCRegime(ctx)
+	marginUsed := ctx.Account.MarginUsed
+
+	for _, item := range items {
+		long := decisionPkg.EntrySide(item.decision.Action) == "long"
+		size := item.verdict.SizeAfter
+
+		if long && regime == decisionPkg.RegimeCrashing && *ro.config.VetoLongsInCrash {
+			item.veto("long while BTC is crashing")
+			continue
+		}
+		switch {
+		case long && (regime == decisionPkg.RegimeCrashing || downtrend):
+			size = item.downsize(size, ro.config.CounterTrendFactor, "long against the BTC downtrend")
+		case !long && regime == decisionPkg.RegimeBullish:
+			size = item.downsize(size, ro.config.CounterTrendFactor, "short against the bullish BTC regime")
+		}
+		if ctx.Account.TotalPnLPct <= -ro.config.DrawdownPct {
+			size = item.downsize(size, ro.config.DrawdownFactor, fmt.Sprintf("account drawdown %.1f%%", ctx.Account.TotalPnLPct))
+		}
+
+		if equity > 0 {
+			limit := equity * ro.config.MaxPositionPct / 100
+			if decisionPkg.IsAddAction(item.decision.Action) {
+				limit -= heldMargin(ctx, item.decision.Symbol, decisionPkg.EntrySide(item.decision.Action)) // The cap covers the whole position
+			}
+			if size > limit {
+				size = max(limit, 0)
+				item.reasons = append(item.reasons, fmt.Sprintf("capped at %.0f%% of equity per position", ro.config.MaxPositionPct))
+			}
+			room := equity*ro.config.MaxMarginUsedPct/100 - marginUsed
+			if room <= 0 {
+				item.veto(fmt.Sprintf("margin used already at the %.0f%% limit", ro.config.MaxMarginUsedPct))
+				continue
+			}
+			if size > room {
+				size = room
+				item.reasons = append(item.reasons, fmt.Sprintf("capped to keep margin used under %.0f%%", ro.config.MaxMarginUsedPct))
+			}
+		}
+
+		item.resize(size, ctx)
+		marginUsed += item.verdict.SizeAfter
+	}
+}
+
+// applyAIReview asks the AI reviewer about the opens the rules kept. A failed review vetoes them unless fail_open
+func (ro *riskOfficer) applyAIReview(reqCtx context.Context, ctx *decisionPkg.Context, items []*riskItem, review *logger.RiskReview) {
+	var pending []*riskItem
+	for _, item := range items {
+		if item.verdict.SizeAfter > 0 {
+			pending = append(pending, item)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	response, usage, err := ro.client.CallWithUsage(reqCtx, riskOfficerSystemPrompt, riskReviewPrompt(ctx, pending))
+	if usage.Calls > 0 {
+		review.AIUsage = &logger.AIUsage{
+			Model:            usage.Model,
+			Calls:            usage.Calls,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			CostUSD:          usage.CostUSD,
+		}
+	}
+	var answer struct {
+		Verdicts []struct {
+			Index      int     `json:"index"`
+			Verdict    string  `json:"verdict"`
+			SizeFactor float64 `json:"size_factor"`
+			Reason     string  `json:"reason"`
+		} `json:"verdicts"`
+		Summary string `json:"summary"`
+	}
+	if err == nil {
+		err = parseRiskAnswer(response, &answer)
+	}
+	if err != nil {
+		review.Error = err.Error()
+		ro.log.Warnf("⚠️  Risk officer AI review failed: %v", err)
+		if !ro.config.FailOpen {
+			for _, item := range pending {
+				item.veto("AI risk review failed")
+			}
+		}
+		return
+	}
+
+	review.Summary = answer.Summary
+	for _, v := range answer.Verdicts {
+		if v.Index < 1 || v.Index > len(pending) {
+			continue
+		}
+		item := pending[v.Index-1]
+		reason := "AI: " + v.Verdict
+		if v.Reason != "" {
+			reason = "AI: " + v.Reason
+		}
+		switch strings.ToLower(v.Verdict) {
+		case logger.RiskVeto:
+			item.veto(reason)
+		case logger.RiskDownsize:
+			if v.SizeFactor <= 0 || v.SizeFactor >= 1 {
+				continue
+			}
+			item.resize(item.downsize(item.verdict.SizeAfter, v.SizeFactor, reason), ctx)
+		}
+	}
+}
+
+// veto drops the open
+func (item *riskItem) veto(reason string) {
+	item.verdict.SizeAfter = 0
+	item.reasons = append(item.reasons, reason)
+}
+
+// downsize size scaled by factor (1 = unchanged, no reason recorded)
+func (item *riskItem) downsize(size, factor float64, reason string) float64 {
+	if factor >= 1 {
+		return size
+	}
+	item.reasons = append(item.reasons, fmt.Sprintf("%s (x%.2f)", reason, factor))
+	return size * factor
+}
+
+// resize sets the margin to execute, vetoing the open when it falls below the minimum margin validation accepts
+// (an add only needs an executable margin)
+func (item *riskItem) resize(size float64, ctx *decisionPkg.Context) {
+	minMargin := ctx.MinOpenMargin(item.decision.Symbol)
+	if decisionPkg.IsAddAction(item.decision.Action) {
+		minMargin = minExecutableMargin
+	}
+	if size < item.verdict.SizeAfter && size < minMargin {
+		item.veto(fmt.Sprintf("downsized to %.2f USDT, below the minimum margin", size))
+		return
+	}
+	item.verdict.SizeAfter = size
+}
+
+// heldMargin margin of the open position on symbol and side (0 = none)
+func heldMargin(ctx *decisionPkg.Context, symbol, side string) float64 {
+	for _, pos := range ctx.Positions {
+		if pos.Symbol == symbol && pos.Side == side {
+			return pos.MarginUsed
+		}
+	}
+	return 0
+}
+
+// riskReviewPrompt account state, market regime, open positions and the opens to review
+func riskReviewPrompt(ctx *decisionPkg.Context, items []*riskItem) string {
+	var sb strings.Builder
+	a := ctx.Account
+	sb.WriteString(fmt.Sprintf("## Account\nEquity %.2f USDT | Available %.2f | Margin used %.2f (%.1f%%) | Total P&L %+.2f%% | %d positions\n\n",
+		a.TotalEquity, a.AvailableBalance, a.MarginUsed, a.MarginUsedPct, a.TotalPnLPct, a.PositionCount))
+
+	sb.WriteString("## Market regime\n")
+	if regime, downtrend := decisionPkg.BTCRegime(ctx); regime != "" {
+		btc := ctx.MarketDataMap["BTCUSDT"]
+		sb.WriteString(fmt.Sprintf("BTC %s (1h %+.2f%%, 4h %+.2f%%)", regime, btc.PriceChange1h, btc.PriceChange4h))
+		if downtrend {
+			sb.WriteString(", 4h downtrend")
+		}
+		sb.WriteString("\n\n")
+	} else {
+		sb.WriteString("BTC data unavailable\n\n")
+	}
+
+	sb.WriteString("## Open positions\n")
+	if len(ctx.Positions) == 0 {
+		sb.WriteString("None\n")
+	}
+	for _, p := range ctx.Positions {
+		sb.WriteString(fmt.Sprintf("- %s %s %dx | margin %.2f | P&L %+.2f%%\n", p.Symbol, p.Side, p.Leverage, p.MarginUsed, p.UnrealizedPnLPct))
+	}
+
+	sb.WriteString("\n## Opens to review\n")
+	for i, item := range items {
+		d := item.decision
+		sb.WriteString(fmt.Sprintf("%d. %s %s %dx | margin %.2f USDT | SL %.4f | TP %.4f | confidence %d\n   Reasoning: %s\n",
+			i+1, d.Symbol, d.Action, d.Leverage, item.verdict.SizeAfter, d.StopLoss, d.TakeProfit, d.Confidence,
+			strings.Join(strings.Fields(d.Reasoning), " ")))
+		if len(item.reasons) > 0 {
+			sb.WriteString(fmt.Sprintf("   Already downsized by rules: %s\n", strings.Join(item.reasons, "; ")))
+		}
+	}
+	return sb.String()
+}
+
+// parseRiskAnswer decodes the JSON object in the reviewer's response
+func parseRiskAnswer(response string, answer interface{}) error {
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in risk review response")
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), answer); err != nil {
+		return fmt.Errorf("failed to parse risk review response: %w", err)
+	}
+	return nil
+}
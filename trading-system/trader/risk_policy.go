@@ -0,0 +1,26 @@
+package trader
+
+import (
+	"lia/config"
+	decisionPkg "lia/decision"
+)
+
+// NewRiskPolicy the decision risk policy of a trader's risk_policy config (nil = the built-in policy)
+func NewRiskPolicy(cfg *config.RiskPolicyConfig) *decisionPkg.RiskPolicy {
+	if cfg == nil {
+		return decisionPkg.DefaultRiskPolicy()
+	}
+	return &decisionPkg.RiskPolicy{
+		Name:                cfg.Name,
+		MinRiskReward:       cfg.MinRiskReward,
+		MaxRiskPerTradePct:  cfg.MaxRiskPerTradePct,
+		MinMarginPctBTCETH:  cfg.MinMarginPctBTCETH,
+		MinMarginPctAltcoin: cfg.MinMarginPctAltcoin,
+		MaxMarginPctBTCETH:  cfg.MaxMarginPctBTCETH,
+		MaxMarginPctAltcoin: cfg.MaxMarginPctAltcoin,
+		MaxStopPctBTCETH:    cfg.MaxStopPctBTCETH,
+		MaxStopPctAltcoin:   cfg.MaxStopPctAltcoin,
+		MaxPositions:        cfg.MaxPositions,
+		MaxAddMarginPct:     cfg.MaxAddMarginPct,
+	}
+}
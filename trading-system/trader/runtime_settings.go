@@ -0,0 +1,71 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuntimeSettings trader settings that can change while the trader runs (config hot reload)
+type RuntimeSettings struct {
+	ScanInterval      time.Duration
+	BTCETHLeverage    int
+	AltcoinLeverage   int
+	AutoTakeProfitPct float64 // Paper trading only (0 = disabled)
+}
+
+// RuntimeSettings the trader's current hot-reloadable settings
+func (at *AutoTrader) RuntimeSettings() RuntimeSettings {
+	at.settingsMu.RLock()
+	defer at.settingsMu.RUnlock()
+	return RuntimeSettings{
+		ScanInterval:      at.config.ScanInterval,
+		BTCETHLeverage:    at.config.BTCETHLeverage,
+		AltcoinLeverage:   at.config.AltcoinLeverage,
+		AutoTakeProfitPct: at.config.AutoTakeProfitPct,
+	}
+}
+
+// ApplyRuntimeSettings applies new settings from the next cycle on (a new scan interval restarts the cycle
+// timer) and returns a description of each change
+func (at *AutoTrader) ApplyRuntimeSettings(s RuntimeSettings) []string {
+	at.settingsMu.Lock()
+	var changes []string
+	if s.ScanInterval > 0 && s.ScanInterval != at.config.ScanInterval {
+		changes = append(changes, fmt.Sprintf("scan interval %v → %v", at.config.ScanInterval, s.ScanInterval))
+		at.config.ScanInterval = s.ScanInterval
+		at.schedule.setInterval(s.ScanInterval)
+	}
+	if s.BTCETHLeverage > 0 && s.BTCETHLeverage != at.config.BTCETHLeverage {
+		changes = append(changes, fmt.Sprintf("BTC/ETH leverage %dx → %dx", at.config.BTCETHLeverage, s.BTCETHLeverage))
+		at.config.BTCETHLeverage = s.BTCETHLeverage
+	}
+	if s.AltcoinLeverage > 0 && s.AltcoinLeverage != at.config.AltcoinLeverage {
+		changes = append(changes, fmt.Sprintf("altcoin leverage %dx → %dx", at.config.AltcoinLeverage, s.AltcoinLeverage))
+		at.config.AltcoinLeverage = s.AltcoinLeverage
+	}
+	if s.AutoTakeProfitPct != at.config.AutoTakeProfitPct {
+		changes = append(changes, fmt.Sprintf("auto take profit %.2f%% → %.2f%%", at.config.AutoTakeProfitPct, s.AutoTakeProfitPct))
+		at.config.AutoTakeProfitPct = s.AutoTakeProfitPct
+	}
+	at.settingsMu.Unlock()
+
+	if len(changes) > 0 {
+		at.log.Infof("🔄 Settings updated: %s", strings.Join(changes, ", "))
+	}
+	return changes
+}
+
+// leverageLimits the configured maximum leverage for BTC/ETH and for altcoins
+func (at *AutoTrader) leverageLimits() (btcEth, altcoin int) {
+	at.settingsMu.RLock()
+	defer at.settingsMu.RUnlock()
+	return at.config.BTCETHLeverage, at.config.AltcoinLeverage
+}
+
+// autoTakeProfitPct the paper auto take profit threshold (0 = disabled)
+func (at *AutoTrader) autoTakeProfitPct() float64 {
+	at.settingsMu.RLock()
+	defer at.settingsMu.RUnlock()
+	return at.config.AutoTakeProfitPct
+}
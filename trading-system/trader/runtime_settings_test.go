@@ -0,0 +1,53 @@
+package trader
+
+import (
+	"testing"
+	"time"
+)
+
+// waitResponse holds through the cycle
+const waitResponse = `Nothing worth trading.
+
+[{"symbol": "BTCUSDT", "action": "wait", "reasoning": "No setup"}]
+`
+
+// TestRunWithConcurrentSettingsReload runs a simulated trader while a config reload changes its settings (run
+// with -race)
+func TestRunWithConcurrentSettingsReload(t *testing.T) {
+	at := newSimulatedTestTrader(t, waitResponse)
+
+	stop := make(chan struct{})
+	reloaded := make(chan int)
+	go func() {
+		count := 0
+		defer func() { reloaded <- count }()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			at.ApplyRuntimeSettings(RuntimeSettings{
+				ScanInterval:      time.Duration(3+count%2) * time.Minute,
+				BTCETHLeverage:    5 + count%3,
+				AltcoinLeverage:   5,
+				AutoTakeProfitPct: float64(count % 2),
+			})
+			count++
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	err := at.Run()
+	close(stop)
+	count := <-reloaded
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if count == 0 {
+		t.Fatal("settings were not reloaded while the trader ran")
+	}
+	if at.callCount == 0 {
+		t.Fatal("no cycle ran")
+	}
+}
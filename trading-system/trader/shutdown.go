@@ -0,0 +1,130 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lia/logger"
+	"sync"
+	"time"
+)
+
+// ErrShutdownDuringCycle the trader was stopped (or the cycle aborted by an emergency flatten) while a cycle was
+// running; the decisions not yet executed were skipped
+var ErrShutdownDuringCycle = errors.New("shutdown during cycle")
+
+// cycleTracker the decision cycle in progress, so shutdown can wait for it to finish
+type cycleTracker struct {
+	mu      sync.Mutex
+	number  int
+	started time.Time
+	done    chan struct{} // Closed when the cycle returns (nil = no cycle running)
+	cancel  context.CancelFunc
+	aborted string // Why the running cycle was aborted ("" = it was not)
+
+	lastSuccess time.Time // End of the latest cycle that completed without error
+}
+
+// begin marks a cycle as running (cancel aborts it)
+func (c *cycleTracker) begin(number int, cancel context.CancelFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.number = number
+	c.started = time.Now()
+	c.done = make(chan struct{})
+	c.cancel = cancel
+	c.aborted = ""
+}
+
+// abort cancels the running cycle's context, so it skips the decisions not yet executed. Returns the channel
+// closed when it has returned, and its number (nil done = no cycle running)
+func (c *cycleTracker) abort(reason string) (done <-chan struct{}, number int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done == nil {
+		return nil, 0
+	}
+	c.aborted = reason
+	c.cancel()
+	return c.done, c.number
+}
+
+// abortReason why the running cycle was aborted ("" = it was not)
+func (c *cycleTracker) abortReason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.aborted
+}
+
+// end marks the running cycle as finished
+func (c *cycleTracker) end() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		close(c.done)
+		c.done = nil
+		c.cancel = nil
+	}
+}
+
+// succeeded records the end of a cycle that completed without error
+func (c *cycleTracker) succeeded() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastSuccess = time.Now()
+}
+
+// lastSucceeded when the latest successful cycle ended (zero = none yet)
+func (c *cycleTracker) lastSucceeded() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastSuccess
+}
+
+// lastStarted when the latest cycle (running or finished) started (zero = none yet)
+func (c *cycleTracker) lastStarted() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.started
+}
+
+// running the cycle in progress (nil done = none)
+func (c *cycleTracker) running() (done <-chan struct{}, number int, started time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done == nil {
+		return nil, 0, time.Time{}
+	}
+	return c.done, c.number, c.started
+}
+
+// Shutdown stops the trader and waits until the cycle in progress (if any) has finished or aborted between
+// orders, or ctx expires. An order already being placed (with its stop loss / take profit) is completed
+func (at *AutoTrader) Shutdown(ctx context.Context) error {
+	at.Stop()
+	done, number, started := at.cycles.running()
+	if done == nil {
+		return nil
+	}
+	at.log.Infof("⏳ Waiting for cycle #%d (running for %v) to finish or abort between orders...",
+		number, time.Since(started).Round(time.Second))
+	select {
+	case <-done:
+		at.log.Infof("✓ Cycle #%d ended, trader stopped", number)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[%s] cycle #%d still running after the shutdown timeout: %w", at.name, number, ctx.Err())
+	}
+}
+
+// recordShutdownDuringCycle marks the cycle's decision record as interrupted by shutdown (or by what aborted it)
+func (at *AutoTrader) recordShutdownDuringCycle(record *logger.DecisionRecord, detail string) {
+	cause := "Shutdown"
+	if reason := at.cycles.abortReason(); reason != "" {
+		cause = reason
+	}
+	at.log.Infof("⏹ %s during cycle #%d: %s", cause, at.callCount, detail)
+	record.Success = false
+	record.ErrorMessage = fmt.Sprintf("%s during cycle: %s", cause, detail)
+	record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("⏹ %s during cycle: %s", cause, detail))
+}
@@ -0,0 +1,313 @@
+package trader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/market"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by SubmitSignal
+var (
+	ErrSignalsDisabled     = errors.New("trader does not accept signals (no signal_webhook)")
+	ErrSignalUnauthorized  = errors.New("invalid signal signature or passphrase")
+	ErrInvalidSignal       = errors.New("invalid signal")
+	ErrSignalQueueFull     = errors.New("too many signals queued")
+	ErrTraderPaused        = errors.New("trader is paused")
+	ErrSignalsInSimulation = errors.New("signals are not supported in simulate mode")
+)
+
+// signalQueueSize signals and manual trades waiting for the trader's loop (each runs as its own cycle)
+const signalQueueSize = 8
+
+// Signal decisions from an external feed or an operator, executed in a cycle of their own
+type Signal struct {
+	ID         string                 `json:"id"`
+	Source     string                 `json:"source"`    // logger.DecisionSourceSignal or logger.DecisionSourceManual
+	Timestamp  time.Time              `json:"timestamp"` // When the feed sent it
+	Comment    string                 `json:"comment,omitempty"`
+	Operator   string                 `json:"operator,omitempty"` // API caller of a manual trade
+	Decisions  []decisionPkg.Decision `json:"decisions"`
+	ReceivedAt time.Time              `json:"received_at"`
+
+	done   chan struct{}          // Closed when its cycle finished (or it was dropped)
+	record *logger.DecisionRecord // The cycle's decision record (nil = dropped before its cycle)
+}
+
+// Wait blocks until the signal's cycle finished and returns its decision record (nil when the signal was
+// dropped, e.g. the trader stopped or was paused first)
+func (s *Signal) Wait(ctx context.Context) (*logger.DecisionRecord, error) {
+	select {
+	case <-s.done:
+		return s.record, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// signalPayload the webhook body: a "decisions" array, or a single decision inline (TradingView alert
+// templates are easier to write flat)
+type signalPayload struct {
+	ID                   string                 `json:"id,omitempty"`
+	Timestamp            json.RawMessage        `json:"timestamp"` // Unix seconds/milliseconds or RFC3339 (TradingView {{timenow}})
+	Passphrase           string                 `json:"passphrase,omitempty"`
+	Comment              string                 `json:"comment,omitempty"`
+	Decisions            []decisionPkg.Decision `json:"decisions,omitempty"`
+	decisionPkg.Decision                        // Single inline decision
+}
+
+// signalFeed the queue of accepted signals and manual trades, and the signal IDs seen within the replay window
+type signalFeed struct {
+	mu      sync.Mutex
+	seen    map[string]time.Time // Signal ID -> received (pruned after two max ages)
+	queue   chan *Signal
+	current *Signal // Signal of the cycle in progress (read and written by the trader's loop only)
+}
+
+// newSignalFeed creates the queue
+func newSignalFeed() *signalFeed {
+	return &signalFeed{seen: make(map[string]time.Time), queue: make(chan *Signal, signalQueueSize)}
+}
+
+// SubmitSignal authenticates a webhook body (signature = the X-Signature header) and queues its decisions for
+// the trader's loop. They are validated against the trader's risk policy when their cycle runs
+func (at *AutoTrader) SubmitSignal(body []byte, signature string) (*Signal, error) {
+	cfg := at.config.SignalWebhook
+	if cfg == nil || at.signals == nil {
+		return nil, ErrSignalsDisabled
+	}
+
+	var payload signalPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignal, err)
+	}
+	if !verifySignal(cfg.Secret, body, signature, payload.Passphrase, cfg.AllowPassphrase) {
+		return nil, ErrSignalUnauthorized
+	}
+
+	sent, err := parseSignalTime(payload.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignal, err)
+	}
+	now := time.Now()
+	maxAge := time.Duration(cfg.MaxAgeSeconds) * time.Second
+	if skew := now.Sub(sent); skew > maxAge || skew < -maxAge {
+		return nil, fmt.Errorf("%w: timestamp %s is more than %ds from now", ErrInvalidSignal, sent.UTC().Format(time.RFC3339), cfg.MaxAgeSeconds)
+	}
+
+	decisions := payload.Decisions
+	if len(decisions) == 0 && payload.Symbol != "" {
+		decisions = []decisionPkg.Decision{payload.Decision}
+	}
+	if len(decisions) == 0 {
+		return nil, fmt.Errorf("%w: no decisions", ErrInvalidSignal)
+	}
+	for i := range decisions {
+		decisions[i].Symbol = strings.ToUpper(strings.TrimSpace(decisions[i].Symbol))
+		decisions[i].Action = strings.ToLower(strings.TrimSpace(decisions[i].Action))
+		if decisions[i].Symbol == "" || decisions[i].Action == "" {
+			return nil, fmt.Errorf("%w: decision #%d needs a symbol and an action", ErrInvalidSignal, i+1)
+		}
+		if decisions[i].Reasoning == "" {
+			decisions[i].Reasoning = "External signal"
+			if payload.Comment != "" {
+				decisions[i].Reasoning = "External signal: " + payload.Comment
+			}
+		}
+	}
+
+	id := payload.ID
+	if id == "" {
+		sum := sha256.Sum256(body)
+		id = hex.EncodeToString(sum[:8])
+	}
+	signal := newSignal(id, logger.DecisionSourceSignal, decisions)
+	signal.Timestamp = sent
+	signal.Comment = payload.Comment
+
+	if paused, _ := at.IsPaused(); paused {
+		return nil, ErrTraderPaused
+	}
+	if !at.signals.markSeen(id, now, maxAge) {
+		return nil, fmt.Errorf("%w: signal %s was already received", ErrInvalidSignal, id)
+	}
+	if err := at.queueSignal(signal); err != nil {
+		at.signals.forget(id) // A retry of a signal that was not queued is not a replay
+		return nil, err
+	}
+	return signal, nil
+}
+
+// newSignal a signal received now
+func newSignal(id, source string, decisions []decisionPkg.Decision) *Signal {
+	now := time.Now()
+	return &Signal{ID: id, Source: source, Timestamp: now, Decisions: decisions, ReceivedAt: now, done: make(chan struct{})}
+}
+
+// queueSignal hands a signal to the trader's loop
+func (at *AutoTrader) queueSignal(signal *Signal) error {
+	if at.signals == nil || !at.isRunning {
+		return ErrTraderNotRunning
+	}
+	if at.sim != nil {
+		return ErrSignalsInSimulation
+	}
+	select {
+	case at.signals.queue <- signal:
+		at.log.Infof("📡 %s %s queued: %d decision(s)", signal.Source, signal.ID, len(signal.Decisions))
+		return nil
+	default:
+		return ErrSignalQueueFull
+	}
+}
+
+// markSeen records a signal ID (false when it was received within the replay window already)
+func (f *signalFeed) markSeen(id string, now time.Time, maxAge time.Duration) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for seenID, received := range f.seen {
+		if now.Sub(received) > 2*maxAge {
+			delete(f.seen, seenID)
+		}
+	}
+	if _, ok := f.seen[id]; ok {
+		return false
+	}
+	f.seen[id] = now
+	return true
+}
+
+// forget drops a signal ID from the replay window
+func (f *signalFeed) forget(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.seen, id)
+}
+
+// verifySignal checks the hex HMAC-SHA256 signature of the body (optionally "sha256=" prefixed) or, when
+// allowed, the payload's passphrase (compared over hashes so neither content nor length leaks)
+func verifySignal(secret string, body []byte, signature, passphrase string, allowPassphrase bool) bool {
+	if signature != "" {
+		got, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	if allowPassphrase && passphrase != "" {
+		want := sha256.Sum256([]byte(secret))
+		got := sha256.Sum256([]byte(passphrase))
+		return subtle.ConstantTimeCompare(want[:], got[:]) == 1
+	}
+	return false
+}
+
+// parseSignalTime a payload timestamp: Unix seconds or milliseconds (number or string), or RFC3339
+func parseSignalTime(raw json.RawMessage) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, fmt.Errorf("missing timestamp")
+	}
+	s := strings.Trim(string(raw), `"`)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(int64(n)), nil
+		}
+		return time.Unix(int64(n), 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp '%s' (Unix time or RFC3339)", s)
+	}
+	return t, nil
+}
+
+// signalQueue the accepted signals (nil without a webhook, so the trader's loop never selects it)
+func (at *AutoTrader) signalQueue() <-chan *Signal {
+	if at.signals == nil {
+		return nil
+	}
+	return at.signals.queue
+}
+
+// currentSignal the signal the cycle in progress executes (nil = a scheduled or manual cycle)
+func (at *AutoTrader) currentSignal() *Signal {
+	if at.signals == nil {
+		return nil
+	}
+	return at.signals.current
+}
+
+// runSignalCycle runs a cycle that executes a signal instead of asking the AI/strategy. Manual trades run
+// even while the trader is paused, like manual cycles
+func (at *AutoTrader) runSignalCycle(signal *Signal) {
+	defer close(signal.done)
+	if paused, _ := at.IsPaused(); paused && signal.Source != logger.DecisionSourceManual {
+		at.log.Infof("⏸ Paused by operator, dropping signal %s", signal.ID)
+		return
+	}
+	at.log.Infof("📡 Signal cycle starting (%s %s, queued %v ago)...", signal.Source, signal.ID, time.Since(signal.ReceivedAt).Round(time.Millisecond))
+	at.signals.current = signal
+	defer func() { at.signals.current = nil }()
+	if err := at.runCycle(at.runCtx); err != nil {
+		at.log.Errorf("❌ Signal cycle failed: %v", err)
+	} else {
+		at.log.Infof("✅ Signal cycle completed, waiting for next cycle")
+	}
+}
+
+// signalDecision the signal's decisions after the same validation as AI decisions (risk policy, leverage
+// limits, confidence threshold)
+func (at *AutoTrader) signalDecision(ctx *decisionPkg.Context, signal *Signal) (*decisionPkg.FullDecision, error) {
+	if err := decisionPkg.PrepareMarketData(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load market data: %w", err)
+	}
+	// Signals may trade coins outside the candidate pool
+	for _, d := range signal.Decisions {
+		if _, ok := ctx.MarketDataMap[d.Symbol]; !ok {
+			if data, err := market.Get(d.Symbol); err == nil {
+				ctx.MarketDataMap[d.Symbol] = data
+			}
+		}
+	}
+
+	decisions := append([]decisionPkg.Decision(nil), signal.Decisions...)
+	reasoning := fmt.Sprintf("📡 External signal %s (sent %s)", signal.ID, signal.Timestamp.UTC().Format(time.RFC3339))
+	prompt := fmt.Sprintf("External signal %s", signal.ID)
+	if signal.Source == logger.DecisionSourceManual {
+		reasoning = fmt.Sprintf("🧑 Manual trade %s by %s", signal.ID, signal.Operator)
+		prompt = fmt.Sprintf("Manual trade %s by %s", signal.ID, signal.Operator)
+	}
+	if signal.Comment != "" {
+		reasoning += "\n" + signal.Comment
+	}
+	decision := decisionPkg.FinalizeDecisions(ctx, decisions, reasoning)
+	decision.UserPrompt = prompt
+	raw, _ := json.Marshal(signal.Decisions)
+	decision.RawResponse = string(raw)
+	return decision, nil
+}
+
+// signalWaitDecision the decision of a scheduled cycle of a signals-only trader (signal_webhook.exclusive)
+func (at *AutoTrader) signalWaitDecision() *decisionPkg.FullDecision {
+	return &decisionPkg.FullDecision{
+		UserPrompt:  "Signals only: no signal this cycle",
+		CoTTrace:    "📡 signal_webhook.exclusive: decisions come from the signal webhook only",
+		Decisions:   []decisionPkg.Decision{{Symbol: "ALL", Action: "wait", Reasoning: "Waiting for the next signal"}},
+		RawResponse: "No signal",
+		Timestamp:   at.now(),
+	}
+}
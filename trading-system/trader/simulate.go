@@ -0,0 +1,133 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/logging"
+	"lia/mcp"
+	"lia/pool"
+	"lia/sim"
+	"math/rand"
+	"time"
+)
+
+// simulationPoolSource candidate source of the simulated symbols (in place of "ai500"/"oi_top")
+const simulationPoolSource = "simulation"
+
+// newSimulatedTrader sets up the simulate exchange mode: the simulation's price paths and clock replace Binance
+// and the wall clock, AI calls return the scripted responses and a fresh paper trader (nothing is restored from
+// earlier runs) logs to the simulation's log directory
+func newSimulatedTrader(config AutoTraderConfig, mcpClient *mcp.Client, strategy decisionPkg.Strategy) (*sim.Simulation, *PaperTrader, *logger.DecisionLogger, error) {
+	if config.Simulation == nil {
+		return nil, nil, nil, fmt.Errorf("simulate exchange mode needs a simulation config")
+	}
+	simulation, err := sim.New(*config.Simulation)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to build the simulation: %w", err)
+	}
+	if strategy.Name() == decisionPkg.StrategyAI && len(simulation.Responses) == 0 {
+		return nil, nil, nil, fmt.Errorf("simulate mode with the AI strategy needs simulation.ai_responses_dir")
+	}
+	// Every strategy gets the scripted client, so none can reach a real AI provider
+	mcpClient.SetScriptedResponses(simulation.Responses)
+	simulation.Install()
+
+	logging.ForTrader(config.ID, config.Name).Infof("🧪 Using simulate mode (%d scripted AI responses)", len(simulation.Responses))
+	paperTrader := NewPaperTrader(config.InitialBalance)
+	paperTrader.UseSimulatedClock(simulation.Clock.Now, simulation.Seed)
+	applyPaperSettings(paperTrader, config)
+	return simulation, paperTrader, logger.NewDecisionLogger(simulation.LogDir), nil
+}
+
+// UseSimulatedClock makes the paper trader read time from now and draw its randomness from seed, so a simulated
+// run fills, charges and recalls the same way every time
+func (t *PaperTrader) UseSimulatedClock(now func() time.Time, seed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = now
+	t.rng = rand.New(rand.NewSource(seed))
+}
+
+// now the paper trader's time (the simulated clock in simulate mode)
+func (t *PaperTrader) now() time.Time {
+	if t.clock != nil {
+		return t.clock()
+	}
+	return time.Now()
+}
+
+// now the trader's time: the simulated clock in simulate mode, else the wall clock
+func (at *AutoTrader) now() time.Time {
+	if at.sim != nil {
+		return at.sim.Clock.Now()
+	}
+	return time.Now()
+}
+
+// GetSimulation the simulation driving this trader (nil unless the exchange is "simulate")
+func (at *AutoTrader) GetSimulation() *sim.Simulation {
+	return at.sim
+}
+
+// simulatedCoinPool the candidate pool of a simulation: every simulated symbol
+func (at *AutoTrader) simulatedCoinPool() *pool.MergedCoinPool {
+	symbols := at.sim.Feed.Symbols()
+	merged := &pool.MergedCoinPool{
+		AllSymbols:    symbols,
+		SymbolSources: make(map[string][]string, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		merged.SymbolSources[symbol] = []string{simulationPoolSource}
+	}
+	return merged
+}
+
+// Step runs one simulated cycle: advances the clock by the scan interval (except before the first cycle), runs
+// the position monitor's checks once, then the decision cycle. Lets tests drive runCycle deterministically
+func (at *AutoTrader) Step() error {
+	if at.sim == nil {
+		return fmt.Errorf("step is only available in simulate exchange mode (exchange: %s)", at.exchange)
+	}
+	if at.callCount > 0 {
+		at.sim.Clock.Advance(at.RuntimeSettings().ScanInterval)
+	}
+
+	at.enforcePaperStops()
+	at.enforcePaperBuyIns()
+	at.accruePaperFunding()
+	if at.positionMonitorNeeded() {
+		at.checkAndCloseProfitablePositions()
+	}
+	cycleCtx := at.runCtx
+	if cycleCtx == nil {
+		cycleCtx = context.Background() // Stepped by tests without Run
+	}
+	return at.runCycle(cycleCtx)
+}
+
+// runSimulation steps through the price paths until they end, the trader completes or it is stopped (in place of
+// the ticker loop and the background monitor), then restores live market data
+func (at *AutoTrader) runSimulation() error {
+	defer at.sim.Uninstall()
+	at.log.Infof("🧪 Simulating until %s (a cycle every %v of simulated time)",
+		at.sim.Feed.End().Format(time.RFC3339), at.RuntimeSettings().ScanInterval)
+
+	started := time.Now()
+	for at.isRunning && !at.sim.Finished() && !at.IsCompleted() {
+		if err := at.Step(); err != nil {
+			at.log.Errorf("❌ Simulated cycle #%d failed: %v", at.callCount, err)
+		}
+	}
+	at.isRunning = false
+
+	equity := at.initialBalance
+	if balance, err := at.trader.GetBalance(); err == nil {
+		equity = balance.Equity()
+	}
+	at.log.Infof("🧪 Simulation finished at %s: %d cycles in %v, equity %.2f USDT (%+.2f), logs in %s",
+		at.sim.Clock.Now().Format(time.RFC3339), at.callCount, time.Since(started).Round(time.Millisecond),
+		equity, equity-at.initialBalance, at.sim.LogDir)
+	return nil
+}
@@ -0,0 +1,119 @@
+package trader
+
+import (
+	"io"
+	"lia/config"
+	"lia/logging"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetOutput(io.Discard) // Cycles log every step
+	os.Exit(m.Run())
+}
+
+// newSimulatedTestTrader a trader in simulate mode on synthetic BTCUSDT (42000) and ETHUSDT (2300) paths whose AI
+// answers with responses in turn. Its logs and state go to a test directory
+func newSimulatedTestTrader(t *testing.T, responses ...string) *AutoTrader {
+	t.Helper()
+	dir := t.TempDir()
+	responsesDir := filepath.Join(dir, "ai_responses")
+	if err := os.MkdirAll(responsesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i, response := range responses {
+		name := filepath.Join(responsesDir, string(rune('a'+i))+".txt")
+		if err := os.WriteFile(name, []byte(response), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	at, err := NewAutoTrader(AutoTraderConfig{
+		ID:              "sim_test",
+		Name:            "Sim Test",
+		Exchange:        "simulate",
+		InitialBalance:  10000,
+		ScanInterval:    3 * time.Minute,
+		BTCETHLeverage:  5,
+		AltcoinLeverage: 5,
+		Simulation: &config.SimulationConfig{
+			Seed:           42,
+			Symbols:        []string{"BTCUSDT", "ETHUSDT"},
+			DurationHours:  2,
+			StartPrices:    map[string]float64{"BTCUSDT": 42000, "ETHUSDT": 2300},
+			AIResponsesDir: responsesDir,
+			LogDir:         filepath.Join(dir, "logs"),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(at.sim.Uninstall)
+	return at
+}
+
+func TestSimulatedCycleOpensPositionsAndLogsDecision(t *testing.T) {
+	at := newSimulatedTestTrader(t, openBothResponse)
+	if err := at.Step(); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+
+	records, err := at.decisionLogger.GetLatestRecords(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("%d decision records, want 1", len(records))
+	}
+	record := records[0]
+	if record.CycleNumber != 1 || !record.Success {
+		t.Errorf("record = cycle %d, success %v (%s), want a successful cycle 1", record.CycleNumber, record.Success, record.ErrorMessage)
+	}
+	opened := make(map[string]float64)
+	for _, action := range record.Decisions {
+		if action.Action != "open_long" || !action.Success || action.Price <= 0 {
+			t.Errorf("action = %+v, want a filled open_long", action)
+			continue
+		}
+		opened[action.Symbol] = action.Price
+	}
+	if len(opened) != 2 {
+		t.Fatalf("opened %v, want BTCUSDT and ETHUSDT", opened)
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("positions = %+v, want the 2 longs", positions)
+	}
+	for _, pos := range positions {
+		price, ok := opened[pos.Symbol]
+		if !ok || pos.Side != "long" || pos.EntryPrice != price {
+			t.Errorf("position = %+v, want a long entered at the logged price %v", pos, price)
+		}
+		// position_size_usd is the margin: 2000 USD at 5x
+		if notional := pos.Quantity * pos.EntryPrice; notional < 9500 || notional > 10500 {
+			t.Errorf("%s notional = %.2f, want about 10000 USD", pos.Symbol, notional)
+		}
+	}
+
+	// The same seed replays the same prices
+	again := newSimulatedTestTrader(t, openBothResponse)
+	if err := again.Step(); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	replayed, err := again.trader.GetPositions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pos := range replayed {
+		if pos.EntryPrice != opened[pos.Symbol] {
+			t.Errorf("%s replay entered at %v, want %v", pos.Symbol, pos.EntryPrice, opened[pos.Symbol])
+		}
+	}
+}
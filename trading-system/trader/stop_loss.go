@@ -0,0 +1,61 @@
+package trader
+
+import (
+	"lia/config"
+	decisionPkg "lia/decision"
+	"strings"
+)
+
+// honorsStops whether opens place stop loss orders (stop_loss_mode "honor_stops"); otherwise stops are for risk
+// planning only and losing positions are held until profitable
+func (at *AutoTrader) honorsStops() bool {
+	return at.config.StopLossMode == config.StopLossHonorStops
+}
+
+// stopOrderPrice the stop loss to place with an open (0 = no stop order)
+func (at *AutoTrader) stopOrderPrice(decision *decisionPkg.Decision) float64 {
+	if !at.honorsStops() {
+		return 0
+	}
+	return decision.StopLoss
+}
+
+// enforcePaperStops closes paper positions whose simulated stop loss was crossed (exchanges execute real stop
+// orders themselves)
+func (at *AutoTrader) enforcePaperStops() {
+	if !at.honorsStops() {
+		return
+	}
+	paperTrader, ok := asPaperTrader(at.trader)
+	if !ok {
+		return
+	}
+
+	for _, stop := range paperTrader.TriggeredStops() {
+		lock := getPositionLock(stop.Symbol, strings.ToUpper(stop.Side))
+		lock.Lock()
+		var err error
+		if stop.Side == "long" {
+			_, err = at.trader.CloseLong(stop.Symbol, 0)
+		} else {
+			_, err = at.trader.CloseShort(stop.Symbol, 0)
+		}
+		lock.Unlock()
+
+		if err != nil {
+			at.log.Errorf("❌ [Stop Loss] Failed to close %s %s at stop %.4f: %v",
+				stop.Symbol, strings.ToUpper(stop.Side), stop.StopPrice, err)
+			continue
+		}
+		at.log.Infof("🛑 [Stop Loss] %s %s stopped out (price %.4f crossed stop %.4f)",
+			stop.Symbol, strings.ToUpper(stop.Side), stop.Price, stop.StopPrice)
+	}
+}
+
+// stopLossMode the configured stop loss mode, as reported in GetStatus
+func (at *AutoTrader) stopLossMode() string {
+	if at.honorsStops() {
+		return config.StopLossHonorStops
+	}
+	return config.StopLossNeverCloseLosers
+}
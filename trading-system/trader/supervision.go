@@ -0,0 +1,137 @@
+package trader
+
+import (
+	"sync"
+	"time"
+)
+
+// Trader health states reported in GetStatus
+const (
+	HealthRunning   = "running"
+	HealthPaused    = "paused"    // Running, scheduled cycles skipped by the operator
+	HealthCrashed   = "crashed"   // The loop panicked, a restart is pending
+	HealthStopped   = "stopped"   // Not started yet or stopped
+	HealthCompleted = "completed" // An end condition was met
+)
+
+// Crash restart backoff: the first restart waits crashBackoffBase, each further consecutive crash doubles it up
+// to crashBackoffMax. A trader that ran crashStableAfter without crashing starts over at the base delay
+const (
+	crashBackoffBase = 5 * time.Second
+	crashBackoffMax  = 5 * time.Minute
+	crashStableAfter = 10 * time.Minute
+)
+
+// supervision crash history of the trader's loop, kept by the supervisor that restarts it
+type supervision struct {
+	mu          sync.Mutex
+	crashes     int       // Since startup
+	consecutive int       // Without a stable run in between (sets the backoff)
+	lastCrashAt time.Time // Zero = never crashed
+	lastCrash   string
+	restartAt   time.Time // Pending restart (zero = none)
+	startedAt   time.Time // Last (re)start of the loop
+}
+
+// RecordStart marks the loop as (re)started by its supervisor
+func (at *AutoTrader) RecordStart() {
+	at.supervision.mu.Lock()
+	defer at.supervision.mu.Unlock()
+	at.supervision.startedAt = time.Now()
+	at.supervision.restartAt = time.Time{}
+}
+
+// RecordCrash records a panic of the loop and returns how long the supervisor waits before restarting it
+func (at *AutoTrader) RecordCrash(cause string) time.Duration {
+	at.supervision.mu.Lock()
+	defer at.supervision.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(at.supervision.startedAt) >= crashStableAfter {
+		at.supervision.consecutive = 0
+	}
+	at.supervision.crashes++
+	at.supervision.consecutive++
+	at.supervision.lastCrashAt = now
+	at.supervision.lastCrash = cause
+
+	delay := crashBackoffBase
+	for i := 1; i < at.supervision.consecutive && delay < crashBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > crashBackoffMax {
+		delay = crashBackoffMax
+	}
+	at.supervision.restartAt = now.Add(delay)
+	return delay
+}
+
+// CancelRestart clears a pending restart (the trader was stopped while waiting for it)
+func (at *AutoTrader) CancelRestart() {
+	at.supervision.mu.Lock()
+	defer at.supervision.mu.Unlock()
+	at.supervision.restartAt = time.Time{}
+}
+
+// IsRunning whether the trading loop is running (false once Stop was called)
+func (at *AutoTrader) IsRunning() bool {
+	return at.isRunning
+}
+
+// HealthState the trader's health state (HealthRunning, HealthPaused, ...)
+func (at *AutoTrader) HealthState() string {
+	at.supervision.mu.Lock()
+	crashed := !at.supervision.restartAt.IsZero()
+	at.supervision.mu.Unlock()
+
+	paused, _ := at.IsPaused()
+	switch {
+	case crashed:
+		return HealthCrashed
+	case at.IsCompleted():
+		return HealthCompleted
+	case !at.isRunning:
+		return HealthStopped
+	case paused:
+		return HealthPaused
+	}
+	return HealthRunning
+}
+
+// LastCrash the cause of the latest crash ("" = never crashed)
+func (at *AutoTrader) LastCrash() string {
+	at.supervision.mu.Lock()
+	defer at.supervision.mu.Unlock()
+	return at.supervision.lastCrash
+}
+
+// RunningSince when the loop was last (re)started by its supervisor (zero = not started)
+func (at *AutoTrader) RunningSince() time.Time {
+	at.supervision.mu.Lock()
+	defer at.supervision.mu.Unlock()
+	return at.supervision.startedAt
+}
+
+// Health the trader's health state and crash history, as reported in GetStatus
+func (at *AutoTrader) Health() map[string]interface{} {
+	state := at.HealthState()
+	at.supervision.mu.Lock()
+	defer at.supervision.mu.Unlock()
+
+	health := map[string]interface{}{
+		"state":   state,
+		"crashes": at.supervision.crashes,
+	}
+	if !at.supervision.lastCrashAt.IsZero() {
+		health["last_crash_at"] = at.supervision.lastCrashAt.Format(time.RFC3339)
+		health["last_crash"] = at.supervision.lastCrash
+		health["consecutive_crashes"] = at.supervision.consecutive
+	}
+	if !at.supervision.restartAt.IsZero() {
+		health["restart_at"] = at.supervision.restartAt.Format(time.RFC3339)
+	}
+	if last := at.cycles.lastSucceeded(); !last.IsZero() {
+		health["last_successful_cycle_at"] = last.Format(time.RFC3339)
+	}
+	return health
+}
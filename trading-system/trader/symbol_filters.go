@@ -0,0 +1,213 @@
+package trader
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SymbolFilters an exchange's order filters for one symbol (0 = not enforced)
+type SymbolFilters struct {
+	StepSize    float64 `json:"step_size"`    // Quantity increment
+	MinQty      float64 `json:"min_qty"`      // Smallest order quantity
+	MaxQty      float64 `json:"max_qty"`      // Largest market order quantity
+	TickSize    float64 `json:"tick_size"`    // Price increment (0 = the adapter formats prices itself)
+	MinNotional float64 `json:"min_notional"` // Smallest order value (quantity × price, USDT)
+}
+
+// SymbolFilterProvider optional interface for exchanges that publish per-symbol order filters (served from the
+// adapter's exchange info cache)
+type SymbolFilterProvider interface {
+	// SymbolFilters returns the symbol's quantity, price and order value filters
+	SymbolFilters(symbol string) (SymbolFilters, error)
+}
+
+// symbolNormalizer fits orders to the exchange's symbol filters before they are sent: opens are rounded down to
+// the step size (up by one step when rounding down alone broke the minimum order value), capped at the maximum
+// market quantity and rejected below the minimum quantity or order value; stop and take profit prices are rounded
+// to the tick size. Orders computed as margin × leverage / price would otherwise be rejected by the exchange
+// (LOT_SIZE, MIN_NOTIONAL), mostly on small coins
+type symbolNormalizer struct {
+	exchange Trader // The adapter: filters and the price the order value is checked at
+}
+
+// newSymbolNormalizer creates the normalizer of an exchange adapter (nil when the exchange publishes no filters)
+func newSymbolNormalizer(exchange Trader) *symbolNormalizer {
+	if _, ok := exchange.(SymbolFilterProvider); !ok {
+		return nil
+	}
+	return &symbolNormalizer{exchange: exchange}
+}
+
+// filterTrader fits the opens and protection orders of the trader it wraps to the exchange's symbol filters.
+// It wraps the ledger, so fills are recorded with the quantity actually sent
+type filterTrader struct {
+	Trader
+	filters *symbolNormalizer
+}
+
+// newFilterTrader wraps t with the symbol filters of the exchange adapter (t unchanged when the exchange
+// publishes none)
+func newFilterTrader(t Trader, exchange Trader) Trader {
+	filters := newSymbolNormalizer(exchange)
+	if filters == nil {
+		return t
+	}
+	return &filterTrader{Trader: t, filters: filters}
+}
+
+func (ft *filterTrader) unwrap() Trader {
+	return ft.Trader
+}
+
+// OpenLong opens a long position with the quantity fitted to the symbol's filters
+func (ft *filterTrader) OpenLong(symbol string, quantity float64, leverage int) (*Order, error) {
+	quantity, err := ft.filters.openQuantity(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return ft.Trader.OpenLong(symbol, quantity, leverage)
+}
+
+// OpenShort opens a short position with the quantity fitted to the symbol's filters
+func (ft *filterTrader) OpenShort(symbol string, quantity float64, leverage int) (*Order, error) {
+	quantity, err := ft.filters.openQuantity(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return ft.Trader.OpenShort(symbol, quantity, leverage)
+}
+
+// SetStopLoss places a stop loss order rounded to the symbol's step and tick size
+func (ft *filterTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	return ft.Trader.SetStopLoss(symbol, positionSide, ft.filters.protectionQuantity(symbol, quantity), ft.filters.price(symbol, stopPrice))
+}
+
+// SetTakeProfit places a take profit order rounded to the symbol's step and tick size
+func (ft *filterTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return ft.Trader.SetTakeProfit(symbol, positionSide, ft.filters.protectionQuantity(symbol, quantity), ft.filters.price(symbol, takeProfitPrice))
+}
+
+// OpenLimit places a limit entry with the quantity fitted to the symbol's filters and the price to its tick size
+func (ft *filterTrader) OpenLimit(symbol, positionSide string, quantity float64, leverage int, price float64, postOnly bool) (*Order, error) {
+	lt, err := limitOrdersOf(ft.Trader)
+	if err != nil {
+		return nil, err
+	}
+	quantity, err = ft.filters.openQuantity(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return lt.OpenLimit(symbol, positionSide, quantity, leverage, ft.filters.price(symbol, price), postOnly)
+}
+
+// GetOrder returns the order's status and fills
+func (ft *filterTrader) GetOrder(symbol string, orderID int64) (*Order, error) {
+	lt, err := limitOrdersOf(ft.Trader)
+	if err != nil {
+		return nil, err
+	}
+	return lt.GetOrder(symbol, orderID)
+}
+
+// CancelOrder cancels one resting order
+func (ft *filterTrader) CancelOrder(symbol string, orderID int64) error {
+	lt, err := limitOrdersOf(ft.Trader)
+	if err != nil {
+		return err
+	}
+	return lt.CancelOrder(symbol, orderID)
+}
+
+// filters the symbol's filters (false when they cannot be loaded: the order is sent as is)
+func (n *symbolNormalizer) filters(symbol string) (SymbolFilters, bool) {
+	if n == nil {
+		return SymbolFilters{}, false
+	}
+	f, err := n.exchange.(SymbolFilterProvider).SymbolFilters(symbol)
+	if err != nil {
+		traderLog.Warnf("  ⚠ %s symbol filters unavailable, order sent unnormalized: %v", symbol, err)
+		return SymbolFilters{}, false
+	}
+	return f, true
+}
+
+// openQuantity the quantity an open is sent with, or a min_notional ExchangeError when the order is too small
+// for the exchange
+func (n *symbolNormalizer) openQuantity(symbol string, quantity float64) (float64, error) {
+	f, ok := n.filters(symbol)
+	if !ok {
+		return quantity, nil
+	}
+
+	q := floorToStep(quantity, f.StepSize)
+	if f.MaxQty > 0 && q > f.MaxQty {
+		q = floorToStep(f.MaxQty, f.StepSize)
+	}
+	if q <= 0 || (f.MinQty > 0 && q < f.MinQty) {
+		return 0, tooSmallError(fmt.Errorf("%s quantity %s is below the minimum order quantity %s",
+			symbol, formatFilterValue(quantity), formatFilterValue(f.MinQty)))
+	}
+
+	if f.MinNotional > 0 {
+		price, err := n.exchange.GetMarketPrice(symbol)
+		if err != nil {
+			traderLog.Warnf("  ⚠ %s minimum order value not checked: %v", symbol, err)
+		} else if q*price < f.MinNotional {
+			// Rounding down alone took an order that met the minimum below it: send one step more
+			stepped := ceilToStep(quantity, f.StepSize)
+			if quantity*price < f.MinNotional || (f.MaxQty > 0 && stepped > f.MaxQty) {
+				return 0, tooSmallError(fmt.Errorf("%s order value %.2f USDT is below the minimum %s USDT",
+					symbol, quantity*price, formatFilterValue(f.MinNotional)))
+			}
+			q = stepped
+		}
+	}
+
+	if q != quantity {
+		traderLog.Infof("  📏 %s quantity fitted to symbol filters: %s → %s (step %s)", symbol,
+			formatFilterValue(quantity), formatFilterValue(q), formatFilterValue(f.StepSize))
+	}
+	return q, nil
+}
+
+// protectionQuantity the quantity a stop loss / take profit order is sent with: rounded down to the step size
+// (unchanged if that leaves nothing, e.g. orders that close the whole position)
+func (n *symbolNormalizer) protectionQuantity(symbol string, quantity float64) float64 {
+	f, ok := n.filters(symbol)
+	if !ok {
+		return quantity
+	}
+	if q := floorToStep(quantity, f.StepSize); q > 0 {
+		return q
+	}
+	return quantity
+}
+
+// price a stop / take profit price rounded to the symbol's tick size
+func (n *symbolNormalizer) price(symbol string, price float64) float64 {
+	f, ok := n.filters(symbol)
+	if !ok || f.TickSize <= 0 {
+		return price
+	}
+	if rounded := roundToStep(price, f.TickSize); rounded > 0 {
+		return rounded
+	}
+	return price
+}
+
+// tooSmallError an order the exchange would reject as too small, classified as min_notional
+func tooSmallError(err error) error {
+	return &ExchangeError{Class: ExchangeErrorMinNotional, Err: err}
+}
+
+// formatFilterValue formats a quantity or filter value without trailing zeros
+func formatFilterValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseFilterValue parses an exchange filter string field ("" or malformed = 0, not enforced)
+func parseFilterValue(v interface{}) float64 {
+	s, _ := v.(string)
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
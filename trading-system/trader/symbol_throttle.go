@@ -0,0 +1,151 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	decisionPkg "lia/decision"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSymbolThrottled returned when a symbol already has the maximum combined entries in the current window
+var ErrSymbolThrottled = errors.New("symbol entry throttled")
+
+// symbolEntry a single position opening recorded by the throttle
+type symbolEntry struct {
+	traderID string
+	at       time.Time
+}
+
+// SymbolThrottle limits combined position entries per symbol across all traders sharing an account
+// Owned by the TraderManager and shared by every AutoTrader it manages
+type SymbolThrottle struct {
+	maxEntries int
+	window     time.Duration
+	entries    map[string][]symbolEntry // key: symbol (e.g., "SOLUSDT")
+	mu         sync.Mutex
+}
+
+// NewSymbolThrottle creates a throttle allowing maxEntries openings per symbol within window
+func NewSymbolThrottle(maxEntries int, window time.Duration) *SymbolThrottle {
+	if maxEntries <= 0 {
+		maxEntries = 1
+	}
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	return &SymbolThrottle{
+		maxEntries: maxEntries,
+		window:     window,
+		entries:    make(map[string][]symbolEntry),
+	}
+}
+
+// pruneLocked drops entries older than the window (caller must hold mu)
+func (st *SymbolThrottle) pruneLocked(symbol string, now time.Time) []symbolEntry {
+	recent := st.entries[symbol][:0]
+	for _, e := range st.entries[symbol] {
+		if now.Sub(e.at) < st.window {
+			recent = append(recent, e)
+		}
+	}
+	if len(recent) == 0 {
+		delete(st.entries, symbol)
+		return nil
+	}
+	st.entries[symbol] = recent
+	return recent
+}
+
+// TryAcquire reserves an entry slot for symbol; returns ErrSymbolThrottled if the symbol is saturated
+func (st *SymbolThrottle) TryAcquire(symbol, traderID string) error {
+	symbol = strings.ToUpper(symbol)
+	now := time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	recent := st.pruneLocked(symbol, now)
+	if len(recent) >= st.maxEntries {
+		resetIn := st.window - now.Sub(recent[0].at)
+		return fmt.Errorf("%w: %s has %d/%d entries across traders in the last %v (frees up in %v)",
+			ErrSymbolThrottled, symbol, len(recent), st.maxEntries, st.window, resetIn.Round(time.Second))
+	}
+
+	st.entries[symbol] = append(recent, symbolEntry{traderID: traderID, at: now})
+	return nil
+}
+
+// Release gives back the most recent slot reserved by traderID (used when the order fails)
+func (st *SymbolThrottle) Release(symbol, traderID string) {
+	symbol = strings.ToUpper(symbol)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	entries := st.entries[symbol]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].traderID == traderID {
+			st.entries[symbol] = append(entries[:i], entries[i+1:]...)
+			if len(st.entries[symbol]) == 0 {
+				delete(st.entries, symbol)
+			}
+			return
+		}
+	}
+}
+
+// Snapshot returns throttle state for every symbol with entries in the current window
+func (st *SymbolThrottle) Snapshot() []decisionPkg.SymbolThrottleInfo {
+	now := time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	symbols := make([]string, 0, len(st.entries))
+	for symbol := range st.entries {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var infos []decisionPkg.SymbolThrottleInfo
+	for _, symbol := range symbols {
+		recent := st.pruneLocked(symbol, now)
+		if len(recent) == 0 {
+			continue
+		}
+
+		traderSet := make(map[string]bool)
+		var traders []string
+		for _, e := range recent {
+			if !traderSet[e.traderID] {
+				traderSet[e.traderID] = true
+				traders = append(traders, e.traderID)
+			}
+		}
+
+		saturated := len(recent) >= st.maxEntries
+		resetIn := 0
+		if saturated {
+			resetIn = int((st.window - now.Sub(recent[0].at)).Seconds())
+		}
+
+		infos = append(infos, decisionPkg.SymbolThrottleInfo{
+			Symbol:         symbol,
+			Entries:        len(recent),
+			MaxEntries:     st.maxEntries,
+			WindowMinutes:  int(st.window.Minutes()),
+			Saturated:      saturated,
+			ResetInSeconds: resetIn,
+			Traders:        traders,
+		})
+	}
+	return infos
+}
+
+// SetSymbolThrottle sets the shared cross-trader symbol throttle (nil disables throttling)
+func (at *AutoTrader) SetSymbolThrottle(st *SymbolThrottle) {
+	at.symbolThrottle = st
+}
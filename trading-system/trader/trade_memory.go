@@ -0,0 +1,524 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"lia/config"
+	decisionPkg "lia/decision"
+	"lia/logger"
+	"lia/market"
+	"lia/mcp"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trade memory indexes this trader's closed trades together with the market regime at entry.
+// Each cycle the episodes most similar to the current candidates/positions are retrieved and
+// inserted into the prompt instead of the last 10 trades, so the AI sees precedent that actually
+// applies (same symbol, similar regime) in fewer tokens. Embeddings come from an OpenAI-compatible
+// API when configured; otherwise (or when the API fails) a local hashed embedding of the same
+// features is used, so retrieval keeps working offline.
+
+const (
+	memoryHashDims       = 256                // Local hashed embedding dimensions
+	memorySymbolWeight   = 3.0                // Symbol token weight (same symbol dominates similarity)
+	memoryMaxQueries     = 12                 // Positions + candidates embedded per cycle
+	memoryEntryMatchSpan = 5 * time.Minute    // Max gap between a recorded entry regime and the trade's open time
+	memoryEntryMaxAge    = 7 * 24 * time.Hour // Entry regimes for positions never seen closing are dropped after this
+)
+
+// MemoryRegime market regime captured when a position was opened
+type MemoryRegime struct {
+	PriceVsEMA20  float64 `json:"price_vs_ema20"` // % distance of price from EMA20
+	PriceChange1h float64 `json:"price_change_1h"`
+	PriceChange4h float64 `json:"price_change_4h"`
+	RSI7          float64 `json:"rsi7"`
+	MACD          float64 `json:"macd"`
+	FundingRate   float64 `json:"funding_rate"`
+	ATRPct        float64 `json:"atr_pct"` // 4h ATR14 as % of price
+}
+
+// regimeFromMarket captures the regime features from market data
+func regimeFromMarket(data *market.Data) *MemoryRegime {
+	if data == nil || data.CurrentPrice <= 0 {
+		return nil
+	}
+	regime := &MemoryRegime{
+		PriceChange1h: data.PriceChange1h,
+		PriceChange4h: data.PriceChange4h,
+		RSI7:          data.CurrentRSI7,
+		MACD:          data.CurrentMACD,
+		FundingRate:   data.FundingRate,
+	}
+	if data.CurrentEMA20 > 0 {
+		regime.PriceVsEMA20 = (data.CurrentPrice - data.CurrentEMA20) / data.CurrentEMA20 * 100
+	}
+	if data.LongerTermContext != nil {
+		regime.ATRPct = data.LongerTermContext.ATR14 / data.CurrentPrice * 100
+	}
+	return regime
+}
+
+// labels buckets the regime into discrete features (embedding tokens)
+func (r *MemoryRegime) labels() []string {
+	bucket := func(v, threshold float64, up, down, flat string) string {
+		switch {
+		case v > threshold:
+			return up
+		case v < -threshold:
+			return down
+		}
+		return flat
+	}
+
+	rsi := "rsi_neutral"
+	if r.RSI7 >= 70 {
+		rsi = "rsi_overbought"
+	} else if r.RSI7 > 0 && r.RSI7 <= 30 {
+		rsi = "rsi_oversold"
+	}
+	volatility := "volatility_normal"
+	if r.ATRPct >= 3 {
+		volatility = "volatility_high"
+	} else if r.ATRPct > 0 && r.ATRPct < 1 {
+		volatility = "volatility_low"
+	}
+
+	return []string{
+		bucket(r.PriceVsEMA20, 0.5, "uptrend", "downtrend", "ranging"),
+		bucket(r.PriceChange1h, 1, "1h_rising", "1h_falling", "1h_flat"),
+		bucket(r.PriceChange4h, 3, "4h_rising", "4h_falling", "4h_flat"),
+		rsi,
+		bucket(r.MACD, 0, "macd_positive", "macd_negative", "macd_flat"),
+		bucket(r.FundingRate, 0.0005, "funding_hot", "funding_negative", "funding_neutral"),
+		volatility,
+	}
+}
+
+// describe short human-readable regime summary for the prompt
+func (r *MemoryRegime) describe() string {
+	labels := r.labels()
+	return fmt.Sprintf("%s, 1h %+.2f%%, 4h %+.2f%%, RSI7 %.0f, %s, %s",
+		labels[0], r.PriceChange1h, r.PriceChange4h, r.RSI7, labels[4], labels[6])
+}
+
+// memoryText the text embedded for a symbol in a regime (side and outcome are deliberately left out,
+// so a query about the current setup retrieves both winners and losers, long and short)
+func memoryText(symbol string, regime *MemoryRegime) string {
+	if regime == nil {
+		return "symbol " + symbol + " | regime unknown"
+	}
+	return "symbol " + symbol + " | " + strings.Join(regime.labels(), " ")
+}
+
+// hashEmbedding local embedding: feature hashing of the symbol and regime tokens (L2-normalized)
+func hashEmbedding(symbol string, regime *MemoryRegime) []float64 {
+	vec := make([]float64, memoryHashDims)
+	add := func(token string, weight float64) {
+		h := fnv.New32a()
+		h.Write([]byte(token))
+		sum := h.Sum32()
+		if sum&0x80000000 != 0 {
+			weight = -weight
+		}
+		vec[sum%memoryHashDims] += weight
+	}
+
+	add("sym:"+symbol, memorySymbolWeight)
+	if regime != nil {
+		for _, label := range regime.labels() {
+			add("regime:"+label, 1)
+		}
+	}
+	return normalize(vec)
+}
+
+// normalize scales a vector to unit length
+func normalize(vec []float64) []float64 {
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm == 0 {
+		return vec
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
+
+// cosine similarity of two unit vectors (0 if dimensions differ)
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+// tradeEpisode an indexed closed trade
+type tradeEpisode struct {
+	Key         string        `json:"key"` // symbol_side_closeUnixNano
+	Symbol      string        `json:"symbol"`
+	Side        string        `json:"side"`
+	OpenPrice   float64       `json:"open_price"`
+	ClosePrice  float64       `json:"close_price"`
+	PnL         float64       `json:"pnl"`
+	PnLPct      float64       `json:"pnl_pct"`
+	Duration    string        `json:"duration"`
+	OpenTime    time.Time     `json:"open_time"`
+	CloseTime   time.Time     `json:"close_time"`
+	Regime      *MemoryRegime `json:"regime,omitempty"`
+	Vector      []float64     `json:"vector,omitempty"`       // API embedding
+	VectorModel string        `json:"vector_model,omitempty"` // Model that produced Vector
+}
+
+// entryRegime regime recorded when a position was opened, waiting for the trade to close
+type entryRegime struct {
+	Regime MemoryRegime `json:"regime"`
+	At     time.Time    `json:"at"`
+}
+
+// tradeMemoryFile on-disk format
+type tradeMemoryFile struct {
+	Episodes     []*tradeEpisode         `json:"episodes"`
+	EntryRegimes map[string]*entryRegime `json:"entry_regimes"`
+}
+
+// TradeMemory per-trader embeddings index of closed trades (implements decision.TradeMemory)
+type TradeMemory struct {
+	cfg      config.TradeMemoryConfig
+	path     string
+	embedder *mcp.Client // nil = local hashed embeddings
+
+	mu           sync.Mutex
+	episodes     []*tradeEpisode
+	indexed      map[string]bool
+	entryRegimes map[string]*entryRegime // key: symbol_side
+
+	// Retrieval is cached per cycle (multi-agent mode retrieves once per agent)
+	cacheKey    string
+	cacheResult []decisionPkg.MemoryEpisode
+}
+
+// NewTradeMemory creates a trade memory persisted at path, loading any existing index
+func NewTradeMemory(cfg config.TradeMemoryConfig, path string) *TradeMemory {
+	m := &TradeMemory{
+		cfg:          cfg,
+		path:         path,
+		indexed:      make(map[string]bool),
+		entryRegimes: make(map[string]*entryRegime),
+	}
+	if cfg.EmbeddingAPIURL != "" {
+		m.embedder = mcp.New()
+		m.embedder.SetCustomAPI(cfg.EmbeddingAPIURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel)
+	}
+
+	if err := m.load(); err != nil {
+		traderLog.Warnf("⚠️  Failed to load trade memory (%s): %v - starting with an empty index", path, err)
+	} else if len(m.episodes) > 0 {
+		traderLog.Infof("🧠 Trade memory loaded: %d trades indexed", len(m.episodes))
+	}
+	return m
+}
+
+// RecordEntry remembers the regime a position was opened in (attached to the trade when it closes)
+func (m *TradeMemory) RecordEntry(symbol, side string, data *market.Data) {
+	regime := regimeFromMarket(data)
+	if regime == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entryRegimes[symbol+"_"+side] = &entryRegime{Regime: *regime, At: time.Now()}
+	m.save()
+}
+
+// Index adds closed trades that are not in the index yet; returns how many were added
+func (m *TradeMemory) Index(trades []logger.TradeOutcome) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var added []*tradeEpisode
+	for _, trade := range trades {
+		key := fmt.Sprintf("%s_%s_%d", trade.Symbol, trade.Side, trade.CloseTime.UnixNano())
+		if m.indexed[key] {
+			continue
+		}
+		episode := &tradeEpisode{
+			Key:        key,
+			Symbol:     trade.Symbol,
+			Side:       trade.Side,
+			OpenPrice:  trade.OpenPrice,
+			ClosePrice: trade.ClosePrice,
+			PnL:        trade.PnL,
+			PnLPct:     trade.PnLPct,
+			Duration:   trade.Duration,
+			OpenTime:   trade.OpenTime,
+			CloseTime:  trade.CloseTime,
+		}
+		regimeKey := trade.Symbol + "_" + trade.Side
+		if entry, ok := m.entryRegimes[regimeKey]; ok && absDuration(entry.At.Sub(trade.OpenTime)) <= memoryEntryMatchSpan {
+			regime := entry.Regime
+			episode.Regime = &regime
+			delete(m.entryRegimes, regimeKey)
+		}
+		m.indexed[key] = true
+		added = append(added, episode)
+	}
+
+	// Drop entry regimes whose trade never showed up as closed
+	for key, entry := range m.entryRegimes {
+		if time.Since(entry.At) > memoryEntryMaxAge {
+			delete(m.entryRegimes, key)
+		}
+	}
+
+	if len(added) == 0 {
+		return 0
+	}
+
+	if m.embedder != nil {
+		texts := make([]string, len(added))
+		for i, episode := range added {
+			texts[i] = memoryText(episode.Symbol, episode.Regime)
+		}
+		vectors, err := m.embedder.Embed(m.cfg.EmbeddingModel, texts)
+		if err != nil {
+			traderLog.Warnf("⚠️  Trade memory embedding failed: %v - local embeddings will be used for retrieval", err)
+		} else {
+			for i, episode := range added {
+				episode.Vector = normalize(vectors[i])
+				episode.VectorModel = m.cfg.EmbeddingModel
+			}
+		}
+	}
+
+	m.episodes = append(m.episodes, added...)
+	sort.SliceStable(m.episodes, func(i, j int) bool {
+		return m.episodes[i].CloseTime.Before(m.episodes[j].CloseTime)
+	})
+	if excess := len(m.episodes) - m.cfg.MaxEpisodes; excess > 0 {
+		for _, episode := range m.episodes[:excess] {
+			delete(m.indexed, episode.Key)
+		}
+		m.episodes = append([]*tradeEpisode(nil), m.episodes[excess:]...)
+	}
+	m.cacheKey = ""
+	m.save()
+	return len(added)
+}
+
+// memoryQuery a current setup to find precedent for
+type memoryQuery struct {
+	label  string // e.g. "BTCUSDT position"
+	symbol string
+	regime *MemoryRegime
+}
+
+// Retrieve returns the indexed trades most similar to this cycle's positions and candidates
+func (m *TradeMemory) Retrieve(ctx *decisionPkg.Context) ([]decisionPkg.MemoryEpisode, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.episodes) == 0 {
+		return nil, 0
+	}
+	cacheKey := fmt.Sprintf("%s#%d", ctx.CurrentTime, ctx.CallCount)
+	if cacheKey == m.cacheKey {
+		return m.cacheResult, len(m.episodes)
+	}
+
+	queries := memoryQueries(ctx)
+	if len(queries) == 0 {
+		return nil, len(m.episodes)
+	}
+
+	queryVectors, episodeVectors := m.vectors(queries)
+
+	type scored struct {
+		episode    *tradeEpisode
+		matchedFor string
+		similarity float64
+	}
+	var matches []scored
+	for i, episode := range m.episodes {
+		best := scored{episode: episode}
+		for j, query := range queries {
+			if sim := cosine(episodeVectors[i], queryVectors[j]); sim > best.similarity {
+				best.similarity = sim
+				best.matchedFor = query.label
+			}
+		}
+		if best.similarity >= m.cfg.MinSimilarity {
+			matches = append(matches, best)
+		}
+	}
+
+	// Most similar first, most recent first among equals
+	sort.SliceStable(matches, func(i, j int) bool {
+		if math.Abs(matches[i].similarity-matches[j].similarity) > 1e-9 {
+			return matches[i].similarity > matches[j].similarity
+		}
+		return matches[i].episode.CloseTime.After(matches[j].episode.CloseTime)
+	})
+	if len(matches) > m.cfg.TopK {
+		matches = matches[:m.cfg.TopK]
+	}
+
+	result := make([]decisionPkg.MemoryEpisode, 0, len(matches))
+	for _, match := range matches {
+		episode := match.episode
+		regime := ""
+		if episode.Regime != nil {
+			regime = episode.Regime.describe()
+		}
+		result = append(result, decisionPkg.MemoryEpisode{
+			Symbol:     episode.Symbol,
+			Side:       episode.Side,
+			Regime:     regime,
+			OpenPrice:  episode.OpenPrice,
+			ClosePrice: episode.ClosePrice,
+			PnL:        episode.PnL,
+			PnLPct:     episode.PnLPct,
+			Duration:   episode.Duration,
+			ClosedAt:   episode.CloseTime.Format("2006-01-02 15:04"),
+			MatchedFor: match.matchedFor,
+			Similarity: math.Round(match.similarity*100) / 100,
+		})
+	}
+
+	m.cacheKey = cacheKey
+	m.cacheResult = result
+	return result, len(m.episodes)
+}
+
+// memoryQueries builds one query per open position, then per candidate (positions take priority)
+func memoryQueries(ctx *decisionPkg.Context) []memoryQuery {
+	var queries []memoryQuery
+	seen := make(map[string]bool)
+	add := func(symbol, label string) {
+		if seen[symbol] || len(queries) >= memoryMaxQueries {
+			return
+		}
+		regime := regimeFromMarket(ctx.MarketDataMap[symbol])
+		if regime == nil {
+			return
+		}
+		seen[symbol] = true
+		queries = append(queries, memoryQuery{label: label, symbol: symbol, regime: regime})
+	}
+
+	for _, pos := range ctx.Positions {
+		add(pos.Symbol, pos.Symbol+" position")
+	}
+	for _, coin := range ctx.CandidateCoins {
+		add(coin.Symbol, coin.Symbol+" setup")
+	}
+	return queries
+}
+
+// vectors embeds the queries and returns them with the episode vectors from the same embedding space
+// (API embeddings when every episode has one from the configured model, local hashed embeddings otherwise)
+func (m *TradeMemory) vectors(queries []memoryQuery) (queryVectors, episodeVectors [][]float64) {
+	if m.embedder != nil && m.allEmbedded() {
+		texts := make([]string, len(queries))
+		for i, query := range queries {
+			texts[i] = memoryText(query.symbol, query.regime)
+		}
+		vectors, err := m.embedder.Embed(m.cfg.EmbeddingModel, texts)
+		if err == nil {
+			for _, vec := range vectors {
+				queryVectors = append(queryVectors, normalize(vec))
+			}
+			for _, episode := range m.episodes {
+				episodeVectors = append(episodeVectors, episode.Vector)
+			}
+			return queryVectors, episodeVectors
+		}
+		traderLog.Warnf("⚠️  Trade memory query embedding failed: %v - using local embeddings this cycle", err)
+	}
+
+	for _, query := range queries {
+		queryVectors = append(queryVectors, hashEmbedding(query.symbol, query.regime))
+	}
+	for _, episode := range m.episodes {
+		episodeVectors = append(episodeVectors, hashEmbedding(episode.Symbol, episode.Regime))
+	}
+	return queryVectors, episodeVectors
+}
+
+// allEmbedded whether every episode has an API embedding from the configured model
+func (m *TradeMemory) allEmbedded() bool {
+	for _, episode := range m.episodes {
+		if len(episode.Vector) == 0 || episode.VectorModel != m.cfg.EmbeddingModel {
+			return false
+		}
+	}
+	return true
+}
+
+// load reads the persisted index (a missing file is not an error)
+func (m *TradeMemory) load() error {
+	data, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file tradeMemoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse trade memory: %w", err)
+	}
+	m.episodes = file.Episodes
+	for _, episode := range m.episodes {
+		m.indexed[episode.Key] = true
+	}
+	if file.EntryRegimes != nil {
+		m.entryRegimes = file.EntryRegimes
+	}
+	return nil
+}
+
+// save persists the index (caller holds mu); failures are logged, the in-memory index stays usable
+func (m *TradeMemory) save() {
+	data, err := json.Marshal(tradeMemoryFile{Episodes: m.episodes, EntryRegimes: m.entryRegimes})
+	if err != nil {
+		traderLog.Warnf("⚠️  Failed to serialize trade memory: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		traderLog.Warnf("⚠️  Failed to create trade memory directory: %v", err)
+		return
+	}
+	tmpPath := m.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		traderLog.Warnf("⚠️  Failed to write trade memory: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, m.path); err != nil {
+		traderLog.Warnf("⚠️  Failed to replace trade memory file: %v", err)
+	}
+}
+
+// absDuration absolute value of a duration
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,41 @@
+package trader
+
+import (
+	"fmt"
+)
+
+// CacheWarmer optional interface for exchanges that can preload metadata (precision, leverage
+// brackets) and account caches before the first trading cycle
+type CacheWarmer interface {
+	WarmUp() error
+}
+
+// candidateLimit number of AI500 coins pulled into the candidate pool each cycle
+func (at *AutoTrader) candidateLimit() int {
+	if at.config.AI500Limit > 0 {
+		return at.config.AI500Limit
+	}
+	return 20 // AI500 takes top 20 highest-scored coins (candidate_pool.ai500_limit overrides)
+}
+
+// WarmUp preloads the exchange caches (if supported) and returns the symbols of open positions
+// and the AI500 limit used for candidates, so the caller can prefetch their market data
+func (at *AutoTrader) WarmUp() (positionSymbols []string, ai500Limit int, err error) {
+	ai500Limit = at.candidateLimit()
+
+	if warmer, ok := baseTrader(at.trader).(CacheWarmer); ok {
+		if err := warmer.WarmUp(); err != nil {
+			return nil, ai500Limit, fmt.Errorf("failed to warm exchange caches: %w", err)
+		}
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return nil, ai500Limit, fmt.Errorf("failed to get positions: %w", err)
+	}
+	for _, pos := range positions {
+		positionSymbols = append(positionSymbols, pos.Symbol)
+	}
+	at.log.Infof("  ✓ Exchange caches warm (%d open positions)", len(positionSymbols))
+	return positionSymbols, ai500Limit, nil
+}